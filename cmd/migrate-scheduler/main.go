@@ -0,0 +1,49 @@
+// cmd/migrate-scheduler/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/storage"
+)
+
+// Copies BlueBerry run history from the shared data database into the
+// dedicated scheduler database (SCHEDULER_DATABASE_NAME).
+func main() {
+	from := flag.String("from", "", "database currently holding BlueBerry collections (default: DATABASE_NAME)")
+	to := flag.String("to", "", "target scheduler database (default: SCHEDULER_DATABASE_NAME)")
+	timeout := flag.Duration("timeout", 30*time.Minute, "overall migration timeout")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	fromDB := *from
+	if fromDB == "" {
+		fromDB = cfg.DatabaseName
+	}
+	toDB := *to
+	if toDB == "" {
+		toDB = cfg.SchedulerDatabaseName
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	log.Printf("Copying BlueBerry collections from %s to %s...", fromDB, toDB)
+	copied, err := storage.MigrateSchedulerCollections(ctx, cfg.MongoDBURI, fromDB, toDB)
+	for collection, count := range copied {
+		log.Printf("  %s: %d documents", collection, count)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	log.Println("Migration complete")
+}
@@ -0,0 +1,341 @@
+// cmd/mock-ingestion/main.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Serves the ingestion API with deterministic synthetic posts for local
+// development, so the orchestrator runs without the real API:
+//
+//	go run ./cmd/mock-ingestion -addr :8081
+//	INGESTION_API_URL=http://localhost:8081 go run ./cmd/server
+//
+// or both at once with make run.
+//
+// Every subreddit exists and has a timeline of posts seeded by its name that
+// grows as time passes, so since_timestamp cursors behave as against the real
+// API. Names starting with private_ answer 403 and missing_ answer 404.
+//
+// Latency and failures are injected for every request by the flags (or
+// MOCK_* env vars), or per request by the latency, error_rate and fail
+// (an HTTP status to answer with) query params.
+func main() {
+	addr := flag.String("addr", getEnv("MOCK_ADDR", ":8081"), "listen address (MOCK_ADDR)")
+	version := flag.String("api-version", getEnv("MOCK_API_VERSION", "v1"), "response format, v1 or v2 (MOCK_API_VERSION)")
+	interval := flag.Duration("post-interval", getEnvDuration("MOCK_POST_INTERVAL", 10*time.Minute), "average time between a subreddit's posts (MOCK_POST_INTERVAL)")
+	latency := flag.Duration("latency", getEnvDuration("MOCK_LATENCY", 0), "delay added to every response (MOCK_LATENCY)")
+	errorRate := flag.Float64("error-rate", getEnvFloat("MOCK_ERROR_RATE", 0), "fraction of requests answered with 500 (MOCK_ERROR_RATE)")
+	rateLimitRate := flag.Float64("rate-limit-rate", getEnvFloat("MOCK_RATE_LIMIT_RATE", 0), "fraction of requests answered with 429 (MOCK_RATE_LIMIT_RATE)")
+	flag.Parse()
+
+	if *version != "v1" && *version != "v2" {
+		log.Fatalf("-api-version must be v1 or v2")
+	}
+	if *interval < 2*time.Second {
+		log.Fatalf("-post-interval must be at least 2s")
+	}
+
+	mock := &mockServer{
+		version:       *version,
+		interval:      *interval,
+		latency:       *latency,
+		errorRate:     *errorRate,
+		rateLimitRate: *rateLimitRate,
+	}
+	server := &http.Server{Addr: *addr, Handler: mock.routes(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		log.Printf("Mock ingestion API (%s) listening on %s", *version, *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Mock ingestion API failed: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown failed: %v", err)
+	}
+}
+
+type mockServer struct {
+	version       string
+	interval      time.Duration
+	latency       time.Duration
+	errorRate     float64
+	rateLimitRate float64
+}
+
+func (m *mockServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"version": m.version})
+	})
+	mux.HandleFunc("/subreddit", m.subreddit)
+	mux.HandleFunc("/subreddit/top", m.subredditTop)
+	mux.HandleFunc("/subreddit/about", m.subredditAbout)
+	mux.HandleFunc("/user", m.user)
+	return m.inject(mux)
+}
+
+// inject delays and fails requests as configured, overridden per request by
+// the latency, error_rate and fail query params
+func (m *mockServer) inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		latency, errorRate := m.latency, m.errorRate
+		if value := query.Get("latency"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "latency must be a duration")
+				return
+			}
+			latency = parsed
+		}
+		if value := query.Get("error_rate"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "error_rate must be a number")
+				return
+			}
+			errorRate = parsed
+		}
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		status := 0
+		if value := query.Get("fail"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 400 || parsed > 599 {
+				writeError(w, http.StatusBadRequest, "fail must be an HTTP error status")
+				return
+			}
+			status = parsed
+		} else if rand.Float64() < m.rateLimitRate {
+			status = http.StatusTooManyRequests
+		} else if rand.Float64() < errorRate {
+			status = http.StatusInternalServerError
+		}
+
+		switch status {
+		case 0:
+			next.ServeHTTP(w, r)
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			w.Header().Set("Retry-After", "30")
+			writeError(w, status, "injected rate limit")
+		default:
+			writeError(w, status, "injected failure")
+		}
+	})
+}
+
+// subreddit serves the newest posts after since_timestamp (and before
+// until_timestamp), newest first
+func (m *mockServer) subreddit(w http.ResponseWriter, r *http.Request) {
+	name, ok := m.subredditName(w, r)
+	if !ok {
+		return
+	}
+	limit, since, until, err := postParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts := subredditTimeline(name, m.interval).newest(limit, since, until, time.Now().UTC())
+	m.writePosts(w, posts, map[string]interface{}{"subreddit": name, "count": len(posts)})
+}
+
+// subredditTop serves posts ranked by score within t (all, year or month),
+// paged by the opaque after of the previous page or by offset
+func (m *mockServer) subredditTop(w http.ResponseWriter, r *http.Request) {
+	name, ok := m.subredditName(w, r)
+	if !ok {
+		return
+	}
+	limit, _, _, err := postParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	var since time.Time
+	switch r.URL.Query().Get("t") {
+	case "all", "":
+	case "year":
+		since = now.AddDate(-1, 0, 0)
+	case "month":
+		since = now.AddDate(0, -1, 0)
+	default:
+		writeError(w, http.StatusBadRequest, "t must be all, year or month")
+		return
+	}
+	// The after cursor happens to be the offset of the page it starts
+	offset := 0
+	for _, key := range []string{"after", "offset"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+				writeError(w, http.StatusBadRequest, "invalid "+key)
+				return
+			}
+		}
+	}
+
+	posts, more := subredditTimeline(name, m.interval).top(limit, offset, since, now)
+	meta := map[string]interface{}{"subreddit": name, "count": len(posts)}
+	if more {
+		meta["after"] = strconv.Itoa(offset + len(posts))
+	}
+	m.writePosts(w, posts, meta)
+}
+
+func (m *mockServer) subredditAbout(w http.ResponseWriter, r *http.Request) {
+	if name, ok := m.subredditName(w, r); ok {
+		writeJSON(w, http.StatusOK, about(name))
+	}
+}
+
+// user serves a user's posts across a few subreddits, newest first
+func (m *mockServer) user(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimSpace(r.URL.Query().Get("username"))
+	if username == "" {
+		writeError(w, http.StatusBadRequest, "username is required")
+		return
+	}
+	limit, since, _, err := postParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts := userTimeline(username).newest(limit, since, time.Time{}, time.Now().UTC())
+	m.writePosts(w, posts, map[string]interface{}{"username": username, "count": len(posts)})
+}
+
+// subredditName reads the subreddit param, answering 403 or 404 for the
+// names reserved for unavailable subreddits
+func (m *mockServer) subredditName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := strings.TrimSpace(r.URL.Query().Get("subreddit"))
+	switch lower := strings.ToLower(name); {
+	case name == "":
+		writeError(w, http.StatusBadRequest, "subreddit is required")
+	case strings.HasPrefix(lower, "private_"):
+		writeError(w, http.StatusForbidden, "subreddit is private")
+	case strings.HasPrefix(lower, "missing_"):
+		writeError(w, http.StatusNotFound, "subreddit not found")
+	default:
+		return name, true
+	}
+	return "", false
+}
+
+// postParams reads limit (default 25, at most 100) and the optional
+// since_timestamp and until_timestamp epoch seconds
+func postParams(r *http.Request) (int, time.Time, time.Time, error) {
+	query := r.URL.Query()
+	limit := 25
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, time.Time{}, time.Time{}, errors.New("limit must be a positive integer")
+		}
+		limit = min(parsed, 100)
+	}
+
+	var bounds [2]time.Time
+	for i, name := range []string{"since_timestamp", "until_timestamp"} {
+		if value := query.Get(name); value != "" {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, time.Time{}, time.Time{}, fmt.Errorf("%s must be epoch seconds", name)
+			}
+			bounds[i] = time.Unix(seconds, 0).UTC()
+		}
+	}
+	return limit, bounds[0], bounds[1], nil
+}
+
+// writePosts answers {"posts": [...], "meta": {...}} in the configured format
+func (m *mockServer) writePosts(w http.ResponseWriter, posts []models.IngestionPost, meta map[string]interface{}) {
+	wire := make([]interface{}, 0, len(posts))
+	for _, post := range posts {
+		if m.version == "v1" {
+			wire = append(wire, post)
+			continue
+		}
+		wire = append(wire, map[string]interface{}{
+			"kind": "t3",
+			"data": v2Data{IngestionPost: post, CreatedAt: float64(post.CreatedAt.Unix())},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"posts": wire, "meta": meta})
+}
+
+// v2Data is a post in the v2 format, with created_at as epoch seconds
+type v2Data struct {
+	models.IngestionPost
+	CreatedAt float64 `json:"created_at"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Writing response failed: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,202 @@
+// cmd/mock-ingestion/posts.go
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// timelineStart is when every synthetic timeline begins
+var timelineStart = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	titleWords = []string{"Why", "How", "Ask", "Show", "Weekly", "Thoughts on", "Help with", "Announcing", "Question about", "Guide to"}
+	topics     = []string{"generics", "error handling", "the scheduler", "MongoDB indexes", "rate limits", "cursors", "testing", "deployments", "caching", "logging"}
+	flairs     = []string{"", "", "Discussion", "Question", "News", "Meta"}
+	domains    = []string{"example.com", "news.example.org", "blog.example.net", "github.com", "youtube.com"}
+	userSubs   = []string{"golang", "programming", "mockdev", "learnprogramming"}
+)
+
+// timeline is a deterministic sequence of posts: post i is created in
+// [start+i*gap, start+i*gap+gap/2), so posts keep their order and any time
+// window maps to a small index range
+type timeline struct {
+	key       string // seed of every post
+	subreddit string // empty for user timelines, which pick one per post
+	author    string // fixed for user timelines
+	start     time.Time
+	gap       time.Duration
+}
+
+// subredditTimeline posts roughly every interval, with a per-subreddit gap
+// between interval/2 and 3*interval/2
+func subredditTimeline(subreddit string, interval time.Duration) timeline {
+	key := strings.ToLower(subreddit)
+	seed := hash(key)
+	gap := interval/2 + time.Duration(seed%uint64(interval/time.Second))*time.Second
+	return timeline{
+		key:       key,
+		subreddit: subreddit,
+		start:     timelineStart.Add(time.Duration(seed%uint64(gap/time.Second)) * time.Second),
+		gap:       gap,
+	}
+}
+
+// userTimeline posts about four times a day across userSubs
+func userTimeline(username string) timeline {
+	key := "u/" + strings.ToLower(username)
+	return timeline{
+		key:    key,
+		author: username,
+		start:  timelineStart.Add(time.Duration(hash(key)%3600) * time.Second),
+		gap:    6 * time.Hour,
+	}
+}
+
+func hash(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+func (t timeline) seed(i int) uint64 {
+	return hash(t.key, strconv.Itoa(i))
+}
+
+func (t timeline) createdAt(i int) time.Time {
+	jitter := time.Duration(t.seed(i)%uint64(t.gap/2/time.Second)) * time.Second
+	return t.start.Add(time.Duration(i)*t.gap + jitter)
+}
+
+// indexBefore returns the index of the last post created before at, or -1
+func (t timeline) indexBefore(at time.Time) int {
+	if !at.After(t.start) {
+		return -1
+	}
+	i := int(at.Sub(t.start) / t.gap)
+	for i >= 0 && !t.createdAt(i).Before(at) {
+		i--
+	}
+	return i
+}
+
+// newest returns up to limit posts created after since and before until,
+// newest first. Zero bounds are open; until never goes past now.
+func (t timeline) newest(limit int, since, until, now time.Time) []models.IngestionPost {
+	if until.IsZero() || until.After(now) {
+		until = now.Add(time.Second)
+	}
+	var posts []models.IngestionPost
+	for i := t.indexBefore(until); i >= 0 && len(posts) < limit; i-- {
+		if !since.IsZero() && !t.createdAt(i).After(since) {
+			break
+		}
+		posts = append(posts, t.post(i))
+	}
+	return posts
+}
+
+// top returns the posts created since, ranked by score then newest, from
+// offset on
+func (t timeline) top(limit, offset int, since, now time.Time) ([]models.IngestionPost, bool) {
+	first := max(t.indexBefore(since)+1, 0)
+	last := t.indexBefore(now.Add(time.Second))
+	if last < first {
+		return nil, false
+	}
+
+	type ranked struct{ index, score int }
+	ranking := make([]ranked, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		ranking = append(ranking, ranked{i, t.score(i)})
+	}
+	sort.Slice(ranking, func(a, b int) bool {
+		if ranking[a].score != ranking[b].score {
+			return ranking[a].score > ranking[b].score
+		}
+		return ranking[a].index > ranking[b].index
+	})
+	if offset >= len(ranking) {
+		return nil, false
+	}
+
+	end := min(offset+limit, len(ranking))
+	posts := make([]models.IngestionPost, 0, end-offset)
+	for _, r := range ranking[offset:end] {
+		posts = append(posts, t.post(r.index))
+	}
+	return posts, end < len(ranking)
+}
+
+// score is skewed so most posts score low and a few score high
+func (t timeline) score(i int) int {
+	roll := int(t.seed(i) % 100)
+	return roll * roll / 10
+}
+
+func (t timeline) post(i int) models.IngestionPost {
+	seed := t.seed(i)
+	id := strconv.FormatUint(seed%(36*36*36*36*36*36*36), 36)
+
+	subreddit := t.subreddit
+	if subreddit == "" {
+		subreddit = userSubs[seed%uint64(len(userSubs))]
+	}
+	author := t.author
+	if author == "" {
+		author = fmt.Sprintf("mock_user_%d", seed>>8%50)
+		if seed%97 == 0 {
+			author = "[deleted]"
+		}
+	}
+
+	title := fmt.Sprintf("%s %s (#%d)", titleWords[seed>>16%uint64(len(titleWords))], topics[seed>>24%uint64(len(topics))], i)
+	slug := strings.ToLower(strings.NewReplacer(" ", "_", "(", "", ")", "", "#", "").Replace(title))
+	permalink := fmt.Sprintf("/r/%s/comments/%s/%s/", subreddit, id, slug)
+
+	post := models.IngestionPost{
+		ID:        id,
+		Title:     title,
+		Author:    author,
+		Score:     t.score(i),
+		CreatedAt: t.createdAt(i),
+		Flair:     flairs[seed>>32%uint64(len(flairs))],
+		Permalink: permalink,
+		IsNSFW:    boolPtr(strings.Contains(strings.ToLower(subreddit), "nsfw")),
+		Spoiler:   boolPtr(seed%50 == 0),
+	}
+	if seed>>40%3 == 0 {
+		post.URL = fmt.Sprintf("https://%s/articles/%s", domains[seed>>44%uint64(len(domains))], id)
+	} else {
+		post.URL = "https://www.reddit.com" + permalink
+		post.Body = fmt.Sprintf("Synthetic post %d of r/%s about %s.", i, subreddit, topics[seed>>24%uint64(len(topics))])
+	}
+	if t.subreddit == "" {
+		post.Subreddit = subreddit
+	}
+	return post
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+// about describes a subreddit deterministically
+func about(subreddit string) map[string]interface{} {
+	seed := hash("about", strings.ToLower(subreddit))
+	return map[string]interface{}{
+		"subscribers":        1000 + int(seed%500000),
+		"public_description": fmt.Sprintf("Mock subreddit r/%s for local development", subreddit),
+		"created_utc":        timelineStart.Add(-time.Duration(seed%(5*365*24)) * time.Hour).Unix(),
+		"over18":             strings.Contains(strings.ToLower(subreddit), "nsfw"),
+	}
+}
@@ -0,0 +1,551 @@
+// cmd/orchctl/commands.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+	"github.com/ersauravadhikari/blueberry-go/blueberry/store"
+
+	"reddit-orchestrator/internal/app"
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/enrichment"
+	"reddit-orchestrator/internal/leader"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/privacy"
+	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/tasks"
+)
+
+func configsList(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("configs list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	page, err := env.storage.GetAllSubredditConfigs(ctx, storage.ConfigFilter{}, storage.ListOptions{})
+	if err != nil {
+		return err
+	}
+	configs := page.Configs
+	if *asJSON {
+		return env.writeJSON(configs)
+	}
+
+	table := env.table()
+	fmt.Fprintln(table, "SUBREDDIT\tENABLED\tSCHEDULE\tMAX POSTS\tPRIORITY")
+	for _, config := range configs {
+		fmt.Fprintf(table, "%s\t%t\t%s\t%d\t%d\n",
+			config.SubredditName, config.Enabled, config.Schedule, config.MaxPosts, config.Priority)
+	}
+	return table.Flush()
+}
+
+func configsEnable(ctx context.Context, env *cliEnv, args []string) error {
+	return setConfigEnabled(ctx, env, "configs enable", args, true)
+}
+
+func configsDisable(ctx context.Context, env *cliEnv, args []string) error {
+	return setConfigEnabled(ctx, env, "configs disable", args, false)
+}
+
+func setConfigEnabled(ctx context.Context, env *cliEnv, name string, args []string, enabled bool) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	subreddit := positional[0]
+
+	config, err := env.storage.GetSubredditConfig(ctx, subreddit)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return fmt.Errorf("no config for r/%s", subreddit)
+	}
+
+	config.Enabled = enabled
+	if err := env.storage.UpsertSubredditConfig(ctx, config, env.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "r/%s enabled=%t\n", subreddit, enabled)
+	return nil
+}
+
+func configsAdd(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("configs add", flag.ContinueOnError)
+	schedule := fs.String("schedule", "", "cron schedule (default SUBREDDIT_SCHEDULE)")
+	maxPosts := fs.Int("max-posts", 0, "posts per run (default DEFAULT_LIMIT)")
+	priority := fs.Int("priority", 0, "higher runs first")
+	disabled := fs.Bool("disabled", false, "add the config disabled")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	subreddit := positional[0]
+
+	existing, err := env.storage.GetSubredditConfig(ctx, subreddit)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("r/%s already has a config; use configs enable/disable", subreddit)
+	}
+
+	config := &models.SubredditConfig{
+		SubredditName: subreddit,
+		Enabled:       !*disabled,
+		Schedule:      *schedule,
+		MaxPosts:      *maxPosts,
+		Priority:      *priority,
+	}
+	if config.MaxPosts <= 0 {
+		config.MaxPosts = env.cfg.DefaultLimit
+	}
+	if err := models.ValidateMaxPosts(config.MaxPosts, env.cfg.MaxPostsCeiling); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if err := env.storage.UpsertSubredditConfig(ctx, config, env.actor()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "Added r/%s (enabled: %t, schedule: %s, max_posts: %d)\n",
+		subreddit, config.Enabled, config.Schedule, config.MaxPosts)
+	return nil
+}
+
+// scrapePollInterval is how often scrape checks whether its run finished
+const scrapePollInterval = 500 * time.Millisecond
+
+// scrapeSummary is the result of a one-off scrape
+type scrapeSummary struct {
+	Subreddit      string   `json:"subreddit"`
+	ExecutionID    int      `json:"execution_id"`
+	Status         string   `json:"status"`
+	Limit          int      `json:"limit,omitempty"`
+	SinceTimestamp int64    `json:"since_timestamp,omitempty"`
+	KeepCursor     bool     `json:"keep_cursor"`
+	Log            []string `json:"log"`
+	Duration       string   `json:"duration"`
+}
+
+// scrape runs monitor_subreddit once, as the server's scheduler would: the
+// run is recorded in the scheduler's history and respects the maintenance
+// pause. With --since or --limit the scrape is bounded and last_scraped_at
+// is left alone, since posts outside the window were not fetched.
+func scrape(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("scrape", flag.ContinueOnError)
+	limit := fs.Int("limit", 0, "posts to fetch (default: config max_posts or DEFAULT_LIMIT)")
+	since := fs.Int64("since", 0, "epoch seconds; default resumes from last_scraped_at")
+	force := fs.Bool("force", false, "run during a maintenance pause")
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	subreddit := positional[0]
+
+	if *limit > env.cfg.MaxPostsCeiling {
+		return fmt.Errorf("%w: --limit must not exceed MAX_POSTS_CEILING (%d)", errUsage, env.cfg.MaxPostsCeiling)
+	}
+
+	runs, err := store.NewMongoDB(env.cfg.MongoDBURI, env.cfg.SchedulerDatabaseName)
+	if err != nil {
+		return fmt.Errorf("connecting to the scheduler database: %w", err)
+	}
+	defer runs.Close()
+	bb := blueberry.NewBlueBerryInstance(runs)
+
+	taskManager, err := newTaskManager(env, bb)
+	if err != nil {
+		return err
+	}
+
+	summary := scrapeSummary{
+		Subreddit:      subreddit,
+		Limit:          *limit,
+		SinceTimestamp: *since,
+		KeepCursor:     *limit > 0 || *since > 0,
+	}
+	start := time.Now()
+	executionID, err := taskManager.ScrapeNow(ctx, tasks.ManualScrape{
+		Subreddit:      subreddit,
+		Limit:          *limit,
+		SinceTimestamp: *since,
+		KeepCursor:     summary.KeepCursor,
+		Force:          *force,
+	})
+	if err != nil {
+		return fmt.Errorf("starting the run: %w", err)
+	}
+	summary.ExecutionID = executionID
+
+	run, err := waitForRun(ctx, bb, runs, executionID)
+	if err != nil {
+		return err
+	}
+	summary.Status = run.Status
+	summary.Duration = time.Since(start).Round(time.Millisecond).String()
+	logs, err := runs.GetTaskRunLogs(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("reading the run log: %w", err)
+	}
+	summary.Log = make([]string, 0, len(logs))
+	for _, entry := range logs {
+		summary.Log = append(summary.Log, entry.Message)
+	}
+
+	if *asJSON {
+		if err := env.writeJSON(summary); err != nil {
+			return err
+		}
+	} else {
+		for _, line := range summary.Log {
+			fmt.Fprintln(env.out, line)
+		}
+		cursor := "advanced"
+		if summary.KeepCursor {
+			cursor = "kept"
+		}
+		fmt.Fprintf(env.out, "r/%s: run %d %s in %s (cursor %s)\n", subreddit, executionID, summary.Status, summary.Duration, cursor)
+	}
+	if run.Status != "completed" {
+		return fmt.Errorf("run %d %s", executionID, run.Status)
+	}
+	return nil
+}
+
+// newTaskManager builds the server's task manager over env's storage, with
+// its tasks registered but not scheduled
+func newTaskManager(env *cliEnv, bb *blueberry.BlueBerry) (*tasks.SubredditTaskManager, error) {
+	fieldMapping, err := client.LoadFieldMapping(env.cfg.IngestionFieldMappingFile, env.cfg.IngestionAPIURL)
+	if err != nil {
+		return nil, err
+	}
+	ingestionClient := client.NewIngestionClient(env.cfg.IngestionAPIURL, env.cfg.RequestTimeout,
+		env.cfg.MaxResponseBytes, env.cfg.IngestionAPIVersion, fieldMapping, nil)
+	dataProcessor := processor.NewProcessor(env.cfg.Features.DebugRejections, privacy.NewAuthors(env.cfg.PrivacyMode, env.cfg.PrivacyHashKey), clock.Real)
+
+	var enricher enrichment.EnricherInterface
+	if env.cfg.EnrichmentURL != "" {
+		enricher = enrichment.NewHTTPEnricher(env.cfg.EnrichmentURL)
+	}
+	notifier, err := app.NewNotifier(env.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	taskManager := tasks.NewSubredditTaskManager(bb, env.storage, ingestionClient, dataProcessor, enricher, notifier,
+		nil, leader.NewStatic(env.cfg.InstanceID), env.cfg, clock.Real)
+	if err := taskManager.RegisterTasks(); err != nil {
+		return nil, err
+	}
+	return taskManager, nil
+}
+
+// waitForRun polls the scheduler database until a run finishes. The run is
+// cancelled if ctx ends first.
+func waitForRun(ctx context.Context, bb *blueberry.BlueBerry, runs blueberry.DB, executionID int) (*blueberry.TaskRun, error) {
+	ticker := time.NewTicker(scrapePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = bb.CancelExecutionByID(executionID)
+			return nil, fmt.Errorf("run %d did not finish: %w", executionID, ctx.Err())
+		case <-ticker.C:
+		}
+
+		run, err := runs.GetTaskRunByID(ctx, executionID)
+		if err != nil {
+			return nil, fmt.Errorf("reading run %d: %w", executionID, err)
+		}
+		if run.Status != "started" {
+			return run, nil
+		}
+	}
+}
+
+func metadataShow(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("metadata show", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	subreddit := positional[0]
+
+	metadata, err := env.storage.GetSubredditMetadata(ctx, subreddit)
+	if err != nil {
+		return err
+	}
+	if metadata == nil {
+		return fmt.Errorf("no metadata for r/%s", subreddit)
+	}
+	if *asJSON {
+		return env.writeJSON(metadata)
+	}
+
+	table := env.table()
+	fmt.Fprintf(table, "Subreddit:\tr/%s\n", metadata.SubredditName)
+	fmt.Fprintf(table, "Last scraped:\t%s\n", formatTime(metadata.LastScrapedAt))
+	fmt.Fprintf(table, "Zero-post runs:\t%d\n", metadata.ZeroPostRuns)
+	fmt.Fprintf(table, "Stale:\t%t\n", metadata.Stale)
+	if run := metadata.LastRun; run != nil {
+		fmt.Fprintf(table, "Last run:\tfetched %d, processed %d (limit %d) in %s\n",
+			run.PostsFetched, run.PostsProcessed, run.Limit, run.Duration.Round(time.Millisecond))
+		if len(run.Rejections) > 0 {
+			fmt.Fprintf(table, "Rejections:\t%s\n", formatCounts(run.Rejections))
+		}
+	}
+	fmt.Fprintf(table, "Updated:\t%s\n", formatTime(metadata.UpdatedAt))
+	return table.Flush()
+}
+
+func postsCount(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("posts count", flag.ContinueOnError)
+	subreddit := fs.String("subreddit", "", "subreddit to count (required)")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *subreddit == "" {
+		return fmt.Errorf("%w: --subreddit is required", errUsage)
+	}
+
+	count, err := env.storage.GetPostsCount(ctx, *subreddit)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return env.writeJSON(map[string]interface{}{"subreddit": *subreddit, "count": count})
+	}
+
+	fmt.Fprintln(env.out, strconv.FormatInt(count, 10))
+	return nil
+}
+
+// postsPartition moves posts from subreddit_post into their monthly
+// partitions. It runs until done or interrupted, ignoring the command timeout;
+// rerunning it resumes where it stopped.
+func postsPartition(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("posts partition", flag.ContinueOnError)
+	batch := fs.Int("batch", 1000, "posts moved per batch")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *batch <= 0 {
+		return fmt.Errorf("%w: --batch must be positive", errUsage)
+	}
+	if !env.cfg.PostPartitioning {
+		return fmt.Errorf("set PARTITIONING=monthly to migrate posts")
+	}
+
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	moved, err := env.storage.MigratePostsToPartitions(ctx, *batch, func(moved int64) {
+		fmt.Fprintf(env.out, "moved %d posts\n", moved)
+	})
+	if err != nil {
+		return fmt.Errorf("migration stopped after %d posts: %w", moved, err)
+	}
+	fmt.Fprintf(env.out, "done: moved %d posts\n", moved)
+	return nil
+}
+
+type reprocessSummary struct {
+	Scope        string `json:"scope"`
+	ApplyFilters bool   `json:"apply_filters"`
+	Examined     int64  `json:"examined"`
+	Modified     int64  `json:"modified"`
+	Deleted      int64  `json:"deleted"`
+	Duration     string `json:"duration"`
+}
+
+// postsReprocess re-applies the current normalization, permalink derivation
+// and tagging to stored posts; with --apply-filters it also deletes posts the
+// current config filters reject. Progress is checkpointed after every batch,
+// so rerunning an interrupted pass resumes it. Like posts partition it ignores
+// the command timeout.
+func postsReprocess(ctx context.Context, env *cliEnv, args []string) error {
+	fs := flag.NewFlagSet("posts reprocess", flag.ContinueOnError)
+	subreddit := fs.String("subreddit", "", "subreddit to reprocess (default: all)")
+	applyFilters := fs.Bool("apply-filters", false, "delete posts the current skip_nsfw, skip_spoilers and max_post_age_days reject")
+	batch := fs.Int("batch", 500, "posts read per batch")
+	rate := fs.Float64("rate", 200, "posts examined per second at most; 0 disables throttling")
+	restart := fs.Bool("restart", false, "discard the checkpoint of an unfinished pass and start over")
+	asJSON := fs.Bool("json", false, "print the summary as JSON")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *batch <= 0 {
+		return fmt.Errorf("%w: --batch must be positive", errUsage)
+	}
+	if *rate < 0 {
+		return fmt.Errorf("%w: --rate must not be negative", errUsage)
+	}
+
+	scope := *subreddit
+	if scope == "" {
+		scope = models.ReprocessScopeAll
+	}
+
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *restart {
+		if err := env.storage.DeleteReprocessProgress(ctx, scope); err != nil {
+			return err
+		}
+	}
+	progress, err := env.storage.GetReprocessProgress(ctx, scope)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = &models.ReprocessProgress{Scope: scope, ApplyFilters: *applyFilters, StartedAt: time.Now().UTC()}
+	} else if progress.ApplyFilters != *applyFilters {
+		return fmt.Errorf("%w: the unfinished pass over %s ran with --apply-filters=%t; rerun it the same way or pass --restart",
+			errUsage, scope, progress.ApplyFilters)
+	} else if !*asJSON {
+		fmt.Fprintf(env.out, "resuming pass started %s after %d posts\n", formatTime(progress.StartedAt), progress.Examined)
+	}
+
+	proc := processor.NewProcessor(nil, privacy.NewAuthors(env.cfg.PrivacyMode, env.cfg.PrivacyHashKey), clock.Real)
+	configs := make(map[string]*models.SubredditConfig)
+	position := storage.PostScanPosition{Collection: progress.Collection, LastID: progress.LastID}
+	for {
+		batchStart := time.Now()
+		posts, next, err := env.storage.ScanPosts(ctx, *subreddit, position, *batch)
+		if err != nil {
+			return fmt.Errorf("reprocess stopped after %d posts: %w; rerun to resume", progress.Examined, err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+
+		updated, deleted, err := reprocessBatch(ctx, env, proc, configs, posts, *applyFilters)
+		if err != nil {
+			return fmt.Errorf("reprocess stopped after %d posts: %w; rerun to resume", progress.Examined, err)
+		}
+		modified, removed, err := env.storage.RewritePosts(ctx, next.Collection, updated, deleted)
+		if err != nil {
+			return fmt.Errorf("reprocess stopped after %d posts: %w; rerun to resume", progress.Examined, err)
+		}
+
+		position = next
+		progress.Collection, progress.LastID = next.Collection, next.LastID
+		progress.Examined += int64(len(posts))
+		progress.Modified += modified
+		progress.Deleted += removed
+		if err := env.storage.SaveReprocessProgress(ctx, progress); err != nil {
+			return fmt.Errorf("saving reprocess progress: %w", err)
+		}
+		if !*asJSON {
+			fmt.Fprintf(env.out, "examined %d posts, modified %d, deleted %d\n", progress.Examined, progress.Modified, progress.Deleted)
+		}
+
+		// Pace batches so live scrapes keep most of the database
+		if *rate > 0 {
+			wait := time.Duration(float64(len(posts))/(*rate)*float64(time.Second)) - time.Since(batchStart)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("reprocess interrupted after %d posts; rerun to resume", progress.Examined)
+			case <-time.After(wait):
+			}
+		}
+	}
+
+	if err := env.storage.DeleteReprocessProgress(ctx, scope); err != nil {
+		return fmt.Errorf("clearing reprocess progress: %w", err)
+	}
+
+	summary := reprocessSummary{
+		Scope:        scope,
+		ApplyFilters: progress.ApplyFilters,
+		Examined:     progress.Examined,
+		Modified:     progress.Modified,
+		Deleted:      progress.Deleted,
+		Duration:     time.Since(progress.StartedAt).Round(time.Second).String(),
+	}
+	if *asJSON {
+		return env.writeJSON(summary)
+	}
+	fmt.Fprintf(env.out, "done: examined %d posts, modified %d, deleted %d in %s\n",
+		summary.Examined, summary.Modified, summary.Deleted, summary.Duration)
+	return nil
+}
+
+// reprocessBatch runs a scanned batch through the processor with each post's
+// subreddit config, caching configs in configs. It returns the posts to
+// update and, with applyFilters, the posts to delete.
+func reprocessBatch(ctx context.Context, env *cliEnv, proc *processor.Processor, configs map[string]*models.SubredditConfig,
+	posts []models.Post, applyFilters bool) ([]models.Post, []models.Post, error) {
+	bySubreddit := make(map[string][]models.Post)
+	for _, post := range posts {
+		bySubreddit[post.Subreddit] = append(bySubreddit[post.Subreddit], post)
+	}
+
+	var updated, deleted []models.Post
+	for subreddit, group := range bySubreddit {
+		subredditConfig, cached := configs[subreddit]
+		if !cached {
+			var err error
+			if subredditConfig, err = env.storage.GetSubredditConfig(ctx, subreddit); err != nil {
+				return nil, nil, err
+			}
+			configs[subreddit] = subredditConfig
+		}
+
+		changed, stats := proc.ReprocessPosts(group, subredditConfig, applyFilters)
+		updated = append(updated, changed...)
+		if !applyFilters {
+			continue
+		}
+
+		rejected := make(map[string]bool)
+		for _, post := range stats.Rejected {
+			if processor.IsFilterRejection(post.Reason) {
+				rejected[post.RedditID] = true
+			}
+		}
+		for _, post := range group {
+			if rejected[post.RedditID] {
+				deleted = append(deleted, post)
+			}
+		}
+	}
+	return updated, deleted, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatCounts(counts map[string]int) string {
+	parts := make([]string, 0, len(counts))
+	for key, count := range counts {
+		parts = append(parts, fmt.Sprintf("%s=%d", key, count))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,168 @@
+// cmd/orchctl/main.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/storage"
+)
+
+// Exit codes: cron and runbooks only need to tell failures from bad invocations
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// errUsage marks errors caused by the invocation rather than the operation
+var errUsage = errors.New("usage error")
+
+const usage = `orchctl runs maintenance commands against the orchestrator's storage
+without starting the scheduler.
+
+Usage:
+  orchctl configs list [--json]
+  orchctl configs enable <subreddit>
+  orchctl configs disable <subreddit>
+  orchctl configs add <subreddit> [--schedule S] [--max-posts N] [--priority N] [--disabled]
+  orchctl scrape <subreddit> [--limit N] [--since EPOCH] [--force] [--json]
+  orchctl metadata show <subreddit> [--json]
+  orchctl posts count --subreddit <subreddit> [--json]
+  orchctl posts partition [--batch N]
+  orchctl posts reprocess [--subreddit S] [--apply-filters] [--batch N] [--rate N] [--restart] [--json]
+
+Config changes are picked up by the server on its next restart.
+`
+
+// command runs one subcommand with its remaining arguments
+type command func(ctx context.Context, env *cliEnv, args []string) error
+
+var commands = map[string]command{
+	"configs enable":  configsEnable,
+	"configs disable": configsDisable,
+	"configs list":    configsList,
+	"configs add":     configsAdd,
+	"scrape":          scrape,
+	"metadata show":   metadataShow,
+	"posts count":     postsCount,
+	"posts partition": postsPartition,
+	"posts reprocess": postsReprocess,
+}
+
+// cliEnv is the configuration and storage shared by all subcommands
+type cliEnv struct {
+	cfg     *config.Config
+	storage storage.StorageInterface
+	out     io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	cmd, rest, ok := lookupCommand(args)
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return exitFailure
+	}
+
+	storage.MaxUnboundedResults = cfg.MaxUnboundedResults
+	storage.MaxFlairHistory = cfg.FlairHistoryCap
+	store, err := storage.NewMongoStorage(cfg.MongoDBURI, cfg.DatabaseName, cfg.SlowQueryThreshold, storage.PartitionOptions{
+		Monthly:       cfg.PostPartitioning,
+		DefaultMonths: cfg.PartitionReadMonths,
+	}, clock.Real)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to storage: %v\n", err)
+		return exitFailure
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.RequestTimeout+time.Minute)
+	defer cancel()
+
+	if err := cmd(ctx, &cliEnv{cfg: cfg, storage: store, out: os.Stdout}, rest); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+			return exitUsage
+		}
+		return exitFailure
+	}
+	return exitOK
+}
+
+// lookupCommand matches "group action" commands before single-word ones
+func lookupCommand(args []string) (command, []string, bool) {
+	if len(args) >= 2 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return cmd, args[2:], true
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd, args[1:], true
+		}
+	}
+	return nil, nil, false
+}
+
+// parseArgs parses flags anywhere in args (the flag package stops at the
+// first positional argument) and checks the positional count
+func parseArgs(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var values []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		values = append(values, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(values) != positional {
+		return nil, fmt.Errorf("%w: expected %d argument(s), got %d", errUsage, positional, len(values))
+	}
+	return values, nil
+}
+
+// writeJSON prints v as indented JSON
+func (env *cliEnv) writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(env.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// actor names the operator in the config audit
+func (env *cliEnv) actor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "orchctl:" + user
+	}
+	return "orchctl"
+}
+
+// table returns a tabwriter for aligned human-readable output; callers Flush it
+func (env *cliEnv) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(env.out, 0, 0, 2, ' ', 0)
+}
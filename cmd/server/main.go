@@ -2,6 +2,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -11,6 +12,17 @@ import (
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending schema migrations and exit")
+	flag.Parse()
+
+	if *migrateOnly {
+		if err := app.Migrate(); err != nil {
+			log.Fatalf("Failed to apply schema migrations: %v", err)
+		}
+		log.Println("Schema migrations are up to date")
+		return
+	}
+
 	application, err := app.Initialize()
 	if err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
@@ -20,19 +32,13 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	go func() {
-		sig := <-sigChan
-		log.Printf("Received signal: %v. Shutting down...", sig)
-		application.Shutdown()
-		os.Exit(0)
-	}()
-
 	log.Println("Starting Reddit Subreddit Orchestrator...")
-	log.Println("BlueBerry dashboard available at http://localhost:8080")
+	log.Printf("BlueBerry dashboard available at http://%s:%s", application.Config.ServerHost, application.Config.ServerPort)
 	log.Println("Login with configured username/password")
 
-	// Start the scheduler and API server
-	if err := application.Start(); err != nil {
+	// Start the scheduler and API server; Run returns once a signal's
+	// shutdown has finished
+	if err := application.Run(sigChan); err != nil {
 		log.Fatalf("Failed to start application: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,255 @@
+// internal/api/admin_handler.go
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// explainQueries reports whether each canonical query shape is served by an index
+func (s *Server) explainQueries(c echo.Context) error {
+	reports, err := s.storage.ExplainQueryShapes(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	unindexed := 0
+	for _, report := range reports {
+		if !report.IndexUsed {
+			unindexed++
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"queries":   reports,
+		"unindexed": unindexed,
+	})
+}
+
+// getOpsData reports the size, oldest record and retention of each
+// operational collection (scheduler task runs and logs, notification log,
+// webhook deliveries, QA samples)
+func (s *Server) getOpsData(c echo.Context) error {
+	stats, err := s.storage.OpsDataStats(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"collections": stats,
+	})
+}
+
+// listNotifyChannels lists the notification channels with their delivery
+// records since startup
+func (s *Server) listNotifyChannels(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"channels": s.tasks.NotificationChannels(),
+	})
+}
+
+// testNotifyChannel sends a synthetic alert to the channel named by the
+// channel query param and returns its record, with 502 when the send failed
+func (s *Server) testNotifyChannel(c echo.Context) error {
+	name := strings.TrimSpace(c.QueryParam("channel"))
+	if name == "" {
+		return badRequest(c, CodeInvalidRequest, "channel is required")
+	}
+
+	status, err := s.tasks.TestNotificationChannel(c.Request().Context(), name)
+	switch {
+	case errors.Is(err, tasks.ErrNoChannels):
+		return badRequest(c, CodeFeatureDisabled, err.Error())
+	case errors.Is(err, notify.ErrUnknownChannel):
+		return notFound(c, CodeChannelNotFound, err.Error())
+	case err != nil:
+		return c.JSON(http.StatusBadGateway, status)
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// repairMetadata finds subreddits whose last_scraped_at is in the future or
+// lags the newest stored post and resets it to that post's created_at.
+// Query params: dry_run (default false) returns the proposals without applying them.
+func (s *Server) repairMetadata(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	dryRun, err := queryBool(c, "dry_run", false)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	repairs, err := s.storage.FindInconsistentMetadata(ctx, time.Now().UTC(), s.config.MetadataRepairMargin)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	for i := range repairs {
+		repair := &repairs[i]
+		if dryRun {
+			log.Printf("Metadata repair (dry run) r/%s: %s, last_scraped_at %s -> %s",
+				repair.SubredditName, repair.Reason, repair.Before.Format(time.RFC3339), repair.After.Format(time.RFC3339))
+			continue
+		}
+
+		applied, err := s.storage.ApplyMetadataRepair(ctx, *repair)
+		if err != nil {
+			return internalError(c, err)
+		}
+		repair.Applied = applied
+
+		if applied {
+			log.Printf("Metadata repair r/%s: %s, last_scraped_at %s -> %s",
+				repair.SubredditName, repair.Reason, repair.Before.Format(time.RFC3339), repair.After.Format(time.RFC3339))
+		} else {
+			log.Printf("Metadata repair r/%s skipped: last_scraped_at changed since scan", repair.SubredditName)
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dry_run": dryRun,
+		"margin":  s.config.MetadataRepairMargin.String(),
+		"repairs": repairs,
+		"count":   len(repairs),
+	})
+}
+
+// pauseRequest is the body of the pause endpoint
+type pauseRequest struct {
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// pauseIngestion pauses scheduled scraping on every instance from an
+// optional {"duration": "2h", "reason": "..."} body; duration is a Go
+// duration or days ("1d"), and without it the pause lasts until resumed.
+// Monitor runs need force to run during the pause. A new pause replaces the
+// current one.
+func (s *Server) pauseIngestion(c echo.Context) error {
+	var request pauseRequest
+	if err := c.Bind(&request); err != nil {
+		return invalidBody(c, err)
+	}
+
+	now := time.Now().UTC()
+	pause := &models.MaintenancePause{
+		Reason:   strings.TrimSpace(request.Reason),
+		Actor:    actor(c),
+		PausedAt: now,
+	}
+	if request.Duration != "" {
+		duration, err := parseAge("duration", request.Duration)
+		if err != nil {
+			return badRequest(c, CodeValidationFailed, err.Error())
+		}
+		until := now.Add(duration)
+		pause.Until = &until
+	}
+
+	if err := s.storage.SetMaintenancePause(c.Request().Context(), pause); err != nil {
+		return internalError(c, err)
+	}
+	log.Printf("Ingestion paused for maintenance by %s (until: %v, reason: %q)", pause.Actor, pause.Until, pause.Reason)
+	return c.JSON(http.StatusOK, map[string]interface{}{"paused": true, "pause": pause})
+}
+
+// resumeIngestion ends the maintenance pause; resumed is false when none
+// was active
+func (s *Server) resumeIngestion(c echo.Context) error {
+	resumed, err := s.storage.ClearMaintenancePause(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+	if resumed {
+		log.Printf("Ingestion resumed by %s", actor(c))
+	}
+	return c.JSON(http.StatusOK, map[string]bool{"resumed": resumed})
+}
+
+// featureFlagView is one flag as returned by the features endpoints
+type featureFlagView struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	RuntimeSafe bool   `json:"runtime_safe"`
+}
+
+// listFeatures returns every feature flag with its current value
+func (s *Server) listFeatures(c echo.Context) error {
+	flags := s.config.Features.All()
+	views := make([]featureFlagView, 0, len(flags))
+	for _, flag := range flags {
+		views = append(views, featureFlagView{
+			Name:        flag.Name,
+			Description: flag.Description,
+			Enabled:     flag.Enabled(),
+			RuntimeSafe: flag.RuntimeSafe,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"features": views,
+	})
+}
+
+// patchFeatures toggles runtime-safe flags from a {"name": bool} body. The
+// whole request is rejected if any flag is unknown or not runtime-safe.
+func (s *Server) patchFeatures(c echo.Context) error {
+	var changes map[string]bool
+	if err := c.Bind(&changes); err != nil || len(changes) == 0 {
+		return badRequest(c, CodeInvalidBody, `body must be an object of flag names to booleans, e.g. {"enrichment": false}`)
+	}
+
+	for name := range changes {
+		if err := s.config.Features.CheckSet(name); err != nil {
+			return badRequest(c, CodeValidationFailed, err.Error())
+		}
+	}
+
+	user := actor(c)
+	for name, enabled := range changes {
+		previous, err := s.config.Features.Set(name, enabled)
+		if err != nil {
+			return badRequest(c, CodeValidationFailed, err.Error())
+		}
+		log.Printf("Feature flag %s changed from %t to %t by %s", name, previous, enabled, user)
+	}
+
+	return s.listFeatures(c)
+}
+
+// getLatestCapture returns the newest raw ingestion response captured for a
+// subreddit (see CAPTURE_RAW_RESPONSES). Query params: raw (default false)
+// serves the captured body alone, as it was received.
+func (s *Server) getLatestCapture(c echo.Context) error {
+	raw, err := queryBool(c, "raw", false)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	capture, err := s.storage.GetLatestRawCapture(c.Request().Context(), c.Param("subreddit"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if capture == nil {
+		return notFound(c, CodeCaptureNotFound, "no capture kept for this subreddit")
+	}
+
+	if raw {
+		// A truncated body is not valid JSON, so it is only labelled JSON when whole
+		contentType := echo.MIMEApplicationJSON
+		if capture.Truncated {
+			contentType = echo.MIMETextPlainCharsetUTF8
+		}
+		return c.Blob(http.StatusOK, contentType, []byte(capture.Body))
+	}
+	return c.JSON(http.StatusOK, capture)
+}
@@ -0,0 +1,32 @@
+// internal/api/anomalies_handler.go
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultAnomaliesLimit = 100
+
+// getAnomalies lists detected author bursts, newest first.
+// Query params: subreddit (optional), limit.
+func (s *Server) getAnomalies(c echo.Context) error {
+	subreddit := strings.TrimSpace(c.QueryParam("subreddit"))
+
+	limit, err := queryLimit(c, defaultAnomaliesLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	anomalies, err := s.storage.GetAnomalies(c.Request().Context(), subreddit, limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"anomalies": anomalies,
+		"count":     len(anomalies),
+	})
+}
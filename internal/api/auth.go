@@ -0,0 +1,58 @@
+// internal/api/auth.go
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+	"time"
+)
+
+// verifiedPasswordTTL is how long a password bcrypt accepted is taken from
+// the cache before bcrypt checks it again
+const verifiedPasswordTTL = time.Minute
+
+// verifiedPassword remembers the password bcrypt accepted last, so clients
+// sending basic auth with every request pay bcrypt's cost once per TTL
+// instead of on each request. It keeps an HMAC digest under a per-process
+// key, never the password.
+type verifiedPassword struct {
+	mu      sync.Mutex
+	key     []byte
+	digest  []byte
+	expires time.Time
+}
+
+// sum returns the digest of password; the caller holds mu
+func (v *verifiedPassword) sum(password string) []byte {
+	if v.key == nil {
+		v.key = make([]byte, sha256.Size)
+		rand.Read(v.key)
+	}
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+// matches reports whether password is the one verified last and the
+// verification has not expired
+func (v *verifiedPassword) matches(password string, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.digest == nil || !now.Before(v.expires) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(v.sum(password), v.digest) == 1
+}
+
+// store records password as verified until the TTL after now
+func (v *verifiedPassword) store(password string, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.digest = v.sum(password)
+	v.expires = now.Add(verifiedPasswordTTL)
+}
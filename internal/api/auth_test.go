@@ -0,0 +1,74 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestAuthenticate(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		password, hash     string
+		user, sentPassword string
+		want               bool
+	}{
+		{name: "plaintext", password: testPassword, user: testUser, sentPassword: testPassword, want: true},
+		{name: "plaintext wrong password", password: testPassword, user: testUser, sentPassword: "a-long-enough-guess", want: false},
+		{name: "plaintext wrong user", password: testPassword, user: "root", sentPassword: testPassword, want: false},
+		{name: "hashed", hash: string(hash), user: testUser, sentPassword: testPassword, want: true},
+		{name: "hashed wrong password", hash: string(hash), user: testUser, sentPassword: "a-long-enough-guess", want: false},
+		{name: "hashed wrong user", hash: string(hash), user: "root", sentPassword: testPassword, want: false},
+		{name: "hashed ignores the plaintext", password: "a-long-enough-guess", hash: string(hash), user: testUser, sentPassword: "a-long-enough-guess", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.WebAuthPassword, cfg.WebAuthPasswordHash = tt.password, tt.hash
+			server, _ := newTestServer(t, cfg, storagetest.NewMemory(clocktest.NewFake(time.Now())))
+
+			// The second attempt may be answered from the verification cache
+			for attempt := 1; attempt <= 2; attempt++ {
+				ok, err := server.authenticate(tt.user, tt.sentPassword, nil)
+				if err != nil || ok != tt.want {
+					t.Fatalf("attempt %d: authenticate() = %v, %v; want %v", attempt, ok, err, tt.want)
+				}
+			}
+			if cached := server.verifiedPassword.digest != nil; cached != (tt.hash != "" && tt.sentPassword == testPassword) {
+				t.Errorf("verification cached = %v, want it cached only after bcrypt accepted the password", cached)
+			}
+		})
+	}
+}
+
+func TestVerifiedPassword(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var v verifiedPassword
+
+	if v.matches(testPassword, now) {
+		t.Fatal("matches() before any verification")
+	}
+	v.store(testPassword, now)
+	if !v.matches(testPassword, now.Add(verifiedPasswordTTL-time.Second)) {
+		t.Error("verified password not matched within the TTL")
+	}
+	if v.matches("a-long-enough-guess", now) {
+		t.Error("a different password matched the verified one")
+	}
+	if v.matches(testPassword, now.Add(verifiedPasswordTTL)) {
+		t.Error("verified password still matched after the TTL")
+	}
+	if string(v.digest) == testPassword || len(v.digest) == 0 {
+		t.Errorf("cache keeps %q, want a digest", v.digest)
+	}
+}
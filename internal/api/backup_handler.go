@@ -0,0 +1,70 @@
+// internal/api/backup_handler.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// getBackup downloads a JSON snapshot of configs, metadata, notification
+// rules, config templates and digests; posts are not included
+func (s *Server) getBackup(c echo.Context) error {
+	backup, err := s.storage.ExportBackup(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	filename := fmt.Sprintf("orchestrator-backup-%s.json", backup.CreatedAt.Format("20060102-150405"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSON(http.StatusOK, backup)
+}
+
+// restoreBackup applies a backup from getBackup.
+// Query params: mode (merge|replace, default merge), dry_run (default false).
+// Subreddit schedules are reconciled with the restored configs right away;
+// digest schedules are registered at startup, so restored digests are
+// scheduled after the next restart.
+func (s *Server) restoreBackup(c echo.Context) error {
+	dryRun, err := queryBool(c, "dry_run", false)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	var backup models.Backup
+	if err := c.Bind(&backup); err != nil {
+		return invalidBody(c, err)
+	}
+
+	ctx := c.Request().Context()
+	opts := storage.RestoreOptions{Mode: c.QueryParam("mode"), DryRun: dryRun}
+	report, err := s.storage.RestoreBackup(ctx, &backup, opts)
+	if errors.Is(err, storage.ErrInvalidBackup) {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if err != nil {
+		// The restore was rolled back, unless MongoDB is a standalone
+		// server; the report shows how far it got
+		return respondError(c, http.StatusInternalServerError, CodeInternal, err.Error(), map[string]interface{}{"report": report})
+	}
+
+	response := map[string]interface{}{"report": report, "restart_required": false}
+	if dryRun {
+		return c.JSON(http.StatusOK, response)
+	}
+
+	schedules, err := s.tasks.ReconcileSchedules(ctx)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, CodeInternal,
+			fmt.Sprintf("backup restored, but reconciling schedules failed: %v", err), map[string]interface{}{"report": report})
+	}
+	digests := report.Collections[storage.DigestsCollection]
+	response["schedules"] = schedules
+	response["restart_required"] = digests.Inserted+digests.Updated+digests.Deleted > 0
+	return c.JSON(http.StatusOK, response)
+}
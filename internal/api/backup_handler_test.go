@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+// restoreStore reports a restore that wrote the given counts to digests
+type restoreStore struct {
+	*storagetest.Memory
+	digests models.RestoreCounts
+}
+
+func (s *restoreStore) RestoreBackup(ctx context.Context, backup *models.Backup, opts storage.RestoreOptions) (models.RestoreReport, error) {
+	report := models.RestoreReport{Mode: opts.Mode, DryRun: opts.DryRun, Collections: map[string]models.RestoreCounts{
+		storage.SubredditConfigCollection: {Inserted: len(backup.SubredditConfigs)},
+		storage.DigestsCollection:         s.digests,
+	}}
+	return report, nil
+}
+
+func TestRestoreBackupReconcilesSchedules(t *testing.T) {
+	body := `{"version":1,"subreddit_configs":[{"subreddit_name":"golang","enabled":true}]}`
+
+	tests := []struct {
+		name           string
+		query          string
+		digests        models.RestoreCounts
+		wantReconciled int
+		wantRestart    bool
+	}{
+		{name: "restore", query: "?mode=replace", wantReconciled: 1},
+		{name: "restore with digests", query: "?mode=replace", digests: models.RestoreCounts{Inserted: 1}, wantReconciled: 1, wantRestart: true},
+		{name: "dry run", query: "?mode=replace&dry_run=true", digests: models.RestoreCounts{Inserted: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskManager := &fakeTasks{}
+			store := &restoreStore{Memory: storagetest.NewMemory(clocktest.NewFake(time.Now())), digests: tt.digests}
+			server := NewServer(testConfig(t), store, taskManager, nil, nil, nil)
+			e := echo.New()
+			server.RegisterRoutes(e)
+
+			rec := serve(e, http.MethodPost, "/api/admin/restore"+tt.query, body)
+			wantStatus(t, rec, http.StatusOK)
+			if got := taskManager.reconciledCount(); got != tt.wantReconciled {
+				t.Errorf("schedules reconciled %d times, want %d", got, tt.wantReconciled)
+			}
+
+			var response struct {
+				RestartRequired bool            `json:"restart_required"`
+				Schedules       json.RawMessage `json:"schedules"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.RestartRequired != tt.wantRestart {
+				t.Errorf("restart_required = %v, want %v", response.RestartRequired, tt.wantRestart)
+			}
+			if (response.Schedules != nil) != (tt.wantReconciled > 0) {
+				t.Errorf("schedules = %s, want them only when reconciled", response.Schedules)
+			}
+		})
+	}
+}
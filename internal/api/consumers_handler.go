@@ -0,0 +1,196 @@
+// internal/api/consumers_handler.go
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+const defaultConsumeLimit = 100
+
+// consumeSettleDelay holds back posts inserted this recently: inserted_at is
+// set before a run's write completes, so a slower concurrent write could
+// still commit posts behind a cursor that already moved past them
+const consumeSettleDelay = 30 * time.Second
+
+// subredditNamePattern matches the names Reddit allows, which are also safe
+// as keys of a consumer's cursors
+var subredditNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// listConsumers lists consumers with their cursors and pending batches
+func (s *Server) listConsumers(c echo.Context) error {
+	consumers, err := s.storage.GetConsumers(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"consumers": consumers,
+		"count":     len(consumers),
+	})
+}
+
+// getConsumer returns one consumer with its cursors and pending batches
+func (s *Server) getConsumer(c echo.Context) error {
+	consumer, err := s.storage.GetConsumer(c.Request().Context(), c.Param("consumer"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if consumer == nil {
+		return notFound(c, CodeConsumerNotFound, "consumer not found")
+	}
+
+	return c.JSON(http.StatusOK, consumer)
+}
+
+// putConsumer creates a consumer or changes its mode (auto or ack, default
+// auto). The name comes from the path; existing cursors are kept.
+func (s *Server) putConsumer(c echo.Context) error {
+	var consumer models.Consumer
+	if err := c.Bind(&consumer); err != nil {
+		return invalidBody(c, err)
+	}
+	consumer.Name = strings.TrimSpace(c.Param("consumer"))
+	if consumer.Mode == "" {
+		consumer.Mode = models.ConsumerModeAuto
+	}
+	if err := consumer.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := s.storage.SaveConsumer(ctx, &consumer); err != nil {
+		return internalError(c, err)
+	}
+	saved, err := s.storage.GetConsumer(ctx, consumer.Name)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, saved)
+}
+
+// deleteConsumer removes a consumer and its cursors
+func (s *Server) deleteConsumer(c echo.Context) error {
+	found, err := s.storage.DeleteConsumer(c.Request().Context(), c.Param("consumer"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !found {
+		return notFound(c, CodeConsumerNotFound, "consumer not found")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// consumePosts returns the posts of a subreddit stored after the consumer's
+// cursor for it, in insertion order. Query params: subreddit (required),
+// limit. In auto mode the cursor moves past the batch before it is returned.
+// In ack mode the response carries a token for POST .../ack; until the batch
+// is acked every read starts at the same post again.
+func (s *Server) consumePosts(c echo.Context) error {
+	ctx := c.Request().Context()
+	name := c.Param("consumer")
+
+	subreddit := strings.TrimSpace(c.QueryParam("subreddit"))
+	if !subredditNamePattern.MatchString(subreddit) {
+		return badRequest(c, CodeInvalidRequest, "subreddit is required and may only contain letters, digits and _")
+	}
+	limit, err := queryLimit(c, defaultConsumeLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	consumer, err := s.storage.GetConsumer(ctx, name)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if consumer == nil {
+		return notFound(c, CodeConsumerNotFound, "consumer not found")
+	}
+
+	from := consumer.Cursors[subreddit]
+	now := time.Now().UTC()
+	posts, err := s.storage.GetPostsInsertedAfter(ctx, subreddit, from, now.Add(-consumeSettleDelay), limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	response := map[string]interface{}{
+		"consumer":  name,
+		"subreddit": subreddit,
+		"mode":      consumer.Mode,
+		"posts":     posts,
+		"count":     len(posts),
+		"cursor":    from,
+	}
+	if len(posts) == 0 {
+		response["posts"] = []models.Post{}
+		return c.JSON(http.StatusOK, response)
+	}
+
+	last := posts[len(posts)-1]
+	to := models.ConsumerPosition{InsertedAt: last.InsertedAt, PostID: last.ID}
+	if consumer.Mode == models.ConsumerModeAck {
+		batch := models.ConsumerBatch{Token: newBatchToken(subreddit), Position: to, Count: len(posts), IssuedAt: now}
+		found, err := s.storage.SetConsumerPending(ctx, name, subreddit, batch)
+		if err != nil {
+			return internalError(c, err)
+		}
+		if !found {
+			return notFound(c, CodeConsumerNotFound, "consumer not found")
+		}
+		response["token"] = batch.Token
+		return c.JSON(http.StatusOK, response)
+	}
+
+	advanced, err := s.storage.AdvanceConsumerCursor(ctx, name, subreddit, from, to)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !advanced {
+		return conflict(c, CodeConflict, "another read advanced the cursor first; read again")
+	}
+	response["cursor"] = to
+	return c.JSON(http.StatusOK, response)
+}
+
+// ackConsumerBatch moves an ack mode consumer's cursor past the batch whose
+// token is in the body ({"token": "..."})
+func (s *Server) ackConsumerBatch(c echo.Context) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return invalidBody(c, err)
+	}
+	subreddit, _, ok := strings.Cut(req.Token, ":")
+	if !ok || !subredditNamePattern.MatchString(subreddit) {
+		return badRequest(c, CodeValidationFailed, "token must be one returned by a read")
+	}
+
+	acked, err := s.storage.AckConsumerBatch(c.Request().Context(), c.Param("consumer"), subreddit, req.Token)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !acked {
+		return conflict(c, CodeBatchNotPending, "no batch with this token is pending; it was acked already or replaced by a later read")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// newBatchToken returns a random token naming the subreddit it acks
+func newBatchToken(subreddit string) string {
+	var random [16]byte
+	_, _ = rand.Read(random[:])
+	return subreddit + ":" + hex.EncodeToString(random[:])
+}
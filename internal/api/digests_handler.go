@@ -0,0 +1,86 @@
+// internal/api/digests_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// listDigests lists all label digests by label
+func (s *Server) listDigests(c echo.Context) error {
+	digests, err := s.storage.GetAllDigests(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"digests": digests,
+		"count":   len(digests),
+	})
+}
+
+func (s *Server) getDigest(c echo.Context) error {
+	label, err := models.NormalizeLabel(c.Param("label"))
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	digest, err := s.storage.GetDigest(c.Request().Context(), label)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if digest == nil {
+		return notFound(c, CodeDigestNotFound, "digest not found")
+	}
+
+	return c.JSON(http.StatusOK, digest)
+}
+
+// putDigest creates or replaces the digest of the label in the path. Schedule
+// changes apply from the next restart, as for other configs.
+func (s *Server) putDigest(c echo.Context) error {
+	var digest models.Digest
+	if err := c.Bind(&digest); err != nil {
+		return invalidBody(c, err)
+	}
+	digest.Label = c.Param("label")
+
+	if err := digest.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if digest.WebhookURL != "" {
+		if err := s.webhooks.CheckURL(digest.WebhookURL); err != nil {
+			return badRequest(c, CodeValidationFailed, fmt.Sprintf("webhook_url: %v", err))
+		}
+	}
+	if err := checkSchedule(digest.Schedule); err != nil {
+		return badRequest(c, CodeInvalidSchedule, err.Error())
+	}
+
+	if err := s.storage.UpsertDigest(c.Request().Context(), &digest); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, digest)
+}
+
+func (s *Server) deleteDigest(c echo.Context) error {
+	label, err := models.NormalizeLabel(c.Param("label"))
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	deleted, err := s.storage.DeleteDigest(c.Request().Context(), label)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !deleted {
+		return notFound(c, CodeDigestNotFound, "digest not found")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
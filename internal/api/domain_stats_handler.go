@@ -0,0 +1,54 @@
+// internal/api/domain_stats_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultDomainStatsAge and maxDomainStatsAge bound the since param of
+	// the domain stats
+	defaultDomainStatsAge = 7 * 24 * time.Hour
+	maxDomainStatsAge     = 90 * 24 * time.Hour
+	defaultDomainStats    = 50
+)
+
+// getDomainStats counts posts per linked domain (registrable domain, "self"
+// for self posts) with their average score, most linked first. Query params:
+// subreddit (default all), since (age such as 7d or 36h, default 7d), limit
+// (default 50). Posts with malformed URLs are counted as unparsed, posts
+// stored before domains were extracted as unextracted.
+func (s *Server) getDomainStats(c echo.Context) error {
+	age, err := queryAge(c, "since", defaultDomainStatsAge)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	if age > maxDomainStatsAge {
+		return badRequest(c, CodeInvalidRequest, fmt.Sprintf("since must not exceed %dd", int(maxDomainStatsAge.Hours()/24)))
+	}
+	limit, err := queryLimit(c, defaultDomainStats)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	subreddit := strings.TrimSpace(c.QueryParam("subreddit"))
+
+	since := time.Now().UTC().Add(-age)
+	stats, err := s.storage.GetDomainStats(c.Request().Context(), subreddit, since, limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subreddit":   subreddit,
+		"since":       since,
+		"domains":     stats.Domains,
+		"count":       len(stats.Domains),
+		"unparsed":    stats.Unparsed,
+		"unextracted": stats.Unextracted,
+	})
+}
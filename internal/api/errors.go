@@ -0,0 +1,175 @@
+// internal/api/errors.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/query"
+)
+
+// Machine-readable error codes. They are part of the API: clients branch on
+// them and localize messages by them, so existing codes must not change.
+const (
+	CodeInvalidRequest       = "invalid_request"             // malformed query parameter or request
+	CodeInvalidBody          = "invalid_body"                // body is not valid JSON for the endpoint
+	CodeValidationFailed     = "validation_failed"           // body decoded but a field is invalid
+	CodeInvalidSchedule      = "invalid_schedule"            // schedule is not a valid cron expression
+	CodeUnknownTemplate      = "unknown_template"            // config template named in a body does not exist
+	CodeUnauthorized         = "unauthorized"                // missing or wrong credentials or signature
+	CodeForbidden            = "forbidden"                   // authenticated but not allowed
+	CodeNotFound             = "not_found"                   // route or resource without a more specific code
+	CodeSubredditNotFound    = "subreddit_not_found"         // no subreddit config by that name
+	CodeTemplateNotFound     = "template_not_found"          // no config template by that name
+	CodeUserNotFound         = "user_not_found"              // no user config by that name
+	CodeRuleNotFound         = "notification_rule_not_found" // no notification rule by that name
+	CodeDigestNotFound       = "digest_not_found"            // no digest for that label
+	CodeDeliveryNotFound     = "delivery_not_found"          // no webhook delivery by that ID
+	CodeChannelNotFound      = "channel_not_found"           // no notification channel by that name
+	CodeCaptureNotFound      = "capture_not_found"           // no raw response captured
+	CodeLabelNotFound        = "label_not_found"             // no subreddit config carries the label
+	CodeConsumerNotFound     = "consumer_not_found"          // no consumer by that name
+	CodePresetNotFound       = "preset_not_found"            // no run preset by that name
+	CodeBatchNotPending      = "batch_not_pending"           // the acked batch is not the pending one
+	CodeFeatureDisabled      = "feature_disabled"            // the endpoint is turned off by configuration
+	CodeMethodNotAllowed     = "method_not_allowed"          // the route does not accept the method
+	CodeAlreadyExists        = "already_exists"              // a resource with that name exists
+	CodeConflict             = "conflict"                    // the request conflicts with current state
+	CodeSubredditUnavailable = "subreddit_unavailable"       // the ingestion API can't read the subreddit
+	CodeBodyTooLarge         = "body_too_large"              // body exceeds the endpoint's limit
+	CodeUnsupportedMedia     = "unsupported_media_type"      // body is not application/json
+	CodeRateLimited          = "rate_limited"                // too many requests, retry later
+	CodeInternal             = "internal_error"              // storage or the orchestrator failed
+	CodeUpstreamFailed       = "upstream_failed"             // the ingestion API failed
+	CodeUnavailable          = "unavailable"                 // a dependency such as storage is down
+	CodeTimeout              = "timeout"                     // the operation took too long
+)
+
+// ErrorResponse is the body of every API error response
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details carries endpoint-specific context, such as partial results
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// respondError writes an error response with the request's ID
+func respondError(c echo.Context, status int, code, message string, details interface{}) error {
+	return c.JSON(status, ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	})
+}
+
+func badRequest(c echo.Context, code, message string) error {
+	return respondError(c, http.StatusBadRequest, code, message, nil)
+}
+
+func unauthorized(c echo.Context, message string) error {
+	return respondError(c, http.StatusUnauthorized, CodeUnauthorized, message, nil)
+}
+
+func forbidden(c echo.Context, message string) error {
+	return respondError(c, http.StatusForbidden, CodeForbidden, message, nil)
+}
+
+func notFound(c echo.Context, code, message string) error {
+	return respondError(c, http.StatusNotFound, code, message, nil)
+}
+
+func conflict(c echo.Context, code, message string) error {
+	return respondError(c, http.StatusConflict, code, message, nil)
+}
+
+func unprocessable(c echo.Context, code, message string) error {
+	return respondError(c, http.StatusUnprocessableEntity, code, message, nil)
+}
+
+func tooManyRequests(c echo.Context, message string) error {
+	return respondError(c, http.StatusTooManyRequests, CodeRateLimited, message, nil)
+}
+
+// internalError reports a failure of storage or another dependency. A
+// filter the query package refused was built from the request, so it
+// answers 400.
+func internalError(c echo.Context, err error) error {
+	if errors.Is(err, query.ErrInvalid) {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	return respondError(c, http.StatusInternalServerError, CodeInternal, err.Error(), nil)
+}
+
+// invalidBody reports a body c.Bind could not decode: 415 when it is not
+// JSON, else 400
+func invalidBody(c echo.Context, err error) error {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code == http.StatusUnsupportedMediaType {
+		return respondError(c, http.StatusUnsupportedMediaType, CodeUnsupportedMedia, "request body must be application/json", nil)
+	}
+	return badRequest(c, CodeInvalidBody, "invalid request body")
+}
+
+// codeForStatus is the code of errors that carry only a status, such as the
+// ones Echo's middleware return
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusRequestEntityTooLarge:
+		return CodeBodyTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMedia
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	return CodeInternal
+}
+
+// handleErrors writes the error envelope for errors handlers and middleware
+// return instead of responding themselves, such as basic auth's 401, and
+// turns panics into 500s carrying the request ID
+func (s *Server) handleErrors(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Printf("Panic in %s %s request_id=%s: %v\n%s", c.Request().Method, c.Path(),
+					c.Response().Header().Get(echo.HeaderXRequestID), recovered, debug.Stack())
+				if c.Response().Committed {
+					err = nil
+					return
+				}
+				err = respondError(c, http.StatusInternalServerError, CodeInternal, "internal server error", nil)
+			}
+		}()
+
+		err = next(c)
+		if err == nil || c.Response().Committed {
+			return err
+		}
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			return respondError(c, httpErr.Code, codeForStatus(httpErr.Code), fmt.Sprint(httpErr.Message), nil)
+		}
+		return internalError(c, err)
+	}
+}
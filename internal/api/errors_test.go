@@ -0,0 +1,348 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+	"reddit-orchestrator/internal/tasks"
+)
+
+var errStorageDown = errors.New("storage is down")
+
+// envelopeStore finds nothing by name and fails listings, for the storage
+// calls the envelope cases reach beyond what Memory implements
+type envelopeStore struct {
+	*storagetest.Memory
+}
+
+func (envelopeStore) GetPostRevisions(ctx context.Context, redditID string) ([]models.PostRevision, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetDeletedSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetStagedConfigChanges(ctx context.Context) ([]models.StagedConfigChange, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) DiscardStagedConfigChanges(ctx context.Context) (int64, error) {
+	return 0, errStorageDown
+}
+
+func (envelopeStore) DeleteSubredditConfig(ctx context.Context, subredditName, actor string) (bool, error) {
+	return false, nil
+}
+
+func (envelopeStore) RestoreSubredditConfig(ctx context.Context, subredditName, actor string) (*models.SubredditConfig, error) {
+	return nil, nil
+}
+
+func (envelopeStore) GetConfigTemplates(ctx context.Context) ([]models.ConfigTemplate, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetConfigTemplate(ctx context.Context, name string) (*models.ConfigTemplate, error) {
+	return nil, nil
+}
+
+func (envelopeStore) DeleteConfigTemplate(ctx context.Context, name string) (bool, error) {
+	return false, nil
+}
+
+func (envelopeStore) GetRunPresets(ctx context.Context) ([]models.RunPreset, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetRunPreset(ctx context.Context, name string) (*models.RunPreset, error) {
+	return nil, nil
+}
+
+func (envelopeStore) DeleteRunPreset(ctx context.Context, name string) (bool, error) {
+	return false, nil
+}
+
+func (envelopeStore) GetAllUserConfigs(ctx context.Context) ([]models.UserConfig, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) DeleteUserConfig(ctx context.Context, username string) error {
+	return errStorageDown
+}
+
+func (envelopeStore) GetAllNotificationRules(ctx context.Context) ([]models.NotificationRule, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) DeleteNotificationRule(ctx context.Context, name string) error {
+	return errStorageDown
+}
+
+func (envelopeStore) GetDigest(ctx context.Context, label string) (*models.Digest, error) {
+	return nil, nil
+}
+
+func (envelopeStore) DeleteDigest(ctx context.Context, label string) (bool, error) {
+	return false, nil
+}
+
+func (envelopeStore) GetConsumers(ctx context.Context) ([]models.Consumer, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetConsumer(ctx context.Context, name string) (*models.Consumer, error) {
+	return nil, nil
+}
+
+func (envelopeStore) DeleteConsumer(ctx context.Context, name string) (bool, error) {
+	return false, nil
+}
+
+func (envelopeStore) GetAnomalies(ctx context.Context, subreddit string, limit int) ([]models.Anomaly, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetSubredditStorageStatsVersion(ctx context.Context) (storage.CollectionVersion, error) {
+	return storage.CollectionVersion{}, errStorageDown
+}
+
+func (envelopeStore) ClearMaintenancePause(ctx context.Context) (bool, error) {
+	return false, errStorageDown
+}
+
+func (envelopeStore) ExplainQueryShapes(ctx context.Context) ([]models.QueryPlanReport, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) OpsDataStats(ctx context.Context) ([]models.OpsDataStats, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) ExportBackup(ctx context.Context) (*models.Backup, error) {
+	return nil, errStorageDown
+}
+
+func (envelopeStore) GetLatestRawCapture(ctx context.Context, subreddit string) (*models.RawCapture, error) {
+	return nil, nil
+}
+
+// envelopeTasks refuses config commits while schedules are registered
+type envelopeTasks struct {
+	*fakeTasks
+}
+
+func (envelopeTasks) CommitStagedConfigs(ctx context.Context, actor string) (tasks.ConfigCommit, error) {
+	return tasks.ConfigCommit{}, tasks.ErrSchedulingInProgress
+}
+
+// TestErrorEnvelopeOnEveryRoute sends a representative failing request to
+// every API route and expects the error envelope back, with the request's
+// ID and the code clients branch on. Routes that cannot fail once the
+// caller is authenticated are covered by their 401.
+func TestErrorEnvelopeOnEveryRoute(t *testing.T) {
+	tests := []struct {
+		method, target, body string
+		noAuth               bool
+		status               int
+		code                 string
+	}{
+		{method: "GET", target: "/api/status", noAuth: true, status: 401, code: CodeUnauthorized},
+		{method: "GET", target: "/api/posts?limit=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/posts/t3_a/revisions", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/feed?since=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/subreddits?limit=-1", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/subreddits", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "GET", target: "/api/subreddits/deleted", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/subreddits/staged", status: 500, code: CodeInternal},
+		{method: "PUT", target: "/api/subreddits/staged", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/subreddits/staged", status: 500, code: CodeInternal},
+		{method: "POST", target: "/api/subreddits/staged/commit", status: 503, code: CodeUnavailable},
+		{method: "GET", target: "/api/subreddits/nope", status: 404, code: CodeSubredditNotFound},
+		{method: "DELETE", target: "/api/subreddits/nope", status: 404, code: CodeSubredditNotFound},
+		{method: "POST", target: "/api/subreddits/nope/restore", status: 404, code: CodeSubredditNotFound},
+		{method: "GET", target: "/api/subreddits/nope/audit?limit=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/subreddits/nope/volume?granularity=week", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/subreddits/nope/preview-filters", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "POST", target: "/api/labels/lang/explode", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/labels/nope/stats?days=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/metadata?limit=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/config-templates", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/config-templates/nope", status: 404, code: CodeTemplateNotFound},
+		{method: "PUT", target: "/api/config-templates/nope", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/config-templates/nope", status: 404, code: CodeTemplateNotFound},
+		{method: "GET", target: "/api/presets", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/presets/nope", status: 404, code: CodePresetNotFound},
+		{method: "PUT", target: "/api/presets/nope", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/presets/nope", status: 404, code: CodePresetNotFound},
+		{method: "POST", target: "/api/presets/nope/run", status: 404, code: CodePresetNotFound},
+		{method: "GET", target: "/api/users", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/users/nope", status: 404, code: CodeUserNotFound},
+		{method: "PUT", target: "/api/users/nope", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/users/nope", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/notification-rules", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/notification-rules/nope", status: 404, code: CodeRuleNotFound},
+		{method: "PUT", target: "/api/notification-rules/nope", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/notification-rules/nope", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/notification-log?limit=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/webhooks/deliveries?limit=abc", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/webhooks/deliveries/nope/replay", status: 404, code: CodeDeliveryNotFound},
+		{method: "GET", target: "/api/digests", noAuth: true, status: 401, code: CodeUnauthorized},
+		{method: "GET", target: "/api/digests/nope", status: 404, code: CodeDigestNotFound},
+		{method: "PUT", target: "/api/digests/nope", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/digests/nope", status: 404, code: CodeDigestNotFound},
+		{method: "GET", target: "/api/consumers", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/consumers/nope", status: 404, code: CodeConsumerNotFound},
+		{method: "PUT", target: "/api/consumers/nope", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "DELETE", target: "/api/consumers/nope", status: 404, code: CodeConsumerNotFound},
+		{method: "GET", target: "/api/consume/nope/posts", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/consume/nope/ack", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "GET", target: "/api/anomalies", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/reports/duplicate-urls?since=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/reports/priority-suggestions", noAuth: true, status: 401, code: CodeUnauthorized},
+		{method: "GET", target: "/api/qa/samples?limit=abc", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/queue", noAuth: true, status: 401, code: CodeUnauthorized},
+		{method: "GET", target: "/api/stats/storage", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/stats/domains?since=abc", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/admin/repair-metadata?dry_run=abc", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/admin/pause", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "POST", target: "/api/admin/resume", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/admin/explain", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/admin/ops-data", status: 500, code: CodeInternal},
+		{method: "GET", target: "/api/admin/notify-channels", noAuth: true, status: 401, code: CodeUnauthorized},
+		{method: "POST", target: "/api/admin/notify-test", body: "{}", status: 400, code: CodeInvalidRequest},
+		{method: "POST", target: "/api/admin/rebuild-rollups", status: 400, code: CodeInvalidRequest},
+		{method: "GET", target: "/api/admin/backup", status: 500, code: CodeInternal},
+		{method: "POST", target: "/api/admin/restore", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "GET", target: "/api/admin/features", noAuth: true, status: 401, code: CodeUnauthorized},
+		{method: "PATCH", target: "/api/admin/features", body: "{", status: 400, code: CodeInvalidBody},
+		{method: "GET", target: "/api/admin/captures/nope/latest", status: 404, code: CodeCaptureNotFound},
+		{method: "POST", target: "/api/ingest/nope", body: "{}", status: 404, code: CodeFeatureDisabled},
+	}
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	server := NewServer(testConfig(t), envelopeStore{storagetest.NewMemory(clocktest.NewFake(time.Now()))}, envelopeTasks{&fakeTasks{}}, nil, nil, nil)
+	e := echo.New()
+	server.RegisterRoutes(e)
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := serveEnvelopeCase(e, tt.method, tt.target, tt.body, tt.noAuth)
+			wantErrorEnvelope(t, rec, tt.status, tt.code)
+
+			ctx := e.NewContext(httptest.NewRequest(tt.method, tt.target, nil), nil)
+			e.Router().Find(tt.method, strings.SplitN(tt.target, "?", 2)[0], ctx)
+			covered[tt.method+" "+ctx.Path()] = true
+		})
+	}
+
+	for _, route := range e.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") || strings.HasPrefix(route.Method, "echo_") {
+			continue
+		}
+		if !covered[route.Method+" "+route.Path] {
+			t.Errorf("no envelope case for %s %s", route.Method, route.Path)
+		}
+	}
+	if strings.Contains(logged.String(), "Panic in") {
+		t.Errorf("a handler panicked:\n%s", logged.String())
+	}
+}
+
+// TestErrorEnvelopeUnauthenticated expects the 401 envelope from every
+// route behind basic auth
+func TestErrorEnvelopeUnauthenticated(t *testing.T) {
+	_, e := newTestServer(t, testConfig(t), storagetest.NewMemory(clocktest.NewFake(time.Now())))
+	for _, route := range e.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") || strings.HasPrefix(route.Method, "echo_") ||
+			route.Path == "/api/version" || route.Path == "/api/ingest/:subreddit" {
+			continue
+		}
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			rec := serveEnvelopeCase(e, route.Method, route.Path, "", true)
+			wantErrorEnvelope(t, rec, http.StatusUnauthorized, CodeUnauthorized)
+		})
+	}
+}
+
+// serveEnvelopeCase sends a request to e, authenticated unless noAuth
+func serveEnvelopeCase(e *echo.Echo, method, target, body string, noAuth bool) *httptest.ResponseRecorder {
+	if !noAuth {
+		return serve(e, method, target, body)
+	}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// wantErrorEnvelope fails the test unless rec is an error envelope with
+// status and code that carries the response's request ID
+func wantErrorEnvelope(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	wantStatus(t, rec, status)
+	if got := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(got, echo.MIMEApplicationJSON) {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("body %q is not a JSON object: %v", rec.Body.String(), err)
+	}
+	for field := range fields {
+		switch field {
+		case "code", "message", "details", "request_id":
+		default:
+			t.Errorf("envelope has unexpected field %q", field)
+		}
+	}
+	response := decodeErrorResponse(t, rec)
+	if response.Code != code {
+		t.Errorf("code = %q, want %q", response.Code, code)
+	}
+	if response.Message == "" {
+		t.Error("message is empty")
+	}
+	if id := rec.Header().Get(echo.HeaderXRequestID); response.RequestID == "" || response.RequestID != id {
+		t.Errorf("request_id = %q, want the X-Request-Id header %q", response.RequestID, id)
+	}
+}
+
+// invalidFilterStore refuses the filter a lookup builds, the way the query
+// package refuses a hostile value
+type invalidFilterStore struct {
+	envelopeStore
+}
+
+func (invalidFilterStore) GetPostRevisions(ctx context.Context, redditID string) ([]models.PostRevision, error) {
+	return nil, fmt.Errorf("%w: reddit_id must not start with an operator", query.ErrInvalid)
+}
+
+// TestInvalidFilterIsBadRequest answers a filter built from the request and
+// refused by the query package with 400, not 500
+func TestInvalidFilterIsBadRequest(t *testing.T) {
+	_, e := newTestServer(t, testConfig(t), invalidFilterStore{envelopeStore{storagetest.NewMemory(clocktest.NewFake(time.Now()))}})
+	rec := serve(e, http.MethodGet, "/api/posts/$where/revisions", "")
+	wantErrorEnvelope(t, rec, http.StatusBadRequest, CodeInvalidRequest)
+}
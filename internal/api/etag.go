@@ -0,0 +1,54 @@
+// internal/api/etag.go
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/storage"
+)
+
+// etagFor builds a weak ETag from the request URI (query params shape the
+// body) and the versions of the data behind the response
+func etagFor(c echo.Context, versions ...storage.CollectionVersion) string {
+	h := sha256.New()
+	fmt.Fprint(h, c.Request().URL.RequestURI())
+	for _, version := range versions {
+		fmt.Fprintf(h, "|%d|%d", version.Count, version.UpdatedAt.UnixNano())
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// notModified sets the ETag header and reports whether the request's
+// If-None-Match already has it, in which case the caller answers 304
+func notModified(c echo.Context, etag string) bool {
+	c.Response().Header().Set("ETag", etag)
+
+	ifNoneMatch := c.Request().Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+	// If-None-Match compares weakly
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl sets Cache-Control to max-age in seconds, or to no-cache
+// (revalidate with the ETag on every request) when seconds is 0
+func cacheControl(c echo.Context, seconds int) {
+	value := "no-cache"
+	if seconds > 0 {
+		value = fmt.Sprintf("private, max-age=%d", seconds)
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, value)
+}
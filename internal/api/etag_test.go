@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+// revalidate sends an authenticated GET carrying ifNoneMatch, if set
+func revalidate(e *echo.Echo, target, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.SetBasicAuth(testUser, testPassword)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// wantNotModified fails unless rec is a bodiless 304 carrying etag
+func wantNotModified(t *testing.T, rec *httptest.ResponseRecorder, etag string) {
+	t.Helper()
+	wantStatus(t, rec, http.StatusNotModified)
+	if rec.Body.Len() != 0 {
+		t.Errorf("304 has body %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("ETag"); got != etag {
+		t.Errorf("304 ETag = %q, want %q", got, etag)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `W/"abc"`
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "no header", ifNoneMatch: "", want: false},
+		{name: "same weak tag", ifNoneMatch: `W/"abc"`, want: true},
+		{name: "strong form of the tag", ifNoneMatch: `"abc"`, want: true},
+		{name: "in a list", ifNoneMatch: `"x", W/"abc"`, want: true},
+		{name: "wildcard", ifNoneMatch: "*", want: true},
+		{name: "other tag", ifNoneMatch: `W/"abd"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			if got := notModified(c, etag); got != tt.want {
+				t.Errorf("notModified() = %v, want %v", got, tt.want)
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag header = %q, want %q", got, etag)
+			}
+		})
+	}
+}
+
+func TestListSubredditsRevalidates(t *testing.T) {
+	ctx := context.Background()
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := storagetest.NewMemory(clk)
+	if err := store.UpsertSubredditConfig(ctx, &models.SubredditConfig{SubredditName: "golang", Enabled: true}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	_, e := newTestServer(t, testConfig(t), store)
+
+	first := revalidate(e, "/api/subreddits", "")
+	wantStatus(t, first, http.StatusOK)
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("listing has no ETag")
+	}
+	if got := first.Header().Get(echo.HeaderCacheControl); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+	wantNotModified(t, revalidate(e, "/api/subreddits", etag), etag)
+
+	// Query params shape the body, so they get their own tag
+	if rec := revalidate(e, "/api/subreddits?limit=1", etag); rec.Code != http.StatusOK {
+		t.Errorf("other query: status = %d, want 200", rec.Code)
+	}
+
+	// An upsert changes the config version and the old tag stops matching
+	clk.Advance(time.Second)
+	if err := store.UpsertSubredditConfig(ctx, &models.SubredditConfig{SubredditName: "golang", Enabled: false}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	updated := revalidate(e, "/api/subreddits", etag)
+	wantStatus(t, updated, http.StatusOK)
+	next := updated.Header().Get("ETag")
+	if next == etag {
+		t.Fatalf("ETag %q unchanged after an upsert", etag)
+	}
+	wantNotModified(t, revalidate(e, "/api/subreddits", next), next)
+
+	// So does a metadata change, as listings join metadata
+	store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", UpdatedAt: clk.Now().Add(time.Second)})
+	if rec := revalidate(e, "/api/subreddits", next); rec.Code != http.StatusOK || rec.Header().Get("ETag") == next {
+		t.Errorf("after a metadata change: status = %d, ETag = %q; want 200 with a new tag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+// statsStore serves storage stats at a version the test sets
+type statsStore struct {
+	*storagetest.Memory
+	version storage.CollectionVersion
+}
+
+func (s *statsStore) GetSubredditStorageStatsVersion(ctx context.Context) (storage.CollectionVersion, error) {
+	return s.version, nil
+}
+
+func (s *statsStore) GetSubredditStorageStats(ctx context.Context) ([]models.SubredditStorageStats, error) {
+	return []models.SubredditStorageStats{{Subreddit: "golang", Documents: s.version.Count}}, nil
+}
+
+func TestStorageStatsRevalidates(t *testing.T) {
+	refreshed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &statsStore{
+		Memory:  storagetest.NewMemory(clocktest.NewFake(refreshed)),
+		version: storage.CollectionVersion{Count: 1, UpdatedAt: refreshed},
+	}
+	cfg := testConfig(t)
+	cfg.StatsCacheMaxAge = 2 * time.Minute
+	_, e := newTestServer(t, cfg, store)
+
+	first := revalidate(e, "/api/stats/storage", "")
+	wantStatus(t, first, http.StatusOK)
+	etag := first.Header().Get("ETag")
+	if got := first.Header().Get(echo.HeaderCacheControl); got != "private, max-age=120" {
+		t.Errorf("Cache-Control = %q, want private, max-age=120", got)
+	}
+	wantNotModified(t, revalidate(e, "/api/stats/storage", etag), etag)
+
+	// The storage_stats task refreshes the footprints
+	store.version.UpdatedAt = refreshed.Add(time.Hour)
+	if rec := revalidate(e, "/api/stats/storage", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("after a refresh: status = %d, ETag = %q; want 200 with a new tag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
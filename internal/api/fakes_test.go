@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/tasks"
+)
+
+const (
+	testUser     = "admin"
+	testPassword = "a-long-enough-passphrase"
+)
+
+// testConfig loads the configuration defaults with basic auth as testUser
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("ENV", "production")
+	t.Setenv("WEB_AUTH_USER", testUser)
+	t.Setenv("WEB_AUTH_PASSWORD", testPassword)
+	t.Setenv("WEB_AUTH_PASSWORD_HASH", "")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	return cfg
+}
+
+// newTestServer mounts the API over store on a new Echo instance
+func newTestServer(t *testing.T, cfg *config.Config, store storage.StorageInterface) (*Server, *echo.Echo) {
+	t.Helper()
+	server := NewServer(cfg, store, nil, nil, nil, nil)
+	e := echo.New()
+	server.RegisterRoutes(e)
+	server.RegisterManagementRoutes(e, nil)
+	return server, e
+}
+
+// serve sends an authenticated request to e
+func serve(e *echo.Echo, method, target, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	req.SetBasicAuth(testUser, testPassword)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeErrorResponse decodes rec's body as an ErrorResponse
+func decodeErrorResponse(t *testing.T, rec *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+	var response ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("body %q is not an error response: %v", rec.Body.String(), err)
+	}
+	return response
+}
+
+// wantStatus fails the test unless rec has status
+func wantStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body.String())
+	}
+}
+
+// fakeTasks is a task manager whose unimplemented methods panic; tests set
+// the functions they expect to be called
+type fakeTasks struct {
+	tasks.TaskManagerInterface
+
+	mu         sync.Mutex
+	pushed     map[string][]models.IngestionPost
+	reconciled int
+}
+
+func (f *fakeTasks) IngestPushed(ctx context.Context, subredditName string, posts []models.IngestionPost) (models.RunStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pushed == nil {
+		f.pushed = make(map[string][]models.IngestionPost)
+	}
+	f.pushed[subredditName] = append(f.pushed[subredditName], posts...)
+	return models.RunStats{PostsFetched: len(posts), PostsProcessed: len(posts)}, nil
+}
+
+// pushedCount returns how many posts were pushed to subreddit
+func (f *fakeTasks) pushedCount(subreddit string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pushed[subreddit])
+}
+
+func (f *fakeTasks) ReconcileSchedules(ctx context.Context) (tasks.ScheduleReconciliation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconciled++
+	return tasks.ScheduleReconciliation{Added: 1}, nil
+}
+
+// reconciledCount returns how often schedules were reconciled
+func (f *fakeTasks) reconciledCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reconciled
+}
@@ -0,0 +1,84 @@
+// internal/api/feed_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Feed caps; the per-subreddit cap keeps one busy subreddit from crowding out
+// the rest and bounds what each subreddit query loads
+const (
+	defaultFeedWindow = 2 * time.Hour
+	maxFeedWindow     = 7 * 24 * time.Hour
+	defaultFeedPerSub = 5
+	maxFeedPerSub     = 50
+	defaultFeedLimit  = 100
+)
+
+// getFeed returns recent posts across all monitored subreddits, newest first.
+// Query params: since (a duration such as 2h, or RFC3339/epoch seconds;
+// default 2h, at most 7 days back), per_sub (posts per subreddit, default 5,
+// max 50), limit (total posts, default 100).
+func (s *Server) getFeed(c echo.Context) error {
+	since, err := feedSince(c)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	perSub := defaultFeedPerSub
+	if value := c.QueryParam("per_sub"); value != "" {
+		perSub, err = strconv.Atoi(value)
+		if err != nil || perSub <= 0 || perSub > maxFeedPerSub {
+			return badRequest(c, CodeInvalidRequest, fmt.Sprintf("per_sub must be an integer between 1 and %d", maxFeedPerSub))
+		}
+	}
+
+	limit, err := queryLimit(c, defaultFeedLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	posts, err := s.storage.GetRecentPostsAllSubreddits(c.Request().Context(), since, perSub, limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if posts == nil {
+		posts = []models.Post{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"since":   since,
+		"per_sub": perSub,
+		"posts":   posts,
+		"count":   len(posts),
+	})
+}
+
+// feedSince parses since as a look-back duration or an absolute time
+func feedSince(c echo.Context) (time.Time, error) {
+	now := time.Now().UTC()
+
+	since := now.Add(-defaultFeedWindow)
+	if value := c.QueryParam("since"); value != "" {
+		if window, err := time.ParseDuration(value); err == nil {
+			if window <= 0 {
+				return time.Time{}, fmt.Errorf("since must be a positive duration")
+			}
+			since = now.Add(-window)
+		} else if since, err = queryTime(c, "since", since); err != nil {
+			return time.Time{}, fmt.Errorf("since must be a duration (e.g. 2h), RFC3339 or epoch seconds")
+		}
+	}
+
+	if now.Sub(since) > maxFeedWindow {
+		return time.Time{}, fmt.Errorf("since must not be more than %v ago", maxFeedWindow)
+	}
+	return since, nil
+}
@@ -0,0 +1,99 @@
+// internal/api/filter_preview_handler.go
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/tasks"
+)
+
+const (
+	defaultPreviewPosts = 100
+	maxPreviewPosts     = 500
+	// previewTimeout bounds loading or fetching the posts of a preview
+	previewTimeout = 15 * time.Second
+)
+
+// previewFiltersRequest holds the candidate processing settings; unset
+// fields keep the stored config's values
+type previewFiltersRequest struct {
+	SkipNSFW       *bool            `json:"skip_nsfw"`
+	SkipSpoilers   *bool            `json:"skip_spoilers"`
+	MaxPostAgeDays *int             `json:"max_post_age_days"`
+	TagRules       []models.TagRule `json:"tag_rules"`
+	// Limit is how many posts to try, default 100, at most 500
+	Limit int `json:"limit"`
+	// Source is stored (default) or fetch for a fresh, unsaved fetch
+	Source string `json:"source"`
+}
+
+// previewFilters shows what candidate filter settings would do to a
+// subreddit's recent posts: kept and rejected counts per reason, tag counts
+// and a sample of rejected posts. Nothing is saved.
+func (s *Server) previewFilters(c echo.Context) error {
+	var req previewFiltersRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidBody(c, err)
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultPreviewPosts
+	}
+	if req.Limit < 0 || req.Limit > maxPreviewPosts {
+		return badRequest(c, CodeValidationFailed, fmt.Sprintf("limit must be between 1 and %d", maxPreviewPosts))
+	}
+	switch req.Source {
+	case "":
+		req.Source = tasks.PreviewSourceStored
+	case tasks.PreviewSourceStored, tasks.PreviewSourceFetch:
+	default:
+		return badRequest(c, CodeValidationFailed, "source must be stored or fetch")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), previewTimeout)
+	defer cancel()
+
+	name := c.Param("name")
+	stored, err := s.storage.GetSubredditConfig(ctx, name)
+	if err != nil {
+		return internalError(c, err)
+	}
+	config := models.SubredditConfig{SubredditName: name}
+	if stored != nil {
+		config = *stored
+	}
+	if req.SkipNSFW != nil {
+		config.SkipNSFW = *req.SkipNSFW
+	}
+	if req.SkipSpoilers != nil {
+		config.SkipSpoilers = *req.SkipSpoilers
+	}
+	if req.MaxPostAgeDays != nil {
+		config.MaxPostAgeDays = *req.MaxPostAgeDays
+	}
+	if req.TagRules != nil {
+		config.TagRules = req.TagRules
+	}
+	if err := config.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+
+	preview, err := s.tasks.PreviewFilters(ctx, config, req.Limit, req.Source)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return respondError(c, http.StatusGatewayTimeout, CodeTimeout, fmt.Sprintf("preview took longer than %s", previewTimeout), nil)
+	}
+	if err != nil {
+		if req.Source == tasks.PreviewSourceFetch {
+			return respondError(c, http.StatusBadGateway, CodeUpstreamFailed, err.Error(), nil)
+		}
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
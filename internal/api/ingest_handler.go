@@ -0,0 +1,135 @@
+// internal/api/ingest_handler.go
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// Push requests are signed with HMAC-SHA256 over "<timestamp>.<body>" using
+// INGEST_SECRET; the signature header is "sha256=<lowercase hex>"
+const (
+	IngestTimestampHeader = "X-Ingest-Timestamp"
+	IngestSignatureHeader = "X-Ingest-Signature"
+)
+
+// seenSignatures remembers accepted push digests for the signature window,
+// so a captured request cannot be replayed while its timestamp is still valid.
+// It is per instance; behind a load balancer a replay to another instance only
+// re-upserts the same posts.
+type seenSignatures struct {
+	mu   sync.Mutex
+	seen map[string]time.Time // lowercase hex digest -> expiry
+}
+
+// add records a digest until expiresAt and reports whether it was new
+func (s *seenSignatures) add(signature string, now, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen == nil {
+		s.seen = make(map[string]time.Time)
+	}
+	for sig, expiry := range s.seen {
+		if now.After(expiry) {
+			delete(s.seen, sig)
+		}
+	}
+	if _, ok := s.seen[signature]; ok {
+		return false
+	}
+	s.seen[signature] = expiresAt
+	return true
+}
+
+// ingestPush accepts posts pushed by the ingestion service in the same
+// format it serves, authenticated by an HMAC signature instead of basic
+// auth. The batch is stored like a scheduled run of the subreddit.
+func (s *Server) ingestPush(c echo.Context) error {
+	secret := s.config.IngestSecret
+	if secret == "" {
+		return notFound(c, CodeFeatureDisabled, "push ingestion is disabled")
+	}
+	subredditName := strings.TrimSpace(c.Param("subreddit"))
+	if subredditName == "" {
+		return badRequest(c, CodeInvalidRequest, "subreddit is required")
+	}
+
+	now := time.Now()
+	timestamp := c.Request().Header.Get(IngestTimestampHeader)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return unauthorized(c, IngestTimestampHeader+" must be a unix timestamp")
+	}
+	window := s.config.IngestSignatureWindow
+	if skew := now.Sub(time.Unix(signedAt, 0)); skew > window || skew < -window {
+		return unauthorized(c, "request timestamp is outside the signature window")
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, s.config.IngestMaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return respondError(c, http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "request body exceeds INGEST_MAX_BODY_BYTES", nil)
+		}
+		return badRequest(c, CodeInvalidBody, "reading request body: "+err.Error())
+	}
+
+	digest, ok := verifyIngestSignature(secret, timestamp, body, c.Request().Header.Get(IngestSignatureHeader))
+	if !ok {
+		return unauthorized(c, "invalid signature")
+	}
+	// Replays are matched on the digest, so a re-encoded header is the same
+	// request. The timestamp check covers the rest of the replay window.
+	if !s.pushSignatures.add(hex.EncodeToString(digest), now, time.Unix(signedAt, 0).Add(window)) {
+		return conflict(c, CodeConflict, "request was already accepted")
+	}
+
+	var posts []models.IngestionPost
+	if err := json.Unmarshal(body, &posts); err != nil {
+		return badRequest(c, CodeInvalidBody, "body must be a JSON array of posts: "+err.Error())
+	}
+
+	stats, err := s.tasks.IngestPushed(c.Request().Context(), subredditName, posts)
+	if errors.Is(err, tasks.ErrSubredditPaused) {
+		return conflict(c, CodeConflict, err.Error())
+	}
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// verifyIngestSignature checks a "sha256=<hex>" signature of
+// "<timestamp>.<body>" and returns its digest. The hex must be lowercase, so
+// each digest has exactly one accepted encoding.
+func verifyIngestSignature(secret, timestamp string, body []byte, signature string) ([]byte, bool) {
+	encoded, found := strings.CutPrefix(signature, "sha256=")
+	digest, err := hex.DecodeString(encoded)
+	if !found || err != nil || hex.EncodeToString(digest) != encoded {
+		return nil, false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if !hmac.Equal(digest, mac.Sum(nil)) {
+		return nil, false
+	}
+	return digest, true
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+const testIngestSecret = "push-secret"
+
+// signIngest returns the lowercase hex signature of a push
+func signIngest(timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(testIngestSecret))
+	mac.Write([]byte(timestamp + "." + body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestIngestPushSignatures(t *testing.T) {
+	body := `[{"id":"t3_a","title":"a","created_at":"2024-05-01T10:00:00Z"}]`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := signIngest(now, body)
+	upper := "sha256=" + strings.ToUpper(strings.TrimPrefix(signature, "sha256="))
+
+	type push struct {
+		timestamp  string
+		signature  string
+		body       string
+		wantStatus int
+	}
+	tests := []struct {
+		name       string
+		pushes     []push
+		wantPushed int
+	}{
+		{
+			name:       "signed push",
+			pushes:     []push{{now, signature, body, http.StatusOK}},
+			wantPushed: 1,
+		},
+		{
+			name:       "replay",
+			pushes:     []push{{now, signature, body, http.StatusOK}, {now, signature, body, http.StatusConflict}},
+			wantPushed: 1,
+		},
+		{
+			name:   "uppercase hex",
+			pushes: []push{{now, upper, body, http.StatusUnauthorized}},
+		},
+		{
+			name:       "uppercase replay of an accepted push",
+			pushes:     []push{{now, signature, body, http.StatusOK}, {now, upper, body, http.StatusUnauthorized}},
+			wantPushed: 1,
+		},
+		{
+			name:   "missing prefix",
+			pushes: []push{{now, strings.TrimPrefix(signature, "sha256="), body, http.StatusUnauthorized}},
+		},
+		{
+			name:   "tampered body",
+			pushes: []push{{now, signature, strings.Replace(body, `"a"`, `"b"`, 1), http.StatusUnauthorized}},
+		},
+		{
+			name: "stale timestamp",
+			pushes: func() []push {
+				stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+				return []push{{stale, signIngest(stale, body), body, http.StatusUnauthorized}}
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.IngestSecret = testIngestSecret
+			taskManager := &fakeTasks{}
+			server := NewServer(cfg, storagetest.NewMemory(clocktest.NewFake(time.Now())), taskManager, nil, nil, nil)
+			e := echo.New()
+			server.RegisterRoutes(e)
+
+			for i, p := range tt.pushes {
+				req := httptest.NewRequest(http.MethodPost, "/api/ingest/golang", strings.NewReader(p.body))
+				req.Header.Set(IngestTimestampHeader, p.timestamp)
+				req.Header.Set(IngestSignatureHeader, p.signature)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				if rec.Code != p.wantStatus {
+					t.Fatalf("push %d: status = %d, want %d; body %s", i, rec.Code, p.wantStatus, rec.Body.String())
+				}
+			}
+			if got := taskManager.pushedCount("golang"); got != tt.wantPushed {
+				t.Errorf("stored %d pushed posts, want %d", got, tt.wantPushed)
+			}
+		})
+	}
+}
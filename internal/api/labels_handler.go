@@ -0,0 +1,145 @@
+// internal/api/labels_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+const (
+	defaultLabelStatsDays = 7
+	maxLabelStatsDays     = 90
+)
+
+// labelActions set a field on every config of a label. Enable and disable take
+// effect at the next restart, when schedules are registered; pause and resume
+// take effect on each subreddit's next run.
+var labelActions = map[string]func(config *models.SubredditConfig){
+	"enable":  func(config *models.SubredditConfig) { config.Enabled = true },
+	"disable": func(config *models.SubredditConfig) { config.Enabled = false },
+	"pause":   func(config *models.SubredditConfig) { config.Paused = true },
+	"resume":  func(config *models.SubredditConfig) { config.Paused = false },
+}
+
+// applyLabelAction enables, disables, pauses or resumes every config of a label.
+// Each changed config is saved and audited on its own, so a failure part way
+// leaves the earlier configs changed; the response lists what was updated.
+// Enabling first checks every disabled config's subreddit with the ingestion
+// API and changes nothing if one is rejected, unless force=true is set.
+func (s *Server) applyLabelAction(c echo.Context) error {
+	apply, ok := labelActions[c.Param("action")]
+	if !ok {
+		return badRequest(c, CodeInvalidRequest, "action must be enable, disable, pause or resume")
+	}
+	force, err := queryBool(c, "force", false)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	label, err := models.NormalizeLabel(c.Param("label"))
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	configs, err := s.storage.GetSubredditConfigsByLabel(c.Request().Context(), label, storage.ListOptions{})
+	if err != nil {
+		return internalError(c, err)
+	}
+	if len(configs) == 0 {
+		return notFound(c, CodeLabelNotFound, "no subreddit configs carry this label")
+	}
+
+	ctx := c.Request().Context()
+	if c.Param("action") == "enable" && !force {
+		for _, config := range configs {
+			if config.Enabled {
+				continue
+			}
+			if status, err := s.validateSubreddit(ctx, config.SubredditName); err != nil {
+				return subredditRejected(c, status, err)
+			}
+		}
+	}
+
+	updated := make([]string, 0, len(configs))
+	warnings := []string{}
+	for i := range configs {
+		config := &configs[i]
+		wasEnabled := config.Enabled
+		apply(config)
+		if err := s.storage.UpsertSubredditConfig(ctx, config, actor(c)); err != nil {
+			return respondError(c, http.StatusInternalServerError, CodeInternal,
+				fmt.Sprintf("r/%s: %v", config.SubredditName, err), map[string]interface{}{"updated": updated})
+		}
+		updated = append(updated, config.SubredditName)
+		if !wasEnabled {
+			if warning := s.nsfwWarning(ctx, *config); warning != "" {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"label":    label,
+		"action":   c.Param("action"),
+		"updated":  updated,
+		"count":    len(updated),
+		"warnings": warnings,
+	})
+}
+
+// getLabelStats returns daily post volume across every subreddit of a label,
+// from the hourly rollups. Query params: days (default 7, max 90).
+func (s *Server) getLabelStats(c echo.Context) error {
+	days := defaultLabelStatsDays
+	if raw := c.QueryParam("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxLabelStatsDays {
+			return badRequest(c, CodeInvalidRequest, fmt.Sprintf("days must be between 1 and %d", maxLabelStatsDays))
+		}
+		days = parsed
+	}
+
+	label, err := models.NormalizeLabel(c.Param("label"))
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	configs, err := s.storage.GetSubredditConfigsByLabel(c.Request().Context(), label, storage.ListOptions{})
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	subreddits := make([]string, 0, len(configs))
+	for _, config := range configs {
+		subreddits = append(subreddits, config.SubredditName)
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-time.Duration(days) * 24 * time.Hour).Truncate(24 * time.Hour)
+	rollups, err := s.storage.GetRollupsForSubreddits(c.Request().Context(), subreddits, from, to)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	buckets := mergeRollups(rollups, 24*time.Hour)
+	total := 0
+	for _, bucket := range buckets {
+		total += bucket.PostCount
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"label":         label,
+		"subreddits":    subreddits,
+		"from":          from,
+		"to":            to,
+		"total_posts":   total,
+		"posts_per_day": float64(total) / float64(days),
+		"buckets":       buckets,
+	})
+}
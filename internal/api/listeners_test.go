@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// probeStore is healthy, for the readiness probe
+type probeStore struct {
+	*storagetest.Memory
+}
+
+func (probeStore) HealthInfo(ctx context.Context) (storage.HealthInfo, error) {
+	return storage.HealthInfo{Latency: time.Millisecond, Primary: true}, nil
+}
+
+// probeTasks has registered its schedules and a running scheduler
+type probeTasks struct {
+	*fakeTasks
+}
+
+func (probeTasks) ScheduleReport() tasks.ScheduleReport { return tasks.ScheduleReport{} }
+
+func (probeTasks) WatchdogStatus() tasks.WatchdogStatus { return tasks.WatchdogStatus{} }
+
+// okHandler stands in for the /metrics and /health BlueBerry mounts on the
+// API's Echo instance
+func okHandler(c echo.Context) error { return c.String(http.StatusOK, "ok") }
+
+// TestRoutesServedOnTheirListener wires the API and management listeners
+// the way app.Start does with MANAGEMENT_PORT set, and expects every route
+// on its own listener only
+func TestRoutesServedOnTheirListener(t *testing.T) {
+	server := NewServer(testConfig(t), probeStore{storagetest.NewMemory(clocktest.NewFake(time.Now()))}, probeTasks{&fakeTasks{}}, nil, nil, nil)
+
+	apiEcho := echo.New()
+	apiEcho.GET("/metrics", okHandler)
+	apiEcho.GET("/health", okHandler)
+	server.RegisterRoutes(apiEcho)
+	apiEcho.Pre(HideManagementRoutes)
+
+	management := echo.New()
+	server.RegisterManagementRoutes(management, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// ManagementPaths must name exactly what the management listener serves,
+	// or HideManagementRoutes leaves a probe on the API's port
+	var mounted []string
+	for _, route := range management.Routes() {
+		if !strings.HasPrefix(route.Method, "echo_") {
+			mounted = append(mounted, route.Path)
+		}
+	}
+	slices.Sort(mounted)
+	paths := slices.Sorted(slices.Values(ManagementPaths))
+	if !slices.Equal(mounted, paths) {
+		t.Fatalf("management listener serves %v, ManagementPaths lists %v", mounted, paths)
+	}
+
+	for _, path := range ManagementPaths {
+		t.Run("management "+path, func(t *testing.T) {
+			if rec := serveEnvelopeCase(management, http.MethodGet, path, "", true); rec.Code != http.StatusOK {
+				t.Errorf("management listener answered %d, want 200; body %s", rec.Code, rec.Body.String())
+			}
+			if rec := serve(apiEcho, http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+				t.Errorf("API listener answered %d, want 404", rec.Code)
+			}
+		})
+	}
+
+	for _, route := range apiEcho.Routes() {
+		if strings.HasPrefix(route.Method, "echo_") || slices.Contains(ManagementPaths, route.Path) {
+			continue
+		}
+		t.Run("api "+route.Method+" "+route.Path, func(t *testing.T) {
+			if rec := serve(management, route.Method, route.Path, ""); rec.Code != http.StatusNotFound {
+				t.Errorf("management listener answered %d, want 404", rec.Code)
+			}
+			ctx := apiEcho.NewContext(nil, nil)
+			apiEcho.Router().Find(route.Method, route.Path, ctx)
+			if ctx.Path() != route.Path {
+				t.Errorf("API listener routes %s to %q", route.Path, ctx.Path())
+			}
+		})
+	}
+}
+
+// TestManagementRoutesOnTheAPIListener serves the probes from the API's
+// listener when MANAGEMENT_PORT is unset
+func TestManagementRoutesOnTheAPIListener(t *testing.T) {
+	server := NewServer(testConfig(t), probeStore{storagetest.NewMemory(clocktest.NewFake(time.Now()))}, probeTasks{&fakeTasks{}}, nil, nil, nil)
+	e := echo.New()
+	server.RegisterRoutes(e)
+	server.RegisterManagementRoutes(e, nil)
+
+	for _, path := range []string{"/healthz", "/readyz", "/api/version", "/metrics/orchestrator"} {
+		if rec := serveEnvelopeCase(e, http.MethodGet, path, "", true); rec.Code != http.StatusOK {
+			t.Errorf("GET %s answered %d, want 200; body %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serveEnvelopeCase(e, http.MethodGet, "/api/posts", "", true); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/posts without credentials answered %d, want 401", rec.Code)
+	}
+}
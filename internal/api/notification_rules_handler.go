@@ -0,0 +1,90 @@
+// internal/api/notification_rules_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+const defaultNotificationLogLimit = 100
+
+// listNotificationRules lists all notification rules by name
+func (s *Server) listNotificationRules(c echo.Context) error {
+	rules, err := s.storage.GetAllNotificationRules(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+func (s *Server) getNotificationRule(c echo.Context) error {
+	rule, err := s.storage.GetNotificationRule(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if rule == nil {
+		return notFound(c, CodeRuleNotFound, "notification rule not found")
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// putNotificationRule creates or replaces a rule. The name comes from the path;
+// invalid regexes are rejected here rather than at evaluation time.
+func (s *Server) putNotificationRule(c echo.Context) error {
+	var rule models.NotificationRule
+	if err := c.Bind(&rule); err != nil {
+		return invalidBody(c, err)
+	}
+	rule.Name = strings.TrimSpace(c.Param("name"))
+
+	if err := rule.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if rule.WebhookURL != "" {
+		if err := s.webhooks.CheckURL(rule.WebhookURL); err != nil {
+			return badRequest(c, CodeValidationFailed, fmt.Sprintf("rule %q webhook_url: %v", rule.Name, err))
+		}
+	}
+
+	if err := s.storage.UpsertNotificationRule(c.Request().Context(), &rule); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+func (s *Server) deleteNotificationRule(c echo.Context) error {
+	if err := s.storage.DeleteNotificationRule(c.Request().Context(), c.Param("name")); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getNotificationLog returns the newest audit entries, optionally for one rule
+func (s *Server) getNotificationLog(c echo.Context) error {
+	limit, err := queryLimit(c, defaultNotificationLogLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	entries, err := s.storage.GetNotificationLog(c.Request().Context(), c.QueryParam("rule"), limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestPutNotificationRuleWebhookTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    []string
+		webhookURL string
+		wantStatus int
+	}{
+		{name: "public webhook", webhookURL: "https://hooks.example.com/rule", wantStatus: http.StatusOK},
+		{name: "loopback webhook", webhookURL: "http://127.0.0.1:27017/", wantStatus: http.StatusBadRequest},
+		{name: "metadata webhook", webhookURL: "http://169.254.169.254/latest", wantStatus: http.StatusBadRequest},
+		{name: "localhost webhook", webhookURL: "http://localhost/hook", wantStatus: http.StatusBadRequest},
+		{name: "allowlisted private webhook", allowed: []string{"10.0.0.7"}, webhookURL: "http://10.0.0.7/hook", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.WebhookAllowedHosts = tt.allowed
+			store := storagetest.NewMemory(clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+			_, e := newTestServer(t, cfg, store)
+
+			rec := serve(e, http.MethodPut, "/api/notification-rules/go", `{"enabled":true,"keywords":["go"],"webhook_url":"`+tt.webhookURL+`"}`)
+			wantStatus(t, rec, tt.wantStatus)
+			if tt.wantStatus == http.StatusBadRequest {
+				if got := decodeErrorResponse(t, rec).Code; got != CodeValidationFailed {
+					t.Errorf("code = %q, want %q", got, CodeValidationFailed)
+				}
+				if calls := store.Calls("UpsertNotificationRule"); calls != 0 {
+					t.Errorf("rule was stored %d times, want 0", calls)
+				}
+			}
+		})
+	}
+}
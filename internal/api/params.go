@@ -0,0 +1,243 @@
+// internal/api/params.go
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/robfig/cron/v3"
+
+	"reddit-orchestrator/internal/query"
+	"reddit-orchestrator/internal/storage"
+)
+
+// maxListLimit bounds every list endpoint's limit parameter
+const maxListLimit = 500
+
+// totalCountHeader carries how many items match a paged listing
+const totalCountHeader = "X-Total-Count"
+
+// queryLimit parses the limit query param, falling back to defaultLimit
+func queryLimit(c echo.Context, defaultLimit int) (int, error) {
+	limitStr := c.QueryParam("limit")
+	if limitStr == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > maxListLimit {
+		return 0, fmt.Errorf("limit must be an integer between 1 and %d", maxListLimit)
+	}
+	return limit, nil
+}
+
+// queryBool parses a boolean query param, falling back to defaultValue when absent
+func queryBool(c echo.Context, name string, defaultValue bool) (bool, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return parsed, nil
+}
+
+// splitQueryList flattens repeated and comma separated query values
+func splitQueryList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// querySet parses a comma separated query param whose items must be among
+// allowed into a set
+func querySet(c echo.Context, name string, allowed ...string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, item := range splitQueryList(c.QueryParams()[name]) {
+		if !slices.Contains(allowed, item) {
+			return nil, fmt.Errorf("%s must be a comma separated list of %s", name, strings.Join(allowed, ", "))
+		}
+		set[item] = true
+	}
+	return set, nil
+}
+
+// extrasFilters collects extras.<path>=value query params. Paths must be
+// plain dot paths (see query.CheckPath), keeping operators out of the filter.
+func extrasFilters(c echo.Context) (map[string]string, error) {
+	filters := make(map[string]string)
+	for key, values := range c.QueryParams() {
+		path, ok := strings.CutPrefix(key, "extras.")
+		if !ok {
+			continue
+		}
+		if query.CheckPath(path) != nil {
+			return nil, fmt.Errorf("invalid extras filter %q", key)
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf("extras filter %q must be given once", key)
+		}
+		filters[path] = values[0]
+	}
+	return filters, nil
+}
+
+// queryTime parses an RFC3339 or epoch-seconds query param, falling back to
+// defaultValue when absent
+func queryTime(c echo.Context, name string, defaultValue time.Time) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC(), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be RFC3339 or epoch seconds", name)
+	}
+	return parsed.UTC(), nil
+}
+
+// queryAge parses a query param giving a time span back from now, as a Go
+// duration ("36h") or in days ("7d"), falling back to defaultValue when absent
+func queryAge(c echo.Context, name string, defaultValue time.Duration) (time.Duration, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return parseAge(name, value)
+}
+
+// parseAge parses the value of name as a positive age such as 7d or 36h
+func parseAge(name, value string) (time.Duration, error) {
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a duration such as 7d or 36h", name)
+		}
+		age = time.Duration(count) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a duration such as 7d or 36h", name)
+		}
+		age = parsed
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("%s must be positive", name)
+	}
+	return age, nil
+}
+
+// queryLocation parses the tz query param used to render timestamps; storage
+// and bucketing stay in UTC
+func queryLocation(c echo.Context) (*time.Location, error) {
+	name := c.QueryParam("tz")
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("tz must be an IANA time zone such as Europe/Berlin")
+	}
+	return location, nil
+}
+
+// queryOffset parses the offset query param used for paging
+func queryOffset(c echo.Context) (int, error) {
+	offsetStr := c.QueryParam("offset")
+	if offsetStr == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("offset must be a non-negative integer")
+	}
+	return offset, nil
+}
+
+// listOptions builds paged storage options from limit/offset/detail params.
+// Unless detail=full, only summaryFields are returned.
+func listOptions(c echo.Context, defaultLimit int, summaryFields []string) (storage.ListOptions, error) {
+	limit, err := queryLimit(c, defaultLimit)
+	if err != nil {
+		return storage.ListOptions{}, err
+	}
+	offset, err := queryOffset(c)
+	if err != nil {
+		return storage.ListOptions{}, err
+	}
+
+	opts := storage.ListOptions{
+		Skip:  int64(offset),
+		Limit: int64(limit),
+	}
+
+	switch c.QueryParam("detail") {
+	case "", "summary":
+		opts.Fields = summaryFields
+	case "full":
+	default:
+		return storage.ListOptions{}, fmt.Errorf("detail must be summary or full")
+	}
+	return opts, nil
+}
+
+// pageParams applies page (1-based) and per_page params over limit/offset;
+// the two styles can't be mixed
+func pageParams(c echo.Context, opts *storage.ListOptions) error {
+	pageStr, perPageStr := c.QueryParam("page"), c.QueryParam("per_page")
+	if pageStr == "" && perPageStr == "" {
+		return nil
+	}
+	if c.QueryParam("limit") != "" || c.QueryParam("offset") != "" {
+		return fmt.Errorf("use either page and per_page or limit and offset")
+	}
+
+	page := 1
+	if pageStr != "" {
+		var err error
+		if page, err = strconv.Atoi(pageStr); err != nil || page < 1 {
+			return fmt.Errorf("page must be a positive integer")
+		}
+	}
+	if perPageStr != "" {
+		perPage, err := strconv.Atoi(perPageStr)
+		if err != nil || perPage <= 0 || perPage > maxListLimit {
+			return fmt.Errorf("per_page must be an integer between 1 and %d", maxListLimit)
+		}
+		opts.Limit = int64(perPage)
+	}
+	opts.Skip = int64(page-1) * opts.Limit
+	return nil
+}
+
+// checkSchedule rejects a schedule the scheduler could not register; empty
+// falls back to a global default and is accepted
+func checkSchedule(schedule string) error {
+	if schedule == "" {
+		return nil
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	return nil
+}
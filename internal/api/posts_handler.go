@@ -0,0 +1,147 @@
+// internal/api/posts_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/storage"
+)
+
+const (
+	defaultPostsLimit = 50
+	// maxPostsSubreddits bounds the $in list of one posts query
+	maxPostsSubreddits = 50
+)
+
+// getPosts lists stored posts matching a storage.PostFilter.
+// Query params: subreddit (repeatable or comma separated; results are
+// ordered across all of them) and/or author (one is required), flair,
+// flair_changed_since (posts whose flair changed at or after it; 0 for any
+// change), tag (repeatable or comma separated), min_score, from, to (RFC3339 or epoch
+// seconds, on created_at), q (case-insensitive text in title or body),
+// sort (new|old|top|inserted, default new), fields (comma separated post
+// fields to return, or -field to omit, e.g. fields=-body,-extras; omitted
+// fields come back empty), limit, cursor (next_cursor of the previous
+// page), include_deleted (default true), include_removed (default false;
+// posts that vanished from the subreddit), exclude_nsfw (default true; pass
+// false to include NSFW posts), import_batch (posts of one snapshot import),
+// domain (posts linking to a domain, matched by its registrable domain in
+// either Unicode or punycode, or self for self posts),
+// extras.<dot.path>=value to match enrichment fields.
+// With PRIVACY_MODE=hash, author is given in clear and matched by its hash.
+func (s *Server) getPosts(c echo.Context) error {
+	filter, err := postFilterFromQuery(c)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	if filter.Author != "" {
+		author, ok := s.authors.Query(filter.Author)
+		if !ok {
+			return badRequest(c, CodeFeatureDisabled, "author filters are unavailable with PRIVACY_MODE=drop")
+		}
+		filter.Author = author
+	}
+
+	page, err := s.storage.FindPosts(c.Request().Context(), filter)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	posts := page.Posts
+	if posts == nil {
+		posts = []models.Post{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"posts":       posts,
+		"count":       len(posts),
+		"next_cursor": page.NextCursor,
+	})
+}
+
+// postFilterFromQuery binds the posts endpoint's query params to a PostFilter
+func postFilterFromQuery(c echo.Context) (storage.PostFilter, error) {
+	filter := storage.PostFilter{
+		Subreddits:  splitQueryList(c.QueryParams()["subreddit"]),
+		Author:      strings.TrimSpace(c.QueryParam("author")),
+		Flair:       strings.TrimSpace(c.QueryParam("flair")),
+		ImportBatch: strings.TrimSpace(c.QueryParam("import_batch")),
+		Tags:        splitQueryList(c.QueryParams()["tag"]),
+		TextQuery:   strings.TrimSpace(c.QueryParam("q")),
+		Sort:        c.QueryParam("sort"),
+		Fields:      splitQueryList(c.QueryParams()["fields"]),
+		Cursor:      c.QueryParam("cursor"),
+	}
+	if len(filter.Subreddits) == 0 && filter.Author == "" {
+		return filter, fmt.Errorf("subreddit or author is required")
+	}
+	if len(filter.Subreddits) > maxPostsSubreddits {
+		return filter, fmt.Errorf("at most %d subreddits can be queried at once", maxPostsSubreddits)
+	}
+
+	var err error
+	if filter.Limit, err = queryLimit(c, defaultPostsLimit); err != nil {
+		return filter, err
+	}
+	if filter.IncludeDeleted, err = queryBool(c, "include_deleted", true); err != nil {
+		return filter, err
+	}
+	if filter.IncludeRemoved, err = queryBool(c, "include_removed", false); err != nil {
+		return filter, err
+	}
+	if filter.ExcludeNSFW, err = queryBool(c, "exclude_nsfw", true); err != nil {
+		return filter, err
+	}
+	if filter.Extras, err = extrasFilters(c); err != nil {
+		return filter, err
+	}
+	if domain := strings.TrimSpace(c.QueryParam("domain")); domain != "" {
+		if filter.Domain = processor.NormalizeDomain(domain); domain == models.DomainSelf {
+			filter.Domain = models.DomainSelf
+		} else if filter.Domain == "" {
+			return filter, fmt.Errorf("domain must be a host name or %s", models.DomainSelf)
+		}
+	}
+	if filter.TimeRange.From, err = queryTime(c, "from", time.Time{}); err != nil {
+		return filter, err
+	}
+	if filter.TimeRange.To, err = queryTime(c, "to", time.Time{}); err != nil {
+		return filter, err
+	}
+	if filter.FlairChangedSince, err = queryTime(c, "flair_changed_since", time.Time{}); err != nil {
+		return filter, err
+	}
+
+	if value := c.QueryParam("min_score"); value != "" {
+		minScore, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("min_score must be an integer")
+		}
+		filter.MinScore = &minScore
+	}
+
+	return filter, filter.Validate()
+}
+
+// getPostRevisions lists previous versions of an edited post, newest first
+func (s *Server) getPostRevisions(c echo.Context) error {
+	redditID := c.Param("reddit_id")
+
+	revisions, err := s.storage.GetPostRevisions(c.Request().Context(), redditID)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"reddit_id": redditID,
+		"revisions": revisions,
+		"count":     len(revisions),
+	})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/storage"
+)
+
+func TestPostFilterFromQuery(t *testing.T) {
+	minScore := 5
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    storage.PostFilter
+		wantErr string
+	}{
+		{
+			name:  "author with min score and time range",
+			query: "author=gopher&min_score=5&from=2024-05-01T00:00:00Z&to=1714694400&sort=top",
+			want: storage.PostFilter{
+				Author:         "gopher",
+				MinScore:       &minScore,
+				TimeRange:      storage.TimeRange{From: from, To: to},
+				Sort:           storage.PostSortTop,
+				Limit:          defaultPostsLimit,
+				IncludeDeleted: true,
+				ExcludeNSFW:    true,
+				Extras:         map[string]string{},
+			},
+		},
+		{
+			name:  "repeated and comma separated lists",
+			query: "subreddit=golang,rust&subreddit=python&tag=release&fields=-body,-extras&limit=10&include_deleted=false&exclude_nsfw=false",
+			want: storage.PostFilter{
+				Subreddits: []string{"golang", "rust", "python"},
+				Tags:       []string{"release"},
+				Fields:     []string{"-body", "-extras"},
+				Limit:      10,
+				Extras:     map[string]string{},
+			},
+		},
+		{name: "neither subreddit nor author", query: "flair=News", wantErr: "subreddit or author is required"},
+		{name: "non-integer min score", query: "author=gopher&min_score=high", wantErr: "min_score"},
+		{name: "unknown sort", query: "subreddit=golang&sort=hot", wantErr: "sort must be"},
+		{name: "cursor from another sort", query: "subreddit=golang&sort=old&cursor=eyJzIjoibmV3IiwidiI6MSwiaWQiOiI2NjNhMDAwMDAwMDAwMDAwMDAwMDAwMDEifQ", wantErr: "sort new"},
+		{name: "operator shaped author", query: "author=%24where", wantErr: "operator"},
+		{name: "too many subreddits", query: "subreddit=" + strings.Repeat("a,", maxPostsSubreddits) + "b", wantErr: "at most"},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/posts?"+tt.query, nil)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			got, err := postFilterFromQuery(c)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("postFilterFromQuery() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("postFilterFromQuery() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("postFilterFromQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
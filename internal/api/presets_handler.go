@@ -0,0 +1,101 @@
+// internal/api/presets_handler.go
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// listRunPresets lists run presets by name
+func (s *Server) listRunPresets(c echo.Context) error {
+	presets, err := s.storage.GetRunPresets(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"presets": presets,
+		"count":   len(presets),
+	})
+}
+
+func (s *Server) getRunPreset(c echo.Context) error {
+	preset, err := s.storage.GetRunPreset(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if preset == nil {
+		return notFound(c, CodePresetNotFound, "run preset not found")
+	}
+
+	return c.JSON(http.StatusOK, preset)
+}
+
+// putRunPreset creates or replaces a preset. The name comes from the path.
+func (s *Server) putRunPreset(c echo.Context) error {
+	var preset models.RunPreset
+	if err := c.Bind(&preset); err != nil {
+		return invalidBody(c, err)
+	}
+	preset.Name = strings.TrimSpace(c.Param("name"))
+
+	if err := preset.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if err := s.tasks.ValidatePreset(preset); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+
+	if err := s.storage.UpsertRunPreset(c.Request().Context(), &preset); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, preset)
+}
+
+// deleteRunPreset deletes a preset
+func (s *Server) deleteRunPreset(c echo.Context) error {
+	found, err := s.storage.DeleteRunPreset(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !found {
+		return notFound(c, CodePresetNotFound, "run preset not found")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// runPreset starts the preset's task with its placeholders filled in from
+// the query params, e.g. POST /api/presets/last-24h/run?subreddit=golang.
+// A missing value or invalid param is rejected before anything runs.
+func (s *Server) runPreset(c echo.Context) error {
+	preset, err := s.storage.GetRunPreset(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if preset == nil {
+		return notFound(c, CodePresetNotFound, "run preset not found")
+	}
+
+	values := make(map[string]string)
+	for name := range c.QueryParams() {
+		values[name] = c.QueryParam(name)
+	}
+
+	run, err := s.tasks.RunPreset(*preset, values)
+	if errors.Is(err, tasks.ErrInvalidPreset) {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, run)
+}
@@ -0,0 +1,33 @@
+// internal/api/qa_handler.go
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultQASamplesLimit = 50
+
+// getQASamples lists posts sampled for QA (see a config's sample_rate) with
+// their raw payload, processed post and stage outcomes, newest first.
+// Query params: subreddit (optional), limit.
+func (s *Server) getQASamples(c echo.Context) error {
+	subreddit := strings.TrimSpace(c.QueryParam("subreddit"))
+
+	limit, err := queryLimit(c, defaultQASamplesLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	samples, err := s.storage.GetQASamples(c.Request().Context(), subreddit, limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"samples": samples,
+		"count":   len(samples),
+	})
+}
@@ -0,0 +1,21 @@
+// internal/api/queue_handler.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// getQueue reports queued, running and recently finished monitor runs
+func (s *Server) getQueue(c echo.Context) error {
+	snapshot := s.tasks.QueueSnapshot()
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"max_concurrent": snapshot.MaxConcurrent,
+		"depth":          len(snapshot.Queued),
+		"queued":         snapshot.Queued,
+		"running":        snapshot.Running,
+		"finished":       snapshot.Finished,
+	})
+}
@@ -0,0 +1,105 @@
+// internal/api/reports_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// maxDuplicateURLAge bounds the since param of the duplicate URL report
+const maxDuplicateURLAge = 90 * 24 * time.Hour
+
+// getDuplicateURLReport lists external links posted to several monitored
+// subreddits, most widespread first. Query params: since (age such as 7d or
+// 36h, default DUPLICATE_URL_LOOKBACK), min_subs (default
+// DUPLICATE_URL_MIN_SUBREDDITS, at least 2).
+func (s *Server) getDuplicateURLReport(c echo.Context) error {
+	age, err := queryAge(c, "since", s.config.DuplicateURLLookback)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	if age > maxDuplicateURLAge {
+		return badRequest(c, CodeInvalidRequest, fmt.Sprintf("since must not exceed %dd", int(maxDuplicateURLAge.Hours()/24)))
+	}
+
+	minSubreddits := s.config.DuplicateURLMinSubreddits
+	if value := c.QueryParam("min_subs"); value != "" {
+		minSubreddits, err = strconv.Atoi(value)
+		if err != nil || minSubreddits < 2 {
+			return badRequest(c, CodeInvalidRequest, "min_subs must be an integer of at least 2")
+		}
+	}
+
+	since := time.Now().UTC().Add(-age)
+	report, err := s.storage.GetDuplicateURLReport(c.Request().Context(), since, minSubreddits)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"since":          since,
+		"min_subreddits": minSubreddits,
+		"urls":           report,
+		"count":          len(report),
+	})
+}
+
+// prioritySuggestion is a row of the priority suggestion report
+type prioritySuggestion struct {
+	Subreddit string `json:"subreddit"`
+	Priority  int    `json:"priority"`
+	Locked    bool   `json:"priority_locked"`
+	// Change is the suggested minus the current priority
+	Change     int                        `json:"change"`
+	Suggestion *models.PrioritySuggestion `json:"suggestion"`
+}
+
+// getPrioritySuggestions lists the priorities recompute_priorities suggested
+// for enabled subreddits, largest change first. Subreddits it has not scored
+// yet are left out.
+func (s *Server) getPrioritySuggestions(c echo.Context) error {
+	configs, err := s.storage.GetActiveSubredditConfigs(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	suggestions := []prioritySuggestion{}
+	for _, config := range configs {
+		if config.PrioritySuggestion == nil {
+			continue
+		}
+		suggestions = append(suggestions, prioritySuggestion{
+			Subreddit:  config.SubredditName,
+			Priority:   config.Priority,
+			Locked:     config.PriorityLocked,
+			Change:     config.PrioritySuggestion.Priority - config.Priority,
+			Suggestion: config.PrioritySuggestion,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i].Change, suggestions[j].Change
+		if a < 0 {
+			a = -a
+		}
+		if b < 0 {
+			b = -b
+		}
+		if a != b {
+			return a > b
+		}
+		return suggestions[i].Subreddit < suggestions[j].Subreddit
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"auto_apply":  s.config.AutoPriority,
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
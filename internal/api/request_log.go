@@ -0,0 +1,97 @@
+// internal/api/request_log.go
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/metrics"
+)
+
+// maxLoggedParamLength truncates query parameter values in slow request logs
+const maxLoggedParamLength = 64
+
+// quietRoutes are polled by probes and scrapers; they are only logged when
+// slow or failing, but still recorded in the route histograms
+var quietRoutes = map[string]bool{
+	"/readyz":               true,
+	"/metrics/orchestrator": true,
+}
+
+// logRequests logs each request with its route template, status, duration,
+// response size, principal and request ID, and records it in the per-route
+// latency histogram. Requests slower than SLOW_REQUEST_THRESHOLD are logged
+// as warnings with their query parameters.
+func (s *Server) logRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		if err := next(c); err != nil {
+			// Write the error response now so its status and size are logged
+			c.Error(err)
+		}
+		duration := time.Since(start)
+
+		req, res := c.Request(), c.Response()
+		route := c.Path()
+		metrics.ObserveRequest(req.Method, route, res.Status, duration)
+
+		slow := s.config.SlowRequestThreshold > 0 && duration >= s.config.SlowRequestThreshold
+		if quietRoutes[route] && !slow && res.Status < http.StatusBadRequest {
+			return nil
+		}
+
+		line := fmt.Sprintf("%s %s %d %v %dB principal=%s request_id=%s",
+			req.Method, route, res.Status, duration.Round(time.Millisecond), res.Size,
+			principal(c), res.Header().Get(echo.HeaderXRequestID))
+		if slow {
+			log.Printf("Warning: slow request: %s query=%s", line, truncatedQuery(c))
+			return nil
+		}
+		log.Printf("API %s", line)
+		return nil
+	}
+}
+
+// principal is the authenticated user, or "-" for unauthenticated routes and
+// rejected credentials
+func principal(c echo.Context) string {
+	if !strings.HasPrefix(c.Path(), "/api/") || c.Response().Status == http.StatusUnauthorized {
+		return "-"
+	}
+	if user := actor(c); user != "" {
+		return user
+	}
+	return "-"
+}
+
+// truncatedQuery renders the query parameters sorted by name, each value cut
+// to maxLoggedParamLength
+func truncatedQuery(c echo.Context) string {
+	params := c.QueryParams()
+	if len(params) == 0 {
+		return "-"
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range params[name] {
+			if len(value) > maxLoggedParamLength {
+				value = value[:maxLoggedParamLength] + "…"
+			}
+			parts = append(parts, name+"="+value)
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,205 @@
+// internal/api/server.go
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/crypto/bcrypt"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/leader"
+	"reddit-orchestrator/internal/metrics"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/privacy"
+	"reddit-orchestrator/internal/runstate"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// Server exposes orchestrator data next to the BlueBerry dashboard
+type Server struct {
+	config   *config.Config
+	storage  storage.StorageInterface
+	tasks    tasks.TaskManagerInterface
+	elector  leader.ElectorInterface
+	client   client.IngestionClientInterface
+	runState runstate.RecorderInterface
+	// Maps author filters to their stored form under PRIVACY_MODE
+	authors *privacy.Authors
+	// Rejects rule and digest webhooks aimed at internal addresses
+	webhooks *notify.WebhookGuard
+
+	// Accepted push signatures, for replay protection
+	pushSignatures seenSignatures
+	// Recent answers of the ingestion API about subreddits being onboarded
+	subredditValidations subredditValidations
+	// The password last verified against WebAuthPasswordHash
+	verifiedPassword verifiedPassword
+}
+
+func NewServer(cfg *config.Config, storage storage.StorageInterface, taskManager tasks.TaskManagerInterface, elector leader.ElectorInterface, ingestionClient client.IngestionClientInterface, runState runstate.RecorderInterface) *Server {
+	return &Server{
+		config:   cfg,
+		storage:  storage,
+		tasks:    taskManager,
+		elector:  elector,
+		client:   ingestionClient,
+		runState: runState,
+		authors:  privacy.NewAuthors(cfg.PrivacyMode, cfg.PrivacyHashKey),
+		webhooks: notify.NewWebhookGuard(cfg.WebhookAllowedHosts),
+	}
+}
+
+// RegisterRoutes mounts the orchestrator API on the given Echo instance.
+// Every route gets a request ID, is logged by logRequests and answers errors
+// and panics with an ErrorResponse.
+func (s *Server) RegisterRoutes(e *echo.Echo) {
+	requestID := middleware.RequestID()
+	api := e.Group("/api", requestID, s.logRequests, s.handleErrors, middleware.BasicAuth(s.authenticate))
+
+	api.GET("/status", s.getStatus)
+	api.GET("/posts", s.getPosts)
+	api.GET("/posts/:reddit_id/revisions", s.getPostRevisions)
+	api.GET("/feed", s.getFeed)
+	api.GET("/subreddits", s.listSubreddits)
+	api.POST("/subreddits", s.createSubreddit)
+	api.GET("/subreddits/deleted", s.listDeletedSubreddits)
+	api.GET("/subreddits/staged", s.listStagedSubreddits)
+	api.PUT("/subreddits/staged", s.stageSubreddits)
+	api.DELETE("/subreddits/staged", s.discardStagedSubreddits)
+	api.POST("/subreddits/staged/commit", s.commitStagedSubreddits)
+	api.GET("/subreddits/:name", s.getSubreddit)
+	api.DELETE("/subreddits/:name", s.deleteSubreddit)
+	api.POST("/subreddits/:name/restore", s.restoreSubreddit)
+	api.GET("/subreddits/:name/audit", s.getSubredditAudit)
+	api.GET("/subreddits/:name/volume", s.getSubredditVolume)
+	api.POST("/subreddits/:name/preview-filters", s.previewFilters)
+	api.POST("/labels/:label/:action", s.applyLabelAction)
+	api.GET("/labels/:label/stats", s.getLabelStats)
+	api.GET("/metadata", s.listMetadata)
+
+	api.GET("/config-templates", s.listConfigTemplates)
+	api.GET("/config-templates/:name", s.getConfigTemplate)
+	api.PUT("/config-templates/:name", s.putConfigTemplate)
+	api.DELETE("/config-templates/:name", s.deleteConfigTemplate)
+	api.GET("/presets", s.listRunPresets)
+	api.GET("/presets/:name", s.getRunPreset)
+	api.PUT("/presets/:name", s.putRunPreset)
+	api.DELETE("/presets/:name", s.deleteRunPreset)
+	api.POST("/presets/:name/run", s.runPreset)
+
+	api.GET("/users", s.listUsers)
+	api.GET("/users/:username", s.getUser)
+	api.PUT("/users/:username", s.putUser)
+	api.DELETE("/users/:username", s.deleteUser)
+
+	api.GET("/notification-rules", s.listNotificationRules)
+	api.GET("/notification-rules/:name", s.getNotificationRule)
+	api.PUT("/notification-rules/:name", s.putNotificationRule)
+	api.DELETE("/notification-rules/:name", s.deleteNotificationRule)
+	api.GET("/notification-log", s.getNotificationLog)
+	api.GET("/webhooks/deliveries", s.listWebhookDeliveries)
+	api.POST("/webhooks/deliveries/:id/replay", s.replayWebhookDelivery)
+	api.GET("/digests", s.listDigests)
+	api.GET("/digests/:label", s.getDigest)
+	api.PUT("/digests/:label", s.putDigest)
+	api.DELETE("/digests/:label", s.deleteDigest)
+
+	api.GET("/consumers", s.listConsumers)
+	api.GET("/consumers/:consumer", s.getConsumer)
+	api.PUT("/consumers/:consumer", s.putConsumer)
+	api.DELETE("/consumers/:consumer", s.deleteConsumer)
+	api.GET("/consume/:consumer/posts", s.consumePosts)
+	api.POST("/consume/:consumer/ack", s.ackConsumerBatch)
+
+	api.GET("/anomalies", s.getAnomalies)
+	api.GET("/reports/duplicate-urls", s.getDuplicateURLReport)
+	api.GET("/reports/priority-suggestions", s.getPrioritySuggestions)
+	api.GET("/qa/samples", s.getQASamples)
+	api.GET("/queue", s.getQueue)
+	api.GET("/stats/storage", s.getStorageStats)
+	api.GET("/stats/domains", s.getDomainStats)
+
+	api.POST("/admin/repair-metadata", s.repairMetadata)
+	api.POST("/admin/pause", s.pauseIngestion)
+	api.POST("/admin/resume", s.resumeIngestion)
+	api.GET("/admin/explain", s.explainQueries)
+	api.GET("/admin/ops-data", s.getOpsData)
+	api.GET("/admin/notify-channels", s.listNotifyChannels)
+	api.POST("/admin/notify-test", s.testNotifyChannel)
+	api.POST("/admin/rebuild-rollups", s.rebuildRollups)
+	api.GET("/admin/backup", s.getBackup)
+	api.POST("/admin/restore", s.restoreBackup)
+	api.GET("/admin/features", s.listFeatures)
+	api.PATCH("/admin/features", s.patchFeatures)
+	api.GET("/admin/captures/:subreddit/latest", s.getLatestCapture)
+
+	// Pushed batches authenticate with an HMAC signature instead of basic auth
+	e.POST("/api/ingest/:subreddit", s.ingestPush, requestID, s.logRequests, s.handleErrors)
+
+}
+
+// ManagementPaths are the routes RegisterManagementRoutes mounts, including
+// BlueBerry's own /health and /metrics
+var ManagementPaths = []string{"/healthz", "/readyz", "/metrics", "/metrics/orchestrator", "/api/version", "/health"}
+
+// RegisterManagementRoutes mounts the unauthenticated probes, metrics and
+// version on e, which is the API's Echo instance unless MANAGEMENT_PORT moves
+// them to their own listener. bbMetrics serves BlueBerry's registry; it is nil
+// on the API's instance, where BlueBerry mounts /metrics and /health itself.
+func (s *Server) RegisterManagementRoutes(e *echo.Echo, bbMetrics http.Handler) {
+	requestID := middleware.RequestID()
+
+	e.GET("/healthz", s.getLiveness, requestID, s.handleErrors)
+	e.GET("/readyz", s.getReadiness, requestID, s.logRequests, s.handleErrors)
+	e.GET("/api/version", s.getVersion, requestID, s.logRequests, s.handleErrors)
+	// BlueBerry serves its own registry on /metrics; ours sits next to it
+	e.GET("/metrics/orchestrator", echo.WrapHandler(metrics.Handler()), requestID, s.logRequests, s.handleErrors)
+	if bbMetrics != nil {
+		e.GET("/metrics", echo.WrapHandler(bbMetrics), requestID, s.logRequests, s.handleErrors)
+		e.GET("/health", s.getLiveness, requestID, s.handleErrors)
+	}
+}
+
+// HideManagementRoutes answers 404 for the ManagementPaths, for the API's
+// Echo instance when they are served on MANAGEMENT_PORT instead. It is
+// middleware for Echo.Pre, as BlueBerry mounts some of them itself.
+func HideManagementRoutes(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if slices.Contains(ManagementPaths, c.Request().URL.Path) {
+			return echo.ErrNotFound
+		}
+		return next(c)
+	}
+}
+
+// authenticate checks basic auth credentials against the web auth config,
+// verifying the password against WebAuthPasswordHash when one is set. A
+// password bcrypt accepted is taken from verifiedPassword until it expires.
+func (s *Server) authenticate(username, password string, c echo.Context) (bool, error) {
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(s.config.WebAuthUser)) == 1
+	var passOK bool
+	if s.config.WebAuthPasswordHash != "" {
+		now := time.Now()
+		passOK = s.verifiedPassword.matches(password, now)
+		if !passOK && bcrypt.CompareHashAndPassword([]byte(s.config.WebAuthPasswordHash), []byte(password)) == nil {
+			passOK = true
+			s.verifiedPassword.store(password, now)
+		}
+	} else {
+		passOK = subtle.ConstantTimeCompare([]byte(password), []byte(s.config.WebAuthPassword)) == 1
+	}
+	return userOK && passOK, nil
+}
+
+// actor is the authenticated user behind a request, for audit records
+func actor(c echo.Context) string {
+	user, _, _ := c.Request().BasicAuth()
+	return user
+}
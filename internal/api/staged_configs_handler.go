@@ -0,0 +1,198 @@
+// internal/api/staged_configs_handler.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// stageSubredditsRequest is the body of PUT /api/subreddits/staged
+type stageSubredditsRequest struct {
+	Changes []stagedChangeRequest `json:"changes"`
+}
+
+// stagedChangeRequest changes the fields it sets, as POST /api/subreddits
+// does, or deletes the config
+type stagedChangeRequest struct {
+	createSubredditRequest
+	Delete bool `json:"delete"`
+}
+
+// stageSubreddits adds a batch of config changes to the staging area, where
+// they wait for POST /api/subreddits/staged/commit. A change to a subreddit
+// that is already staged applies on top of the staged config. Each change is
+// validated on its own here; the commit validates them together. New enabled
+// configs are checked against the ingestion API unless force=true.
+func (s *Server) stageSubreddits(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	force, err := queryBool(c, "force", false)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	var req stageSubredditsRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidBody(c, err)
+	}
+	if len(req.Changes) == 0 || len(req.Changes) > maxListLimit {
+		return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes must list 1 to %d changes", maxListLimit))
+	}
+
+	current, err := s.storage.GetStagedConfigChanges(ctx)
+	if err != nil {
+		return internalError(c, err)
+	}
+	staged := make(map[string]models.StagedConfigChange, len(current))
+	for _, change := range current {
+		staged[change.SubredditName] = change
+	}
+
+	changes := make([]models.StagedConfigChange, 0, len(req.Changes))
+	seen := make(map[string]bool, len(req.Changes))
+	for i, change := range req.Changes {
+		name := strings.TrimSpace(change.SubredditName)
+		if name == "" {
+			return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes[%d]: subreddit_name is required", i))
+		}
+		if seen[name] {
+			return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes[%d]: r/%s is changed twice", i, name))
+		}
+		seen[name] = true
+
+		live, err := s.storage.GetSubredditConfig(ctx, name)
+		if err != nil {
+			return internalError(c, err)
+		}
+		next := models.StagedConfigChange{SubredditName: name, Delete: change.Delete, StagedBy: actor(c)}
+		// The base stays the live config seen when the subreddit was first
+		// staged, so the commit notices edits made to it since
+		previous, restaged := staged[name]
+		if restaged {
+			next.BaseUpdatedAt = previous.BaseUpdatedAt
+		} else if live != nil {
+			updatedAt := live.UpdatedAt
+			next.BaseUpdatedAt = &updatedAt
+		}
+
+		if change.Delete {
+			if live == nil {
+				return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes[%d]: r/%s has no config to delete", i, name))
+			}
+			changes = append(changes, next)
+			continue
+		}
+
+		config := models.SubredditConfig{SubredditName: name, Enabled: true}
+		switch {
+		case restaged && previous.Config != nil:
+			config = *previous.Config
+		case live != nil:
+			config = *live
+		}
+		isNew := config.CreatedAt.IsZero()
+		if change.Template != "" {
+			if !isNew {
+				return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes[%d]: template only applies to new configs", i))
+			}
+			template, err := s.storage.GetConfigTemplate(ctx, change.Template)
+			if err != nil {
+				return internalError(c, err)
+			}
+			if template == nil {
+				return badRequest(c, CodeUnknownTemplate, "unknown template "+change.Template)
+			}
+			config = template.NewConfig(name)
+		}
+		change.apply(&config)
+
+		if config.MaxPosts <= 0 {
+			config.MaxPosts = s.config.DefaultLimit
+		}
+		if err := config.Validate(); err != nil {
+			return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes[%d]: %v", i, err))
+		}
+		if err := checkSchedule(config.Schedule); err != nil {
+			return badRequest(c, CodeInvalidSchedule, fmt.Sprintf("changes[%d]: %v", i, err))
+		}
+		if err := models.ValidateMaxPosts(config.MaxPosts, s.config.MaxPostsCeiling); err != nil {
+			return badRequest(c, CodeValidationFailed, fmt.Sprintf("changes[%d]: %v", i, err))
+		}
+		if isNew && config.Enabled && !force {
+			if status, err := s.validateSubreddit(ctx, name); err != nil {
+				return subredditRejected(c, status, err)
+			}
+		}
+		next.Config = &config
+		changes = append(changes, next)
+	}
+
+	for _, change := range changes {
+		if err := s.storage.StageConfigChange(ctx, change); err != nil {
+			return internalError(c, err)
+		}
+	}
+	return s.listStagedSubreddits(c)
+}
+
+// listStagedSubreddits lists the staged changes with each one's action,
+// its field changes against the live config and any conflict with edits
+// made to the live config since it was staged
+func (s *Server) listStagedSubreddits(c echo.Context) error {
+	changes, err := s.storage.GetStagedConfigChanges(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	conflicts := 0
+	for _, change := range changes {
+		if change.Conflict != "" {
+			conflicts++
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"changes":   changes,
+		"count":     len(changes),
+		"conflicts": conflicts,
+	})
+}
+
+// discardStagedSubreddits empties the staging area
+func (s *Server) discardStagedSubreddits(c echo.Context) error {
+	discarded, err := s.storage.DiscardStagedConfigChanges(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"discarded": discarded})
+}
+
+// commitStagedSubreddits applies every staged change and reconciles the
+// subreddit schedules once. Invalid sets answer 422 with every problem in
+// details and apply nothing; a storage failure partway answers 500 with what
+// was applied, the rest staying staged.
+func (s *Server) commitStagedSubreddits(c echo.Context) error {
+	commit, err := s.tasks.CommitStagedConfigs(c.Request().Context(), actor(c))
+	var invalid *tasks.ConfigCommitError
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, commit)
+	case errors.Is(err, tasks.ErrNothingStaged):
+		return conflict(c, CodeConflict, err.Error())
+	case errors.Is(err, tasks.ErrSchedulingInProgress):
+		return respondError(c, http.StatusServiceUnavailable, CodeUnavailable, err.Error()+", retry shortly", nil)
+	case errors.As(err, &invalid):
+		return respondError(c, http.StatusUnprocessableEntity, CodeValidationFailed, "staged changes are invalid; nothing was applied", map[string]interface{}{
+			"problems":                invalid.Problems,
+			"scheduled_runs_per_hour": commit.ScheduledRunsPerHour,
+		})
+	default:
+		return respondError(c, http.StatusInternalServerError, CodeInternal, err.Error(), commit)
+	}
+}
@@ -0,0 +1,108 @@
+// internal/api/status_handler.go
+package api
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/storage"
+)
+
+// getStatus reports which instance this is and whether it runs schedules.
+// With HA_MODE=on, role is leader or follower and leader_id names the lease
+// holder; with HA_MODE=off, role is single. schedules is the startup
+// schedule registration report, with in_progress set while subreddit schedules
+// register; storage_health is null when storage is down. maintenance_pause
+// is the active maintenance pause, or null when there is none.
+func (s *Server) getStatus(c echo.Context) error {
+	storageStatus := "ok"
+	var storageHealth *storage.HealthInfo
+	if health, err := s.storage.HealthInfo(c.Request().Context()); err != nil {
+		storageStatus = err.Error()
+	} else {
+		storageHealth = &health
+	}
+	// Left null when storage is down, which storage already reports
+	pause, _ := s.storage.GetMaintenancePause(c.Request().Context())
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"leadership":        s.elector.Status(),
+		"storage":           storageStatus,
+		"storage_health":    storageHealth,
+		"queue_depth":       len(s.tasks.QueueSnapshot().Queued),
+		"schedules":         s.tasks.ScheduleReport(),
+		"runtime":           s.runState.Status(),
+		"maintenance_pause": pause,
+	})
+}
+
+// getLiveness is the unauthenticated liveness probe; it answers 200 as long
+// as the process serves requests, whatever the state of its dependencies
+func (s *Server) getLiveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// getVersion reports the build: module version, VCS revision and time when
+// built from a checkout, and the Go version
+func (s *Server) getVersion(c echo.Context) error {
+	version := map[string]interface{}{"go": runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version["version"] = info.Main.Version
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				version["revision"] = setting.Value
+			case "vcs.time":
+				version["build_time"] = setting.Value
+			case "vcs.modified":
+				version["modified"] = setting.Value == "true"
+			}
+		}
+	}
+	return c.JSON(http.StatusOK, version)
+}
+
+// getReadiness is the unauthenticated readiness probe. It answers 503 when
+// storage is down, or degraded because its round trip exceeds
+// READY_LATENCY_THRESHOLD, with READY_REQUIRES_SCHEDULES while subreddit
+// schedules are still registering and with READY_FAILS_WHEN_PAUSED during a
+// maintenance pause; the body says which.
+func (s *Server) getReadiness(c echo.Context) error {
+	health, err := s.storage.HealthInfo(c.Request().Context())
+	if err != nil {
+		return respondError(c, http.StatusServiceUnavailable, CodeUnavailable, err.Error(), map[string]string{"status": "unavailable"})
+	}
+	pause, err := s.storage.GetMaintenancePause(c.Request().Context())
+	if err != nil {
+		return respondError(c, http.StatusServiceUnavailable, CodeUnavailable, err.Error(), map[string]string{"status": "unavailable"})
+	}
+
+	watchdog := s.tasks.WatchdogStatus()
+	schedules := s.tasks.ScheduleReport()
+	status, code := "ready", http.StatusOK
+	switch {
+	case schedules.InProgress && s.config.ReadyRequiresSchedules:
+		status, code = "scheduling", http.StatusServiceUnavailable
+	case pause != nil && s.config.ReadyFailsWhenPaused:
+		status, code = "paused", http.StatusServiceUnavailable
+	case watchdog.Stalled:
+		status, code = "scheduler_stalled", http.StatusServiceUnavailable
+	case health.Latency > s.config.ReadyLatencyThreshold:
+		status, code = "degraded", http.StatusServiceUnavailable
+	}
+	return c.JSON(code, map[string]interface{}{
+		"status":            status,
+		"storage":           health,
+		"watchdog":          watchdog,
+		"maintenance_pause": pause,
+		"scheduling": map[string]interface{}{
+			"in_progress": schedules.InProgress,
+			"subreddits":  schedules.Subreddits,
+			"registered":  schedules.Registered,
+			"failed":      schedules.Failed,
+		},
+	})
+}
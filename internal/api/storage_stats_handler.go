@@ -0,0 +1,46 @@
+// internal/api/storage_stats_handler.go
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// getStorageStats returns the per-subreddit storage footprints cached by the
+// storage_stats task, largest first. It is empty until the task first runs.
+// The footprints only change when the task refreshes them, so clients may
+// cache the response for StatsCacheMaxAge and revalidate with its ETag.
+func (s *Server) getStorageStats(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	version, err := s.storage.GetSubredditStorageStatsVersion(ctx)
+	if err != nil {
+		return internalError(c, err)
+	}
+	cacheControl(c, int(s.config.StatsCacheMaxAge.Seconds()))
+	if notModified(c, etagFor(c, version)) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	stats, err := s.storage.GetSubredditStorageStats(ctx)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	var documents, bytes int64
+	for _, stat := range stats {
+		documents += stat.Documents
+		bytes += stat.Bytes
+	}
+	response := map[string]interface{}{
+		"subreddits":      stats,
+		"total_documents": documents,
+		"total_bytes":     bytes,
+		"computed_at":     nil,
+	}
+	if len(stats) > 0 {
+		response["computed_at"] = stats[0].ComputedAt
+	}
+	return c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,125 @@
+// internal/api/subreddit_validation.go
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/models"
+)
+
+const (
+	// subredditValidationTTL is how long a probe's answer is reused
+	subredditValidationTTL = 10 * time.Minute
+	// subredditValidationTimeout bounds one probe of the ingestion API
+	subredditValidationTimeout = 10 * time.Second
+)
+
+// subredditValidations caches ValidateSubreddit answers per subreddit, so
+// retrying a rejected save or enabling a label does not probe again. Failed
+// probes are not cached.
+type subredditValidations struct {
+	mu      sync.Mutex
+	results map[string]subredditValidation
+}
+
+type subredditValidation struct {
+	err       error // nil when the subreddit is readable
+	expiresAt time.Time
+}
+
+func (v *subredditValidations) get(subreddit string, now time.Time) (error, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	result, ok := v.results[subreddit]
+	if !ok || now.After(result.expiresAt) {
+		return nil, false
+	}
+	return result.err, true
+}
+
+func (v *subredditValidations) put(subreddit string, err error, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.results == nil {
+		v.results = make(map[string]subredditValidation)
+	}
+	for name, result := range v.results {
+		if now.After(result.expiresAt) {
+			delete(v.results, name)
+		}
+	}
+	v.results[subreddit] = subredditValidation{err: err, expiresAt: now.Add(subredditValidationTTL)}
+}
+
+// validateSubreddit asks the ingestion API whether subreddit exists and is
+// readable. It returns 422 when the API rejects it and 502 when the probe
+// failed, with the ingestion error in the message; 0 when it is readable.
+func (s *Server) validateSubreddit(ctx context.Context, subreddit string) (int, error) {
+	now := time.Now()
+	err, cached := s.subredditValidations.get(subreddit, now)
+	if !cached {
+		probeCtx, cancel := context.WithTimeout(ctx, subredditValidationTimeout)
+		err = s.client.ValidateSubreddit(probeCtx, subreddit)
+		cancel()
+
+		var unavailable *client.SubredditUnavailableError
+		if err == nil || errors.As(err, &unavailable) {
+			s.subredditValidations.put(subreddit, err, now)
+		}
+	}
+
+	var unavailable *client.SubredditUnavailableError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &unavailable):
+		return http.StatusUnprocessableEntity, fmt.Errorf("%w; set force=true to save anyway", err)
+	default:
+		return http.StatusBadGateway, fmt.Errorf("could not validate r/%s with the ingestion API: %w; set force=true to save anyway", subreddit, err)
+	}
+}
+
+// subredditRejected reports a validateSubreddit failure: 422 when the
+// ingestion API can't read the subreddit, 502 when the probe failed
+func subredditRejected(c echo.Context, status int, err error) error {
+	code := CodeSubredditUnavailable
+	if status == http.StatusBadGateway {
+		code = CodeUpstreamFailed
+	}
+	return respondError(c, status, code, err.Error(), nil)
+}
+
+// nsfwWarning warns when config collects a subreddit marked NSFW without
+// skip_nsfw. Stored about info is used when there is some, else the ingestion
+// API is asked; when neither knows, there is no warning.
+func (s *Server) nsfwWarning(ctx context.Context, config models.SubredditConfig) string {
+	if !config.Enabled || config.SkipNSFW {
+		return ""
+	}
+
+	var about *models.AboutInfo
+	metadata, err := s.storage.GetSubredditMetadata(ctx, config.SubredditName)
+	if err == nil && metadata != nil {
+		about = metadata.About
+	}
+	if about == nil {
+		probeCtx, cancel := context.WithTimeout(ctx, subredditValidationTimeout)
+		about, _ = s.client.GetSubredditAbout(probeCtx, config.SubredditName)
+		cancel()
+	}
+
+	if about == nil || !about.Over18 {
+		return ""
+	}
+	return fmt.Sprintf("r/%s is marked NSFW but skip_nsfw is not set, so NSFW posts will be stored", config.SubredditName)
+}
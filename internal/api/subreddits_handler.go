@@ -0,0 +1,489 @@
+// internal/api/subreddits_handler.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+const (
+	defaultSubredditsLimit = 100
+	defaultAuditLimit      = 50
+)
+
+var (
+	configSummaryFields   = []string{"subreddit_name", "enabled", "schedule", "max_posts", "priority", "labels", "paused"}
+	metadataSummaryFields = []string{"subreddit_name", "last_scraped_at", "stale", "zero_post_runs"}
+)
+
+// Values of listSubreddits' include param
+const (
+	includeMetadata = "metadata"
+	includeLastRun  = "last_run"
+)
+
+// subredditListing is a config annotated with its scrape state from metadata
+type subredditListing struct {
+	models.SubredditConfig
+	Stale          bool                   `json:"stale"`
+	LastScrapedAt  *time.Time             `json:"last_scraped_at,omitempty"`
+	BestRecentPost *models.BestRecentPost `json:"best_recent_post,omitempty"`
+	// Set by include=metadata and include=last_run
+	Metadata *metadataSummary       `json:"metadata,omitempty"`
+	LastRun  *models.TaskRunSummary `json:"last_run,omitempty"`
+}
+
+// metadataSummary is the scrape state a subreddit table shows
+type metadataSummary struct {
+	// Status is never_scraped, unavailable, stale or ok
+	Status        string     `json:"status"`
+	LastScrapedAt *time.Time `json:"last_scraped_at,omitempty"`
+	ZeroPostRuns  int        `json:"zero_post_runs"`
+	// FailureRate is over the recent runs kept on metadata
+	FailureRate       float64                  `json:"failure_rate"`
+	LastRunStats      *models.RunStats         `json:"last_run_stats,omitempty"`
+	CursorCorrection  *models.CursorCorrection `json:"cursor_correction,omitempty"`
+	AboutFetchedAt    *time.Time               `json:"about_fetched_at,omitempty"`
+	MetadataUpdatedAt *time.Time               `json:"metadata_updated_at,omitempty"`
+}
+
+func summarizeMetadata(metadata models.SubredditMetadata) *metadataSummary {
+	summary := &metadataSummary{
+		Status:           "ok",
+		ZeroPostRuns:     metadata.ZeroPostRuns,
+		FailureRate:      metadata.FailureRate(time.Time{}),
+		LastRunStats:     metadata.LastRun,
+		CursorCorrection: metadata.CursorCorrection,
+	}
+	if !metadata.LastScrapedAt.IsZero() {
+		summary.LastScrapedAt = &metadata.LastScrapedAt
+	}
+	if !metadata.UpdatedAt.IsZero() {
+		summary.MetadataUpdatedAt = &metadata.UpdatedAt
+	}
+	if metadata.About != nil {
+		summary.AboutFetchedAt = &metadata.About.FetchedAt
+	}
+
+	switch {
+	case metadata.LastScrapedAt.IsZero():
+		summary.Status = "never_scraped"
+	case metadata.About != nil && !metadata.About.Available:
+		summary.Status = "unavailable"
+	case metadata.Stale:
+		summary.Status = "stale"
+	}
+	return summary
+}
+
+// listSubreddits lists subreddit configs, by priority unless sorted otherwise.
+// Query params: q (name prefix), enabled, label, min_priority, max_priority,
+// sort (priority|name|updated), limit and offset or page and per_page,
+// detail (summary|full, default summary) and include, a comma separated list
+// of metadata (each config's scrape state) and last_run (its latest
+// monitor_subreddit run), which are joined server-side in one batch each.
+// The matching total is returned in the body and the X-Total-Count header.
+// Responses carry an ETag; a matching If-None-Match gets 304 Not Modified.
+// Listings including last_run don't, as task runs aren't versioned.
+func (s *Server) listSubreddits(c echo.Context) error {
+	opts, err := listOptions(c, defaultSubredditsLimit, configSummaryFields)
+	if err == nil {
+		err = pageParams(c, &opts)
+	}
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	filter, err := configFilterFromQuery(c)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	include, err := querySet(c, "include", includeMetadata, includeLastRun)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+
+	// Listings join metadata, so both collections version the response
+	configsVersion, err := s.storage.GetSubredditConfigsVersion(ctx, filter.Label)
+	if err != nil {
+		return internalError(c, err)
+	}
+	metadataVersion, err := s.storage.GetSubredditMetadataVersion(ctx)
+	if err != nil {
+		return internalError(c, err)
+	}
+	cacheControl(c, 0)
+	if !include[includeLastRun] && notModified(c, etagFor(c, configsVersion, metadataVersion)) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	page, err := s.storage.GetAllSubredditConfigs(ctx, filter, opts)
+	if err != nil {
+		return internalError(c, err)
+	}
+	configs := page.Configs
+
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		names = append(names, config.SubredditName)
+	}
+	metadatas, err := s.storage.GetSubredditMetadataByNames(ctx, names)
+	if err != nil {
+		return internalError(c, err)
+	}
+	var lastRuns map[string]models.TaskRunSummary
+	if include[includeLastRun] {
+		if lastRuns, err = s.storage.GetLatestSubredditRuns(ctx, names); err != nil {
+			return internalError(c, err)
+		}
+	}
+
+	listings := make([]subredditListing, 0, len(configs))
+	for _, config := range configs {
+		metadata := metadatas[config.SubredditName]
+		listing := subredditListing{
+			SubredditConfig: config,
+			Stale:           metadata.Stale,
+			BestRecentPost:  metadata.BestRecentPost,
+		}
+		if !metadata.LastScrapedAt.IsZero() {
+			listing.LastScrapedAt = &metadata.LastScrapedAt
+		}
+		if include[includeMetadata] {
+			listing.Metadata = summarizeMetadata(metadata)
+		}
+		if run, ok := lastRuns[config.SubredditName]; ok {
+			listing.LastRun = &run
+		}
+		listings = append(listings, listing)
+	}
+
+	c.Response().Header().Set(totalCountHeader, strconv.FormatInt(page.Total, 10))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subreddits": listings,
+		"count":      len(listings),
+		"offset":     opts.Skip,
+		"total":      page.Total,
+	})
+}
+
+// configFilterFromQuery reads the listSubreddits filter params
+func configFilterFromQuery(c echo.Context) (storage.ConfigFilter, error) {
+	filter := storage.ConfigFilter{
+		Query: c.QueryParam("q"),
+		Sort:  c.QueryParam("sort"),
+	}
+
+	if value := c.QueryParam("enabled"); value != "" {
+		enabled, err := queryBool(c, "enabled", false)
+		if err != nil {
+			return filter, err
+		}
+		filter.Enabled = &enabled
+	}
+	if value := c.QueryParam("label"); value != "" {
+		label, err := models.NormalizeLabel(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Label = label
+	}
+	if value := c.QueryParam("min_priority"); value != "" {
+		minPriority, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("min_priority must be an integer")
+		}
+		filter.MinPriority = &minPriority
+	}
+	if value := c.QueryParam("max_priority"); value != "" {
+		maxPriority, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("max_priority must be an integer")
+		}
+		filter.MaxPriority = &maxPriority
+	}
+
+	return filter, filter.Validate()
+}
+
+// listMetadata lists per-subreddit scrape metadata by name.
+// Query params: limit, offset, detail (summary|full, default summary).
+func (s *Server) listMetadata(c echo.Context) error {
+	opts, err := listOptions(c, defaultSubredditsLimit, metadataSummaryFields)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	metadatas, err := s.storage.GetAllSubredditMetadata(c.Request().Context(), opts)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metadata": metadatas,
+		"count":    len(metadatas),
+		"offset":   opts.Skip,
+	})
+}
+
+// createSubredditRequest is the body of POST /api/subreddits. Unset fields
+// come from the template when one is named, else from the global defaults.
+type createSubredditRequest struct {
+	SubredditName             string           `json:"subreddit_name"`
+	Template                  string           `json:"template"`
+	Enabled                   *bool            `json:"enabled"`
+	Schedule                  *string          `json:"schedule"`
+	MaxPosts                  *int             `json:"max_posts"`
+	Priority                  *int             `json:"priority"`
+	Description               *string          `json:"description"`
+	Labels                    []string         `json:"labels"`
+	TagRules                  []models.TagRule `json:"tag_rules"`
+	TrackRevisions            *bool            `json:"track_revisions"`
+	SkipNSFW                  *bool            `json:"skip_nsfw"`
+	SkipSpoilers              *bool            `json:"skip_spoilers"`
+	ExpectedPostIntervalHours *int             `json:"expected_post_interval_hours"`
+	MaxPostAgeDays            *int             `json:"max_post_age_days"`
+	FirstRunMode              *string          `json:"first_run_mode"`
+	PushEnabled               *bool            `json:"push_enabled"`
+	AdaptiveSchedule          *bool            `json:"adaptive_schedule"`
+	SampleRate                *float64         `json:"sample_rate"`
+}
+
+// apply overrides config with the fields set in the request
+func (r *createSubredditRequest) apply(config *models.SubredditConfig) {
+	if r.Enabled != nil {
+		config.Enabled = *r.Enabled
+	}
+	if r.Schedule != nil {
+		config.Schedule = *r.Schedule
+	}
+	if r.MaxPosts != nil {
+		config.MaxPosts = *r.MaxPosts
+	}
+	if r.Priority != nil {
+		config.Priority = *r.Priority
+	}
+	if r.Description != nil {
+		config.Description = *r.Description
+	}
+	if r.Labels != nil {
+		config.Labels = r.Labels
+	}
+	if r.TagRules != nil {
+		config.TagRules = r.TagRules
+	}
+	if r.TrackRevisions != nil {
+		config.TrackRevisions = *r.TrackRevisions
+	}
+	if r.SkipNSFW != nil {
+		config.SkipNSFW = *r.SkipNSFW
+	}
+	if r.SkipSpoilers != nil {
+		config.SkipSpoilers = *r.SkipSpoilers
+	}
+	if r.ExpectedPostIntervalHours != nil {
+		config.ExpectedPostIntervalHours = *r.ExpectedPostIntervalHours
+	}
+	if r.MaxPostAgeDays != nil {
+		config.MaxPostAgeDays = *r.MaxPostAgeDays
+	}
+	if r.FirstRunMode != nil {
+		config.FirstRunMode = *r.FirstRunMode
+	}
+	if r.PushEnabled != nil {
+		config.PushEnabled = *r.PushEnabled
+	}
+	if r.AdaptiveSchedule != nil {
+		config.AdaptiveSchedule = *r.AdaptiveSchedule
+	}
+	if r.SampleRate != nil {
+		config.SampleRate = *r.SampleRate
+	}
+}
+
+// createSubreddit adds a subreddit config, optionally from a template; the
+// config records the template name but later template changes don't apply
+// to it. The schedule starts on the next restart, as for other config changes.
+// Enabled configs are rejected with 422 when the ingestion API can't read the
+// subreddit, unless the query param force=true is set.
+func (s *Server) createSubreddit(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	force, err := queryBool(c, "force", false)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	var req createSubredditRequest
+	if err := c.Bind(&req); err != nil {
+		return invalidBody(c, err)
+	}
+	name := strings.TrimSpace(req.SubredditName)
+
+	config := models.SubredditConfig{SubredditName: name, Enabled: true}
+	if req.Template != "" {
+		template, err := s.storage.GetConfigTemplate(ctx, req.Template)
+		if err != nil {
+			return internalError(c, err)
+		}
+		if template == nil {
+			return badRequest(c, CodeUnknownTemplate, "unknown template "+req.Template)
+		}
+		config = template.NewConfig(name)
+	}
+	req.apply(&config)
+
+	if config.MaxPosts <= 0 {
+		config.MaxPosts = s.config.DefaultLimit
+	}
+	if err := config.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if err := checkSchedule(config.Schedule); err != nil {
+		return badRequest(c, CodeInvalidSchedule, err.Error())
+	}
+	if err := models.ValidateMaxPosts(config.MaxPosts, s.config.MaxPostsCeiling); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+
+	existing, err := s.storage.GetSubredditConfig(ctx, name)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if existing != nil {
+		return conflict(c, CodeAlreadyExists, "subreddit config already exists")
+	}
+	if config.Enabled && !force {
+		if status, err := s.validateSubreddit(ctx, name); err != nil {
+			return subredditRejected(c, status, err)
+		}
+	}
+
+	if err := s.storage.UpsertSubredditConfig(ctx, &config, actor(c)); err != nil {
+		return internalError(c, err)
+	}
+
+	response := createSubredditResponse{SubredditConfig: config}
+	if warning := s.nsfwWarning(ctx, config); warning != "" {
+		response.Warnings = append(response.Warnings, warning)
+	}
+	return c.JSON(http.StatusCreated, response)
+}
+
+// createSubredditResponse is the saved config with anything worth a second look
+type createSubredditResponse struct {
+	models.SubredditConfig
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// getSubreddit returns a subreddit's config with its metadata, including the
+// about info stored by refresh_subreddit_info, the activity profile of
+// adaptive subreddits and the schedule this instance runs it on
+func (s *Server) getSubreddit(c echo.Context) error {
+	ctx := c.Request().Context()
+	name := c.Param("name")
+
+	config, err := s.storage.GetSubredditConfig(ctx, name)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if config == nil {
+		return notFound(c, CodeSubredditNotFound, "subreddit config not found")
+	}
+	metadata, err := s.storage.GetSubredditMetadata(ctx, name)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	schedule, source := s.tasks.EffectiveSchedule(*config)
+	response := map[string]interface{}{
+		"config":   config,
+		"metadata": metadata,
+		"about":    nil,
+		"activity": nil,
+		"effective_schedule": map[string]string{
+			"schedule": schedule,
+			"source":   source,
+		},
+	}
+	if metadata != nil {
+		response["about"] = metadata.About
+		response["activity"] = metadata.Activity
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// listDeletedSubreddits lists soft-deleted configs that can still be restored
+func (s *Server) listDeletedSubreddits(c echo.Context) error {
+	configs, err := s.storage.GetDeletedSubredditConfigs(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subreddits":  configs,
+		"count":       len(configs),
+		"purge_after": s.config.ConfigPurgeAfter.String(),
+	})
+}
+
+// deleteSubreddit soft-deletes a subreddit config. Its schedule keeps running
+// until the next restart, as with other config changes; stored posts are kept.
+func (s *Server) deleteSubreddit(c echo.Context) error {
+	found, err := s.storage.DeleteSubredditConfig(c.Request().Context(), c.Param("name"), actor(c))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !found {
+		return notFound(c, CodeSubredditNotFound, "subreddit config not found")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// restoreSubreddit undeletes the most recently deleted config of a subreddit
+func (s *Server) restoreSubreddit(c echo.Context) error {
+	config, err := s.storage.RestoreSubredditConfig(c.Request().Context(), c.Param("name"), actor(c))
+	if errors.Is(err, storage.ErrSubredditConfigExists) {
+		return conflict(c, CodeAlreadyExists, err.Error())
+	}
+	if err != nil {
+		return internalError(c, err)
+	}
+	if config == nil {
+		return notFound(c, CodeSubredditNotFound, "no deleted config for this subreddit")
+	}
+
+	return c.JSON(http.StatusOK, config)
+}
+
+// getSubredditAudit lists a subreddit's config changes, newest first.
+// Query params: limit.
+func (s *Server) getSubredditAudit(c echo.Context) error {
+	limit, err := queryLimit(c, defaultAuditLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	subreddit := c.Param("name")
+	entries, err := s.storage.GetConfigAudit(c.Request().Context(), subreddit, limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subreddit": subreddit,
+		"audit":     entries,
+		"count":     len(entries),
+	})
+}
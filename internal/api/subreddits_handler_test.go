@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestListSubredditsReportsStorageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{name: "configs", method: "GetAllSubredditConfigs"},
+		{name: "config version", method: "GetSubredditConfigsVersion"},
+		{name: "metadata version", method: "GetSubredditMetadataVersion"},
+		{name: "metadata", method: "GetSubredditMetadataByNames"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storagetest.NewMemory(clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+			if err := store.UpsertSubredditConfig(context.Background(), &models.SubredditConfig{SubredditName: "golang", Enabled: true}, "test"); err != nil {
+				t.Fatal(err)
+			}
+			store.Fail(tt.method, 1, errors.New("connection reset"))
+			_, e := newTestServer(t, testConfig(t), store)
+
+			rec := serve(e, http.MethodGet, "/api/subreddits", "")
+
+			wantStatus(t, rec, http.StatusInternalServerError)
+			if response := decodeErrorResponse(t, rec); response.Code != CodeInternal {
+				t.Errorf("code = %q, want %q", response.Code, CodeInternal)
+			}
+		})
+	}
+}
+
+func TestListSubredditsIncludesStaleFlag(t *testing.T) {
+	store := storagetest.NewMemory(clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+	for _, name := range []string{"golang", "rust"} {
+		if err := store.UpsertSubredditConfig(context.Background(), &models.SubredditConfig{SubredditName: name, Enabled: true}, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.SetMetadata(models.SubredditMetadata{SubredditName: "rust", ZeroPostRuns: 50, Stale: true})
+	_, e := newTestServer(t, testConfig(t), store)
+
+	rec := serve(e, http.MethodGet, "/api/subreddits", "")
+
+	wantStatus(t, rec, http.StatusOK)
+	var body struct {
+		Subreddits []struct {
+			SubredditName string `json:"subreddit_name"`
+			Stale         bool   `json:"stale"`
+		} `json:"subreddits"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	stale := map[string]bool{}
+	for _, listing := range body.Subreddits {
+		stale[listing.SubredditName] = listing.Stale
+	}
+	if len(stale) != 2 || stale["golang"] || !stale["rust"] {
+		t.Errorf("stale flags = %v, want only rust stale", stale)
+	}
+}
+
+func TestListSubredditsLoadsMetadataInOneQuery(t *testing.T) {
+	scraped := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+	store := storagetest.NewMemory(clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+	const count = 30
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("sub%02d", i)
+		if err := store.UpsertSubredditConfig(context.Background(), &models.SubredditConfig{SubredditName: name, Enabled: true}, "test"); err != nil {
+			t.Fatal(err)
+		}
+		// Odd subreddits have never been scraped and have no metadata
+		if i%2 == 0 {
+			store.SetMetadata(models.SubredditMetadata{SubredditName: name, LastScrapedAt: scraped})
+		}
+	}
+	_, e := newTestServer(t, testConfig(t), store)
+
+	rec := serve(e, http.MethodGet, "/api/subreddits?limit=100", "")
+
+	wantStatus(t, rec, http.StatusOK)
+	var body struct {
+		Subreddits []struct {
+			SubredditName string     `json:"subreddit_name"`
+			LastScrapedAt *time.Time `json:"last_scraped_at"`
+		} `json:"subreddits"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Subreddits) != count {
+		t.Fatalf("got %d subreddits, want %d", len(body.Subreddits), count)
+	}
+	for _, listing := range body.Subreddits {
+		var i int
+		fmt.Sscanf(listing.SubredditName, "sub%d", &i)
+		if wantScraped := i%2 == 0; (listing.LastScrapedAt != nil) != wantScraped {
+			t.Errorf("r/%s last_scraped_at = %v, want set %v", listing.SubredditName, listing.LastScrapedAt, wantScraped)
+		} else if wantScraped && !listing.LastScrapedAt.Equal(scraped) {
+			t.Errorf("r/%s last_scraped_at = %v, want %v", listing.SubredditName, listing.LastScrapedAt, scraped)
+		}
+	}
+
+	if calls := store.Calls("GetSubredditMetadataByNames"); calls != 1 {
+		t.Errorf("GetSubredditMetadataByNames called %d times, want 1", calls)
+	}
+	if calls := store.Calls("GetSubredditMetadata"); calls != 0 {
+		t.Errorf("GetSubredditMetadata called %d times, want 0", calls)
+	}
+}
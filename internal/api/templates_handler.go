@@ -0,0 +1,74 @@
+// internal/api/templates_handler.go
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// listConfigTemplates lists config templates by name
+func (s *Server) listConfigTemplates(c echo.Context) error {
+	templates, err := s.storage.GetConfigTemplates(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+func (s *Server) getConfigTemplate(c echo.Context) error {
+	template, err := s.storage.GetConfigTemplate(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if template == nil {
+		return notFound(c, CodeTemplateNotFound, "config template not found")
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+// putConfigTemplate creates or replaces a template. The name comes from the path.
+func (s *Server) putConfigTemplate(c echo.Context) error {
+	var template models.ConfigTemplate
+	if err := c.Bind(&template); err != nil {
+		return invalidBody(c, err)
+	}
+	template.Name = strings.TrimSpace(c.Param("name"))
+
+	if err := template.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if err := checkSchedule(template.Schedule); err != nil {
+		return badRequest(c, CodeInvalidSchedule, err.Error())
+	}
+	if err := models.ValidateMaxPosts(template.MaxPosts, s.config.MaxPostsCeiling); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+
+	if err := s.storage.UpsertConfigTemplate(c.Request().Context(), &template); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+// deleteConfigTemplate deletes a template; configs created from it are kept
+func (s *Server) deleteConfigTemplate(c echo.Context) error {
+	found, err := s.storage.DeleteConfigTemplate(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if !found {
+		return notFound(c, CodeTemplateNotFound, "config template not found")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
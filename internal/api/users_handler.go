@@ -0,0 +1,83 @@
+// internal/api/users_handler.go
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// listUsers lists monitored user accounts by username
+func (s *Server) listUsers(c echo.Context) error {
+	configs, err := s.storage.GetAllUserConfigs(c.Request().Context())
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users": configs,
+		"count": len(configs),
+	})
+}
+
+// getUser returns one user config together with its scrape metadata
+func (s *Server) getUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	username := c.Param("username")
+
+	config, err := s.storage.GetUserConfig(ctx, username)
+	if err != nil {
+		return internalError(c, err)
+	}
+	if config == nil {
+		return notFound(c, CodeUserNotFound, "user not found")
+	}
+
+	metadata, err := s.storage.GetUserMetadata(ctx, username)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user":     config,
+		"metadata": metadata,
+	})
+}
+
+// putUser creates or replaces a user config. The username comes from the path.
+// Schedule changes apply on the next restart, as for subreddit configs.
+func (s *Server) putUser(c echo.Context) error {
+	var config models.UserConfig
+	if err := c.Bind(&config); err != nil {
+		return invalidBody(c, err)
+	}
+	config.Username = strings.TrimSpace(c.Param("username"))
+
+	if err := config.Validate(); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+	if err := checkSchedule(config.Schedule); err != nil {
+		return badRequest(c, CodeInvalidSchedule, err.Error())
+	}
+	if err := models.ValidateMaxPosts(config.MaxPosts, s.config.MaxPostsCeiling); err != nil {
+		return badRequest(c, CodeValidationFailed, err.Error())
+	}
+
+	if err := s.storage.UpsertUserConfig(c.Request().Context(), &config); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, config)
+}
+
+// deleteUser stops monitoring a user; already stored posts are kept
+func (s *Server) deleteUser(c echo.Context) error {
+	if err := s.storage.DeleteUserConfig(c.Request().Context(), c.Param("username")); err != nil {
+		return internalError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+// internal/api/volume_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Rollups are hourly, so these are the granularities served from them, with
+// their default and maximum query ranges
+var volumeGranularities = map[string]struct {
+	bucket       time.Duration
+	defaultRange time.Duration
+	maxRange     time.Duration
+}{
+	"hour": {bucket: time.Hour, defaultRange: 24 * time.Hour, maxRange: 31 * 24 * time.Hour},
+	"day":  {bucket: 24 * time.Hour, defaultRange: 30 * 24 * time.Hour, maxRange: 366 * 24 * time.Hour},
+}
+
+// volumeBucket is one bucket of the volume response
+type volumeBucket struct {
+	BucketStart   time.Time `json:"bucket_start"`
+	PostCount     int       `json:"post_count"`
+	SumScore      int64     `json:"sum_score"`
+	AvgScore      float64   `json:"avg_score"`
+	MaxScore      int       `json:"max_score"`
+	AuthorCount   int       `json:"author_count"`
+	AuthorsCapped bool      `json:"authors_capped,omitempty"`
+
+	authors map[string]bool
+}
+
+// getSubredditVolume returns post volume for a subreddit from the hourly rollups.
+// Query params: granularity (hour|day, default hour), from, to (RFC3339 or
+// epoch seconds; default the granularity's range ending now), tz (IANA zone
+// for displaying timestamps, default UTC). Buckets are always UTC hours/days;
+// tz only changes how they are rendered. Empty buckets are omitted.
+func (s *Server) getSubredditVolume(c echo.Context) error {
+	granularityName := c.QueryParam("granularity")
+	if granularityName == "" {
+		granularityName = "hour"
+	}
+	granularity, ok := volumeGranularities[granularityName]
+	if !ok {
+		return badRequest(c, CodeInvalidRequest, "granularity must be hour or day")
+	}
+	location, err := queryLocation(c)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+
+	to, err := queryTime(c, "to", time.Now().UTC())
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	from, err := queryTime(c, "from", to.Add(-granularity.defaultRange))
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	if !from.Before(to) {
+		return badRequest(c, CodeInvalidRequest, "from must be before to")
+	}
+	if to.Sub(from) > granularity.maxRange {
+		return badRequest(c, CodeInvalidRequest, fmt.Sprintf("range must not exceed %v for granularity %s", granularity.maxRange, granularityName))
+	}
+
+	// Whole buckets only; day buckets are UTC days
+	from = from.Truncate(granularity.bucket)
+
+	subreddit := c.Param("name")
+	rollups, err := s.storage.GetRollups(c.Request().Context(), subreddit, from, to)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	buckets := mergeRollups(rollups, granularity.bucket)
+	for i := range buckets {
+		buckets[i].BucketStart = buckets[i].BucketStart.In(location)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subreddit":   subreddit,
+		"granularity": granularityName,
+		"timezone":    location.String(),
+		"from":        from.In(location),
+		"to":          to.In(location),
+		"buckets":     buckets,
+	})
+}
+
+// mergeRollups folds hourly rollups (oldest first) into buckets of the given size
+func mergeRollups(rollups []models.PostRollup, size time.Duration) []volumeBucket {
+	buckets := []volumeBucket{}
+	for _, rollup := range rollups {
+		start := rollup.BucketStart.Truncate(size)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].BucketStart.Equal(start) {
+			buckets = append(buckets, volumeBucket{
+				BucketStart: start,
+				MaxScore:    rollup.MaxScore,
+				authors:     make(map[string]bool),
+			})
+		}
+
+		bucket := &buckets[len(buckets)-1]
+		bucket.PostCount += rollup.PostCount
+		bucket.SumScore += rollup.SumScore
+		if rollup.MaxScore > bucket.MaxScore {
+			bucket.MaxScore = rollup.MaxScore
+		}
+		bucket.AuthorsCapped = bucket.AuthorsCapped || rollup.AuthorsCapped
+		for _, author := range rollup.Authors {
+			bucket.authors[author] = true
+		}
+	}
+
+	for i := range buckets {
+		if buckets[i].PostCount > 0 {
+			buckets[i].AvgScore = float64(buckets[i].SumScore) / float64(buckets[i].PostCount)
+		}
+		buckets[i].AuthorCount = len(buckets[i].authors)
+	}
+	return buckets
+}
+
+// rebuildRollups recomputes a subreddit's rollups from stored posts.
+// Query params: subreddit (required), from, to (RFC3339 or epoch seconds;
+// default the last 30 days).
+func (s *Server) rebuildRollups(c echo.Context) error {
+	subreddit := c.QueryParam("subreddit")
+	if subreddit == "" {
+		return badRequest(c, CodeInvalidRequest, "subreddit is required")
+	}
+
+	to, err := queryTime(c, "to", time.Now().UTC())
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	from, err := queryTime(c, "from", to.Add(-30*24*time.Hour))
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	if !from.Before(to) {
+		return badRequest(c, CodeInvalidRequest, "from must be before to")
+	}
+
+	buckets, err := s.storage.RebuildRollups(c.Request().Context(), subreddit, from, to)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"subreddit": subreddit,
+		"from":      from,
+		"to":        to,
+		"buckets":   buckets,
+	})
+}
@@ -0,0 +1,64 @@
+// internal/api/webhook_deliveries_handler.go
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/tasks"
+)
+
+const defaultDeliveryLimit = 100
+
+// listWebhookDeliveries returns the newest webhook deliveries, optionally
+// filtered by status (delivered or failed)
+func (s *Server) listWebhookDeliveries(c echo.Context) error {
+	limit, err := queryLimit(c, defaultDeliveryLimit)
+	if err != nil {
+		return badRequest(c, CodeInvalidRequest, err.Error())
+	}
+	status := c.QueryParam("status")
+	if status != "" && status != models.DeliveryDelivered && status != models.DeliveryFailed {
+		return badRequest(c, CodeInvalidRequest, "status must be delivered or failed")
+	}
+
+	deliveries, err := s.storage.GetWebhookDeliveries(c.Request().Context(), status, limit)
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+// replayWebhookDelivery re-sends a delivery with its original delivery ID.
+// The new delivery record is returned, with 502 when the webhook rejected it.
+func (s *Server) replayWebhookDelivery(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	original, err := s.storage.GetWebhookDelivery(ctx, c.Param("id"))
+	if err != nil {
+		return internalError(c, err)
+	}
+	if original == nil {
+		return notFound(c, CodeDeliveryNotFound, "delivery not found")
+	}
+
+	replay, err := s.tasks.ReplayWebhookDelivery(ctx, *original)
+	if errors.Is(err, tasks.ErrReplayUnavailable) {
+		return conflict(c, CodeConflict, err.Error())
+	}
+	if err != nil {
+		return internalError(c, err)
+	}
+
+	if replay.Status == models.DeliveryFailed {
+		return c.JSON(http.StatusBadGateway, replay)
+	}
+	return c.JSON(http.StatusOK, replay)
+}
@@ -2,15 +2,35 @@
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/ersauravadhikari/blueberry-go/blueberry"
 	"github.com/ersauravadhikari/blueberry-go/blueberry/store"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"reddit-orchestrator/internal/api"
+	"reddit-orchestrator/internal/capture"
 	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock"
 	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/enrichment"
+	"reddit-orchestrator/internal/leader"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/privacy"
 	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/runstate"
 	"reddit-orchestrator/internal/storage"
 	"reddit-orchestrator/internal/tasks"
 )
@@ -22,8 +42,42 @@ type App struct {
 	Client      client.IngestionClientInterface
 	Processor   processor.ProcessorInterface
 	TaskManager tasks.TaskManagerInterface
+	Elector     leader.ElectorInterface
+	RunState    runstate.RecorderInterface
+	API         *api.Server
+
+	server *echo.Echo
+	// management serves the probes, metrics and version when MANAGEMENT_PORT
+	// is set; nil when they share server
+	management *echo.Echo
+	// stopElector ends the leadership campaign (HA mode only); electorDone is
+	// closed once the lease has been released
+	stopElector context.CancelFunc
+	electorDone chan struct{}
+	// stopWatchdog ends the scheduler watchdog once the scheduler has started
+	stopWatchdog context.CancelFunc
+	// stopHeartbeat ends the runtime state heartbeat
+	stopHeartbeat context.CancelFunc
+	// stopScheduling cancels subreddit schedule registration if Shutdown
+	// comes first; schedulingFailed carries a STRICT_SCHEDULING failure to Start
+	stopScheduling   context.CancelFunc
+	schedulingFailed chan error
+	// uncleanStart is set when the previous process on this host did not
+	// finish its shutdown
+	uncleanStart bool
+	// shutdownMu guards shuttingDown and shutdownDone, which is closed once
+	// the first Shutdown has finished
+	shutdownMu   sync.Mutex
+	shuttingDown bool
+	shutdownDone chan struct{}
 }
 
+const (
+	serverShutdownTimeout = 10 * time.Second
+	// runStateTimeout bounds the runtime state writes at start and shutdown
+	runStateTimeout = 5 * time.Second
+)
+
 func Initialize() (*App, error) {
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -31,30 +85,76 @@ func Initialize() (*App, error) {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	mongoStore, err := storage.NewMongoStorage(cfg.MongoDBURI, cfg.DatabaseName)
+	// Timestamps are stored and compared in UTC; a local zone only affects
+	// log output, but has caused confusion when comparing hosts
+	if !hostTimezoneIsUTC() {
+		log.Printf("Warning: host time zone is %s, not UTC. Timestamps are stored in UTC; set TZ=UTC to keep logs consistent", time.Local)
+	}
+
+	// Storage, processing and tasks share one clock; tests substitute a fake
+	clk := clock.Real
+
+	mongoStore, err := newMongoStorage(cfg, clk)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize MongoDB storage: %w", err)
 	}
 
-	schedulerDBName := cfg.DatabaseName
-	blueBerryStore, err := store.NewMongoDB(cfg.MongoDBURI, schedulerDBName)
+	blueBerryStore, err := store.NewMongoDB(cfg.MongoDBURI, cfg.SchedulerDatabaseName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize BlueBerry MongoDB store: %w", err)
 	}
 
 	bb := blueberry.NewBlueBerryInstance(blueBerryStore)
 
-	// Add authentication (required)
-	if cfg.WebAuthUser == "" || cfg.WebAuthPassword == "" {
-		return nil, fmt.Errorf("web authentication credentials are required")
+	// Add authentication (required). BlueBerry's login compares plaintext, so
+	// with only a hash its dashboard is locked behind a random password.
+	dashboardPassword := cfg.WebAuthPassword
+	if dashboardPassword == "" {
+		dashboardPassword, err = randomPassword()
+		if err != nil {
+			return nil, err
+		}
+		log.Println("WARNING: only WEB_AUTH_PASSWORD_HASH is set; the BlueBerry dashboard can't verify hashes and is locked, set WEB_AUTH_PASSWORD as well to use it")
+	}
+	bb.AddWebOnlyPasswordAuth(cfg.WebAuthUser, dashboardPassword)
+	log.Printf("Web auth: API passwords verified by %s (ENV=%s)\n", cfg.WebAuthMode(), cfg.Env)
+
+	var capturer capture.CapturerInterface
+	if cfg.CaptureRawResponses != "" && cfg.CaptureRawResponses != "off" {
+		log.Printf("Capturing raw ingestion responses for: %s", cfg.CaptureRawResponses)
+		capturer = capture.NewStoreCapturer(mongoStore, cfg.CaptureRawResponses, cfg.CaptureMaxBytes, cfg.CaptureDailyLimit, cfg.CaptureTTL)
+	}
+
+	fieldMapping, err := client.LoadFieldMapping(cfg.IngestionFieldMappingFile, cfg.IngestionAPIURL)
+	if err != nil {
+		return nil, err
+	}
+	ingestionClient := client.NewIngestionClient(cfg.IngestionAPIURL, cfg.RequestTimeout, cfg.MaxResponseBytes, cfg.IngestionAPIVersion, fieldMapping, capturer)
+
+	dataProcessor := processor.NewProcessor(cfg.Features.DebugRejections, privacy.NewAuthors(cfg.PrivacyMode, cfg.PrivacyHashKey), clk)
+
+	var enricher enrichment.EnricherInterface
+	if cfg.EnrichmentURL != "" {
+		enricher = enrichment.NewHTTPEnricher(cfg.EnrichmentURL)
+	}
+
+	notifier, err := NewNotifier(cfg)
+	if err != nil {
+		return nil, err
 	}
-	bb.AddWebOnlyPasswordAuth(cfg.WebAuthUser, cfg.WebAuthPassword)
 
-	ingestionClient := client.NewIngestionClient(cfg.IngestionAPIURL, cfg.RequestTimeout)
+	var mailer notify.MailerInterface
+	if cfg.SMTPHost != "" {
+		mailer = notify.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.RequestTimeout)
+	}
 
-	dataProcessor := processor.NewProcessor()
+	var elector leader.ElectorInterface = leader.NewStatic(cfg.InstanceID)
+	if cfg.HAEnabled {
+		elector = leader.NewElector(mongoStore, cfg.InstanceID, cfg.LeaderLeaseTTL, cfg.LeaderRenewInterval, clk)
+	}
 
-	taskManager := tasks.NewSubredditTaskManager(bb, mongoStore, ingestionClient, dataProcessor, cfg)
+	taskManager := tasks.NewSubredditTaskManager(bb, mongoStore, ingestionClient, dataProcessor, enricher, notifier, mailer, elector, cfg, clk)
+	runState := runstate.NewRecorder(mongoStore, cfg.StateFile, cfg.InstanceID)
 
 	app := &App{
 		Config:      cfg,
@@ -63,6 +163,9 @@ func Initialize() (*App, error) {
 		Client:      ingestionClient,
 		Processor:   dataProcessor,
 		TaskManager: taskManager,
+		Elector:     elector,
+		RunState:    runState,
+		API:         api.NewServer(cfg, mongoStore, taskManager, elector, ingestionClient, runState),
 	}
 
 	if err := app.TaskManager.RegisterTasks(); err != nil {
@@ -72,20 +175,402 @@ func Initialize() (*App, error) {
 	return app, nil
 }
 
+// Migrate applies pending schema migrations and exits without starting the
+// scheduler or API (server --migrate-only)
+func Migrate() error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	mongoStore, err := newMongoStorage(cfg, clock.Real)
+	if err != nil {
+		return fmt.Errorf("failed to initialize MongoDB storage: %w", err)
+	}
+	return mongoStore.Close()
+}
+
+// NewNotifier builds the alert notifier: a fan-out over NOTIFY_CHANNELS_FILE
+// when set, else the NOTIFY_WEBHOOK_URL webhook, else the log
+func NewNotifier(cfg *config.Config) (notify.NotifierInterface, error) {
+	channels, err := notify.LoadChannels(cfg.NotifyChannelsFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.NotifyChannelsFile == "" {
+		if cfg.NotifyWebhookURL != "" {
+			return notify.NewWebhookNotifier(cfg.NotifyWebhookURL, cfg.RequestTimeout), nil
+		}
+		return notify.NewLogNotifier(), nil
+	}
+
+	if cfg.NotifyWebhookURL != "" {
+		if slices.ContainsFunc(channels, func(c notify.ChannelConfig) bool { return c.Name == "default" }) {
+			return nil, fmt.Errorf("notification channel \"default\" is reserved for NOTIFY_WEBHOOK_URL")
+		}
+		channels = append(channels, notify.ChannelConfig{Name: "default", Type: notify.ChannelWebhook, URL: cfg.NotifyWebhookURL})
+		// Rule notifications sent to it are recorded as webhook deliveries,
+		// as without channels
+		return notify.NewDeliveringFanoutNotifier(channels, "default", cfg.RequestTimeout)
+	}
+	return notify.NewFanoutNotifier(channels, cfg.RequestTimeout)
+}
+
+// newMongoStorage connects to the posts database, which applies pending
+// schema migrations and ensures indexes, then syncs the operational data
+// retention
+func newMongoStorage(cfg *config.Config, clk clock.Clock) (*storage.MongoStorage, error) {
+	storage.MaxUnboundedResults = cfg.MaxUnboundedResults
+	storage.MaxFlairHistory = cfg.FlairHistoryCap
+	mongoStore, err := storage.NewMongoStorage(cfg.MongoDBURI, cfg.DatabaseName, cfg.SlowQueryThreshold, storage.PartitionOptions{
+		Monthly:       cfg.PostPartitioning,
+		DefaultMonths: cfg.PartitionReadMonths,
+	}, clk)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like migrations, this may wait for another instance holding the lock
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	err = mongoStore.ApplyRetention(ctx, storage.RetentionOptions{
+		SchedulerDatabase: cfg.SchedulerDatabaseName,
+		TaskRuns:          cfg.TaskRunRetention,
+		NotificationLog:   cfg.NotificationLogRetention,
+		WebhookDeliveries: cfg.WebhookDeliveryTTL,
+		QASamples:         cfg.QASampleTTL,
+	})
+	if err != nil {
+		mongoStore.Close()
+		return nil, fmt.Errorf("failed to apply data retention: %w", err)
+	}
+	return mongoStore, nil
+}
+
+// Run starts the app and shuts it down on the first signal. It returns only
+// once that shutdown has finished, so the process doesn't exit before the
+// clean shutdown is recorded and storage closed.
+func (a *App) Run(signals <-chan os.Signal) error {
+	go func() {
+		sig, ok := <-signals
+		if !ok {
+			return
+		}
+		log.Printf("Received signal: %v. Shutting down...", sig)
+		a.Shutdown()
+	}()
+
+	err := a.Start()
+	if started, done := a.shutdownState(false); started {
+		<-done
+	}
+	return err
+}
+
 func (a *App) Start() error {
+	// Same paths as BlueBerry.RunAPI, with our own routes mounted alongside
+	e, err := a.BlueBerry.GetEcho(&blueberry.Config{
+		WebUIPath: "",
+		APIPath:   "/api/v1",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up API server: %w", err)
+	}
+	e.HideBanner = true
+	a.API.RegisterRoutes(e)
+
+	// Bind before starting the scheduler so a taken port fails fast
+	addr := net.JoinHostPort(a.Config.ServerHost, a.Config.ServerPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind API server to %s: %w", addr, err)
+	}
+	e.Listener = listener
+	a.server = e
+
+	if a.Config.ManagementPort == "" {
+		a.API.RegisterManagementRoutes(e, nil)
+	} else {
+		e.Pre(api.HideManagementRoutes)
+		if err := a.startManagementServer(); err != nil {
+			return err
+		}
+	}
+
+	a.recordStart()
+	a.checkPrivacyMode()
+
+	// Subreddit schedules register in the background so the probes answer
+	// while a long config list is worked through
+	schedulingCtx, cancelScheduling := context.WithCancel(context.Background())
+	a.stopScheduling = cancelScheduling
+	a.schedulingFailed = make(chan error, 1)
+	go a.scheduleSubreddits(schedulingCtx)
+
+	if a.Config.HAEnabled {
+		// Followers keep the API up and start the scheduler once elected
+		var startScheduler sync.Once
+		a.Elector.OnElected(func() {
+			startScheduler.Do(a.startScheduler)
+			a.catchUp()
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		a.stopElector = cancel
+		a.electorDone = make(chan struct{})
+		go func() {
+			defer close(a.electorDone)
+			a.Elector.Run(ctx)
+		}()
+	} else {
+		a.startScheduler()
+		a.catchUp()
+	}
+
+	log.Printf("Starting API server on %s...", addr)
+	if err := e.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("API server failed: %w", err)
+	}
+
+	select {
+	case err := <-a.schedulingFailed:
+		return fmt.Errorf("failed to register schedules: %w", err)
+	default:
+		return nil
+	}
+}
+
+// scheduleSubreddits registers the subreddit schedules. When the configs
+// cannot be read, or too many registrations fail with STRICT_SCHEDULING, the
+// app shuts down and Start returns the error, as startup used to fail.
+func (a *App) scheduleSubreddits(ctx context.Context) {
+	err := a.TaskManager.ScheduleSubreddits(ctx)
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	log.Printf("Subreddit schedule registration failed: %v", err)
+	a.schedulingFailed <- err
+	a.Shutdown()
+}
+
+// startManagementServer binds MANAGEMENT_PORT and serves the probes, metrics
+// and version on it until Shutdown
+func (a *App) startManagementServer() error {
+	m := echo.New()
+	m.HideBanner = true
+	m.HidePort = true
+	a.API.RegisterManagementRoutes(m, promhttp.HandlerFor(a.BlueBerry.PrometheusRegistry(), promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	}))
+
+	addr := net.JoinHostPort(a.Config.ManagementHost, a.Config.ManagementPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to bind management server to %s: %w", addr, err)
+	}
+	m.Listener = listener
+	a.management = m
+
+	log.Printf("Starting management server on %s...", addr)
+	go func() {
+		if err := m.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Management server failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// startScheduler starts BlueBerry's cron; it cannot be stopped again, so in
+// HA mode scheduled runs check leadership themselves
+func (a *App) startScheduler() {
 	log.Printf("Initializing task scheduler...")
 	a.BlueBerry.InitTaskScheduler()
 
-	log.Printf("Starting API server on port %s...", a.Config.ServerPort)
-	a.BlueBerry.RunAPI(a.Config.ServerPort)
+	if a.Config.WatchdogInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		a.stopWatchdog = cancel
+		go a.TaskManager.RunWatchdog(ctx, a.Config.WatchdogInterval)
+	}
+}
+
+// recordStart writes this process's runtime state and starts its heartbeat.
+// An unclean shutdown of the previous process is logged and makes the
+// catch-up sweep run, and run more eagerly, even with CATCHUP_ON_START off.
+func (a *App) recordStart() {
+	ctx, cancel := context.WithTimeout(context.Background(), runStateTimeout)
+	previous, err := a.RunState.Start(ctx)
+	cancel()
+	if err != nil {
+		log.Printf("Failed to record runtime state: %v", err)
+	}
+	if previous != nil && !previous.CleanShutdown {
+		a.uncleanStart = true
+		log.Printf("WARNING: previous orchestrator on %s (instance %s, pid %d, started %s) did not shut down cleanly; last heartbeat %s with runs in flight for %v",
+			previous.Host, previous.InstanceID, previous.PID, previous.StartedAt.Format(time.RFC3339),
+			previous.HeartbeatAt.Format(time.RFC3339), previous.Running)
+	}
 
-	return nil
+	if a.Config.StateHeartbeatInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		a.stopHeartbeat = cancel
+		go a.RunState.RunHeartbeat(ctx, a.Config.StateHeartbeatInterval, a.runningSubreddits)
+	}
 }
 
+// privacySampleSize is how many of the oldest posts checkPrivacyMode inspects
+const privacySampleSize = 50
+
+// checkPrivacyMode warns when stored authors were written under another
+// PRIVACY_MODE or hash key, e.g. after the mode changed, since author queries
+// and correlation silently miss those posts until they are converted
+func (a *App) checkPrivacyMode() {
+	ctx, cancel := context.WithTimeout(context.Background(), runStateTimeout)
+	defer cancel()
+	page, err := a.Storage.FindPosts(ctx, storage.PostFilter{
+		Sort:           storage.PostSortOld,
+		Fields:         []string{"author"},
+		IncludeDeleted: true,
+		IncludeRemoved: true,
+		Limit:          privacySampleSize,
+	})
+	if err != nil {
+		log.Printf("Failed to check stored authors against PRIVACY_MODE: %v", err)
+		return
+	}
+
+	authors := privacy.NewAuthors(a.Config.PrivacyMode, a.Config.PrivacyHashKey)
+	mismatched := 0
+	for _, post := range page.Posts {
+		if !authors.Conforms(post.Author) {
+			mismatched++
+		}
+	}
+	if mismatched > 0 {
+		log.Printf("WARNING: %d of the %d oldest posts store authors written under another PRIVACY_MODE or PRIVACY_HASH_KEY than the current %s; "+
+			"author queries miss them until they are converted with `orchctl posts reprocess` (hashed authors can't be turned back into names)",
+			mismatched, len(page.Posts), authors.Mode())
+	}
+}
+
+// runningSubreddits lists the subreddits with a run in flight
+func (a *App) runningSubreddits() []string {
+	running := a.TaskManager.QueueSnapshot().Running
+	subreddits := make([]string, 0, len(running))
+	for _, run := range running {
+		subreddits = append(subreddits, run.Subreddit)
+	}
+	return subreddits
+}
+
+func (a *App) catchUp() {
+	if !a.Config.Features.CatchUpOnStart.Enabled() && !a.uncleanStart {
+		return
+	}
+	if _, err := a.TaskManager.CatchUp(context.Background(), a.uncleanStart); err != nil {
+		log.Printf("Catch-up sweep failed: %v", err)
+	}
+}
+
+// shutdownState reports whether Shutdown has been called and returns the
+// channel closed once it has finished. begin marks it called.
+func (a *App) shutdownState(begin bool) (bool, chan struct{}) {
+	a.shutdownMu.Lock()
+	defer a.shutdownMu.Unlock()
+	if a.shutdownDone == nil {
+		a.shutdownDone = make(chan struct{})
+	}
+	started := a.shuttingDown
+	a.shuttingDown = a.shuttingDown || begin
+	return started, a.shutdownDone
+}
+
+// Shutdown stops the app. Later calls, e.g. a signal arriving while a failed
+// schedule registration shuts down, wait for the first to finish.
 func (a *App) Shutdown() {
+	started, done := a.shutdownState(true)
+	if started {
+		<-done
+		return
+	}
+	defer close(done)
+
 	log.Println("Shutting down orchestrator...")
+
+	if a.stopScheduling != nil {
+		a.stopScheduling()
+	}
+	// Hand leadership over before draining so a follower can resume schedules
+	if a.stopElector != nil {
+		a.stopElector()
+		<-a.electorDone
+	}
+	// Draining stops runs from completing, which must not trip the watchdog
+	if a.stopWatchdog != nil {
+		a.stopWatchdog()
+	}
+
+	// Let in-flight runs finish before their storage goes away
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), a.Config.DrainTimeout)
+	abandoned, err := a.TaskManager.Drain(drainCtx)
+	cancelDrain()
+	if err != nil {
+		log.Printf("Drain timed out after %v, abandoning %d running task(s)", a.Config.DrainTimeout, abandoned)
+	} else {
+		log.Println("All running tasks finished")
+	}
+
 	a.BlueBerry.Shutdown()
+	// Both servers stop together; probes keep answering until then
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	var servers sync.WaitGroup
+	for name, server := range map[string]*echo.Echo{"API": a.server, "Management": a.management} {
+		if server == nil {
+			continue
+		}
+		servers.Add(1)
+		go func() {
+			defer servers.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Printf("%s server shutdown error: %v", name, err)
+			}
+		}()
+	}
+	servers.Wait()
+	// Recording the clean shutdown comes last, so a crash anywhere above
+	// still shows as unclean
+	if a.stopHeartbeat != nil {
+		a.stopHeartbeat()
+	}
+	if a.RunState != nil && a.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), runStateTimeout)
+		if err := a.RunState.Stop(ctx); err != nil {
+			log.Printf("Failed to record clean shutdown: %v", err)
+		}
+		cancel()
+	}
 	if a.Storage != nil {
 		a.Storage.Close()
 	}
-}
\ No newline at end of file
+}
+
+// hostTimezoneIsUTC checks the local zone's offset in winter and summer, so
+// zones that match UTC for only part of the year are reported too
+func hostTimezoneIsUTC() bool {
+	year := time.Now().Year()
+	for _, month := range []time.Month{time.January, time.July} {
+		if _, offset := time.Date(year, month, 1, 0, 0, 0, 0, time.Local).Zone(); offset != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// randomPassword returns a password nobody knows, to lock a login
+func randomPassword() (string, error) {
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", fmt.Errorf("failed to generate dashboard password: %w", err)
+	}
+	return hex.EncodeToString(random[:]), nil
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/api"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/runstate"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+	"reddit-orchestrator/internal/tasks"
+)
+
+// events records the shutdown steps the fakes see, in order
+type events struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (e *events) add(step string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.steps = append(e.steps, step)
+}
+
+func (e *events) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.steps...)
+}
+
+// closingStore is the in-memory storage with the calls Start and Shutdown
+// make outside the task manager
+type closingStore struct {
+	*storagetest.Memory
+	events *events
+}
+
+func (s closingStore) FindPosts(ctx context.Context, filter storage.PostFilter) (storage.PostPage, error) {
+	return storage.PostPage{}, nil
+}
+
+func (s closingStore) Close() error {
+	// Slow enough that an exit racing the shutdown would skip it
+	time.Sleep(50 * time.Millisecond)
+	s.events.add("close storage")
+	return nil
+}
+
+// idleTasks is a task manager with no subreddits and nothing running
+type idleTasks struct {
+	tasks.TaskManagerInterface
+	events *events
+}
+
+func (idleTasks) ScheduleSubreddits(ctx context.Context) error { return nil }
+
+func (idleTasks) CatchUp(ctx context.Context, afterUncleanShutdown bool) (int, error) { return 0, nil }
+
+func (idleTasks) RunWatchdog(ctx context.Context, interval time.Duration) { <-ctx.Done() }
+
+func (idleTasks) QueueSnapshot() tasks.QueueSnapshot { return tasks.QueueSnapshot{} }
+
+func (m idleTasks) Drain(ctx context.Context) (int, error) {
+	m.events.add("drain")
+	return 0, nil
+}
+
+// recordingRunState records the clean shutdown
+type recordingRunState struct {
+	events *events
+}
+
+func (r recordingRunState) Start(ctx context.Context) (*models.RuntimeState, error) {
+	return nil, nil
+}
+
+func (r recordingRunState) RunHeartbeat(ctx context.Context, interval time.Duration, running func() []string) {
+	<-ctx.Done()
+}
+
+func (r recordingRunState) Stop(ctx context.Context) error {
+	r.events.add("record clean shutdown")
+	return nil
+}
+
+func (r recordingRunState) Status() runstate.Status { return runstate.Status{} }
+
+// emptyRunDB is a BlueBerry run store that keeps nothing
+type emptyRunDB struct{}
+
+func (emptyRunDB) SaveTaskRun(ctx context.Context, taskRun *blueberry.TaskRun) error { return nil }
+
+func (emptyRunDB) SaveTaskRunLog(ctx context.Context, taskRunLog *blueberry.TaskRunLog) error {
+	return nil
+}
+
+func (emptyRunDB) GetTaskRuns(ctx context.Context) ([]blueberry.TaskRun, error) { return nil, nil }
+
+func (emptyRunDB) GetTaskRunByID(ctx context.Context, id int) (*blueberry.TaskRun, error) {
+	return nil, nil
+}
+
+func (emptyRunDB) GetTaskRunLogs(ctx context.Context, taskRunID int) ([]blueberry.TaskRunLog, error) {
+	return nil, nil
+}
+
+func (emptyRunDB) GetPaginatedTaskRunLogs(ctx context.Context, taskRunID int, level string, page, size int) ([]blueberry.TaskRunLog, int, error) {
+	return nil, 0, nil
+}
+
+func (emptyRunDB) GetPaginatedTaskRunsForTaskName(ctx context.Context, name string, page, limit int) ([]blueberry.TaskRun, error) {
+	return nil, nil
+}
+
+func (emptyRunDB) GetTaskRunsCountForTaskName(ctx context.Context, name string) (int, error) {
+	return 0, nil
+}
+
+func (emptyRunDB) Close() error { return nil }
+
+func TestRunWaitsForSignalShutdown(t *testing.T) {
+	t.Setenv("ENV", "production")
+	t.Setenv("WEB_AUTH_USER", "admin")
+	t.Setenv("WEB_AUTH_PASSWORD", "a-long-enough-passphrase")
+	t.Setenv("WEB_AUTH_PASSWORD_HASH", "")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ServerHost, cfg.ServerPort, _ = net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	cfg.ManagementPort = ""
+	cfg.HAEnabled = false
+
+	var seen events
+	store := closingStore{Memory: storagetest.NewMemory(clocktest.NewFake(time.Now())), events: &seen}
+	taskManager := idleTasks{events: &seen}
+	runState := recordingRunState{events: &seen}
+	a := &App{
+		Config:      cfg,
+		BlueBerry:   blueberry.NewBlueBerryInstance(emptyRunDB{}),
+		Storage:     store,
+		TaskManager: taskManager,
+		RunState:    runState,
+		API:         api.NewServer(cfg, store, taskManager, nil, nil, runState),
+	}
+
+	signals := make(chan os.Signal, 1)
+	ran := make(chan error, 1)
+	go func() { ran <- a.Run(signals) }()
+
+	url := "http://" + net.JoinHostPort(cfg.ServerHost, cfg.ServerPort) + "/healthz"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("API server not answering: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	signals <- syscall.SIGTERM
+	select {
+	case err := <-ran:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return after the signal")
+	}
+
+	// Everything after Start's return happened before Run's
+	want := []string{"drain", "record clean shutdown", "close storage"}
+	if got := seen.list(); !slices.Equal(got, want) {
+		t.Errorf("steps before Run returned = %q, want %q", got, want)
+	}
+
+	// A second Shutdown repeats nothing
+	a.Shutdown()
+	if got := seen.list(); len(got) != len(want) {
+		t.Errorf("steps after a second Shutdown = %q, want %q", got, want)
+	}
+}
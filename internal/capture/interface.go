@@ -0,0 +1,12 @@
+// internal/capture/interface.go
+package capture
+
+import "context"
+
+type CapturerInterface interface {
+	// Limit returns how many body bytes to keep from the subreddit's next
+	// response, or 0 when it should not be captured
+	Limit(ctx context.Context, subreddit string) int
+	// Capture saves a body read from endpoint; truncated means it was cut at the limit
+	Capture(ctx context.Context, subreddit, endpoint string, body []byte, truncated bool)
+}
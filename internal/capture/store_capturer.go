@@ -0,0 +1,109 @@
+// internal/capture/store_capturer.go
+package capture
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// Ensure StoreCapturer implements CapturerInterface
+var _ CapturerInterface = (*StoreCapturer)(nil)
+
+// secretParams are query parameter name fragments whose values are redacted
+var secretParams = []string{"token", "key", "secret", "password", "auth", "signature"}
+
+// StoreCapturer saves raw responses to the raw_captures collection, at most
+// dailyLimit per subreddit per UTC day. Capture failures are logged, never
+// returned, so debugging can't break a run.
+type StoreCapturer struct {
+	store      storage.CaptureStore
+	all        bool
+	subreddits map[string]bool
+	maxBytes   int
+	dailyLimit int
+	ttl        time.Duration
+}
+
+// NewStoreCapturer captures responses of the comma-separated subreddits in
+// targets, or of every subreddit when targets is "all"
+func NewStoreCapturer(store storage.CaptureStore, targets string, maxBytes, dailyLimit int, ttl time.Duration) *StoreCapturer {
+	c := &StoreCapturer{
+		store:      store,
+		subreddits: make(map[string]bool),
+		maxBytes:   maxBytes,
+		dailyLimit: dailyLimit,
+		ttl:        ttl,
+	}
+	for _, target := range strings.Split(targets, ",") {
+		target = strings.ToLower(strings.TrimSpace(target))
+		if target == "all" {
+			c.all = true
+		} else if target != "" {
+			c.subreddits[target] = true
+		}
+	}
+	return c
+}
+
+// Limit returns maxBytes while the subreddit is targeted and under its daily quota
+func (c *StoreCapturer) Limit(ctx context.Context, subreddit string) int {
+	if !c.all && !c.subreddits[strings.ToLower(subreddit)] {
+		return 0
+	}
+
+	dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+	count, err := c.store.CountRawCaptures(ctx, subreddit, dayStart)
+	if err != nil {
+		log.Printf("Raw capture quota check failed for r/%s: %v", subreddit, err)
+		return 0
+	}
+	if count >= int64(c.dailyLimit) {
+		return 0
+	}
+	return c.maxBytes
+}
+
+// Capture stores the body with the redacted request URL
+func (c *StoreCapturer) Capture(ctx context.Context, subreddit, endpoint string, body []byte, truncated bool) {
+	now := time.Now().UTC()
+	capture := &models.RawCapture{
+		Subreddit:  subreddit,
+		RequestURL: RedactURL(endpoint),
+		Body:       string(body),
+		Size:       len(body),
+		Truncated:  truncated,
+		CapturedAt: now,
+		ExpiresAt:  now.Add(c.ttl),
+	}
+	if err := c.store.InsertRawCapture(ctx, capture); err != nil {
+		log.Printf("Failed to save raw capture for r/%s: %v", subreddit, err)
+	}
+}
+
+// RedactURL drops credentials from a URL and masks query parameters that look secret
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "[unparseable url]"
+	}
+	u.User = nil
+
+	query := u.Query()
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, secret := range secretParams {
+			if strings.Contains(lower, secret) {
+				query.Set(name, "REDACTED")
+				break
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,115 @@
+// internal/client/coalesce.go
+package client
+
+import (
+	"context"
+	"sync"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// coalesceBucketSeconds is the granularity since timestamps are rounded down
+// to, so runs started seconds apart share one request
+const coalesceBucketSeconds = 60
+
+type noCoalesceKey struct{}
+
+// NoCoalesce returns a context whose post fetches always make their own
+// upstream request instead of joining an identical one in flight. Backfills
+// use it so they never depend on another run's request.
+func NoCoalesce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCoalesceKey{}, true)
+}
+
+func coalescingDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCoalesceKey{}).(bool)
+	return disabled
+}
+
+// coalesceSince rounds a since timestamp down to its bucket. Open bounds
+// (zero) stay open. The rounded value is what gets requested, so every
+// caller sharing the request receives at least the posts it asked for.
+func coalesceSince(since int64) int64 {
+	if since <= 0 {
+		return since
+	}
+	return since - since%coalesceBucketSeconds
+}
+
+// coalescer shares one upstream fetch among concurrent callers asking for the
+// same key. The fetch runs detached from any single caller's context: a
+// caller that gives up stops waiting, and the fetch is cancelled only once
+// every caller has.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*sharedFetch
+}
+
+type sharedFetch struct {
+	done    chan struct{}
+	posts   []models.IngestionPost
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*sharedFetch)}
+}
+
+// do runs fetch, or joins the identical fetch already in flight, and returns
+// the caller's own copy of the posts
+func (g *coalescer) do(ctx context.Context, key string, fetch func(context.Context) ([]models.IngestionPost, error)) ([]models.IngestionPost, error) {
+	if g == nil || coalescingDisabled(ctx) {
+		return fetch(ctx)
+	}
+
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		// Keeps the first caller's values (capture settings) but not its
+		// cancellation; the HTTP client's timeout still bounds the request
+		shared, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &sharedFetch{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go g.run(shared, key, call, fetch)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return append([]models.IngestionPost(nil), call.posts...), nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			g.forget(key, call)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (g *coalescer) run(ctx context.Context, key string, call *sharedFetch, fetch func(context.Context) ([]models.IngestionPost, error)) {
+	posts, err := fetch(ctx)
+	call.cancel()
+
+	g.mu.Lock()
+	call.posts, call.err = posts, err
+	g.forget(key, call)
+	g.mu.Unlock()
+	close(call.done)
+}
+
+// forget drops call from the in-flight set unless a newer fetch replaced it;
+// g.mu must be held
+func (g *coalescer) forget(key string, call *sharedFetch) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// blockingServer serves postsBody(3) once release is closed and counts the
+// requests it received
+type blockingServer struct {
+	*httptest.Server
+	hits      atomic.Int32
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func newBlockingServer(t *testing.T) *blockingServer {
+	t.Helper()
+	s := &blockingServer{release: make(chan struct{}), cancelled: make(chan struct{}, 1)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		select {
+		case <-s.release:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(postsBody(3)))
+		case <-r.Context().Done():
+			s.cancelled <- struct{}{}
+		}
+	}))
+	t.Cleanup(func() {
+		select {
+		case <-s.release:
+		default:
+			close(s.release)
+		}
+		s.Close()
+	})
+	return s
+}
+
+// waitForWaiters blocks until n callers share the in-flight fetch of key
+func waitForWaiters(t *testing.T, c *IngestionClient, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.inflight.mu.Lock()
+		call := c.inflight.calls[key]
+		waiters := 0
+		if call != nil {
+			waiters = call.waiters
+		}
+		c.inflight.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers share %s, want %d", waiters, key, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// since is a minute boundary; the callers below ask for times within it
+const coalesceTestSince = 1714557600
+
+func TestCoalesceParallelCallers(t *testing.T) {
+	const callers = 20
+	server := newBlockingServer(t)
+	c := newTestClient(t, server.Server, 0)
+
+	results := make([][]models.IngestionPost, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Since timestamps within one minute share the request
+			results[i], errs[i] = c.GetSubredditPosts(context.Background(), "golang", 25, coalesceTestSince+int64(i), 0)
+		}(i)
+	}
+	waitForWaiters(t, c, "subreddit|golang|25|1714557600|0", callers)
+	close(server.release)
+	wg.Wait()
+
+	if got := server.hits.Load(); got != 1 {
+		t.Fatalf("upstream received %d requests for %d parallel callers, want 1", got, callers)
+	}
+	for i := range results {
+		if errs[i] != nil || len(results[i]) != 3 {
+			t.Fatalf("caller %d got %d posts, error %v; want 3 posts", i, len(results[i]), errs[i])
+		}
+	}
+	// Every caller owns its copy
+	results[0][0].Title = "changed"
+	if results[1][0].Title == "changed" {
+		t.Error("callers share one slice of posts")
+	}
+	if len(c.inflight.calls) != 0 {
+		t.Errorf("%d fetches still in flight after every caller returned", len(c.inflight.calls))
+	}
+}
+
+func TestCoalesceKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func(i int) context.Context
+		call     func(c *IngestionClient, ctx context.Context, i int) error
+		wantHits int32
+	}{
+		{
+			name: "NoCoalesce makes its own request",
+			ctx:  func(int) context.Context { return NoCoalesce(context.Background()) },
+			call: func(c *IngestionClient, ctx context.Context, i int) error {
+				_, err := c.GetSubredditPosts(ctx, "golang", 25, coalesceTestSince, 0)
+				return err
+			},
+			wantHits: 4,
+		},
+		{
+			name: "since in different minutes",
+			call: func(c *IngestionClient, ctx context.Context, i int) error {
+				_, err := c.GetSubredditPosts(ctx, "golang", 25, coalesceTestSince+int64(i)*60, 0)
+				return err
+			},
+			wantHits: 4,
+		},
+		{
+			name: "different limits",
+			call: func(c *IngestionClient, ctx context.Context, i int) error {
+				_, err := c.GetSubredditPosts(ctx, "golang", 25+i, coalesceTestSince, 0)
+				return err
+			},
+			wantHits: 4,
+		},
+		{
+			name: "subreddit names differing in case",
+			call: func(c *IngestionClient, ctx context.Context, i int) error {
+				name := "golang"
+				if i%2 == 1 {
+					name = "GoLang"
+				}
+				_, err := c.GetSubredditPosts(ctx, name, 25, coalesceTestSince, 0)
+				return err
+			},
+			wantHits: 1,
+		},
+		{
+			name: "users",
+			call: func(c *IngestionClient, ctx context.Context, i int) error {
+				_, err := c.GetUserPosts(ctx, "spez", 25, coalesceTestSince+int64(i))
+				return err
+			},
+			wantHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newBlockingServer(t)
+			c := newTestClient(t, server.Server, 0)
+
+			const callers = 4
+			errs := make(chan error, callers)
+			for i := 0; i < callers; i++ {
+				ctx := context.Background()
+				if tt.ctx != nil {
+					ctx = tt.ctx(i)
+				}
+				go func(i int) { errs <- tt.call(c, ctx, i) }(i)
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for server.hits.Load() < tt.wantHits && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			// Give callers that would wrongly make another request the time to
+			time.Sleep(20 * time.Millisecond)
+			close(server.release)
+			for i := 0; i < callers; i++ {
+				if err := <-errs; err != nil {
+					t.Errorf("caller error = %v", err)
+				}
+			}
+			if got := server.hits.Load(); got != tt.wantHits {
+				t.Errorf("upstream received %d requests, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestCoalesceCancellation(t *testing.T) {
+	const key = "subreddit|golang|25|1714557600|0"
+
+	t.Run("one caller cancelling leaves the request to the others", func(t *testing.T) {
+		server := newBlockingServer(t)
+		c := newTestClient(t, server.Server, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelled := make(chan error, 1)
+		go func() {
+			_, err := c.GetSubredditPosts(ctx, "golang", 25, coalesceTestSince, 0)
+			cancelled <- err
+		}()
+		waiting := make(chan []models.IngestionPost, 1)
+		go func() {
+			posts, _ := c.GetSubredditPosts(context.Background(), "golang", 25, coalesceTestSince, 0)
+			waiting <- posts
+		}()
+		waitForWaiters(t, c, key, 2)
+
+		cancel()
+		if err := <-cancelled; !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled caller error = %v, want context.Canceled", err)
+		}
+		waitForWaiters(t, c, key, 1)
+		close(server.release)
+		if posts := <-waiting; len(posts) != 3 {
+			t.Errorf("remaining caller got %d posts, want 3", len(posts))
+		}
+		if got := server.hits.Load(); got != 1 {
+			t.Errorf("upstream received %d requests, want 1", got)
+		}
+	})
+
+	t.Run("the last caller cancelling cancels the request", func(t *testing.T) {
+		server := newBlockingServer(t)
+		c := newTestClient(t, server.Server, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.GetSubredditPosts(ctx, "golang", 25, coalesceTestSince, 0)
+			}()
+		}
+		waitForWaiters(t, c, key, 3)
+		cancel()
+		wg.Wait()
+
+		select {
+		case <-server.cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("upstream request not cancelled after every caller gave up")
+		}
+		// A new caller starts a fresh request rather than joining the cancelled one
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.GetSubredditPosts(context.Background(), "golang", 25, coalesceTestSince, 0)
+			done <- err
+		}()
+		waitForWaiters(t, c, key, 1)
+		close(server.release)
+		if err := <-done; err != nil {
+			t.Errorf("new caller error = %v", err)
+		}
+		if got := server.hits.Load(); got != 2 {
+			t.Errorf("upstream received %d requests, want 2", got)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// internal/client/decode.go
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Ingestion API response versions
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+)
+
+// epochSeconds decodes a numeric epoch timestamp
+type epochSeconds float64
+
+func (e epochSeconds) Time() time.Time {
+	if e == 0 {
+		return time.Time{}
+	}
+	seconds, fraction := math.Modf(float64(e))
+	return time.Unix(int64(seconds), int64(fraction*1e9)).UTC()
+}
+
+// versionMappings are the response formats as field mappings. v1 posts are
+// read as they are; v2 wraps the v1 fields as {"kind": "t3", "data": {...}},
+// with created_at as epoch seconds (fractional).
+var versionMappings = map[string]FieldMapping{
+	APIVersion1: {},
+	APIVersion2: v2Mapping(),
+}
+
+func v2Mapping() FieldMapping {
+	mapping := make(FieldMapping, len(mappingTargets))
+	for target := range mappingTargets {
+		mapping[target] = FieldRule{From: "data." + target}
+	}
+	mapping["created_at"] = FieldRule{From: "data.created_at", Convert: ConvertEpochSeconds}
+	return mapping
+}
+
+// postVersion detects the format of one post by probing for the v2 "data"
+// wrapper, so a response in the other version's shape still decodes during
+// mixed deployments
+func postVersion(object map[string]json.RawMessage) string {
+	raw, _ := lookupKey(object, "data")
+	if wrapper := bytes.TrimSpace(raw); len(wrapper) > 0 && wrapper[0] == '{' {
+		return APIVersion2
+	}
+	return APIVersion1
+}
+
+// postsResponse is the {"posts": [...], "meta": {...}} envelope shared by
+// both versions, with posts left raw until their format is known
+type postsResponse struct {
+	Posts []json.RawMessage      `json:"posts"`
+	Meta  map[string]interface{} `json:"meta"`
+}
+
+// decoded reads each post through its format's mapping with mapping's rules
+// on top, and returns how many posts were in a version other than expected
+func (r *postsResponse) decoded(expected string, mapping FieldMapping) ([]models.IngestionPost, int, error) {
+	posts := make([]models.IngestionPost, 0, len(r.Posts))
+	mismatched := 0
+	for i, raw := range r.Posts {
+		post, version, err := mapping.decode(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decoding post %d: %w", i, err)
+		}
+		if version != expected {
+			mismatched++
+		}
+		posts = append(posts, post)
+		r.Posts[i] = nil // so the raw page is released as it is decoded
+	}
+	return posts, mismatched, nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostsResponseDecodesBothVersions(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           string
+		expected       string
+		wantIDs        []string
+		wantMismatched int
+	}{
+		{
+			name:     "v1 as expected",
+			body:     `{"posts":[{"id":"t3_a","title":"a","created_at":"2024-05-01T12:00:00.5+02:00"}],"meta":{}}`,
+			expected: APIVersion1,
+			wantIDs:  []string{"t3_a"},
+		},
+		{
+			name:     "v2 as expected",
+			body:     `{"posts":[{"kind":"t3","data":{"id":"t3_b","title":"b","created_at":1714557600.5}}],"meta":{}}`,
+			expected: APIVersion2,
+			wantIDs:  []string{"t3_b"},
+		},
+		{
+			name:           "v2 while expecting v1",
+			body:           `{"posts":[{"kind":"t3","data":{"id":"t3_b","title":"b","created_at":1714557600.5}}],"meta":{}}`,
+			expected:       APIVersion1,
+			wantIDs:        []string{"t3_b"},
+			wantMismatched: 1,
+		},
+		{
+			name:           "mixed page",
+			body:           `{"posts":[{"id":"t3_a","title":"a","created_at":"2024-05-01T10:00:00.5Z"},{"kind":"t3","data":{"id":"t3_b","title":"b","created_at":1714557600.5}}]}`,
+			expected:       APIVersion2,
+			wantIDs:        []string{"t3_a", "t3_b"},
+			wantMismatched: 1,
+		},
+		{
+			name:     "v1 post with a non-object data field",
+			body:     `{"posts":[{"id":"t3_a","title":"a","data":"x","created_at":"2024-05-01T10:00:00.5Z"}]}`,
+			expected: APIVersion1,
+			wantIDs:  []string{"t3_a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response postsResponse
+			if err := json.Unmarshal([]byte(tt.body), &response); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			posts, mismatched, err := response.decoded(tt.expected, nil)
+			if err != nil {
+				t.Fatalf("decoded() error = %v", err)
+			}
+			if mismatched != tt.wantMismatched {
+				t.Errorf("mismatched = %d, want %d", mismatched, tt.wantMismatched)
+			}
+			if len(posts) != len(tt.wantIDs) {
+				t.Fatalf("got %d posts, want %d", len(posts), len(tt.wantIDs))
+			}
+			for i, post := range posts {
+				if post.ID != tt.wantIDs[i] {
+					t.Errorf("post %d ID = %q, want %q", i, post.ID, tt.wantIDs[i])
+				}
+				if !post.CreatedAt.Equal(created) || post.CreatedAt.Location() != time.UTC {
+					t.Errorf("post %d CreatedAt = %v, want %v in UTC", i, post.CreatedAt, created)
+				}
+			}
+		})
+	}
+}
+
+// decodeFixture renders a page of n posts in the given API version's shape
+func decodeFixture(version string, n int) []byte {
+	posts := make([]string, n)
+	for i := range posts {
+		if version == APIVersion2 {
+			posts[i] = fmt.Sprintf(`{"kind":"t3","data":{"id":"t3_%d","title":"post %d","body":"some body text","author":"someone","score":%d,"url":"https://example.com/%d","is_nsfw":false,"created_at":1714557600.5}}`, i, i, i, i)
+		} else {
+			posts[i] = fmt.Sprintf(`{"id":"t3_%d","title":"post %d","body":"some body text","author":"someone","score":%d,"url":"https://example.com/%d","is_nsfw":false,"created_at":"2024-05-01T10:00:00.5Z"}`, i, i, i, i)
+		}
+	}
+	return []byte(`{"posts":[` + strings.Join(posts, ",") + `],"meta":{}}`)
+}
+
+func BenchmarkDecodePosts(b *testing.B) {
+	for _, version := range []string{APIVersion1, APIVersion2} {
+		for _, size := range []int{100, 1000} {
+			body := decodeFixture(version, size)
+			b.Run(fmt.Sprintf("%s/%d", version, size), func(b *testing.B) {
+				b.SetBytes(int64(len(body)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					var response postsResponse
+					if err := json.Unmarshal(body, &response); err != nil {
+						b.Fatal(err)
+					}
+					if posts, _, err := response.decoded(version, nil); err != nil || len(posts) != size {
+						b.Fatalf("decoded %d posts, %v; want %d", len(posts), err, size)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// internal/client/errors.go
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"reddit-orchestrator/internal/capture"
+)
+
+// RateLimitedError is returned when the ingestion API answers 429 or 503.
+// ResumeAt is derived from the Retry-After header, or zero when absent.
+type RateLimitedError struct {
+	StatusCode int
+	ResumeAt   time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.ResumeAt.IsZero() {
+		return fmt.Sprintf("ingestion API rate limited (status %d)", e.StatusCode)
+	}
+	return fmt.Sprintf("ingestion API rate limited (status %d), retry after %s",
+		e.StatusCode, e.ResumeAt.Format(time.RFC3339))
+}
+
+// ResponseTooLargeError is returned when a response body exceeds the
+// configured limit. ContentLength is -1 when the server did not declare it.
+type ResponseTooLargeError struct {
+	Limit         int64
+	ContentLength int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.ContentLength < 0 {
+		return fmt.Sprintf("ingestion API response exceeds %d bytes (content-length not declared)", e.Limit)
+	}
+	return fmt.Sprintf("ingestion API response exceeds %d bytes (content-length: %d)", e.Limit, e.ContentLength)
+}
+
+// APIError is returned for other non-200 responses. Body holds at most the
+// first 4KB of the response and Endpoint is the request URL with credentials
+// redacted. Retryable is set for server errors and timeouts, which may pass
+// on a later attempt.
+type APIError struct {
+	StatusCode int
+	Body       string
+	Endpoint   string
+	Retryable  bool
+}
+
+func newAPIError(statusCode int, body, endpoint string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Body:       body,
+		Endpoint:   capture.RedactURL(endpoint),
+		Retryable:  statusCode >= http.StatusInternalServerError || statusCode == http.StatusRequestTimeout,
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d from %s: %s", e.StatusCode, e.Endpoint, e.Body)
+}
+
+// SubredditUnavailableError is returned by ValidateSubreddit when the
+// ingestion API rejects the subreddit: 404 when it does not exist, 403 when
+// it is private or banned, 400 when the name is invalid
+type SubredditUnavailableError struct {
+	Subreddit string
+	Status    *APIError
+}
+
+func (e *SubredditUnavailableError) Error() string {
+	reason := "was rejected"
+	switch e.Status.StatusCode {
+	case http.StatusNotFound:
+		reason = "does not exist"
+	case http.StatusForbidden:
+		reason = "is private or banned"
+	}
+	return fmt.Sprintf("r/%s %s according to the ingestion API (%v)", e.Subreddit, reason, e.Status)
+}
+
+func (e *SubredditUnavailableError) Unwrap() error {
+	return e.Status
+}
+
+// IsNotFound reports whether err wraps a 404 APIError
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsRateLimited reports whether err wraps a RateLimitedError
+func IsRateLimited(err error) bool {
+	_, ok := AsRateLimited(err)
+	return ok
+}
+
+// IsServerError reports whether err wraps a retryable APIError
+func IsServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable
+}
+
+// AsRateLimited reports whether err wraps a RateLimitedError
+func AsRateLimited(err error) (*RateLimitedError, bool) {
+	var rateLimited *RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return rateLimited, true
+	}
+	return nil, false
+}
+
+// parseRetryAfter understands both delta-seconds and HTTP-date values
+func parseRetryAfter(value string, now time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return date
+	}
+
+	return time.Time{}
+}
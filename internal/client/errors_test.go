@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientCalls are the client methods that fetch from the ingestion API,
+// each returning only its error
+var clientCalls = map[string]func(c *IngestionClient) error{
+	"GetSubredditPosts": func(c *IngestionClient) error {
+		_, err := c.GetSubredditPosts(context.Background(), "golang", 10, 0, 0)
+		return err
+	},
+	"GetUserPosts": func(c *IngestionClient) error {
+		_, err := c.GetUserPosts(context.Background(), "someone", 10, 0)
+		return err
+	},
+	"GetTopPosts": func(c *IngestionClient) error {
+		_, err := c.GetTopPosts(context.Background(), "golang", TopRangeAll, 10, "")
+		return err
+	},
+	"GetTopPostsAt": func(c *IngestionClient) error {
+		_, err := c.GetTopPostsAt(context.Background(), "golang", TopRangeAll, 10, 100)
+		return err
+	},
+	"GetSubredditAbout": func(c *IngestionClient) error {
+		_, err := c.GetSubredditAbout(context.Background(), "golang")
+		return err
+	},
+}
+
+func TestErrorClasses(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		retryAfter    string
+		body          string
+		wantNotFound  bool
+		wantLimited   bool
+		wantServer    bool
+		wantResumeIn  time.Duration
+		wantBodyBytes int
+	}{
+		{name: "not found", status: http.StatusNotFound, body: `{"error":"no such subreddit"}`, wantNotFound: true},
+		{name: "forbidden", status: http.StatusForbidden, body: `{"error":"private"}`},
+		{name: "bad request", status: http.StatusBadRequest, body: `{"error":"invalid name"}`},
+		{name: "rate limited", status: http.StatusTooManyRequests, retryAfter: "120", wantLimited: true, wantResumeIn: 120 * time.Second},
+		{name: "unavailable", status: http.StatusServiceUnavailable, wantLimited: true},
+		{name: "server error", status: http.StatusInternalServerError, body: "boom", wantServer: true},
+		{name: "bad gateway", status: http.StatusBadGateway, body: "upstream down", wantServer: true},
+		{name: "request timeout", status: http.StatusRequestTimeout, wantServer: true},
+		{name: "long body is truncated", status: http.StatusInternalServerError, body: strings.Repeat("x", 3*maxErrorBodyBytes), wantServer: true, wantBodyBytes: maxErrorBodyBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			c := newTestClient(t, server, 0)
+
+			for method, call := range clientCalls {
+				start := time.Now()
+				err := call(c)
+				if err == nil {
+					t.Fatalf("%s() succeeded on a %d", method, tt.status)
+				}
+				if got := IsNotFound(err); got != tt.wantNotFound {
+					t.Errorf("%s(): IsNotFound(%v) = %v, want %v", method, err, got, tt.wantNotFound)
+				}
+				if got := IsRateLimited(err); got != tt.wantLimited {
+					t.Errorf("%s(): IsRateLimited(%v) = %v, want %v", method, err, got, tt.wantLimited)
+				}
+				if got := IsServerError(err); got != tt.wantServer {
+					t.Errorf("%s(): IsServerError(%v) = %v, want %v", method, err, got, tt.wantServer)
+				}
+
+				if tt.wantLimited {
+					rateLimited, _ := AsRateLimited(err)
+					if rateLimited.StatusCode != tt.status {
+						t.Errorf("%s(): rate limit status = %d, want %d", method, rateLimited.StatusCode, tt.status)
+					}
+					if tt.wantResumeIn == 0 && !rateLimited.ResumeAt.IsZero() {
+						t.Errorf("%s(): ResumeAt = %v without a Retry-After", method, rateLimited.ResumeAt)
+					}
+					if tt.wantResumeIn > 0 && rateLimited.ResumeAt.Sub(start).Round(time.Second) != tt.wantResumeIn {
+						t.Errorf("%s(): ResumeAt = %v, want %v after the request", method, rateLimited.ResumeAt, tt.wantResumeIn)
+					}
+					continue
+				}
+
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("%s() error = %v, want an APIError", method, err)
+				}
+				if apiErr.StatusCode != tt.status || apiErr.Retryable != tt.wantServer {
+					t.Errorf("%s(): APIError = %d, retryable %v; want %d, %v", method, apiErr.StatusCode, apiErr.Retryable, tt.status, tt.wantServer)
+				}
+				if !strings.HasPrefix(apiErr.Endpoint, server.URL+"/") {
+					t.Errorf("%s(): endpoint = %q, want one on %s", method, apiErr.Endpoint, server.URL)
+				}
+				wantBody := tt.body
+				if tt.wantBodyBytes > 0 {
+					wantBody = tt.body[:tt.wantBodyBytes]
+				}
+				if apiErr.Body != wantBody {
+					t.Errorf("%s(): body has %d bytes, want %d", method, len(apiErr.Body), len(wantBody))
+				}
+			}
+		})
+	}
+}
+
+func TestAPIErrorRedactsEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	base := strings.Replace(server.URL, "http://", "http://ingest:hunter2@", 1)
+	c := NewIngestionClient(base, 5*time.Second, 0, APIVersion1, nil, nil)
+	_, err := c.GetSubredditPosts(context.Background(), "golang", 10, 0, 0)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetSubredditPosts() error = %v, want an APIError", err)
+	}
+	if strings.Contains(apiErr.Endpoint, "hunter2") || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("error %q leaks the credentials", err)
+	}
+}
+
+func TestValidateSubredditErrorClasses(t *testing.T) {
+	tests := []struct {
+		status          int
+		wantUnavailable string
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNotFound, wantUnavailable: "r/golang does not exist"},
+		{status: http.StatusForbidden, wantUnavailable: "r/golang is private or banned"},
+		{status: http.StatusBadRequest, wantUnavailable: "r/golang was rejected"},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"posts":[]}`))
+			}))
+			defer server.Close()
+
+			err := newTestClient(t, server, 0).ValidateSubreddit(context.Background(), "golang")
+			var unavailable *SubredditUnavailableError
+			switch {
+			case tt.wantUnavailable != "":
+				if !errors.As(err, &unavailable) || !strings.Contains(err.Error(), tt.wantUnavailable) {
+					t.Fatalf("ValidateSubreddit() error = %v, want %q", err, tt.wantUnavailable)
+				}
+				if unavailable.Status.StatusCode != tt.status {
+					t.Errorf("wrapped status = %d, want %d", unavailable.Status.StatusCode, tt.status)
+				}
+			case tt.status == http.StatusOK:
+				if err != nil {
+					t.Fatalf("ValidateSubreddit() error = %v", err)
+				}
+			default:
+				// The probe failed; that says nothing about the subreddit
+				if err == nil || errors.As(err, &unavailable) {
+					t.Errorf("ValidateSubreddit() error = %v, want a failed probe", err)
+				}
+			}
+		})
+	}
+}
@@ -4,32 +4,147 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"reddit-orchestrator/internal/capture"
 	"reddit-orchestrator/internal/models"
 )
 
+// maxErrorBodyBytes caps how much of a non-200 body is read into the error message
+const maxErrorBodyBytes = 4 << 10
+
+// versionDetectTimeout bounds the /version call made before the first posts request
+const versionDetectTimeout = 10 * time.Second
+
 type IngestionClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL          string
+	httpClient       *http.Client
+	maxResponseBytes int64
+
+	// version is the response format we expect; posts in the other format
+	// are still decoded, see postVersion. Empty until detected.
+	versionMu sync.Mutex
+	version   string
+
+	// mapping overrides how fields of the v1/v2 formats are read, for a
+	// nonstandard API
+	mapping FieldMapping
+
+	// capturer records raw subreddit responses (CAPTURE_RAW_RESPONSES); nil disables capture
+	capturer capture.CapturerInterface
+
+	// inflight shares identical concurrent post fetches, see NoCoalesce
+	inflight *coalescer
 }
 
-func NewIngestionClient(baseURL string, timeout time.Duration) *IngestionClient {
-	return &IngestionClient{
+// NewIngestionClient creates a client; maxResponseBytes <= 0 disables the size
+// guard. An empty version is detected from the API's /version endpoint before
+// the first posts request, see Init. A non-nil mapping overrides fields of
+// whichever format each post is in.
+func NewIngestionClient(baseURL string, timeout time.Duration, maxResponseBytes int64, version string, mapping FieldMapping, capturer capture.CapturerInterface) *IngestionClient {
+	c := &IngestionClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		maxResponseBytes: maxResponseBytes,
+		version:          version,
+		mapping:          mapping,
+		capturer:         capturer,
+		inflight:         newCoalescer(),
+	}
+
+	if mapping != nil {
+		log.Printf("Using field mapping for ingestion API %s (%d mapped fields)", baseURL, len(mapping))
+	}
+	if version != "" {
+		log.Printf("Using ingestion API response format %s", version)
+	}
+
+	return c
+}
+
+// Init detects the response format now rather than on the first posts
+// request. The format is detected once; when /version is unreachable the
+// client assumes v1 for this call and detection is retried on the next.
+func (c *IngestionClient) Init(ctx context.Context) error {
+	_, err := c.apiVersion(ctx)
+	return err
+}
+
+// apiVersion returns the expected response format, detecting it first if needed
+func (c *IngestionClient) apiVersion(ctx context.Context) (string, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if c.version != "" {
+		return c.version, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, versionDetectTimeout)
+	defer cancel()
+	detected, err := c.detectVersion(ctx)
+	if err != nil {
+		log.Printf("Ingestion API version detection failed, assuming %s: %v", APIVersion1, err)
+		return APIVersion1, err
+	}
+	c.version = detected
+	log.Printf("Using ingestion API response format %s", c.version)
+	return c.version, nil
+}
+
+// APIVersion returns the response format the client expects, or "" before
+// it has been detected
+func (c *IngestionClient) APIVersion() string {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.version
+}
+
+// detectVersion reads {"version": "..."} from /version; a 404 means the API
+// predates versioning and serves v1
+func (c *IngestionClient) detectVersion(ctx context.Context) (string, error) {
+	var response struct {
+		Version string `json:"version"`
+	}
+	if err := c.makeRequest(ctx, fmt.Sprintf("%s/version", c.baseURL), &response, nil); err != nil {
+		if IsNotFound(err) {
+			return APIVersion1, nil
+		}
+		return "", err
+	}
+
+	switch version := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(response.Version)), "v"); {
+	case version == "1" || strings.HasPrefix(version, "1."):
+		return APIVersion1, nil
+	case version == "2" || strings.HasPrefix(version, "2."):
+		return APIVersion2, nil
+	default:
+		return "", fmt.Errorf("unsupported ingestion API version %q", response.Version)
 	}
 }
 
-// GetSubredditPosts calls the ingestion API to fetch subreddit posts
-func (c *IngestionClient) GetSubredditPosts(ctx context.Context, subreddit string, limit int, sinceTimestamp int64) ([]models.IngestionPost, error) {
+// GetSubredditPosts calls the ingestion API to fetch subreddit posts.
+// A zero sinceTimestamp/untilTimestamp leaves that bound open. Concurrent
+// calls for the same subreddit, limit and bounds (since rounded down to the
+// minute) share one request unless ctx was made with NoCoalesce.
+func (c *IngestionClient) GetSubredditPosts(ctx context.Context, subreddit string, limit int, sinceTimestamp, untilTimestamp int64) ([]models.IngestionPost, error) {
+	sinceTimestamp = coalesceSince(sinceTimestamp)
+	key := fmt.Sprintf("subreddit|%s|%d|%d|%d", strings.ToLower(subreddit), limit, sinceTimestamp, untilTimestamp)
+	return c.inflight.do(ctx, key, func(ctx context.Context) ([]models.IngestionPost, error) {
+		return c.getSubredditPosts(ctx, subreddit, limit, sinceTimestamp, untilTimestamp)
+	})
+}
+
+func (c *IngestionClient) getSubredditPosts(ctx context.Context, subreddit string, limit int, sinceTimestamp, untilTimestamp int64) ([]models.IngestionPost, error) {
 	params := url.Values{}
 	params.Set("subreddit", subreddit)
 	if limit > 0 {
@@ -38,19 +153,194 @@ func (c *IngestionClient) GetSubredditPosts(ctx context.Context, subreddit strin
 	if sinceTimestamp > 0 {
 		params.Set("since_timestamp", strconv.FormatInt(sinceTimestamp, 10))
 	}
+	if untilTimestamp > 0 {
+		params.Set("until_timestamp", strconv.FormatInt(untilTimestamp, 10))
+	}
 
 	endpoint := fmt.Sprintf("%s/subreddit?%s", c.baseURL, params.Encode())
-	
+
+	// Bodies are saved even when decoding fails; those are the ones worth seeing
+	var captured *captureBuffer
+	if c.capturer != nil {
+		if limit := c.capturer.Limit(ctx, subreddit); limit > 0 {
+			captured = &captureBuffer{limit: limit}
+			defer func() {
+				if len(captured.data) > 0 {
+					c.capturer.Capture(ctx, subreddit, endpoint, captured.data, captured.truncated)
+				}
+			}()
+		}
+	}
+	return c.fetchPosts(ctx, endpoint, limit, captured)
+}
+
+// GetUserPosts calls the ingestion API to fetch a user's submissions across
+// subreddits. Each post carries its own subreddit. Identical concurrent calls
+// are coalesced as in GetSubredditPosts.
+func (c *IngestionClient) GetUserPosts(ctx context.Context, username string, limit int, sinceTimestamp int64) ([]models.IngestionPost, error) {
+	sinceTimestamp = coalesceSince(sinceTimestamp)
+	key := fmt.Sprintf("user|%s|%d|%d", strings.ToLower(username), limit, sinceTimestamp)
+	return c.inflight.do(ctx, key, func(ctx context.Context) ([]models.IngestionPost, error) {
+		return c.getUserPosts(ctx, username, limit, sinceTimestamp)
+	})
+}
+
+func (c *IngestionClient) getUserPosts(ctx context.Context, username string, limit int, sinceTimestamp int64) ([]models.IngestionPost, error) {
+	params := url.Values{}
+	params.Set("username", username)
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	if sinceTimestamp > 0 {
+		params.Set("since_timestamp", strconv.FormatInt(sinceTimestamp, 10))
+	}
+
+	endpoint := fmt.Sprintf("%s/user?%s", c.baseURL, params.Encode())
+	return c.fetchPosts(ctx, endpoint, limit, nil)
+}
+
+// Time ranges of GetTopPosts
+const (
+	TopRangeAll   = "all"
+	TopRangeYear  = "year"
+	TopRangeMonth = "month"
+)
+
+// TopPostsPage is one page of a subreddit's top posts; After is empty on
+// the last page
+type TopPostsPage struct {
+	Posts []models.IngestionPost
+	After string
+}
+
+// GetTopPosts fetches one page of a subreddit's posts ranked by score within
+// timeRange from /subreddit/top. after is the previous page's After, empty
+// for the first page; it is opaque, so a page can only be requested once the
+// one before it has arrived. Pages are never coalesced.
+func (c *IngestionClient) GetTopPosts(ctx context.Context, subreddit, timeRange string, limit int, after string) (*TopPostsPage, error) {
+	params := topParams(subreddit, timeRange, limit)
+	if after != "" {
+		params.Set("after", after)
+	}
+	return c.topPosts(ctx, params)
+}
+
+// GetTopPostsAt fetches the page of top posts starting at rank offset, for
+// an API that pages /subreddit/top by offset as well as by cursor. Unlike
+// after cursors, offsets let several pages be requested at once.
+func (c *IngestionClient) GetTopPostsAt(ctx context.Context, subreddit, timeRange string, limit, offset int) (*TopPostsPage, error) {
+	params := topParams(subreddit, timeRange, limit)
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+	return c.topPosts(ctx, params)
+}
+
+// topParams are the query parameters every /subreddit/top request has
+func topParams(subreddit, timeRange string, limit int) url.Values {
+	params := url.Values{}
+	params.Set("subreddit", subreddit)
+	params.Set("t", timeRange)
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	return params
+}
+
+func (c *IngestionClient) topPosts(ctx context.Context, params url.Values) (*TopPostsPage, error) {
+	posts, meta, err := c.decodePosts(ctx, fmt.Sprintf("%s/subreddit/top?%s", c.baseURL, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	page := &TopPostsPage{Posts: posts}
+	page.After, _ = meta["after"].(string)
+	return page, nil
+}
+
+// ValidateSubreddit checks with a one-post fetch that the ingestion API can
+// read the subreddit. A 400, 403 or 404 answer is returned as a
+// *SubredditUnavailableError; other errors mean the probe itself failed.
+func (c *IngestionClient) ValidateSubreddit(ctx context.Context, subreddit string) error {
+	params := url.Values{}
+	params.Set("subreddit", subreddit)
+	params.Set("limit", "1")
+
+	var response json.RawMessage
+	err := c.makeRequest(ctx, fmt.Sprintf("%s/subreddit?%s", c.baseURL, params.Encode()), &response, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+			return &SubredditUnavailableError{Subreddit: subreddit, Status: apiErr}
+		}
+	}
+	return err
+}
+
+// GetSubredditAbout reads {"subscribers", "public_description",
+// "created_utc", "over18"} from /subreddit/about. FetchedAt is left for the
+// caller; an *APIError with 404 means the API or the subreddit has none.
+func (c *IngestionClient) GetSubredditAbout(ctx context.Context, subreddit string) (*models.AboutInfo, error) {
+	params := url.Values{}
+	params.Set("subreddit", subreddit)
+
 	var response struct {
-		Posts []models.IngestionPost `json:"posts"`
-		Meta  map[string]interface{} `json:"meta"`
+		Subscribers       int          `json:"subscribers"`
+		PublicDescription string       `json:"public_description"`
+		CreatedUTC        epochSeconds `json:"created_utc"`
+		Over18            bool         `json:"over18"`
 	}
-	
-	if err := c.makeRequest(ctx, endpoint, &response); err != nil {
+	if err := c.makeRequest(ctx, fmt.Sprintf("%s/subreddit/about?%s", c.baseURL, params.Encode()), &response, nil); err != nil {
 		return nil, err
 	}
 
-	return response.Posts, nil
+	about := &models.AboutInfo{
+		Available:   true,
+		Subscribers: response.Subscribers,
+		Description: strings.TrimSpace(response.PublicDescription),
+		Over18:      response.Over18,
+	}
+	if created := response.CreatedUTC.Time(); !created.IsZero() {
+		about.SubredditCreatedAt = &created
+	}
+	return about, nil
+}
+
+// fetchPosts decodes a {"posts": [...], "meta": {...}} response in either
+// format, through the field mapping when there is one. A full page (limit
+// posts) is logged, since the window may hold more. A non-nil captured
+// receives a copy of the response body.
+func (c *IngestionClient) fetchPosts(ctx context.Context, endpoint string, limit int, captured *captureBuffer) ([]models.IngestionPost, error) {
+	posts, _, err := c.decodePosts(ctx, endpoint, captured)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(posts) == limit {
+		log.Printf("Ingestion API returned exactly the requested limit of %d posts; older posts may need pagination (%s)",
+			limit, endpoint)
+	}
+	return posts, nil
+}
+
+// decodePosts fetches and decodes a posts response, returning its meta object too
+func (c *IngestionClient) decodePosts(ctx context.Context, endpoint string, captured *captureBuffer) ([]models.IngestionPost, map[string]interface{}, error) {
+	// Both formats decode either way, so a failed detection only costs the log line
+	version, _ := c.apiVersion(ctx)
+
+	var response postsResponse
+	if err := c.makeRequest(ctx, endpoint, &response, captured); err != nil {
+		return nil, nil, err
+	}
+
+	posts, mismatched, err := response.decoded(version, c.mapping)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if mismatched > 0 {
+		log.Printf("Ingestion API returned %d of %d posts in a format other than %s; decoded them anyway",
+			mismatched, len(posts), version)
+	}
+	return posts, response.Meta, nil
 }
 
 // Health check method
@@ -69,13 +359,14 @@ func (c *IngestionClient) HealthCheck(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("ingestion API health check failed with status: %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return fmt.Errorf("ingestion API health check failed: %w", newAPIError(resp.StatusCode, string(body), endpoint))
 	}
 
 	return nil
 }
 
-func (c *IngestionClient) makeRequest(ctx context.Context, endpoint string, result interface{}) error {
+func (c *IngestionClient) makeRequest(ctx context.Context, endpoint string, result interface{}, captured *captureBuffer) error {
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
@@ -87,19 +378,56 @@ func (c *IngestionClient) makeRequest(ctx context.Context, endpoint string, resu
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return &RateLimitedError{
+			StatusCode: resp.StatusCode,
+			ResumeAt:   parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return newAPIError(resp.StatusCode, string(body), endpoint)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+	// Decode straight from the body rather than reading it whole first;
+	// posts stay raw only until postsResponse.decoded reads them
+	var body io.Reader = resp.Body
+	if c.maxResponseBytes > 0 {
+		if resp.ContentLength > c.maxResponseBytes {
+			return &ResponseTooLargeError{Limit: c.maxResponseBytes, ContentLength: resp.ContentLength}
+		}
+		body = http.MaxBytesReader(nil, resp.Body, c.maxResponseBytes)
+	}
+	if captured != nil {
+		body = io.TeeReader(body, captured)
 	}
 
-	if err := json.Unmarshal(body, result); err != nil {
+	if err := json.NewDecoder(body).Decode(result); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return &ResponseTooLargeError{Limit: c.maxResponseBytes, ContentLength: resp.ContentLength}
+		}
 		return fmt.Errorf("parsing response: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// captureBuffer keeps the first limit bytes written to it and drops the rest,
+// so teeing a response into it never fails the read
+type captureBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.data); room < len(p) {
+		b.data = append(b.data, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.data = append(b.data, p...)
+	}
+	return len(p), nil
+}
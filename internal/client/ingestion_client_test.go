@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a v1 client for server, so no /version call is made
+func newTestClient(t *testing.T, server *httptest.Server, maxResponseBytes int64) *IngestionClient {
+	t.Helper()
+	return NewIngestionClient(server.URL, 5*time.Second, maxResponseBytes, APIVersion1, nil, nil)
+}
+
+// postsBody renders a v1 response of n posts
+func postsBody(n int) string {
+	posts := make([]string, n)
+	for i := range posts {
+		posts[i] = fmt.Sprintf(`{"id":"t3_%d","title":"post %d","body":%q,"author":"someone","score":%d,"created_at":"2024-05-01T10:00:00Z"}`,
+			i, i, strings.Repeat("x", 100), i)
+	}
+	return `{"posts":[` + strings.Join(posts, ",") + `],"meta":{}}`
+}
+
+func TestResponseSizeGuard(t *testing.T) {
+	body := postsBody(50)
+
+	tests := []struct {
+		name          string
+		limit         int64
+		declareLength bool
+		wantErr       bool
+		wantLength    int64
+	}{
+		{name: "within limit", limit: int64(len(body)) + 1, declareLength: true},
+		{name: "guard disabled", limit: 0, declareLength: true},
+		{name: "declared length over limit", limit: 1024, declareLength: true, wantErr: true, wantLength: int64(len(body))},
+		{name: "chunked body over limit", limit: 1024, declareLength: false, wantErr: true, wantLength: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if tt.declareLength {
+					w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+					fmt.Fprint(w, body)
+					return
+				}
+				// Flushing before the end forces chunked encoding, so no length is declared
+				half := len(body) / 2
+				fmt.Fprint(w, body[:half])
+				w.(http.Flusher).Flush()
+				fmt.Fprint(w, body[half:])
+			}))
+			defer server.Close()
+
+			posts, err := newTestClient(t, server, tt.limit).GetSubredditPosts(context.Background(), "golang", 50, 0, 0)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("GetSubredditPosts() error = %v", err)
+				}
+				if len(posts) != 50 {
+					t.Errorf("got %d posts, want 50", len(posts))
+				}
+				return
+			}
+
+			var tooLarge *ResponseTooLargeError
+			if !errors.As(err, &tooLarge) {
+				t.Fatalf("GetSubredditPosts() error = %v, want a ResponseTooLargeError", err)
+			}
+			if tooLarge.Limit != tt.limit || tooLarge.ContentLength != tt.wantLength {
+				t.Errorf("error = %+v, want limit %d and content length %d", tooLarge, tt.limit, tt.wantLength)
+			}
+			if tt.wantLength > 0 && !strings.Contains(err.Error(), fmt.Sprint(tt.wantLength)) {
+				t.Errorf("error %q does not mention the content length", err)
+			}
+		})
+	}
+}
+
+func TestVersionDetectedOnFirstRequest(t *testing.T) {
+	v2Body := `{"posts":[{"kind":"t3","data":{"id":"t3_b","title":"b","created_at":1714557600}}],"meta":{}}`
+
+	tests := []struct {
+		name            string
+		configured      string
+		mapping         FieldMapping
+		versionStatus   int
+		versionBody     string
+		wantVersion     string
+		wantInitErr     bool
+		wantVersionHits int32
+	}{
+		{name: "configured v2", configured: APIVersion2, wantVersion: APIVersion2},
+		{name: "detected v2", versionStatus: http.StatusOK, versionBody: `{"version":"2.1"}`, wantVersion: APIVersion2, wantVersionHits: 1},
+		{name: "detected v1", versionStatus: http.StatusOK, versionBody: `{"version":"v1"}`, wantVersion: APIVersion1, wantVersionHits: 1},
+		{name: "no version endpoint", versionStatus: http.StatusNotFound, wantVersion: APIVersion1, wantVersionHits: 1},
+		{
+			name:            "detected with a field mapping",
+			mapping:         FieldMapping{"score": {From: "data.ups"}},
+			versionStatus:   http.StatusOK,
+			versionBody:     `{"version":"2"}`,
+			wantVersion:     APIVersion2,
+			wantVersionHits: 1,
+		},
+		{name: "unsupported version retried", versionStatus: http.StatusOK, versionBody: `{"version":"3"}`, wantInitErr: true, wantVersionHits: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var versionHits atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/version" {
+					versionHits.Add(1)
+					w.WriteHeader(tt.versionStatus)
+					fmt.Fprint(w, tt.versionBody)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, v2Body)
+			}))
+			defer server.Close()
+
+			c := NewIngestionClient(server.URL, 5*time.Second, 0, tt.configured, tt.mapping, nil)
+			if got := versionHits.Load(); got != 0 {
+				t.Fatalf("constructor made %d /version calls, want none", got)
+			}
+
+			if err := c.Init(context.Background()); (err != nil) != tt.wantInitErr {
+				t.Fatalf("Init() error = %v, want error %v", err, tt.wantInitErr)
+			}
+			for i := 0; i < 2; i++ {
+				posts, err := c.GetSubredditPosts(NoCoalesce(context.Background()), "golang", 10, 0, 0)
+				if err != nil {
+					t.Fatalf("GetSubredditPosts() error = %v", err)
+				}
+				if len(posts) != 1 || posts[0].ID != "t3_b" {
+					t.Fatalf("posts = %+v, want t3_b decoded", posts)
+				}
+			}
+
+			if got := versionHits.Load(); got != tt.wantVersionHits {
+				t.Errorf("got %d /version calls, want %d", got, tt.wantVersionHits)
+			}
+			if got := c.APIVersion(); got != tt.wantVersion {
+				t.Errorf("APIVersion() = %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
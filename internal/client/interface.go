@@ -8,7 +8,12 @@ import (
 )
 
 type IngestionClientInterface interface {
-	GetSubredditPosts(ctx context.Context, subreddit string, limit int, sinceTimestamp int64) ([]models.IngestionPost, error)
+	GetSubredditPosts(ctx context.Context, subreddit string, limit int, sinceTimestamp, untilTimestamp int64) ([]models.IngestionPost, error)
+	GetUserPosts(ctx context.Context, username string, limit int, sinceTimestamp int64) ([]models.IngestionPost, error)
+	GetTopPosts(ctx context.Context, subreddit, timeRange string, limit int, after string) (*TopPostsPage, error)
+	GetTopPostsAt(ctx context.Context, subreddit, timeRange string, limit, offset int) (*TopPostsPage, error)
+	ValidateSubreddit(ctx context.Context, subreddit string) error
+	GetSubredditAbout(ctx context.Context, subreddit string) (*models.AboutInfo, error)
 	HealthCheck(ctx context.Context) error
 }
 
@@ -0,0 +1,245 @@
+// internal/client/mapping.go
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Field converters for values the target field can't decode directly
+const (
+	ConvertEpochSeconds = "epoch_seconds" // number (or numeric string) of seconds -> created_at
+	ConvertEpochMillis  = "epoch_millis"  // number (or numeric string) of milliseconds -> created_at
+	ConvertRFC3339      = "rfc3339"       // RFC3339 string -> created_at
+	ConvertStringToInt  = "string_to_int" // "42" or 42 -> score
+)
+
+// FieldRule reads a post field from From, a dot path into the post object
+// (e.g. "stats.ups"), optionally through a converter
+type FieldRule struct {
+	From    string `json:"from"`
+	Convert string `json:"convert,omitempty"`
+}
+
+// FieldMapping maps post fields (the json names of models.IngestionPost) to
+// the response keys they are read from. Unmapped fields are read as the
+// post's format reads them (see versionMappings), so a nil mapping decodes
+// the v1 and v2 formats unchanged.
+type FieldMapping map[string]FieldRule
+
+// mappingTargets addresses each mappable field of a post
+var mappingTargets = map[string]func(*models.IngestionPost) interface{}{
+	"id":         func(p *models.IngestionPost) interface{} { return &p.ID },
+	"title":      func(p *models.IngestionPost) interface{} { return &p.Title },
+	"body":       func(p *models.IngestionPost) interface{} { return &p.Body },
+	"author":     func(p *models.IngestionPost) interface{} { return &p.Author },
+	"score":      func(p *models.IngestionPost) interface{} { return &p.Score },
+	"created_at": func(p *models.IngestionPost) interface{} { return &p.CreatedAt },
+	"subreddit":  func(p *models.IngestionPost) interface{} { return &p.Subreddit },
+	"flair":      func(p *models.IngestionPost) interface{} { return &p.Flair },
+	"url":        func(p *models.IngestionPost) interface{} { return &p.URL },
+	"permalink":  func(p *models.IngestionPost) interface{} { return &p.Permalink },
+	"is_nsfw":    func(p *models.IngestionPost) interface{} { return &p.IsNSFW },
+	"spoiler":    func(p *models.IngestionPost) interface{} { return &p.Spoiler },
+}
+
+// converter decodes a raw value into dst, a pointer to the target field
+type converter struct {
+	targets []string // Fields the converter can fill
+	decode  func(raw json.RawMessage, dst interface{}) error
+}
+
+var converters = map[string]converter{
+	ConvertEpochSeconds: {[]string{"created_at"}, func(raw json.RawMessage, dst interface{}) error {
+		seconds, err := rawNumber(raw)
+		*dst.(*time.Time) = epochSeconds(seconds).Time()
+		return err
+	}},
+	ConvertEpochMillis: {[]string{"created_at"}, func(raw json.RawMessage, dst interface{}) error {
+		millis, err := rawNumber(raw)
+		*dst.(*time.Time) = epochSeconds(millis / 1000).Time()
+		return err
+	}},
+	ConvertRFC3339: {[]string{"created_at"}, func(raw json.RawMessage, dst interface{}) error {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		*dst.(*time.Time) = parsed.UTC()
+		return err
+	}},
+	ConvertStringToInt: {[]string{"score"}, func(raw json.RawMessage, dst interface{}) error {
+		value, err := strconv.Atoi(strings.Trim(string(raw), `"`))
+		*dst.(*int) = value
+		return err
+	}},
+}
+
+// rawNumber reads a JSON number, or a string holding one
+func rawNumber(raw json.RawMessage) (float64, error) {
+	return strconv.ParseFloat(strings.Trim(string(raw), `"`), 64)
+}
+
+// LoadFieldMapping reads a JSON or YAML file of field mappings keyed by
+// ingestion API base URL and returns the one for baseURL, or nil when the
+// path is empty or no mapping names baseURL. Every mapping in the file is
+// validated so a typo fails at startup.
+func LoadFieldMapping(path, baseURL string) (FieldMapping, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading field mapping: %w", err)
+	}
+	var mappings map[string]FieldMapping
+	if err := yaml.Unmarshal(data, &mappings); err != nil {
+		return nil, fmt.Errorf("parsing field mapping %s: %w", path, err)
+	}
+
+	var found FieldMapping
+	for url, mapping := range mappings {
+		if err := mapping.Validate(); err != nil {
+			return nil, fmt.Errorf("field mapping for %s: %w", url, err)
+		}
+		if strings.TrimRight(url, "/") == strings.TrimRight(baseURL, "/") {
+			found = mapping
+		}
+	}
+	return found, nil
+}
+
+// Validate rejects unknown target fields and converters, and converters
+// applied to a field they can't fill
+func (m FieldMapping) Validate() error {
+	targets := make([]string, 0, len(m))
+	for target := range m {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		rule := m[target]
+		if _, ok := mappingTargets[target]; !ok {
+			return fmt.Errorf("unknown target field %q", target)
+		}
+		if strings.TrimSpace(rule.From) == "" {
+			return fmt.Errorf("%s: from is required", target)
+		}
+		if rule.Convert == "" {
+			continue
+		}
+		conv, ok := converters[rule.Convert]
+		if !ok {
+			return fmt.Errorf("%s: unknown converter %q", target, rule.Convert)
+		}
+		if !slices.Contains(conv.targets, target) {
+			return fmt.Errorf("%s: converter %s only applies to %s", target, rule.Convert, strings.Join(conv.targets, ", "))
+		}
+	}
+	return nil
+}
+
+// decode reads one post object, returning the format it was in. Fields the
+// mapping has no rule for are read as that format reads them.
+func (m FieldMapping) decode(data json.RawMessage) (models.IngestionPost, string, error) {
+	var post models.IngestionPost
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return post, "", err
+	}
+	version := postVersion(object)
+	base := versionMappings[version]
+	fields := postFields{object: object}
+
+	for target, field := range mappingTargets {
+		rule, mapped := m[target]
+		if !mapped {
+			if rule, mapped = base[target]; !mapped {
+				rule = FieldRule{From: target}
+			}
+		}
+		raw, ok := fields.lookup(rule.From)
+		if !ok || string(raw) == "null" {
+			continue
+		}
+
+		dst := field(&post)
+		var err error
+		if rule.Convert != "" {
+			err = converters[rule.Convert].decode(raw, dst)
+		} else {
+			err = json.Unmarshal(raw, dst)
+		}
+		if err != nil {
+			return post, version, fmt.Errorf("%s from %q: %w", target, rule.From, err)
+		}
+	}
+	// v1 timestamps are RFC3339 with whatever offset the API host uses
+	post.CreatedAt = post.CreatedAt.UTC()
+	return post, version, nil
+}
+
+// postFields looks up dot paths in a post object, decoding each nested
+// object once however many fields are read from it
+type postFields struct {
+	object map[string]json.RawMessage
+	nested map[string]map[string]json.RawMessage
+}
+
+func (f *postFields) lookup(path string) (json.RawMessage, bool) {
+	object := f.object
+	key := path
+	for end := strings.IndexByte(path, '.'); end >= 0; {
+		prefix := path[:end]
+		nested, ok := f.nested[prefix]
+		if !ok {
+			raw, _ := lookupKey(object, prefix[strings.LastIndexByte(prefix, '.')+1:])
+			if err := json.Unmarshal(raw, &nested); err != nil {
+				nested = nil
+			}
+			if f.nested == nil {
+				f.nested = make(map[string]map[string]json.RawMessage)
+			}
+			f.nested[prefix] = nested
+		}
+		if nested == nil {
+			return nil, false
+		}
+		object = nested
+
+		key = path[end+1:]
+		next := strings.IndexByte(key, '.')
+		if next < 0 {
+			break
+		}
+		end += 1 + next
+	}
+	return lookupKey(object, key)
+}
+
+// lookupKey finds key in object, falling back to a case-insensitive match
+// as encoding/json does for struct fields
+func lookupKey(object map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if raw, ok := object[key]; ok {
+		return raw, true
+	}
+	for name, raw := range object {
+		if strings.EqualFold(name, key) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
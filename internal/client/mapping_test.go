@@ -0,0 +1,242 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+)
+
+// The same two posts in each response shape. The second leaves out the
+// optional fields and sends null where the API may.
+const (
+	goldenV1 = `{"posts":[
+		{"id":"t3_a","Title":"Go 1.23 released","body":"changelog","author":"gopher","score":42,
+		 "created_at":"2024-05-01T12:00:00.5+02:00","subreddit":"golang","flair":"News",
+		 "url":"https://go.dev/blog/go1.23?utm_source=reddit","permalink":"/r/golang/comments/a/go_123/",
+		 "is_nsfw":false,"spoiler":true},
+		{"id":"t3_b","title":"Question","body":"","author":"someone","score":0,
+		 "created_at":"2024-05-01T09:30:00Z","url":"https://www.reddit.com/r/golang/comments/b/question/","is_nsfw":null}
+	],"meta":{"count":2}}`
+
+	goldenV2 = `{"posts":[
+		{"kind":"t3","data":{"id":"t3_a","Title":"Go 1.23 released","body":"changelog","author":"gopher","score":42,
+		 "created_at":1714557600.5,"subreddit":"golang","flair":"News",
+		 "url":"https://go.dev/blog/go1.23?utm_source=reddit","permalink":"/r/golang/comments/a/go_123/",
+		 "is_nsfw":false,"spoiler":true}},
+		{"kind":"t3","data":{"id":"t3_b","title":"Question","body":"","author":"someone","score":0,
+		 "created_at":1714555800,"url":"https://www.reddit.com/r/golang/comments/b/question/","is_nsfw":null}}
+	],"meta":{"count":2}}`
+
+	goldenCustom = `{"posts":[
+		{"post_id":"t3_a","headline":"Go 1.23 released","text":"changelog","by":{"name":"gopher"},"stats":{"ups":"42"},
+		 "created_utc":1714557600500,"subreddit":"golang","flair":"News",
+		 "url":"https://go.dev/blog/go1.23?utm_source=reddit","permalink":"/r/golang/comments/a/go_123/",
+		 "over_18":false,"spoiler":true},
+		{"post_id":"t3_b","headline":"Question","text":"","by":{"name":"someone"},"stats":{"ups":0},
+		 "created_utc":1714555800000,"url":"https://www.reddit.com/r/golang/comments/b/question/","over_18":null}
+	],"meta":{"count":2}}`
+)
+
+// goldenMapping reads goldenCustom
+var goldenMapping = FieldMapping{
+	"id":         {From: "post_id"},
+	"title":      {From: "headline"},
+	"body":       {From: "text"},
+	"author":     {From: "by.name"},
+	"score":      {From: "stats.ups", Convert: ConvertStringToInt},
+	"created_at": {From: "created_utc", Convert: ConvertEpochMillis},
+	"is_nsfw":    {From: "over_18"},
+}
+
+func goldenPosts() []models.IngestionPost {
+	no, yes := false, true
+	return []models.IngestionPost{
+		{
+			ID: "t3_a", Title: "Go 1.23 released", Body: "changelog", Author: "gopher", Score: 42,
+			CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC), Subreddit: "golang", Flair: "News",
+			URL: "https://go.dev/blog/go1.23?utm_source=reddit", Permalink: "/r/golang/comments/a/go_123/",
+			IsNSFW: &no, Spoiler: &yes,
+		},
+		{
+			ID: "t3_b", Title: "Question", Author: "someone",
+			CreatedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+			URL:       "https://www.reddit.com/r/golang/comments/b/question/",
+		},
+	}
+}
+
+// TestResponseShapesDecodeAlike decodes the golden posts from every shape
+// and runs them through the processor, which must not see a difference
+func TestResponseShapesDecodeAlike(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		version string
+		mapping FieldMapping
+	}{
+		{name: "v1", body: goldenV1, version: APIVersion1},
+		{name: "v2", body: goldenV2, version: APIVersion2},
+		{name: "custom mapping", body: goldenCustom, version: APIVersion1, mapping: goldenMapping},
+	}
+
+	clk := clocktest.NewFake(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC))
+	want, _ := processor.NewProcessor(nil, nil, clk).ProcessSubredditPosts(goldenPosts(), "golang", nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response postsResponse
+			if err := json.Unmarshal([]byte(tt.body), &response); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			posts, mismatched, err := response.decoded(tt.version, tt.mapping)
+			if err != nil {
+				t.Fatalf("decoded() error = %v", err)
+			}
+			if mismatched != 0 {
+				t.Errorf("mismatched = %d, want 0", mismatched)
+			}
+			if !reflect.DeepEqual(posts, goldenPosts()) {
+				t.Fatalf("decoded posts differ from the golden posts\n got: %+v\nwant: %+v", posts, goldenPosts())
+			}
+
+			got, _ := processor.NewProcessor(nil, nil, clk).ProcessSubredditPosts(posts, "golang", nil)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("processed posts differ\n got: %+v\nwant: %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFieldMappingOverridesVersionFields(t *testing.T) {
+	// Unmapped fields of a v2 post are still read from its data wrapper
+	body := `{"posts":[{"kind":"t3","data":{"id":"t3_a","title":"a","ups":7,"created_at":1714557600}}]}`
+	var response postsResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	posts, mismatched, err := response.decoded(APIVersion2, FieldMapping{"score": {From: "data.ups"}})
+	if err != nil {
+		t.Fatalf("decoded() error = %v", err)
+	}
+	if mismatched != 0 || len(posts) != 1 || posts[0].ID != "t3_a" || posts[0].Score != 7 || posts[0].CreatedAt.Unix() != 1714557600 {
+		t.Errorf("decoded %+v (%d mismatched), want t3_a with score 7", posts, mismatched)
+	}
+}
+
+func TestFieldMappingDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		post    string
+		mapping FieldMapping
+		wantErr string
+	}{
+		{name: "wrong type", post: `{"id":"t3_a","score":"many"}`, wantErr: "score"},
+		{name: "bad converter input", post: `{"id":"t3_a","ups":"many"}`, mapping: FieldMapping{"score": {From: "ups", Convert: ConvertStringToInt}}, wantErr: `score from "ups"`},
+		{name: "bad timestamp", post: `{"id":"t3_a","when":"yesterday"}`, mapping: FieldMapping{"created_at": {From: "when", Convert: ConvertRFC3339}}, wantErr: "created_at"},
+		{name: "not an object", post: `["t3_a"]`, wantErr: "cannot unmarshal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := postsResponse{Posts: []json.RawMessage{json.RawMessage(tt.post)}}
+			_, _, err := response.decoded(APIVersion1, tt.mapping)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("decoded() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFieldMapping(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	mappings := write("mappings.yaml", `
+https://other.example.com/:
+  id: {from: post_id}
+  created_at: {from: created_utc, convert: epoch_seconds}
+https://ingest.example.com:
+  score: {from: stats.ups, convert: string_to_int}
+`)
+
+	tests := []struct {
+		name    string
+		path    string
+		baseURL string
+		want    FieldMapping
+		wantErr string
+	}{
+		{name: "no file", path: "", baseURL: "https://other.example.com"},
+		{
+			name:    "trailing slash in the file",
+			path:    mappings,
+			baseURL: "https://other.example.com",
+			want: FieldMapping{
+				"id":         {From: "post_id"},
+				"created_at": {From: "created_utc", Convert: ConvertEpochSeconds},
+			},
+		},
+		{
+			name:    "trailing slash in the base URL",
+			path:    mappings,
+			baseURL: "https://ingest.example.com/",
+			want:    FieldMapping{"score": {From: "stats.ups", Convert: ConvertStringToInt}},
+		},
+		{name: "unmapped base URL", path: mappings, baseURL: "https://api.example.com"},
+		{
+			name:    "unknown target in another mapping",
+			path:    write("unknown.json", `{"https://a.example.com":{"ups":{"from":"score"}},"https://b.example.com":{}}`),
+			baseURL: "https://b.example.com",
+			wantErr: `unknown target field "ups"`,
+		},
+		{
+			name:    "converter on the wrong field",
+			path:    write("wrong.json", `{"https://a.example.com":{"title":{"from":"t","convert":"epoch_millis"}}}`),
+			baseURL: "https://a.example.com",
+			wantErr: "only applies to created_at",
+		},
+		{
+			name:    "unknown converter",
+			path:    write("converter.json", `{"https://a.example.com":{"score":{"from":"s","convert":"float"}}}`),
+			baseURL: "https://a.example.com",
+			wantErr: `unknown converter "float"`,
+		},
+		{
+			name:    "missing from",
+			path:    write("from.json", `{"https://a.example.com":{"score":{"convert":"string_to_int"}}}`),
+			baseURL: "https://a.example.com",
+			wantErr: "from is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadFieldMapping(tt.path, tt.baseURL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFieldMapping() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFieldMapping() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFieldMapping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// internal/clock/clock.go
+package clock
+
+import "time"
+
+// Clock tells the time. Code that computes cursors, windows, backoffs or
+// cutoffs reads it instead of the time package, so tests can control it.
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel once d has passed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -5,63 +5,677 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+
+	"reddit-orchestrator/internal/features"
 )
 
 type Config struct {
 	MongoDBURI   string
 	DatabaseName string
 
-	IngestionAPIURL string
-	RequestTimeout  time.Duration
+	// BlueBerry keeps its run history in its own database
+	SchedulerDatabaseName string
+	AllowSharedDB         bool
+
+	IngestionAPIURL  string
+	RequestTimeout   time.Duration
+	MaxResponseBytes int64 // Ingestion responses larger than this are rejected
+	// "v1" or "v2"; empty detects the version from the API's /version endpoint
+	IngestionAPIVersion string
+	// JSON or YAML file of per-base-URL field mappings for nonstandard ingestion APIs
+	IngestionFieldMappingFile string
+
+	// Debug capture of raw subreddit responses: "off", "all" or comma-separated
+	// subreddits. Each capture keeps at most CaptureMaxBytes of the body, at most
+	// CaptureDailyLimit are taken per subreddit per UTC day, and they expire after CaptureTTL.
+	// Bodies keep plaintext authors, so capturing requires PRIVACY_MODE=off.
+	CaptureRawResponses string
+	CaptureMaxBytes     int
+	CaptureDailyLimit   int
+	CaptureTTL          time.Duration
+
+	// QA samples taken at a subreddit config's sample_rate expire after QASampleTTL
+	QASampleTTL time.Duration
+
+	// Optional enrichment service called per batch before storage (empty URL disables it)
+	EnrichmentURL     string
+	EnrichmentTimeout time.Duration
 
+	ServerHost string
 	ServerPort string
 
+	// MANAGEMENT_PORT moves /healthz, /readyz, /metrics and /api/version off
+	// ServerPort onto their own listener on ManagementHost (default
+	// ServerHost); empty keeps everything on ServerPort
+	ManagementHost string
+	ManagementPort string
+
 	// Authentication configuration (required)
 	WebAuthUser     string
 	WebAuthPassword string
 
+	// bcrypt hash of the web auth password; when set the API verifies
+	// passwords against it instead of WebAuthPassword
+	WebAuthPasswordHash string
+	// Outside ENV=dev a plaintext WebAuthPassword must be at least this long
+	// and not a well-known default
+	WebAuthMinPasswordLength int
+
+	// Deployment environment (ENV); "dev" relaxes the checks meant for
+	// production, such as password strength
+	Env string
+
 	// Task configuration
 	DefaultSubreddits        []string
 	SubredditSchedule        string
 	DefaultLimit             int
 	DefaultLookbackHours     int
 	MaxRetries               int
+
+	// Default schedules by priority band (PRIORITY_TIERS, e.g.
+	// "80=@every 15m;50=@hourly;0=@every 6h"), highest band first. Configs
+	// without their own schedule use their band's, or SubredditSchedule
+	// below every band.
+	PriorityTiers []PriorityTier
+
+	// Largest page the ingestion API serves; max_posts above it is rejected on
+	// save and clamped when read from older configs
+	MaxPostsCeiling int
+
+	// Runs beyond this limit wait in the run queue for a free slot
+	MaxConcurrentRuns int
+	// Fetched posts are processed and stored this many at a time
+	ProcessChunkSize int
+	// Pages of top posts an import requests at once. 1 follows the API's
+	// after cursors; above 1 pages by offset, which the ingestion API must
+	// support on /subreddit/top
+	TopImportConcurrency int
+	// How long shutdown waits for running runs before abandoning them
+	DrainTimeout time.Duration
+
+	// Upper bound for honouring ingestion API Retry-After headers
+	MaxIngestionPause time.Duration
+
+	// Storage queries slower than this are logged with their redacted filter (0 disables)
+	SlowQueryThreshold time.Duration
+
+	// Listings called without a limit return at most this many results
+	MaxUnboundedResults int
+
+	// /readyz reports degraded when a storage health check takes longer than this
+	ReadyLatencyThreshold time.Duration
+
+	// API requests slower than this are logged as warnings with their query
+	// parameters (0 disables)
+	SlowRequestThreshold time.Duration
+
+	// PARTITIONING=monthly stores posts in subreddit_post_YYYYMM collections;
+	// post queries without a lower time bound read the newest
+	// PartitionReadMonths partitions, and the cleanup task drops partitions
+	// older than PostRetentionMonths (0 keeps every partition)
+	PostPartitioning    bool
+	PartitionReadMonths int
+	PostRetentionMonths int
+
+	// How far last_scraped_at may lag the newest stored post before repair-metadata resets it
+	MetadataRepairMargin time.Duration
+
+	// Staleness: consecutive zero-post runs before a subreddit is flagged
+	// (when it has no expected post interval) and before a probe fetch is issued
+	StaleZeroRunThreshold int
+	StaleProbeAfter       int
+	StaleProbeLimit       int
+
+	// Scrape cursor checks before each run: a cursor in the future or older
+	// than CursorMaxAge (0 disables), or one that has fetched nothing for
+	// CursorProbeAfter runs (0 disables) while a daily probe finds an unstored
+	// recent post, is reset to CursorResetLookback ago
+	CursorMaxAge        time.Duration
+	CursorProbeAfter    int
+	CursorResetLookback time.Duration
+
+	// Staleness and other alerts go to this webhook, or to the log when empty
+	NotifyWebhookURL string
+	// JSON or YAML list of named notification channels (see
+	// notify.ChannelConfig). When set, alerts fan out to every channel whose
+	// severity filter matches, NotifyWebhookURL becoming the "default" channel.
+	NotifyChannelsFile string
+
+	// Digests run on their own schedule or DigestSchedule, listing the top
+	// DigestTopPosts posts with body previews of DigestPreviewChars characters
+	DigestSchedule     string
+	DigestTopPosts     int
+	DigestPreviewChars int
+
+	// Outgoing mail for digest recipients; mail is disabled when SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Author burst detection (empty AnomalySchedule disables the task)
+	AnomalySchedule        string
+	AnomalyAuthorThreshold int
+	AnomalyWindow          time.Duration
+	AnomalyLookback        time.Duration
+
+	// Weekly duplicate URL report (empty DuplicateURLSchedule disables the
+	// task): notifies about links posted to at least DuplicateURLMinSubreddits
+	// subreddits within DuplicateURLLookback
+	DuplicateURLSchedule      string
+	DuplicateURLLookback      time.Duration
+	DuplicateURLMinSubreddits int
+
+	// Removed post detection (empty RemovalScanSchedule disables the
+	// schedule): each scan refetches the posts created within
+	// RemovalScanWindow and marks stored ones missing twice in a row removed
+	RemovalScanSchedule string
+	RemovalScanWindow   time.Duration
+
+	// Priority recomputation (empty PriorityRecomputeSchedule disables the
+	// schedule): enabled subreddits are scored from the last PriorityLookback
+	// of posts and runs, recent days counting most. AutoPriority applies the
+	// result to unlocked configs; otherwise it is only suggested.
+	PriorityRecomputeSchedule string
+	PriorityLookback          time.Duration
+	AutoPriority              bool
+	PriorityWeightVolume      float64
+	PriorityWeightScore       float64
+	PriorityWeightReliability float64
+
+	// Document size audit (empty DocumentAuditSchedule disables the schedule):
+	// posts larger than DocumentSizeThreshold bytes are reported and their
+	// flair history trimmed to the newest DocumentAuditKeepHistory entries.
+	// FlairHistoryCap bounds flair_history on every write.
+	DocumentAuditSchedule    string
+	DocumentSizeThreshold    int
+	DocumentAuditKeepHistory int
+	FlairHistoryCap          int
+
+	// Cleanup task (empty CleanupSchedule disables the schedule); soft-deleted
+	// subreddit configs are purged ConfigPurgeAfter after deletion
+	CleanupSchedule  string
+	ConfigPurgeAfter time.Duration
+
+	// Storage stats task (empty StorageStatsSchedule disables the schedule);
+	// the scan is skipped above StorageStatsMaxDocuments posts unless forced
+	// (0 = no limit) and each aggregation is cut off after StorageStatsTimeout
+	StorageStatsSchedule     string
+	StorageStatsMaxDocuments int64
+	StorageStatsTimeout      time.Duration
+	// Clients may reuse GET /api/stats/storage for StatsCacheMaxAge
+	StatsCacheMaxAge time.Duration
+
+	// refresh_subreddit_info stores each configured subreddit's about info
+	// (empty SubredditInfoSchedule leaves it manual-only)
+	SubredditInfoSchedule string
+
+	// reconcile_adaptive_schedules moves adaptive_schedule subreddits between
+	// AdaptiveMinInterval during their AdaptivePeakHours busiest hours of the
+	// week and AdaptiveMaxInterval otherwise, from a profile of the last
+	// AdaptiveProfileWeeks weeks of rollups recomputed daily (empty
+	// AdaptiveReconcileSchedule disables it)
+	AdaptiveReconcileSchedule string
+	AdaptivePeakHours         int
+	AdaptiveMinInterval       time.Duration
+	AdaptiveMaxInterval       time.Duration
+	AdaptiveProfileWeeks      int
+
+	// Webhook delivery records are kept this long for auditing and replay
+	WebhookDeliveryTTL time.Duration
+	// Rule and digest webhooks may not target loopback, private or link-local
+	// addresses unless their host is listed here
+	WebhookAllowedHosts []string
+
+	// Operational data retention, enforced by TTL indexes synced at startup:
+	// scheduler task runs and their logs, and the notification log. Zero
+	// keeps the data forever.
+	TaskRunRetention         time.Duration
+	NotificationLogRetention time.Duration
+
+	// Push ingestion: POST /api/ingest/:subreddit is enabled when IngestSecret
+	// is set. Requests carry an HMAC-SHA256 signature of "timestamp.body" and
+	// are rejected when the timestamp is more than IngestSignatureWindow off or
+	// the body exceeds IngestMaxBodyBytes. Configs with push_enabled are polled
+	// on PushPollSchedule instead of their own schedule.
+	IngestSecret          string
+	IngestSignatureWindow time.Duration
+	IngestMaxBodyBytes    int64
+	PushPollSchedule      string
+
+	// With StrictScheduling, startup fails when more than ScheduleFailureThreshold
+	// schedules fail to register; otherwise failures are only reported.
+	// Subreddit schedules register in the background once the API is up,
+	// prepared by ScheduleWorkers goroutines, with progress logged every
+	// ScheduleProgressEvery subreddits. With ReadyRequiresSchedules, /readyz
+	// answers 503 until they are all registered; with ReadyFailsWhenPaused,
+	// also while a maintenance pause is active.
+	StrictScheduling         bool
+	ScheduleFailureThreshold int
+	ScheduleWorkers          int
+	ScheduleProgressEvery    int
+	ReadyRequiresSchedules   bool
+	ReadyFailsWhenPaused     bool
+
+	// Committing staged config changes fails when the active subreddits
+	// would be scheduled for more than MaxScheduledRunsPerHour runs an hour;
+	// zero allows any load
+	MaxScheduledRunsPerHour int
+
+	// The watchdog checks every WatchdogInterval (0 disables it) that a task
+	// run completed within three gaps of the densest subreddit schedule; with
+	// WatchdogExit a stalled scheduler exits the process so it is restarted
+	WatchdogInterval time.Duration
+	WatchdogExit     bool
+
+	// Runtime state for external supervisors: refreshed every
+	// StateHeartbeatInterval (0 only records start and shutdown) and mirrored
+	// to StateFile when set
+	StateHeartbeatInterval time.Duration
+	StateFile              string
+
+	// PRIVACY_MODE=hash stores authors as a keyed HMAC of PrivacyHashKey,
+	// drop stores them empty; off keeps them as received
+	PrivacyMode    string
+	PrivacyHashKey string
+
+	// HA_MODE=on lets several instances share one database: only the holder of
+	// the leadership lease runs schedules. Off keeps single-instance behaviour.
+	HAEnabled bool
+	// Identifies this instance in the lease; defaults to hostname-pid
+	InstanceID string
+	// A leader that has not renewed within LeaderLeaseTTL loses leadership
+	LeaderLeaseTTL      time.Duration
+	LeaderRenewInterval time.Duration
+
+	// Behaviour toggles; some can be flipped at runtime via /api/admin/features
+	Features *features.FeatureFlags
 }
 
 func LoadConfig() (*Config, error) {
-	_ = godotenv.Load()
+	// Variables already set win over the file; ENV_FILE names another file
+	// than .env, such as .env.example for `make run`
+	_ = godotenv.Load(getEnv("ENV_FILE", ".env"))
 
 	cfg := &Config{
 		MongoDBURI:           getEnv("MONGODB_URI", "mongodb://localhost:27017"),
 		DatabaseName:         getEnv("DATABASE_NAME", "reddit_data"),
 		IngestionAPIURL:      getEnv("INGESTION_API_URL", "http://localhost:8080"),
 		RequestTimeout:       getEnvDuration("REQUEST_TIMEOUT", 60*time.Second),
+		MaxResponseBytes:     int64(getEnvInt("MAX_RESPONSE_BYTES", 50<<20)),
+		IngestionAPIVersion:  getEnv("INGESTION_API_VERSION", ""),
+		CaptureRawResponses:  getEnv("CAPTURE_RAW_RESPONSES", "off"),
+		CaptureMaxBytes:      getEnvInt("CAPTURE_MAX_BYTES", 1<<20),
+		CaptureDailyLimit:    getEnvInt("CAPTURE_DAILY_LIMIT", 5),
+		CaptureTTL:           getEnvDuration("CAPTURE_TTL", 72*time.Hour),
+		QASampleTTL:          getEnvDuration("QA_SAMPLE_TTL", 14*24*time.Hour),
+		EnrichmentURL:        getEnv("ENRICHMENT_URL", ""),
+		EnrichmentTimeout:    getEnvDuration("ENRICHMENT_TIMEOUT", 10*time.Second),
+		ServerHost:           getEnv("SERVER_HOST", "0.0.0.0"),
 		ServerPort:           getEnv("SERVER_PORT", "8080"),
 		WebAuthUser:          getEnv("WEB_AUTH_USER", "admin"),
-		WebAuthPassword:      getEnv("WEB_AUTH_PASSWORD", "password"),
+		WebAuthPassword:      getEnv("WEB_AUTH_PASSWORD", ""),
 		SubredditSchedule:    getEnv("SUBREDDIT_SCHEDULE", "@every 1h"),
 		DefaultLimit:         getEnvInt("DEFAULT_LIMIT", 100),
 		DefaultLookbackHours: getEnvInt("DEFAULT_LOOKBACK_HOURS", 1),
 		MaxRetries:           getEnvInt("MAX_RETRIES", 3),
+		MaxPostsCeiling:      getEnvInt("MAX_POSTS_CEILING", 1000),
+		MaxConcurrentRuns:    getEnvInt("MAX_CONCURRENT_RUNS", 4),
+		ProcessChunkSize:     getEnvInt("PROCESS_CHUNK_SIZE", 500),
+		TopImportConcurrency: getEnvInt("TOP_IMPORT_CONCURRENCY", 1),
+		DrainTimeout:         getEnvDuration("DRAIN_TIMEOUT", 30*time.Second),
+		MaxIngestionPause:    getEnvDuration("MAX_INGESTION_PAUSE", time.Hour),
+		MetadataRepairMargin: getEnvDuration("METADATA_REPAIR_MARGIN", 24*time.Hour),
+		SlowQueryThreshold:   getEnvDuration("SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+
+		MaxUnboundedResults: getEnvInt("MAX_UNBOUNDED_RESULTS", 10000),
+
 		DefaultSubreddits:    getEnvStringSlice("DEFAULT_SUBREDDITS", []string{"golang", "programming"}),
+		AllowSharedDB:        getEnvBool("ALLOW_SHARED_DB", false),
+
+		IngestionFieldMappingFile: getEnv("INGESTION_FIELD_MAPPING_FILE", ""),
+
+		ReadyLatencyThreshold: getEnvDuration("READY_LATENCY_THRESHOLD", time.Second),
+		SlowRequestThreshold:  getEnvDuration("SLOW_REQUEST_THRESHOLD", 2*time.Second),
+
+		PartitionReadMonths: getEnvInt("PARTITION_READ_MONTHS", 3),
+		PostRetentionMonths: getEnvInt("POST_RETENTION_MONTHS", 0),
+
+		StaleZeroRunThreshold: getEnvInt("STALE_ZERO_RUN_THRESHOLD", 48),
+		StaleProbeAfter:       getEnvInt("STALE_PROBE_AFTER", 6),
+		StaleProbeLimit:       getEnvInt("STALE_PROBE_LIMIT", 5),
+		NotifyWebhookURL:      getEnv("NOTIFY_WEBHOOK_URL", ""),
+		NotifyChannelsFile:    getEnv("NOTIFY_CHANNELS_FILE", ""),
+
+		CursorMaxAge:        getEnvDuration("CURSOR_MAX_AGE", 30*24*time.Hour),
+		CursorProbeAfter:    getEnvInt("CURSOR_PROBE_AFTER", 12),
+		CursorResetLookback: getEnvDuration("CURSOR_RESET_LOOKBACK", 24*time.Hour),
+
+		DigestSchedule:     getEnv("DIGEST_SCHEDULE", "@daily"),
+		DigestTopPosts:     getEnvInt("DIGEST_TOP_POSTS", 10),
+		DigestPreviewChars: getEnvInt("DIGEST_PREVIEW_CHARS", 200),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
+
+		AnomalySchedule:        getEnv("ANOMALY_SCHEDULE", "@every 1h"),
+		AnomalyAuthorThreshold: getEnvInt("ANOMALY_AUTHOR_THRESHOLD", 5),
+		AnomalyWindow:          getEnvDuration("ANOMALY_WINDOW", time.Hour),
+		AnomalyLookback:        getEnvDuration("ANOMALY_LOOKBACK", 24*time.Hour),
+
+		DuplicateURLSchedule:      getEnv("DUPLICATE_URL_SCHEDULE", "@weekly"),
+		DuplicateURLLookback:      getEnvDuration("DUPLICATE_URL_LOOKBACK", 7*24*time.Hour),
+		DuplicateURLMinSubreddits: getEnvInt("DUPLICATE_URL_MIN_SUBREDDITS", 3),
+
+		RemovalScanSchedule: getEnv("REMOVAL_SCAN_SCHEDULE", "@every 6h"),
+		RemovalScanWindow:   getEnvDuration("REMOVAL_SCAN_WINDOW", 48*time.Hour),
+
+		PriorityRecomputeSchedule: getEnv("PRIORITY_RECOMPUTE_SCHEDULE", "@weekly"),
+		PriorityLookback:          getEnvDuration("PRIORITY_LOOKBACK", 28*24*time.Hour),
+		AutoPriority:              getEnvBool("AUTO_PRIORITY", false),
+		PriorityWeightVolume:      getEnvFloat("PRIORITY_WEIGHT_VOLUME", 0.5),
+		PriorityWeightScore:       getEnvFloat("PRIORITY_WEIGHT_SCORE", 0.3),
+		PriorityWeightReliability: getEnvFloat("PRIORITY_WEIGHT_RELIABILITY", 0.2),
+
+		DocumentAuditSchedule:    getEnv("DOCUMENT_AUDIT_SCHEDULE", "@weekly"),
+		DocumentSizeThreshold:    getEnvInt("DOCUMENT_SIZE_THRESHOLD", 1<<20),
+		DocumentAuditKeepHistory: getEnvInt("DOCUMENT_AUDIT_KEEP_HISTORY", 5),
+		FlairHistoryCap:          getEnvInt("FLAIR_HISTORY_CAP", 20),
+
+		CleanupSchedule:  getEnv("CLEANUP_SCHEDULE", "@daily"),
+		ConfigPurgeAfter: getEnvDuration("CONFIG_PURGE_AFTER", 30*24*time.Hour),
+
+		StorageStatsSchedule:     getEnv("STORAGE_STATS_SCHEDULE", "@daily"),
+		StorageStatsMaxDocuments: int64(getEnvInt("STORAGE_STATS_MAX_DOCUMENTS", 20000000)),
+		StorageStatsTimeout:      getEnvDuration("STORAGE_STATS_TIMEOUT", 30*time.Minute),
+		StatsCacheMaxAge:         getEnvDuration("STATS_CACHE_MAX_AGE", 5*time.Minute),
+
+		SubredditInfoSchedule: getEnv("SUBREDDIT_INFO_SCHEDULE", "@weekly"),
+
+		AdaptiveReconcileSchedule: getEnv("ADAPTIVE_RECONCILE_SCHEDULE", "@hourly"),
+		AdaptivePeakHours:         getEnvInt("ADAPTIVE_PEAK_HOURS", 48),
+		AdaptiveMinInterval:       getEnvDuration("ADAPTIVE_MIN_INTERVAL", 10*time.Minute),
+		AdaptiveMaxInterval:       getEnvDuration("ADAPTIVE_MAX_INTERVAL", 2*time.Hour),
+		AdaptiveProfileWeeks:      getEnvInt("ADAPTIVE_PROFILE_WEEKS", 1),
+
+		WebhookDeliveryTTL:  getEnvDuration("WEBHOOK_DELIVERY_TTL", 14*24*time.Hour),
+		WebhookAllowedHosts: getEnvList("WEBHOOK_ALLOWED_HOSTS"),
+
+		TaskRunRetention:         getEnvDuration("TASK_RUN_RETENTION", 30*24*time.Hour),
+		NotificationLogRetention: getEnvDuration("NOTIFICATION_LOG_RETENTION", 90*24*time.Hour),
+
+		IngestSecret:          getEnv("INGEST_SECRET", ""),
+		IngestSignatureWindow: getEnvDuration("INGEST_SIGNATURE_WINDOW", 5*time.Minute),
+		IngestMaxBodyBytes:    int64(getEnvInt("INGEST_MAX_BODY_BYTES", 10<<20)),
+		PushPollSchedule:      getEnv("PUSH_POLL_SCHEDULE", "@every 6h"),
+
+		StrictScheduling:         getEnvBool("STRICT_SCHEDULING", false),
+		ScheduleFailureThreshold: getEnvInt("SCHEDULE_FAILURE_THRESHOLD", 0),
+		ScheduleWorkers:          getEnvInt("SCHEDULE_WORKERS", 8),
+		ScheduleProgressEvery:    getEnvInt("SCHEDULE_PROGRESS_EVERY", 50),
+		ReadyRequiresSchedules:   getEnvBool("READY_REQUIRES_SCHEDULES", true),
+		ReadyFailsWhenPaused:     getEnvBool("READY_FAILS_WHEN_PAUSED", false),
+
+		MaxScheduledRunsPerHour: getEnvInt("MAX_SCHEDULED_RUNS_PER_HOUR", 0),
+
+		WatchdogInterval: getEnvDuration("WATCHDOG_INTERVAL", time.Minute),
+		WatchdogExit:     getEnvBool("WATCHDOG_EXIT", false),
+
+		StateHeartbeatInterval: getEnvDuration("STATE_HEARTBEAT_INTERVAL", 30*time.Second),
+		StateFile:              getEnv("STATE_FILE", ""),
+
+		PrivacyMode:    getEnv("PRIVACY_MODE", "off"),
+		PrivacyHashKey: getEnv("PRIVACY_HASH_KEY", ""),
+
+		WebAuthPasswordHash:      getEnv("WEB_AUTH_PASSWORD_HASH", ""),
+		WebAuthMinPasswordLength: getEnvInt("WEB_AUTH_MIN_PASSWORD_LENGTH", 12),
+
+		Env: getEnv("ENV", "production"),
+
+		ManagementPort: getEnv("MANAGEMENT_PORT", ""),
+
+		InstanceID:          getEnv("INSTANCE_ID", defaultInstanceID()),
+		LeaderLeaseTTL:      getEnvDuration("LEADER_LEASE_TTL", 30*time.Second),
+		LeaderRenewInterval: getEnvDuration("LEADER_RENEW_INTERVAL", 10*time.Second),
+
+		Features: features.New(features.Values{
+			CatchUpOnStart:    getEnvBool("CATCHUP_ON_START", true),
+			Enrichment:        getEnvBool("ENRICHMENT_ENABLED", true),
+			NotificationRules: getEnvBool("NOTIFICATION_RULES_ENABLED", true),
+			AnomalyTagPosts:   getEnvBool("ANOMALY_TAG_POSTS", true),
+			DebugRejections:   getEnvBool("DEBUG_REJECTIONS", false),
+		}),
+	}
+	tiers, err := parsePriorityTiers(getEnv("PRIORITY_TIERS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("PRIORITY_TIERS: %w", err)
+	}
+	cfg.PriorityTiers = tiers
+
+	if cfg.AdaptivePeakHours < 1 || cfg.AdaptivePeakHours >= 7*24 {
+		return nil, fmt.Errorf("ADAPTIVE_PEAK_HOURS must be between 1 and 167")
+	}
+	if cfg.AdaptiveMinInterval < time.Minute || cfg.AdaptiveMaxInterval < cfg.AdaptiveMinInterval {
+		return nil, fmt.Errorf("ADAPTIVE_MIN_INTERVAL must be at least 1m and at most ADAPTIVE_MAX_INTERVAL")
+	}
+	if cfg.AdaptiveProfileWeeks < 1 {
+		return nil, fmt.Errorf("ADAPTIVE_PROFILE_WEEKS must be positive")
+	}
+
+	switch cfg.PrivacyMode {
+	case "hash":
+		if cfg.PrivacyHashKey == "" {
+			return nil, fmt.Errorf("PRIVACY_MODE=hash requires PRIVACY_HASH_KEY")
+		}
+	case "off", "drop":
+	default:
+		return nil, fmt.Errorf("PRIVACY_MODE must be off, hash or drop, got %q", cfg.PrivacyMode)
+	}
+	// Captures keep response bodies as fetched, plaintext authors included
+	if cfg.PrivacyMode != "off" && cfg.CaptureRawResponses != "" && cfg.CaptureRawResponses != "off" {
+		return nil, fmt.Errorf("CAPTURE_RAW_RESPONSES must be off with PRIVACY_MODE=%s, as captured bodies keep plaintext authors", cfg.PrivacyMode)
+	}
+
+	switch haMode := getEnv("HA_MODE", "off"); haMode {
+	case "on":
+		cfg.HAEnabled = true
+	case "off":
+	default:
+		return nil, fmt.Errorf("HA_MODE must be on or off, got %q", haMode)
+	}
+	switch partitioning := getEnv("PARTITIONING", "off"); partitioning {
+	case "monthly":
+		cfg.PostPartitioning = true
+	case "off":
+	default:
+		return nil, fmt.Errorf("PARTITIONING must be off or monthly, got %q", partitioning)
+	}
+	if cfg.ProcessChunkSize <= 0 {
+		return nil, fmt.Errorf("PROCESS_CHUNK_SIZE must be positive")
+	}
+	if cfg.TopImportConcurrency < 1 || cfg.TopImportConcurrency > 8 {
+		return nil, fmt.Errorf("TOP_IMPORT_CONCURRENCY must be between 1 and 8")
+	}
+	if cfg.WebhookDeliveryTTL <= 0 {
+		return nil, fmt.Errorf("WEBHOOK_DELIVERY_TTL must be positive")
+	}
+	if cfg.TaskRunRetention < 0 || cfg.NotificationLogRetention < 0 {
+		return nil, fmt.Errorf("TASK_RUN_RETENTION and NOTIFICATION_LOG_RETENTION must not be negative")
+	}
+	if cfg.DigestTopPosts <= 0 || cfg.DigestPreviewChars < 0 {
+		return nil, fmt.Errorf("DIGEST_TOP_POSTS must be positive and DIGEST_PREVIEW_CHARS not negative")
+	}
+	if cfg.SMTPHost != "" && cfg.SMTPFrom == "" {
+		return nil, fmt.Errorf("SMTP_FROM is required when SMTP_HOST is set")
+	}
+	if cfg.IngestSignatureWindow <= 0 || cfg.IngestMaxBodyBytes <= 0 {
+		return nil, fmt.Errorf("INGEST_SIGNATURE_WINDOW and INGEST_MAX_BODY_BYTES must be positive")
+	}
+	if cfg.StorageStatsMaxDocuments < 0 || cfg.StorageStatsTimeout <= 0 {
+		return nil, fmt.Errorf("STORAGE_STATS_MAX_DOCUMENTS must not be negative and STORAGE_STATS_TIMEOUT must be positive")
+	}
+	if cfg.StatsCacheMaxAge < 0 {
+		return nil, fmt.Errorf("STATS_CACHE_MAX_AGE must not be negative")
+	}
+	if cfg.WatchdogInterval < 0 {
+		return nil, fmt.Errorf("WATCHDOG_INTERVAL must not be negative")
+	}
+	if cfg.MaxUnboundedResults <= 0 {
+		return nil, fmt.Errorf("MAX_UNBOUNDED_RESULTS must be positive")
+	}
+	if cfg.StateHeartbeatInterval < 0 {
+		return nil, fmt.Errorf("STATE_HEARTBEAT_INTERVAL must not be negative")
+	}
+	if cfg.PartitionReadMonths <= 0 || cfg.PostRetentionMonths < 0 {
+		return nil, fmt.Errorf("PARTITION_READ_MONTHS must be positive and POST_RETENTION_MONTHS not negative")
+	}
+	cfg.SchedulerDatabaseName = getEnv("SCHEDULER_DATABASE_NAME", cfg.DatabaseName+"_scheduler")
+	cfg.ManagementHost = getEnv("MANAGEMENT_HOST", cfg.ServerHost)
+	if cfg.ManagementPort != "" && cfg.ManagementPort == cfg.ServerPort {
+		return nil, fmt.Errorf("MANAGEMENT_PORT must differ from SERVER_PORT; leave it unset to serve everything on one port")
 	}
 
 	if cfg.MongoDBURI == "" {
 		return nil, fmt.Errorf("MONGODB_URI is required")
 	}
+	if cfg.SchedulerDatabaseName == cfg.DatabaseName && !cfg.AllowSharedDB {
+		return nil, fmt.Errorf("SCHEDULER_DATABASE_NAME must differ from DATABASE_NAME (set ALLOW_SHARED_DB=true to override)")
+	}
 	if cfg.IngestionAPIURL == "" {
 		return nil, fmt.Errorf("INGESTION_API_URL is required")
 	}
-	if cfg.WebAuthUser == "" || cfg.WebAuthPassword == "" {
-		return nil, fmt.Errorf("WEB_AUTH_USER and WEB_AUTH_PASSWORD are required")
+	if cfg.IngestionAPIVersion != "" && cfg.IngestionAPIVersion != "v1" && cfg.IngestionAPIVersion != "v2" {
+		return nil, fmt.Errorf("INGESTION_API_VERSION must be v1 or v2, got %q", cfg.IngestionAPIVersion)
+	}
+	// Mongo documents are capped at 16MB, which must also hold the other capture fields
+	if cfg.CaptureMaxBytes <= 0 || cfg.CaptureMaxBytes > 8<<20 {
+		return nil, fmt.Errorf("CAPTURE_MAX_BYTES must be between 1 and %d", 8<<20)
+	}
+	if cfg.CaptureDailyLimit <= 0 || cfg.CaptureTTL <= 0 {
+		return nil, fmt.Errorf("CAPTURE_DAILY_LIMIT and CAPTURE_TTL must be positive")
+	}
+	if cfg.QASampleTTL <= 0 {
+		return nil, fmt.Errorf("QA_SAMPLE_TTL must be positive")
+	}
+	if cfg.MaxPostsCeiling <= 0 {
+		return nil, fmt.Errorf("MAX_POSTS_CEILING must be positive")
+	}
+	if cfg.DefaultLookbackHours <= 0 {
+		return nil, fmt.Errorf("DEFAULT_LOOKBACK_HOURS must be positive")
+	}
+	if cfg.DefaultLimit > cfg.MaxPostsCeiling {
+		return nil, fmt.Errorf("DEFAULT_LIMIT (%d) must not exceed MAX_POSTS_CEILING (%d)", cfg.DefaultLimit, cfg.MaxPostsCeiling)
+	}
+	if cfg.DuplicateURLLookback <= 0 || cfg.DuplicateURLMinSubreddits < 2 {
+		return nil, fmt.Errorf("DUPLICATE_URL_LOOKBACK must be positive and DUPLICATE_URL_MIN_SUBREDDITS at least 2")
+	}
+	if cfg.CursorMaxAge < 0 || cfg.CursorProbeAfter < 0 {
+		return nil, fmt.Errorf("CURSOR_MAX_AGE and CURSOR_PROBE_AFTER must not be negative")
+	}
+	// A reset cursor must pass the age check it was reset by
+	if cfg.CursorResetLookback <= 0 || (cfg.CursorMaxAge > 0 && cfg.CursorResetLookback >= cfg.CursorMaxAge) {
+		return nil, fmt.Errorf("CURSOR_RESET_LOOKBACK must be positive and shorter than CURSOR_MAX_AGE")
+	}
+	if cfg.AnomalyWindow <= 0 {
+		return nil, fmt.Errorf("ANOMALY_WINDOW must be positive")
+	}
+	if cfg.RemovalScanWindow <= 0 {
+		return nil, fmt.Errorf("REMOVAL_SCAN_WINDOW must be positive")
+	}
+	if cfg.PriorityLookback <= 0 {
+		return nil, fmt.Errorf("PRIORITY_LOOKBACK must be positive")
+	}
+	if cfg.PriorityWeightVolume < 0 || cfg.PriorityWeightScore < 0 || cfg.PriorityWeightReliability < 0 ||
+		cfg.PriorityWeightVolume+cfg.PriorityWeightScore+cfg.PriorityWeightReliability == 0 {
+		return nil, fmt.Errorf("PRIORITY_WEIGHT_VOLUME, PRIORITY_WEIGHT_SCORE and PRIORITY_WEIGHT_RELIABILITY must not be negative and must not all be zero")
+	}
+	// Mongo caps documents at 16MB, so larger thresholds would never match
+	if cfg.DocumentSizeThreshold <= 0 || cfg.DocumentSizeThreshold >= 16<<20 {
+		return nil, fmt.Errorf("DOCUMENT_SIZE_THRESHOLD must be between 1 and %d", 16<<20-1)
+	}
+	if cfg.FlairHistoryCap <= 0 || cfg.DocumentAuditKeepHistory < 0 || cfg.DocumentAuditKeepHistory > cfg.FlairHistoryCap {
+		return nil, fmt.Errorf("FLAIR_HISTORY_CAP must be positive and DOCUMENT_AUDIT_KEEP_HISTORY between 0 and FLAIR_HISTORY_CAP")
+	}
+	if cfg.ScheduleFailureThreshold < 0 {
+		return nil, fmt.Errorf("SCHEDULE_FAILURE_THRESHOLD must not be negative")
+	}
+	if cfg.MaxScheduledRunsPerHour < 0 {
+		return nil, fmt.Errorf("MAX_SCHEDULED_RUNS_PER_HOUR must not be negative")
+	}
+	if cfg.ScheduleWorkers <= 0 || cfg.ScheduleProgressEvery <= 0 {
+		return nil, fmt.Errorf("SCHEDULE_WORKERS and SCHEDULE_PROGRESS_EVERY must be positive")
+	}
+	if cfg.HAEnabled && (cfg.LeaderRenewInterval <= 0 || cfg.LeaderRenewInterval >= cfg.LeaderLeaseTTL) {
+		return nil, fmt.Errorf("LEADER_RENEW_INTERVAL must be positive and shorter than LEADER_LEASE_TTL")
+	}
+	if err := validateWebAuth(cfg); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// weakPasswords are defaults and well-known passwords refused outside ENV=dev
+var weakPasswords = map[string]bool{
+	"password": true, "admin": true, "changeme": true, "secret": true,
+	"123456": true, "12345678": true, "123456789": true, "qwerty": true,
+	"letmein": true, "welcome": true, "default": true, "orchestrator": true,
+	"password123": true, "admin123": true,
+}
+
+// validateWebAuth checks the web auth credentials. In ENV=dev without any
+// password it falls back to the old admin/password default.
+func validateWebAuth(cfg *Config) error {
+	if cfg.WebAuthPassword == "" && cfg.WebAuthPasswordHash == "" && cfg.Env == "dev" {
+		cfg.WebAuthPassword = "password"
+	}
+	if cfg.WebAuthUser == "" || (cfg.WebAuthPassword == "" && cfg.WebAuthPasswordHash == "") {
+		return fmt.Errorf("WEB_AUTH_USER and WEB_AUTH_PASSWORD or WEB_AUTH_PASSWORD_HASH are required")
+	}
+	if cfg.WebAuthPasswordHash != "" {
+		if _, err := bcrypt.Cost([]byte(cfg.WebAuthPasswordHash)); err != nil {
+			return fmt.Errorf("WEB_AUTH_PASSWORD_HASH must be a bcrypt hash: %w", err)
+		}
+	}
+	if cfg.WebAuthPassword == "" || cfg.Env == "dev" {
+		return nil
+	}
+	if weakPasswords[strings.ToLower(cfg.WebAuthPassword)] {
+		return fmt.Errorf("WEB_AUTH_PASSWORD is a default or well-known password; set a strong one, or ENV=dev for local use")
+	}
+	if len(cfg.WebAuthPassword) < cfg.WebAuthMinPasswordLength {
+		return fmt.Errorf("WEB_AUTH_PASSWORD must be at least %d characters (WEB_AUTH_MIN_PASSWORD_LENGTH)", cfg.WebAuthMinPasswordLength)
+	}
+	return nil
+}
+
+// WebAuthMode names how API passwords are verified, for startup logs
+func (c *Config) WebAuthMode() string {
+	if c.WebAuthPasswordHash != "" {
+		return "bcrypt hash"
+	}
+	return "plaintext"
+}
+
+// defaultInstanceID is unique per process on a host, which is enough to tell
+// replicas apart in the leadership lease
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "orchestrator"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -78,6 +692,24 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -87,6 +719,17 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvStringSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// setValidEnv sets the variables LoadConfig requires outside ENV=dev, so a
+// test only sets the ones it is about
+func setValidEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("ENV", "production")
+	t.Setenv("WEB_AUTH_USER", "admin")
+	t.Setenv("WEB_AUTH_PASSWORD", "a-long-enough-passphrase")
+	t.Setenv("WEB_AUTH_PASSWORD_HASH", "")
+}
+
+func TestLoadConfigRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "zero anomaly window", env: map[string]string{"ANOMALY_WINDOW": "0s"}, wantErr: "ANOMALY_WINDOW"},
+		{name: "negative anomaly window", env: map[string]string{"ANOMALY_WINDOW": "-1h"}, wantErr: "ANOMALY_WINDOW"},
+		{name: "unknown partitioning", env: map[string]string{"PARTITIONING": "weekly"}, wantErr: "PARTITIONING"},
+		{name: "captures with hashed authors", env: map[string]string{"PRIVACY_MODE": "hash", "PRIVACY_HASH_KEY": "key", "CAPTURE_RAW_RESPONSES": "all"}, wantErr: "CAPTURE_RAW_RESPONSES"},
+		{name: "captures with dropped authors", env: map[string]string{"PRIVACY_MODE": "drop", "CAPTURE_RAW_RESPONSES": "golang"}, wantErr: "CAPTURE_RAW_RESPONSES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setValidEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want one mentioning %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigWebAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("a-long-enough-passphrase"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantErr      string
+		wantPassword string
+		wantMode     string
+	}{
+		{name: "plaintext", wantPassword: "a-long-enough-passphrase", wantMode: "plaintext"},
+		{name: "hashed", env: map[string]string{"WEB_AUTH_PASSWORD": "", "WEB_AUTH_PASSWORD_HASH": string(hash)}, wantMode: "bcrypt hash"},
+		{name: "hash that is not bcrypt", env: map[string]string{"WEB_AUTH_PASSWORD_HASH": "not-a-hash"}, wantErr: "WEB_AUTH_PASSWORD_HASH"},
+		{name: "default password", env: map[string]string{"WEB_AUTH_PASSWORD": "password"}, wantErr: "well-known"},
+		{name: "well-known password in another case", env: map[string]string{"WEB_AUTH_PASSWORD": "ChangeMe"}, wantErr: "well-known"},
+		{name: "short password", env: map[string]string{"WEB_AUTH_PASSWORD": "s3cr3t-pw"}, wantErr: "at least 12 characters"},
+		{name: "raised minimum length", env: map[string]string{"WEB_AUTH_MIN_PASSWORD_LENGTH": "32"}, wantErr: "at least 32 characters"},
+		{name: "no password", env: map[string]string{"WEB_AUTH_PASSWORD": ""}, wantErr: "required"},
+		{name: "weak password in dev", env: map[string]string{"ENV": "dev", "WEB_AUTH_PASSWORD": "password"}, wantPassword: "password", wantMode: "plaintext"},
+		{name: "dev default", env: map[string]string{"ENV": "dev", "WEB_AUTH_PASSWORD": ""}, wantPassword: "password", wantMode: "plaintext"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setValidEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := LoadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.WebAuthPassword != tt.wantPassword || cfg.WebAuthMode() != tt.wantMode {
+				t.Errorf("password %q in %s mode, want %q in %s mode", cfg.WebAuthPassword, cfg.WebAuthMode(), tt.wantPassword, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAcceptsDefaults(t *testing.T) {
+	setValidEnv(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.AnomalyWindow <= 0 {
+		t.Errorf("AnomalyWindow = %v, want a positive default", cfg.AnomalyWindow)
+	}
+}
+
+// TestLoadConfigAcceptsEnvExample loads .env.example as `make run` does
+// without a .env, so its placeholders keep passing validation
+func TestLoadConfigAcceptsEnvExample(t *testing.T) {
+	const example = "../../.env.example"
+	values, err := godotenv.Read(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Variables already set would win over the file
+	for key := range values {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("ENV_FILE", example)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() with %s error = %v", example, err)
+	}
+	if cfg.WebAuthPassword != values["WEB_AUTH_PASSWORD"] {
+		t.Errorf("WebAuthPassword = %q, want the one in %s", cfg.WebAuthPassword, example)
+	}
+}
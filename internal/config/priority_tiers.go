@@ -0,0 +1,67 @@
+// internal/config/priority_tiers.go
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// PriorityTier is the default schedule of subreddit configs whose priority is
+// at least MinPriority and below the next higher tier's
+type PriorityTier struct {
+	MinPriority int
+	Schedule    string
+}
+
+// Name labels the tier in the schedule report, e.g. "priority>=80"
+func (t PriorityTier) Name() string {
+	return fmt.Sprintf("priority>=%d", t.MinPriority)
+}
+
+// parsePriorityTiers reads "80=@every 15m;50=@hourly;0=@every 6h" into tiers
+// sorted from the highest MinPriority down; empty means no tiers
+func parsePriorityTiers(value string) ([]PriorityTier, error) {
+	var tiers []PriorityTier
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		minPriority, schedule, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("tier %q must be min_priority=schedule", part)
+		}
+		min, err := strconv.Atoi(strings.TrimSpace(minPriority))
+		if err != nil {
+			return nil, fmt.Errorf("tier %q: min priority must be an integer", part)
+		}
+		if seen[min] {
+			return nil, fmt.Errorf("tier %q: min priority %d is listed twice", part, min)
+		}
+		seen[min] = true
+		schedule = strings.TrimSpace(schedule)
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return nil, fmt.Errorf("tier %q: invalid schedule: %w", part, err)
+		}
+		tiers = append(tiers, PriorityTier{MinPriority: min, Schedule: schedule})
+	}
+
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinPriority > tiers[j].MinPriority })
+	return tiers, nil
+}
+
+// TierFor returns the tier a priority falls in; false when it is below every
+// tier, leaving SubredditSchedule as the default
+func (c *Config) TierFor(priority int) (PriorityTier, bool) {
+	for _, tier := range c.PriorityTiers {
+		if priority >= tier.MinPriority {
+			return tier, true
+		}
+	}
+	return PriorityTier{}, false
+}
@@ -0,0 +1,104 @@
+// internal/enrichment/http_enricher.go
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Ensure HTTPEnricher implements EnricherInterface
+var _ EnricherInterface = (*HTTPEnricher)(nil)
+
+// maxErrorBodyBytes caps how much of a non-200 body is read into the error message
+const maxErrorBodyBytes = 4 << 10
+
+// HTTPEnricher POSTs each batch to an enrichment service, which answers with
+// {"posts": [{"reddit_id": ..., "extras": {...}}]}
+type HTTPEnricher struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewHTTPEnricher(url string) *HTTPEnricher {
+	return &HTTPEnricher{
+		url:        url,
+		httpClient: &http.Client{},
+	}
+}
+
+type enrichmentRequest struct {
+	Posts []models.Post `json:"posts"`
+}
+
+type enrichmentResponse struct {
+	Posts []struct {
+		RedditID string                 `json:"reddit_id"`
+		Extras   map[string]interface{} `json:"extras"`
+	} `json:"posts"`
+}
+
+// Enrich sends the batch and merges returned extras by reddit_id. Posts the
+// service does not return are passed through unchanged. The batch timeout
+// comes from ctx.
+func (e *HTTPEnricher) Enrich(ctx context.Context, posts []models.Post) ([]models.Post, error) {
+	if len(posts) == 0 {
+		return posts, nil
+	}
+
+	payload, err := json.Marshal(enrichmentRequest{Posts: posts})
+	if err != nil {
+		return nil, fmt.Errorf("encoding enrichment request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("creating enrichment request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making enrichment request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, fmt.Errorf("enrichment error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response enrichmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("parsing enrichment response: %w", err)
+	}
+
+	extrasByID := make(map[string]map[string]interface{}, len(response.Posts))
+	for _, enriched := range response.Posts {
+		if enriched.RedditID != "" && len(enriched.Extras) > 0 {
+			extrasByID[enriched.RedditID] = enriched.Extras
+		}
+	}
+
+	result := make([]models.Post, len(posts))
+	copy(result, posts)
+	for i := range result {
+		extras, ok := extrasByID[result[i].RedditID]
+		if !ok {
+			continue
+		}
+		if result[i].Extras == nil {
+			result[i].Extras = make(map[string]interface{}, len(extras))
+		}
+		for key, value := range extras {
+			result[i].Extras[key] = value
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,13 @@
+// internal/enrichment/interface.go
+package enrichment
+
+import (
+	"context"
+
+	"reddit-orchestrator/internal/models"
+)
+
+type EnricherInterface interface {
+	// Enrich returns the posts with service-provided fields merged into Extras
+	Enrich(ctx context.Context, posts []models.Post) ([]models.Post, error)
+}
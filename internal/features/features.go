@@ -0,0 +1,109 @@
+// internal/features/features.go
+package features
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+var (
+	ErrUnknownFlag    = errors.New("unknown feature flag")
+	ErrNotRuntimeSafe = errors.New("feature flag cannot be changed at runtime")
+)
+
+// Flag is a boolean toggle that is safe to read from concurrent tasks
+type Flag struct {
+	Name        string
+	Description string
+	// RuntimeSafe flags may be changed through the admin API; the others are
+	// only read at startup or would leave data inconsistent if flipped
+	RuntimeSafe bool
+
+	enabled atomic.Bool
+}
+
+func newFlag(name, description string, runtimeSafe, enabled bool) *Flag {
+	flag := &Flag{Name: name, Description: description, RuntimeSafe: runtimeSafe}
+	flag.enabled.Store(enabled)
+	return flag
+}
+
+// Enabled reports the flag's current value; a nil flag is disabled
+func (f *Flag) Enabled() bool {
+	return f != nil && f.enabled.Load()
+}
+
+// FeatureFlags are the behaviour toggles of one deployment
+type FeatureFlags struct {
+	CatchUpOnStart    *Flag
+	Enrichment        *Flag
+	NotificationRules *Flag
+	AnomalyTagPosts   *Flag
+	DebugRejections   *Flag
+
+	byName map[string]*Flag
+}
+
+// Values are the startup values of every flag, read from the environment by config
+type Values struct {
+	CatchUpOnStart    bool
+	Enrichment        bool
+	NotificationRules bool
+	AnomalyTagPosts   bool
+	DebugRejections   bool
+}
+
+func New(values Values) *FeatureFlags {
+	flags := &FeatureFlags{
+		CatchUpOnStart:    newFlag("catch_up_on_start", "Run overdue subreddits once at startup", false, values.CatchUpOnStart),
+		Enrichment:        newFlag("enrichment", "Call the enrichment service before storing posts (needs ENRICHMENT_URL)", true, values.Enrichment),
+		NotificationRules: newFlag("notification_rules", "Evaluate notification rules against new posts", true, values.NotificationRules),
+		AnomalyTagPosts:   newFlag("anomaly_tag_posts", "Tag posts of authors flagged by burst detection", true, values.AnomalyTagPosts),
+		DebugRejections:   newFlag("debug_rejections", "Log sample IDs of posts dropped by the processor", true, values.DebugRejections),
+	}
+
+	flags.byName = make(map[string]*Flag)
+	for _, flag := range []*Flag{
+		flags.CatchUpOnStart,
+		flags.Enrichment,
+		flags.NotificationRules,
+		flags.AnomalyTagPosts,
+		flags.DebugRejections,
+	} {
+		flags.byName[flag.Name] = flag
+	}
+
+	return flags
+}
+
+// All returns every flag sorted by name
+func (f *FeatureFlags) All() []*Flag {
+	all := make([]*Flag, 0, len(f.byName))
+	for _, flag := range f.byName {
+		all = append(all, flag)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// CheckSet reports whether Set would accept the change, without applying it
+func (f *FeatureFlags) CheckSet(name string) error {
+	flag, ok := f.byName[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	if !flag.RuntimeSafe {
+		return fmt.Errorf("%w: %s", ErrNotRuntimeSafe, name)
+	}
+	return nil
+}
+
+// Set changes a runtime-safe flag and returns its previous value
+func (f *FeatureFlags) Set(name string, enabled bool) (bool, error) {
+	if err := f.CheckSet(name); err != nil {
+		return false, err
+	}
+	return f.byName[name].enabled.Swap(enabled), nil
+}
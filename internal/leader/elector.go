@@ -0,0 +1,189 @@
+// internal/leader/elector.go
+package leader
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// LeaseName is the lease document instances compete for to run schedules
+const LeaseName = "scheduler"
+
+// releaseTimeout bounds the lease release on shutdown
+const releaseTimeout = 5 * time.Second
+
+// Ensure both electors implement ElectorInterface
+var (
+	_ ElectorInterface = (*Elector)(nil)
+	_ ElectorInterface = (*Static)(nil)
+)
+
+// Elector holds leadership through a TTL lease in storage. The leader renews
+// it every renewInterval; followers poll at the same interval and take over
+// once the lease expires. Hosts are assumed to have reasonably synced clocks.
+type Elector struct {
+	store         storage.LeaderStore
+	instanceID    string
+	ttl           time.Duration
+	renewInterval time.Duration
+	clock         clock.Clock
+	onElected     []func()
+
+	mu             sync.RWMutex
+	leader         bool
+	lease          models.LeaderLease
+	lastTransition time.Time
+	lastErr        string
+}
+
+func NewElector(store storage.LeaderStore, instanceID string, ttl, renewInterval time.Duration, clk clock.Clock) *Elector {
+	return &Elector{
+		store:         store,
+		instanceID:    instanceID,
+		ttl:           ttl,
+		renewInterval: renewInterval,
+		clock:         clk,
+	}
+}
+
+func (e *Elector) OnElected(fn func()) {
+	e.onElected = append(e.onElected, fn)
+}
+
+func (e *Elector) Run(ctx context.Context) {
+	log.Printf("HA mode: instance %s campaigning for leadership (lease TTL %v)", e.instanceID, e.ttl)
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-e.clock.After(e.renewInterval):
+		}
+	}
+}
+
+// campaign claims or renews the lease once and logs any change of role
+func (e *Elector) campaign(ctx context.Context) {
+	now := e.clock.Now().UTC()
+
+	claimCtx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	lease, err := e.store.ClaimLeadership(claimCtx, LeaseName, e.instanceID, now, e.ttl)
+	cancel()
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	if err != nil {
+		e.lastErr = err.Error()
+		// Keep leading while the last renewal holds; after that another
+		// instance may already have taken over
+		if e.leader && !now.Before(e.lease.ExpiresAt) {
+			e.leader = false
+		}
+	} else {
+		e.lastErr = ""
+		e.lease = *lease
+		e.leader = lease.HolderID == e.instanceID
+	}
+	isLeader := e.leader
+	leaderID := e.lease.HolderID
+	if isLeader != wasLeader {
+		e.lastTransition = now
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Leadership claim failed for %s: %v", e.instanceID, err)
+	}
+
+	switch {
+	case isLeader && !wasLeader:
+		log.Printf("Instance %s acquired leadership; starting schedules", e.instanceID)
+		for _, fn := range e.onElected {
+			go fn()
+		}
+	case !isLeader && wasLeader:
+		log.Printf("Instance %s lost leadership (current leader: %s); scheduled runs will be skipped", e.instanceID, leaderID)
+	}
+}
+
+// release gives up the lease on shutdown so a follower takes over immediately
+func (e *Elector) release() {
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	if wasLeader {
+		e.lastTransition = e.clock.Now().UTC()
+	}
+	e.mu.Unlock()
+
+	if !wasLeader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := e.store.ReleaseLeadership(ctx, LeaseName, e.instanceID); err != nil {
+		log.Printf("Failed to release leadership for %s: %v", e.instanceID, err)
+		return
+	}
+	log.Printf("Instance %s released leadership", e.instanceID)
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := Status{
+		HAMode:         true,
+		InstanceID:     e.instanceID,
+		Role:           RoleFollower,
+		LeaderID:       e.lease.HolderID,
+		LeaseExpiresAt: e.lease.ExpiresAt,
+		LastTransition: e.lastTransition,
+		LastError:      e.lastErr,
+	}
+	if e.leader {
+		status.Role = RoleLeader
+	}
+	return status
+}
+
+// Static is the HA_MODE=off elector: the only instance always leads
+type Static struct {
+	instanceID string
+}
+
+func NewStatic(instanceID string) *Static {
+	return &Static{instanceID: instanceID}
+}
+
+// Run returns immediately; there is no lease to hold
+func (s *Static) Run(ctx context.Context) {}
+
+// OnElected runs fn immediately, since a single instance is leader from the start
+func (s *Static) OnElected(fn func()) {
+	go fn()
+}
+
+func (s *Static) IsLeader() bool {
+	return true
+}
+
+func (s *Static) Status() Status {
+	return Status{InstanceID: s.instanceID, Role: RoleSingle, LeaderID: s.instanceID}
+}
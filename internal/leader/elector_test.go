@@ -0,0 +1,202 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+)
+
+const (
+	testTTL   = 30 * time.Second
+	testRenew = 10 * time.Second
+)
+
+// memoryLeases is a storage.LeaderStore with the lease semantics of
+// MongoStorage, reading expiry against a shared fake clock
+type memoryLeases struct {
+	clock *clocktest.Fake
+
+	mu     sync.Mutex
+	leases map[string]models.LeaderLease
+	err    error
+}
+
+func newMemoryLeases(clk *clocktest.Fake) *memoryLeases {
+	return &memoryLeases{clock: clk, leases: map[string]models.LeaderLease{}}
+}
+
+func (m *memoryLeases) ClaimLeadership(ctx context.Context, name, instanceID string, now time.Time, ttl time.Duration) (*models.LeaderLease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	lease, ok := m.leases[name]
+	if ok && lease.HolderID != instanceID && now.Before(lease.ExpiresAt) {
+		return &lease, nil
+	}
+	if !ok || lease.HolderID != instanceID {
+		lease = models.LeaderLease{Name: name, HolderID: instanceID, AcquiredAt: now}
+	}
+	lease.RenewedAt = now
+	lease.ExpiresAt = now.Add(ttl)
+	m.leases[name] = lease
+	return &lease, nil
+}
+
+func (m *memoryLeases) ReleaseLeadership(ctx context.Context, name, instanceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lease, ok := m.leases[name]; ok && lease.HolderID == instanceID {
+		lease.ExpiresAt = m.clock.Now().UTC()
+		m.leases[name] = lease
+	}
+	return nil
+}
+
+func (m *memoryLeases) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// newTestElector returns an elector whose OnElected callbacks signal elected
+func newTestElector(store *memoryLeases, clk *clocktest.Fake, instanceID string) (*Elector, chan struct{}) {
+	elected := make(chan struct{}, 4)
+	e := NewElector(store, instanceID, testTTL, testRenew, clk)
+	e.OnElected(func() { elected <- struct{}{} })
+	return e, elected
+}
+
+func wantElected(t *testing.T, elected chan struct{}, instanceID string) {
+	t.Helper()
+	select {
+	case <-elected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("OnElected callbacks of %s did not run", instanceID)
+	}
+}
+
+func wantRole(t *testing.T, e *Elector, role, leaderID string) {
+	t.Helper()
+	status := e.Status()
+	if status.Role != role || status.LeaderID != leaderID {
+		t.Fatalf("%s status = %+v, want role %s with leader %s", e.instanceID, status, role, leaderID)
+	}
+	if e.IsLeader() != (role == RoleLeader) {
+		t.Fatalf("%s IsLeader() = %v with role %s", e.instanceID, e.IsLeader(), role)
+	}
+}
+
+func TestFollowerTakesOverExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := newMemoryLeases(clk)
+	a, aElected := newTestElector(store, clk, "a")
+	b, bElected := newTestElector(store, clk, "b")
+
+	a.campaign(ctx)
+	wantElected(t, aElected, "a")
+	b.campaign(ctx)
+	wantRole(t, a, RoleLeader, "a")
+	wantRole(t, b, RoleFollower, "a")
+
+	// Renewals keep the lease past its first expiry
+	for i := 0; i < 4; i++ {
+		clk.Advance(testRenew)
+		a.campaign(ctx)
+		b.campaign(ctx)
+	}
+	wantRole(t, b, RoleFollower, "a")
+
+	// a dies: its heartbeat stops, and b takes over only once the lease expires
+	clk.Advance(testTTL - time.Second)
+	b.campaign(ctx)
+	wantRole(t, b, RoleFollower, "a")
+
+	clk.Advance(time.Second)
+	b.campaign(ctx)
+	wantElected(t, bElected, "b")
+	wantRole(t, b, RoleLeader, "b")
+	if got := b.Status().LastTransition; !got.Equal(clk.Now()) {
+		t.Errorf("b LastTransition = %v, want %v", got, clk.Now())
+	}
+
+	// a comes back and steps down instead of scheduling alongside b
+	a.campaign(ctx)
+	wantRole(t, a, RoleFollower, "b")
+	select {
+	case <-aElected:
+		t.Fatal("a was elected again while b held the lease")
+	default:
+	}
+}
+
+func TestElectorKeepsLeadingThroughClaimErrorsUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := newMemoryLeases(clk)
+	a, aElected := newTestElector(store, clk, "a")
+
+	a.campaign(ctx)
+	wantElected(t, aElected, "a")
+
+	store.fail(errors.New("connection refused"))
+	clk.Advance(testTTL - time.Second)
+	a.campaign(ctx)
+	wantRole(t, a, RoleLeader, "a")
+	if got := a.Status().LastError; got != "connection refused" {
+		t.Errorf("LastError = %q, want the claim error", got)
+	}
+
+	// Past the lease another instance may lead, so a stops scheduling
+	clk.Advance(time.Second)
+	a.campaign(ctx)
+	wantRole(t, a, RoleFollower, "a")
+}
+
+func TestElectorReleasesLeaseOnShutdown(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := newMemoryLeases(clk)
+	a, aElected := newTestElector(store, clk, "a")
+	b, bElected := newTestElector(store, clk, "b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx)
+		close(done)
+	}()
+	wantElected(t, aElected, "a")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after its context was cancelled")
+	}
+	wantRole(t, a, RoleFollower, "a")
+
+	// b takes over at once, without waiting out the TTL
+	b.campaign(context.Background())
+	wantElected(t, bElected, "b")
+	wantRole(t, b, RoleLeader, "b")
+}
+
+func TestStaticAlwaysLeads(t *testing.T) {
+	s := NewStatic("solo")
+	elected := make(chan struct{}, 1)
+	s.OnElected(func() { elected <- struct{}{} })
+	wantElected(t, elected, "solo")
+
+	status := s.Status()
+	if !s.IsLeader() || status.HAMode || status.Role != RoleSingle || status.LeaderID != "solo" {
+		t.Errorf("Static status = %+v, IsLeader() = %v; want a single instance leading", status, s.IsLeader())
+	}
+}
@@ -0,0 +1,35 @@
+// internal/leader/interface.go
+package leader
+
+import (
+	"context"
+	"time"
+)
+
+type ElectorInterface interface {
+	// Run campaigns for leadership until ctx is cancelled, then releases it
+	Run(ctx context.Context)
+	// OnElected registers fn to run (in its own goroutine) each time this
+	// instance becomes leader; register before Run
+	OnElected(fn func())
+	IsLeader() bool
+	Status() Status
+}
+
+// Roles reported in Status
+const (
+	RoleSingle   = "single" // HA_MODE=off: always leads, no lease
+	RoleLeader   = "leader"
+	RoleFollower = "follower"
+)
+
+// Status describes this instance's view of leadership
+type Status struct {
+	HAMode         bool      `json:"ha_mode"`
+	InstanceID     string    `json:"instance_id"`
+	Role           string    `json:"role"`
+	LeaderID       string    `json:"leader_id,omitempty"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at,omitempty"`
+	LastTransition time.Time `json:"last_transition,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+}
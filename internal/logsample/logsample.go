@@ -0,0 +1,168 @@
+// internal/logsample/logsample.go
+package logsample
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSamples is how many item IDs an Aggregator keeps per reason
+const DefaultSamples = 3
+
+// Aggregator counts repeated per-item errors within one run so each reason
+// is logged once, e.g. "validation failed: empty_title ×312 (sample
+// reddit_ids: a,b,c)". It is not safe for concurrent use.
+type Aggregator struct {
+	prefix     string
+	idName     string
+	maxSamples int
+	counts     map[string]int
+	samples    map[string][]string
+}
+
+// NewAggregator creates an aggregator whose lines start with prefix and name
+// the sampled IDs idName; maxSamples <= 0 keeps DefaultSamples
+func NewAggregator(prefix, idName string, maxSamples int) *Aggregator {
+	if maxSamples <= 0 {
+		maxSamples = DefaultSamples
+	}
+	return &Aggregator{
+		prefix:     prefix,
+		idName:     idName,
+		maxSamples: maxSamples,
+		counts:     make(map[string]int),
+		samples:    make(map[string][]string),
+	}
+}
+
+// Add counts one item failing for reason; an empty id is counted unsampled
+func (a *Aggregator) Add(reason, id string) {
+	a.AddN(reason, 1, id)
+}
+
+// AddN counts n items failing for reason, sampling the given IDs
+func (a *Aggregator) AddN(reason string, n int, ids ...string) {
+	if n <= 0 {
+		return
+	}
+	a.counts[reason] += n
+	for _, id := range ids {
+		if id != "" && len(a.samples[reason]) < a.maxSamples {
+			a.samples[reason] = append(a.samples[reason], id)
+		}
+	}
+}
+
+// Total is the number of items counted across reasons
+func (a *Aggregator) Total() int {
+	total := 0
+	for _, count := range a.counts {
+		total += count
+	}
+	return total
+}
+
+// Lines returns one line per reason, most frequent first
+func (a *Aggregator) Lines() []string {
+	reasons := make([]string, 0, len(a.counts))
+	for reason := range a.counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if a.counts[reasons[i]] != a.counts[reasons[j]] {
+			return a.counts[reasons[i]] > a.counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	lines := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		line := fmt.Sprintf("%s: %s ×%d", a.prefix, reason, a.counts[reason])
+		if samples := a.samples[reason]; len(samples) > 0 {
+			line += fmt.Sprintf(" (sample %s: %s)", a.idName, strings.Join(samples, ","))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// maxLimiterKeys bounds the keys a Limiter tracks; expired ones are dropped past it
+const maxLimiterKeys = 1024
+
+// Limiter caps how often the same warning is logged across runs: at most
+// burst lines per key in each window. The next line let through after a
+// window with drops reports how many were dropped.
+type Limiter struct {
+	burst  int
+	window time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*limiterKey
+}
+
+type limiterKey struct {
+	windowStart time.Time
+	count       int
+	suppressed  int
+}
+
+// NewLimiter creates a limiter allowing burst lines per key every window
+func NewLimiter(burst int, window time.Duration) *Limiter {
+	return &Limiter{burst: burst, window: window, now: time.Now, keys: make(map[string]*limiterKey)}
+}
+
+// Allow reports whether a line for key may be logged now, and how many lines
+// for key were dropped since the last one allowed
+func (l *Limiter) Allow(key string) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	state, ok := l.keys[key]
+	if !ok {
+		if len(l.keys) >= maxLimiterKeys {
+			l.dropExpired(now)
+		}
+		state = &limiterKey{windowStart: now}
+		l.keys[key] = state
+	}
+	if now.Sub(state.windowStart) >= l.window {
+		state.windowStart = now
+		state.count = 0
+	}
+
+	if state.count >= l.burst {
+		state.suppressed++
+		return false, 0
+	}
+	state.count++
+	suppressed := state.suppressed
+	state.suppressed = 0
+	return true, suppressed
+}
+
+// Printf logs the line unless key is over its limit
+func (l *Limiter) Printf(key, format string, args ...interface{}) {
+	ok, suppressed := l.Allow(key)
+	if !ok {
+		return
+	}
+	line := fmt.Sprintf(format, args...)
+	if suppressed > 0 {
+		line += fmt.Sprintf(" (%d similar lines suppressed)", suppressed)
+	}
+	log.Print(line)
+}
+
+func (l *Limiter) dropExpired(now time.Time) {
+	for key, state := range l.keys {
+		if now.Sub(state.windowStart) >= l.window && state.suppressed == 0 {
+			delete(l.keys, key)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package logsample
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAggregatorLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		add       func(a *Aggregator)
+		wantLines []string
+		wantTotal int
+	}{
+		{
+			name:      "nothing counted",
+			add:       func(a *Aggregator) {},
+			wantLines: []string{},
+		},
+		{
+			name: "repeated reason is one line with capped samples",
+			add: func(a *Aggregator) {
+				for _, id := range []string{"a", "b", "c", "d", "e"} {
+					a.Add("empty_title", id)
+				}
+			},
+			wantLines: []string{"validation failed: empty_title ×5 (sample reddit_ids: a,b,c)"},
+			wantTotal: 5,
+		},
+		{
+			name: "distinct reasons stay apart, most frequent first",
+			add: func(a *Aggregator) {
+				a.Add("too_old", "x")
+				a.Add("empty_title", "a")
+				a.Add("empty_title", "b")
+				a.Add("nsfw", "n")
+			},
+			wantLines: []string{
+				"validation failed: empty_title ×2 (sample reddit_ids: a,b)",
+				"validation failed: nsfw ×1 (sample reddit_ids: n)",
+				"validation failed: too_old ×1 (sample reddit_ids: x)",
+			},
+			wantTotal: 4,
+		},
+		{
+			name: "reasons differing only in case are distinct",
+			add: func(a *Aggregator) {
+				a.Add("Empty_Title", "a")
+				a.Add("empty_title", "b")
+			},
+			wantLines: []string{
+				"validation failed: Empty_Title ×1 (sample reddit_ids: a)",
+				"validation failed: empty_title ×1 (sample reddit_ids: b)",
+			},
+			wantTotal: 2,
+		},
+		{
+			name: "counts without ids",
+			add: func(a *Aggregator) {
+				a.Add("empty_title", "")
+				a.AddN("duplicate", 40)
+				a.AddN("ignored", 0, "z")
+			},
+			wantLines: []string{
+				"validation failed: duplicate ×40",
+				"validation failed: empty_title ×1",
+			},
+			wantTotal: 41,
+		},
+		{
+			name: "batch with samples",
+			add: func(a *Aggregator) {
+				a.AddN("tag_failed", 312, "a", "", "b", "c", "d")
+			},
+			wantLines: []string{"validation failed: tag_failed ×312 (sample reddit_ids: a,b,c)"},
+			wantTotal: 312,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAggregator("validation failed", "reddit_ids", 0)
+			tt.add(a)
+			if got := a.Lines(); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("Lines() = %q, want %q", got, tt.wantLines)
+			}
+			if got := a.Total(); got != tt.wantTotal {
+				t.Errorf("Total() = %d, want %d", got, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// newTestLimiter returns a limiter reading the time from *now
+func newTestLimiter(burst int, window time.Duration, now *time.Time) *Limiter {
+	l := NewLimiter(burst, window)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(2, time.Minute, &now)
+
+	for i := 0; i < 2; i++ {
+		if ok, suppressed := l.Allow("slow find"); !ok || suppressed != 0 {
+			t.Fatalf("line %d: Allow() = %v, %d; want allowed", i, ok, suppressed)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("slow find"); ok {
+			t.Fatalf("line %d past the burst was allowed", i+3)
+		}
+	}
+	// Other keys have their own budget
+	if ok, _ := l.Allow("slow aggregate"); !ok {
+		t.Error("a different key was limited")
+	}
+
+	now = now.Add(59 * time.Second)
+	if ok, _ := l.Allow("slow find"); ok {
+		t.Error("allowed before the window ended")
+	}
+	now = now.Add(time.Second)
+	if ok, suppressed := l.Allow("slow find"); !ok || suppressed != 4 {
+		t.Errorf("next window: Allow() = %v, %d; want allowed reporting 4 suppressed", ok, suppressed)
+	}
+	if _, suppressed := l.Allow("slow find"); suppressed != 0 {
+		t.Errorf("suppressed count %d reported twice", suppressed)
+	}
+}
+
+func TestLimiterPrintf(t *testing.T) {
+	var out bytes.Buffer
+	flags := log.Flags()
+	log.SetOutput(&out)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	})
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(1, time.Minute, &now)
+	for i := 0; i < 3; i++ {
+		l.Printf("slow", "slow query %d", i)
+	}
+	now = now.Add(time.Minute)
+	l.Printf("slow", "slow query %d", 3)
+
+	want := "slow query 0\nslow query 3 (2 similar lines suppressed)\n"
+	if got := out.String(); got != want {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+func TestLimiterDropsExpiredKeys(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestLimiter(1, time.Minute, &now)
+	for i := 0; i < maxLimiterKeys; i++ {
+		l.Allow(fmt.Sprint("key", i))
+	}
+	now = now.Add(time.Minute)
+	l.Allow("new")
+	if got := len(l.keys); got != 1 {
+		t.Errorf("limiter tracks %d keys after the others expired, want 1", got)
+	}
+}
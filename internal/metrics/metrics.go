@@ -0,0 +1,150 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "orchestrator"
+
+// Registry holds orchestrator metrics. BlueBerry keeps its own registry for
+// task execution metrics, so ours is served on a separate path.
+var Registry = prometheus.NewRegistry()
+
+// Handler serves the orchestrator registry in Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// RegisterQueueGauges exposes run queue depth and the age of the oldest
+// waiting run, both evaluated at scrape time
+func RegisterQueueGauges(depth, oldestWaitSeconds func() float64) {
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "run_queue_depth",
+		Help:      "Number of subreddit runs waiting for a concurrency slot.",
+	}, depth))
+	register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "run_queue_oldest_wait_seconds",
+		Help:      "Age in seconds of the oldest run waiting for a concurrency slot.",
+	}, oldestWaitSeconds))
+}
+
+// register adds a collector, tolerating repeated registration of the same metric
+func register(collector prometheus.Collector) {
+	if err := Registry.Register(collector); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			panic(err)
+		}
+	}
+}
+
+var processorRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "processor_rejections_total",
+	Help:      "Posts dropped by the processor, by subreddit and reason.",
+}, []string{"subreddit", "reason"})
+
+func init() {
+	register(processorRejections)
+}
+
+// RecordRejections adds a batch's rejection counts to the per-reason counters
+func RecordRejections(subreddit string, rejections map[string]int) {
+	for reason, count := range rejections {
+		processorRejections.WithLabelValues(subreddit, reason).Add(float64(count))
+	}
+}
+
+var storageOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "storage_operations_total",
+	Help:      "Storage operations by operation and outcome (ok or error category).",
+}, []string{"operation", "outcome"})
+
+func init() {
+	register(storageOperations)
+}
+
+// RecordStorageOperation counts one storage operation outcome
+func RecordStorageOperation(operation, outcome string) {
+	storageOperations.WithLabelValues(operation, outcome).Inc()
+}
+
+var scheduleFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "schedule_registration_failures_total",
+	Help:      "Schedules that failed to register at startup, by kind.",
+}, []string{"kind"})
+
+func init() {
+	register(scheduleFailures)
+}
+
+// RecordScheduleFailure counts one schedule that failed to register
+func RecordScheduleFailure(kind string) {
+	scheduleFailures.WithLabelValues(kind).Inc()
+}
+
+var storageLatency = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "storage_ping_latency_seconds",
+	Help:      "Round-trip latency of the latest storage health check.",
+})
+
+func init() {
+	register(storageLatency)
+}
+
+// SetStorageLatency records the latest storage health check round trip
+func SetStorageLatency(latency time.Duration) {
+	storageLatency.Set(latency.Seconds())
+}
+
+var (
+	oversizedDocuments = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "oversized_documents",
+		Help:      "Post documents above DOCUMENT_SIZE_THRESHOLD found by the latest document audit.",
+	})
+	largestDocumentBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "largest_oversized_document_bytes",
+		Help:      "BSON size of the largest oversized post found by the latest document audit (0 when none).",
+	})
+)
+
+func init() {
+	register(oversizedDocuments)
+	register(largestDocumentBytes)
+}
+
+// SetOversizedDocuments records the latest document audit's findings
+func SetOversizedDocuments(count, largestBytes int) {
+	oversizedDocuments.Set(float64(count))
+	largestDocumentBytes.Set(float64(largestBytes))
+}
+
+var requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "http_request_duration_seconds",
+	Help:      "Orchestrator API request duration by method, route template and status.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+func init() {
+	register(requestDuration)
+}
+
+// ObserveRequest records one API request under its route template
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	requestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
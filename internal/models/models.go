@@ -2,6 +2,12 @@
 package models
 
 import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,9 +18,177 @@ type SubredditMetadata struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	SubredditName  string             `bson:"subreddit_name" json:"subreddit_name"`
 	LastScrapedAt  time.Time          `bson:"last_scraped_at" json:"last_scraped_at"`
-	MonitorConfig  MonitorConfig      `bson:"monitor_config" json:"monitor_config"`
-	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	// MonitorConfig is only written when set, so a nil one leaves the stored value
+	MonitorConfig *MonitorConfig `bson:"monitor_config,omitempty" json:"monitor_config,omitempty"`
+	LastRun       *RunStats      `bson:"last_run,omitempty" json:"last_run,omitempty"`
+	// ZeroPostRuns counts consecutive runs that fetched nothing; Stale is set
+	// once it passes the subreddit's threshold
+	ZeroPostRuns int  `bson:"zero_post_runs,omitempty" json:"zero_post_runs,omitempty"`
+	Stale        bool `bson:"stale,omitempty" json:"stale,omitempty"`
+	// About is refreshed by refresh_subreddit_info; nil until its first run
+	About *AboutInfo `bson:"about,omitempty" json:"about,omitempty"`
+	// Activity is computed by reconcile_adaptive_schedules for subreddits
+	// with adaptive_schedule; nil until its first run
+	Activity *ActivityProfile `bson:"activity,omitempty" json:"activity,omitempty"`
+	// RecentRuns are the outcomes of the latest monitor runs, oldest first,
+	// capped at MaxRecentRuns
+	RecentRuns []RunOutcome `bson:"recent_runs,omitempty" json:"recent_runs,omitempty"`
+	// CursorCorrection is the latest automatic reset of LastScrapedAt;
+	// CursorProbedAt is when the zero-post cursor probe last ran
+	CursorCorrection *CursorCorrection `bson:"cursor_correction,omitempty" json:"cursor_correction,omitempty"`
+	CursorProbedAt   *time.Time        `bson:"cursor_probed_at,omitempty" json:"cursor_probed_at,omitempty"`
+	// BestRecentPost is the top post of the latest run that stored posts
+	BestRecentPost *BestRecentPost `bson:"best_recent_post,omitempty" json:"best_recent_post,omitempty"`
+	CreatedAt      time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+// MaxRecentRuns caps SubredditMetadata.RecentRuns
+const MaxRecentRuns = 50
+
+// RunOutcome is whether one monitor run failed
+type RunOutcome struct {
+	At     time.Time `bson:"at" json:"at"`
+	Failed bool      `bson:"failed" json:"failed"`
+}
+
+// FailureRate is the share of runs since since that failed; zero when there
+// were none
+func (m *SubredditMetadata) FailureRate(since time.Time) float64 {
+	runs, failed := 0, 0
+	for _, run := range m.RecentRuns {
+		if run.At.Before(since) {
+			continue
+		}
+		runs++
+		if run.Failed {
+			failed++
+		}
+	}
+	if runs == 0 {
+		return 0
+	}
+	return float64(failed) / float64(runs)
+}
+
+// PrioritySuggestion is the priority recompute_priorities derived from a
+// subreddit's recent activity, with the stats it was scored from
+type PrioritySuggestion struct {
+	Priority    int       `bson:"priority" json:"priority"`
+	PostsPerDay float64   `bson:"posts_per_day" json:"posts_per_day"`
+	AvgScore    float64   `bson:"avg_score" json:"avg_score"`
+	FailureRate float64   `bson:"failure_rate" json:"failure_rate"`
+	Applied     bool      `bson:"applied" json:"applied"`
+	ComputedAt  time.Time `bson:"computed_at" json:"computed_at"`
+}
+
+// HoursPerWeek is the length of an ActivityProfile
+const HoursPerWeek = 7 * 24
+
+// ActivityProfile is a subreddit's posting volume by hour of the week (UTC),
+// averaged over the hourly rollups of the last Weeks weeks
+type ActivityProfile struct {
+	// PostsPerHour has one average per hour of the week, Monday 00:00 UTC first
+	PostsPerHour []float64 `bson:"posts_per_hour" json:"posts_per_hour"`
+	// PeakHours are the busiest hours of the week, as indexes into PostsPerHour
+	PeakHours  []int     `bson:"peak_hours" json:"peak_hours"`
+	Weeks      int       `bson:"weeks" json:"weeks"`
+	ComputedAt time.Time `bson:"computed_at" json:"computed_at"`
+}
+
+// HourOfWeek returns t's index into PostsPerHour
+func HourOfWeek(t time.Time) int {
+	t = t.UTC()
+	return (int(t.Weekday())+6)%7*24 + t.Hour()
+}
+
+// IsPeak reports whether t falls in one of the profile's peak hours
+func (p *ActivityProfile) IsPeak(t time.Time) bool {
+	hour := HourOfWeek(t)
+	for _, peak := range p.PeakHours {
+		if peak == hour {
+			return true
+		}
+	}
+	return false
+}
+
+// AboutInfo is a subreddit's "about" data from the ingestion API. Available
+// is false when the API could not provide it, leaving the other fields empty.
+type AboutInfo struct {
+	Available   bool   `bson:"available" json:"available"`
+	Subscribers int    `bson:"subscribers,omitempty" json:"subscribers,omitempty"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+	// SubredditCreatedAt is when the subreddit itself was created
+	SubredditCreatedAt *time.Time `bson:"subreddit_created_at,omitempty" json:"subreddit_created_at,omitempty"`
+	Over18             bool       `bson:"over18,omitempty" json:"over18,omitempty"`
+	FetchedAt          time.Time  `bson:"fetched_at" json:"fetched_at"`
+}
+
+// RunStats summarizes the most recent scrape of a subreddit
+type RunStats struct {
+	Limit          int            `bson:"limit" json:"limit"`
+	PostsFetched   int            `bson:"posts_fetched" json:"posts_fetched"`
+	PostsProcessed int            `bson:"posts_processed" json:"posts_processed"`
+	SkippedNSFW    int            `bson:"skipped_nsfw,omitempty" json:"skipped_nsfw,omitempty"`
+	SkippedSpoiler int            `bson:"skipped_spoiler,omitempty" json:"skipped_spoiler,omitempty"`
+	Rejections     map[string]int `bson:"rejections,omitempty" json:"rejections,omitempty"` // Processor rejection reason -> count
+	Duration       time.Duration  `bson:"duration" json:"duration"`
+	// Source is how the posts arrived: RunSourcePoll or RunSourcePush
+	Source string `bson:"source,omitempty" json:"source,omitempty"`
+	// created_at range of the processed posts; unset when none had a timestamp
+	OldestPostAt *time.Time `bson:"oldest_post_at,omitempty" json:"oldest_post_at,omitempty"`
+	NewestPostAt *time.Time `bson:"newest_post_at,omitempty" json:"newest_post_at,omitempty"`
+}
+
+// Run sources; runs recorded before sources were tracked have none and were polled
+const (
+	RunSourcePoll = "poll"
+	RunSourcePush = "push"
+)
+
+// SetPostRange records the processed posts' created_at range; zero times leave it unset
+func (s *RunStats) SetPostRange(oldest, newest time.Time) {
+	if oldest.IsZero() || newest.IsZero() {
+		return
+	}
+	s.OldestPostAt = &oldest
+	s.NewestPostAt = &newest
+}
+
+// MetadataRepair is a proposed correction of a subreddit's last_scraped_at
+type MetadataRepair struct {
+	SubredditName string    `json:"subreddit_name"`
+	Reason        string    `json:"reason"`
+	Before        time.Time `json:"before"`
+	After         time.Time `json:"after"` // Newest stored post's created_at, zero if none
+	Applied       bool      `json:"applied"`
+}
+
+// BestRecentPost summarizes the highest-scoring post a monitor run stored
+type BestRecentPost struct {
+	RedditID   string    `bson:"reddit_id" json:"reddit_id"`
+	Title      string    `bson:"title" json:"title"`
+	Score      int       `bson:"score" json:"score"`
+	Permalink  string    `bson:"permalink,omitempty" json:"permalink,omitempty"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	RecordedAt time.Time `bson:"recorded_at" json:"recorded_at"`
+}
+
+// Reasons a monitor run reset a subreddit's scrape cursor
+const (
+	CursorReasonFuture    = "in_future"
+	CursorReasonTooOld    = "too_old"
+	CursorReasonZeroPosts = "zero_posts_with_activity"
+)
+
+// CursorCorrection records a monitor run resetting last_scraped_at from
+// Before to After
+type CursorCorrection struct {
+	Reason string    `bson:"reason" json:"reason"`
+	Before time.Time `bson:"before" json:"before"`
+	After  time.Time `bson:"after" json:"after"`
+	At     time.Time `bson:"at" json:"at"`
 }
 
 // MonitorConfig holds configuration for monitoring subreddits
@@ -25,31 +199,446 @@ type MonitorConfig struct {
 
 // SubredditConfig represents a subreddit configuration for monitoring
 type SubredditConfig struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SubredditName  string             `bson:"subreddit_name" json:"subreddit_name"`
+	Enabled        bool               `bson:"enabled" json:"enabled"`
+	Schedule       string             `bson:"schedule" json:"schedule"`           
+	MaxPosts       int                `bson:"max_posts" json:"max_posts"`
+	Priority       int                `bson:"priority" json:"priority"`           // Higher number = higher priority
+	Description    string             `bson:"description,omitempty" json:"description,omitempty"`
+	TagRules       []TagRule          `bson:"tag_rules,omitempty" json:"tag_rules,omitempty"`
+	TrackRevisions bool               `bson:"track_revisions,omitempty" json:"track_revisions,omitempty"` // Keep prior title/body versions of edited posts
+	SkipNSFW       bool               `bson:"skip_nsfw,omitempty" json:"skip_nsfw,omitempty"`
+	SkipSpoilers   bool               `bson:"skip_spoilers,omitempty" json:"skip_spoilers,omitempty"`
+	// EnrichmentFailClosed fails the run when enrichment fails instead of storing posts unenriched
+	EnrichmentFailClosed bool `bson:"enrichment_fail_closed,omitempty" json:"enrichment_fail_closed,omitempty"`
+	// ExpectedPostIntervalHours is the longest normal gap between posts; zero
+	// uses the global stale threshold
+	ExpectedPostIntervalHours int `bson:"expected_post_interval_hours,omitempty" json:"expected_post_interval_hours,omitempty"`
+	// MaxPostAgeDays drops posts older than this many days and bounds a
+	// full first run; zero keeps every post
+	MaxPostAgeDays int `bson:"max_post_age_days,omitempty" json:"max_post_age_days,omitempty"`
+	// FirstRunMode is how the first run, which has no cursor, fetches:
+	// FirstRunSkipHistory, FirstRunLastNHours or FirstRunFull. Empty is
+	// FirstRunLastNHours.
+	FirstRunMode string `bson:"first_run_mode,omitempty" json:"first_run_mode,omitempty"`
+	// Labels group configs for bulk operations and stats; normalized on save
+	Labels []string `bson:"labels,omitempty" json:"labels,omitempty"`
+	// Paused skips scheduled runs from the next run on, without a restart
+	Paused bool `bson:"paused,omitempty" json:"paused,omitempty"`
+	// PushEnabled marks a subreddit whose posts are pushed to /api/ingest;
+	// it is then polled on PUSH_POLL_SCHEDULE as a fallback
+	PushEnabled bool `bson:"push_enabled,omitempty" json:"push_enabled,omitempty"`
+	// AdaptiveSchedule scrapes on ADAPTIVE_MIN_INTERVAL during the subreddit's
+	// busiest hours of the week and on ADAPTIVE_MAX_INTERVAL otherwise,
+	// replacing its schedule once an activity profile exists
+	AdaptiveSchedule bool `bson:"adaptive_schedule,omitempty" json:"adaptive_schedule,omitempty"`
+	// SampleRate is the share of fetched posts (0 to 1) kept in qa_samples
+	// with their raw payload for QA; zero disables sampling
+	SampleRate float64 `bson:"sample_rate,omitempty" json:"sample_rate,omitempty"`
+	// PriorityLocked keeps recompute_priorities from changing Priority
+	PriorityLocked bool `bson:"priority_locked,omitempty" json:"priority_locked,omitempty"`
+	// PrioritySuggestion is written by recompute_priorities; it is not
+	// editable and survives config updates
+	PrioritySuggestion *PrioritySuggestion `bson:"priority_suggestion,omitempty" json:"priority_suggestion,omitempty"`
+	// Template names the config template this config was created from; later
+	// template changes do not apply to it
+	Template  string    `bson:"template,omitempty" json:"template,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	// Set on soft delete; deleted configs are hidden until restored or purged
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+// First run modes of a subreddit config
+const (
+	// FirstRunSkipHistory stores nothing and sets the cursor to now, so only
+	// posts made after the first run are collected
+	FirstRunSkipHistory = "skip_history"
+	// FirstRunLastNHours fetches the last DEFAULT_LOOKBACK_HOURS
+	FirstRunLastNHours = "last_n_hours"
+	// FirstRunFull fetches as far back as the ingestion API goes, bounded
+	// only by MaxPostAgeDays
+	FirstRunFull = "full"
+)
+
+// UserConfig represents a Reddit account whose submissions are monitored
+// across all subreddits
+type UserConfig struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Username  string             `bson:"username" json:"username"`
+	Enabled   bool               `bson:"enabled" json:"enabled"`
+	Schedule  string             `bson:"schedule" json:"schedule"`
+	MaxPosts  int                `bson:"max_posts" json:"max_posts"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	// SkipNSFW and SkipSpoilers filter the user's posts like the subreddit
+	// config fields of the same name
+	SkipNSFW     bool `bson:"skip_nsfw,omitempty" json:"skip_nsfw,omitempty"`
+	SkipSpoilers bool `bson:"skip_spoilers,omitempty" json:"skip_spoilers,omitempty"`
+}
+
+// Validate checks the user configuration before it is saved
+func (c *UserConfig) Validate() error {
+	if strings.TrimSpace(c.Username) == "" {
+		return fmt.Errorf("username is required")
+	}
+	if c.MaxPosts < 0 {
+		return fmt.Errorf("max_posts must not be negative")
+	}
+	return nil
+}
+
+// ValidateMaxPosts rejects a max_posts value the ingestion API would silently cap
+func ValidateMaxPosts(maxPosts, ceiling int) error {
+	if ceiling > 0 && maxPosts > ceiling {
+		return fmt.Errorf("max_posts %d exceeds the ingestion API limit of %d posts per run (MAX_POSTS_CEILING)", maxPosts, ceiling)
+	}
+	return nil
+}
+
+// UserMetadata tracks the scrape cursor of a monitored user
+type UserMetadata struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	SubredditName string             `bson:"subreddit_name" json:"subreddit_name"`
-	Enabled       bool               `bson:"enabled" json:"enabled"`
-	Schedule      string             `bson:"schedule" json:"schedule"`           
-	MaxPosts      int                `bson:"max_posts" json:"max_posts"`
-	Priority      int                `bson:"priority" json:"priority"`           // Higher number = higher priority
-	Description   string             `bson:"description,omitempty" json:"description,omitempty"`
+	Username      string             `bson:"username" json:"username"`
+	LastScrapedAt time.Time          `bson:"last_scraped_at" json:"last_scraped_at"`
+	LastRun       *RunStats          `bson:"last_run,omitempty" json:"last_run,omitempty"`
 	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
+// TagRule assigns Tag to posts whose title/body contain any of the keywords
+// or whose title matches TitleRegex
+type TagRule struct {
+	Tag           string   `bson:"tag" json:"tag"`
+	AnyOfKeywords []string `bson:"any_of_keywords,omitempty" json:"any_of_keywords,omitempty"`
+	TitleRegex    string   `bson:"title_regex,omitempty" json:"title_regex,omitempty"`
+}
+
+// Validate checks the subreddit configuration before it is saved and
+// normalizes its labels in place
+func (c *SubredditConfig) Validate() error {
+	if strings.TrimSpace(c.SubredditName) == "" {
+		return fmt.Errorf("subreddit_name is required")
+	}
+	if c.MaxPostAgeDays < 0 {
+		return fmt.Errorf("max_post_age_days must not be negative")
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("sample_rate must be between 0 and 1")
+	}
+	switch c.FirstRunMode {
+	case "", FirstRunSkipHistory, FirstRunLastNHours, FirstRunFull:
+	default:
+		return fmt.Errorf("first_run_mode must be %s, %s or %s", FirstRunSkipHistory, FirstRunLastNHours, FirstRunFull)
+	}
+
+	labels, err := NormalizeLabels(c.Labels)
+	if err != nil {
+		return err
+	}
+	c.Labels = labels
+
+	for i, rule := range c.TagRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("tag_rules[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// MaxPostAge is MaxPostAgeDays as a duration; zero means no limit
+func (c *SubredditConfig) MaxPostAge() time.Duration {
+	return time.Duration(c.MaxPostAgeDays) * 24 * time.Hour
+}
+
+// FirstRun returns FirstRunMode, defaulting to FirstRunLastNHours; a nil
+// config is an unconfigured subreddit
+func (c *SubredditConfig) FirstRun() string {
+	if c == nil || c.FirstRunMode == "" {
+		return FirstRunLastNHours
+	}
+	return c.FirstRunMode
+}
+
+// ConfigTemplate holds preset values for new subreddit configs. Zero
+// Schedule and MaxPosts fall back to the global defaults.
+type ConfigTemplate struct {
+	ID                        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name                      string             `bson:"name" json:"name"`
+	Description               string             `bson:"description,omitempty" json:"description,omitempty"`
+	Schedule                  string             `bson:"schedule,omitempty" json:"schedule,omitempty"`
+	MaxPosts                  int                `bson:"max_posts,omitempty" json:"max_posts,omitempty"`
+	Priority                  int                `bson:"priority,omitempty" json:"priority,omitempty"`
+	Labels                    []string           `bson:"labels,omitempty" json:"labels,omitempty"`
+	TagRules                  []TagRule          `bson:"tag_rules,omitempty" json:"tag_rules,omitempty"`
+	TrackRevisions            bool               `bson:"track_revisions,omitempty" json:"track_revisions,omitempty"`
+	SkipNSFW                  bool               `bson:"skip_nsfw,omitempty" json:"skip_nsfw,omitempty"`
+	SkipSpoilers              bool               `bson:"skip_spoilers,omitempty" json:"skip_spoilers,omitempty"`
+	ExpectedPostIntervalHours int                `bson:"expected_post_interval_hours,omitempty" json:"expected_post_interval_hours,omitempty"`
+	CreatedAt                 time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt                 time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the template before it is saved and normalizes its labels
+func (t *ConfigTemplate) Validate() error {
+	if !labelPattern.MatchString(t.Name) {
+		return fmt.Errorf("template name %q must be 1-40 characters of a-z, 0-9, '-' or '_'", t.Name)
+	}
+	if t.MaxPosts < 0 || t.ExpectedPostIntervalHours < 0 {
+		return fmt.Errorf("max_posts and expected_post_interval_hours must not be negative")
+	}
+
+	labels, err := NormalizeLabels(t.Labels)
+	if err != nil {
+		return err
+	}
+	t.Labels = labels
+
+	for i, rule := range t.TagRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("tag_rules[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// NewConfig returns an enabled config for subreddit with the template's values
+func (t *ConfigTemplate) NewConfig(subreddit string) SubredditConfig {
+	return SubredditConfig{
+		SubredditName:             subreddit,
+		Enabled:                   true,
+		Schedule:                  t.Schedule,
+		MaxPosts:                  t.MaxPosts,
+		Priority:                  t.Priority,
+		Labels:                    append([]string(nil), t.Labels...),
+		TagRules:                  append([]TagRule(nil), t.TagRules...),
+		TrackRevisions:            t.TrackRevisions,
+		SkipNSFW:                  t.SkipNSFW,
+		SkipSpoilers:              t.SkipSpoilers,
+		ExpectedPostIntervalHours: t.ExpectedPostIntervalHours,
+		Template:                  t.Name,
+	}
+}
+
+// DefaultConfigTemplates are the built-in templates, created once by a
+// storage migration; they can be edited or deleted like any other
+func DefaultConfigTemplates() []ConfigTemplate {
+	return []ConfigTemplate{
+		{
+			Name:                      "news-high-volume",
+			Description:               "Busy news subreddits: frequent runs, large pages, edits tracked",
+			Schedule:                  "@every 15m",
+			MaxPosts:                  500,
+			Priority:                  10,
+			Labels:                    []string{"news"},
+			TrackRevisions:            true,
+			ExpectedPostIntervalHours: 1,
+		},
+		{
+			Name:                      "community-low-volume",
+			Description:               "Quiet communities: a few runs a day, default page size",
+			Schedule:                  "@every 6h",
+			MaxPosts:                  100,
+			Labels:                    []string{"community"},
+			ExpectedPostIntervalHours: 48,
+		},
+	}
+}
+
+// RunPreset is a named set of task params for manual runs. String values may
+// hold placeholders: {{name}} takes the run request's query param of that
+// name, {{ago:24h}} the epoch seconds that long ago and {{max_limit}}
+// MAX_POSTS_CEILING.
+type RunPreset struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name        string                 `bson:"name" json:"name"`
+	Task        string                 `bson:"task" json:"task"`
+	Description string                 `bson:"description,omitempty" json:"description,omitempty"`
+	Params      map[string]interface{} `bson:"params" json:"params"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the preset's name and task; whether the params fit the
+// task is checked by the task manager
+func (p *RunPreset) Validate() error {
+	if !labelPattern.MatchString(p.Name) {
+		return fmt.Errorf("preset name %q must be 1-40 characters of a-z, 0-9, '-' or '_'", p.Name)
+	}
+	p.Task = strings.TrimSpace(p.Task)
+	if p.Task == "" {
+		return fmt.Errorf("task is required")
+	}
+	if p.Params == nil {
+		p.Params = map[string]interface{}{}
+	}
+	return nil
+}
+
+// DefaultRunPresets are the built-in presets, created once by a storage
+// migration; they can be edited or deleted like any other
+func DefaultRunPresets() []RunPreset {
+	return []RunPreset{
+		{
+			Name:        "full-refresh",
+			Task:        "monitor_subreddit",
+			Description: "Refetch up to MAX_POSTS_CEILING posts, ignoring the last scrape",
+			Params: map[string]interface{}{
+				"subreddit":       "{{subreddit}}",
+				"limit":           "{{max_limit}}",
+				"since_timestamp": "1117584000", // 2005-06-01, before Reddit's first posts
+			},
+		},
+		{
+			Name:        "last-24h",
+			Task:        "monitor_subreddit",
+			Description: "Refetch the posts of the last 24 hours",
+			Params: map[string]interface{}{
+				"subreddit":       "{{subreddit}}",
+				"limit":           "{{max_limit}}",
+				"since_timestamp": "{{ago:24h}}",
+			},
+		},
+		{
+			Name:        "dry-run",
+			Task:        "monitor_subreddit",
+			Description: "Fetch and process new posts without storing anything",
+			Params: map[string]interface{}{
+				"subreddit": "{{subreddit}}",
+				"dry_run":   true,
+			},
+		},
+	}
+}
+
+// MaxLabelsPerConfig bounds how many labels one subreddit config can carry
+const MaxLabelsPerConfig = 10
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// NormalizeLabel lowercases and trims a label and checks its format
+func NormalizeLabel(label string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if !labelPattern.MatchString(label) {
+		return "", fmt.Errorf("label %q must be 1-40 characters of a-z, 0-9, '-' or '_'", label)
+	}
+	return label, nil
+}
+
+// NormalizeLabels normalizes each label, drops duplicates and enforces MaxLabelsPerConfig
+func NormalizeLabels(labels []string) ([]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(labels))
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label, err := NormalizeLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[label] {
+			seen[label] = true
+			normalized = append(normalized, label)
+		}
+	}
+	if len(normalized) > MaxLabelsPerConfig {
+		return nil, fmt.Errorf("at most %d labels are allowed, got %d", MaxLabelsPerConfig, len(normalized))
+	}
+	return normalized, nil
+}
+
+// Validate checks that the rule has a tag, at least one condition and a compilable regex
+func (r TagRule) Validate() error {
+	if strings.TrimSpace(r.Tag) == "" {
+		return fmt.Errorf("tag is required")
+	}
+	if len(r.AnyOfKeywords) == 0 && r.TitleRegex == "" {
+		return fmt.Errorf("rule %q needs any_of_keywords or title_regex", r.Tag)
+	}
+	if r.TitleRegex != "" {
+		if _, err := regexp.Compile(r.TitleRegex); err != nil {
+			return fmt.Errorf("rule %q has invalid title_regex: %w", r.Tag, err)
+		}
+	}
+	return nil
+}
+
 // Post represents a Reddit post stored in MongoDB
 type Post struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	RedditID   string             `bson:"reddit_id" json:"reddit_id"`
-	Title      string             `bson:"title" json:"title"`
-	Body       string             `bson:"body" json:"body"`
-	Author     string             `bson:"author" json:"author"`
-	Score      int                `bson:"score" json:"score"`
-	Subreddit  string             `bson:"subreddit" json:"subreddit"`
-	URL        string             `bson:"url" json:"url"`
-	Flair      string             `bson:"flair,omitempty" json:"flair,omitempty"`
-	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
-	InsertedAt time.Time          `bson:"inserted_at" json:"inserted_at"`
-	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	RedditID      string                 `bson:"reddit_id" json:"reddit_id"`
+	Title         string                 `bson:"title" json:"title"`
+	Body          string                 `bson:"body" json:"body"`
+	Author        string                 `bson:"author" json:"author"`
+	Score         int                    `bson:"score" json:"score"`
+	Subreddit     string                 `bson:"subreddit" json:"subreddit"`
+	URL           string                 `bson:"url" json:"url"`
+	Permalink     string                 `bson:"permalink,omitempty" json:"permalink,omitempty"` // Reddit thread URL; URL may point at an external article
+	NormalizedURL string                 `bson:"normalized_url,omitempty" json:"-"`              // URL reduced for grouping; empty for self posts
+	Domain        string                 `bson:"domain" json:"domain"`                           // Registrable domain of URL, DomainSelf or empty when URL is malformed
+	Flair         string                 `bson:"flair,omitempty" json:"flair,omitempty"`
+	Tags          []string               `bson:"tags,omitempty" json:"tags,omitempty"`
+	ContentHash   string                 `bson:"content_hash,omitempty" json:"-"` // Fingerprint of title+body to detect edits
+	RevisionCount int                    `bson:"revision_count,omitempty" json:"revision_count"`
+	IsNSFW        bool                   `bson:"is_nsfw" json:"is_nsfw"`
+	Spoiler       bool                   `bson:"spoiler" json:"spoiler"`
+	NSFWUnknown   bool                   `bson:"nsfw_unknown,omitempty" json:"nsfw_unknown,omitempty"` // Payload had no is_nsfw flag; treated as SFW
+	Extras        map[string]interface{} `bson:"extras,omitempty" json:"extras,omitempty"`             // Fields added by the enrichment service
+	// FlairHistory records flair changes seen on re-upserts, oldest first and
+	// capped; FlairChangedAt is when the latest was seen
+	FlairHistory   []FlairChange `bson:"flair_history,omitempty" json:"flair_history,omitempty"`
+	FlairChangedAt *time.Time    `bson:"flair_changed_at,omitempty" json:"flair_changed_at,omitempty"`
+	// Status is maintained by reconcile_removed_posts (see PostStatusVisible);
+	// MissedScans counts the consecutive scans the post was absent from
+	Status      string     `bson:"status,omitempty" json:"status,omitempty"`
+	RemovedAt   *time.Time `bson:"removed_at,omitempty" json:"removed_at,omitempty"`
+	RestoredAt  *time.Time `bson:"restored_at,omitempty" json:"restored_at,omitempty"`
+	MissedScans int        `bson:"missed_scans,omitempty" json:"-"`
+	// ImportBatch labels posts first stored by a snapshot import such as
+	// import_top_posts; posts scraped by monitoring have none
+	ImportBatch string    `bson:"import_batch,omitempty" json:"import_batch,omitempty"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	InsertedAt  time.Time `bson:"inserted_at" json:"inserted_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Post statuses. A post missing from two consecutive scans of its
+// subreddit's recent window is removed (e.g. held in a mod queue) and is
+// restored once a scan sees it again. Posts stored before statuses were
+// tracked have none and count as visible.
+const (
+	PostStatusVisible  = "visible"
+	PostStatusRemoved  = "removed"
+	PostStatusRestored = "restored"
+)
+
+// PresenceScan is the outcome of comparing one scan with the stored posts
+type PresenceScan struct {
+	Missed   int `json:"missed"`   // Posts absent from this scan, removed or not
+	Removed  int `json:"removed"`  // Posts newly marked removed
+	Restored int `json:"restored"` // Removed posts seen again
+}
+
+// FlairChange is one flair change of a stored post; empty is no flair
+type FlairChange struct {
+	Old        string    `bson:"old" json:"old"`
+	New        string    `bson:"new" json:"new"`
+	ObservedAt time.Time `bson:"observed_at" json:"observed_at"`
+}
+
+// PostRevision is a previous version of an edited post's title/body
+type PostRevision struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RedditID    string             `bson:"reddit_id" json:"reddit_id"`
+	Title       string             `bson:"title" json:"title"`
+	Body        string             `bson:"body" json:"body"`
+	ContentHash string             `bson:"content_hash" json:"content_hash"`
+	CapturedAt  time.Time          `bson:"captured_at" json:"captured_at"`
 }
 
 // IngestionPost represents the structure returned by the ingestion API
@@ -60,8 +649,499 @@ type IngestionPost struct {
 	Author    string    `json:"author"`
 	Score     int       `json:"score"`
 	CreatedAt time.Time `json:"created_at"`
+	Subreddit string    `json:"subreddit,omitempty"` // Set by the /user endpoint
 	Flair     string    `json:"flair,omitempty"`
 	URL       string    `json:"url"`
+	Permalink string    `json:"permalink,omitempty"` // Absolute or site-relative ("/r/...") thread URL
+	IsNSFW    *bool     `json:"is_nsfw,omitempty"`   // nil when the payload omits the flag
+	Spoiler   *bool     `json:"spoiler,omitempty"`
+}
+
+// TagBurstAuthor marks the posts of a detected author burst. The anomaly task
+// writes it, not the processor, so rescrapes and reprocessing keep it while
+// they replace the processor's tags.
+const TagBurstAuthor = "burst-author"
+
+// IsAnomalyTag reports whether tag is written by the anomaly task
+func IsAnomalyTag(tag string) bool {
+	return tag == TagBurstAuthor
+}
+
+// KeepAnomalyTags returns tags with the anomaly tags among stored added, the
+// tags a post stored with stored ends up with when it is written with tags
+func KeepAnomalyTags(tags, stored []string) []string {
+	kept := tags
+	for _, tag := range stored {
+		if IsAnomalyTag(tag) && !slices.Contains(kept, tag) {
+			kept = append(slices.Clip(kept), tag)
+		}
+	}
+	return kept
+}
+
+// Anomaly records an author posting unusually often to a subreddit within one window
+type Anomaly struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Subreddit   string             `bson:"subreddit" json:"subreddit"`
+	Author      string             `bson:"author" json:"author"`
+	WindowStart time.Time          `bson:"window_start" json:"window_start"`
+	WindowEnd   time.Time          `bson:"window_end" json:"window_end"`
+	Count       int                `bson:"count" json:"count"`
+	SampleIDs   []string           `bson:"sample_ids" json:"sample_ids"`
+	DetectedAt  time.Time          `bson:"detected_at" json:"detected_at"`
+}
+
+// DomainSelf is the domain of self posts
+const DomainSelf = "self"
+
+// DomainStat is how many posts linked to one domain and their average score
+type DomainStat struct {
+	Domain    string  `bson:"_id" json:"domain"`
+	PostCount int     `bson:"post_count" json:"post_count"`
+	AvgScore  float64 `bson:"avg_score" json:"avg_score"`
+}
+
+// DomainStats breaks posts down by linked domain, most linked first
+type DomainStats struct {
+	Domains []DomainStat `json:"domains"`
+	// Unparsed counts posts whose URL has no usable host; Unextracted counts
+	// posts stored before domains were extracted, until they are reprocessed
+	Unparsed    int `json:"unparsed"`
+	Unextracted int `json:"unextracted"`
+}
+
+// DuplicateURL is an external link posted to several monitored subreddits,
+// grouped by its normalized URL
+type DuplicateURL struct {
+	URL            string               `bson:"_id" json:"url"`
+	SubredditCount int                  `bson:"subreddit_count" json:"subreddit_count"`
+	PostCount      int                  `bson:"post_count" json:"post_count"`
+	Subreddits     []string             `bson:"subreddits" json:"subreddits"`
+	FirstSeen      time.Time            `bson:"first_seen" json:"first_seen"`
+	LastSeen       time.Time            `bson:"last_seen" json:"last_seen"`
+	Samples        []DuplicateURLSample `bson:"samples" json:"samples"`
+}
+
+// DuplicateURLSample references one post sharing a duplicate URL
+type DuplicateURLSample struct {
+	RedditID  string    `bson:"reddit_id" json:"reddit_id"`
+	Subreddit string    `bson:"subreddit" json:"subreddit"`
+	Title     string    `bson:"title" json:"title"`
+	Permalink string    `bson:"permalink,omitempty" json:"permalink,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// NotificationRule alerts on newly inserted posts that match any of its
+// conditions. Empty Subreddits means every subreddit.
+type NotificationRule struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name            string             `bson:"name" json:"name"`
+	Enabled         bool               `bson:"enabled" json:"enabled"`
+	Subreddits      []string           `bson:"subreddits,omitempty" json:"subreddits,omitempty"`
+	Keywords        []string           `bson:"keywords,omitempty" json:"keywords,omitempty"` // Case-insensitive, matched against title and body
+	TitleRegex      string             `bson:"title_regex,omitempty" json:"title_regex,omitempty"`
+	BodyRegex       string             `bson:"body_regex,omitempty" json:"body_regex,omitempty"`
+	Channel         string             `bson:"channel,omitempty" json:"channel,omitempty"`
+	WebhookURL      string             `bson:"webhook_url,omitempty" json:"webhook_url,omitempty"` // Overrides the default notifier
+	CooldownMinutes int                `bson:"cooldown_minutes" json:"cooldown_minutes"`
+	LastFiredAt     time.Time          `bson:"last_fired_at,omitempty" json:"last_fired_at,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the rule has a name and a condition, and that its regexes compile
+func (r *NotificationRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Keywords) == 0 && r.TitleRegex == "" && r.BodyRegex == "" {
+		return fmt.Errorf("rule %q needs keywords, title_regex or body_regex", r.Name)
+	}
+	for _, keyword := range r.Keywords {
+		if strings.TrimSpace(keyword) == "" {
+			return fmt.Errorf("rule %q has an empty keyword", r.Name)
+		}
+	}
+	if r.TitleRegex != "" {
+		if _, err := regexp.Compile(r.TitleRegex); err != nil {
+			return fmt.Errorf("rule %q has invalid title_regex: %w", r.Name, err)
+		}
+	}
+	if r.BodyRegex != "" {
+		if _, err := regexp.Compile(r.BodyRegex); err != nil {
+			return fmt.Errorf("rule %q has invalid body_regex: %w", r.Name, err)
+		}
+	}
+	if r.WebhookURL != "" {
+		parsed, err := url.Parse(r.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("rule %q has invalid webhook_url", r.Name)
+		}
+	}
+	if r.CooldownMinutes < 0 {
+		return fmt.Errorf("cooldown_minutes must not be negative")
+	}
+	return nil
+}
+
+// NotificationLog records one post matched by a notification rule. Suppressed
+// entries matched while the rule was cooling down and were not sent.
+type NotificationLog struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RuleName     string             `bson:"rule_name" json:"rule_name"`
+	RedditID     string             `bson:"reddit_id" json:"reddit_id"`
+	Subreddit    string             `bson:"subreddit" json:"subreddit"`
+	Title        string             `bson:"title" json:"title"`
+	MatchedTerms []string           `bson:"matched_terms" json:"matched_terms"`
+	Channel      string             `bson:"channel,omitempty" json:"channel,omitempty"`
+	Suppressed   bool               `bson:"suppressed" json:"suppressed"`
+	Error        string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// MaxDeliveryPostIDs caps the post IDs a webhook delivery keeps for replay;
+// PostIDsTruncated marks deliveries that listed more posts
+const MaxDeliveryPostIDs = 100
+
+// WebhookDelivery is one attempt to send a notification rule's matches to a
+// webhook. Bodies are not stored; a replay rebuilds them from RedditIDs.
+type WebhookDelivery struct {
+	ID               primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	RuleName         string              `bson:"rule_name" json:"rule_name"`
+	Target           string              `bson:"target" json:"target"` // Scheme and host only
+	Channel          string              `bson:"channel,omitempty" json:"channel,omitempty"`
+	BatchSize        int                 `bson:"batch_size" json:"batch_size"`
+	RedditIDs        []string            `bson:"reddit_ids" json:"reddit_ids"`
+	PostIDsTruncated bool                `bson:"post_ids_truncated,omitempty" json:"post_ids_truncated,omitempty"`
+	Status           string              `bson:"status" json:"status"`
+	StatusCode       int                 `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	LatencyMs        int64               `bson:"latency_ms" json:"latency_ms"`
+	Error            string              `bson:"error,omitempty" json:"error,omitempty"`
+	ReplayOf         *primitive.ObjectID `bson:"replay_of,omitempty" json:"replay_of,omitempty"` // Original delivery of a replay
+	CreatedAt        time.Time           `bson:"created_at" json:"created_at"`
+	ExpiresAt        time.Time           `bson:"expires_at" json:"-"`
+}
+
+// Digest sends a label's top posts of the last day as one summary. Recipients
+// are mailed through SMTP and WebhookURL receives it as a Slack-compatible
+// webhook; with neither, the default notifier gets it.
+type Digest struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Label      string             `bson:"label" json:"label"`
+	Enabled    bool               `bson:"enabled" json:"enabled"`
+	Schedule   string             `bson:"schedule,omitempty" json:"schedule,omitempty"` // Empty uses DIGEST_SCHEDULE
+	Recipients []string           `bson:"recipients,omitempty" json:"recipients,omitempty"`
+	WebhookURL string             `bson:"webhook_url,omitempty" json:"webhook_url,omitempty"`
+	// SendEmpty sends a "no activity" digest instead of skipping a quiet day
+	SendEmpty  bool      `bson:"send_empty,omitempty" json:"send_empty,omitempty"`
+	LastSentAt time.Time `bson:"last_sent_at,omitempty" json:"last_sent_at,omitempty"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate normalizes the label and checks the recipients and webhook URL
+func (d *Digest) Validate() error {
+	label, err := NormalizeLabel(d.Label)
+	if err != nil {
+		return err
+	}
+	d.Label = label
+	for _, recipient := range d.Recipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("digest %q has invalid recipient %q", d.Label, recipient)
+		}
+	}
+	if d.WebhookURL != "" {
+		parsed, err := url.Parse(d.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("digest %q has invalid webhook_url", d.Label)
+		}
+	}
+	return nil
+}
+
+// PostRollup aggregates the posts of one subreddit created within one hour.
+// Scores are as first seen; later score changes are not applied.
+type PostRollup struct {
+	Subreddit     string    `bson:"subreddit" json:"subreddit"`
+	BucketStart   time.Time `bson:"bucket_start" json:"bucket_start"`
+	PostCount     int       `bson:"post_count" json:"post_count"`
+	SumScore      int64     `bson:"sum_score" json:"sum_score"`
+	MaxScore      int       `bson:"max_score" json:"max_score"`
+	Authors       []string  `bson:"authors,omitempty" json:"-"`       // Distinct authors, capped
+	AuthorCount   int       `bson:"author_count" json:"author_count"` // Exact unless AuthorsCapped
+	AuthorsCapped bool      `bson:"authors_capped,omitempty" json:"authors_capped,omitempty"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SubredditStorageStats is the storage footprint of one subreddit's posts, as
+// of the latest storage_stats refresh. Bytes is the BSON size of the documents,
+// excluding indexes and compression.
+type SubredditStorageStats struct {
+	Subreddit  string    `bson:"_id" json:"subreddit"`
+	Documents  int64     `bson:"documents" json:"documents"`
+	Bytes      int64     `bson:"bytes" json:"bytes"`
+	AvgBytes   int64     `bson:"avg_bytes" json:"avg_bytes"`
+	ComputedAt time.Time `bson:"computed_at" json:"computed_at"`
+}
+
+// OpsDataStats is the size and age of one operational collection and how
+// long its documents are kept. Bytes is the uncompressed data size.
+type OpsDataStats struct {
+	Database   string     `json:"database"`
+	Collection string     `json:"collection"`
+	Documents  int64      `json:"documents"`
+	Bytes      int64      `json:"bytes"`
+	Oldest     *time.Time `json:"oldest"`
+	// Retention is a Go duration, or empty when documents are kept forever;
+	// Setting names the env var it comes from
+	Retention string `json:"retention"`
+	Setting   string `json:"setting"`
+}
+
+// ReprocessScopeAll is the ReprocessProgress scope of a pass over every subreddit
+const ReprocessScopeAll = "*"
+
+// ReprocessProgress checkpoints a posts reprocess pass after every batch, so
+// an interrupted pass resumes where it stopped. Scope is the subreddit, or
+// ReprocessScopeAll.
+type ReprocessProgress struct {
+	Scope        string             `bson:"_id" json:"scope"`
+	ApplyFilters bool               `bson:"apply_filters" json:"apply_filters"`
+	Collection   string             `bson:"collection" json:"collection"` // Post collection being read
+	LastID       primitive.ObjectID `bson:"last_id" json:"last_id"`       // Last post _id handled in it
+	Examined     int64              `bson:"examined" json:"examined"`
+	Modified     int64              `bson:"modified" json:"modified"`
+	Deleted      int64              `bson:"deleted" json:"deleted"`
+	StartedAt    time.Time          `bson:"started_at" json:"started_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Config audit actions
+const (
+	ConfigAuditCreate  = "create"
+	ConfigAuditUpdate  = "update"
+	ConfigAuditDelete  = "delete"
+	ConfigAuditRestore = "restore"
+	ConfigAuditPurge   = "purge"
+)
+
+// ConfigChange is the old and new value of one changed config field
+type ConfigChange struct {
+	From interface{} `bson:"from" json:"from"`
+	To   interface{} `bson:"to" json:"to"`
+}
+
+// ConfigAudit records one change to a subreddit config and who made it
+type ConfigAudit struct {
+	ID            primitive.ObjectID      `bson:"_id,omitempty" json:"id"`
+	SubredditName string                  `bson:"subreddit_name" json:"subreddit_name"`
+	Action        string                  `bson:"action" json:"action"`
+	Actor         string                  `bson:"actor" json:"actor"`
+	Changes       map[string]ConfigChange `bson:"changes,omitempty" json:"changes,omitempty"` // Keyed by bson field name
+	CreatedAt     time.Time               `bson:"created_at" json:"created_at"`
+}
+
+// Staged config change actions
+const (
+	StagedCreate = "create"
+	StagedUpdate = "update"
+	StagedDelete = "delete"
+)
+
+// StagedConfigChange is a subreddit config change waiting in the staging area
+// until all staged changes are committed together
+type StagedConfigChange struct {
+	SubredditName string `bson:"subreddit_name" json:"subreddit_name"`
+	Delete        bool   `bson:"delete,omitempty" json:"delete,omitempty"`
+	// Config is the whole config to apply; nil for deletes
+	Config *SubredditConfig `bson:"config,omitempty" json:"config,omitempty"`
+	// BaseUpdatedAt is the live config's updated_at when the change was first
+	// staged, nil when there was none; a commit fails if it moved since
+	BaseUpdatedAt *time.Time `bson:"base_updated_at,omitempty" json:"base_updated_at,omitempty"`
+	StagedBy      string     `bson:"staged_by" json:"staged_by"`
+	StagedAt      time.Time  `bson:"staged_at" json:"staged_at"`
+
+	// Action and Changes compare the change to the live config when read
+	Action  string                  `bson:"-" json:"action"`
+	Changes map[string]ConfigChange `bson:"-" json:"changes,omitempty"` // Keyed by bson field name
+	// Conflict says why the change can't be committed as staged
+	Conflict string `bson:"-" json:"conflict,omitempty"`
+}
+
+// LeaderLease is the lease document an HA instance must hold to run schedules
+type LeaderLease struct {
+	Name       string    `bson:"_id" json:"name"`
+	HolderID   string    `bson:"holder_id" json:"holder_id"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"` // When the current holder took over
+	RenewedAt  time.Time `bson:"renewed_at" json:"renewed_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// MaintenancePauseID is the _id of the single maintenance pause document
+const MaintenancePauseID = "global"
+
+// MaintenancePause stops scheduled scraping on every instance, e.g. while
+// the ingestion service is down for maintenance. A nil Until lasts until it
+// is cleared.
+type MaintenancePause struct {
+	ID       string     `bson:"_id" json:"-"`
+	Reason   string     `bson:"reason,omitempty" json:"reason,omitempty"`
+	Actor    string     `bson:"actor,omitempty" json:"actor,omitempty"`
+	PausedAt time.Time  `bson:"paused_at" json:"paused_at"`
+	Until    *time.Time `bson:"until,omitempty" json:"until,omitempty"`
+}
+
+// RuntimeState is what an orchestrator process last recorded about itself, one
+// document per host, for external supervisors. CleanShutdown stays false until
+// the process has finished shutting down.
+type RuntimeState struct {
+	Host          string     `bson:"_id" json:"host"`
+	InstanceID    string     `bson:"instance_id" json:"instance_id"`
+	PID           int        `bson:"pid" json:"pid"`
+	StartedAt     time.Time  `bson:"started_at" json:"started_at"`
+	HeartbeatAt   time.Time  `bson:"heartbeat_at" json:"heartbeat_at"`
+	Running       []string   `bson:"running,omitempty" json:"running,omitempty"` // Subreddits with a run in flight at the last heartbeat
+	CleanShutdown bool       `bson:"clean_shutdown" json:"clean_shutdown"`
+	StoppedAt     *time.Time `bson:"stopped_at,omitempty" json:"stopped_at,omitempty"`
+}
+
+// Delivery modes of a consumer
+const (
+	// ConsumerModeAuto advances the cursor as posts are read: at-most-once
+	ConsumerModeAuto = "auto"
+	// ConsumerModeAck advances it when the batch is acked: at-least-once
+	ConsumerModeAck = "ack"
+)
+
+// Consumer is a named reader of posts with a delivery cursor per subreddit,
+// so a downstream job gets each post once without deduplicating itself
+type Consumer struct {
+	Name string `bson:"_id" json:"name"`
+	Mode string `bson:"mode" json:"mode"`
+	// Cursors holds, per subreddit, the last post delivered (auto) or acked (ack)
+	Cursors map[string]ConsumerPosition `bson:"cursors,omitempty" json:"cursors,omitempty"`
+	// Pending holds, per subreddit, the batch delivered in ack mode and not acked yet
+	Pending   map[string]ConsumerBatch `bson:"pending,omitempty" json:"pending,omitempty"`
+	CreatedAt time.Time                `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time                `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the consumer before it is saved
+func (c *Consumer) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.Mode != ConsumerModeAuto && c.Mode != ConsumerModeAck {
+		return fmt.Errorf("mode must be %s or %s", ConsumerModeAuto, ConsumerModeAck)
+	}
+	return nil
+}
+
+// ConsumerPosition is a position in a subreddit's posts in insertion order:
+// the inserted_at and _id of the last post before it. The zero value is the start.
+type ConsumerPosition struct {
+	InsertedAt time.Time          `bson:"inserted_at" json:"inserted_at"`
+	PostID     primitive.ObjectID `bson:"post_id" json:"post_id"`
+}
+
+// ConsumerBatch is a batch delivered in ack mode; acking Token moves the
+// cursor to Position, the last post of the batch
+type ConsumerBatch struct {
+	Token    string           `bson:"token" json:"token"`
+	Position ConsumerPosition `bson:"position" json:"position"`
+	Count    int              `bson:"count" json:"count"`
+	IssuedAt time.Time        `bson:"issued_at" json:"issued_at"`
+}
+
+// RawCapture is a raw ingestion API response kept for debugging decode issues
+type RawCapture struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Subreddit  string             `bson:"subreddit" json:"subreddit"`
+	RequestURL string             `bson:"request_url" json:"request_url"` // Credentials and secret params redacted
+	Body       string             `bson:"body" json:"body"`
+	Size       int                `bson:"size" json:"size"` // Bytes kept in Body
+	Truncated  bool               `bson:"truncated" json:"truncated"`
+	CapturedAt time.Time          `bson:"captured_at" json:"captured_at"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"` // Removed by a TTL index
+}
+
+// OversizedPost is a post document larger than the audit threshold
+type OversizedPost struct {
+	Collection   string             `bson:"-" json:"collection"`
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	RedditID     string             `bson:"reddit_id" json:"reddit_id"`
+	Subreddit    string             `bson:"subreddit" json:"subreddit"`
+	Bytes        int                `bson:"bytes" json:"bytes"`
+	FlairHistory int                `bson:"flair_history" json:"flair_history"` // Entries in flair_history
+}
+
+// ArchivedFlairChange is a flair change trimmed from an oversized post
+type ArchivedFlairChange struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PostID     primitive.ObjectID `bson:"post_id" json:"post_id"`
+	RedditID   string             `bson:"reddit_id" json:"reddit_id"`
+	Change     FlairChange        `bson:"change" json:"change"`
+	ArchivedAt time.Time          `bson:"archived_at" json:"archived_at"`
+}
+
+// QA sample stages and their outcomes
+const (
+	QAStageProcess = "process"
+	QAStageEnrich  = "enrich"
+
+	QAOutcomeKept     = "kept"
+	QAOutcomeRejected = "rejected"
+	QAOutcomeEnriched = "enriched"
+	QAOutcomeNoExtras = "no_extras" // Enricher added nothing, or failed open
+	QAOutcomeFailed   = "failed"
+	QAOutcomeSkipped  = "skipped" // Enrichment disabled
+)
+
+// QASample is a fetched post kept for QA with the post it was processed
+// into; Processed is nil when the processor rejected it
+type QASample struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RunID     string             `bson:"run_id" json:"run_id"`
+	Subreddit string             `bson:"subreddit" json:"subreddit"`
+	RedditID  string             `bson:"reddit_id" json:"reddit_id"`
+	Raw       IngestionPost      `bson:"raw" json:"raw"`
+	Processed *Post              `bson:"processed,omitempty" json:"processed,omitempty"`
+	Stages    []QAStage          `bson:"stages" json:"stages"`
+	SampledAt time.Time          `bson:"sampled_at" json:"sampled_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"` // Removed by a TTL index
+}
+
+// QAStage is what one pipeline stage did with a sampled post
+type QAStage struct {
+	Stage   string `bson:"stage" json:"stage"`
+	Outcome string `bson:"outcome" json:"outcome"`
+	Detail  string `bson:"detail,omitempty" json:"detail,omitempty"` // e.g. the rejection reason
+}
+
+// QueryPlanReport describes the winning plan of one canonical query shape
+type QueryPlanReport struct {
+	Name       string   `json:"name"`
+	Collection string   `json:"collection"`
+	IndexUsed  bool     `json:"index_used"`
+	Indexes    []string `json:"indexes,omitempty"`
+	Stages     []string `json:"stages"`
+}
+
+// TaskRunSummary is one run of a BlueBerry task as recorded in task_runs
+type TaskRunSummary struct {
+	ID         int        `json:"id"`
+	TaskName   string     `json:"task_name"`
+	Status     string     `json:"status"` // started, completed, failed or cancelled
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"` // Nil while running
+	DurationMs int64      `json:"duration_ms,omitempty"`
 }
 
 // TaskExecutionResult represents the result of a task execution
@@ -72,4 +1152,30 @@ type TaskExecutionResult struct {
 	PostsProcessed int           `json:"posts_processed"`
 	Duration       time.Duration `json:"duration"`
 	Error          string        `json:"error,omitempty"`
+}
+
+// Backup is a snapshot of everything except posts and derived data: configs,
+// scrape metadata, notification rules, config templates and digests
+type Backup struct {
+	Version           int                 `json:"version"`
+	CreatedAt         time.Time           `json:"created_at"`
+	SubredditConfigs  []SubredditConfig   `json:"subreddit_configs"` // Soft-deleted configs included
+	SubredditMetadata []SubredditMetadata `json:"subreddit_metadata"`
+	NotificationRules []NotificationRule  `json:"notification_rules"`
+	ConfigTemplates   []ConfigTemplate    `json:"config_templates"`
+	Digests           []Digest            `json:"digests"`
+}
+
+// RestoreCounts is what a restore did, or would do, to one collection
+type RestoreCounts struct {
+	Inserted int `json:"inserted"`
+	Updated  int `json:"updated"`
+	Deleted  int `json:"deleted"`
+}
+
+// RestoreReport summarizes a restore per collection
+type RestoreReport struct {
+	Mode        string                   `json:"mode"`
+	DryRun      bool                     `json:"dry_run"`
+	Collections map[string]RestoreCounts `json:"collections"`
 }
\ No newline at end of file
@@ -0,0 +1,440 @@
+// internal/notify/channels.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ghodss/yaml"
+)
+
+// Ensure FanoutNotifier implements ChannelNotifierInterface, and
+// DeliveringFanoutNotifier DelivererInterface too
+var (
+	_ ChannelNotifierInterface = (*FanoutNotifier)(nil)
+	_ ChannelNotifierInterface = (*DeliveringFanoutNotifier)(nil)
+	_ DelivererInterface       = (*DeliveringFanoutNotifier)(nil)
+)
+
+// Channel types
+const (
+	// ChannelSlack posts {"text": <rendered template>} to a Slack-compatible
+	// incoming webhook
+	ChannelSlack = "slack"
+	// ChannelWebhook posts the rendered template as a JSON body, e.g. a
+	// PagerDuty Events API payload; without a template it posts the
+	// notification as JSON, as NOTIFY_WEBHOOK_URL does
+	ChannelWebhook = "webhook"
+	// ChannelLog writes the rendered template to the process log
+	ChannelLog = "log"
+)
+
+// maxRenderedBytes caps what a channel template may render
+const maxRenderedBytes = 64 << 10
+
+// defaultTextTemplate renders slack and log channels without a template
+const defaultTextTemplate = "*{{.Subject}}*\n{{.Message}}"
+
+// ErrUnknownChannel means no channel has the requested name
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+// severityRank orders severities for MinSeverity
+var severityRank = map[string]int{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+// ChannelConfig is one named notification channel
+type ChannelConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+	// MinSeverity drops less severe notifications; empty sends every one
+	MinSeverity string `json:"min_severity,omitempty"`
+	// Template is a text/template over the notification (.Subject,
+	// .Message, .Severity, .Subreddit, .Error, .Counts and .SentAt), with
+	// the functions json, upper, lower, truncate, join and default
+	Template string `json:"template,omitempty"`
+}
+
+// templateFuncs is the whole function set channel templates get besides
+// text/template's builtins; none of them reach outside the notification
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"truncate": func(length int, text string) string {
+		if utf8.RuneCountInString(text) <= length {
+			return text
+		}
+		return string([]rune(text)[:max(length, 0)]) + "…"
+	},
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" || value == 0 {
+			return fallback
+		}
+		return value
+	},
+}
+
+// sampleNotification checks templates when channels are loaded
+var sampleNotification = Notification{
+	Subject:   "Sample subject",
+	Message:   "Sample message",
+	Severity:  SeverityCritical,
+	Subreddit: "golang",
+	Error:     "sample error",
+	Counts:    map[string]int{"posts": 1},
+}
+
+// LoadChannels reads a JSON or YAML list of channels; an empty path has none.
+// Every channel is validated, templates included, so a typo fails at startup.
+func LoadChannels(path string) ([]ChannelConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading notification channels: %w", err)
+	}
+	var channels []ChannelConfig
+	if err := yaml.Unmarshal(data, &channels); err != nil {
+		return nil, fmt.Errorf("parsing notification channels %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		if err := channel.Validate(); err != nil {
+			return nil, err
+		}
+		if names[channel.Name] {
+			return nil, fmt.Errorf("notification channel %q is defined twice", channel.Name)
+		}
+		names[channel.Name] = true
+	}
+	return channels, nil
+}
+
+// Validate checks the channel's name, type, URL, severity filter and template
+func (c ChannelConfig) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("notification channel name is required")
+	}
+	switch c.Type {
+	case ChannelSlack, ChannelWebhook:
+		parsed, err := url.Parse(c.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("notification channel %q needs an http(s) url", c.Name)
+		}
+	case ChannelLog:
+	default:
+		return fmt.Errorf("notification channel %q type must be %s, %s or %s", c.Name, ChannelSlack, ChannelWebhook, ChannelLog)
+	}
+	if _, ok := severityRank[c.MinSeverity]; c.MinSeverity != "" && !ok {
+		return fmt.Errorf("notification channel %q min_severity must be %s, %s or %s", c.Name, SeverityInfo, SeverityWarning, SeverityCritical)
+	}
+
+	ch, err := newChannel(c, time.Second)
+	if err != nil {
+		return err
+	}
+	if ch.template != nil {
+		rendered, err := ch.render(sampleNotification, time.Now())
+		if err != nil {
+			return fmt.Errorf("notification channel %q template: %w", c.Name, err)
+		}
+		if c.Type == ChannelWebhook && !json.Valid(rendered) {
+			return fmt.Errorf("notification channel %q template must render JSON", c.Name)
+		}
+	}
+	return nil
+}
+
+// ChannelStatus is a channel's delivery record since startup
+type ChannelStatus struct {
+	Name           string     `json:"name"`
+	Type           string     `json:"type"`
+	Target         string     `json:"target,omitempty"` // Scheme and host only
+	MinSeverity    string     `json:"min_severity,omitempty"`
+	Sent           int64      `json:"sent"`
+	Failed         int64      `json:"failed"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// channel is a configured channel with its compiled template and record
+type channel struct {
+	config     ChannelConfig
+	template   *template.Template
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	status ChannelStatus
+}
+
+func newChannel(config ChannelConfig, timeout time.Duration) (*channel, error) {
+	ch := &channel{
+		config:     config,
+		httpClient: &http.Client{Timeout: timeout},
+		status:     ChannelStatus{Name: config.Name, Type: config.Type, MinSeverity: config.MinSeverity},
+	}
+	if parsed, err := url.Parse(config.URL); err == nil && parsed.Host != "" {
+		ch.status.Target = parsed.Scheme + "://" + parsed.Host
+	}
+
+	text := config.Template
+	if text == "" && config.Type != ChannelWebhook {
+		text = defaultTextTemplate
+	}
+	if text != "" {
+		parsed, err := template.New(config.Name).Option("missingkey=zero").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("notification channel %q template: %w", config.Name, err)
+		}
+		ch.template = parsed
+	}
+	return ch, nil
+}
+
+// accepts reports whether the notification passes the severity filter;
+// unknown severities count as info
+func (c *channel) accepts(notification Notification) bool {
+	return severityRank[notification.Severity] >= severityRank[c.config.MinSeverity]
+}
+
+// templateData is what channel templates see
+type templateData struct {
+	Notification
+	SentAt time.Time
+}
+
+// render executes the template, failing once the output passes maxRenderedBytes
+func (c *channel) render(notification Notification, now time.Time) ([]byte, error) {
+	output := &limitedBuffer{limit: maxRenderedBytes}
+	if err := c.template.Execute(output, templateData{Notification: notification, SentAt: now}); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// body builds the request body, or the log line for log channels
+func (c *channel) body(notification Notification, now time.Time) ([]byte, error) {
+	if c.template == nil {
+		return json.Marshal(webhookPayload{
+			Text:         fmt.Sprintf("*%s*\n%s", notification.Subject, notification.Message),
+			Notification: notification,
+		})
+	}
+	rendered, err := c.render(notification, now)
+	if err != nil || c.config.Type != ChannelSlack {
+		return rendered, err
+	}
+	return json.Marshal(map[string]string{"text": string(rendered)})
+}
+
+// send delivers one notification and records the outcome. A non-empty
+// deliveryID is sent in DeliveryIDHeader.
+func (c *channel) send(ctx context.Context, notification Notification, deliveryID string) (ChannelStatus, error) {
+	now := time.Now().UTC()
+	statusCode, err := c.deliver(ctx, notification, deliveryID, now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.LastAttemptAt = &now
+	c.status.LastStatusCode = statusCode
+	c.status.LastError = ""
+	if err != nil {
+		c.status.Failed++
+		c.status.LastError = err.Error()
+	} else {
+		c.status.Sent++
+	}
+	return c.status, err
+}
+
+func (c *channel) deliver(ctx context.Context, notification Notification, deliveryID string, now time.Time) (int, error) {
+	body, err := c.body(notification, now)
+	if err != nil {
+		return 0, fmt.Errorf("rendering notification: %w", err)
+	}
+	switch c.config.Type {
+	case ChannelLog:
+		log.Printf("[%s] [%s] %s", c.config.Name, notification.Severity, body)
+		return 0, nil
+	case ChannelWebhook:
+		// A template that passed the sample can still break on a field
+		// value it doesn't escape
+		if !json.Valid(body) {
+			return 0, fmt.Errorf("notification channel %s template rendered invalid JSON", c.config.Name)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("creating notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if deliveryID != "" {
+		req.Header.Set(DeliveryIDHeader, deliveryID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return resp.StatusCode, fmt.Errorf("notification channel %s error %d: %s", c.config.Name, resp.StatusCode, string(respBody))
+	}
+	return resp.StatusCode, nil
+}
+
+// FanoutNotifier sends every notification to each channel whose severity
+// filter accepts it. Channels are sent to concurrently, so a slow or failing
+// channel never holds back the others.
+type FanoutNotifier struct {
+	channels []*channel
+}
+
+func NewFanoutNotifier(configs []ChannelConfig, timeout time.Duration) (*FanoutNotifier, error) {
+	notifier := &FanoutNotifier{}
+	for _, config := range configs {
+		ch, err := newChannel(config, timeout)
+		if err != nil {
+			return nil, err
+		}
+		notifier.channels = append(notifier.channels, ch)
+	}
+	return notifier, nil
+}
+
+// Notify sends to the accepting channels and joins their errors
+func (n *FanoutNotifier) Notify(ctx context.Context, notification Notification) error {
+	return n.broadcast(ctx, notification, "", nil)
+}
+
+// broadcast sends to the accepting channels but skip, with deliveryID, and
+// joins their errors
+func (n *FanoutNotifier) broadcast(ctx context.Context, notification Notification, deliveryID string, skip *channel) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, ch := range n.channels {
+		if ch == skip || !ch.accepts(notification) {
+			continue
+		}
+		wg.Add(1)
+		go func(ch *channel) {
+			defer wg.Done()
+			if _, err := ch.send(ctx, notification, deliveryID); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(ch)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// NotifyChannel sends to one channel regardless of its severity filter
+func (n *FanoutNotifier) NotifyChannel(ctx context.Context, name string, notification Notification) (ChannelStatus, error) {
+	for _, ch := range n.channels {
+		if ch.config.Name == name {
+			return ch.send(ctx, notification, "")
+		}
+	}
+	return ChannelStatus{}, fmt.Errorf("%w %q", ErrUnknownChannel, name)
+}
+
+// Channels returns every channel's delivery record, in configured order
+func (n *FanoutNotifier) Channels() []ChannelStatus {
+	statuses := make([]ChannelStatus, 0, len(n.channels))
+	for _, ch := range n.channels {
+		ch.mu.Lock()
+		statuses = append(statuses, ch.status)
+		ch.mu.Unlock()
+	}
+	return statuses
+}
+
+// DeliveringFanoutNotifier is a FanoutNotifier with one webhook channel whose
+// sends are recorded as webhook deliveries, so rule notifications keep their
+// delivery log, delivery IDs and replays when channels are configured
+type DeliveringFanoutNotifier struct {
+	*FanoutNotifier
+	webhook *channel
+}
+
+// NewDeliveringFanoutNotifier fans out to configs, recording the sends to
+// the webhook channel named webhook
+func NewDeliveringFanoutNotifier(configs []ChannelConfig, webhook string, timeout time.Duration) (*DeliveringFanoutNotifier, error) {
+	fanout, err := NewFanoutNotifier(configs, timeout)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range fanout.channels {
+		if ch.config.Name == webhook && ch.config.Type == ChannelWebhook {
+			return &DeliveringFanoutNotifier{FanoutNotifier: fanout, webhook: ch}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownChannel, webhook)
+}
+
+// Target is the webhook channel's scheme and host
+func (n *DeliveringFanoutNotifier) Target() string {
+	n.webhook.mu.Lock()
+	defer n.webhook.mu.Unlock()
+	if n.webhook.status.Target == "" {
+		return "webhook"
+	}
+	return n.webhook.status.Target
+}
+
+// Deliver sends to every accepting channel with deliveryID, and returns the
+// webhook channel's outcome. The other channels' failures are logged and
+// kept in their records.
+func (n *DeliveringFanoutNotifier) Deliver(ctx context.Context, notification Notification, deliveryID string) (int, error) {
+	done := make(chan error, 1)
+	go func() { done <- n.broadcast(ctx, notification, deliveryID, n.webhook) }()
+
+	status, err := n.webhook.send(ctx, notification, deliveryID)
+	if othersErr := <-done; othersErr != nil {
+		log.Printf("Failed to send notification to other channels: %v", othersErr)
+	}
+	return status.LastStatusCode, err
+}
+
+// limitedBuffer fails writes past its limit, stopping runaway templates
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered notification exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
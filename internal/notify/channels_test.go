@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver records the delivery IDs and bodies a channel endpoint got
+type receiver struct {
+	*httptest.Server
+	status int
+
+	mu          sync.Mutex
+	deliveryIDs []string
+	bodies      []string
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+	r := &receiver{status: status}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.deliveryIDs = append(r.deliveryIDs, req.Header.Get(DeliveryIDHeader))
+		r.bodies = append(r.bodies, string(body))
+		r.mu.Unlock()
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() ([]string, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.deliveryIDs...), append([]string(nil), r.bodies...)
+}
+
+func TestDeliveringFanoutNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "delivered", status: http.StatusAccepted},
+		{name: "webhook failing", status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := newReceiver(t, tt.status)
+			slack := newReceiver(t, http.StatusOK)
+			notifier, err := NewDeliveringFanoutNotifier([]ChannelConfig{
+				{Name: "slack", Type: ChannelSlack, URL: slack.URL},
+				{Name: "default", Type: ChannelWebhook, URL: webhook.URL + "/hooks/secret"},
+			}, "default", time.Second)
+			if err != nil {
+				t.Fatalf("NewDeliveringFanoutNotifier() error = %v", err)
+			}
+			if got := notifier.Target(); got != webhook.URL {
+				t.Errorf("Target() = %q, want %q without the path", got, webhook.URL)
+			}
+
+			notification := Notification{Subject: "Rule matched", Message: "Go 1.23", Severity: SeverityInfo, Counts: map[string]int{"matches": 1}}
+			status, err := notifier.Deliver(context.Background(), notification, "delivery-1")
+			if status != tt.status || (err != nil) != tt.wantErr {
+				t.Errorf("Deliver() = %d, %v; want %d, error %v", status, err, tt.status, tt.wantErr)
+			}
+
+			// Every channel gets the delivery ID, whatever the webhook answered
+			for name, r := range map[string]*receiver{"webhook": webhook, "slack": slack} {
+				ids, _ := r.received()
+				if len(ids) != 1 || ids[0] != "delivery-1" {
+					t.Errorf("%s got delivery IDs %q, want [delivery-1]", name, ids)
+				}
+			}
+			_, bodies := webhook.received()
+			var payload webhookPayload
+			if err := json.Unmarshal([]byte(bodies[0]), &payload); err != nil || payload.Counts["matches"] != 1 {
+				t.Errorf("webhook body %s, want the notification with its counts", bodies[0])
+			}
+
+			// Notify sends without a delivery ID
+			notifier.Notify(context.Background(), notification)
+			if ids, _ := webhook.received(); len(ids) != 2 || ids[1] != "" {
+				t.Errorf("webhook got delivery IDs %q after Notify, want none sent", ids)
+			}
+		})
+	}
+
+	if _, err := NewDeliveringFanoutNotifier([]ChannelConfig{{Name: "log", Type: ChannelLog}}, "default", time.Second); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("NewDeliveringFanoutNotifier() without the webhook error = %v, want ErrUnknownChannel", err)
+	}
+}
+
+func TestWebhookChannelRendersJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		message  string
+		wantErr  bool
+	}{
+		{name: "escaped", template: `{"summary": {{json .Message}}}`, message: `disk "data" full`},
+		{name: "unescaped plain value", template: `{"summary": "{{.Message}}"}`, message: "disk full"},
+		{name: "unescaped quote", template: `{"summary": "{{.Message}}"}`, message: `disk "data" full`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhook := newReceiver(t, http.StatusOK)
+			config := ChannelConfig{Name: "pager", Type: ChannelWebhook, URL: webhook.URL, Template: tt.template}
+			if err := config.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			notifier, err := NewFanoutNotifier([]ChannelConfig{config}, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = notifier.Notify(context.Background(), Notification{Subject: "Alert", Message: tt.message, Severity: SeverityCritical})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, want error %v", err, tt.wantErr)
+			}
+			ids, bodies := webhook.received()
+			if tt.wantErr {
+				if len(ids) != 0 {
+					t.Errorf("webhook got %q, want nothing posted", bodies)
+				}
+				if status := notifier.Channels()[0]; status.Failed != 1 || !strings.Contains(status.LastError, "invalid JSON") {
+					t.Errorf("channel record %+v, want the invalid JSON failure", status)
+				}
+				return
+			}
+			if len(bodies) != 1 || !json.Valid([]byte(bodies[0])) {
+				t.Errorf("webhook got %q, want one JSON body", bodies)
+			}
+		})
+	}
+}
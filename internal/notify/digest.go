@@ -0,0 +1,67 @@
+// internal/notify/digest.go
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// markdownEscaper backslash-escapes the characters that would start
+// formatting, links or block elements inside digest lines
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"(", `\(`, ")", `\)`, "#", `\#`, "<", `\<`, ">", `\>`, "|", `\|`, "~", `\~`,
+)
+
+// EscapeMarkdown makes text render literally in Markdown
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// RenderDigest renders a label's top posts since a time as a compact
+// Markdown digest. Body previews are collapsed to one line and cut to
+// previewChars characters (0 leaves them out).
+func RenderDigest(label string, since time.Time, posts []models.Post, previewChars int) (subject, body string) {
+	sinceText := since.UTC().Format("2006-01-02 15:04 UTC")
+	if len(posts) == 0 {
+		return fmt.Sprintf("Digest for %s: no activity", label),
+			fmt.Sprintf("No new posts in *%s* since %s.", EscapeMarkdown(label), sinceText)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Top %d posts in *%s* since %s\n", len(posts), EscapeMarkdown(label), sinceText)
+	for i, post := range posts {
+		link := post.Permalink
+		if link == "" {
+			link = post.URL
+		}
+		fmt.Fprintf(&b, "\n%d. [%s](%s) r/%s · %d points · u/%s\n",
+			i+1, EscapeMarkdown(post.Title), markdownURL(link),
+			EscapeMarkdown(post.Subreddit), post.Score, EscapeMarkdown(post.Author))
+		if preview := bodyPreview(post.Body, previewChars); preview != "" {
+			fmt.Fprintf(&b, "   %s\n", EscapeMarkdown(preview))
+		}
+	}
+	return fmt.Sprintf("Digest for %s: %d posts", label, len(posts)), b.String()
+}
+
+// bodyPreview collapses whitespace and cuts the body to max characters
+func bodyPreview(body string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	preview := strings.Join(strings.Fields(body), " ")
+	if utf8.RuneCountInString(preview) <= max {
+		return preview
+	}
+	return string([]rune(preview)[:max]) + "…"
+}
+
+// markdownURL escapes the characters that would end a Markdown link target
+func markdownURL(link string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E").Replace(link)
+}
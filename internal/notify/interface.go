@@ -0,0 +1,54 @@
+// internal/notify/interface.go
+package notify
+
+import "context"
+
+type NotifierInterface interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// DelivererInterface is a notifier whose sends are recorded as webhook
+// deliveries. deliveryID is sent in the DeliveryIDHeader so receivers can
+// drop replays of a delivery they already processed.
+type DelivererInterface interface {
+	NotifierInterface
+	Deliver(ctx context.Context, notification Notification, deliveryID string) (statusCode int, err error)
+	// Target names the receiver without credentials, for the delivery log
+	Target() string
+}
+
+// ChannelNotifierInterface is a notifier fanning out to named channels
+type ChannelNotifierInterface interface {
+	NotifierInterface
+	// NotifyChannel sends to one channel, ignoring its severity filter
+	NotifyChannel(ctx context.Context, name string, notification Notification) (ChannelStatus, error)
+	Channels() []ChannelStatus
+}
+
+// MailerInterface sends plain-text email
+type MailerInterface interface {
+	Send(ctx context.Context, to []string, subject, body string) error
+}
+
+// DeliveryIDHeader carries the delivery ID; a replay repeats the original's
+const DeliveryIDHeader = "X-Delivery-ID"
+
+// Severity levels for notifications
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Notification is a message about orchestrator state, e.g. a stale subreddit
+type Notification struct {
+	Subject   string `json:"subject"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
+	Subreddit string `json:"subreddit,omitempty"`
+	Channel   string `json:"channel,omitempty"` // Routing hint for the receiving webhook
+	// Error and Counts give channel templates the failure and the numbers
+	// behind the message, when it has them
+	Error  string         `json:"error,omitempty"`
+	Counts map[string]int `json:"counts,omitempty"`
+}
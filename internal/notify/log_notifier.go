@@ -0,0 +1,22 @@
+// internal/notify/log_notifier.go
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Ensure LogNotifier implements NotifierInterface
+var _ NotifierInterface = (*LogNotifier)(nil)
+
+// LogNotifier writes notifications to the process log; used when no webhook is configured
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("[%s] %s: %s", notification.Severity, notification.Subject, notification.Message)
+	return nil
+}
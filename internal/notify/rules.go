@@ -0,0 +1,120 @@
+// internal/notify/rules.go
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// RuleMatcher is a NotificationRule with its regexes compiled once per batch
+type RuleMatcher struct {
+	Rule       models.NotificationRule
+	subreddits map[string]bool
+	keywords   []string
+	titleRegex *regexp.Regexp
+	bodyRegex  *regexp.Regexp
+}
+
+// NewRuleMatcher compiles a rule; rules are validated on save, so an error
+// here means the stored document was edited by hand
+func NewRuleMatcher(rule models.NotificationRule) (*RuleMatcher, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	matcher := &RuleMatcher{Rule: rule}
+	if len(rule.Subreddits) > 0 {
+		matcher.subreddits = make(map[string]bool, len(rule.Subreddits))
+		for _, subreddit := range rule.Subreddits {
+			matcher.subreddits[strings.ToLower(subreddit)] = true
+		}
+	}
+	for _, keyword := range rule.Keywords {
+		matcher.keywords = append(matcher.keywords, strings.ToLower(strings.TrimSpace(keyword)))
+	}
+	if rule.TitleRegex != "" {
+		matcher.titleRegex = regexp.MustCompile(rule.TitleRegex)
+	}
+	if rule.BodyRegex != "" {
+		matcher.bodyRegex = regexp.MustCompile(rule.BodyRegex)
+	}
+
+	return matcher, nil
+}
+
+// Match returns the terms that matched the post; nil means no match. A post
+// matches when any keyword or regex condition does.
+func (m *RuleMatcher) Match(post models.Post) []string {
+	if m.subreddits != nil && !m.subreddits[strings.ToLower(post.Subreddit)] {
+		return nil
+	}
+
+	var terms []string
+	if len(m.keywords) > 0 {
+		text := strings.ToLower(post.Title + "\n" + post.Body)
+		for _, keyword := range m.keywords {
+			if strings.Contains(text, keyword) {
+				terms = append(terms, keyword)
+			}
+		}
+	}
+	if m.titleRegex != nil {
+		if match := m.titleRegex.FindString(post.Title); match != "" {
+			terms = append(terms, match)
+		}
+	}
+	if m.bodyRegex != nil {
+		if match := m.bodyRegex.FindString(post.Body); match != "" {
+			terms = append(terms, match)
+		}
+	}
+
+	return terms
+}
+
+// RuleMatch is one post matched by a rule
+type RuleMatch struct {
+	Post  models.Post
+	Terms []string
+}
+
+// maxMatchesPerMessage caps the posts listed in one rule notification
+const maxMatchesPerMessage = 10
+
+// FormatRuleNotification renders the matches of one rule as a single
+// notification, so a burst of matching posts produces one message
+func FormatRuleNotification(rule models.NotificationRule, matches []RuleMatch) Notification {
+	var message strings.Builder
+	for i, match := range matches {
+		if i == maxMatchesPerMessage {
+			fmt.Fprintf(&message, "...and %d more\n", len(matches)-maxMatchesPerMessage)
+			break
+		}
+		fmt.Fprintf(&message, "%s\n%s\nMatched: %s\n",
+			match.Post.Title, postLink(match.Post), strings.Join(match.Terms, ", "))
+	}
+
+	notification := Notification{
+		Subject:  fmt.Sprintf("Rule %q matched %d new posts", rule.Name, len(matches)),
+		Message:  strings.TrimSuffix(message.String(), "\n"),
+		Severity: SeverityInfo,
+		Channel:  rule.Channel,
+		Counts:   map[string]int{"matches": len(matches)},
+	}
+	if len(matches) == 1 {
+		notification.Subject = fmt.Sprintf("Rule %q matched a new post in r/%s", rule.Name, matches[0].Post.Subreddit)
+		notification.Subreddit = matches[0].Post.Subreddit
+	}
+	return notification
+}
+
+// postLink prefers the Reddit thread over the post's external URL
+func postLink(post models.Post) string {
+	if post.Permalink != "" {
+		return post.Permalink
+	}
+	return post.URL
+}
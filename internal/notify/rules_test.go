@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+
+	"reddit-orchestrator/internal/models"
+)
+
+func TestFormatRuleNotificationLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		post     models.Post
+		wantLink string
+	}{
+		{
+			name:     "permalink preferred",
+			post:     models.Post{Title: "Go", Subreddit: "golang", URL: "https://example.com/a", Permalink: "https://reddit.com/r/golang/comments/1abc/"},
+			wantLink: "https://reddit.com/r/golang/comments/1abc/",
+		},
+		{name: "external url without permalink", post: models.Post{Title: "Go", Subreddit: "golang", URL: "https://example.com/a"}, wantLink: "https://example.com/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := models.NotificationRule{Name: "go", Channel: "alerts"}
+			notification := FormatRuleNotification(rule, []RuleMatch{{Post: tt.post, Terms: []string{"go"}}})
+
+			lines := strings.Split(notification.Message, "\n")
+			if len(lines) != 3 || lines[1] != tt.wantLink {
+				t.Fatalf("message = %q, want the link %q on its second line", notification.Message, tt.wantLink)
+			}
+			if notification.Subreddit != "golang" || notification.Channel != "alerts" {
+				t.Errorf("notification = %+v, want subreddit golang on channel alerts", notification)
+			}
+		})
+	}
+}
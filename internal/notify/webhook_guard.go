@@ -0,0 +1,122 @@
+// internal/notify/webhook_guard.go
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedTarget means a webhook would reach a loopback, private,
+// link-local, unspecified or other special-purpose address
+var ErrBlockedTarget = errors.New("webhook target is not a public address")
+
+// WebhookGuard keeps webhooks configured through the API (notification rules,
+// digests) from reaching the orchestrator's own network, such as MongoDB,
+// cloud metadata endpoints or admin ports. Hosts in WEBHOOK_ALLOWED_HOSTS are
+// exempt. Names are checked on every connection, so a name resolving to a
+// blocked address later is still refused.
+type WebhookGuard struct {
+	allowed map[string]bool
+}
+
+func NewWebhookGuard(allowedHosts []string) *WebhookGuard {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		allowed[strings.ToLower(host)] = true
+	}
+	return &WebhookGuard{allowed: allowed}
+}
+
+// CheckURL rejects a webhook URL whose host is localhost or a blocked literal
+// address, so the mistake is reported when the URL is saved
+func (g *WebhookGuard) CheckURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid webhook URL")
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if g.allowed[host] {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrBlockedTarget, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && blockedIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedTarget, host)
+	}
+	return nil
+}
+
+// NewNotifier returns a WebhookNotifier for rawURL. Unless its host is
+// allowed, connections to blocked addresses fail with ErrBlockedTarget and
+// proxies from the environment are not used, as they would be dialed instead.
+func (g *WebhookGuard) NewNotifier(rawURL string, timeout time.Duration) *WebhookNotifier {
+	notifier := NewWebhookNotifier(rawURL, timeout)
+	if parsed, err := url.Parse(rawURL); err == nil && g.allowed[strings.ToLower(parsed.Hostname())] {
+		return notifier
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+				return fmt.Errorf("%w: %s", ErrBlockedTarget, host)
+			}
+			return nil
+		},
+	}
+	notifier.httpClient.Transport = &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+	return notifier
+}
+
+// blockedNetworks are the special-purpose ranges the net.IP predicates don't
+// cover, which reach a carrier's or the host's own network rather than the
+// internet
+var blockedNetworks = parseCIDRs(
+	"0.0.0.0/8",      // "this network"
+	"100.64.0.0/10",  // carrier-grade NAT, also used by some cloud VPCs
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, and the broadcast address
+	"64:ff9b:1::/48", // local-use NAT64
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// blockedIP reports whether ip is an address webhooks may not reach
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
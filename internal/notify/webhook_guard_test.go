@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookGuardCheckURL(t *testing.T) {
+	guard := NewWebhookGuard([]string{"hooks.internal", "10.0.0.7"})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{name: "public host", url: "https://hooks.example.com/x"},
+		{name: "public address", url: "http://93.184.216.34/x"},
+		{name: "localhost", url: "http://localhost:8080/", wantErr: ErrBlockedTarget},
+		{name: "localhost subdomain", url: "http://api.localhost/", wantErr: ErrBlockedTarget},
+		{name: "loopback", url: "http://127.0.0.1:27017/", wantErr: ErrBlockedTarget},
+		{name: "ipv6 loopback", url: "http://[::1]/", wantErr: ErrBlockedTarget},
+		{name: "private", url: "http://10.1.2.3/", wantErr: ErrBlockedTarget},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: ErrBlockedTarget},
+		{name: "unspecified", url: "http://0.0.0.0/", wantErr: ErrBlockedTarget},
+		{name: "this network", url: "http://0.1.2.3/", wantErr: ErrBlockedTarget},
+		{name: "carrier-grade nat", url: "http://100.100.100.200/", wantErr: ErrBlockedTarget},
+		{name: "ipv4-mapped carrier-grade nat", url: "http://[::ffff:100.64.0.1]/", wantErr: ErrBlockedTarget},
+		{name: "benchmarking", url: "http://198.19.0.1/", wantErr: ErrBlockedTarget},
+		{name: "reserved", url: "http://240.0.0.1/", wantErr: ErrBlockedTarget},
+		{name: "next to carrier-grade nat", url: "http://100.128.0.1/"},
+		{name: "allowed name", url: "http://HOOKS.internal/x"},
+		{name: "allowed private address", url: "http://10.0.0.7:9000/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := guard.CheckURL(tt.url)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("CheckURL(%q) error = %v", tt.url, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckURL(%q) error = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := guard.CheckURL("::not a url"); err == nil {
+		t.Error("CheckURL() accepted an unparseable URL")
+	}
+}
+
+func TestWebhookGuardNewNotifier(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	parsed, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		name     string
+		allowed  []string
+		wantErr  error
+		wantHits int32
+	}{
+		{name: "loopback refused", wantErr: ErrBlockedTarget},
+		{name: "loopback allowed", allowed: []string{parsed.Hostname()}, wantHits: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+			notifier := NewWebhookGuard(tt.allowed).NewNotifier(server.URL, 5*time.Second)
+
+			_, err := notifier.Deliver(context.Background(), Notification{Subject: "s", Message: "m"}, "delivery-1")
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Deliver() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Deliver() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("server got %d requests, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// internal/notify/webhook_notifier.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Ensure WebhookNotifier implements NotifierInterface and DelivererInterface
+var (
+	_ NotifierInterface  = (*WebhookNotifier)(nil)
+	_ DelivererInterface = (*WebhookNotifier)(nil)
+)
+
+// maxErrorBodyBytes caps how much of a non-2xx body is read into the error message
+const maxErrorBodyBytes = 4 << 10
+
+// WebhookNotifier POSTs notifications as JSON. The "text" field makes the
+// payload usable with Slack-compatible incoming webhooks.
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+type webhookPayload struct {
+	Text string `json:"text"`
+	Notification
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	_, err := n.Deliver(ctx, notification, "")
+	return err
+}
+
+// Target is the webhook's scheme and host; paths and queries of incoming
+// webhook URLs often embed their secret
+func (n *WebhookNotifier) Target() string {
+	parsed, err := url.Parse(n.url)
+	if err != nil || parsed.Host == "" {
+		return "webhook"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// Deliver sends a notification and returns the response status code (0 when
+// no response arrived). A non-empty deliveryID is sent in DeliveryIDHeader.
+func (n *WebhookNotifier) Deliver(ctx context.Context, notification Notification, deliveryID string) (int, error) {
+	payload, err := json.Marshal(webhookPayload{
+		Text:         fmt.Sprintf("*%s*\n%s", notification.Subject, notification.Message),
+		Notification: notification,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encoding notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("creating notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if deliveryID != "" {
+		req.Header.Set(DeliveryIDHeader, deliveryID)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return resp.StatusCode, fmt.Errorf("notification webhook error %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,62 @@
+// internal/processor/domain.go
+package processor
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// postDomain returns the registrable domain a post links to (see
+// NormalizeDomain), models.DomainSelf for self posts, whose URL is empty or
+// their own thread, and empty when the URL has no usable host
+func postDomain(raw, redditID string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return models.DomainSelf
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if id := strings.TrimPrefix(strings.ToLower(redditID), "t3_"); id != "" && isRedditHost(host) &&
+		strings.Contains(parsed.Path+"/", "/comments/"+id+"/") {
+		return models.DomainSelf
+	}
+	return NormalizeDomain(host)
+}
+
+// NormalizeDomain reduces a host name to its registrable domain (eTLD+1,
+// e.g. news.bbc.co.uk to bbc.co.uk) in lower case Unicode, so punycode and
+// Unicode spellings of a domain match. IP addresses and hosts that are a
+// public suffix themselves are kept whole. It is empty for invalid host names.
+func NormalizeDomain(host string) string {
+	host = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if host == "" {
+		return ""
+	}
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return ip.String()
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil || ascii == "" {
+		return ""
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(ascii)
+	if err != nil {
+		domain = ascii
+	}
+	unicode, err := idna.Lookup.ToUnicode(domain)
+	if err != nil {
+		return ""
+	}
+	return unicode
+}
@@ -2,9 +2,71 @@
 package processor
 
 import (
+	"time"
+
 	"reddit-orchestrator/internal/models"
 )
 
 type ProcessorInterface interface {
-	ProcessSubredditPosts(ingestionPosts []models.IngestionPost, subreddit string) []models.Post
+	ProcessSubredditPosts(ingestionPosts []models.IngestionPost, subreddit string, cfg *models.SubredditConfig) ([]models.Post, ProcessStats)
+	ProcessUserPosts(ingestionPosts []models.IngestionPost, cfg *models.UserConfig) ([]models.Post, ProcessStats)
+	PreviewSubredditPosts(ingestionPosts []models.IngestionPost, subreddit string, cfg *models.SubredditConfig) ([]models.Post, ProcessStats)
+	ReprocessPosts(posts []models.Post, cfg *models.SubredditConfig, applyFilters bool) ([]models.Post, ProcessStats)
+}
+
+// Rejection reasons reported in RejectionSummary
+const (
+	RejectEmptyID         = "empty_id"
+	RejectEmptyTitle      = "empty_title"
+	RejectEmptySubreddit  = "empty_subreddit"
+	RejectInvalidIDFormat = "invalid_id_format"
+	RejectNSFW            = "nsfw"
+	RejectSpoiler         = "spoiler"
+	RejectTooOld          = "too_old" // Older than the config's MaxPostAgeDays
+)
+
+// IsFilterRejection reports whether reason comes from a config filter rather
+// than from validating the payload
+func IsFilterRejection(reason string) bool {
+	return reason == RejectNSFW || reason == RejectSpoiler || reason == RejectTooOld
+}
+
+// RejectionSummary counts dropped posts by reason
+type RejectionSummary map[string]int
+
+// Total returns the number of rejected posts
+func (r RejectionSummary) Total() int {
+	total := 0
+	for _, count := range r {
+		total += count
+	}
+	return total
+}
+
+// ProcessStats describes what happened to a batch besides the kept posts
+type ProcessStats struct {
+	Rejections RejectionSummary
+	// RejectedSamples holds up to maxRejectedSamples post IDs per reason,
+	// only collected when rejection debugging is enabled
+	RejectedSamples map[string][]string
+	NSFWUnknown     int // Kept posts whose payload had no is_nsfw flag
+	Batch           BatchInfo
+	// Rejected lists every rejected post, only for PreviewSubredditPosts and
+	// ReprocessPosts
+	Rejected []RejectedPost
+}
+
+// RejectedPost is a post the processor dropped; RedditID is empty for
+// posts rejected for having none
+type RejectedPost struct {
+	RedditID string `json:"reddit_id"`
+	Reason   string `json:"reason"`
+}
+
+// BatchInfo describes the kept posts of a batch. Posts without a created_at
+// don't count towards the range, which is zero when none had one.
+type BatchInfo struct {
+	Count           int
+	OldestCreatedAt time.Time
+	NewestCreatedAt time.Time
 }
@@ -0,0 +1,57 @@
+// internal/processor/normalized_url.go
+package processor
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query params that only identify where a click came from
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"igshid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "ref": true,
+	"ref_src": true, "ref_url": true, "spm": true, "si": true,
+}
+
+// normalizeURL reduces a post's link to a key shared by every spelling of it:
+// scheme, www., default ports, fragments, tracking params and a trailing
+// slash are dropped and the remaining params sorted. It is empty for self
+// posts and links to Reddit itself, which are not external links.
+func normalizeURL(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if host == "" || isRedditHost(host) {
+		return ""
+	}
+	if port := parsed.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	query := parsed.Query()
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	normalized := host + strings.TrimRight(parsed.EscapedPath(), "/")
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
+
+// isRedditHost reports whether host serves Reddit threads or Reddit-hosted media
+func isRedditHost(host string) bool {
+	for _, domain := range []string{"reddit.com", "redd.it", "redditmedia.com"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "plain", raw: "https://example.com/a", want: "example.com/a"},
+		{name: "http and https match", raw: "http://example.com/a", want: "example.com/a"},
+		{name: "uppercase scheme", raw: "HTTPS://example.com/a", want: "example.com/a"},
+		{name: "www and host case", raw: "https://WWW.Example.com/a", want: "example.com/a"},
+		{name: "trailing dot in host", raw: "https://example.com./a", want: "example.com/a"},
+		{name: "path case kept", raw: "https://example.com/A", want: "example.com/A"},
+		{name: "trailing slash", raw: "https://example.com/a/", want: "example.com/a"},
+		{name: "bare host", raw: "https://example.com/", want: "example.com"},
+		{name: "fragment", raw: "https://example.com/a#comments", want: "example.com/a"},
+		{name: "default https port", raw: "https://example.com:443/a", want: "example.com/a"},
+		{name: "default http port", raw: "http://example.com:80/a", want: "example.com/a"},
+		{name: "other port kept", raw: "https://example.com:8080/a", want: "example.com:8080/a"},
+		{name: "surrounding space", raw: "  https://example.com/a  ", want: "example.com/a"},
+		{name: "escaped path kept", raw: "https://example.com/a%20b", want: "example.com/a%20b"},
+		{name: "utm params", raw: "https://example.com/a?utm_source=reddit&utm_medium=social", want: "example.com/a"},
+		{name: "utm params in any case", raw: "https://example.com/a?UTM_Campaign=x", want: "example.com/a"},
+		{name: "click ids", raw: "https://example.com/a?fbclid=1&gclid=2&msclkid=3", want: "example.com/a"},
+		{name: "ref and share ids", raw: "https://youtu.be/abc?si=xyz&ref=hn", want: "youtu.be/abc"},
+		{name: "params sorted", raw: "https://example.com/a?b=2&utm_source=x&a=1", want: "example.com/a?a=1&b=2"},
+		{name: "repeated param order kept", raw: "https://example.com/a?q=1&q=0", want: "example.com/a?q=1&q=0"},
+		{name: "empty query", raw: "https://example.com/a?", want: "example.com/a"},
+		{name: "reddit thread", raw: "https://old.reddit.com/r/golang/comments/abc/", want: ""},
+		{name: "reddit media", raw: "https://i.redd.it/abc.png", want: ""},
+		{name: "reddit lookalike", raw: "https://notreddit.com/a", want: "notreddit.com/a"},
+		{name: "relative permalink of a self post", raw: "/r/golang/comments/abc/", want: ""},
+		{name: "empty", raw: "", want: ""},
+		{name: "other scheme", raw: "ftp://example.com/a", want: ""},
+		{name: "mailto", raw: "mailto:someone@example.com", want: ""},
+		{name: "unparseable", raw: "https://exa mple.com/%zz", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeURL(tt.raw); got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessSubredditPostsSetsNormalizedURL(t *testing.T) {
+	spellings := []string{
+		"https://blog.example.com/go-1.23/",
+		"http://www.blog.example.com/go-1.23?utm_source=reddit",
+		"https://BLOG.example.com/go-1.23#top",
+	}
+	var posts []models.IngestionPost
+	for i, url := range spellings {
+		posts = append(posts, models.IngestionPost{ID: fmt.Sprintf("t3_%d", i), Title: "Go 1.23", URL: url, CreatedAt: testNow.Add(-time.Hour)})
+	}
+	posts = append(posts, models.IngestionPost{ID: "t3_self", Title: "Question", Body: "how?", URL: "https://www.reddit.com/r/golang/comments/self/question/", CreatedAt: testNow.Add(-time.Hour)})
+
+	processed, _ := newTestProcessor().ProcessSubredditPosts(posts, "golang", nil)
+	if len(processed) != len(posts) {
+		t.Fatalf("processed %d posts, want %d", len(processed), len(posts))
+	}
+	for _, post := range processed {
+		want := "blog.example.com/go-1.23"
+		if post.RedditID == "t3_self" {
+			want = ""
+		}
+		if post.NormalizedURL != want {
+			t.Errorf("%s NormalizedURL = %q, want %q", post.RedditID, post.NormalizedURL, want)
+		}
+	}
+}
@@ -2,34 +2,116 @@
 package processor
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/features"
 	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/privacy"
 )
 
 // Ensure Processor implements ProcessorInterface
 var _ ProcessorInterface = (*Processor)(nil)
 
-type Processor struct{}
+// maxRejectedSamples caps the post IDs kept per rejection reason
+const maxRejectedSamples = 10
 
-func NewProcessor() *Processor {
-	return &Processor{}
+const redditBaseURL = "https://reddit.com"
+
+type Processor struct {
+	// debugRejections collects sample IDs of rejected posts; nil disables it
+	debugRejections *features.Flag
+	// authors applies PRIVACY_MODE to author names; nil keeps them as received
+	authors *privacy.Authors
+	// clock stamps processed posts and computes the max_post_age cutoff
+	clock clock.Clock
+}
+
+func NewProcessor(debugRejections *features.Flag, authors *privacy.Authors, clk clock.Clock) *Processor {
+	return &Processor{debugRejections: debugRejections, authors: authors, clock: clk}
+}
+
+// ProcessSubredditPosts cleans and validates posts from the ingestion API and
+// returns them oldest first. cfg may be nil for subreddits without a stored configuration.
+func (p *Processor) ProcessSubredditPosts(ingestionPosts []models.IngestionPost, subreddit string, cfg *models.SubredditConfig) ([]models.Post, ProcessStats) {
+	// Use the subreddit we're monitoring
+	return p.processPosts(ingestionPosts, cfg, false, func(models.IngestionPost) string { return subreddit })
+}
+
+// PreviewSubredditPosts processes posts like ProcessSubredditPosts and also
+// lists every rejected post with its reason, for trying a config before saving it
+func (p *Processor) PreviewSubredditPosts(ingestionPosts []models.IngestionPost, subreddit string, cfg *models.SubredditConfig) ([]models.Post, ProcessStats) {
+	return p.processPosts(ingestionPosts, cfg, true, func(models.IngestionPost) string { return subreddit })
+}
+
+// ProcessUserPosts cleans posts fetched for a monitored user. Posts span
+// subreddits, so each keeps the subreddit from its own payload. cfg may be nil,
+// which applies no filters.
+func (p *Processor) ProcessUserPosts(ingestionPosts []models.IngestionPost, cfg *models.UserConfig) ([]models.Post, ProcessStats) {
+	var filters *models.SubredditConfig
+	if cfg != nil {
+		filters = &models.SubredditConfig{SkipNSFW: cfg.SkipNSFW, SkipSpoilers: cfg.SkipSpoilers}
+	}
+	return p.processPosts(ingestionPosts, filters, false, func(post models.IngestionPost) string {
+		return strings.TrimSpace(post.Subreddit)
+	})
 }
 
-// ProcessSubredditPosts cleans and validates posts from the ingestion API
-func (p *Processor) ProcessSubredditPosts(ingestionPosts []models.IngestionPost, subreddit string) []models.Post {
+func (p *Processor) processPosts(ingestionPosts []models.IngestionPost, cfg *models.SubredditConfig, listRejected bool, subredditOf func(models.IngestionPost) string) ([]models.Post, ProcessStats) {
 	processed := make([]models.Post, 0, len(ingestionPosts))
+	stats := ProcessStats{Rejections: RejectionSummary{}}
+	if p.debugRejections.Enabled() {
+		stats.RejectedSamples = make(map[string][]string)
+	}
+	reject := func(reason, redditID string) {
+		stats.Rejections[reason]++
+		if stats.RejectedSamples != nil && redditID != "" && len(stats.RejectedSamples[reason]) < maxRejectedSamples {
+			stats.RejectedSamples[reason] = append(stats.RejectedSamples[reason], redditID)
+		}
+		if listRejected {
+			stats.Rejected = append(stats.Rejected, RejectedPost{RedditID: redditID, Reason: reason})
+		}
+	}
+
+	var tagRules []models.TagRule
+	var skipNSFW, skipSpoilers bool
+	var oldest time.Time
+	if cfg != nil {
+		tagRules = cfg.TagRules
+		skipNSFW = cfg.SkipNSFW
+		skipSpoilers = cfg.SkipSpoilers
+		// The fetch is already bounded on first runs; this catches APIs
+		// that ignore since_timestamp
+		if cfg.MaxPostAgeDays > 0 {
+			oldest = p.clock.Now().UTC().Add(-cfg.MaxPostAge())
+		}
+	}
+	tagger := NewTagger(tagRules)
 	
 	for _, ingestionPost := range ingestionPosts {
 		redditID := strings.TrimSpace(ingestionPost.ID)
 		title := strings.TrimSpace(ingestionPost.Title)
 		
-		if redditID == "" || title == "" {
+		if redditID == "" {
+			reject(RejectEmptyID, "")
+			continue
+		}
+		if title == "" {
+			reject(RejectEmptyTitle, redditID)
 			continue
 		}
 
 		if len(redditID) < 3 || strings.Contains(redditID, " ") {
+			reject(RejectInvalidIDFormat, redditID)
+			continue
+		}
+
+		subreddit := subredditOf(ingestionPost)
+		if subreddit == "" {
+			reject(RejectEmptySubreddit, redditID)
 			continue
 		}
 
@@ -37,22 +119,93 @@ func (p *Processor) ProcessSubredditPosts(ingestionPosts []models.IngestionPost,
 			RedditID:   redditID,
 			Title:      title,
 			Body:       strings.TrimSpace(ingestionPost.Body),
-			Author:     strings.TrimSpace(ingestionPost.Author),
+			Author:     p.authors.Apply(ingestionPost.Author),
 			Score:      ingestionPost.Score,
-			Subreddit:  subreddit, // Use the subreddit we're monitoring
+			Subreddit:  subreddit,
 			URL:        strings.TrimSpace(ingestionPost.URL),
+			Permalink:  permalink(ingestionPost.Permalink, subreddit, redditID),
 			Flair:      strings.TrimSpace(ingestionPost.Flair),
-			CreatedAt:  ingestionPost.CreatedAt,
-			InsertedAt: time.Now(),
-			UpdatedAt:  time.Now(),
+			CreatedAt:  ingestionPost.CreatedAt.UTC(),
+			InsertedAt: p.clock.Now().UTC(),
+			UpdatedAt:  p.clock.Now().UTC(),
 		}
 
-		if processedPost.RedditID == "" || processedPost.Title == "" {
+		processedPost.NormalizedURL = normalizeURL(processedPost.URL)
+		processedPost.Domain = postDomain(processedPost.URL, redditID)
+
+		// A missing flag is treated as SFW but marked so coverage can be audited
+		if ingestionPost.IsNSFW != nil {
+			processedPost.IsNSFW = *ingestionPost.IsNSFW
+		} else {
+			processedPost.NSFWUnknown = true
+		}
+		if ingestionPost.Spoiler != nil {
+			processedPost.Spoiler = *ingestionPost.Spoiler
+		}
+
+		// Posts without a timestamp can't be aged and are kept
+		if !oldest.IsZero() && !processedPost.CreatedAt.IsZero() && processedPost.CreatedAt.Before(oldest) {
+			reject(RejectTooOld, redditID)
+			continue
+		}
+
+		if skipNSFW && processedPost.IsNSFW {
+			reject(RejectNSFW, redditID)
+			continue
+		}
+		if skipSpoilers && processedPost.Spoiler {
+			reject(RejectSpoiler, redditID)
 			continue
 		}
+		if processedPost.NSFWUnknown {
+			stats.NSFWUnknown++
+		}
+
+		processedPost.Tags = tagger.Tags(&processedPost)
 
 		processed = append(processed, processedPost)
 	}
 
-	return processed
-}
\ No newline at end of file
+	// Oldest first; posts created at the same time keep their ingestion order
+	sort.SliceStable(processed, func(i, j int) bool {
+		return processed[i].CreatedAt.Before(processed[j].CreatedAt)
+	})
+	stats.Batch = batchInfo(processed)
+
+	return processed, stats
+}
+
+// batchInfo summarizes posts sorted by created_at, skipping zero timestamps
+func batchInfo(posts []models.Post) BatchInfo {
+	info := BatchInfo{Count: len(posts)}
+	for _, post := range posts {
+		if post.CreatedAt.IsZero() {
+			continue
+		}
+		if info.OldestCreatedAt.IsZero() {
+			info.OldestCreatedAt = post.CreatedAt
+		}
+		info.NewestCreatedAt = post.CreatedAt
+	}
+	return info
+}
+
+// permalink returns the payload's thread URL, made absolute, or derives the
+// canonical one from subreddit and ID. It is empty when the subreddit is
+// unknown or the ID without its t3_ prefix is not a plain base36 ID, so we
+// never link to a made-up thread.
+func permalink(payloadPermalink, subreddit, redditID string) string {
+	payloadPermalink = strings.TrimSpace(payloadPermalink)
+	if strings.HasPrefix(payloadPermalink, "https://") || strings.HasPrefix(payloadPermalink, "http://") {
+		return payloadPermalink
+	}
+	if strings.HasPrefix(payloadPermalink, "/r/") {
+		return redditBaseURL + payloadPermalink
+	}
+
+	id := strings.TrimPrefix(redditID, "t3_")
+	if subreddit == "" || id == "" || strings.TrimLeft(strings.ToLower(id), "0123456789abcdefghijklmnopqrstuvwxyz") != "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/r/%s/comments/%s/", redditBaseURL, subreddit, id)
+}
@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// benchSizes are the batch sizes the processor benchmarks run at
+var benchSizes = []int{100, 1000, 10000}
+
+// benchConfig filters NSFW posts and old posts and tags by keyword and regex
+var benchConfig = &models.SubredditConfig{
+	SubredditName:  "golang",
+	SkipNSFW:       true,
+	MaxPostAgeDays: 7,
+	TagRules: []models.TagRule{
+		{Tag: "release", AnyOfKeywords: []string{"released", "changelog"}},
+		{Tag: "question", TitleRegex: `(?i)^(how|why|what)\b`},
+	},
+}
+
+// fixturePosts returns n posts shaped like a real listing: a mix of self,
+// external and Reddit-hosted links, tracking params, missing NSFW flags and
+// some posts older than benchConfig keeps
+func fixturePosts(n int) []models.IngestionPost {
+	posts := make([]models.IngestionPost, n)
+	for i := range posts {
+		post := models.IngestionPost{
+			ID:        fmt.Sprintf("t3_%06x", i),
+			Title:     fmt.Sprintf("  How does post %d compare to the released changelog?  ", i),
+			Body:      "Some body text that goes on for a while, as self posts tend to do.",
+			Author:    fmt.Sprintf("author%d", i%50),
+			Score:     i % 500,
+			CreatedAt: testNow.Add(-time.Duration(i%240) * time.Hour),
+			Flair:     "Discussion",
+			IsNSFW:    boolPtr(i%20 == 0),
+		}
+		switch i % 4 {
+		case 0:
+			post.URL = fmt.Sprintf("https://www.reddit.com/r/golang/comments/%06x/post/", i)
+		case 1:
+			post.URL = fmt.Sprintf("https://blog.example.co.uk/posts/%d?utm_source=reddit&ref=hn", i)
+		case 2:
+			post.URL = "https://i.redd.it/abc.png"
+		default:
+			post.IsNSFW = nil
+		}
+		posts[i] = post
+	}
+	return posts
+}
+
+func BenchmarkProcessSubredditPosts(b *testing.B) {
+	for _, size := range benchSizes {
+		posts := fixturePosts(size)
+		for _, bench := range []struct {
+			name string
+			cfg  *models.SubredditConfig
+		}{
+			{name: "no filters", cfg: nil},
+			{name: "filters", cfg: benchConfig},
+		} {
+			b.Run(fmt.Sprintf("%d/%s", size, bench.name), func(b *testing.B) {
+				p := newTestProcessor()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					p.ProcessSubredditPosts(posts, "golang", bench.cfg)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkNormalization covers the per-post URL, domain and permalink stages
+func BenchmarkNormalization(b *testing.B) {
+	posts := fixturePosts(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		post := posts[i%len(posts)]
+		normalizeURL(post.URL)
+		postDomain(post.URL, post.ID)
+		permalink(post.Permalink, "golang", post.ID)
+	}
+}
+
+func BenchmarkTagger(b *testing.B) {
+	tagger := NewTagger(benchConfig.TagRules)
+	posts, _ := newTestProcessor().ProcessSubredditPosts(fixturePosts(100), "golang", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tagger.Tags(&posts[i%len(posts)])
+	}
+}
+
+// TestProcessSubredditPostsAllocations fails when the processor's
+// allocations per post grow, e.g. from an allocation added to a per-post
+// step. Bounds sit a little above the measured cost (7 and 9 per post), so
+// raise them only for a deliberate change.
+func TestProcessSubredditPostsAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector changes allocation counts")
+	}
+	posts := fixturePosts(1000)
+
+	tests := []struct {
+		name             string
+		cfg              *models.SubredditConfig
+		maxAllocsPerPost float64
+	}{
+		{name: "no filters", cfg: nil, maxAllocsPerPost: 8},
+		{name: "filters", cfg: benchConfig, maxAllocsPerPost: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProcessor()
+			allocs := testing.AllocsPerRun(5, func() {
+				p.ProcessSubredditPosts(posts, "golang", tt.cfg)
+			})
+			if perPost := allocs / float64(len(posts)); perPost > tt.maxAllocsPerPost {
+				t.Errorf("%.2f allocations per post, want at most %v", perPost, tt.maxAllocsPerPost)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package processor
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestProcessor() *Processor {
+	return NewProcessor(nil, nil, clocktest.NewFake(testNow))
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+// userPosts returns one SFW, one NSFW and one spoiler post across two subreddits
+func userPosts() []models.IngestionPost {
+	return []models.IngestionPost{
+		{ID: "t3_sfw", Title: "plain", Subreddit: "golang", CreatedAt: testNow.Add(-3 * time.Hour), IsNSFW: boolPtr(false)},
+		{ID: "t3_nsfw", Title: "nsfw", Subreddit: "pics", CreatedAt: testNow.Add(-2 * time.Hour), IsNSFW: boolPtr(true)},
+		{ID: "t3_spoil", Title: "spoiler", Subreddit: "golang", CreatedAt: testNow.Add(-time.Hour), IsNSFW: boolPtr(false), Spoiler: boolPtr(true)},
+	}
+}
+
+func TestProcessUserPostsFilters(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            *models.UserConfig
+		wantIDs        []string
+		wantRejections RejectionSummary
+	}{
+		{name: "no config", cfg: nil, wantIDs: []string{"t3_sfw", "t3_nsfw", "t3_spoil"}, wantRejections: RejectionSummary{}},
+		{name: "no filters", cfg: &models.UserConfig{Username: "someone"}, wantIDs: []string{"t3_sfw", "t3_nsfw", "t3_spoil"}, wantRejections: RejectionSummary{}},
+		{name: "skip nsfw", cfg: &models.UserConfig{Username: "someone", SkipNSFW: true}, wantIDs: []string{"t3_sfw", "t3_spoil"}, wantRejections: RejectionSummary{RejectNSFW: 1}},
+		{name: "skip spoilers", cfg: &models.UserConfig{Username: "someone", SkipSpoilers: true}, wantIDs: []string{"t3_sfw", "t3_nsfw"}, wantRejections: RejectionSummary{RejectSpoiler: 1}},
+		{
+			name:           "skip both",
+			cfg:            &models.UserConfig{Username: "someone", SkipNSFW: true, SkipSpoilers: true},
+			wantIDs:        []string{"t3_sfw"},
+			wantRejections: RejectionSummary{RejectNSFW: 1, RejectSpoiler: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, stats := newTestProcessor().ProcessUserPosts(userPosts(), tt.cfg)
+
+			if len(posts) != len(tt.wantIDs) {
+				t.Fatalf("got %d posts, want %v", len(posts), tt.wantIDs)
+			}
+			for i, post := range posts {
+				if post.RedditID != tt.wantIDs[i] {
+					t.Errorf("post %d = %s, want %s", i, post.RedditID, tt.wantIDs[i])
+				}
+			}
+			if len(stats.Rejections) != len(tt.wantRejections) {
+				t.Fatalf("rejections = %v, want %v", stats.Rejections, tt.wantRejections)
+			}
+			for reason, count := range tt.wantRejections {
+				if stats.Rejections[reason] != count {
+					t.Errorf("rejections[%s] = %d, want %d", reason, stats.Rejections[reason], count)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessUserPostsKeepsPayloadSubreddit(t *testing.T) {
+	posts, _ := newTestProcessor().ProcessUserPosts(userPosts(), nil)
+
+	want := map[string]string{"t3_sfw": "golang", "t3_nsfw": "pics", "t3_spoil": "golang"}
+	for _, post := range posts {
+		if post.Subreddit != want[post.RedditID] {
+			t.Errorf("%s subreddit = %q, want %q", post.RedditID, post.Subreddit, want[post.RedditID])
+		}
+	}
+}
+
+func TestPermalink(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		subreddit string
+		redditID  string
+		want      string
+	}{
+		{name: "prefixed id", subreddit: "golang", redditID: "t3_1abc", want: "https://reddit.com/r/golang/comments/1abc/"},
+		{name: "bare id", subreddit: "golang", redditID: "1abc", want: "https://reddit.com/r/golang/comments/1abc/"},
+		{name: "absolute payload permalink", payload: "https://old.reddit.com/r/golang/comments/1abc/x/", subreddit: "golang", redditID: "t3_1abc", want: "https://old.reddit.com/r/golang/comments/1abc/x/"},
+		{name: "relative payload permalink", payload: " /r/golang/comments/1abc/x/ ", subreddit: "golang", redditID: "t3_1abc", want: "https://reddit.com/r/golang/comments/1abc/x/"},
+		{name: "empty id", subreddit: "golang", redditID: "", want: ""},
+		{name: "prefix only", subreddit: "golang", redditID: "t3_", want: ""},
+		{name: "comment id prefix", subreddit: "golang", redditID: "t1_1abc", want: ""},
+		{name: "path in id", subreddit: "golang", redditID: "t3_1abc/../x", want: ""},
+		{name: "unknown subreddit", subreddit: "", redditID: "t3_1abc", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permalink(tt.payload, tt.subreddit, tt.redditID); got != tt.want {
+				t.Errorf("permalink(%q, %q, %q) = %q, want %q", tt.payload, tt.subreddit, tt.redditID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessSubredditPostsSetsPermalink(t *testing.T) {
+	posts, _ := newTestProcessor().ProcessSubredditPosts([]models.IngestionPost{
+		{ID: "t3_1abc", Title: "plain", URL: "https://example.com/article", CreatedAt: testNow.Add(-time.Hour), IsNSFW: boolPtr(false)},
+	}, "golang", nil)
+
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	if want := "https://reddit.com/r/golang/comments/1abc/"; posts[0].Permalink != want {
+		t.Errorf("Permalink = %q, want %q", posts[0].Permalink, want)
+	}
+	if posts[0].URL != "https://example.com/article" {
+		t.Errorf("URL = %q, want the external article kept", posts[0].URL)
+	}
+}
+
+func TestReprocessPostsReplacesRuleTags(t *testing.T) {
+	stored := models.Post{RedditID: "t3_a", Title: "go 1.23 security fix", Subreddit: "golang", CreatedAt: testNow,
+		Tags: []string{"release", models.TagBurstAuthor}}
+	cfg := &models.SubredditConfig{SubredditName: "golang", TagRules: []models.TagRule{{Tag: "security", AnyOfKeywords: []string{"security"}}}}
+
+	changed, _ := newTestProcessor().ReprocessPosts([]models.Post{stored}, cfg, false)
+	if len(changed) != 1 {
+		t.Fatalf("ReprocessPosts() changed %d posts, want 1", len(changed))
+	}
+	// The release rule is gone; the anomaly task's tag stays
+	if want := []string{"security", models.TagBurstAuthor}; !slices.Equal(changed[0].Tags, want) {
+		t.Errorf("tags = %v, want %v", changed[0].Tags, want)
+	}
+}
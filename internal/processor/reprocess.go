@@ -0,0 +1,99 @@
+// internal/processor/reprocess.go
+package processor
+
+import (
+	"slices"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ReprocessPosts runs stored posts through the re-applicable stages again:
+// normalization (including the PRIVACY_MODE author handling), permalink
+// derivation and tagging. The NSFW, spoiler and age filters only run with
+// applyFilters; posts they reject are listed in stats.Rejected. It returns the posts whose normalized fields changed, with
+// everything else (ID, score, extras, timestamps) as stored. The current tag
+// rules' tags replace the stored ones, except for anomaly tags such as
+// burst-author, which are kept.
+func (p *Processor) ReprocessPosts(posts []models.Post, cfg *models.SubredditConfig, applyFilters bool) ([]models.Post, ProcessStats) {
+	reprocessCfg := &models.SubredditConfig{}
+	if cfg != nil {
+		copied := *cfg
+		if !applyFilters {
+			copied.SkipNSFW, copied.SkipSpoilers, copied.MaxPostAgeDays = false, false, 0
+		}
+		reprocessCfg = &copied
+	}
+
+	ingestionPosts := make([]models.IngestionPost, 0, len(posts))
+	stored := make(map[string]models.Post, len(posts))
+	for _, post := range posts {
+		ingestionPosts = append(ingestionPosts, IngestionPostOf(post))
+		stored[post.RedditID] = post
+	}
+
+	processed, stats := p.processPosts(ingestionPosts, reprocessCfg, true, func(post models.IngestionPost) string {
+		return post.Subreddit
+	})
+
+	var changed []models.Post
+	for _, post := range processed {
+		original, ok := stored[post.RedditID]
+		if !ok {
+			continue // The ID was trimmed; upserts have always stored it trimmed
+		}
+
+		tags := models.KeepAnomalyTags(post.Tags, original.Tags)
+
+		updated := original
+		updated.Title = post.Title
+		updated.Body = post.Body
+		updated.Author = post.Author
+		updated.URL = post.URL
+		updated.Permalink = post.Permalink
+		updated.NormalizedURL = post.NormalizedURL
+		updated.Domain = post.Domain
+		updated.Flair = post.Flair
+		updated.Tags = tags
+		if normalizedFieldsChanged(original, updated) {
+			changed = append(changed, updated)
+		}
+	}
+	return changed, stats
+}
+
+// normalizedFieldsChanged compares the fields ReprocessPosts rewrites; tag
+// order does not matter
+func normalizedFieldsChanged(a, b models.Post) bool {
+	if a.Title != b.Title || a.Body != b.Body || a.Author != b.Author || a.URL != b.URL ||
+		a.Permalink != b.Permalink || a.NormalizedURL != b.NormalizedURL || a.Domain != b.Domain || a.Flair != b.Flair ||
+		len(a.Tags) != len(b.Tags) {
+		return true
+	}
+	for _, tag := range a.Tags {
+		if !slices.Contains(b.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IngestionPostOf turns a stored post back into the payload it came from
+func IngestionPostOf(post models.Post) models.IngestionPost {
+	ingestionPost := models.IngestionPost{
+		ID:        post.RedditID,
+		Title:     post.Title,
+		Body:      post.Body,
+		Author:    post.Author,
+		Score:     post.Score,
+		CreatedAt: post.CreatedAt,
+		Subreddit: post.Subreddit,
+		Flair:     post.Flair,
+		URL:       post.URL,
+		Permalink: post.Permalink,
+		Spoiler:   &post.Spoiler,
+	}
+	if !post.NSFWUnknown {
+		ingestionPost.IsNSFW = &post.IsNSFW
+	}
+	return ingestionPost
+}
@@ -0,0 +1,80 @@
+// internal/processor/tagger.go
+package processor
+
+import (
+	"regexp"
+	"strings"
+
+	"reddit-orchestrator/internal/models"
+)
+
+type compiledTagRule struct {
+	tag        string
+	keywords   []string
+	titleRegex *regexp.Regexp
+}
+
+// Tagger evaluates per-subreddit tag rules against posts
+type Tagger struct {
+	rules []compiledTagRule
+}
+
+// NewTagger compiles the given rules. Rules that fail validation are skipped so
+// that a legacy config saved before validation existed can't break a scrape.
+func NewTagger(rules []models.TagRule) *Tagger {
+	tagger := &Tagger{rules: make([]compiledTagRule, 0, len(rules))}
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			continue
+		}
+
+		compiled := compiledTagRule{tag: strings.TrimSpace(rule.Tag)}
+		for _, keyword := range rule.AnyOfKeywords {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				compiled.keywords = append(compiled.keywords, keyword)
+			}
+		}
+		if rule.TitleRegex != "" {
+			compiled.titleRegex = regexp.MustCompile(rule.TitleRegex)
+		}
+
+		tagger.rules = append(tagger.rules, compiled)
+	}
+
+	return tagger
+}
+
+// Tags returns every tag whose rule matches the post, without duplicates
+func (t *Tagger) Tags(post *models.Post) []string {
+	if len(t.rules) == 0 {
+		return nil
+	}
+
+	title := strings.ToLower(post.Title)
+	body := strings.ToLower(post.Body)
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, rule := range t.rules {
+		if seen[rule.tag] || !rule.matches(post.Title, title, body) {
+			continue
+		}
+		seen[rule.tag] = true
+		tags = append(tags, rule.tag)
+	}
+
+	return tags
+}
+
+func (r compiledTagRule) matches(rawTitle, title, body string) bool {
+	if r.titleRegex != nil && r.titleRegex.MatchString(rawTitle) {
+		return true
+	}
+	for _, keyword := range r.keywords {
+		if strings.Contains(title, keyword) || strings.Contains(body, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+// internal/query/query.go
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bounds on user supplied filter values
+const (
+	MaxValueLength = 256
+	MaxTextLength  = 200
+	MaxListLength  = 100
+	MaxPathDepth   = 8
+	// MaxScore bounds score filters; Reddit scores stay far below it
+	MaxScore = 1_000_000_000
+)
+
+var (
+	// fieldPattern allows plain dot paths, so a field can't name an operator
+	fieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+	// operatorPattern matches values shaped like a Mongo operator ("$where")
+	operatorPattern = regexp.MustCompile(`^\$[A-Za-z]`)
+
+	// earliest and latest bound time filters; epoch 0 is how callers ask for
+	// "any time"
+	earliest = time.Unix(0, 0).UTC()
+	latest   = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// ErrInvalid is wrapped by every error a Condition reports
+var ErrInvalid = errors.New("invalid filter")
+
+// Condition is one term of a Mongo filter built from user input by this
+// package's functions. Values are always matched literally: strings never
+// become operators or regex patterns. An invalid value makes the condition
+// carry its error, which Build returns.
+type Condition struct {
+	filter bson.M
+	err    error
+}
+
+func invalid(format string, args ...interface{}) Condition {
+	return Condition{err: fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, args...))}
+}
+
+// Err reports why the condition is invalid, or nil
+func (c Condition) Err() error {
+	return c.err
+}
+
+// Trusted wraps a filter built by code from constants, e.g. a status match.
+// It must not contain user input.
+func Trusted(filter bson.M) Condition {
+	return Condition{filter: filter}
+}
+
+// Equals matches field to value literally
+func Equals(field, value string) Condition {
+	if err := checkField(field); err != nil {
+		return Condition{err: err}
+	}
+	if err := checkValue(field, value, MaxValueLength); err != nil {
+		return Condition{err: err}
+	}
+	return Condition{filter: bson.M{field: value}}
+}
+
+// In matches field to any of values; one value is matched with Equals
+func In(field string, values ...string) Condition {
+	if len(values) == 1 {
+		return Equals(field, values[0])
+	}
+	list, err := checkList(field, values)
+	if err != nil {
+		return Condition{err: err}
+	}
+	return Condition{filter: bson.M{field: bson.M{"$in": list}}}
+}
+
+// ContainsAll matches array field holding every one of values
+func ContainsAll(field string, values ...string) Condition {
+	list, err := checkList(field, values)
+	if err != nil {
+		return Condition{err: err}
+	}
+	return Condition{filter: bson.M{field: bson.M{"$all": list}}}
+}
+
+// BySubreddits matches posts in any of names
+func BySubreddits(names ...string) Condition {
+	return In("subreddit", names...)
+}
+
+// ByAuthor matches posts by author, already hashed when PRIVACY_MODE=hash
+func ByAuthor(author string) Condition {
+	return Equals("author", author)
+}
+
+// ByFlair matches posts with the given flair text
+func ByFlair(flair string) Condition {
+	return Equals("flair", flair)
+}
+
+// ByDomain matches posts linking to a normalized domain
+func ByDomain(domain string) Condition {
+	return Equals("domain", domain)
+}
+
+// ByImportBatch matches the posts of one snapshot import
+func ByImportBatch(batch string) Condition {
+	return Equals("import_batch", batch)
+}
+
+// WithTags matches posts carrying every tag
+func WithTags(tags ...string) Condition {
+	return ContainsAll("tags", tags...)
+}
+
+// ScoreAtLeast matches posts scoring min or more
+func ScoreAtLeast(min int) Condition {
+	if min < -MaxScore || min > MaxScore {
+		return invalid("score must be between %d and %d", -MaxScore, MaxScore)
+	}
+	return Condition{filter: bson.M{"score": bson.M{"$gte": min}}}
+}
+
+// AtOrAfter matches time field at or after since
+func AtOrAfter(field string, since time.Time) Condition {
+	return Between(field, since, time.Time{})
+}
+
+// Between matches time field in [from, to); zero ends are open and two zero
+// ends match everything
+func Between(field string, from, to time.Time) Condition {
+	bounds, err := timeBounds(field, from, to)
+	if err != nil {
+		return Condition{err: err}
+	}
+	if len(bounds) == 0 {
+		return Condition{}
+	}
+	return Condition{filter: bson.M{field: bounds}}
+}
+
+// CreatedBetween matches posts created in [from, to)
+func CreatedBetween(from, to time.Time) Condition {
+	return Between("created_at", from, to)
+}
+
+// CreatedOrUpdatedBetween matches posts created or updated in [from, to)
+func CreatedOrUpdatedBetween(from, to time.Time) Condition {
+	bounds, err := timeBounds("created_at", from, to)
+	if err != nil {
+		return Condition{err: err}
+	}
+	if len(bounds) == 0 {
+		return Condition{}
+	}
+	return Condition{filter: bson.M{"$or": bson.A{
+		bson.M{"created_at": bounds},
+		bson.M{"updated_at": bounds},
+	}}}
+}
+
+// TextSearch matches text case-insensitively as a literal substring of any
+// of fields (title and body when none are given). The text is escaped, so
+// regex syntax in it can't build an expensive pattern.
+func TextSearch(text string, fields ...string) Condition {
+	if len(fields) == 0 {
+		fields = []string{"title", "body"}
+	}
+	if err := checkValue("text", text, MaxTextLength); err != nil {
+		return Condition{err: err}
+	}
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}
+	or := make(bson.A, 0, len(fields))
+	for _, field := range fields {
+		if err := checkField(field); err != nil {
+			return Condition{err: err}
+		}
+		or = append(or, bson.M{field: pattern})
+	}
+	if len(or) == 1 {
+		return Condition{filter: or[0].(bson.M)}
+	}
+	return Condition{filter: bson.M{"$or": or}}
+}
+
+// HasPrefix matches string field starting with any of prefixes, each as an
+// escaped anchored regex an index on field can bound
+func HasPrefix(field string, prefixes ...string) Condition {
+	list, err := checkList(field, prefixes)
+	if err != nil {
+		return Condition{err: err}
+	}
+	patterns := make(bson.A, 0, len(list))
+	for _, prefix := range list {
+		patterns = append(patterns, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix.(string))})
+	}
+	return Condition{filter: bson.M{field: bson.M{"$in": patterns}}}
+}
+
+// ExtrasEquals matches the enrichment field at path below extras (e.g.
+// "label.name") to value, which also matches its number or boolean form
+func ExtrasEquals(path, value string) Condition {
+	if err := CheckPath(path); err != nil {
+		return Condition{err: err}
+	}
+	field := "extras." + path
+	if err := checkValue(field, value, MaxValueLength); err != nil {
+		return Condition{err: err}
+	}
+	candidates := bson.A{value}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, number)
+	}
+	if value == "true" || value == "false" {
+		candidates = append(candidates, value == "true")
+	}
+	return Condition{filter: bson.M{field: bson.M{"$in": candidates}}}
+}
+
+// CheckPath validates a user supplied dot path below a document field
+func CheckPath(path string) error {
+	if !fieldPattern.MatchString(path) || strings.Count(path, ".") >= MaxPathDepth {
+		return fmt.Errorf("%w: %q is not a plain dot path of at most %d parts", ErrInvalid, path, MaxPathDepth)
+	}
+	return nil
+}
+
+// Build combines conditions into one filter. Conditions whose fields don't
+// overlap are merged into the top level, where indexes see them; the rest
+// are added to $and. It returns the first condition's error.
+func Build(conditions ...Condition) (bson.M, error) {
+	filter := bson.M{}
+	var and bson.A
+	for _, condition := range conditions {
+		if condition.err != nil {
+			return nil, condition.err
+		}
+		if len(condition.filter) == 0 {
+			continue
+		}
+		if overlaps(filter, condition.filter) {
+			and = append(and, condition.filter)
+			continue
+		}
+		for key, value := range condition.filter {
+			filter[key] = value
+		}
+	}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+	return filter, nil
+}
+
+func overlaps(filter, other bson.M) bool {
+	for key := range other {
+		if _, ok := filter[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func checkField(field string) error {
+	if !fieldPattern.MatchString(field) {
+		return fmt.Errorf("%w: field %q", ErrInvalid, field)
+	}
+	return nil
+}
+
+// checkValue bounds a literal's length and rejects operator-shaped values,
+// control characters and invalid UTF-8, none of which occur in Reddit data
+func checkValue(name, value string, maxLength int) error {
+	switch {
+	case !utf8.ValidString(value):
+		return fmt.Errorf("%w: %s is not valid UTF-8", ErrInvalid, name)
+	case utf8.RuneCountInString(value) > maxLength:
+		return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalid, name, maxLength)
+	case operatorPattern.MatchString(value):
+		return fmt.Errorf("%w: %s must not start with an operator", ErrInvalid, name)
+	case strings.ContainsFunc(value, func(r rune) bool { return r < 0x20 || r == 0x7f }):
+		return fmt.Errorf("%w: %s contains control characters", ErrInvalid, name)
+	}
+	return nil
+}
+
+func checkList(field string, values []string) (bson.A, error) {
+	if err := checkField(field); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: %s needs at least one value", ErrInvalid, field)
+	}
+	if len(values) > MaxListLength {
+		return nil, fmt.Errorf("%w: %s takes at most %d values", ErrInvalid, field, MaxListLength)
+	}
+	list := make(bson.A, 0, len(values))
+	for _, value := range values {
+		if err := checkValue(field, value, MaxValueLength); err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func timeBounds(field string, from, to time.Time) (bson.M, error) {
+	if err := checkField(field); err != nil {
+		return nil, err
+	}
+	bounds := bson.M{}
+	for _, end := range []struct {
+		op string
+		at time.Time
+	}{{"$gte", from}, {"$lt", to}} {
+		if end.at.IsZero() {
+			continue
+		}
+		if end.at.Before(earliest) || !end.at.Before(latest) {
+			return nil, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalid, field, earliest.Year(), latest.Year()-1)
+		}
+		bounds[end.op] = end.at
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, fmt.Errorf("%w: %s range start must be before its end", ErrInvalid, field)
+	}
+	return bounds, nil
+}
@@ -0,0 +1,242 @@
+package query
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// hostileValues are the seeds of every fuzz test: operators, JavaScript,
+// catastrophic regex patterns and values at the bounds
+var hostileValues = []string{
+	"golang",
+	"$where",
+	"$gt",
+	"$regex",
+	"$ne",
+	`{"$gt": ""}`,
+	"this.password == 'x' || true",
+	"'; return true; //",
+	"sleep(5000)",
+	"(a+)+$",
+	"(a|aa)*b",
+	"^(([a-z])+.)+[A-Z]([a-z])+$",
+	".*.*.*.*.*.*=.*",
+	`\`,
+	"[",
+	"a.b",
+	"\x00",
+	"line\nbreak",
+	"\xff\xfe",
+	strings.Repeat("a", MaxValueLength),
+	strings.Repeat("a", MaxValueLength+1),
+	strings.Repeat("(a*)*", 50),
+}
+
+// operators are the only keys the builders write besides field names
+var operators = map[string]bool{"$in": true, "$all": true, "$or": true, "$and": true, "$gte": true, "$lt": true}
+
+// checkKeys fails unless every key in filter is one of fields or a known
+// operator, so no user value became a key
+func checkKeys(t *testing.T, filter interface{}, fields ...string) {
+	t.Helper()
+	switch v := filter.(type) {
+	case bson.M:
+		for key, value := range v {
+			if !operators[key] && !contains(fields, key) {
+				t.Fatalf("filter %v has key %q", filter, key)
+			}
+			checkKeys(t, value, fields...)
+		}
+	case bson.A:
+		for _, value := range v {
+			checkKeys(t, value, fields...)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether value passes the validation every builder applies
+func allowed(value string, maxLength int) bool {
+	return utf8.ValidString(value) && utf8.RuneCountInString(value) <= maxLength &&
+		!regexp.MustCompile(`^\$[A-Za-z]`).MatchString(value) &&
+		!strings.ContainsFunc(value, func(r rune) bool { return r < 0x20 || r == 0x7f })
+}
+
+// wantRefusedOrLiteral fails unless err is ErrInvalid, or valid is true
+// and the filter is built
+func wantRefusedOrLiteral(t *testing.T, value string, filter bson.M, err error, valid bool) {
+	t.Helper()
+	if valid != (err == nil) {
+		t.Fatalf("value %q: error = %v, want refused: %v", value, err, !valid)
+	}
+	if err != nil && !errors.Is(err, ErrInvalid) {
+		t.Fatalf("value %q: error %v does not wrap ErrInvalid", value, err)
+	}
+	if err == nil && filter == nil {
+		t.Fatalf("value %q: no filter and no error", value)
+	}
+}
+
+func FuzzEquals(f *testing.F) {
+	for _, value := range hostileValues {
+		f.Add(value)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		filter, err := Build(ByAuthor(value))
+		wantRefusedOrLiteral(t, value, filter, err, allowed(value, MaxValueLength))
+		if err != nil {
+			return
+		}
+		checkKeys(t, filter, "author")
+		// Strings stay strings; a JSON- or operator-looking value is a literal
+		if got, ok := filter["author"].(string); !ok || got != value {
+			t.Fatalf("filter %v, want author matched literally to %q", filter, value)
+		}
+	})
+}
+
+func FuzzIn(f *testing.F) {
+	for _, value := range hostileValues {
+		f.Add("golang", value)
+	}
+	f.Fuzz(func(t *testing.T, first, second string) {
+		filter, err := Build(BySubreddits(first, second))
+		wantRefusedOrLiteral(t, first+" "+second, filter, err, allowed(first, MaxValueLength) && allowed(second, MaxValueLength))
+		if err != nil {
+			return
+		}
+		checkKeys(t, filter, "subreddit")
+		list := filter["subreddit"].(bson.M)["$in"].(bson.A)
+		if len(list) != 2 || list[0] != first || list[1] != second {
+			t.Fatalf("filter %v, want $in of the two literals", filter)
+		}
+	})
+}
+
+func FuzzTextSearch(f *testing.F) {
+	for _, value := range hostileValues {
+		f.Add(value)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		filter, err := Build(TextSearch(text))
+		wantRefusedOrLiteral(t, text, filter, err, allowed(text, MaxTextLength))
+		if err != nil {
+			return
+		}
+		checkKeys(t, filter, "title", "body")
+		for _, term := range filter["$or"].(bson.A) {
+			for _, value := range term.(bson.M) {
+				pattern := value.(primitive.Regex)
+				// Escaped, the pattern is a literal: no groups, repetition or
+				// alternation to backtrack over
+				if pattern.Pattern != regexp.QuoteMeta(text) || pattern.Options != "i" {
+					t.Fatalf("pattern %+v for %q, want the escaped literal", pattern, text)
+				}
+				if !regexp.MustCompile("(?i)" + pattern.Pattern).MatchString("x" + text + "x") {
+					t.Fatalf("pattern %q does not match the text it was built from", pattern.Pattern)
+				}
+			}
+		}
+	})
+}
+
+func FuzzHasPrefix(f *testing.F) {
+	for _, value := range hostileValues {
+		f.Add(value)
+	}
+	f.Fuzz(func(t *testing.T, prefix string) {
+		filter, err := Build(HasPrefix("subreddit_name", prefix))
+		wantRefusedOrLiteral(t, prefix, filter, err, allowed(prefix, MaxValueLength))
+		if err != nil {
+			return
+		}
+		checkKeys(t, filter, "subreddit_name")
+		pattern := filter["subreddit_name"].(bson.M)["$in"].(bson.A)[0].(primitive.Regex)
+		if pattern.Pattern != "^"+regexp.QuoteMeta(prefix) {
+			t.Fatalf("pattern %q for %q, want the anchored escaped literal", pattern.Pattern, prefix)
+		}
+	})
+}
+
+func FuzzExtrasEquals(f *testing.F) {
+	for _, value := range hostileValues {
+		f.Add("label.name", value)
+		f.Add(value, "spam")
+	}
+	f.Add("$where", "1")
+	f.Add("label.$gt", "1")
+	f.Add("a.b.c.d.e.f.g.h.i", "1")
+	f.Fuzz(func(t *testing.T, path, value string) {
+		filter, err := Build(ExtrasEquals(path, value))
+		if CheckPath(path) != nil {
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("path %q: error = %v, want ErrInvalid", path, err)
+			}
+			return
+		}
+		wantRefusedOrLiteral(t, value, filter, err, allowed(value, MaxValueLength))
+		if err != nil {
+			return
+		}
+		checkKeys(t, filter, "extras."+path)
+		if got := filter["extras."+path].(bson.M)["$in"].(bson.A)[0]; got != value {
+			t.Fatalf("filter %v, want %q matched literally", filter, value)
+		}
+	})
+}
+
+func TestBuildRefusesFields(t *testing.T) {
+	for _, field := range []string{"$where", "author.$gt", "", "a..b", "a b", "tags.$"} {
+		if _, err := Build(Equals(field, "x")); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Equals(%q) error = %v, want ErrInvalid", field, err)
+		}
+	}
+}
+
+func TestBuildMergesConditions(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	filter, err := Build(
+		BySubreddits("golang"),
+		CreatedBetween(since, time.Time{}),
+		ScoreAtLeast(10),
+		TextSearch("go 1.23"),
+		CreatedOrUpdatedBetween(since, time.Time{}),
+	)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	// The second $or can't share the top level with TextSearch's
+	if filter["subreddit"] != "golang" || filter["score"] == nil || filter["created_at"] == nil {
+		t.Errorf("filter %v, want the distinct fields at the top level", filter)
+	}
+	if and, ok := filter["$and"].(bson.A); !ok || len(and) != 1 {
+		t.Errorf("filter %v, want the overlapping $or in $and", filter)
+	}
+
+	for _, condition := range []Condition{
+		ScoreAtLeast(MaxScore + 1),
+		CreatedBetween(since, since),
+		CreatedBetween(time.Unix(-1, 0), time.Time{}),
+		In("subreddit"),
+		In("subreddit", make([]string, MaxListLength+1)...),
+	} {
+		if _, err := Build(BySubreddits("golang"), condition); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Build(%+v) error = %v, want ErrInvalid", condition, err)
+		}
+	}
+}
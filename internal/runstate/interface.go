@@ -0,0 +1,30 @@
+// internal/runstate/interface.go
+package runstate
+
+import (
+	"context"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+type RecorderInterface interface {
+	// Start records this process as running, not yet cleanly shut down, and
+	// returns the record it replaced (nil on a first boot)
+	Start(ctx context.Context) (*models.RuntimeState, error)
+	// RunHeartbeat refreshes the record with the subreddits running reports
+	// every interval until ctx is cancelled
+	RunHeartbeat(ctx context.Context, interval time.Duration, running func() []string)
+	// Stop records the clean shutdown; it should be the last step of shutdown
+	Stop(ctx context.Context) error
+	Status() Status
+}
+
+// Status is this process's runtime state and the one its host recorded before it
+type Status struct {
+	Current  models.RuntimeState  `json:"current"`
+	Previous *models.RuntimeState `json:"previous,omitempty"`
+	// PreviousUnclean is set when the previous process died without
+	// finishing its shutdown
+	PreviousUnclean bool `json:"previous_unclean"`
+}
@@ -0,0 +1,166 @@
+// internal/runstate/recorder.go
+package runstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// Ensure Recorder implements RecorderInterface
+var _ RecorderInterface = (*Recorder)(nil)
+
+// Recorder keeps this host's runtime state document in storage and, when
+// filePath is set, mirrors it to a local JSON file for supervisors without
+// MongoDB access. Records are keyed by hostname, so a restarted process finds
+// its predecessor's even though the instance ID contains the PID.
+type Recorder struct {
+	store    storage.RuntimeStateStore
+	filePath string
+
+	mu       sync.Mutex
+	state    models.RuntimeState
+	previous *models.RuntimeState
+	stopped  bool
+}
+
+func NewRecorder(store storage.RuntimeStateStore, filePath, instanceID string) *Recorder {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = instanceID
+	}
+	return &Recorder{
+		store:    store,
+		filePath: filePath,
+		state: models.RuntimeState{
+			Host:       host,
+			InstanceID: instanceID,
+			PID:        os.Getpid(),
+		},
+	}
+}
+
+// Start reads the previous record from storage, or from the state file when
+// storage has none or fails, then writes this process's record. A failed read
+// is returned alongside the write's outcome but does not stop the write.
+func (r *Recorder) Start(ctx context.Context) (*models.RuntimeState, error) {
+	previous, readErr := r.store.GetRuntimeState(ctx, r.state.Host)
+	if previous == nil && r.filePath != "" {
+		if fromFile, err := r.readFile(); err == nil {
+			previous, readErr = fromFile, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			readErr = errors.Join(readErr, err)
+		}
+	}
+	if readErr != nil {
+		readErr = fmt.Errorf("reading previous runtime state: %w", readErr)
+	}
+
+	r.mu.Lock()
+	r.previous = previous
+	now := time.Now().UTC()
+	r.state.StartedAt, r.state.HeartbeatAt = now, now
+	state := r.state
+	r.mu.Unlock()
+
+	return previous, errors.Join(readErr, r.write(ctx, state))
+}
+
+func (r *Recorder) RunHeartbeat(ctx context.Context, interval time.Duration, running func() []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		if r.stopped {
+			r.mu.Unlock()
+			return
+		}
+		r.state.HeartbeatAt = time.Now().UTC()
+		r.state.Running = running()
+		state := r.state
+		r.mu.Unlock()
+
+		if err := r.write(ctx, state); err != nil {
+			log.Printf("Failed to record runtime state heartbeat: %v", err)
+		}
+	}
+}
+
+func (r *Recorder) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	now := time.Now().UTC()
+	r.state.HeartbeatAt = now
+	r.state.Running = nil
+	r.state.CleanShutdown = true
+	r.state.StoppedAt = &now
+	state := r.state
+	r.mu.Unlock()
+
+	return r.write(ctx, state)
+}
+
+func (r *Recorder) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := Status{Current: r.state, Previous: r.previous}
+	status.PreviousUnclean = r.previous != nil && !r.previous.CleanShutdown
+	return status
+}
+
+// write saves state to storage and the state file; one failing does not
+// skip the other
+func (r *Recorder) write(ctx context.Context, state models.RuntimeState) error {
+	var errs []error
+	if err := r.store.SaveRuntimeState(ctx, &state); err != nil {
+		errs = append(errs, fmt.Errorf("saving runtime state: %w", err))
+	}
+	if r.filePath != "" {
+		if err := r.writeFile(state); err != nil {
+			errs = append(errs, fmt.Errorf("writing %s: %w", r.filePath, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeFile replaces the state file through a rename, so a supervisor never
+// reads a half-written document
+func (r *Recorder) writeFile(state models.RuntimeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.filePath + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.filePath)
+}
+
+func (r *Recorder) readFile() (*models.RuntimeState, error) {
+	data, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, err
+	}
+	var state models.RuntimeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", r.filePath, err)
+	}
+	return &state, nil
+}
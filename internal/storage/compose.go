@@ -0,0 +1,55 @@
+// internal/storage/compose.go
+package storage
+
+// Ensure Composed implements StorageInterface
+var _ StorageInterface = (*Composed)(nil)
+
+// Composed assembles a StorageInterface from one implementation per facet,
+// so a decorator (cache, metrics) can wrap a single facet:
+//
+//	store := storage.Compose(mongoStore)
+//	store.PostStore = newCachedPostStore(store.PostStore)
+type Composed struct {
+	MetadataStore
+	PostStore
+	ConfigStore
+	AnomalyStore
+	ReportStore
+	NotificationStore
+	RollupStore
+	LeaderStore
+	PauseStore
+	RuntimeStateStore
+	ConsumerStore
+	CaptureStore
+	PartitionStore
+	ReprocessStore
+	StatsStore
+	BackupStore
+	Transactor
+	HealthChecker
+}
+
+// Compose returns a Composed whose facets are all served by base
+func Compose(base StorageInterface) *Composed {
+	return &Composed{
+		MetadataStore:     base,
+		PostStore:         base,
+		ConfigStore:       base,
+		AnomalyStore:      base,
+		ReportStore:       base,
+		NotificationStore: base,
+		RollupStore:       base,
+		LeaderStore:       base,
+		PauseStore:        base,
+		RuntimeStateStore: base,
+		ConsumerStore:     base,
+		CaptureStore:      base,
+		PartitionStore:    base,
+		ReprocessStore:    base,
+		StatsStore:        base,
+		BackupStore:       base,
+		Transactor:        base,
+		HealthChecker:     base,
+	}
+}
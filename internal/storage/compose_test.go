@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// facetStub stands in for a whole backend; its nil embedded interface
+// panics on any call it does not override
+type facetStub struct {
+	StorageInterface
+	name string
+}
+
+func (s *facetStub) GetPostByRedditID(ctx context.Context, redditID string) (*models.Post, error) {
+	return &models.Post{RedditID: redditID, Subreddit: s.name}, nil
+}
+
+func TestComposeFillsEveryFacet(t *testing.T) {
+	base := &facetStub{name: "base"}
+	composed := Compose(base)
+
+	value := reflect.ValueOf(composed).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if value.Field(i).IsNil() {
+			t.Errorf("Compose() left %s unset", field.Name)
+			continue
+		}
+		if value.Field(i).Interface() != StorageInterface(base) {
+			t.Errorf("Compose() set %s to %v, want the base store", field.Name, value.Field(i).Interface())
+		}
+	}
+}
+
+func TestComposedRoutesToReplacedFacet(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace bool
+		want    string
+	}{
+		{name: "base facet", want: "base"},
+		{name: "replaced facet", replace: true, want: "decorated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composed := Compose(&facetStub{name: "base"})
+			if tt.replace {
+				composed.PostStore = &facetStub{name: "decorated"}
+			}
+
+			post, err := composed.GetPostByRedditID(context.Background(), "t3_a")
+			if err != nil {
+				t.Fatalf("GetPostByRedditID() error = %v", err)
+			}
+			if post.Subreddit != tt.want {
+				t.Errorf("served by %q, want %q", post.Subreddit, tt.want)
+			}
+		})
+	}
+}
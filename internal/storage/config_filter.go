@@ -0,0 +1,115 @@
+// internal/storage/config_filter.go
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+)
+
+// Config sort orders accepted by GetAllSubredditConfigs
+const (
+	ConfigSortPriority = "priority" // priority descending, then name (default)
+	ConfigSortName     = "name"     // subreddit_name ascending
+	ConfigSortUpdated  = "updated"  // updated_at descending
+)
+
+// ConfigFilter narrows GetAllSubredditConfigs. Zero values match every live config.
+type ConfigFilter struct {
+	// Query matches names starting with it as typed, lowercased or
+	// capitalized. Each form is an anchored regex the subreddit_name index
+	// bounds; a truly case-insensitive match would scan every name.
+	Query string
+	// Enabled matches only enabled or only disabled configs; nil matches both
+	Enabled *bool
+	// Label must already be normalized
+	Label string
+	// MinPriority and MaxPriority bound priority inclusively; nil is open
+	MinPriority *int
+	MaxPriority *int
+	// Sort is one of ConfigSortPriority, ConfigSortName or ConfigSortUpdated;
+	// empty means priority
+	Sort string
+}
+
+// ConfigPage is one page of GetAllSubredditConfigs results
+type ConfigPage struct {
+	Configs []models.SubredditConfig
+	// Total counts every config matching the filter, ignoring Skip and Limit
+	Total int64
+}
+
+// Validate checks the sort, the priority range and the user supplied values
+func (f ConfigFilter) Validate() error {
+	switch f.Sort {
+	case "", ConfigSortPriority, ConfigSortName, ConfigSortUpdated:
+	default:
+		return fmt.Errorf("sort must be %s, %s or %s", ConfigSortPriority, ConfigSortName, ConfigSortUpdated)
+	}
+	if f.MinPriority != nil && f.MaxPriority != nil && *f.MinPriority > *f.MaxPriority {
+		return errors.New("min priority must not exceed max priority")
+	}
+	_, err := f.bson()
+	return err
+}
+
+func (f ConfigFilter) sort() bson.D {
+	switch f.Sort {
+	case ConfigSortName:
+		return bson.D{{Key: "subreddit_name", Value: 1}}
+	case ConfigSortUpdated:
+		return bson.D{{Key: "updated_at", Value: -1}, {Key: "subreddit_name", Value: 1}}
+	default:
+		return bson.D{{Key: "priority", Value: -1}, {Key: "subreddit_name", Value: 1}}
+	}
+}
+
+// bson builds the Mongo filter through the query package; soft-deleted
+// configs never match
+func (f ConfigFilter) bson() (bson.M, error) {
+	conditions := []query.Condition{query.Trusted(bson.M{"deleted_at": bson.M{"$exists": false}})}
+	if prefix := strings.TrimSpace(f.Query); prefix != "" {
+		conditions = append(conditions, query.HasPrefix("subreddit_name", nameForms(prefix)...))
+	}
+	if f.Enabled != nil {
+		conditions = append(conditions, query.Trusted(bson.M{"enabled": *f.Enabled}))
+	}
+	if f.Label != "" {
+		conditions = append(conditions, query.Equals("labels", f.Label))
+	}
+	priority := bson.M{}
+	if f.MinPriority != nil {
+		priority["$gte"] = *f.MinPriority
+	}
+	if f.MaxPriority != nil {
+		priority["$lte"] = *f.MaxPriority
+	}
+	if len(priority) > 0 {
+		conditions = append(conditions, query.Trusted(bson.M{"priority": priority}))
+	}
+	return query.Build(conditions...)
+}
+
+// nameForms returns the distinct spellings of a name prefix to match: as
+// typed, lowercased and capitalized, which covers how subreddits are named
+func nameForms(query string) []string {
+	lower := strings.ToLower(query)
+	first, size := utf8.DecodeRuneInString(lower)
+	capitalized := string(unicode.ToUpper(first)) + lower[size:]
+
+	forms := []string{query}
+	for _, form := range []string{lower, capitalized} {
+		if !slices.Contains(forms, form) {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
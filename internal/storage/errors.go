@@ -0,0 +1,140 @@
+// internal/storage/errors.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"reddit-orchestrator/internal/metrics"
+)
+
+// ErrorCategory groups storage failures by cause, for metrics and retry decisions
+type ErrorCategory string
+
+const (
+	ErrorDuplicateKey ErrorCategory = "duplicate_key"
+	ErrorTimeout      ErrorCategory = "timeout"
+	ErrorNetwork      ErrorCategory = "network"
+	ErrorValidation   ErrorCategory = "validation"
+	ErrorWriteConcern ErrorCategory = "write_concern"
+	ErrorOther        ErrorCategory = "other"
+)
+
+// Operations whose outcomes are counted in storage_operations_total
+const (
+	OpUpsertPosts    = "upsert_posts"
+	OpGetPosts       = "get_posts"
+	OpMetadataUpdate = "metadata_update"
+)
+
+// MaxUnboundedResults caps listings called without a limit (MAX_UNBOUNDED_RESULTS),
+// so one bad call can't pull a whole collection into memory
+var MaxUnboundedResults = 10000
+
+// ErrTruncated is returned, wrapped and together with the first
+// MaxUnboundedResults results, by listings called without a limit that
+// matched more. IteratePosts reads any number of posts.
+var ErrTruncated = errors.New("results truncated")
+
+func truncatedError() error {
+	return fmt.Errorf("%w at %d (MAX_UNBOUNDED_RESULTS); pass a limit or iterate", ErrTruncated, MaxUnboundedResults)
+}
+
+// Server error codes that mean the request itself was invalid
+var validationErrorCodes = []int{
+	2,   // BadValue
+	9,   // FailedToParse
+	14,  // TypeMismatch
+	121, // DocumentValidationFailure
+}
+
+// Server error codes reported when a write concern cannot be met
+var writeConcernErrorCodes = []int{
+	64,  // WriteConcernFailed
+	100, // UnsatisfiableWriteConcern
+}
+
+// OperationError is a failed storage operation with its error category
+type OperationError struct {
+	Op       string
+	Category ErrorCategory
+	Err      error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s failed (%s): %v", e.Op, e.Category, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError maps a driver or context error to a category. Timeouts are
+// checked first since the driver also reports them as network errors.
+func ClassifyError(err error) ErrorCategory {
+	var opErr *OperationError
+	switch {
+	case errors.As(err, &opErr):
+		return opErr.Category
+	case errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err):
+		return ErrorTimeout
+	case mongo.IsDuplicateKeyError(err):
+		return ErrorDuplicateKey
+	case mongo.IsNetworkError(err):
+		return ErrorNetwork
+	}
+
+	var writeErr mongo.WriteException
+	if errors.As(err, &writeErr) && writeErr.WriteConcernError != nil {
+		return ErrorWriteConcern
+	}
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError != nil {
+		return ErrorWriteConcern
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range writeConcernErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return ErrorWriteConcern
+			}
+		}
+		for _, code := range validationErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return ErrorValidation
+			}
+		}
+	}
+
+	return ErrorOther
+}
+
+// IsRetryable reports whether an operation that failed with err is worth one
+// more attempt: timeouts and network errors are, everything else is not
+func IsRetryable(err error) bool {
+	switch ClassifyError(err) {
+	case ErrorTimeout, ErrorNetwork:
+		return true
+	default:
+		return false
+	}
+}
+
+// observe counts the outcome of op and wraps a failure in an OperationError
+func observe(op string, err error) error {
+	if err == nil {
+		metrics.RecordStorageOperation(op, "ok")
+		return nil
+	}
+
+	var opErr *OperationError
+	if !errors.As(err, &opErr) {
+		opErr = &OperationError{Op: op, Category: ClassifyError(err), Err: err}
+	}
+	metrics.RecordStorageOperation(op, string(opErr.Category))
+	return opErr
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"reddit-orchestrator/internal/metrics"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		want          ErrorCategory
+		wantRetryable bool
+	}{
+		{name: "context deadline", err: context.DeadlineExceeded, want: ErrorTimeout, wantRetryable: true},
+		{name: "wrapped deadline", err: fmt.Errorf("storing posts: %w", context.DeadlineExceeded), want: ErrorTimeout, wantRetryable: true},
+		{name: "max time expired", err: mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}, want: ErrorTimeout, wantRetryable: true},
+		{
+			name:          "network timeout",
+			err:           mongo.CommandError{Labels: []string{"NetworkError"}, Wrapped: context.DeadlineExceeded},
+			want:          ErrorTimeout,
+			wantRetryable: true,
+		},
+		{name: "network error", err: mongo.CommandError{Labels: []string{"NetworkError"}}, want: ErrorNetwork, wantRetryable: true},
+		{
+			name: "duplicate key write",
+			err:  mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key"}}},
+			want: ErrorDuplicateKey,
+		},
+		{
+			name: "duplicate key in bulk write",
+			err:  mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 11000}}}},
+			want: ErrorDuplicateKey,
+		},
+		{
+			name: "write concern",
+			err:  mongo.WriteException{WriteConcernError: &mongo.WriteConcernError{Code: 64, Name: "WriteConcernFailed"}},
+			want: ErrorWriteConcern,
+		},
+		{
+			name: "write concern in bulk write",
+			err:  mongo.BulkWriteException{WriteConcernError: &mongo.WriteConcernError{Code: 100}},
+			want: ErrorWriteConcern,
+		},
+		{name: "unsatisfiable write concern command", err: mongo.CommandError{Code: 100}, want: ErrorWriteConcern},
+		{name: "bad value", err: mongo.CommandError{Code: 2, Name: "BadValue"}, want: ErrorValidation},
+		{
+			name: "document validation",
+			err:  mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 121, Message: "Document failed validation"}}},
+			want: ErrorValidation,
+		},
+		{name: "already classified", err: fmt.Errorf("wrapped: %w", &OperationError{Op: OpGetPosts, Category: ErrorValidation, Err: errors.New("bad")}), want: ErrorValidation},
+		{name: "unknown command error", err: mongo.CommandError{Code: 13, Name: "Unauthorized"}, want: ErrorOther},
+		{name: "plain error", err: errors.New("boom"), want: ErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %s, want %s", got, tt.want)
+			}
+			if got := IsRetryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestObserveWrapsAndCounts(t *testing.T) {
+	before := storageOperationCount(t, OpUpsertPosts, string(ErrorDuplicateKey))
+	okBefore := storageOperationCount(t, OpUpsertPosts, "ok")
+
+	cause := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	err := observe(OpUpsertPosts, cause)
+
+	var opErr *OperationError
+	if !errors.As(err, &opErr) || opErr.Op != OpUpsertPosts || opErr.Category != ErrorDuplicateKey {
+		t.Fatalf("observe() = %v, want an upsert_posts duplicate_key OperationError", err)
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("observe() error %v no longer unwraps to the driver error", err)
+	}
+	// An already wrapped error is counted under its own category, not rewrapped
+	if again := observe(OpUpsertPosts, err); again != err {
+		t.Errorf("observe() rewrapped %v as %v", err, again)
+	}
+	if err := observe(OpUpsertPosts, nil); err != nil {
+		t.Errorf("observe(nil) = %v", err)
+	}
+
+	if got := storageOperationCount(t, OpUpsertPosts, string(ErrorDuplicateKey)) - before; got != 2 {
+		t.Errorf("duplicate_key count grew by %v, want 2", got)
+	}
+	if got := storageOperationCount(t, OpUpsertPosts, "ok") - okBefore; got != 1 {
+		t.Errorf("ok count grew by %v, want 1", got)
+	}
+}
+
+// storageOperationCount reads storage_operations_total for one operation and outcome
+func storageOperationCount(t *testing.T, operation, outcome string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "orchestrator_storage_operations_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["outcome"] == outcome {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -3,31 +3,308 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"reddit-orchestrator/internal/models"
 )
 
-type StorageInterface interface {
-	// Subreddit metadata operations
+// PostQueryOptions narrows post listing queries
+type PostQueryOptions struct {
+	// Tags requires posts to carry every listed tag
+	Tags []string
+	// ExcludeNSFW drops posts flagged NSFW; posts with unknown status are kept
+	ExcludeNSFW bool
+	// Extras matches enrichment fields by dot path below extras (e.g. "label.name")
+	Extras map[string]string
+	// Sort and Fields are as in PostFilter
+	Sort   string
+	Fields []string
+}
+
+// ListOptions projects and pages whole-collection listings. Zero values
+// return every field of every document.
+type ListOptions struct {
+	// Fields limits the returned fields (bson names); _id is always included
+	Fields []string
+	Skip   int64
+	Limit  int64
+}
+
+// CollectionVersion changes whenever the documents it covers do: a write
+// moves UpdatedAt forward and a removal lowers Count
+type CollectionVersion struct {
+	Count     int64
+	UpdatedAt time.Time
+}
+
+// UpsertOptions controls per-batch behaviour of UpsertPosts
+type UpsertOptions struct {
+	// TrackRevisions saves the previous title/body of edited posts
+	TrackRevisions bool
+	// ImportBatch labels the posts this upsert inserts; posts already stored
+	// keep their label, or lack of one
+	ImportBatch string
+}
+
+// UpsertResult reports what UpsertPosts changed
+type UpsertResult struct {
+	// InsertedIDs are the reddit IDs of posts that did not exist before
+	InsertedIDs []string
+}
+
+// InsertedPosts returns the posts of the upserted batch that were newly inserted
+func (r UpsertResult) InsertedPosts(posts []models.Post) []models.Post {
+	if len(r.InsertedIDs) == 0 {
+		return nil
+	}
+
+	inserted := make(map[string]bool, len(r.InsertedIDs))
+	for _, id := range r.InsertedIDs {
+		inserted[id] = true
+	}
+	newPosts := make([]models.Post, 0, len(r.InsertedIDs))
+	for _, post := range posts {
+		if inserted[post.RedditID] {
+			newPosts = append(newPosts, post)
+		}
+	}
+	return newPosts
+}
+
+// MetadataStore tracks scrape cursors and run stats of subreddits and users
+type MetadataStore interface {
 	GetSubredditMetadata(ctx context.Context, subredditName string) (*models.SubredditMetadata, error)
+	GetSubredditMetadataByNames(ctx context.Context, names []string) (map[string]models.SubredditMetadata, error)
+	GetLatestSubredditRuns(ctx context.Context, names []string) (map[string]models.TaskRunSummary, error)
 	UpsertSubredditMetadata(ctx context.Context, metadata *models.SubredditMetadata) error
-	GetAllSubredditMetadata(ctx context.Context) ([]models.SubredditMetadata, error)
+	UpdateLastScraped(ctx context.Context, subredditName string, scrapedAt time.Time, stats models.RunStats) error
+	UpdateZeroPostRuns(ctx context.Context, subredditName string, postsFetched int) (int, error)
+	SetBestRecentPost(ctx context.Context, subredditName string, best models.BestRecentPost) error
+	SetSubredditStale(ctx context.Context, subredditName string, stale bool) error
+	SetSubredditAbout(ctx context.Context, subredditName string, about models.AboutInfo) error
+	SetSubredditActivity(ctx context.Context, subredditName string, profile models.ActivityProfile) error
+	RecordRunOutcome(ctx context.Context, subredditName string, failed bool) error
+	GetAllSubredditMetadata(ctx context.Context, opts ListOptions) ([]models.SubredditMetadata, error)
+	FindInconsistentMetadata(ctx context.Context, now time.Time, margin time.Duration) ([]models.MetadataRepair, error)
+	ApplyMetadataRepair(ctx context.Context, repair models.MetadataRepair) (bool, error)
+	CorrectScrapeCursor(ctx context.Context, subredditName string, correction models.CursorCorrection) (bool, error)
+	RecordCursorProbe(ctx context.Context, subredditName string, at time.Time) error
+	GetSubredditMetadataVersion(ctx context.Context) (CollectionVersion, error)
 
-	// Post operations
+	GetUserMetadata(ctx context.Context, username string) (*models.UserMetadata, error)
+	UpdateUserLastScraped(ctx context.Context, username string, scrapedAt time.Time, stats models.RunStats) error
+}
+
+// PostStore stores posts and their revisions
+type PostStore interface {
 	UpsertPost(ctx context.Context, post *models.Post) error
-	UpsertPosts(ctx context.Context, posts []models.Post) error
-	GetPostsBySubreddit(ctx context.Context, subreddit string, limit int) ([]models.Post, error)
+	UpsertPosts(ctx context.Context, posts []models.Post, opts UpsertOptions) (UpsertResult, error)
+	FindPosts(ctx context.Context, filter PostFilter) (PostPage, error)
+	IteratePosts(ctx context.Context, filter PostFilter, fn func(models.Post) error) error
+	GetPostsBySubreddit(ctx context.Context, subreddit string, limit int, opts PostQueryOptions) ([]models.Post, error)
 	GetPostByRedditID(ctx context.Context, redditID string) (*models.Post, error)
+	GetExistingRedditIDs(ctx context.Context, redditIDs []string) (map[string]bool, error)
+	GetPostRevisions(ctx context.Context, redditID string) ([]models.PostRevision, error)
+	GetPostsWithFlairChange(ctx context.Context, subreddit string, since time.Time) ([]models.Post, error)
 	GetRecentPosts(ctx context.Context, subreddit string, hours int) ([]models.Post, error)
+	GetRecentPostsAllSubreddits(ctx context.Context, since time.Time, perSubredditCap, totalCap int) ([]models.Post, error)
 	GetPostsCount(ctx context.Context, subreddit string) (int64, error)
+	ReconcilePostPresence(ctx context.Context, subreddit string, window TimeRange, seen []string, missesToRemove int) (models.PresenceScan, error)
+}
 
-	GetAllSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+// ConfigStore holds the monitored subreddit and user configurations
+type ConfigStore interface {
+	GetAllSubredditConfigs(ctx context.Context, filter ConfigFilter, opts ListOptions) (ConfigPage, error)
 	GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
-	UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig) error
+	GetSubredditConfigsByLabel(ctx context.Context, label string, opts ListOptions) ([]models.SubredditConfig, error)
+	GetSubredditConfigsVersion(ctx context.Context, label string) (CollectionVersion, error)
+	UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig, actor string) error
 	GetSubredditConfig(ctx context.Context, subredditName string) (*models.SubredditConfig, error)
-	DeleteSubredditConfig(ctx context.Context, subredditName string) error
+	DeleteSubredditConfig(ctx context.Context, subredditName, actor string) (bool, error)
+	RestoreSubredditConfig(ctx context.Context, subredditName, actor string) (*models.SubredditConfig, error)
+	GetDeletedSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+	PurgeDeletedSubredditConfigs(ctx context.Context, deletedBefore time.Time, actor string) (int, error)
+	GetConfigAudit(ctx context.Context, subredditName string, limit int) ([]models.ConfigAudit, error)
+	SetPrioritySuggestion(ctx context.Context, subredditName string, suggestion models.PrioritySuggestion, actor string) (bool, error)
+
+	StageConfigChange(ctx context.Context, change models.StagedConfigChange) error
+	GetStagedConfigChanges(ctx context.Context) ([]models.StagedConfigChange, error)
+	UnstageConfigChange(ctx context.Context, subredditName string) error
+	DiscardStagedConfigChanges(ctx context.Context) (int64, error)
+
+	GetConfigTemplates(ctx context.Context) ([]models.ConfigTemplate, error)
+	GetConfigTemplate(ctx context.Context, name string) (*models.ConfigTemplate, error)
+	UpsertConfigTemplate(ctx context.Context, template *models.ConfigTemplate) error
+	DeleteConfigTemplate(ctx context.Context, name string) (bool, error)
+
+	GetRunPresets(ctx context.Context) ([]models.RunPreset, error)
+	GetRunPreset(ctx context.Context, name string) (*models.RunPreset, error)
+	UpsertRunPreset(ctx context.Context, preset *models.RunPreset) error
+	DeleteRunPreset(ctx context.Context, name string) (bool, error)
+
+	GetAllUserConfigs(ctx context.Context) ([]models.UserConfig, error)
+	GetActiveUserConfigs(ctx context.Context) ([]models.UserConfig, error)
+	GetUserConfig(ctx context.Context, username string) (*models.UserConfig, error)
+	UpsertUserConfig(ctx context.Context, config *models.UserConfig) error
+	DeleteUserConfig(ctx context.Context, username string) error
+}
+
+// AnomalyStore finds and records author posting bursts
+type AnomalyStore interface {
+	FindAuthorBursts(ctx context.Context, subreddit string, since time.Time, window time.Duration, minPosts int) ([]models.Anomaly, error)
+	UpsertAnomaly(ctx context.Context, anomaly *models.Anomaly) error
+	GetAnomalies(ctx context.Context, subreddit string, limit int) ([]models.Anomaly, error)
+	AddTagToAuthorPosts(ctx context.Context, subreddit, author string, from, to time.Time, tag string) error
+}
+
+// ReportStore computes reports across the posts of every subreddit
+type ReportStore interface {
+	GetDuplicateURLReport(ctx context.Context, since time.Time, minSubreddits int) ([]models.DuplicateURL, error)
+	GetDomainStats(ctx context.Context, subreddit string, since time.Time, limit int) (models.DomainStats, error)
+}
 
-	// Health check and cleanup
+// NotificationStore holds notification rules and their audit log
+type NotificationStore interface {
+	GetAllNotificationRules(ctx context.Context) ([]models.NotificationRule, error)
+	GetActiveNotificationRules(ctx context.Context) ([]models.NotificationRule, error)
+	GetNotificationRule(ctx context.Context, name string) (*models.NotificationRule, error)
+	UpsertNotificationRule(ctx context.Context, rule *models.NotificationRule) error
+	DeleteNotificationRule(ctx context.Context, name string) error
+	ClaimNotificationRule(ctx context.Context, name string, now time.Time, cooldown time.Duration) (bool, error)
+	ReleaseNotificationRule(ctx context.Context, name string, claimedAt time.Time) error
+	InsertNotificationLogs(ctx context.Context, entries []models.NotificationLog) error
+	GetNotificationLog(ctx context.Context, ruleName string, limit int) ([]models.NotificationLog, error)
+	InsertWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, status string, limit int) ([]models.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error)
+
+	GetAllDigests(ctx context.Context) ([]models.Digest, error)
+	GetDigest(ctx context.Context, label string) (*models.Digest, error)
+	UpsertDigest(ctx context.Context, digest *models.Digest) error
+	DeleteDigest(ctx context.Context, label string) (bool, error)
+	MarkDigestSent(ctx context.Context, label string, sentAt time.Time) error
+}
+
+// RollupStore maintains hourly per-subreddit post rollups for volume stats
+type RollupStore interface {
+	IncrementRollups(ctx context.Context, posts []models.Post) error
+	RebuildRollups(ctx context.Context, subreddit string, from, to time.Time) (int, error)
+	GetRollups(ctx context.Context, subreddit string, from, to time.Time) ([]models.PostRollup, error)
+	GetRollupsForSubreddits(ctx context.Context, subreddits []string, from, to time.Time) ([]models.PostRollup, error)
+}
+
+// LeaderStore holds the leadership lease shared by HA instances
+type LeaderStore interface {
+	ClaimLeadership(ctx context.Context, name, instanceID string, now time.Time, ttl time.Duration) (*models.LeaderLease, error)
+	ReleaseLeadership(ctx context.Context, name, instanceID string) error
+}
+
+// PauseStore holds the maintenance pause shared by every instance
+type PauseStore interface {
+	GetMaintenancePause(ctx context.Context) (*models.MaintenancePause, error)
+	SetMaintenancePause(ctx context.Context, pause *models.MaintenancePause) error
+	ClearMaintenancePause(ctx context.Context) (bool, error)
+}
+
+// RuntimeStateStore keeps each host's runtime state for external supervisors
+type RuntimeStateStore interface {
+	GetRuntimeState(ctx context.Context, host string) (*models.RuntimeState, error)
+	SaveRuntimeState(ctx context.Context, state *models.RuntimeState) error
+}
+
+// ConsumerStore keeps named consumers and their delivery cursors, and reads
+// posts in insertion order for them
+type ConsumerStore interface {
+	GetConsumer(ctx context.Context, name string) (*models.Consumer, error)
+	GetConsumers(ctx context.Context) ([]models.Consumer, error)
+	SaveConsumer(ctx context.Context, consumer *models.Consumer) error
+	DeleteConsumer(ctx context.Context, name string) (bool, error)
+	GetPostsInsertedAfter(ctx context.Context, subreddit string, after models.ConsumerPosition, before time.Time, limit int) ([]models.Post, error)
+	AdvanceConsumerCursor(ctx context.Context, name, subreddit string, from, to models.ConsumerPosition) (bool, error)
+	SetConsumerPending(ctx context.Context, name, subreddit string, batch models.ConsumerBatch) (bool, error)
+	AckConsumerBatch(ctx context.Context, name, subreddit, token string) (bool, error)
+}
+
+// CaptureStore keeps raw ingestion responses captured in debug mode and the
+// posts sampled for QA
+type CaptureStore interface {
+	InsertRawCapture(ctx context.Context, capture *models.RawCapture) error
+	CountRawCaptures(ctx context.Context, subreddit string, since time.Time) (int64, error)
+	GetLatestRawCapture(ctx context.Context, subreddit string) (*models.RawCapture, error)
+	InsertQASamples(ctx context.Context, samples []models.QASample) error
+	GetQASamples(ctx context.Context, subreddit string, limit int) ([]models.QASample, error)
+}
+
+// PartitionStore manages the monthly post partitions (PARTITIONING=monthly)
+type PartitionStore interface {
+	PostPartitions(ctx context.Context) ([]string, error)
+	DropPostPartitionsBefore(ctx context.Context, before time.Time) ([]string, error)
+	MigratePostsToPartitions(ctx context.Context, batchSize int, progress func(moved int64)) (int64, error)
+}
+
+// ReprocessStore scans stored posts and writes reprocessed ones back, for
+// re-applying the current processing to posts stored by older versions
+type ReprocessStore interface {
+	ScanPosts(ctx context.Context, subreddit string, after PostScanPosition, limit int) ([]models.Post, PostScanPosition, error)
+	RewritePosts(ctx context.Context, collection string, updated, deleted []models.Post) (int64, int64, error)
+	GetReprocessProgress(ctx context.Context, scope string) (*models.ReprocessProgress, error)
+	SaveReprocessProgress(ctx context.Context, progress *models.ReprocessProgress) error
+	DeleteReprocessProgress(ctx context.Context, scope string) error
+}
+
+// StatsStore computes and caches per-subreddit storage footprints, finds
+// and trims oversized post documents, and sizes the operational collections
+type StatsStore interface {
+	RefreshSubredditStorageStats(ctx context.Context, opts StorageStatsOptions) ([]models.SubredditStorageStats, error)
+	GetSubredditStorageStats(ctx context.Context) ([]models.SubredditStorageStats, error)
+	GetSubredditStorageStatsVersion(ctx context.Context) (CollectionVersion, error)
+	FindOversizedPosts(ctx context.Context, minBytes, limit int, timeout time.Duration) ([]models.OversizedPost, error)
+	TrimPostHistory(ctx context.Context, collection string, post models.OversizedPost, keep int) (int, error)
+	OpsDataStats(ctx context.Context) ([]models.OpsDataStats, error)
+}
+
+// BackupStore exports and restores everything but posts and derived data
+type BackupStore interface {
+	ExportBackup(ctx context.Context) (*models.Backup, error)
+	RestoreBackup(ctx context.Context, backup *models.Backup, opts RestoreOptions) (models.RestoreReport, error)
+}
+
+// Transactor runs a group of writes atomically
+type Transactor interface {
+	// WithTransaction runs fn in a transaction; fn's storage calls must use
+	// the ctx it is given. fn may be retried on transient errors, so it must
+	// reset any state it builds. Without transaction support fn runs once
+	// and its writes are not atomic.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// HealthChecker covers health checks, diagnostics and cleanup
+type HealthChecker interface {
 	Ping(ctx context.Context) error
+	HealthInfo(ctx context.Context) (HealthInfo, error)
+	ExplainQueryShapes(ctx context.Context) ([]models.QueryPlanReport, error)
 	Close() error
-}
\ No newline at end of file
+}
+
+// StorageInterface is the full storage backend. Consumers should depend on
+// the narrowest facets they use.
+type StorageInterface interface {
+	MetadataStore
+	PostStore
+	ConfigStore
+	AnomalyStore
+	ReportStore
+	NotificationStore
+	RollupStore
+	LeaderStore
+	PauseStore
+	RuntimeStateStore
+	ConsumerStore
+	CaptureStore
+	PartitionStore
+	ReprocessStore
+	StatsStore
+	BackupStore
+	Transactor
+	HealthChecker
+}
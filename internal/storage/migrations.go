@@ -0,0 +1,217 @@
+// internal/storage/migrations.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// SchemaMigrationsCollection records the applied migrations, one document per ID
+const SchemaMigrationsCollection = "schema_migrations"
+
+const (
+	// migrationLockName is the leadership lease that serializes migration runs
+	migrationLockName = "schema_migrations"
+	// migrationLockTTL bounds how long a crashed instance blocks the others;
+	// the lock is renewed before every migration
+	migrationLockTTL = 2 * time.Minute
+	// migrationLockPoll is how often a waiting instance retries the lock
+	migrationLockPoll = 2 * time.Second
+	// migrationTimeout bounds a whole startup migration run, lock wait included
+	migrationTimeout = 10 * time.Minute
+)
+
+// migration is one schema change. up must be idempotent: a crash between up
+// and recording it reruns it on the next start.
+type migration struct {
+	id   string
+	name string
+	up   func(ctx context.Context, db *mongo.Database) error
+}
+
+// migrations run in order; append new ones and never renumber or remove them
+var migrations = []migration{
+	{id: "001", name: "drop_legacy_indexes", up: dropLegacyIndexes},
+	{id: "002", name: "builtin_config_templates", up: insertBuiltinConfigTemplates},
+	{id: "003", name: "builtin_run_presets", up: insertBuiltinRunPresets},
+}
+
+// schemaMigration is the record of an applied migration
+type schemaMigration struct {
+	ID        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// dropLegacyIndexes drops indexes replaced by ones createIndexes now builds:
+// the old reddit_name and non-unique reddit_id post indexes, and the config
+// name index that blocked soft-deleted duplicates. Missing indexes are skipped.
+func dropLegacyIndexes(ctx context.Context, db *mongo.Database) error {
+	legacy := []struct{ collection, index string }{
+		{SubredditPostsCollection, "reddit_name_1"},
+		{SubredditPostsCollection, "reddit_id_1"},
+		{SubredditConfigCollection, "subreddit_name_1"},
+	}
+	for _, idx := range legacy {
+		if err := dropIndexIfExists(ctx, db.Collection(idx.collection), idx.index); err != nil {
+			return fmt.Errorf("dropping %s.%s: %w", idx.collection, idx.index, err)
+		}
+	}
+	return nil
+}
+
+// insertBuiltinConfigTemplates creates the default config templates. A
+// template that already exists by name is left as it is.
+func insertBuiltinConfigTemplates(ctx context.Context, db *mongo.Database) error {
+	now := time.Now().UTC()
+	for _, template := range models.DefaultConfigTemplates() {
+		template.CreatedAt, template.UpdatedAt = now, now
+		_, err := db.Collection(ConfigTemplatesCollection).UpdateOne(ctx,
+			bson.M{"name": template.Name},
+			bson.M{"$setOnInsert": template},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("inserting template %s: %w", template.Name, err)
+		}
+	}
+	return nil
+}
+
+// insertBuiltinRunPresets creates the default run presets. A preset that
+// already exists by name is left as it is.
+func insertBuiltinRunPresets(ctx context.Context, db *mongo.Database) error {
+	now := time.Now().UTC()
+	for _, preset := range models.DefaultRunPresets() {
+		preset.CreatedAt, preset.UpdatedAt = now, now
+		_, err := db.Collection(RunPresetsCollection).UpdateOne(ctx,
+			bson.M{"name": preset.Name},
+			bson.M{"$setOnInsert": preset},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("inserting preset %s: %w", preset.Name, err)
+		}
+	}
+	return nil
+}
+
+// dropIndexIfExists drops an index, treating a missing index or collection as done
+func dropIndexIfExists(ctx context.Context, collection *mongo.Collection, name string) error {
+	_, err := collection.Indexes().DropOne(ctx, name)
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && (serverErr.HasErrorCode(26) || serverErr.HasErrorCode(27)) { // NamespaceNotFound, IndexNotFound
+		return nil
+	}
+	return err
+}
+
+// migrate applies the pending migrations in order while holding the
+// migration lock, so concurrent instances apply each one once. It returns the
+// IDs it applied.
+func (s *MongoStorage) migrate(ctx context.Context) ([]string, error) {
+	pending, err := s.pendingMigrations(ctx)
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	var applied []string
+	err = s.withMigrationLock(ctx, func(holder string) error {
+		// Another instance may have applied some while we waited
+		if pending, err = s.pendingMigrations(ctx); err != nil {
+			return err
+		}
+
+		records := s.database.Collection(SchemaMigrationsCollection)
+		for _, m := range pending {
+			if _, err := s.ClaimLeadership(ctx, migrationLockName, holder, time.Now().UTC(), migrationLockTTL); err != nil {
+				return fmt.Errorf("renewing the migration lock: %w", err)
+			}
+
+			start := time.Now()
+			if err := m.up(ctx, s.database); err != nil {
+				return fmt.Errorf("migration %s_%s: %w", m.id, m.name, err)
+			}
+
+			record := schemaMigration{ID: m.id, Name: m.name, AppliedAt: time.Now().UTC()}
+			opts := options.Replace().SetUpsert(true)
+			if _, err := records.ReplaceOne(ctx, bson.M{"_id": m.id}, record, opts); err != nil {
+				return fmt.Errorf("recording migration %s_%s: %w", m.id, m.name, err)
+			}
+			log.Printf("Applied schema migration %s_%s in %v", m.id, m.name, time.Since(start).Round(time.Millisecond))
+			applied = append(applied, m.id)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// withMigrationLock runs fn while holding the migration lock. fn gets the
+// holder ID to renew the lock with during long steps.
+func (s *MongoStorage) withMigrationLock(ctx context.Context, fn func(holder string) error) error {
+	holder := primitive.NewObjectID().Hex()
+	if err := s.acquireMigrationLock(ctx, holder); err != nil {
+		return err
+	}
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := s.ReleaseLeadership(releaseCtx, migrationLockName, holder); err != nil {
+			log.Printf("Warning: failed to release the migration lock: %v", err)
+		}
+	}()
+	return fn(holder)
+}
+
+// pendingMigrations returns the migrations without a schema_migrations record
+func (s *MongoStorage) pendingMigrations(ctx context.Context) ([]migration, error) {
+	cursor, err := s.database.Collection(SchemaMigrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []schemaMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]bool, len(records))
+	for _, record := range records {
+		done[record.ID] = true
+	}
+
+	var pending []migration
+	for _, m := range migrations {
+		if !done[m.id] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// acquireMigrationLock waits until holder holds the migration lease
+func (s *MongoStorage) acquireMigrationLock(ctx context.Context, holder string) error {
+	for {
+		lease, err := s.ClaimLeadership(ctx, migrationLockName, holder, time.Now().UTC(), migrationLockTTL)
+		if err != nil {
+			return fmt.Errorf("claiming the migration lock: %w", err)
+		}
+		if lease.HolderID == holder {
+			return nil
+		}
+
+		log.Printf("Waiting for schema migrations running on %s", lease.HolderID)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for the migration lock: %w", ctx.Err())
+		case <-time.After(migrationLockPoll):
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+)
+
+func TestMigrationChainIsIdempotent(t *testing.T) {
+	// NewMongoStorage has run the chain once against the empty database
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	wantApplied(t, s, len(migrations))
+	if applied, err := s.migrate(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("second migrate() = %v, %v; want nothing applied", applied, err)
+	}
+
+	// Without records, as after a crash between up and recording it, every
+	// migration reruns over its own results
+	if _, err := s.database.Collection(SchemaMigrationsCollection).DeleteMany(ctx, bson.M{}); err != nil {
+		t.Fatal(err)
+	}
+	applied, err := s.migrate(ctx)
+	if err != nil {
+		t.Fatalf("rerun migrate() error = %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("rerun applied %v, want all %d migrations", applied, len(migrations))
+	}
+	wantApplied(t, s, len(migrations))
+	wantCount(t, s, ConfigTemplatesCollection, len(models.DefaultConfigTemplates()))
+	wantCount(t, s, RunPresetsCollection, len(models.DefaultRunPresets()))
+}
+
+func TestMigrationsRunOnceAcrossInstances(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	if _, err := s.database.Collection(SchemaMigrationsCollection).DeleteMany(ctx, bson.M{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Instances sharing the database race to migrate; the lock lets one apply
+	// each migration and the others find nothing pending
+	const instances = 3
+	results := make([][]string, instances)
+	errs := make([]error, instances)
+	var wg sync.WaitGroup
+	for i := 0; i < instances; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = s.migrate(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("instance %d migrate() error = %v", i, errs[i])
+		}
+		total += len(results[i])
+	}
+	if total != len(migrations) {
+		t.Errorf("instances applied %v, want each of the %d migrations once", results, len(migrations))
+	}
+	wantApplied(t, s, len(migrations))
+}
+
+func wantApplied(t *testing.T, s *MongoStorage, want int) {
+	t.Helper()
+	pending, err := s.pendingMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("pendingMigrations() error = %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("%d migrations still pending", len(pending))
+	}
+	wantCount(t, s, SchemaMigrationsCollection, want)
+}
+
+func wantCount(t *testing.T, s *MongoStorage, collection string, want int) {
+	t.Helper()
+	count, err := s.database.Collection(collection).CountDocuments(context.Background(), bson.M{})
+	if err != nil {
+		t.Fatalf("counting %s: %v", collection, err)
+	}
+	if count != int64(want) {
+		t.Errorf("%s has %d documents, want %d", collection, count, want)
+	}
+}
+
+func TestMigrationsRunTwiceOnEmptyDatabase(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	// A database with no collections at all, not even the ones
+	// NewMongoStorage creates indexes on
+	db := s.client.Database(s.database.Name() + "_empty")
+	t.Cleanup(func() { _ = db.Drop(context.Background()) })
+
+	for run := 1; run <= 2; run++ {
+		for _, m := range migrations {
+			if err := m.up(ctx, db); err != nil {
+				t.Fatalf("run %d: migration %s_%s error = %v", run, m.id, m.name, err)
+			}
+		}
+	}
+
+	for collection, want := range map[string]int{
+		ConfigTemplatesCollection: len(models.DefaultConfigTemplates()),
+		RunPresetsCollection:      len(models.DefaultRunPresets()),
+	} {
+		count, err := db.Collection(collection).CountDocuments(ctx, bson.M{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != int64(want) {
+			t.Errorf("%s has %d documents after two runs, want %d", collection, count, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// internal/storage/mongo_anomalies.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+)
+
+// maxAnomalySampleIDs caps the reddit IDs kept on an anomaly record
+const maxAnomalySampleIDs = 10
+
+// FindAuthorBursts groups recent posts by author and fixed window (aligned to
+// the epoch) and returns every author/window with more than minPosts posts
+func (s *MongoStorage) FindAuthorBursts(ctx context.Context, subreddit string, since time.Time, window time.Duration, minPosts int) ([]models.Anomaly, error) {
+	names, err := s.postCollectionNames(ctx, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := query.Build(
+		query.BySubreddits(subreddit),
+		query.CreatedBetween(since, time.Time{}),
+		query.Trusted(bson.M{"author": bson.M{"$nin": []string{"", "[deleted]"}}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	windowMillis := window.Milliseconds()
+	createdMillis := bson.M{"$toLong": "$created_at"}
+
+	match := bson.A{bson.M{"$match": filter}}
+	pipeline := bson.A{
+		bson.M{"$group": bson.M{
+			"_id": bson.M{
+				"author": "$author",
+				"window_start": bson.M{"$toDate": bson.M{
+					"$subtract": []interface{}{createdMillis, bson.M{"$mod": []interface{}{createdMillis, windowMillis}}},
+				}},
+			},
+			"count":      bson.M{"$sum": 1},
+			"sample_ids": bson.M{"$push": "$reddit_id"},
+		}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": minPosts}}},
+		bson.M{"$project": bson.M{
+			"author":       "$_id.author",
+			"window_start": "$_id.window_start",
+			"count":        1,
+			"sample_ids":   bson.M{"$slice": []interface{}{"$sample_ids", maxAnomalySampleIDs}},
+		}},
+		bson.M{"$sort": bson.M{"count": -1}},
+	}
+
+	cursor, err := s.aggregatePosts(ctx, names, match, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Author      string    `bson:"author"`
+		WindowStart time.Time `bson:"window_start"`
+		Count       int       `bson:"count"`
+		SampleIDs   []string  `bson:"sample_ids"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]models.Anomaly, 0, len(results))
+	for _, result := range results {
+		anomalies = append(anomalies, models.Anomaly{
+			Subreddit:   subreddit,
+			Author:      result.Author,
+			WindowStart: result.WindowStart,
+			WindowEnd:   result.WindowStart.Add(window),
+			Count:       result.Count,
+			SampleIDs:   result.SampleIDs,
+		})
+	}
+
+	return anomalies, nil
+}
+
+// UpsertAnomaly records an anomaly keyed by subreddit, author and window start.
+// Re-detecting the same burst refreshes the count but keeps detected_at.
+func (s *MongoStorage) UpsertAnomaly(ctx context.Context, anomaly *models.Anomaly) error {
+	collection := s.database.Collection(AnomaliesCollection)
+
+	filter := bson.M{
+		"subreddit":    anomaly.Subreddit,
+		"author":       anomaly.Author,
+		"window_start": anomaly.WindowStart,
+	}
+
+	if anomaly.DetectedAt.IsZero() {
+		anomaly.DetectedAt = s.clock.Now().UTC()
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"window_end": anomaly.WindowEnd,
+			"count":      anomaly.Count,
+			"sample_ids": anomaly.SampleIDs,
+		},
+		"$setOnInsert": bson.M{
+			"detected_at": anomaly.DetectedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := collection.UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+func (s *MongoStorage) GetAnomalies(ctx context.Context, subreddit string, limit int) ([]models.Anomaly, error) {
+	collection := s.database.Collection(AnomaliesCollection)
+
+	var conditions []query.Condition
+	if subreddit != "" {
+		conditions = append(conditions, query.BySubreddits(subreddit))
+	}
+	filter, err := query.Build(conditions...)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "detected_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var anomalies []models.Anomaly
+	if err := cursor.All(ctx, &anomalies); err != nil {
+		return nil, err
+	}
+
+	return anomalies, nil
+}
+
+// AddTagToAuthorPosts tags every post by author created within [from, to)
+func (s *MongoStorage) AddTagToAuthorPosts(ctx context.Context, subreddit, author string, from, to time.Time, tag string) error {
+	names, err := s.postCollectionNames(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	filter, err := query.Build(query.BySubreddits(subreddit), query.ByAuthor(author), query.CreatedBetween(from, to))
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$addToSet": bson.M{"tags": tag}}
+
+	for _, name := range names {
+		if _, err := s.database.Collection(name).UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
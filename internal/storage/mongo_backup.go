@@ -0,0 +1,300 @@
+// internal/storage/mongo_backup.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// BackupVersion is the backup format ExportBackup writes and RestoreBackup reads
+const BackupVersion = 1
+
+// Restore modes: merge upserts the backup's documents by name and keeps
+// others; replace empties each collection before inserting them
+const (
+	RestoreMerge   = "merge"
+	RestoreReplace = "replace"
+)
+
+// ErrInvalidBackup is returned when a backup fails validation; nothing is written
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// RestoreOptions controls RestoreBackup
+type RestoreOptions struct {
+	Mode string // RestoreMerge (default) or RestoreReplace
+	// DryRun validates the backup and reports the changes without writing
+	DryRun bool
+}
+
+// restoreItem is one backup document and the filter that finds its current
+// version. Replace inserts doc; merge upserts merged, which drops the _id
+// (an existing document's _id can't change) unless the filter matches on it.
+type restoreItem struct {
+	filter bson.M
+	doc    interface{}
+	merged interface{}
+}
+
+// restoreSet is the documents restored into one collection
+type restoreSet struct {
+	name  string
+	items []restoreItem
+}
+
+// ExportBackup reads the collections a backup covers, each sorted by name
+func (s *MongoStorage) ExportBackup(ctx context.Context) (*models.Backup, error) {
+	backup := &models.Backup{
+		Version:           BackupVersion,
+		CreatedAt:         s.clock.Now().UTC(),
+		SubredditConfigs:  []models.SubredditConfig{},
+		SubredditMetadata: []models.SubredditMetadata{},
+		NotificationRules: []models.NotificationRule{},
+		ConfigTemplates:   []models.ConfigTemplate{},
+		Digests:           []models.Digest{},
+	}
+
+	exports := []struct {
+		collection string
+		sortKey    string
+		results    interface{}
+	}{
+		{SubredditConfigCollection, "subreddit_name", &backup.SubredditConfigs},
+		{SubredditMetadataCollection, "subreddit_name", &backup.SubredditMetadata},
+		{NotificationRulesCollection, "name", &backup.NotificationRules},
+		{ConfigTemplatesCollection, "name", &backup.ConfigTemplates},
+		{DigestsCollection, "label", &backup.Digests},
+	}
+	for _, export := range exports {
+		opts := options.Find().SetSort(bson.D{{Key: export.sortKey, Value: 1}, {Key: "_id", Value: 1}})
+		cursor, err := s.database.Collection(export.collection).Find(ctx, bson.M{}, opts)
+		if err != nil {
+			return nil, fmt.Errorf("exporting %s: %w", export.collection, err)
+		}
+		if err := cursor.All(ctx, export.results); err != nil {
+			return nil, fmt.Errorf("exporting %s: %w", export.collection, err)
+		}
+	}
+	return backup, nil
+}
+
+// RestoreBackup validates a backup and writes it collection by collection.
+// Documents keep their created_at, while configs and metadata get a new
+// updated_at so cached listings see the change; merge matches them by name (live configs
+// by subreddit name, soft-deleted ones by _id). The writes run in one
+// transaction, so an error or a crash leaves every collection as it was; on
+// a standalone server, which has no transactions, the collections before a
+// failing one stay written.
+func (s *MongoStorage) RestoreBackup(ctx context.Context, backup *models.Backup, opts RestoreOptions) (models.RestoreReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = RestoreMerge
+	}
+	report := models.RestoreReport{Mode: opts.Mode, DryRun: opts.DryRun, Collections: map[string]models.RestoreCounts{}}
+	if opts.Mode != RestoreMerge && opts.Mode != RestoreReplace {
+		return report, fmt.Errorf("%w: mode must be %s or %s", ErrInvalidBackup, RestoreMerge, RestoreReplace)
+	}
+
+	prepared, err := PrepareBackup(backup, s.clock.Now().UTC())
+	if err != nil {
+		return report, err
+	}
+	sets := restoreItems(prepared)
+
+	restore := func(ctx context.Context) error {
+		// Reset on each attempt, as a transaction may be retried
+		report.Collections = map[string]models.RestoreCounts{}
+		for _, set := range sets {
+			counts, err := s.restoreCollection(ctx, set.name, set.items, opts)
+			report.Collections[set.name] = counts
+			if err != nil {
+				return fmt.Errorf("restoring %s: %w", set.name, err)
+			}
+		}
+		return nil
+	}
+	if opts.DryRun {
+		return report, restore(ctx)
+	}
+	return report, s.WithTransaction(ctx, restore)
+}
+
+// PrepareBackup validates backup and returns the copy a restore writes:
+// normalized by the models' Validate, with restoredAt as the updated_at of
+// configs and metadata. Errors wrap ErrInvalidBackup.
+func PrepareBackup(backup *models.Backup, restoredAt time.Time) (*models.Backup, error) {
+	if backup.Version != BackupVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported (expected %d)", ErrInvalidBackup, backup.Version, BackupVersion)
+	}
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidBackup, fmt.Sprintf(format, args...))
+	}
+	prepared := &models.Backup{Version: backup.Version, CreatedAt: backup.CreatedAt}
+
+	liveConfigs := make(map[string]bool)
+	for _, config := range backup.SubredditConfigs {
+		if err := config.Validate(); err != nil {
+			return nil, invalid("subreddit config %q: %v", config.SubredditName, err)
+		}
+		config.UpdatedAt = restoredAt
+		if config.DeletedAt == nil {
+			if liveConfigs[config.SubredditName] {
+				return nil, invalid("subreddit config %q appears twice", config.SubredditName)
+			}
+			liveConfigs[config.SubredditName] = true
+		} else if config.ID.IsZero() {
+			return nil, invalid("deleted subreddit config %q has no id", config.SubredditName)
+		}
+		prepared.SubredditConfigs = append(prepared.SubredditConfigs, config)
+	}
+
+	seen := make(map[string]bool)
+	for _, meta := range backup.SubredditMetadata {
+		if meta.SubredditName == "" || seen[meta.SubredditName] {
+			return nil, invalid("subreddit metadata %q is missing a name or appears twice", meta.SubredditName)
+		}
+		seen[meta.SubredditName] = true
+		meta.UpdatedAt = restoredAt
+		prepared.SubredditMetadata = append(prepared.SubredditMetadata, meta)
+	}
+
+	seen = make(map[string]bool)
+	for _, rule := range backup.NotificationRules {
+		if err := rule.Validate(); err != nil {
+			return nil, invalid("notification rule %q: %v", rule.Name, err)
+		}
+		if seen[rule.Name] {
+			return nil, invalid("notification rule %q appears twice", rule.Name)
+		}
+		seen[rule.Name] = true
+		prepared.NotificationRules = append(prepared.NotificationRules, rule)
+	}
+
+	seen = make(map[string]bool)
+	for _, template := range backup.ConfigTemplates {
+		if err := template.Validate(); err != nil {
+			return nil, invalid("config template %q: %v", template.Name, err)
+		}
+		if seen[template.Name] {
+			return nil, invalid("config template %q appears twice", template.Name)
+		}
+		seen[template.Name] = true
+		prepared.ConfigTemplates = append(prepared.ConfigTemplates, template)
+	}
+
+	seen = make(map[string]bool)
+	for _, digest := range backup.Digests {
+		if err := digest.Validate(); err != nil {
+			return nil, invalid("digest %q: %v", digest.Label, err)
+		}
+		if seen[digest.Label] {
+			return nil, invalid("digest %q appears twice", digest.Label)
+		}
+		seen[digest.Label] = true
+		prepared.Digests = append(prepared.Digests, digest)
+	}
+	return prepared, nil
+}
+
+// restoreItems builds each collection's documents of a prepared backup in
+// restore order
+func restoreItems(backup *models.Backup) []restoreSet {
+	var configs, metadata, rules, templates, digests []restoreItem
+	for _, config := range backup.SubredditConfigs {
+		item := restoreItem{doc: config, merged: config}
+		if config.DeletedAt == nil {
+			item.filter = liveConfigFilter(config.SubredditName)
+			merged := config
+			merged.ID = primitive.NilObjectID
+			item.merged = merged
+		} else {
+			item.filter = bson.M{"_id": config.ID}
+		}
+		configs = append(configs, item)
+	}
+	for _, meta := range backup.SubredditMetadata {
+		merged := meta
+		merged.ID = primitive.NilObjectID
+		metadata = append(metadata, restoreItem{filter: bson.M{"subreddit_name": meta.SubredditName}, doc: meta, merged: merged})
+	}
+	for _, rule := range backup.NotificationRules {
+		merged := rule
+		merged.ID = primitive.NilObjectID
+		rules = append(rules, restoreItem{filter: bson.M{"name": rule.Name}, doc: rule, merged: merged})
+	}
+	for _, template := range backup.ConfigTemplates {
+		merged := template
+		merged.ID = primitive.NilObjectID
+		templates = append(templates, restoreItem{filter: bson.M{"name": template.Name}, doc: template, merged: merged})
+	}
+	for _, digest := range backup.Digests {
+		merged := digest
+		merged.ID = primitive.NilObjectID
+		digests = append(digests, restoreItem{filter: bson.M{"label": digest.Label}, doc: digest, merged: merged})
+	}
+
+	return []restoreSet{
+		{SubredditConfigCollection, configs},
+		{SubredditMetadataCollection, metadata},
+		{NotificationRulesCollection, rules},
+		{ConfigTemplatesCollection, templates},
+		{DigestsCollection, digests},
+	}
+}
+
+// restoreCollection applies one collection's documents, or only counts the
+// changes on a dry run
+func (s *MongoStorage) restoreCollection(ctx context.Context, name string, items []restoreItem, opts RestoreOptions) (models.RestoreCounts, error) {
+	collection := s.database.Collection(name)
+	var counts models.RestoreCounts
+
+	if opts.Mode == RestoreReplace {
+		existing, err := collection.CountDocuments(ctx, bson.M{})
+		if err != nil {
+			return counts, err
+		}
+		counts.Deleted = int(existing)
+		counts.Inserted = len(items)
+		if opts.DryRun {
+			return counts, nil
+		}
+
+		if _, err := collection.DeleteMany(ctx, bson.M{}); err != nil {
+			return models.RestoreCounts{}, err
+		}
+		if len(items) == 0 {
+			return counts, nil
+		}
+		docs := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			docs = append(docs, item.doc)
+		}
+		_, err = collection.InsertMany(ctx, docs)
+		return counts, err
+	}
+
+	for _, item := range items {
+		existing, err := collection.CountDocuments(ctx, item.filter, options.Count().SetLimit(1))
+		if err != nil {
+			return counts, err
+		}
+		if existing > 0 {
+			counts.Updated++
+		} else {
+			counts.Inserted++
+		}
+		if opts.DryRun {
+			continue
+		}
+		if _, err := collection.ReplaceOne(ctx, item.filter, item.merged, options.Replace().SetUpsert(true)); err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
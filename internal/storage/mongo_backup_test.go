@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// TestBackupRestoreRoundTrip restores a downloaded backup into wiped
+// collections and expects the same documents back, ids included; merge
+// would give live documents new ids, so it restores with replace
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	seedBackupCollections(t, s)
+
+	backup, err := s.ExportBackup(ctx)
+	if err != nil {
+		t.Fatalf("ExportBackup() error = %v", err)
+	}
+	// Restore what an operator downloads, not the in-memory value
+	file, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, collection := range []string{SubredditConfigCollection, SubredditMetadataCollection,
+		NotificationRulesCollection, ConfigTemplatesCollection, DigestsCollection} {
+		if _, err := s.database.Collection(collection).DeleteMany(ctx, bson.M{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var uploaded models.Backup
+	if err := json.Unmarshal(file, &uploaded); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreBackup(ctx, &uploaded, RestoreOptions{Mode: RestoreReplace}); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+
+	restored, err := s.ExportBackup(ctx)
+	if err != nil {
+		t.Fatalf("ExportBackup() after restore error = %v", err)
+	}
+	want, got := normalizeBackup(t, backup), normalizeBackup(t, restored)
+	if !bytes.Equal(got, want) {
+		t.Errorf("restored backup differs from the original\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// seedBackupCollections writes one or more documents to every collection a
+// backup covers, including a soft-deleted config
+func seedBackupCollections(t *testing.T, s *MongoStorage) {
+	t.Helper()
+	ctx := context.Background()
+	for _, config := range []models.SubredditConfig{
+		{SubredditName: "golang", Enabled: true, Schedule: "*/30 * * * *", Labels: []string{"lang"},
+			TagRules: []models.TagRule{{Tag: "release", AnyOfKeywords: []string{"released"}}}},
+		{SubredditName: "rust", Enabled: false, MaxPostAgeDays: 7},
+	} {
+		if err := s.UpsertSubredditConfig(ctx, &config, "test"); err != nil {
+			t.Fatalf("UpsertSubredditConfig() error = %v", err)
+		}
+	}
+	if _, err := s.DeleteSubredditConfig(ctx, "rust", "test"); err != nil {
+		t.Fatalf("DeleteSubredditConfig() error = %v", err)
+	}
+	if err := s.UpsertSubredditMetadata(ctx, &models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("UpsertSubredditMetadata() error = %v", err)
+	}
+	if err := s.UpsertNotificationRule(ctx, &models.NotificationRule{Name: "releases", Enabled: true, Keywords: []string{"release"}, CooldownMinutes: 30}); err != nil {
+		t.Fatalf("UpsertNotificationRule() error = %v", err)
+	}
+	if err := s.UpsertDigest(ctx, &models.Digest{Label: "daily", Enabled: true, Recipients: []string{"ops@example.com"}}); err != nil {
+		t.Fatalf("UpsertDigest() error = %v", err)
+	}
+	// Config templates are seeded by the migrations
+}
+
+// normalizeBackup encodes backup without the fields a restore sets anew: the
+// backup's own timestamp and the updated_at of configs and metadata
+func normalizeBackup(t *testing.T, backup *models.Backup) []byte {
+	t.Helper()
+	normalized := *backup
+	normalized.CreatedAt = time.Time{}
+	normalized.SubredditConfigs = append([]models.SubredditConfig(nil), backup.SubredditConfigs...)
+	for i := range normalized.SubredditConfigs {
+		normalized.SubredditConfigs[i].UpdatedAt = time.Time{}
+	}
+	normalized.SubredditMetadata = append([]models.SubredditMetadata(nil), backup.SubredditMetadata...)
+	for i := range normalized.SubredditMetadata {
+		normalized.SubredditMetadata[i].UpdatedAt = time.Time{}
+	}
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
@@ -0,0 +1,76 @@
+// internal/storage/mongo_captures.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// InsertRawCapture saves a captured response; ExpiresAt must be set
+func (s *MongoStorage) InsertRawCapture(ctx context.Context, capture *models.RawCapture) error {
+	_, err := s.database.Collection(RawCapturesCollection).InsertOne(ctx, capture)
+	return err
+}
+
+// CountRawCaptures counts a subreddit's captures taken at or after since
+func (s *MongoStorage) CountRawCaptures(ctx context.Context, subreddit string, since time.Time) (int64, error) {
+	filter := bson.M{"subreddit": subreddit, "captured_at": bson.M{"$gte": since}}
+	return s.database.Collection(RawCapturesCollection).CountDocuments(ctx, filter)
+}
+
+// GetLatestRawCapture returns a subreddit's newest capture, or nil if none is kept
+func (s *MongoStorage) GetLatestRawCapture(ctx context.Context, subreddit string) (*models.RawCapture, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "captured_at", Value: -1}})
+
+	var capture models.RawCapture
+	err := s.database.Collection(RawCapturesCollection).FindOne(ctx, bson.M{"subreddit": subreddit}, opts).Decode(&capture)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &capture, nil
+}
+
+// InsertQASamples saves posts sampled for QA; ExpiresAt must be set
+func (s *MongoStorage) InsertQASamples(ctx context.Context, samples []models.QASample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(samples))
+	for i := range samples {
+		docs[i] = samples[i]
+	}
+	_, err := s.database.Collection(QASamplesCollection).InsertMany(ctx, docs)
+	return err
+}
+
+// GetQASamples returns up to limit QA samples, newest first; an empty
+// subreddit returns every subreddit's
+func (s *MongoStorage) GetQASamples(ctx context.Context, subreddit string, limit int) ([]models.QASample, error) {
+	filter := bson.M{}
+	if subreddit != "" {
+		filter["subreddit"] = subreddit
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "sampled_at", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := s.database.Collection(QASamplesCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	samples := []models.QASample{}
+	if err := cursor.All(ctx, &samples); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
@@ -0,0 +1,244 @@
+// internal/storage/mongo_config_audit.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ErrSubredditConfigExists is returned when restoring a config whose name has
+// been taken by a new live config in the meantime
+var ErrSubredditConfigExists = errors.New("a live config with this name already exists")
+
+// liveConfigFilter matches the config of a subreddit that is not soft-deleted
+func liveConfigFilter(subredditName string) bson.M {
+	return bson.M{"subreddit_name": subredditName, "deleted_at": bson.M{"$exists": false}}
+}
+
+// subredditConfigFields lists the user-editable fields saved by
+// UpsertSubredditConfig; these are the fields the audit diffs
+func subredditConfigFields(config *models.SubredditConfig) bson.M {
+	return bson.M{
+		"subreddit_name":               config.SubredditName,
+		"enabled":                      config.Enabled,
+		"schedule":                     config.Schedule,
+		"max_posts":                    config.MaxPosts,
+		"priority":                     config.Priority,
+		"description":                  config.Description,
+		"tag_rules":                    config.TagRules,
+		"track_revisions":              config.TrackRevisions,
+		"skip_nsfw":                    config.SkipNSFW,
+		"skip_spoilers":                config.SkipSpoilers,
+		"enrichment_fail_closed":       config.EnrichmentFailClosed,
+		"expected_post_interval_hours": config.ExpectedPostIntervalHours,
+		"max_post_age_days":            config.MaxPostAgeDays,
+		"first_run_mode":               config.FirstRunMode,
+		"labels":                       config.Labels,
+		"paused":                       config.Paused,
+		"push_enabled":                 config.PushEnabled,
+		"adaptive_schedule":            config.AdaptiveSchedule,
+		"sample_rate":                  config.SampleRate,
+		"priority_locked":              config.PriorityLocked,
+		"template":                     config.Template,
+	}
+}
+
+// diffConfigFields returns the fields whose value differs between before and after
+func diffConfigFields(before, after bson.M) map[string]models.ConfigChange {
+	changes := make(map[string]models.ConfigChange)
+	for key, to := range after {
+		from := before[key]
+		if sameConfigValue(from, to) {
+			continue
+		}
+		changes[key] = models.ConfigChange{From: from, To: to}
+	}
+	return changes
+}
+
+// sameConfigValue compares field values, treating nil and empty slices as equal
+// since a stored empty array decodes as an empty slice
+func sameConfigValue(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return isEmptySlice(a) && isEmptySlice(b)
+}
+
+func isEmptySlice(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Slice && v.Len() == 0
+}
+
+// DeleteSubredditConfig soft-deletes a subreddit's config. It reports whether
+// a live config existed. Schedules already registered keep running until restart.
+func (s *MongoStorage) DeleteSubredditConfig(ctx context.Context, subredditName, actor string) (bool, error) {
+	collection := s.database.Collection(SubredditConfigCollection)
+
+	now := s.clock.Now().UTC()
+	update := bson.M{"$set": bson.M{"deleted_at": now, "deleted_by": actor}}
+	result, err := collection.UpdateOne(ctx, liveConfigFilter(subredditName), update)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, nil
+	}
+
+	return true, s.insertConfigAudit(ctx, models.ConfigAudit{
+		SubredditName: subredditName,
+		Action:        models.ConfigAuditDelete,
+		Actor:         actor,
+		CreatedAt:     now,
+	})
+}
+
+// RestoreSubredditConfig undeletes the most recently deleted config of a
+// subreddit. It returns nil if there is none, and ErrSubredditConfigExists if
+// the name has a live config again.
+func (s *MongoStorage) RestoreSubredditConfig(ctx context.Context, subredditName, actor string) (*models.SubredditConfig, error) {
+	existing, err := s.GetSubredditConfig(ctx, subredditName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrSubredditConfigExists
+	}
+
+	collection := s.database.Collection(SubredditConfigCollection)
+
+	now := s.clock.Now().UTC()
+	filter := bson.M{"subreddit_name": subredditName, "deleted_at": bson.M{"$exists": true}}
+	update := bson.M{
+		"$set":   bson.M{"updated_at": now},
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "deleted_at", Value: -1}}).
+		SetReturnDocument(options.After)
+
+	var config models.SubredditConfig
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&config); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		// A concurrent create took the name between the check and the update
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSubredditConfigExists
+		}
+		return nil, err
+	}
+
+	err = s.insertConfigAudit(ctx, models.ConfigAudit{
+		SubredditName: subredditName,
+		Action:        models.ConfigAuditRestore,
+		Actor:         actor,
+		CreatedAt:     now,
+	})
+	return &config, err
+}
+
+// GetDeletedSubredditConfigs lists soft-deleted configs, most recently deleted first
+func (s *MongoStorage) GetDeletedSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error) {
+	collection := s.database.Collection(SubredditConfigCollection)
+
+	filter := bson.M{"deleted_at": bson.M{"$exists": true}}
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var configs []models.SubredditConfig
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+// PurgeDeletedSubredditConfigs permanently removes configs soft-deleted
+// before the cutoff and returns how many were removed
+func (s *MongoStorage) PurgeDeletedSubredditConfigs(ctx context.Context, deletedBefore time.Time, actor string) (int, error) {
+	collection := s.database.Collection(SubredditConfigCollection)
+
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+	opts := options.Find().SetProjection(bson.M{"subreddit_name": 1})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	var expired []models.SubredditConfig
+	if err := cursor.All(ctx, &expired); err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now().UTC()
+	purged := 0
+	for _, config := range expired {
+		result, err := collection.DeleteOne(ctx, bson.M{"_id": config.ID})
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount == 0 {
+			continue
+		}
+		purged++
+
+		err = s.insertConfigAudit(ctx, models.ConfigAudit{
+			SubredditName: config.SubredditName,
+			Action:        models.ConfigAuditPurge,
+			Actor:         actor,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// GetConfigAudit returns the newest audit entries, optionally for one subreddit
+func (s *MongoStorage) GetConfigAudit(ctx context.Context, subredditName string, limit int) ([]models.ConfigAudit, error) {
+	collection := s.database.Collection(ConfigAuditCollection)
+
+	filter := bson.M{}
+	if subredditName != "" {
+		filter["subreddit_name"] = subredditName
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.ConfigAudit
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *MongoStorage) insertConfigAudit(ctx context.Context, audit models.ConfigAudit) error {
+	_, err := s.database.Collection(ConfigAuditCollection).InsertOne(ctx, audit)
+	return err
+}
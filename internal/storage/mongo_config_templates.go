@@ -0,0 +1,89 @@
+// internal/storage/mongo_config_templates.go
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// GetConfigTemplates lists all config templates by name
+func (s *MongoStorage) GetConfigTemplates(ctx context.Context) ([]models.ConfigTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := s.database.Collection(ConfigTemplatesCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	templates := []models.ConfigTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetConfigTemplate returns a template by name, or nil when there is none
+func (s *MongoStorage) GetConfigTemplate(ctx context.Context, name string) (*models.ConfigTemplate, error) {
+	var template models.ConfigTemplate
+	err := s.database.Collection(ConfigTemplatesCollection).FindOne(ctx, bson.M{"name": name}).Decode(&template)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// UpsertConfigTemplate validates and saves a template. Configs created from
+// an earlier version keep their values.
+func (s *MongoStorage) UpsertConfigTemplate(ctx context.Context, template *models.ConfigTemplate) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("invalid config template: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	template.UpdatedAt = now
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = now
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":                         template.Name,
+			"description":                  template.Description,
+			"schedule":                     template.Schedule,
+			"max_posts":                    template.MaxPosts,
+			"priority":                     template.Priority,
+			"labels":                       template.Labels,
+			"tag_rules":                    template.TagRules,
+			"track_revisions":              template.TrackRevisions,
+			"skip_nsfw":                    template.SkipNSFW,
+			"skip_spoilers":                template.SkipSpoilers,
+			"expected_post_interval_hours": template.ExpectedPostIntervalHours,
+			"updated_at":                   template.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": template.CreatedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := s.database.Collection(ConfigTemplatesCollection).UpdateOne(ctx, bson.M{"name": template.Name}, update, opts)
+	return err
+}
+
+// DeleteConfigTemplate deletes a template and reports whether it existed
+func (s *MongoStorage) DeleteConfigTemplate(ctx context.Context, name string) (bool, error) {
+	result, err := s.database.Collection(ConfigTemplatesCollection).DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,206 @@
+// internal/storage/mongo_consumers.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+)
+
+// GetConsumer returns a consumer with its cursors, or nil when there is none
+func (s *MongoStorage) GetConsumer(ctx context.Context, name string) (*models.Consumer, error) {
+	filter, err := query.Build(query.Equals("_id", name))
+	if err != nil {
+		return nil, err
+	}
+	var consumer models.Consumer
+	err = s.database.Collection(ConsumersCollection).FindOne(ctx, filter).Decode(&consumer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &consumer, nil
+}
+
+// GetConsumers lists every consumer by name
+func (s *MongoStorage) GetConsumers(ctx context.Context) ([]models.Consumer, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := s.database.Collection(ConsumersCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	consumers := []models.Consumer{}
+	if err := cursor.All(ctx, &consumers); err != nil {
+		return nil, err
+	}
+	return consumers, nil
+}
+
+// SaveConsumer creates a consumer or changes its mode; cursors are kept
+func (s *MongoStorage) SaveConsumer(ctx context.Context, consumer *models.Consumer) error {
+	now := s.clock.Now().UTC()
+	consumer.UpdatedAt = now
+	update := bson.M{
+		"$set":         bson.M{"mode": consumer.Mode, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+	filter, err := query.Build(query.Equals("_id", consumer.Name))
+	if err != nil {
+		return err
+	}
+	_, err = s.database.Collection(ConsumersCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// DeleteConsumer removes a consumer and its cursors; it reports whether it existed
+func (s *MongoStorage) DeleteConsumer(ctx context.Context, name string) (bool, error) {
+	filter, err := query.Build(query.Equals("_id", name))
+	if err != nil {
+		return false, err
+	}
+	result, err := s.database.Collection(ConsumersCollection).DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// GetPostsInsertedAfter returns up to limit posts of a subreddit inserted
+// after the position and before before, in insertion order. Every partition
+// is read, as a post's partition follows its created_at, not its insertion.
+func (s *MongoStorage) GetPostsInsertedAfter(ctx context.Context, subreddit string, after models.ConsumerPosition, before time.Time, limit int) ([]models.Post, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return nil, observe(OpGetPosts, err)
+	}
+
+	conditions := []query.Condition{query.BySubreddits(subreddit), query.Between("inserted_at", time.Time{}, before)}
+	if !after.InsertedAt.IsZero() {
+		// The position was issued by the server, not typed by a user
+		conditions = append(conditions, query.Trusted(bson.M{"$or": bson.A{
+			bson.M{"inserted_at": bson.M{"$gt": after.InsertedAt}},
+			bson.M{"inserted_at": after.InsertedAt, "_id": bson.M{"$gt": after.PostID}},
+		}}))
+	}
+	filter, err := query.Build(conditions...)
+	if err != nil {
+		return nil, err
+	}
+	sort := bson.D{{Key: "inserted_at", Value: 1}, {Key: "_id", Value: 1}}
+
+	var cursor *mongo.Cursor
+	if len(names) == 1 {
+		opts := options.Find().SetSort(sort).SetLimit(int64(limit))
+		cursor, err = s.database.Collection(names[0]).Find(ctx, filter, opts)
+	} else {
+		stages := bson.A{bson.M{"$match": filter}, bson.M{"$sort": sort}, bson.M{"$limit": limit}}
+		cursor, err = s.aggregatePosts(ctx, names, stages, stages[1:])
+	}
+	if err != nil {
+		return nil, observe(OpGetPosts, err)
+	}
+	defer cursor.Close(ctx)
+
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, observe(OpGetPosts, err)
+	}
+	return posts, nil
+}
+
+// AdvanceConsumerCursor moves a consumer's cursor for subreddit from one
+// position to another. It reports false, changing nothing, when the cursor
+// is no longer at from because another read advanced it first.
+func (s *MongoStorage) AdvanceConsumerCursor(ctx context.Context, name, subreddit string, from, to models.ConsumerPosition) (bool, error) {
+	key, err := consumerKey(subreddit)
+	if err != nil {
+		return false, err
+	}
+
+	at := query.Trusted(bson.M{"cursors." + key: bson.M{"$exists": false}})
+	if !from.InsertedAt.IsZero() {
+		at = query.Trusted(bson.M{"cursors." + key: from})
+	}
+	filter, err := query.Build(query.Equals("_id", name), at)
+	if err != nil {
+		return false, err
+	}
+	update := bson.M{"$set": bson.M{"cursors." + key: to, "updated_at": s.clock.Now().UTC()}}
+
+	result, err := s.database.Collection(ConsumersCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// SetConsumerPending records the batch last delivered for subreddit in ack
+// mode, replacing an unacked one. It reports false when the consumer is gone.
+func (s *MongoStorage) SetConsumerPending(ctx context.Context, name, subreddit string, batch models.ConsumerBatch) (bool, error) {
+	key, err := consumerKey(subreddit)
+	if err != nil {
+		return false, err
+	}
+
+	filter, err := query.Build(query.Equals("_id", name))
+	if err != nil {
+		return false, err
+	}
+	update := bson.M{"$set": bson.M{"pending." + key: batch, "updated_at": s.clock.Now().UTC()}}
+	result, err := s.database.Collection(ConsumersCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// AckConsumerBatch moves the cursor for subreddit past the pending batch
+// with token and clears it. It reports false when no batch with that token
+// is pending, e.g. it was acked already or replaced by a later read.
+func (s *MongoStorage) AckConsumerBatch(ctx context.Context, name, subreddit, token string) (bool, error) {
+	key, err := consumerKey(subreddit)
+	if err != nil {
+		return false, err
+	}
+
+	// The pipeline update copies the position within the document atomically
+	filter, err := query.Build(query.Equals("_id", name), query.Equals("pending."+key+".token", token))
+	if err != nil {
+		return false, err
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"cursors." + key: "$pending." + key + ".position",
+			"updated_at":     s.clock.Now().UTC(),
+		}}},
+		{{Key: "$unset", Value: "pending." + key}},
+	}
+	result, err := s.database.Collection(ConsumersCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// consumerKey checks that a subreddit name can be used as a field name
+func consumerKey(subreddit string) (string, error) {
+	if strings.Contains(subreddit, ".") || query.CheckPath(subreddit) != nil {
+		return "", fmt.Errorf("%w: subreddit name %q", query.ErrInvalid, subreddit)
+	}
+	return subreddit, nil
+}
@@ -0,0 +1,90 @@
+// internal/storage/mongo_digests.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// Digest operations
+func (s *MongoStorage) GetAllDigests(ctx context.Context) ([]models.Digest, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "label", Value: 1}})
+	cursor, err := s.database.Collection(DigestsCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := []models.Digest{}
+	if err := cursor.All(ctx, &digests); err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+func (s *MongoStorage) GetDigest(ctx context.Context, label string) (*models.Digest, error) {
+	var digest models.Digest
+	err := s.database.Collection(DigestsCollection).FindOne(ctx, bson.M{"label": label}).Decode(&digest)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// UpsertDigest validates and saves a digest; last_sent_at is owned by
+// MarkDigestSent and left untouched
+func (s *MongoStorage) UpsertDigest(ctx context.Context, digest *models.Digest) error {
+	if err := digest.Validate(); err != nil {
+		return fmt.Errorf("invalid digest: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	digest.UpdatedAt = now
+	if digest.CreatedAt.IsZero() {
+		digest.CreatedAt = now
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"label":       digest.Label,
+			"enabled":     digest.Enabled,
+			"schedule":    digest.Schedule,
+			"recipients":  digest.Recipients,
+			"webhook_url": digest.WebhookURL,
+			"send_empty":  digest.SendEmpty,
+			"updated_at":  digest.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": digest.CreatedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := s.database.Collection(DigestsCollection).UpdateOne(ctx, bson.M{"label": digest.Label}, update, opts)
+	return err
+}
+
+// DeleteDigest removes a digest and reports whether it existed
+func (s *MongoStorage) DeleteDigest(ctx context.Context, label string) (bool, error) {
+	result, err := s.database.Collection(DigestsCollection).DeleteOne(ctx, bson.M{"label": label})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// MarkDigestSent records when a digest was last delivered
+func (s *MongoStorage) MarkDigestSent(ctx context.Context, label string, sentAt time.Time) error {
+	update := bson.M{"$set": bson.M{"last_sent_at": sentAt}}
+	_, err := s.database.Collection(DigestsCollection).UpdateOne(ctx, bson.M{"label": label}, update)
+	return err
+}
@@ -0,0 +1,106 @@
+// internal/storage/mongo_document_sizes.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// FindOversizedPosts returns up to limit posts whose BSON size exceeds
+// minBytes, largest first. It reads every post, one collection at a time
+// within timeout each; $bsonSize needs MongoDB 4.4 or later.
+func (s *MongoStorage) FindOversizedPosts(ctx context.Context, minBytes, limit int, timeout time.Duration) ([]models.OversizedPost, error) {
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := bson.A{
+		bson.M{"$project": bson.M{
+			"reddit_id":     1,
+			"subreddit":     1,
+			"bytes":         bson.M{"$bsonSize": "$$ROOT"},
+			"flair_history": bson.M{"$size": bson.M{"$ifNull": bson.A{"$flair_history", bson.A{}}}},
+		}},
+		bson.M{"$match": bson.M{"bytes": bson.M{"$gt": minBytes}}},
+		bson.M{"$sort": bson.M{"bytes": -1}},
+		bson.M{"$limit": limit},
+	}
+	aggregateOpts := options.Aggregate().SetAllowDiskUse(true).SetMaxTime(timeout)
+
+	var oversized []models.OversizedPost
+	for _, name := range names {
+		cursor, err := s.database.Collection(name).Aggregate(ctx, pipeline, aggregateOpts)
+		if err != nil {
+			return nil, fmt.Errorf("sizing %s: %w", name, err)
+		}
+		var found []models.OversizedPost
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, fmt.Errorf("sizing %s: %w", name, err)
+		}
+		for i := range found {
+			found[i].Collection = name
+		}
+		oversized = append(oversized, found...)
+	}
+
+	sort.Slice(oversized, func(i, j int) bool { return oversized[i].Bytes > oversized[j].Bytes })
+	if len(oversized) > limit {
+		oversized = oversized[:limit]
+	}
+	return oversized, nil
+}
+
+// TrimPostHistory moves all but the newest keep entries of a post's
+// flair_history to the flair_history_archive collection and returns how
+// many it moved. Entries are archived before the trim, so a failure in
+// between leaves duplicates, never losses.
+func (s *MongoStorage) TrimPostHistory(ctx context.Context, collection string, post models.OversizedPost, keep int) (int, error) {
+	if collection != SubredditPostsCollection && !partitionPattern.MatchString(collection) {
+		return 0, fmt.Errorf("%q is not a post collection", collection)
+	}
+	if keep < 0 {
+		return 0, fmt.Errorf("keep must not be negative")
+	}
+
+	var stored struct {
+		FlairHistory []models.FlairChange `bson:"flair_history"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"flair_history": 1})
+	if err := s.database.Collection(collection).FindOne(ctx, bson.M{"_id": post.ID}, opts).Decode(&stored); err != nil {
+		return 0, err
+	}
+	excess := len(stored.FlairHistory) - keep
+	if excess <= 0 {
+		return 0, nil
+	}
+
+	now := s.clock.Now().UTC()
+	archived := make([]interface{}, 0, excess)
+	for _, change := range stored.FlairHistory[:excess] {
+		archived = append(archived, models.ArchivedFlairChange{
+			PostID:     post.ID,
+			RedditID:   post.RedditID,
+			Change:     change,
+			ArchivedAt: now,
+		})
+	}
+	if _, err := s.database.Collection(FlairArchiveCollection).InsertMany(ctx, archived); err != nil {
+		return 0, err
+	}
+
+	// An empty $each with $slice keeps the newest entries, including any
+	// pushed since the read
+	update := bson.M{"$push": bson.M{"flair_history": bson.M{"$each": bson.A{}, "$slice": -keep}}}
+	if _, err := s.database.Collection(collection).UpdateOne(ctx, bson.M{"_id": post.ID}, update); err != nil {
+		return 0, err
+	}
+	return excess, nil
+}
@@ -0,0 +1,176 @@
+// internal/storage/mongo_explain.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// queryShape is one canonical query run through explain
+type queryShape struct {
+	name       string
+	collection string
+	filter     bson.D
+	sort       bson.D
+}
+
+// canonicalQueryShapes mirrors the filters and sorts the storage methods issue.
+// Values are placeholders; only the shape matters to the planner.
+func canonicalQueryShapes() []queryShape {
+	since := time.Now().UTC().Add(-24 * time.Hour)
+	return []queryShape{
+		{
+			name:       "posts_by_subreddit",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "subreddit", Value: "golang"}},
+			sort:       bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			name:       "recent_posts",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "subreddit", Value: "golang"}, {Key: "created_at", Value: bson.M{"$gte": since}}},
+			sort:       bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			name:       "posts_by_tag",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "subreddit", Value: "golang"}, {Key: "tags", Value: bson.M{"$all": bson.A{"release"}}}},
+			sort:       bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			name:       "posts_by_subreddits",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "subreddit", Value: bson.M{"$in": bson.A{"golang", "rust", "python"}}}, {Key: "created_at", Value: bson.M{"$gte": since}}},
+			sort:       bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:       "top_posts_by_subreddit",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "subreddit", Value: "golang"}},
+			sort:       bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:       "inserted_posts_by_subreddit",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "subreddit", Value: "golang"}},
+			sort:       bson.D{{Key: "inserted_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:       "posts_by_author",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "author", Value: "spez"}, {Key: "score", Value: bson.M{"$gte": 10}}},
+			sort:       bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:       "post_by_reddit_id",
+			collection: SubredditPostsCollection,
+			filter:     bson.D{{Key: "reddit_id", Value: "t3_abc"}},
+		},
+		{
+			name:       "active_configs",
+			collection: SubredditConfigCollection,
+			filter:     bson.D{{Key: "enabled", Value: true}, {Key: "deleted_at", Value: bson.M{"$exists": false}}},
+			sort:       bson.D{{Key: "priority", Value: -1}, {Key: "subreddit_name", Value: 1}},
+		},
+		{
+			name:       "anomalies_by_subreddit",
+			collection: AnomaliesCollection,
+			filter:     bson.D{{Key: "subreddit", Value: "golang"}},
+			sort:       bson.D{{Key: "detected_at", Value: -1}},
+		},
+	}
+}
+
+// ExplainQueryShapes runs explain (queryPlanner verbosity) on the canonical
+// query shapes and reports whether the winning plan uses an index
+func (s *MongoStorage) ExplainQueryShapes(ctx context.Context) ([]models.QueryPlanReport, error) {
+	shapes := canonicalQueryShapes()
+	reports := make([]models.QueryPlanReport, 0, len(shapes))
+
+	// With partitioning, post shapes are explained against the newest partition
+	postsCollection := SubredditPostsCollection
+	if names, err := s.postCollectionNames(ctx, time.Time{}, time.Time{}); err != nil {
+		return nil, err
+	} else if len(names) > 1 {
+		postsCollection = names[0]
+	}
+
+	for _, shape := range shapes {
+		if shape.collection == SubredditPostsCollection {
+			shape.collection = postsCollection
+		}
+		find := bson.D{
+			{Key: "find", Value: shape.collection},
+			{Key: "filter", Value: shape.filter},
+			{Key: "limit", Value: 50},
+		}
+		if len(shape.sort) > 0 {
+			find = append(find, bson.E{Key: "sort", Value: shape.sort})
+		}
+		command := bson.D{
+			{Key: "explain", Value: find},
+			{Key: "verbosity", Value: "queryPlanner"},
+		}
+
+		var result explainResult
+		if err := s.database.RunCommand(ctx, command).Decode(&result); err != nil {
+			return nil, fmt.Errorf("explain %s: %w", shape.name, err)
+		}
+
+		report := models.QueryPlanReport{
+			Name:       shape.name,
+			Collection: shape.collection,
+		}
+		winningPlan := result.QueryPlanner.WinningPlan
+		// Slot-based engine plans nest the classic plan under queryPlan
+		if winningPlan.QueryPlan != nil {
+			winningPlan = *winningPlan.QueryPlan
+		}
+		winningPlan.collect(&report)
+		for _, stage := range report.Stages {
+			if stage == "IXSCAN" || stage == "IDHACK" || stage == "EXPRESS_IXSCAN" {
+				report.IndexUsed = true
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// explainResult is the part of an explain response the report needs
+type explainResult struct {
+	QueryPlanner struct {
+		WinningPlan explainPlan `bson:"winningPlan"`
+	} `bson:"queryPlanner"`
+}
+
+type explainPlan struct {
+	Stage       string        `bson:"stage"`
+	IndexName   string        `bson:"indexName"`
+	InputStage  *explainPlan  `bson:"inputStage"`
+	InputStages []explainPlan `bson:"inputStages"`
+	QueryPlan   *explainPlan  `bson:"queryPlan"`
+}
+
+// collect walks the plan tree, recording stage names and index names
+func (p explainPlan) collect(report *models.QueryPlanReport) {
+	if p.Stage != "" {
+		report.Stages = append(report.Stages, p.Stage)
+	}
+	if p.IndexName != "" {
+		report.Indexes = append(report.Indexes, p.IndexName)
+	}
+	if p.InputStage != nil {
+		p.InputStage.collect(report)
+	}
+	for _, input := range p.InputStages {
+		input.collect(report)
+	}
+}
@@ -0,0 +1,44 @@
+// internal/storage/mongo_flair.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// MaxFlairHistory caps the flair changes kept per post (FLAIR_HISTORY_CAP);
+// every push drops the oldest ones beyond it
+var MaxFlairHistory = 20
+
+// addFlairChange extends a post upsert to append the change to flair_history
+// and set flair_changed_at
+func addFlairChange(update bson.M, oldFlair, newFlair string, observedAt time.Time) {
+	update["$set"].(bson.M)["flair_changed_at"] = observedAt
+	update["$push"] = bson.M{"flair_history": bson.M{
+		"$each":  bson.A{models.FlairChange{Old: oldFlair, New: newFlair, ObservedAt: observedAt}},
+		"$slice": -MaxFlairHistory,
+	}}
+}
+
+// GetPostsWithFlairChange returns a subreddit's posts whose flair changed at
+// or after since, newest first; a zero since returns every post whose flair
+// ever changed. Past MaxUnboundedResults posts it returns ErrTruncated.
+func (s *MongoStorage) GetPostsWithFlairChange(ctx context.Context, subreddit string, since time.Time) ([]models.Post, error) {
+	if since.IsZero() {
+		since = time.Unix(0, 0).UTC()
+	}
+	page, err := s.FindPosts(ctx, PostFilter{
+		Subreddit:         subreddit,
+		FlairChangedSince: since,
+		IncludeDeleted:    true,
+		IncludeRemoved:    true,
+	})
+	if err == nil && page.Truncated {
+		err = truncatedError()
+	}
+	return page.Posts, err
+}
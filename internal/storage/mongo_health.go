@@ -0,0 +1,90 @@
+// internal/storage/mongo_health.go
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+
+	"reddit-orchestrator/internal/metrics"
+)
+
+// HealthInfo describes the storage connection as seen by one health check
+type HealthInfo struct {
+	Latency       time.Duration `json:"latency"` // Round trip of the hello command
+	Primary       bool          `json:"primary"` // Connected to a writable primary (or standalone)
+	ServerVersion string        `json:"server_version"`
+	Pool          PoolStats     `json:"pool"`
+}
+
+// PoolStats are connection pool counters gathered from driver pool events
+type PoolStats struct {
+	Open             int64 `json:"open"`
+	InUse            int64 `json:"in_use"`
+	Created          int64 `json:"created"`
+	CheckoutFailures int64 `json:"checkout_failures"`
+}
+
+// poolCounters tracks pool events for PoolStats
+type poolCounters struct {
+	created, closed, checkedOut, checkedIn, checkoutFailed atomic.Int64
+}
+
+func (p *poolCounters) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				p.created.Add(1)
+			case event.ConnectionClosed:
+				p.closed.Add(1)
+			case event.GetSucceeded:
+				p.checkedOut.Add(1)
+			case event.ConnectionReturned:
+				p.checkedIn.Add(1)
+			case event.GetFailed:
+				p.checkoutFailed.Add(1)
+			}
+		},
+	}
+}
+
+func (p *poolCounters) stats() PoolStats {
+	return PoolStats{
+		Open:             p.created.Load() - p.closed.Load(),
+		InUse:            p.checkedOut.Load() - p.checkedIn.Load(),
+		Created:          p.created.Load(),
+		CheckoutFailures: p.checkoutFailed.Load(),
+	}
+}
+
+// HealthInfo times a hello round trip and reports the server's role, version
+// and our pool usage. The latency is also exported as a gauge.
+func (s *MongoStorage) HealthInfo(ctx context.Context) (HealthInfo, error) {
+	var hello struct {
+		IsWritablePrimary bool `bson:"isWritablePrimary"`
+	}
+	start := time.Now()
+	if err := s.database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return HealthInfo{}, err
+	}
+	latency := time.Since(start)
+	metrics.SetStorageLatency(latency)
+
+	var buildInfo struct {
+		Version string `bson:"version"`
+	}
+	if err := s.database.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo); err != nil {
+		return HealthInfo{}, err
+	}
+
+	return HealthInfo{
+		Latency:       latency,
+		Primary:       hello.IsWritablePrimary,
+		ServerVersion: buildInfo.Version,
+		Pool:          s.pool.stats(),
+	}, nil
+}
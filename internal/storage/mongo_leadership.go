@@ -0,0 +1,67 @@
+// internal/storage/mongo_leadership.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ClaimLeadership takes or renews the named lease for instanceID when it is
+// free, expired or already held by instanceID, and returns the lease as it
+// stands afterwards. The caller leads if the returned holder is instanceID.
+func (s *MongoStorage) ClaimLeadership(ctx context.Context, name, instanceID string, now time.Time, ttl time.Duration) (*models.LeaderLease, error) {
+	collection := s.database.Collection(LeadershipCollection)
+
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder_id": instanceID},
+			bson.M{"expires_at": bson.M{"$lte": now}},
+		},
+	}
+	// acquired_at only moves when the holder changes
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"acquired_at": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$holder_id", instanceID}}, "$acquired_at", now,
+			}},
+			"holder_id":  instanceID,
+			"renewed_at": now,
+			"expires_at": now.Add(ttl),
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var lease models.LeaderLease
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&lease)
+	if err == nil {
+		return &lease, nil
+	}
+	// The lease exists and is held by another instance, so the upsert
+	// collided on _id
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	if err := collection.FindOne(ctx, bson.M{"_id": name}).Decode(&lease); err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+// ReleaseLeadership expires the lease if instanceID holds it, so another
+// instance can take over without waiting out the TTL
+func (s *MongoStorage) ReleaseLeadership(ctx context.Context, name, instanceID string) error {
+	collection := s.database.Collection(LeadershipCollection)
+
+	filter := bson.M{"_id": name, "holder_id": instanceID}
+	update := bson.M{"$set": bson.M{"expires_at": s.clock.Now().UTC()}}
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,154 @@
+// internal/storage/mongo_metadata_repair.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+const (
+	RepairReasonFuture = "last_scraped_at_in_future"
+	RepairReasonBehind = "last_scraped_at_behind_newest_post"
+)
+
+// FindInconsistentMetadata scans subreddit metadata for last_scraped_at values
+// that are in the future or lag the newest stored post by more than margin.
+// Each proposal resets last_scraped_at to the newest post's created_at.
+func (s *MongoStorage) FindInconsistentMetadata(ctx context.Context, now time.Time, margin time.Duration) ([]models.MetadataRepair, error) {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	opts := options.Find().SetProjection(bson.M{"subreddit_name": 1, "last_scraped_at": 1})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var metadatas []models.SubredditMetadata
+	if err := cursor.All(ctx, &metadatas); err != nil {
+		return nil, err
+	}
+
+	var repairs []models.MetadataRepair
+	for _, metadata := range metadatas {
+		newest, err := s.newestPostCreatedAt(ctx, metadata.SubredditName)
+		if err != nil {
+			return nil, err
+		}
+		if newest.After(now) {
+			newest = now
+		}
+
+		lastScraped := metadata.LastScrapedAt
+		var reason string
+		switch {
+		case lastScraped.After(now):
+			reason = RepairReasonFuture
+		case !newest.IsZero() && lastScraped.Before(newest.Add(-margin)):
+			reason = RepairReasonBehind
+		default:
+			continue
+		}
+
+		repairs = append(repairs, models.MetadataRepair{
+			SubredditName: metadata.SubredditName,
+			Reason:        reason,
+			Before:        lastScraped,
+			After:         newest,
+		})
+	}
+
+	return repairs, nil
+}
+
+// ApplyMetadataRepair sets last_scraped_at to repair.After, but only if it
+// still equals repair.Before so a concurrent scrape is not overwritten
+func (s *MongoStorage) ApplyMetadataRepair(ctx context.Context, repair models.MetadataRepair) (bool, error) {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	filter := bson.M{
+		"subreddit_name":  repair.SubredditName,
+		"last_scraped_at": repair.Before,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"last_scraped_at": repair.After,
+			"updated_at":      s.clock.Now().UTC(),
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// CorrectScrapeCursor sets last_scraped_at to correction.After and records
+// the correction, but only if the cursor still equals correction.Before so a
+// concurrent scrape is not overwritten
+func (s *MongoStorage) CorrectScrapeCursor(ctx context.Context, subredditName string, correction models.CursorCorrection) (bool, error) {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	filter := bson.M{
+		"subreddit_name":  subredditName,
+		"last_scraped_at": correction.Before,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"last_scraped_at":   correction.After,
+			"cursor_correction": correction,
+			"updated_at":        s.clock.Now().UTC(),
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// RecordCursorProbe stores when the subreddit's cursor was last probed
+func (s *MongoStorage) RecordCursorProbe(ctx context.Context, subredditName string, at time.Time) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"subreddit_name": subredditName}, bson.M{
+		"$set": bson.M{"cursor_probed_at": at, "updated_at": s.clock.Now().UTC()},
+	})
+	return err
+}
+
+// newestPostCreatedAt returns the created_at of the subreddit's newest stored post
+func (s *MongoStorage) newestPostCreatedAt(ctx context.Context, subreddit string) (time.Time, error) {
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.M{"created_at": 1})
+
+	var newest time.Time
+	for _, name := range names {
+		var post models.Post
+		err := s.database.Collection(name).FindOne(ctx, bson.M{"subreddit": subreddit}, opts).Decode(&post)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if post.CreatedAt.After(newest) {
+			newest = post.CreatedAt
+		}
+	}
+	return newest, nil
+}
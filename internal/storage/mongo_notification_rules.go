@@ -0,0 +1,243 @@
+// internal/storage/mongo_notification_rules.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+)
+
+// Notification rule operations
+func (s *MongoStorage) GetAllNotificationRules(ctx context.Context) ([]models.NotificationRule, error) {
+	return s.findNotificationRules(ctx, bson.M{})
+}
+
+func (s *MongoStorage) GetActiveNotificationRules(ctx context.Context) ([]models.NotificationRule, error) {
+	return s.findNotificationRules(ctx, bson.M{"enabled": true})
+}
+
+func (s *MongoStorage) findNotificationRules(ctx context.Context, filter bson.M) ([]models.NotificationRule, error) {
+	collection := s.database.Collection(NotificationRulesCollection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []models.NotificationRule
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *MongoStorage) GetNotificationRule(ctx context.Context, name string) (*models.NotificationRule, error) {
+	collection := s.database.Collection(NotificationRulesCollection)
+
+	filter, err := query.Build(query.Equals("name", name))
+	if err != nil {
+		return nil, err
+	}
+	var rule models.NotificationRule
+	err = collection.FindOne(ctx, filter).Decode(&rule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// UpsertNotificationRule validates and saves a rule; last_fired_at is owned by
+// ClaimNotificationRule and left untouched
+func (s *MongoStorage) UpsertNotificationRule(ctx context.Context, rule *models.NotificationRule) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("invalid notification rule: %w", err)
+	}
+
+	collection := s.database.Collection(NotificationRulesCollection)
+
+	now := s.clock.Now().UTC()
+	rule.UpdatedAt = now
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":             rule.Name,
+			"enabled":          rule.Enabled,
+			"subreddits":       rule.Subreddits,
+			"keywords":         rule.Keywords,
+			"title_regex":      rule.TitleRegex,
+			"body_regex":       rule.BodyRegex,
+			"channel":          rule.Channel,
+			"webhook_url":      rule.WebhookURL,
+			"cooldown_minutes": rule.CooldownMinutes,
+			"updated_at":       rule.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": rule.CreatedAt,
+		},
+	}
+
+	filter, err := query.Build(query.Equals("name", rule.Name))
+	if err != nil {
+		return err
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err = collection.UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+func (s *MongoStorage) DeleteNotificationRule(ctx context.Context, name string) error {
+	collection := s.database.Collection(NotificationRulesCollection)
+
+	filter, err := query.Build(query.Equals("name", name))
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, filter)
+	return err
+}
+
+// ClaimNotificationRule sets last_fired_at to now if the rule's cooldown has
+// elapsed. The conditional update keeps concurrent runs from both sending.
+func (s *MongoStorage) ClaimNotificationRule(ctx context.Context, name string, now time.Time, cooldown time.Duration) (bool, error) {
+	collection := s.database.Collection(NotificationRulesCollection)
+
+	filter, err := query.Build(
+		query.Equals("name", name),
+		query.Trusted(bson.M{"$or": bson.A{
+			bson.M{"last_fired_at": bson.M{"$exists": false}},
+			bson.M{"last_fired_at": bson.M{"$lte": now.Add(-cooldown)}},
+		}}),
+	)
+	if err != nil {
+		return false, err
+	}
+	update := bson.M{"$set": bson.M{"last_fired_at": now}}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// ReleaseNotificationRule undoes the claim made at claimedAt, so a rule whose
+// send failed is not cooling down. A newer claim is left alone.
+func (s *MongoStorage) ReleaseNotificationRule(ctx context.Context, name string, claimedAt time.Time) error {
+	collection := s.database.Collection(NotificationRulesCollection)
+
+	filter, err := query.Build(query.Equals("name", name), query.Trusted(bson.M{"last_fired_at": claimedAt}))
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"last_fired_at": ""}})
+	return err
+}
+
+// Notification log operations
+func (s *MongoStorage) InsertNotificationLogs(ctx context.Context, entries []models.NotificationLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		documents = append(documents, entry)
+	}
+
+	_, err := s.database.Collection(NotificationLogCollection).InsertMany(ctx, documents)
+	return err
+}
+
+// GetNotificationLog returns the newest log entries, optionally for one rule
+func (s *MongoStorage) GetNotificationLog(ctx context.Context, ruleName string, limit int) ([]models.NotificationLog, error) {
+	collection := s.database.Collection(NotificationLogCollection)
+
+	filter := bson.M{}
+	if ruleName != "" {
+		filter["rule_name"] = ruleName
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.NotificationLog
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// InsertWebhookDelivery records one webhook delivery attempt
+func (s *MongoStorage) InsertWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := s.database.Collection(WebhookDeliveriesCollection).InsertOne(ctx, delivery)
+	return err
+}
+
+// GetWebhookDeliveries returns the newest deliveries, optionally with one status
+func (s *MongoStorage) GetWebhookDeliveries(ctx context.Context, status string, limit int) ([]models.WebhookDelivery, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := s.database.Collection(WebhookDeliveriesCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []models.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery by ID; nil when the ID is unknown,
+// expired or malformed
+func (s *MongoStorage) GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var delivery models.WebhookDelivery
+	err = s.database.Collection(WebhookDeliveriesCollection).FindOne(ctx, bson.M{"_id": objectID}).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
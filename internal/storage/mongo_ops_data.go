@@ -0,0 +1,207 @@
+// internal/storage/mongo_ops_data.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// RetentionOptions sets how long operational data is kept; zero keeps it
+// forever. Webhook deliveries and QA samples expire per document, so their
+// settings are only reported.
+type RetentionOptions struct {
+	// SchedulerDatabase holds the scheduler's task_runs and task_run_logs
+	SchedulerDatabase string
+	TaskRuns          time.Duration
+	NotificationLog   time.Duration
+	WebhookDeliveries time.Duration
+	QASamples         time.Duration
+}
+
+// opsCollection is an operational collection. With ttlIndex set it is pruned
+// by a TTL index on field; otherwise its documents carry their own
+// expires_at. Only the collections listed in opsCollections are ever pruned
+// by retention, never posts, configs or metadata.
+type opsCollection struct {
+	scheduler bool // in the scheduler database
+	name      string
+	field     string // when a document was written
+	ttlIndex  bool
+	setting   string
+	retention func(RetentionOptions) time.Duration
+}
+
+var opsCollections = []opsCollection{
+	{scheduler: true, name: "task_runs", field: "starttime", ttlIndex: true, setting: "TASK_RUN_RETENTION",
+		retention: func(o RetentionOptions) time.Duration { return o.TaskRuns }},
+	{scheduler: true, name: "task_run_logs", field: "timestamp", ttlIndex: true, setting: "TASK_RUN_RETENTION",
+		retention: func(o RetentionOptions) time.Duration { return o.TaskRuns }},
+	{name: NotificationLogCollection, field: "created_at", ttlIndex: true, setting: "NOTIFICATION_LOG_RETENTION",
+		retention: func(o RetentionOptions) time.Duration { return o.NotificationLog }},
+	{name: WebhookDeliveriesCollection, field: "created_at", setting: "WEBHOOK_DELIVERY_TTL",
+		retention: func(o RetentionOptions) time.Duration { return o.WebhookDeliveries }},
+	{name: QASamplesCollection, field: "sampled_at", setting: "QA_SAMPLE_TTL",
+		retention: func(o RetentionOptions) time.Duration { return o.QASamples }},
+}
+
+// opsDatabase returns the database holding ops, or nil for scheduler
+// collections when no scheduler database is configured
+func (s *MongoStorage) opsDatabase(ops opsCollection) *mongo.Database {
+	if !ops.scheduler {
+		return s.database
+	}
+	return s.schedulerDatabase()
+}
+
+// ApplyRetention syncs the TTL indexes of the operational collections to
+// opts and creates the task_runs index GetLatestSubredditRuns reads. It runs
+// under the migration lock, so instances starting with different settings
+// apply them one after the other and the last one wins.
+func (s *MongoStorage) ApplyRetention(ctx context.Context, opts RetentionOptions) error {
+	s.retention = opts
+	return s.withMigrationLock(ctx, func(string) error {
+		if db := s.schedulerDatabase(); db != nil {
+			if _, err := db.Collection("task_runs").Indexes().CreateOne(ctx, taskRunLookupIndex); err != nil {
+				return fmt.Errorf("creating the task_runs lookup index: %w", err)
+			}
+		}
+		for _, ops := range opsCollections {
+			db := s.opsDatabase(ops)
+			if !ops.ttlIndex || db == nil {
+				continue
+			}
+			if err := syncTTLIndex(ctx, db.Collection(ops.name), ops.field, ops.retention(opts)); err != nil {
+				return fmt.Errorf("syncing the %s retention: %w", ops.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// syncTTLIndex makes the ascending index on field expire documents ttl after
+// it, creating the index, changing its expiry in place with collMod, or
+// dropping it when ttl is zero. An index that already matches is left alone.
+func syncTTLIndex(ctx context.Context, collection *mongo.Collection, field string, ttl time.Duration) error {
+	name := field + "_1"
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	var serverErr mongo.ServerError
+	if err != nil && !(errors.As(err, &serverErr) && serverErr.HasErrorCode(26)) { // NamespaceNotFound
+		return err
+	}
+	var current *mongo.IndexSpecification
+	for _, spec := range specs {
+		if spec.Name == name {
+			current = spec
+		}
+	}
+
+	seconds := int32(min(ttl/time.Second, math.MaxInt32))
+	switch {
+	case ttl <= 0:
+		if current == nil || current.ExpireAfterSeconds == nil {
+			return nil
+		}
+		log.Printf("Dropping the TTL index %s.%s: documents are now kept forever", collection.Name(), name)
+		return dropIndexIfExists(ctx, collection, name)
+	case current == nil:
+		log.Printf("Creating the TTL index %s.%s expiring after %v", collection.Name(), name, ttl)
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: field, Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(seconds),
+		})
+		return err
+	case current.ExpireAfterSeconds != nil && *current.ExpireAfterSeconds == seconds:
+		return nil
+	default:
+		log.Printf("Changing the TTL index %s.%s to expire after %v", collection.Name(), name, ttl)
+		return collection.Database().RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: collection.Name()},
+			{Key: "index", Value: bson.D{{Key: "name", Value: name}, {Key: "expireAfterSeconds", Value: seconds}}},
+		}).Err()
+	}
+}
+
+// OpsDataStats reports the size, oldest document and retention of each
+// operational collection. Missing collections are reported empty.
+func (s *MongoStorage) OpsDataStats(ctx context.Context) ([]models.OpsDataStats, error) {
+	stats := make([]models.OpsDataStats, 0, len(opsCollections))
+	for _, ops := range opsCollections {
+		db := s.opsDatabase(ops)
+		if db == nil {
+			continue
+		}
+		stat := models.OpsDataStats{Database: db.Name(), Collection: ops.name, Setting: ops.setting}
+		if retention := ops.retention(s.retention); retention > 0 {
+			stat.Retention = retention.String()
+		}
+
+		collection := db.Collection(ops.name)
+		if err := collectionSize(ctx, collection, &stat); err != nil {
+			return nil, fmt.Errorf("sizing %s: %w", ops.name, err)
+		}
+		oldest, err := oldestTime(ctx, collection, ops.field)
+		if err != nil {
+			return nil, fmt.Errorf("finding the oldest %s: %w", ops.name, err)
+		}
+		stat.Oldest = oldest
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// collectionSize fills in the document count and data size from $collStats
+func collectionSize(ctx context.Context, collection *mongo.Collection, stat *models.OpsDataStats) error {
+	cursor, err := collection.Aggregate(ctx, bson.A{bson.M{"$collStats": bson.M{"storageStats": bson.M{}}}})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(26) { // NamespaceNotFound
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var results []struct {
+		StorageStats struct {
+			Count int64 `bson:"count,truncate"`
+			Size  int64 `bson:"size,truncate"`
+		} `bson:"storageStats"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return err
+	}
+	for _, result := range results {
+		stat.Documents += result.StorageStats.Count
+		stat.Bytes += result.StorageStats.Size
+	}
+	return nil
+}
+
+// oldestTime returns the earliest value of a time field, or nil for an empty
+// collection
+func oldestTime(ctx context.Context, collection *mongo.Collection, field string) (*time.Time, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: field, Value: 1}}).
+		SetProjection(bson.M{"_id": 0, field: 1})
+	raw, err := collection.FindOne(ctx, bson.M{field: bson.M{"$type": "date"}}, opts).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	oldest, ok := raw.Lookup(field).TimeOK()
+	if !ok {
+		return nil, nil
+	}
+	oldest = oldest.UTC()
+	return &oldest, nil
+}
@@ -0,0 +1,57 @@
+// internal/storage/mongo_pause.go
+package storage
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// GetMaintenancePause returns the active maintenance pause, or nil when there
+// is none. A pause past its until counts as ended even before the TTL index
+// removes it.
+func (s *MongoStorage) GetMaintenancePause(ctx context.Context) (*models.MaintenancePause, error) {
+	filter := bson.M{
+		"_id": models.MaintenancePauseID,
+		"$or": bson.A{
+			bson.M{"until": bson.M{"$exists": false}},
+			bson.M{"until": bson.M{"$gt": s.clock.Now().UTC()}},
+		},
+	}
+
+	var pause models.MaintenancePause
+	err := s.database.Collection(MaintenancePauseCollection).FindOne(ctx, filter).Decode(&pause)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// SetMaintenancePause replaces the maintenance pause
+func (s *MongoStorage) SetMaintenancePause(ctx context.Context, pause *models.MaintenancePause) error {
+	pause.ID = models.MaintenancePauseID
+	_, err := s.database.Collection(MaintenancePauseCollection).ReplaceOne(ctx,
+		bson.M{"_id": pause.ID}, pause, options.Replace().SetUpsert(true))
+	return err
+}
+
+// ClearMaintenancePause ends the maintenance pause. Reports whether an
+// active one was ended.
+func (s *MongoStorage) ClearMaintenancePause(ctx context.Context) (bool, error) {
+	var pause models.MaintenancePause
+	err := s.database.Collection(MaintenancePauseCollection).FindOneAndDelete(ctx, bson.M{"_id": models.MaintenancePauseID}).Decode(&pause)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return pause.Until == nil || pause.Until.After(s.clock.Now().UTC()), nil
+}
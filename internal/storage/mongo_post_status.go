@@ -0,0 +1,78 @@
+// internal/storage/mongo_post_status.go
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ReconcilePostPresence compares one scan of a subreddit with its stored
+// posts. seen are the reddit IDs the scan returned; only posts created
+// within window are judged absent, since the scan covers nothing else. A
+// post absent from missesToRemove consecutive scans is marked removed, and
+// a removed post that is seen again is marked restored.
+func (s *MongoStorage) ReconcilePostPresence(ctx context.Context, subreddit string, window TimeRange, seen []string, missesToRemove int) (models.PresenceScan, error) {
+	var scan models.PresenceScan
+	if window.From.IsZero() || window.To.IsZero() || missesToRemove < 1 {
+		return scan, fmt.Errorf("presence scans need a bounded window and at least one miss")
+	}
+	if seen == nil {
+		seen = []string{}
+	}
+
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return scan, observe(OpUpsertPosts, err)
+	}
+	inWindow, err := s.postCollectionNames(ctx, window.From, window.To)
+	if err != nil {
+		return scan, observe(OpUpsertPosts, err)
+	}
+
+	now := s.clock.Now().UTC()
+	// A removed post keeps its created_at, so it may be restored from any collection
+	for _, name := range names {
+		collection := s.database.Collection(name)
+		restored, err := collection.UpdateMany(ctx,
+			bson.M{"subreddit": subreddit, "reddit_id": bson.M{"$in": seen}, "status": models.PostStatusRemoved},
+			bson.M{"$set": bson.M{"status": models.PostStatusRestored, "restored_at": now, "updated_at": now}, "$unset": bson.M{"missed_scans": ""}})
+		if err != nil {
+			return scan, observe(OpUpsertPosts, err)
+		}
+		scan.Restored += int(restored.ModifiedCount)
+
+		if _, err := collection.UpdateMany(ctx,
+			bson.M{"subreddit": subreddit, "reddit_id": bson.M{"$in": seen}, "missed_scans": bson.M{"$gt": 0}},
+			bson.M{"$unset": bson.M{"missed_scans": ""}}); err != nil {
+			return scan, observe(OpUpsertPosts, err)
+		}
+	}
+
+	for _, name := range inWindow {
+		collection := s.database.Collection(name)
+		absent := bson.M{
+			"subreddit":  subreddit,
+			"created_at": window.bounds(),
+			"reddit_id":  bson.M{"$nin": seen},
+		}
+		missed, err := collection.UpdateMany(ctx, absent, bson.M{"$inc": bson.M{"missed_scans": 1}})
+		if err != nil {
+			return scan, observe(OpUpsertPosts, err)
+		}
+		scan.Missed += int(missed.ModifiedCount)
+
+		absent["status"] = bson.M{"$ne": models.PostStatusRemoved}
+		absent["missed_scans"] = bson.M{"$gte": missesToRemove}
+		removed, err := collection.UpdateMany(ctx, absent,
+			bson.M{"$set": bson.M{"status": models.PostStatusRemoved, "removed_at": now, "updated_at": now}})
+		if err != nil {
+			return scan, observe(OpUpsertPosts, err)
+		}
+		scan.Removed += int(removed.ModifiedCount)
+	}
+	return scan, nil
+}
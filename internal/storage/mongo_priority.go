@@ -0,0 +1,80 @@
+// internal/storage/mongo_priority.go
+package storage
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// RecordRunOutcome appends a monitor run's outcome to the subreddit's
+// metadata, keeping the newest models.MaxRecentRuns
+func (s *MongoStorage) RecordRunOutcome(ctx context.Context, subredditName string, failed bool) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	now := s.clock.Now().UTC()
+	filter := bson.M{"subreddit_name": subredditName}
+	update := bson.M{
+		"$push": bson.M{"recent_runs": bson.M{
+			"$each":  bson.A{models.RunOutcome{At: now, Failed: failed}},
+			"$slice": -models.MaxRecentRuns,
+		}},
+		"$set":         bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// SetPrioritySuggestion saves a subreddit's suggested priority. With
+// suggestion.Applied it also becomes the config's priority, audited under
+// actor, unless the config is priority_locked. Reports whether the priority
+// changed; false with no error also covers a config that no longer exists.
+func (s *MongoStorage) SetPrioritySuggestion(ctx context.Context, subredditName string, suggestion models.PrioritySuggestion, actor string) (bool, error) {
+	collection := s.database.Collection(SubredditConfigCollection)
+
+	previous, err := s.GetSubredditConfig(ctx, subredditName)
+	if err != nil || previous == nil {
+		return false, err
+	}
+	apply := suggestion.Applied && !previous.PriorityLocked && previous.Priority != suggestion.Priority
+	suggestion.Applied = suggestion.Applied && !previous.PriorityLocked
+
+	set := bson.M{"priority_suggestion": suggestion}
+	filter := liveConfigFilter(subredditName)
+	if apply {
+		set["priority"] = suggestion.Priority
+		set["updated_at"] = s.clock.Now().UTC()
+		// A lock or manual change since the read wins
+		filter["priority_locked"] = bson.M{"$ne": true}
+		filter["priority"] = previous.Priority
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	if !apply {
+		return false, nil
+	}
+	if result.MatchedCount == 0 {
+		// Keep the suggestion, unapplied, for review
+		suggestion.Applied = false
+		_, err := collection.UpdateOne(ctx, liveConfigFilter(subredditName), bson.M{"$set": bson.M{"priority_suggestion": suggestion}})
+		return false, err
+	}
+
+	return true, s.insertConfigAudit(ctx, models.ConfigAudit{
+		SubredditName: subredditName,
+		Action:        models.ConfigAuditUpdate,
+		Actor:         actor,
+		Changes: map[string]models.ConfigChange{
+			"priority": {From: previous.Priority, To: suggestion.Priority},
+		},
+		CreatedAt: s.clock.Now().UTC(),
+	})
+}
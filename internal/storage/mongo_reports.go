@@ -0,0 +1,159 @@
+// internal/storage/mongo_reports.go
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+)
+
+const (
+	// maxDuplicateURLs caps the URLs in a duplicate URL report
+	maxDuplicateURLs = 100
+	// maxDuplicateURLSamples caps the posts referenced per duplicate URL
+	maxDuplicateURLSamples = 5
+)
+
+// GetDuplicateURLReport groups the posts created since since by normalized
+// URL and returns the URLs posted to at least minSubreddits subreddits, most
+// widespread first. Self posts have no normalized URL and are left out, as
+// are posts stored before normalized URLs were (until they are reprocessed).
+func (s *MongoStorage) GetDuplicateURLReport(ctx context.Context, since time.Time, minSubreddits int) ([]models.DuplicateURL, error) {
+	names, err := s.postCollectionNames(ctx, since, time.Time{})
+	if err != nil {
+		return nil, observe(OpGetPosts, err)
+	}
+
+	filter, err := query.Build(
+		query.CreatedBetween(since, time.Time{}),
+		query.Trusted(bson.M{"normalized_url": bson.M{"$gt": ""}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	match := bson.A{bson.M{"$match": filter}}
+	pipeline := bson.A{
+		bson.M{"$sort": bson.M{"created_at": 1}},
+		bson.M{"$group": bson.M{
+			"_id":        "$normalized_url",
+			"subreddits": bson.M{"$addToSet": "$subreddit"},
+			"post_count": bson.M{"$sum": 1},
+			"first_seen": bson.M{"$min": "$created_at"},
+			"last_seen":  bson.M{"$max": "$created_at"},
+			"samples": bson.M{"$push": bson.M{
+				"reddit_id":  "$reddit_id",
+				"subreddit":  "$subreddit",
+				"title":      "$title",
+				"permalink":  "$permalink",
+				"created_at": "$created_at",
+			}},
+		}},
+		bson.M{"$addFields": bson.M{"subreddit_count": bson.M{"$size": "$subreddits"}}},
+		bson.M{"$match": bson.M{"subreddit_count": bson.M{"$gte": minSubreddits}}},
+		bson.M{"$sort": bson.D{{Key: "subreddit_count", Value: -1}, {Key: "post_count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": maxDuplicateURLs},
+		bson.M{"$addFields": bson.M{"samples": bson.M{"$slice": bson.A{"$samples", maxDuplicateURLSamples}}}},
+	}
+
+	cursor, err := s.aggregatePosts(ctx, names, match, pipeline)
+	if err != nil {
+		return nil, observe(OpGetPosts, err)
+	}
+	defer cursor.Close(ctx)
+
+	report := []models.DuplicateURL{}
+	if err := cursor.All(ctx, &report); err != nil {
+		return nil, observe(OpGetPosts, err)
+	}
+	for i := range report {
+		sort.Strings(report[i].Subreddits)
+	}
+	return report, nil
+}
+
+// domainGroup is one group of GetDomainStats' pipeline; ID is nil for posts
+// without a domain field
+type domainGroup struct {
+	ID        *string `bson:"_id"`
+	PostCount int     `bson:"post_count"`
+}
+
+// GetDomainStats counts the posts created since since per linked domain,
+// with their average score, for one subreddit or all when subreddit is
+// empty. The limit most linked domains are returned; posts without a domain
+// are counted separately. Imported posts are left out.
+func (s *MongoStorage) GetDomainStats(ctx context.Context, subreddit string, since time.Time, limit int) (models.DomainStats, error) {
+	names, err := s.postCollectionNames(ctx, since, time.Time{})
+	if err != nil {
+		return models.DomainStats{}, observe(OpGetPosts, err)
+	}
+
+	conditions := []query.Condition{
+		query.CreatedBetween(since, time.Time{}),
+		query.Trusted(bson.M{"import_batch": bson.M{"$exists": false}}),
+	}
+	if subreddit != "" {
+		conditions = append(conditions, query.BySubreddits(subreddit))
+	}
+	filter, err := query.Build(conditions...)
+	if err != nil {
+		return models.DomainStats{}, err
+	}
+	match := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$project": bson.M{"domain": 1, "score": 1}},
+	}
+	pipeline := bson.A{
+		bson.M{"$group": bson.M{
+			"_id":        "$domain",
+			"post_count": bson.M{"$sum": 1},
+			"avg_score":  bson.M{"$avg": "$score"},
+		}},
+		bson.M{"$facet": bson.M{
+			"domains": bson.A{
+				bson.M{"$match": bson.M{"_id": bson.M{"$gt": ""}}},
+				bson.M{"$sort": bson.D{{Key: "post_count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": limit},
+				bson.M{"$addFields": bson.M{"avg_score": bson.M{"$round": bson.A{"$avg_score", 2}}}},
+			},
+			"without": bson.A{
+				bson.M{"$match": bson.M{"_id": bson.M{"$not": bson.M{"$gt": ""}}}},
+			},
+		}},
+	}
+
+	cursor, err := s.aggregatePosts(ctx, names, match, pipeline)
+	if err != nil {
+		return models.DomainStats{}, observe(OpGetPosts, err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Domains []models.DomainStat `bson:"domains"`
+		Without []domainGroup       `bson:"without"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return models.DomainStats{}, observe(OpGetPosts, err)
+	}
+
+	stats := models.DomainStats{Domains: []models.DomainStat{}}
+	if len(results) == 0 {
+		return stats, nil
+	}
+	if results[0].Domains != nil {
+		stats.Domains = results[0].Domains
+	}
+	for _, group := range results[0].Without {
+		if group.ID == nil {
+			stats.Unextracted += group.PostCount
+		} else {
+			stats.Unparsed += group.PostCount
+		}
+	}
+	return stats, nil
+}
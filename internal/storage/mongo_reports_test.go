@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+func TestGetDuplicateURLReport(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	posts := []struct {
+		subreddit  string
+		normalized string
+		created    time.Time
+	}{
+		{"golang", "example.com/a", since.Add(time.Hour)},
+		{"rust", "example.com/a", since.Add(2 * time.Hour)},
+		{"python", "example.com/a", since.Add(3 * time.Hour)},
+		{"python", "example.com/a", since.Add(4 * time.Hour)},
+		{"golang", "example.com/b", since.Add(time.Hour)},
+		{"rust", "example.com/b", since.Add(time.Hour)},
+		// Before the window, so example.com/b stays at two subreddits
+		{"python", "example.com/b", since.Add(-time.Hour)},
+		// Self posts have no normalized URL
+		{"golang", "", since.Add(time.Hour)},
+		{"rust", "", since.Add(time.Hour)},
+		{"python", "", since.Add(time.Hour)},
+	}
+	for i, p := range posts {
+		post := &models.Post{
+			RedditID:      fmt.Sprintf("t3_%d", i),
+			Title:         fmt.Sprintf("post %d", i),
+			Subreddit:     p.subreddit,
+			NormalizedURL: p.normalized,
+			CreatedAt:     p.created,
+		}
+		if err := s.UpsertPost(ctx, post); err != nil {
+			t.Fatalf("UpsertPost() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		minSubreddits int
+		want          []models.DuplicateURL
+	}{
+		{
+			name:          "three subreddits",
+			minSubreddits: 3,
+			want: []models.DuplicateURL{
+				{URL: "example.com/a", SubredditCount: 3, PostCount: 4, Subreddits: []string{"golang", "python", "rust"}},
+			},
+		},
+		{
+			name:          "two subreddits",
+			minSubreddits: 2,
+			want: []models.DuplicateURL{
+				{URL: "example.com/a", SubredditCount: 3, PostCount: 4, Subreddits: []string{"golang", "python", "rust"}},
+				{URL: "example.com/b", SubredditCount: 2, PostCount: 2, Subreddits: []string{"golang", "rust"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := s.GetDuplicateURLReport(ctx, since, tt.minSubreddits)
+			if err != nil {
+				t.Fatalf("GetDuplicateURLReport() error = %v", err)
+			}
+			if len(report) != len(tt.want) {
+				t.Fatalf("report has %d URLs, want %d: %+v", len(report), len(tt.want), report)
+			}
+			for i, got := range report {
+				want := tt.want[i]
+				if got.URL != want.URL || got.SubredditCount != want.SubredditCount || got.PostCount != want.PostCount ||
+					!reflect.DeepEqual(got.Subreddits, want.Subreddits) {
+					t.Errorf("URL %d = %s in %v (%d subreddits, %d posts), want %+v",
+						i, got.URL, got.Subreddits, got.SubredditCount, got.PostCount, want)
+				}
+				if len(got.Samples) != got.PostCount || got.Samples[0].CreatedAt.After(got.Samples[len(got.Samples)-1].CreatedAt) {
+					t.Errorf("URL %s samples = %+v, want every post oldest first", got.URL, got.Samples)
+				}
+				if !got.FirstSeen.Equal(got.Samples[0].CreatedAt) || got.FirstSeen.Before(since) {
+					t.Errorf("URL %s first seen %v, want its oldest post in the window", got.URL, got.FirstSeen)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// internal/storage/mongo_reprocess.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// PostScanPosition is where ScanPosts stopped: the post collection it was
+// reading and the last _id it returned from it. The zero value starts a scan.
+type PostScanPosition struct {
+	Collection string
+	LastID     primitive.ObjectID
+}
+
+// ScanPosts returns up to limit posts after the position, all from one
+// collection, and the position to continue from. Collections are read in name
+// order (subreddit_post, then partitions oldest first) and each in _id order,
+// so partitions created during a scan are read last. An empty subreddit scans
+// every subreddit; an empty batch means the scan is done.
+func (s *MongoStorage) ScanPosts(ctx context.Context, subreddit string, after PostScanPosition, limit int) ([]models.Post, PostScanPosition, error) {
+	if limit <= 0 {
+		return nil, after, fmt.Errorf("limit must be positive")
+	}
+
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return nil, after, err
+	}
+	sort.Strings(names)
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	for _, name := range names {
+		if name < after.Collection {
+			continue
+		}
+
+		filter := bson.M{}
+		if subreddit != "" {
+			filter["subreddit"] = subreddit
+		}
+		if name == after.Collection && !after.LastID.IsZero() {
+			filter["_id"] = bson.M{"$gt": after.LastID}
+		}
+
+		cursor, err := s.database.Collection(name).Find(ctx, filter, opts)
+		if err != nil {
+			return nil, after, observe(OpGetPosts, err)
+		}
+		var posts []models.Post
+		if err := cursor.All(ctx, &posts); err != nil {
+			return nil, after, observe(OpGetPosts, err)
+		}
+		if len(posts) > 0 {
+			return posts, PostScanPosition{Collection: name, LastID: posts[len(posts)-1].ID}, nil
+		}
+	}
+	return nil, after, nil
+}
+
+// RewritePosts writes back posts ScanPosts read from collection: updated
+// posts get their normalized fields, tags, normalized URL, domain and content hash
+// replaced and deleted posts are removed. It returns how many were modified and deleted.
+func (s *MongoStorage) RewritePosts(ctx context.Context, collection string, updated, deleted []models.Post) (int64, int64, error) {
+	if collection != SubredditPostsCollection && !partitionPattern.MatchString(collection) {
+		return 0, 0, fmt.Errorf("%q is not a post collection", collection)
+	}
+
+	now := s.clock.Now().UTC()
+	writes := make([]mongo.WriteModel, 0, len(updated)+len(deleted))
+	for _, post := range updated {
+		set := bson.M{
+			"title":          post.Title,
+			"body":           post.Body,
+			"author":         post.Author,
+			"url":            post.URL,
+			"normalized_url": post.NormalizedURL,
+			"domain":         post.Domain,
+			"permalink":      post.Permalink,
+			"flair":          post.Flair,
+			"content_hash":   contentHash(post.Title, post.Body),
+			"updated_at":     now,
+		}
+		update := bson.M{"$set": set}
+		if len(post.Tags) > 0 {
+			set["tags"] = post.Tags
+		} else {
+			update["$unset"] = bson.M{"tags": ""}
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": post.ID}).SetUpdate(update))
+	}
+	for _, post := range deleted {
+		writes = append(writes, mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": post.ID}))
+	}
+	if len(writes) == 0 {
+		return 0, 0, nil
+	}
+
+	result, err := s.database.Collection(collection).BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, 0, observe(OpUpsertPosts, err)
+	}
+	return result.ModifiedCount, result.DeletedCount, nil
+}
+
+// GetReprocessProgress returns the checkpoint of an unfinished reprocess pass
+// over scope, or nil when there is none
+func (s *MongoStorage) GetReprocessProgress(ctx context.Context, scope string) (*models.ReprocessProgress, error) {
+	var progress models.ReprocessProgress
+	err := s.database.Collection(ReprocessProgressCollection).FindOne(ctx, bson.M{"_id": scope}).Decode(&progress)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// SaveReprocessProgress stores a pass's checkpoint, replacing the previous one
+func (s *MongoStorage) SaveReprocessProgress(ctx context.Context, progress *models.ReprocessProgress) error {
+	progress.UpdatedAt = s.clock.Now().UTC()
+	_, err := s.database.Collection(ReprocessProgressCollection).ReplaceOne(ctx,
+		bson.M{"_id": progress.Scope}, progress, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeleteReprocessProgress removes a pass's checkpoint once it finished or is restarted
+func (s *MongoStorage) DeleteReprocessProgress(ctx context.Context, scope string) error {
+	_, err := s.database.Collection(ReprocessProgressCollection).DeleteOne(ctx, bson.M{"_id": scope})
+	return err
+}
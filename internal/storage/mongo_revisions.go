@@ -0,0 +1,119 @@
+// internal/storage/mongo_revisions.go
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// storedContent is the part of a stored post that upserts compare and keep
+type storedContent struct {
+	RedditID    string   `bson:"reddit_id"`
+	Title       string   `bson:"title"`
+	Body        string   `bson:"body"`
+	ContentHash string   `bson:"content_hash"`
+	Flair       string   `bson:"flair"`
+	Tags        []string `bson:"tags"`
+	// collection is where the post was found
+	collection string
+}
+
+// hash returns the stored hash, computing it for documents written before
+// content hashes existed
+func (c storedContent) hash() string {
+	if c.ContentHash != "" {
+		return c.ContentHash
+	}
+	return contentHash(c.Title, c.Body)
+}
+
+// contentHash fingerprints a post's editable content
+func contentHash(title, body string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+// getStoredContent loads the flair and tags of the already stored posts among
+// posts, and their title/body when withContent is set
+func (s *MongoStorage) getStoredContent(ctx context.Context, posts []models.Post, withContent bool) (map[string]storedContent, error) {
+	redditIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		redditIDs = append(redditIDs, post.RedditID)
+	}
+
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"reddit_id": bson.M{"$in": redditIDs}}
+	projection := bson.M{"reddit_id": 1, "flair": 1, "tags": 1}
+	if withContent {
+		projection["title"] = 1
+		projection["body"] = 1
+		projection["content_hash"] = 1
+	}
+	opts := options.Find().SetProjection(projection)
+
+	byID := make(map[string]storedContent, len(posts))
+	for _, name := range names {
+		cursor, err := s.database.Collection(name).Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var stored []storedContent
+		if err := cursor.All(ctx, &stored); err != nil {
+			return nil, err
+		}
+		for _, content := range stored {
+			content.collection = name
+			byID[content.RedditID] = content
+		}
+	}
+	return byID, nil
+}
+
+// saveRevision records the version of a post that is about to be overwritten
+func (s *MongoStorage) saveRevision(ctx context.Context, redditID string, prev storedContent, capturedAt time.Time) error {
+	collection := s.database.Collection(PostRevisionsCollection)
+
+	revision := models.PostRevision{
+		RedditID:    redditID,
+		Title:       prev.Title,
+		Body:        prev.Body,
+		ContentHash: prev.hash(),
+		CapturedAt:  capturedAt,
+	}
+
+	_, err := collection.InsertOne(ctx, revision)
+	return err
+}
+
+// GetPostRevisions returns previous versions of a post, newest first
+func (s *MongoStorage) GetPostRevisions(ctx context.Context, redditID string) ([]models.PostRevision, error) {
+	collection := s.database.Collection(PostRevisionsCollection)
+
+	filter := bson.M{"reddit_id": redditID}
+	opts := options.Find().SetSort(bson.D{{Key: "captured_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []models.PostRevision
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
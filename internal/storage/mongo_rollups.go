@@ -0,0 +1,239 @@
+// internal/storage/mongo_rollups.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// maxRollupAuthors caps the distinct authors kept per hourly bucket; beyond it
+// author_count is a lower bound and authors_capped is set
+const maxRollupAuthors = 500
+
+// rollupBucket is the hourly bucket a post is counted in
+func rollupBucket(createdAt time.Time) time.Time {
+	return createdAt.UTC().Truncate(time.Hour)
+}
+
+// rollupAuthor reports whether an author counts towards distinct authors
+func rollupAuthor(author string) bool {
+	return author != "" && author != "[deleted]"
+}
+
+type rollupKey struct {
+	subreddit string
+	bucket    time.Time
+}
+
+type rollupDelta struct {
+	count    int
+	sumScore int64
+	maxScore int
+	authors  map[string]bool
+}
+
+// IncrementRollups adds newly inserted posts to their hourly buckets. Callers
+// must pass only posts UpsertPosts reported as inserted, or they are counted
+// twice. Posts without created_at are skipped.
+func (s *MongoStorage) IncrementRollups(ctx context.Context, posts []models.Post) error {
+	deltas := make(map[rollupKey]*rollupDelta)
+	for _, post := range posts {
+		if post.CreatedAt.IsZero() {
+			continue
+		}
+		key := rollupKey{subreddit: post.Subreddit, bucket: rollupBucket(post.CreatedAt)}
+		delta, ok := deltas[key]
+		if !ok {
+			delta = &rollupDelta{maxScore: post.Score, authors: make(map[string]bool)}
+			deltas[key] = delta
+		}
+		delta.count++
+		delta.sumScore += int64(post.Score)
+		if post.Score > delta.maxScore {
+			delta.maxScore = post.Score
+		}
+		if rollupAuthor(post.Author) {
+			delta.authors[post.Author] = true
+		}
+	}
+
+	collection := s.database.Collection(PostRollupsCollection)
+	now := s.clock.Now().UTC()
+
+	for key, delta := range deltas {
+		authors := make(bson.A, 0, len(delta.authors))
+		for author := range delta.authors {
+			authors = append(authors, author)
+		}
+		merged := bson.M{"$setUnion": bson.A{bson.M{"$ifNull": bson.A{"$authors", bson.A{}}}, authors}}
+
+		// Both stages see the document as it was before the update
+		update := mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"subreddit":    key.subreddit,
+				"bucket_start": key.bucket,
+				"post_count":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$post_count", 0}}, delta.count}},
+				"sum_score":    bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$sum_score", 0}}, delta.sumScore}},
+				"max_score":    bson.M{"$max": bson.A{"$max_score", delta.maxScore}},
+				"authors":      bson.M{"$slice": bson.A{merged, maxRollupAuthors}},
+				"authors_capped": bson.M{"$or": bson.A{
+					bson.M{"$ifNull": bson.A{"$authors_capped", false}},
+					bson.M{"$gt": bson.A{bson.M{"$size": merged}, maxRollupAuthors}},
+				}},
+				"updated_at": now,
+			}}},
+			{{Key: "$set", Value: bson.M{"author_count": bson.M{"$size": "$authors"}}}},
+		}
+
+		filter := bson.M{"subreddit": key.subreddit, "bucket_start": key.bucket}
+		if _, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebuildRollups recomputes a subreddit's hourly buckets in [from, to) from
+// stored posts, leaving out imported ones as monitoring never counted them.
+// The range is widened to whole hours. Posts inserted by a
+// concurrent run may be missed or counted twice, so run it while the
+// subreddit is idle. Returns the number of buckets written.
+func (s *MongoStorage) RebuildRollups(ctx context.Context, subreddit string, from, to time.Time) (int, error) {
+	from = rollupBucket(from)
+	if bucket := rollupBucket(to); bucket.Before(to) {
+		to = bucket.Add(time.Hour)
+	}
+
+	names, err := s.postCollectionNames(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	match := bson.A{bson.D{{Key: "$match", Value: bson.M{
+		"subreddit":    subreddit,
+		"created_at":   bson.M{"$gte": from, "$lt": to},
+		"import_batch": bson.M{"$exists": false},
+	}}}}
+	pipeline := bson.A{
+		bson.D{{Key: "$group", Value: bson.M{
+			// created_at minus its milliseconds past the hour
+			"_id": bson.M{"$subtract": bson.A{
+				"$created_at",
+				bson.M{"$mod": bson.A{bson.M{"$toLong": "$created_at"}, time.Hour.Milliseconds()}},
+			}},
+			"post_count": bson.M{"$sum": 1},
+			"sum_score":  bson.M{"$sum": "$score"},
+			"max_score":  bson.M{"$max": "$score"},
+			"authors":    bson.M{"$addToSet": "$author"},
+		}}},
+	}
+
+	cursor, err := s.aggregatePosts(ctx, names, match, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Bucket    time.Time `bson:"_id"`
+		PostCount int       `bson:"post_count"`
+		SumScore  int64     `bson:"sum_score"`
+		MaxScore  int       `bson:"max_score"`
+		Authors   []string  `bson:"authors"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now().UTC()
+	documents := make([]interface{}, 0, len(groups))
+	for _, group := range groups {
+		rollup := models.PostRollup{
+			Subreddit:   subreddit,
+			BucketStart: group.Bucket.UTC(),
+			PostCount:   group.PostCount,
+			SumScore:    group.SumScore,
+			MaxScore:    group.MaxScore,
+			UpdatedAt:   now,
+		}
+		for _, author := range group.Authors {
+			if !rollupAuthor(author) {
+				continue
+			}
+			if len(rollup.Authors) == maxRollupAuthors {
+				rollup.AuthorsCapped = true
+				break
+			}
+			rollup.Authors = append(rollup.Authors, author)
+		}
+		rollup.AuthorCount = len(rollup.Authors)
+		documents = append(documents, rollup)
+	}
+
+	collection := s.database.Collection(PostRollupsCollection)
+	filter := bson.M{"subreddit": subreddit, "bucket_start": bson.M{"$gte": from, "$lt": to}}
+	if _, err := collection.DeleteMany(ctx, filter); err != nil {
+		return 0, err
+	}
+	if len(documents) == 0 {
+		return 0, nil
+	}
+	if _, err := collection.InsertMany(ctx, documents); err != nil {
+		return 0, err
+	}
+
+	return len(documents), nil
+}
+
+// GetRollups returns a subreddit's hourly buckets in [from, to), oldest first
+func (s *MongoStorage) GetRollups(ctx context.Context, subreddit string, from, to time.Time) ([]models.PostRollup, error) {
+	collection := s.database.Collection(PostRollupsCollection)
+
+	filter := bson.M{"subreddit": subreddit, "bucket_start": bson.M{"$gte": from, "$lt": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "bucket_start", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rollups []models.PostRollup
+	if err := cursor.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+
+	return rollups, nil
+}
+
+// GetRollupsForSubreddits returns the hourly rollups of every listed subreddit
+// in [from, to), ordered by bucket; buckets of different subreddits are not merged
+func (s *MongoStorage) GetRollupsForSubreddits(ctx context.Context, subreddits []string, from, to time.Time) ([]models.PostRollup, error) {
+	if len(subreddits) == 0 {
+		return nil, nil
+	}
+	collection := s.database.Collection(PostRollupsCollection)
+
+	filter := bson.M{"subreddit": bson.M{"$in": subreddits}, "bucket_start": bson.M{"$gte": from, "$lt": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "bucket_start", Value: 1}, {Key: "subreddit", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rollups []models.PostRollup
+	if err := cursor.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+
+	return rollups, nil
+}
@@ -0,0 +1,81 @@
+// internal/storage/mongo_run_presets.go
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// GetRunPresets lists all run presets by name
+func (s *MongoStorage) GetRunPresets(ctx context.Context) ([]models.RunPreset, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := s.database.Collection(RunPresetsCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	presets := []models.RunPreset{}
+	if err := cursor.All(ctx, &presets); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// GetRunPreset returns a preset by name, or nil when there is none
+func (s *MongoStorage) GetRunPreset(ctx context.Context, name string) (*models.RunPreset, error) {
+	var preset models.RunPreset
+	err := s.database.Collection(RunPresetsCollection).FindOne(ctx, bson.M{"name": name}).Decode(&preset)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// UpsertRunPreset validates and saves a preset
+func (s *MongoStorage) UpsertRunPreset(ctx context.Context, preset *models.RunPreset) error {
+	if err := preset.Validate(); err != nil {
+		return fmt.Errorf("invalid run preset: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	preset.UpdatedAt = now
+	if preset.CreatedAt.IsZero() {
+		preset.CreatedAt = now
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":        preset.Name,
+			"task":        preset.Task,
+			"description": preset.Description,
+			"params":      preset.Params,
+			"updated_at":  preset.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": preset.CreatedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := s.database.Collection(RunPresetsCollection).UpdateOne(ctx, bson.M{"name": preset.Name}, update, opts)
+	return err
+}
+
+// DeleteRunPreset deletes a preset and reports whether it existed
+func (s *MongoStorage) DeleteRunPreset(ctx context.Context, name string) (bool, error) {
+	result, err := s.database.Collection(RunPresetsCollection).DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,33 @@
+// internal/storage/mongo_runtime_state.go
+package storage
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// GetRuntimeState returns the runtime state last recorded on host, or nil
+// when there is none
+func (s *MongoStorage) GetRuntimeState(ctx context.Context, host string) (*models.RuntimeState, error) {
+	var state models.RuntimeState
+	err := s.database.Collection(RuntimeStateCollection).FindOne(ctx, bson.M{"_id": host}).Decode(&state)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveRuntimeState replaces the runtime state recorded for the state's host
+func (s *MongoStorage) SaveRuntimeState(ctx context.Context, state *models.RuntimeState) error {
+	_, err := s.database.Collection(RuntimeStateCollection).ReplaceOne(ctx,
+		bson.M{"_id": state.Host}, state, options.Replace().SetUpsert(true))
+	return err
+}
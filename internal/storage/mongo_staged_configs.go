@@ -0,0 +1,107 @@
+// internal/storage/mongo_staged_configs.go
+package storage
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// StageConfigChange puts a change in the staging area, replacing any change
+// staged for the same subreddit
+func (s *MongoStorage) StageConfigChange(ctx context.Context, change models.StagedConfigChange) error {
+	change.StagedAt = s.clock.Now().UTC()
+	opts := options.Replace().SetUpsert(true)
+	_, err := s.database.Collection(StagedConfigsCollection).ReplaceOne(ctx, bson.M{"subreddit_name": change.SubredditName}, change, opts)
+	return err
+}
+
+// GetStagedConfigChanges lists the staged changes by subreddit name, each
+// compared to its live config: Action, Changes and, when the live config
+// moved since the change was staged, Conflict are filled in
+func (s *MongoStorage) GetStagedConfigChanges(ctx context.Context) ([]models.StagedConfigChange, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "subreddit_name", Value: 1}})
+	cursor, err := s.database.Collection(StagedConfigsCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	changes := []models.StagedConfigChange{}
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	names := make([]string, 0, len(changes))
+	for _, change := range changes {
+		names = append(names, change.SubredditName)
+	}
+	cursor, err = s.database.Collection(SubredditConfigCollection).Find(ctx, bson.M{
+		"subreddit_name": bson.M{"$in": names},
+		"deleted_at":     bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var configs []models.SubredditConfig
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, err
+	}
+	live := make(map[string]*models.SubredditConfig, len(configs))
+	for i := range configs {
+		live[configs[i].SubredditName] = &configs[i]
+	}
+
+	for i := range changes {
+		compareStagedChange(&changes[i], live[changes[i].SubredditName])
+	}
+	return changes, nil
+}
+
+// compareStagedChange fills in the change's Action, Changes and Conflict
+// against the live config, nil when there is none
+func compareStagedChange(change *models.StagedConfigChange, live *models.SubredditConfig) {
+	switch {
+	case change.BaseUpdatedAt == nil && live != nil:
+		change.Conflict = "a config was created after this change was staged"
+	case change.BaseUpdatedAt != nil && live == nil:
+		change.Conflict = "the config was deleted after this change was staged"
+	case live != nil && !live.UpdatedAt.Equal(*change.BaseUpdatedAt):
+		change.Conflict = "the config was changed after this change was staged"
+	}
+
+	switch {
+	case change.Delete:
+		change.Action = models.StagedDelete
+	case live == nil:
+		change.Action = models.StagedCreate
+		if change.Config != nil {
+			change.Changes = diffConfigFields(bson.M{}, subredditConfigFields(change.Config))
+		}
+	default:
+		change.Action = models.StagedUpdate
+		if change.Config != nil {
+			change.Changes = diffConfigFields(subredditConfigFields(live), subredditConfigFields(change.Config))
+		}
+	}
+}
+
+// UnstageConfigChange removes a subreddit's change from the staging area
+func (s *MongoStorage) UnstageConfigChange(ctx context.Context, subredditName string) error {
+	_, err := s.database.Collection(StagedConfigsCollection).DeleteOne(ctx, bson.M{"subreddit_name": subredditName})
+	return err
+}
+
+// DiscardStagedConfigChanges empties the staging area and returns how many
+// changes it held
+func (s *MongoStorage) DiscardStagedConfigChanges(ctx context.Context) (int64, error) {
+	result, err := s.database.Collection(StagedConfigsCollection).DeleteMany(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
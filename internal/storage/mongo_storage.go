@@ -4,6 +4,8 @@ package storage
 import (
 	"context"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"reddit-orchestrator/internal/clock"
 	"reddit-orchestrator/internal/models"
 )
 
@@ -18,20 +21,75 @@ const (
 	SubredditMetadataCollection = "subreddit_metadata" 
 	SubredditPostsCollection   = "subreddit_post"
 	SubredditConfigCollection  = "subreddit_config"
+	AnomaliesCollection        = "anomalies"
+	PostRevisionsCollection     = "post_revisions"
+	UserConfigCollection        = "user_config"
+	UserMetadataCollection      = "user_metadata"
+	NotificationRulesCollection = "notification_rules"
+	NotificationLogCollection   = "notification_log"
+	PostRollupsCollection       = "post_rollups"
+	ConfigAuditCollection       = "config_audit"
+	LeadershipCollection        = "leadership"
+	RawCapturesCollection       = "raw_captures"
+	StorageStatsCollection      = "storage_stats"
+	WebhookDeliveriesCollection = "webhook_deliveries"
+	ConfigTemplatesCollection   = "config_templates"
+	RunPresetsCollection        = "run_presets"
+	DigestsCollection           = "digests"
+	ReprocessProgressCollection = "reprocess_progress"
+	RuntimeStateCollection      = "runtime_state"
+	ConsumersCollection         = "consumers"
+	QASamplesCollection         = "qa_samples"
+	FlairArchiveCollection      = "flair_history_archive"
+	MaintenancePauseCollection  = "maintenance_pause"
+	StagedConfigsCollection     = "staged_configs"
 )
 
-var _ StorageInterface = (*MongoStorage)(nil)
+var (
+	_ StorageInterface  = (*MongoStorage)(nil)
+	_ MetadataStore     = (*MongoStorage)(nil)
+	_ PostStore         = (*MongoStorage)(nil)
+	_ ConfigStore       = (*MongoStorage)(nil)
+	_ AnomalyStore      = (*MongoStorage)(nil)
+	_ NotificationStore = (*MongoStorage)(nil)
+	_ RollupStore       = (*MongoStorage)(nil)
+	_ LeaderStore       = (*MongoStorage)(nil)
+	_ PauseStore        = (*MongoStorage)(nil)
+	_ CaptureStore      = (*MongoStorage)(nil)
+	_ PartitionStore    = (*MongoStorage)(nil)
+	_ StatsStore        = (*MongoStorage)(nil)
+	_ BackupStore       = (*MongoStorage)(nil)
+	_ HealthChecker     = (*MongoStorage)(nil)
+)
 
 type MongoStorage struct {
-	client   *mongo.Client
-	database *mongo.Database
+	client     *mongo.Client
+	database   *mongo.Database
+	pool       *poolCounters
+	partitions *partitionSet
+	// clock stamps written documents and computes time windows
+	clock clock.Clock
+	// retention is set by ApplyRetention
+	retention RetentionOptions
+	// transactions is set when the deployment supports multi-document
+	// transactions, which backup restores then run in
+	transactions bool
 }
 
-func NewMongoStorage(mongoURI, databaseName string) (*MongoStorage, error) {
+// NewMongoStorage connects, applies pending schema migrations and ensures
+// indexes. Find/aggregate/count commands slower than slowQueryThreshold are
+// logged; zero disables the check.
+func NewMongoStorage(mongoURI, databaseName string, slowQueryThreshold time.Duration, partitioning PartitionOptions, clk clock.Clock) (*MongoStorage, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	pool := &poolCounters{}
+	clientOpts := options.Client().ApplyURI(mongoURI).SetPoolMonitor(pool.monitor())
+	if slowQueryThreshold > 0 {
+		clientOpts.SetMonitor(newSlowQueryMonitor(slowQueryThreshold))
+	}
+
+	client, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -43,13 +101,34 @@ func NewMongoStorage(mongoURI, databaseName string) (*MongoStorage, error) {
 
 	database := client.Database(databaseName)
 
+	transactions, err := supportsTransactions(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MongoDB topology: %w", err)
+	}
+	if !transactions {
+		log.Printf("Warning: MongoDB is a standalone server, so backup restores will not be atomic")
+	}
+
 	storage := &MongoStorage{
-		client:   client,
-		database: database,
+		client:       client,
+		database:     database,
+		pool:         pool,
+		partitions:   newPartitionSet(partitioning),
+		clock:        clk,
+		transactions: transactions,
+	}
+
+	// Migrations may wait for another instance's run, so schema setup gets
+	// its own deadline. They go first so legacy indexes are gone before
+	// createIndexes.
+	setupCtx, cancelSetup := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancelSetup()
+	if _, err := storage.migrate(setupCtx); err != nil {
+		return nil, fmt.Errorf("failed to apply schema migrations: %w", err)
 	}
 
 	// Create indexes
-	if err := storage.createIndexes(ctx); err != nil {
+	if err := storage.createIndexes(setupCtx); err != nil {
 		return nil, fmt.Errorf("failed to create indexes: %w", err)
 	}
 
@@ -57,12 +136,8 @@ func NewMongoStorage(mongoURI, databaseName string) (*MongoStorage, error) {
 }
 
 func (s *MongoStorage) createIndexes(ctx context.Context) error {
-	// Clean up any problematic indexes first
 	postsCollection := s.database.Collection(SubredditPostsCollection)
 	
-	postsCollection.Indexes().DropOne(ctx, "reddit_name_1")
-	postsCollection.Indexes().DropOne(ctx, "reddit_id_1") 
-
 	// Subreddit metadata collection indexes
 	metadataIndexes := []mongo.IndexModel{
 		{
@@ -70,38 +145,183 @@ func (s *MongoStorage) createIndexes(ctx context.Context) error {
 			Options: options.Index().SetUnique(true),
 		},
 		{Keys: bson.D{{Key: "last_scraped_at", Value: -1}}},
+		{Keys: bson.D{{Key: "updated_at", Value: -1}}},
 	}
 	if _, err := s.database.Collection(SubredditMetadataCollection).Indexes().CreateMany(ctx, metadataIndexes); err != nil {
 		return err
 	}
 
-	// Subreddit posts collection indexes
-	postsIndexes := []mongo.IndexModel{
-		{
-			Keys:    bson.D{{Key: "reddit_id", Value: 1}},
-			Options: options.Index().SetUnique(true).SetSparse(true), // Sparse to handle any nulls
-		},
-		{Keys: bson.D{{Key: "subreddit", Value: 1}}},
-		{Keys: bson.D{{Key: "author", Value: 1}}},
-		{Keys: bson.D{{Key: "created_at", Value: -1}}},
-		{Keys: bson.D{{Key: "updated_at", Value: -1}}},
-		{Keys: bson.D{{Key: "inserted_at", Value: -1}}},
-		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "created_at", Value: -1}}},
-	}
-	if _, err := postsCollection.Indexes().CreateMany(ctx, postsIndexes); err != nil {
+	// Subreddit posts collection indexes; partitions get theirs on first write
+	if _, err := postsCollection.Indexes().CreateMany(ctx, postIndexModels()); err != nil {
 		return err
 	}
 
+	// Live configs have no deleted_at, which indexes as null, so a name is
+	// unique among live configs while any number of deleted copies can remain
+	configCollection := s.database.Collection(SubredditConfigCollection)
+
 	configIndexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "subreddit_name", Value: 1}},
+			Keys:    bson.D{{Key: "subreddit_name", Value: 1}, {Key: "deleted_at", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{Keys: bson.D{{Key: "enabled", Value: 1}}},
 		{Keys: bson.D{{Key: "priority", Value: -1}}},
 		{Keys: bson.D{{Key: "updated_at", Value: -1}}},
+		{Keys: bson.D{{Key: "labels", Value: 1}}},
+	}
+	if _, err := configCollection.Indexes().CreateMany(ctx, configIndexes); err != nil {
+		return err
+	}
+
+	templateIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := s.database.Collection(ConfigTemplatesCollection).Indexes().CreateMany(ctx, templateIndexes); err != nil {
+		return err
+	}
+	if _, err := s.database.Collection(RunPresetsCollection).Indexes().CreateMany(ctx, templateIndexes); err != nil {
+		return err
+	}
+	if _, err := s.database.Collection(StagedConfigsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "subreddit_name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
 	}
-	if _, err := s.database.Collection(SubredditConfigCollection).Indexes().CreateMany(ctx, configIndexes); err != nil {
+	if _, err := s.database.Collection(UserConfigCollection).Indexes().CreateMany(ctx, userIndexes); err != nil {
+		return err
+	}
+	if _, err := s.database.Collection(UserMetadataCollection).Indexes().CreateMany(ctx, userIndexes); err != nil {
+		return err
+	}
+
+	revisionIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "reddit_id", Value: 1}, {Key: "captured_at", Value: -1}}},
+	}
+	if _, err := s.database.Collection(PostRevisionsCollection).Indexes().CreateMany(ctx, revisionIndexes); err != nil {
+		return err
+	}
+
+	// One anomaly per author and window keeps detection idempotent
+	anomalyIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "subreddit", Value: 1}, {Key: "author", Value: 1}, {Key: "window_start", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "detected_at", Value: -1}}},
+	}
+	if _, err := s.database.Collection(AnomaliesCollection).Indexes().CreateMany(ctx, anomalyIndexes); err != nil {
+		return err
+	}
+
+	ruleIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := s.database.Collection(NotificationRulesCollection).Indexes().CreateMany(ctx, ruleIndexes); err != nil {
+		return err
+	}
+
+	rollupIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "subreddit", Value: 1}, {Key: "bucket_start", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := s.database.Collection(PostRollupsCollection).Indexes().CreateMany(ctx, rollupIndexes); err != nil {
+		return err
+	}
+
+	logIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "rule_name", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if _, err := s.database.Collection(NotificationLogCollection).Indexes().CreateMany(ctx, logIndexes); err != nil {
+		return err
+	}
+
+	auditIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subreddit_name", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if _, err := s.database.Collection(ConfigAuditCollection).Indexes().CreateMany(ctx, auditIndexes); err != nil {
+		return err
+	}
+
+	// Each capture carries its own expiry, so changing CAPTURE_TTL needs no index change
+	captureIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "captured_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := s.database.Collection(RawCapturesCollection).Indexes().CreateMany(ctx, captureIndexes); err != nil {
+		return err
+	}
+
+	// QA samples carry their own expiry, like raw captures
+	qaSampleIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "sampled_at", Value: -1}}},
+		{Keys: bson.D{{Key: "sampled_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := s.database.Collection(QASamplesCollection).Indexes().CreateMany(ctx, qaSampleIndexes); err != nil {
+		return err
+	}
+
+	// A timed maintenance pause is dropped once it ends
+	pauseIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "until", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := s.database.Collection(MaintenancePauseCollection).Indexes().CreateMany(ctx, pauseIndexes); err != nil {
+		return err
+	}
+
+	// Deliveries carry their own expiry, like raw captures
+	deliveryIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := s.database.Collection(WebhookDeliveriesCollection).Indexes().CreateMany(ctx, deliveryIndexes); err != nil {
+		return err
+	}
+
+	statsIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "bytes", Value: -1}}},
+	}
+	if _, err := s.database.Collection(StorageStatsCollection).Indexes().CreateMany(ctx, statsIndexes); err != nil {
+		return err
+	}
+
+	digestIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "label", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := s.database.Collection(DigestsCollection).Indexes().CreateMany(ctx, digestIndexes); err != nil {
 		return err
 	}
 
@@ -128,18 +348,89 @@ func (s *MongoStorage) GetSubredditMetadata(ctx context.Context, subredditName s
 	return &metadata, nil
 }
 
+// metadataLookupChunk caps the names in one $in query of GetSubredditMetadataByNames
+const metadataLookupChunk = 500
+
+// GetSubredditMetadataByNames loads the metadata of many subreddits in one
+// query per metadataLookupChunk names, keyed by subreddit name. Names without
+// metadata are absent from the map.
+func (s *MongoStorage) GetSubredditMetadataByNames(ctx context.Context, names []string) (map[string]models.SubredditMetadata, error) {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	metadatas := make(map[string]models.SubredditMetadata, len(names))
+	for start := 0; start < len(names); start += metadataLookupChunk {
+		end := min(start+metadataLookupChunk, len(names))
+
+		cursor, err := collection.Find(ctx, bson.M{"subreddit_name": bson.M{"$in": names[start:end]}})
+		if err != nil {
+			return nil, err
+		}
+
+		var chunk []models.SubredditMetadata
+		err = cursor.All(ctx, &chunk)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, metadata := range chunk {
+			metadatas[metadata.SubredditName] = metadata
+		}
+	}
+
+	return metadatas, nil
+}
+
+// UpsertSubredditMetadata writes the provided metadata fields: a zero
+// LastScrapedAt and nil MonitorConfig or LastRun are left untouched so
+// explicit config flows can't wipe data they don't own. A non-nil
+// MonitorConfig is written as given, zero values included.
 func (s *MongoStorage) UpsertSubredditMetadata(ctx context.Context, metadata *models.SubredditMetadata) error {
 	collection := s.database.Collection(SubredditMetadataCollection)
 	
 	filter := bson.M{"subreddit_name": metadata.SubredditName}
 
-	now := time.Now()
+	opts := options.Update().SetUpsert(true)
+	_, err := collection.UpdateOne(ctx, filter, metadataUpdate(metadata, s.clock.Now().UTC()), opts)
+	return err
+}
+
+// metadataUpdate builds the upsert of UpsertSubredditMetadata
+func metadataUpdate(metadata *models.SubredditMetadata, now time.Time) bson.M {
+	set := bson.M{
+		"subreddit_name": metadata.SubredditName,
+		"updated_at":     now,
+	}
+	if !metadata.LastScrapedAt.IsZero() {
+		set["last_scraped_at"] = metadata.LastScrapedAt
+	}
+	if metadata.MonitorConfig != nil {
+		set["monitor_config"] = metadata.MonitorConfig
+	}
+	if metadata.LastRun != nil {
+		set["last_run"] = metadata.LastRun
+	}
+
+	return bson.M{
+		"$set": set,
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}
+}
+
+// UpdateLastScraped advances the scrape cursor and records run stats without
+// touching any other metadata field
+func (s *MongoStorage) UpdateLastScraped(ctx context.Context, subredditName string, scrapedAt time.Time, stats models.RunStats) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	filter := bson.M{"subreddit_name": subredditName}
+
+	now := s.clock.Now().UTC()
 	update := bson.M{
 		"$set": bson.M{
-			"subreddit_name":   metadata.SubredditName,
-			"last_scraped_at":  metadata.LastScrapedAt,
-			"monitor_config":   metadata.MonitorConfig,
-			"updated_at":       now,
+			"last_scraped_at": scrapedAt,
+			"last_run":        stats,
+			"updated_at":      now,
 		},
 		"$setOnInsert": bson.M{
 			"created_at": now,
@@ -148,13 +439,101 @@ func (s *MongoStorage) UpsertSubredditMetadata(ctx context.Context, metadata *mo
 
 	opts := options.Update().SetUpsert(true)
 	_, err := collection.UpdateOne(ctx, filter, update, opts)
+	return observe(OpMetadataUpdate, err)
+}
+
+// SetBestRecentPost replaces the subreddit's best recent post
+func (s *MongoStorage) SetBestRecentPost(ctx context.Context, subredditName string, best models.BestRecentPost) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"subreddit_name": subredditName}, bson.M{
+		"$set": bson.M{"best_recent_post": best, "updated_at": s.clock.Now().UTC()},
+	})
+	return observe(OpMetadataUpdate, err)
+}
+
+// UpdateZeroPostRuns increments the consecutive zero-post run counter, or
+// resets it (and the stale flag) when posts were fetched. Returns the new count.
+func (s *MongoStorage) UpdateZeroPostRuns(ctx context.Context, subredditName string, postsFetched int) (int, error) {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	filter := bson.M{"subreddit_name": subredditName}
+	var update bson.M
+	if postsFetched > 0 {
+		update = bson.M{"$set": bson.M{"zero_post_runs": 0, "stale": false, "updated_at": s.clock.Now().UTC()}}
+	} else {
+		update = bson.M{"$inc": bson.M{"zero_post_runs": 1}}
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"zero_post_runs": 1})
+
+	var metadata models.SubredditMetadata
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&metadata)
+	if err == mongo.ErrNoDocuments {
+		// Metadata is created by UpdateLastScraped; nothing to count yet
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return metadata.ZeroPostRuns, nil
+}
+
+func (s *MongoStorage) SetSubredditStale(ctx context.Context, subredditName string, stale bool) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	filter := bson.M{"subreddit_name": subredditName}
+	update := bson.M{"$set": bson.M{"stale": stale, "updated_at": s.clock.Now().UTC()}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
 	return err
 }
 
-func (s *MongoStorage) GetAllSubredditMetadata(ctx context.Context) ([]models.SubredditMetadata, error) {
+// SetSubredditAbout stores the subreddit's about info, creating its metadata
+// when the subreddit was never scraped
+func (s *MongoStorage) SetSubredditAbout(ctx context.Context, subredditName string, about models.AboutInfo) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	now := s.clock.Now().UTC()
+	filter := bson.M{"subreddit_name": subredditName}
+	update := bson.M{
+		"$set":         bson.M{"about": about, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// SetSubredditActivity stores the subreddit's activity profile, creating its
+// metadata when the subreddit was never scraped
+func (s *MongoStorage) SetSubredditActivity(ctx context.Context, subredditName string, profile models.ActivityProfile) error {
+	collection := s.database.Collection(SubredditMetadataCollection)
+
+	now := s.clock.Now().UTC()
+	filter := bson.M{"subreddit_name": subredditName}
+	update := bson.M{
+		"$set":         bson.M{"activity": profile, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetAllSubredditMetadata lists metadata by subreddit name; without a limit
+// more than MaxUnboundedResults documents return ErrTruncated with the first ones
+func (s *MongoStorage) GetAllSubredditMetadata(ctx context.Context, listOpts ListOptions) ([]models.SubredditMetadata, error) {
 	collection := s.database.Collection(SubredditMetadataCollection)
 	
-	cursor, err := collection.Find(ctx, bson.M{})
+	opts := findOptions(listOpts).SetSort(bson.D{{Key: "subreddit_name", Value: 1}})
+	unbounded := listOpts.Limit <= 0
+	if unbounded {
+		opts.SetLimit(int64(MaxUnboundedResults) + 1)
+	}
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +543,9 @@ func (s *MongoStorage) GetAllSubredditMetadata(ctx context.Context) ([]models.Su
 	if err := cursor.All(ctx, &metadatas); err != nil {
 		return nil, err
 	}
+	if unbounded && len(metadatas) > MaxUnboundedResults {
+		return metadatas[:MaxUnboundedResults], truncatedError()
+	}
 
 	return metadatas, nil
 }
@@ -175,42 +557,46 @@ func (s *MongoStorage) UpsertPost(ctx context.Context, post *models.Post) error
 		return fmt.Errorf("invalid post data: reddit_id and title are required")
 	}
 
-	collection := s.database.Collection(SubredditPostsCollection)
-	
+	if err := s.moveLegacyPosts(ctx, []string{post.RedditID}); err != nil {
+		return err
+	}
+	collection, err := s.postsCollectionFor(ctx, post.CreatedAt)
+	if err != nil {
+		return err
+	}
+
 	filter := bson.M{"reddit_id": post.RedditID}
 
-	now := time.Now()
+	now := s.clock.Now().UTC()
 	post.UpdatedAt = now
 	if post.InsertedAt.IsZero() {
 		post.InsertedAt = now
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"reddit_id":   post.RedditID,
-			"title":       post.Title,
-			"body":        post.Body,
-			"author":      post.Author,
-			"score":       post.Score,
-			"subreddit":   post.Subreddit,
-			"url":         post.URL,
-			"flair":       post.Flair,
-			"created_at":  post.CreatedAt,
-			"updated_at":  post.UpdatedAt,
-		},
-		"$setOnInsert": bson.M{
-			"inserted_at": post.InsertedAt,
-		},
+	post.ContentHash = contentHash(post.Title, post.Body)
+
+	previous, err := s.getStoredContent(ctx, []models.Post{*post}, false)
+	if err != nil {
+		return err
 	}
+	post.Tags = models.KeepAnomalyTags(post.Tags, previous[post.RedditID].Tags)
+
+	update := postUpdate(post)
 
 	opts := options.Update().SetUpsert(true)
-	_, err := collection.UpdateOne(ctx, filter, update, opts)
+	_, err = collection.UpdateOne(ctx, filter, update, opts)
 	return err
 }
 
-func (s *MongoStorage) UpsertPosts(ctx context.Context, posts []models.Post) error {
+func (s *MongoStorage) UpsertPosts(ctx context.Context, posts []models.Post, upsertOpts UpsertOptions) (UpsertResult, error) {
+	result, err := s.upsertPosts(ctx, posts, upsertOpts)
+	return result, observe(OpUpsertPosts, err)
+}
+
+func (s *MongoStorage) upsertPosts(ctx context.Context, posts []models.Post, upsertOpts UpsertOptions) (UpsertResult, error) {
+	var result UpsertResult
 	if len(posts) == 0 {
-		return nil
+		return result, nil
 	}
 
 	// Filter and validate posts before bulk operation
@@ -224,6 +610,7 @@ func (s *MongoStorage) UpsertPosts(ctx context.Context, posts []models.Post) err
 			post.Body = strings.TrimSpace(post.Body)
 			post.Author = strings.TrimSpace(post.Author)
 			post.URL = strings.TrimSpace(post.URL)
+			post.Permalink = strings.TrimSpace(post.Permalink)
 			post.Flair = strings.TrimSpace(post.Flair)
 			
 			validPosts = append(validPosts, post)
@@ -231,48 +618,79 @@ func (s *MongoStorage) UpsertPosts(ctx context.Context, posts []models.Post) err
 	}
 
 	if len(validPosts) == 0 {
-		return fmt.Errorf("no valid posts to insert")
+		return result, &OperationError{Op: OpUpsertPosts, Category: ErrorValidation, Err: fmt.Errorf("no valid posts to insert")}
+	}
+
+	// Flair and tags (and with TrackRevisions, title/body) of posts we already
+	// have, to detect flair changes and edits and keep anomaly tags
+	previous, err := s.getStoredContent(ctx, validPosts, upsertOpts.TrackRevisions)
+	if err != nil {
+		return result, fmt.Errorf("failed to load stored posts for change tracking: %w", err)
+	}
+
+	// Posts still in subreddit_post move to their partition first, so the
+	// upserts below update them instead of storing a second copy
+	var legacy []string
+	for redditID, prev := range previous {
+		if prev.collection == SubredditPostsCollection {
+			legacy = append(legacy, redditID)
+		}
+	}
+	if err := s.moveLegacyPosts(ctx, legacy); err != nil {
+		return result, fmt.Errorf("failed to move posts to their partitions: %w", err)
 	}
 
 	// Use individual upserts to handle duplicates gracefully
-	collection := s.database.Collection(SubredditPostsCollection)
-	now := time.Now()
-	
+	now := s.clock.Now().UTC()
+
 	successCount := 0
 	errorCount := 0
+	var lastErr error
 
 	for _, post := range validPosts {
 		post.UpdatedAt = now
 		if post.InsertedAt.IsZero() {
 			post.InsertedAt = now
 		}
+		post.ContentHash = contentHash(post.Title, post.Body)
+		// A burst-author tag written between loading and this upsert is lost;
+		// the next anomaly run, which covers the lookback, adds it again
+		prev, exists := previous[post.RedditID]
+		post.Tags = models.KeepAnomalyTags(post.Tags, prev.Tags)
 
 		filter := bson.M{"reddit_id": post.RedditID}
-		update := bson.M{
-			"$set": bson.M{
-				"reddit_id":   post.RedditID,
-				"title":       post.Title,
-				"body":        post.Body,
-				"author":      post.Author,
-				"score":       post.Score,
-				"subreddit":   post.Subreddit,
-				"url":         post.URL,
-				"flair":       post.Flair,
-				"created_at":  post.CreatedAt,
-				"updated_at":  post.UpdatedAt,
-			},
-			"$setOnInsert": bson.M{
-				"inserted_at": post.InsertedAt,
-			},
+		update := postUpdate(&post)
+		if upsertOpts.ImportBatch != "" {
+			update["$setOnInsert"].(bson.M)["import_batch"] = upsertOpts.ImportBatch
+		}
+
+		if exists && upsertOpts.TrackRevisions && prev.hash() != post.ContentHash {
+			if err := s.saveRevision(ctx, post.RedditID, prev, now); err != nil {
+				fmt.Printf("Failed to save revision for post %s: %v\n", post.RedditID, err)
+			} else {
+				update["$inc"] = bson.M{"revision_count": 1}
+			}
+		}
+		if exists && prev.Flair != post.Flair {
+			addFlairChange(update, prev.Flair, post.Flair, now)
+		}
+
+		collection, err := s.postsCollectionFor(ctx, post.CreatedAt)
+		if err != nil {
+			return result, err
 		}
 
 		opts := options.Update().SetUpsert(true)
-		_, err := collection.UpdateOne(ctx, filter, update, opts)
+		updateResult, err := collection.UpdateOne(ctx, filter, update, opts)
 		if err != nil {
-			fmt.Printf("Failed to upsert post %s: %v\n", post.RedditID, err)
+			fmt.Printf("Failed to upsert post %s (%s): %v\n", post.RedditID, ClassifyError(err), err)
 			errorCount++
+			lastErr = err
 		} else {
 			successCount++
+			if updateResult.UpsertedCount > 0 {
+				result.InsertedIDs = append(result.InsertedIDs, post.RedditID)
+			}
 		}
 	}
 
@@ -280,101 +698,394 @@ func (s *MongoStorage) UpsertPosts(ctx context.Context, posts []models.Post) err
 	
 	// Only return error if all operations failed
 	if errorCount > 0 && successCount == 0 {
-		return fmt.Errorf("all post insertions failed")
+		return result, fmt.Errorf("all %d post insertions failed, last error: %w", errorCount, lastErr)
 	}
 
-	return nil
+	return result, nil
 }
 
-func (s *MongoStorage) GetPostsBySubreddit(ctx context.Context, subreddit string, limit int) ([]models.Post, error) {
-	collection := s.database.Collection(SubredditPostsCollection)
-	
-	filter := bson.M{"subreddit": subreddit}
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-	if limit > 0 {
-		opts.SetLimit(int64(limit))
+// findOptions applies ListOptions projection and paging to a Find
+func findOptions(listOpts ListOptions) *options.FindOptions {
+	opts := options.Find()
+	if len(listOpts.Fields) > 0 {
+		projection := bson.M{}
+		for _, field := range listOpts.Fields {
+			projection[field] = 1
+		}
+		opts.SetProjection(projection)
+	}
+	if listOpts.Skip > 0 {
+		opts.SetSkip(listOpts.Skip)
 	}
+	if listOpts.Limit > 0 {
+		opts.SetLimit(listOpts.Limit)
+	}
+	return opts
+}
 
-	cursor, err := collection.Find(ctx, filter, opts)
+// postUpdate builds the upsert of a post. Its tags replace the stored ones, so
+// tags of removed or changed tag rules go; callers add the stored anomaly tags
+// to keep with models.KeepAnomalyTags first.
+func postUpdate(post *models.Post) bson.M {
+	set := postSetFields(post)
+	update := bson.M{
+		"$set": set,
+		"$setOnInsert": bson.M{
+			"inserted_at": post.InsertedAt,
+			"status":      models.PostStatusVisible,
+		},
+	}
+	if len(post.Tags) > 0 {
+		set["tags"] = post.Tags
+	} else {
+		update["$unset"] = bson.M{"tags": ""}
+	}
+	return update
+}
+
+// postSetFields lists the fields refreshed on every upsert of a post
+func postSetFields(post *models.Post) bson.M {
+	fields := bson.M{
+		"reddit_id":      post.RedditID,
+		"title":          post.Title,
+		"body":           post.Body,
+		"author":         post.Author,
+		"score":          post.Score,
+		"subreddit":      post.Subreddit,
+		"url":            post.URL,
+		"normalized_url": post.NormalizedURL,
+		"domain":         post.Domain,
+		"permalink":      post.Permalink,
+		"flair":          post.Flair,
+		"is_nsfw":        post.IsNSFW,
+		"spoiler":        post.Spoiler,
+		"nsfw_unknown":   post.NSFWUnknown,
+		"content_hash":   post.ContentHash,
+		"created_at":     post.CreatedAt,
+		"updated_at":     post.UpdatedAt,
+	}
+	// Unenriched batches (fail-open) keep extras from earlier runs
+	if len(post.Extras) > 0 {
+		fields["extras"] = post.Extras
+	}
+	return fields
+}
+
+// FindPosts returns one page of posts matching filter in its sort order
+func (s *MongoStorage) FindPosts(ctx context.Context, filter PostFilter) (PostPage, error) {
+	page, err := s.findPosts(ctx, filter)
+	return page, observe(OpGetPosts, err)
+}
+
+func (s *MongoStorage) findPosts(ctx context.Context, filter PostFilter) (PostPage, error) {
+	limit := filter.Limit
+	unbounded := limit <= 0
+	if unbounded {
+		limit = MaxUnboundedResults
+	}
+
+	// One extra post tells whether another page follows
+	cursor, err := s.postsCursor(ctx, filter, limit+1)
 	if err != nil {
-		return nil, err
+		return PostPage{}, err
 	}
 	defer cursor.Close(ctx)
 
 	var posts []models.Post
 	if err := cursor.All(ctx, &posts); err != nil {
+		return PostPage{}, err
+	}
+
+	page := PostPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		page.NextCursor = filter.nextCursor(page.Posts[limit-1])
+		page.Truncated = unbounded
+	}
+	return page, nil
+}
+
+// IteratePosts calls fn with every post matching filter in its sort order,
+// decoding one at a time, up to filter.Limit posts when it is set. It stops
+// at the first error fn returns and returns it.
+func (s *MongoStorage) IteratePosts(ctx context.Context, filter PostFilter, fn func(models.Post) error) error {
+	cursor, err := s.postsCursor(ctx, filter, filter.Limit)
+	if err != nil {
+		return observe(OpGetPosts, err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post models.Post
+		if err := cursor.Decode(&post); err != nil {
+			return observe(OpGetPosts, err)
+		}
+		if err := fn(post); err != nil {
+			return err
+		}
+	}
+	return observe(OpGetPosts, cursor.Err())
+}
+
+// postsCursor opens a cursor over the posts matching filter across the
+// collections its time range covers; limit <= 0 leaves it unlimited
+func (s *MongoStorage) postsCursor(ctx context.Context, filter PostFilter, limit int) (*mongo.Cursor, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, &OperationError{Op: OpGetPosts, Category: ErrorValidation, Err: err}
+	}
+	query, err := filter.bson()
+	if err != nil {
+		return nil, err
+	}
+
+	// updated_at can fall in any partition, so only created_at bounds narrow them
+	var from, to time.Time
+	if !filter.TimeRange.MatchUpdated {
+		from, to = filter.TimeRange.From, filter.TimeRange.To
+	}
+	names, err := s.postCollectionNames(ctx, from, to)
+	if err != nil {
 		return nil, err
 	}
 
-	return posts, nil
+	if len(names) == 1 {
+		opts := options.Find().SetSort(filter.sort())
+		if limit > 0 {
+			opts.SetLimit(int64(limit))
+		}
+		if projection := filter.projection(); projection != nil {
+			opts.SetProjection(projection)
+		}
+		return s.database.Collection(names[0]).Find(ctx, query, opts)
+	}
+
+	stages := bson.A{bson.M{"$match": query}, bson.M{"$sort": filter.sort()}}
+	if limit > 0 {
+		stages = append(stages, bson.M{"$limit": limit})
+	}
+	if projection := filter.projection(); projection != nil {
+		stages = append(stages, bson.M{"$project": projection})
+	}
+	return s.aggregatePosts(ctx, names, stages, stages[1:])
+}
+
+// GetPostsBySubreddit returns up to limit posts of a subreddit; without a
+// limit more than MaxUnboundedResults matches return ErrTruncated with the
+// first ones
+func (s *MongoStorage) GetPostsBySubreddit(ctx context.Context, subreddit string, limit int, queryOpts PostQueryOptions) ([]models.Post, error) {
+	page, err := s.FindPosts(ctx, PostFilter{
+		Subreddit:      subreddit,
+		Tags:           queryOpts.Tags,
+		ExcludeNSFW:    queryOpts.ExcludeNSFW,
+		Extras:         queryOpts.Extras,
+		Sort:           queryOpts.Sort,
+		Fields:         queryOpts.Fields,
+		IncludeDeleted: true,
+		Limit:          limit,
+	})
+	if err == nil && page.Truncated {
+		err = truncatedError()
+	}
+	return page.Posts, err
 }
 
 func (s *MongoStorage) GetPostByRedditID(ctx context.Context, redditID string) (*models.Post, error) {
-	collection := s.database.Collection(SubredditPostsCollection)
-	
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	filter := bson.M{"reddit_id": redditID}
 
-	var post models.Post
-	err := collection.FindOne(ctx, filter).Decode(&post)
-	if err != nil {
+	for _, name := range names {
+		var post models.Post
+		err := s.database.Collection(name).FindOne(ctx, filter).Decode(&post)
 		if err == mongo.ErrNoDocuments {
-			return nil, nil
+			continue
 		}
+		if err != nil {
+			return nil, err
+		}
+		return &post, nil
+	}
+
+	return nil, nil
+}
+
+// GetExistingRedditIDs reports which of the given reddit IDs are already stored
+func (s *MongoStorage) GetExistingRedditIDs(ctx context.Context, redditIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(redditIDs) == 0 {
+		return existing, nil
+	}
+
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	return &post, nil
+	filter := bson.M{"reddit_id": bson.M{"$in": redditIDs}}
+	opts := options.Find().SetProjection(bson.M{"reddit_id": 1, "_id": 0})
+	for _, name := range names {
+		cursor, err := s.database.Collection(name).Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var docs []struct {
+			RedditID string `bson:"reddit_id"`
+		}
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			existing[doc.RedditID] = true
+		}
+	}
+
+	return existing, nil
 }
 
+// GetRecentPosts returns a subreddit's posts updated in the last hours,
+// returning ErrTruncated past MaxUnboundedResults. Imported posts are left out.
 func (s *MongoStorage) GetRecentPosts(ctx context.Context, subreddit string, hours int) ([]models.Post, error) {
-	collection := s.database.Collection(SubredditPostsCollection)
-	
-	cutoff := time.Now().Add(-time.Duration(hours) * time.Hour)
-	filter := bson.M{
-		"subreddit": subreddit,
-		"$or": []bson.M{
-			{"created_at": bson.M{"$gte": cutoff}},
-			{"updated_at": bson.M{"$gte": cutoff}},
+	page, err := s.FindPosts(ctx, PostFilter{
+		Subreddit: subreddit,
+		TimeRange: TimeRange{
+			From:         s.clock.Now().UTC().Add(-time.Duration(hours) * time.Hour),
+			MatchUpdated: true,
 		},
+		IncludeDeleted:  true,
+		ExcludeImported: true,
+	})
+	if err == nil && page.Truncated {
+		err = truncatedError()
 	}
+	return page.Posts, err
+}
 
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-	cursor, err := collection.Find(ctx, filter, opts)
+// GetRecentPostsAllSubreddits returns posts created since the given time
+// across all subreddits, newest first. Each subreddit contributes at most
+// perSubredditCap posts (one indexed, limited query per subreddit) and the
+// merged feed is cut to totalCap. Deleted, removed and imported posts are
+// left out.
+func (s *MongoStorage) GetRecentPostsAllSubreddits(ctx context.Context, since time.Time, perSubredditCap, totalCap int) ([]models.Post, error) {
+	if perSubredditCap <= 0 {
+		return nil, fmt.Errorf("per-subreddit cap must be positive")
+	}
+
+	names, err := s.postCollectionNames(ctx, since, time.Time{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var posts []models.Post
-	if err := cursor.All(ctx, &posts); err != nil {
-		return nil, err
+	subreddits := make(map[string]bool)
+	for _, name := range names {
+		values, err := s.database.Collection(name).Distinct(ctx, "subreddit", bson.M{"created_at": bson.M{"$gte": since}})
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if subreddit, ok := value.(string); ok && subreddit != "" {
+				subreddits[subreddit] = true
+			}
+		}
+	}
+
+	var feed []models.Post
+	for subreddit := range subreddits {
+
+		page, err := s.FindPosts(ctx, PostFilter{
+			Subreddit:       subreddit,
+			TimeRange:       TimeRange{From: since},
+			Limit:           perSubredditCap,
+			ExcludeImported: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetching feed posts for r/%s: %w", subreddit, err)
+		}
+		feed = append(feed, page.Posts...)
 	}
 
-	return posts, nil
+	sort.Slice(feed, func(i, j int) bool {
+		if !feed[i].CreatedAt.Equal(feed[j].CreatedAt) {
+			return feed[i].CreatedAt.After(feed[j].CreatedAt)
+		}
+		return feed[i].ID.Hex() > feed[j].ID.Hex()
+	})
+	if totalCap > 0 && len(feed) > totalCap {
+		feed = feed[:totalCap]
+	}
+
+	return feed, nil
 }
 
 func (s *MongoStorage) GetPostsCount(ctx context.Context, subreddit string) (int64, error) {
-	collection := s.database.Collection(SubredditPostsCollection)
-	
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	filter := bson.M{}
 	if subreddit != "" {
 		filter["subreddit"] = subreddit
 	}
 
-	count, err := collection.CountDocuments(ctx, filter)
+	var total int64
+	for _, name := range names {
+		count, err := s.database.Collection(name).CountDocuments(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
+// Subreddit config operations. Soft-deleted configs are excluded unless a
+// method says otherwise.
+
+// GetAllSubredditConfigs returns the page of configs matching filter and how
+// many match in total
+func (s *MongoStorage) GetAllSubredditConfigs(ctx context.Context, filter ConfigFilter, listOpts ListOptions) (ConfigPage, error) {
+	if err := filter.Validate(); err != nil {
+		return ConfigPage{}, err
+	}
+	collection := s.database.Collection(SubredditConfigCollection)
+	query, err := filter.bson()
+	if err != nil {
+		return ConfigPage{}, err
+	}
+
+	opts := findOptions(listOpts).SetSort(filter.sort())
+	cursor, err := collection.Find(ctx, query, opts)
 	if err != nil {
-		return 0, err
+		return ConfigPage{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var configs []models.SubredditConfig
+	if err := cursor.All(ctx, &configs); err != nil {
+		return ConfigPage{}, err
 	}
 
-	return count, nil
+	page := ConfigPage{Configs: configs, Total: int64(len(configs))}
+	if listOpts.Skip > 0 || (listOpts.Limit > 0 && page.Total == listOpts.Limit) {
+		// Only a partial page needs counting
+		if page.Total, err = collection.CountDocuments(ctx, query); err != nil {
+			return ConfigPage{}, err
+		}
+	}
+	return page, nil
 }
 
-// Subreddit config operations
-func (s *MongoStorage) GetAllSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error) {
+func (s *MongoStorage) GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error) {
 	collection := s.database.Collection(SubredditConfigCollection)
 	
+	filter := bson.M{"enabled": true, "deleted_at": bson.M{"$exists": false}}
 	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "subreddit_name", Value: 1}})
-	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	cursor, err := collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -388,11 +1099,13 @@ func (s *MongoStorage) GetAllSubredditConfigs(ctx context.Context) ([]models.Sub
 	return configs, nil
 }
 
-func (s *MongoStorage) GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error) {
+// GetSubredditConfigsByLabel returns the live configs carrying label, which
+// must already be normalized
+func (s *MongoStorage) GetSubredditConfigsByLabel(ctx context.Context, label string, listOpts ListOptions) ([]models.SubredditConfig, error) {
 	collection := s.database.Collection(SubredditConfigCollection)
-	
-	filter := bson.M{"enabled": true}
-	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "subreddit_name", Value: 1}})
+
+	filter := bson.M{"labels": label, "deleted_at": bson.M{"$exists": false}}
+	opts := findOptions(listOpts).SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "subreddit_name", Value: 1}})
 	cursor, err := collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
@@ -407,44 +1120,68 @@ func (s *MongoStorage) GetActiveSubredditConfigs(ctx context.Context) ([]models.
 	return configs, nil
 }
 
-func (s *MongoStorage) UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig) error {
+// UpsertSubredditConfig validates and saves a config, recording the create or
+// the changed fields in the config audit. Saves that change nothing are not audited.
+func (s *MongoStorage) UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig, actor string) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid subreddit config: %w", err)
+	}
+
 	collection := s.database.Collection(SubredditConfigCollection)
 	
-	filter := bson.M{"subreddit_name": config.SubredditName}
+	filter := liveConfigFilter(config.SubredditName)
 
-	now := time.Now()
+	previous, err := s.GetSubredditConfig(ctx, config.SubredditName)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().UTC()
 	config.UpdatedAt = now
 	if config.CreatedAt.IsZero() {
 		config.CreatedAt = now
 	}
 
+	fields := subredditConfigFields(config)
+	set := bson.M{"updated_at": config.UpdatedAt}
+	for key, value := range fields {
+		set[key] = value
+	}
 	update := bson.M{
-		"$set": bson.M{
-			"subreddit_name": config.SubredditName,
-			"enabled":        config.Enabled,
-			"schedule":       config.Schedule,
-			"max_posts":      config.MaxPosts,
-			"priority":       config.Priority,
-			"description":    config.Description,
-			"updated_at":     config.UpdatedAt,
-		},
+		"$set": set,
 		"$setOnInsert": bson.M{
 			"created_at": config.CreatedAt,
 		},
 	}
 
 	opts := options.Update().SetUpsert(true)
-	_, err := collection.UpdateOne(ctx, filter, update, opts)
-	return err
+	if _, err := collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		return err
+	}
+
+	audit := models.ConfigAudit{
+		SubredditName: config.SubredditName,
+		Action:        models.ConfigAuditCreate,
+		Actor:         actor,
+		CreatedAt:     now,
+	}
+	if previous != nil {
+		audit.Action = models.ConfigAuditUpdate
+		audit.Changes = diffConfigFields(subredditConfigFields(previous), fields)
+		if len(audit.Changes) == 0 {
+			return nil
+		}
+	} else {
+		audit.Changes = diffConfigFields(bson.M{}, fields)
+	}
+	return s.insertConfigAudit(ctx, audit)
 }
 
 func (s *MongoStorage) GetSubredditConfig(ctx context.Context, subredditName string) (*models.SubredditConfig, error) {
 	collection := s.database.Collection(SubredditConfigCollection)
 	
-	filter := bson.M{"subreddit_name": subredditName}
-
 	var config models.SubredditConfig
-	err := collection.FindOne(ctx, filter).Decode(&config)
+	err := collection.FindOne(ctx, liveConfigFilter(subredditName)).Decode(&config)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -455,16 +1192,11 @@ func (s *MongoStorage) GetSubredditConfig(ctx context.Context, subredditName str
 	return &config, nil
 }
 
-func (s *MongoStorage) DeleteSubredditConfig(ctx context.Context, subredditName string) error {
-	collection := s.database.Collection(SubredditConfigCollection)
-	
-	filter := bson.M{"subreddit_name": subredditName}
-	_, err := collection.DeleteOne(ctx, filter)
-	return err
-}
 // Health check and cleanup
+// Ping reports whether storage answers; HealthInfo has the details
 func (s *MongoStorage) Ping(ctx context.Context) error {
-	return s.client.Ping(ctx, nil)
+	_, err := s.HealthInfo(ctx)
+	return err
 }
 
 func (s *MongoStorage) Close() error {
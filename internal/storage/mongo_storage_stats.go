@@ -0,0 +1,125 @@
+// internal/storage/mongo_storage_stats.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ErrStorageStatsSkipped is returned when the posts are too many to scan
+// without StorageStatsOptions.Force
+var ErrStorageStatsSkipped = errors.New("storage stats skipped: posts exceed the document limit")
+
+// StorageStatsOptions bounds the storage stats aggregation, which reads every post
+type StorageStatsOptions struct {
+	// MaxDocuments skips the scan when the estimated post count is larger (0 = no limit)
+	MaxDocuments int64
+	// Timeout is the server-side time limit of each collection's aggregation
+	Timeout time.Duration
+	// Force scans regardless of MaxDocuments
+	Force bool
+}
+
+// RefreshSubredditStorageStats sums the BSON size and count of every
+// subreddit's posts, replaces the storage_stats cache with the result and
+// returns it. $bsonSize needs MongoDB 4.4 or later.
+func (s *MongoStorage) RefreshSubredditStorageStats(ctx context.Context, opts StorageStatsOptions) ([]models.SubredditStorageStats, error) {
+	names, err := s.allPostCollectionNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.Force && opts.MaxDocuments > 0 {
+		var estimated int64
+		for _, name := range names {
+			count, err := s.database.Collection(name).EstimatedDocumentCount(ctx)
+			if err != nil {
+				return nil, err
+			}
+			estimated += count
+		}
+		if estimated > opts.MaxDocuments {
+			return nil, fmt.Errorf("%w (about %d posts, limit %d)", ErrStorageStatsSkipped, estimated, opts.MaxDocuments)
+		}
+	}
+
+	pipeline := bson.A{
+		bson.M{"$group": bson.M{
+			"_id":       "$subreddit",
+			"documents": bson.M{"$sum": 1},
+			"bytes":     bson.M{"$sum": bson.M{"$bsonSize": "$$ROOT"}},
+		}},
+	}
+	aggregateOpts := options.Aggregate().SetAllowDiskUse(true).SetMaxTime(opts.Timeout)
+
+	// Partitions are aggregated one at a time and summed, which keeps each
+	// aggregation within its own time limit
+	bySubreddit := make(map[string]*models.SubredditStorageStats)
+	for _, name := range names {
+		cursor, err := s.database.Collection(name).Aggregate(ctx, pipeline, aggregateOpts)
+		if err != nil {
+			return nil, fmt.Errorf("sizing %s: %w", name, err)
+		}
+		var partial []models.SubredditStorageStats
+		if err := cursor.All(ctx, &partial); err != nil {
+			return nil, fmt.Errorf("sizing %s: %w", name, err)
+		}
+		for _, stat := range partial {
+			total, ok := bySubreddit[stat.Subreddit]
+			if !ok {
+				total = &models.SubredditStorageStats{Subreddit: stat.Subreddit}
+				bySubreddit[stat.Subreddit] = total
+			}
+			total.Documents += stat.Documents
+			total.Bytes += stat.Bytes
+		}
+	}
+
+	now := s.clock.Now().UTC()
+	collection := s.database.Collection(StorageStatsCollection)
+	writes := make([]mongo.WriteModel, 0, len(bySubreddit))
+	for _, stat := range bySubreddit {
+		stat.ComputedAt = now
+		if stat.Documents > 0 {
+			stat.AvgBytes = stat.Bytes / stat.Documents
+		}
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": stat.Subreddit}).
+			SetReplacement(stat).
+			SetUpsert(true))
+	}
+	if len(writes) > 0 {
+		if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return nil, err
+		}
+	}
+	// Subreddits whose posts are all gone
+	if _, err := collection.DeleteMany(ctx, bson.M{"computed_at": bson.M{"$lt": now}}); err != nil {
+		return nil, err
+	}
+
+	return s.GetSubredditStorageStats(ctx)
+}
+
+// GetSubredditStorageStats returns the cached footprints, largest first
+func (s *MongoStorage) GetSubredditStorageStats(ctx context.Context) ([]models.SubredditStorageStats, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "bytes", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := s.database.Collection(StorageStatsCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []models.SubredditStorageStats{}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,356 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/models"
+)
+
+// newTestStorage connects to the MongoDB at MONGODB_TEST_URI using a fresh
+// database that is dropped when the test ends. Without MONGODB_TEST_URI the
+// test is skipped, so go test needs no external services by default.
+func newTestStorage(t testing.TB, partitioning PartitionOptions) *MongoStorage {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+
+	name := fmt.Sprintf("orchestrator_test_%d", time.Now().UnixNano())
+	s, err := NewMongoStorage(uri, name, 0, partitioning, clock.Real)
+	if err != nil {
+		t.Fatalf("NewMongoStorage() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = s.database.Drop(context.Background())
+		_ = s.Close()
+	})
+	return s
+}
+
+func TestPostUpdateSetsTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      []string
+		wantUnset bool
+	}{
+		{name: "tagged post", tags: []string{"release", "security"}},
+		{name: "untagged post", tags: nil, wantUnset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := postUpdate(&models.Post{RedditID: "abc", Title: "title", Tags: tt.tags})
+
+			// Added tags would keep those of deleted tag rules
+			if _, ok := update["$addToSet"]; ok {
+				t.Fatalf("update adds to tags: %v", update)
+			}
+			set, _ := update["$set"].(bson.M)["tags"].([]string)
+			_, unset := update["$unset"]
+			if unset != tt.wantUnset || !slices.Equal(set, tt.tags) {
+				t.Errorf("$set tags = %v, $unset tags %v; want %v, %v", set, unset, tt.tags, tt.wantUnset)
+			}
+		})
+	}
+}
+
+func TestRescrapeReplacesTagsKeepingAnomalyTags(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	post := models.Post{RedditID: "t3_a", Title: "go 1.23", Author: "alice", Subreddit: "golang", CreatedAt: created, Tags: []string{"release"}}
+	if _, err := s.UpsertPosts(ctx, []models.Post{post}, UpsertOptions{}); err != nil {
+		t.Fatalf("UpsertPosts() error = %v", err)
+	}
+	if err := s.AddTagToAuthorPosts(ctx, "golang", "alice", created, created.Add(time.Hour), models.TagBurstAuthor); err != nil {
+		t.Fatalf("AddTagToAuthorPosts() error = %v", err)
+	}
+
+	// The release rule was deleted and a security rule added since
+	post.Tags = []string{"security"}
+	if _, err := s.UpsertPosts(ctx, []models.Post{post}, UpsertOptions{}); err != nil {
+		t.Fatalf("UpsertPosts() error = %v", err)
+	}
+	stored, err := s.GetPostByRedditID(ctx, "t3_a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"security", models.TagBurstAuthor}; !slices.Equal(stored.Tags, want) {
+		t.Errorf("tags after the rescrape = %v, want %v", stored.Tags, want)
+	}
+}
+
+func TestMetadataUpdateWritesExplicitZeroMonitorConfig(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		metadata models.SubredditMetadata
+		wantSet  bool
+	}{
+		{name: "nil monitor config", metadata: models.SubredditMetadata{SubredditName: "golang"}, wantSet: false},
+		{name: "zero monitor config", metadata: models.SubredditMetadata{SubredditName: "golang", MonitorConfig: &models.MonitorConfig{}}, wantSet: true},
+		{name: "set monitor config", metadata: models.SubredditMetadata{SubredditName: "golang", MonitorConfig: &models.MonitorConfig{Enabled: true, MaxPosts: 50}}, wantSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := metadataUpdate(&tt.metadata, now)["$set"].(bson.M)
+			got, ok := set["monitor_config"]
+			if ok != tt.wantSet {
+				t.Fatalf("monitor_config set = %v, want %v", ok, tt.wantSet)
+			}
+			if ok && *got.(*models.MonitorConfig) != *tt.metadata.MonitorConfig {
+				t.Errorf("monitor_config = %+v, want %+v", got, tt.metadata.MonitorConfig)
+			}
+		})
+	}
+}
+
+// A scrape advancing the cursor must not touch metadata other flows wrote
+func TestUpdateLastScrapedKeepsCustomMetadata(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	custom := &models.MonitorConfig{Enabled: false, MaxPosts: 0}
+	if err := s.UpsertSubredditMetadata(ctx, &models.SubredditMetadata{SubredditName: "golang", MonitorConfig: custom}); err != nil {
+		t.Fatalf("UpsertSubredditMetadata() error = %v", err)
+	}
+	if err := s.SetSubredditStale(ctx, "golang", true); err != nil {
+		t.Fatalf("SetSubredditStale() error = %v", err)
+	}
+
+	scrapedAt := time.Now().UTC().Truncate(time.Millisecond)
+	if err := s.UpdateLastScraped(ctx, "golang", scrapedAt, models.RunStats{PostsFetched: 3}); err != nil {
+		t.Fatalf("UpdateLastScraped() error = %v", err)
+	}
+
+	metadata, err := s.GetSubredditMetadata(ctx, "golang")
+	if err != nil || metadata == nil {
+		t.Fatalf("GetSubredditMetadata() = %v, %v", metadata, err)
+	}
+	if metadata.MonitorConfig == nil || *metadata.MonitorConfig != *custom {
+		t.Errorf("MonitorConfig = %+v, want %+v", metadata.MonitorConfig, custom)
+	}
+	if !metadata.Stale {
+		t.Error("Stale was cleared by the scrape")
+	}
+	if !metadata.LastScrapedAt.Equal(scrapedAt) {
+		t.Errorf("LastScrapedAt = %v, want %v", metadata.LastScrapedAt, scrapedAt)
+	}
+}
+
+func TestExplainQueryShapesUseIndexes(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+
+	reports, err := s.ExplainQueryShapes(context.Background())
+	if err != nil {
+		t.Fatalf("ExplainQueryShapes() error = %v", err)
+	}
+	if len(reports) != len(canonicalQueryShapes()) {
+		t.Fatalf("got %d reports, want one per canonical shape", len(reports))
+	}
+	for _, report := range reports {
+		if !report.IndexUsed {
+			t.Errorf("%s on %s does not use an index: stages %v", report.Name, report.Collection, report.Stages)
+		}
+	}
+}
+
+func TestReleaseNotificationRule(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	rule := &models.NotificationRule{Name: "go", Enabled: true, Keywords: []string{"go"}, CooldownMinutes: 60}
+	if err := s.UpsertNotificationRule(ctx, rule); err != nil {
+		t.Fatalf("UpsertNotificationRule() error = %v", err)
+	}
+
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	claimed, err := s.ClaimNotificationRule(ctx, "go", first, time.Hour)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimNotificationRule() = %v, %v, want a claim", claimed, err)
+	}
+	if claimed, _ := s.ClaimNotificationRule(ctx, "go", first.Add(time.Minute), time.Hour); claimed {
+		t.Fatal("ClaimNotificationRule() claimed a rule inside its cooldown")
+	}
+
+	// Releasing a claim that is not the current one changes nothing
+	if err := s.ReleaseNotificationRule(ctx, "go", first.Add(-time.Hour)); err != nil {
+		t.Fatalf("ReleaseNotificationRule() error = %v", err)
+	}
+	if claimed, _ := s.ClaimNotificationRule(ctx, "go", first.Add(time.Minute), time.Hour); claimed {
+		t.Fatal("a stale release ended the cooldown")
+	}
+
+	if err := s.ReleaseNotificationRule(ctx, "go", first); err != nil {
+		t.Fatalf("ReleaseNotificationRule() error = %v", err)
+	}
+	if claimed, _ := s.ClaimNotificationRule(ctx, "go", first.Add(time.Minute), time.Hour); !claimed {
+		t.Fatal("ClaimNotificationRule() did not claim a released rule")
+	}
+}
+
+// BenchmarkMongoUpsertPosts upserts batches into the MongoDB at
+// MONGODB_TEST_URI and is skipped without it
+func BenchmarkMongoUpsertPosts(b *testing.B) {
+	s := newTestStorage(b, PartitionOptions{})
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, size := range []int{100, 1000, 10000} {
+		posts := make([]models.Post, size)
+		for i := range posts {
+			posts[i] = models.Post{
+				RedditID:  fmt.Sprintf("t3_%d_%06x", size, i),
+				Title:     fmt.Sprintf("post %d", i),
+				Body:      "Some body text that goes on for a while.",
+				Author:    fmt.Sprintf("author%d", i%50),
+				Subreddit: "golang",
+				CreatedAt: created.Add(-time.Duration(i) * time.Minute),
+			}
+		}
+		// The first iteration inserts, the rest update unchanged posts
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.UpsertPosts(ctx, posts, UpsertOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSubredditMetadataByNamesChunks(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// More names than one $in query takes, every third without metadata
+	names := make([]string, metadataLookupChunk+5)
+	for i := range names {
+		names[i] = fmt.Sprintf("sub%04d", i)
+		if i%3 == 0 {
+			continue
+		}
+		if err := s.UpsertSubredditMetadata(ctx, &models.SubredditMetadata{SubredditName: names[i], LastScrapedAt: base.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatalf("UpsertSubredditMetadata() error = %v", err)
+		}
+	}
+
+	metadatas, err := s.GetSubredditMetadataByNames(ctx, names)
+	if err != nil {
+		t.Fatalf("GetSubredditMetadataByNames() error = %v", err)
+	}
+	for i, name := range names {
+		metadata, ok := metadatas[name]
+		if ok != (i%3 != 0) {
+			t.Fatalf("r/%s present = %v, want %v", name, ok, i%3 != 0)
+		}
+		if want := base.Add(time.Duration(i) * time.Minute); ok && !metadata.LastScrapedAt.Equal(want) {
+			t.Errorf("r/%s LastScrapedAt = %v, want %v", name, metadata.LastScrapedAt, want)
+		}
+	}
+}
+
+func TestUpsertMovesLegacyPostsToPartitions(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{Monthly: true, DefaultMonths: 3})
+	ctx := context.Background()
+
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	inserted := created.Add(time.Minute)
+	// Stored before partitioning was enabled
+	legacy := bson.M{
+		"reddit_id": "t3_old", "title": "old title", "subreddit": "golang",
+		"created_at": created, "inserted_at": inserted, "import_batch": "snapshot-1",
+	}
+	if _, err := s.database.Collection(SubredditPostsCollection).InsertMany(ctx, []interface{}{
+		legacy,
+		bson.M{"reddit_id": "t3_single", "title": "single", "subreddit": "golang", "created_at": created, "inserted_at": inserted},
+	}); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+
+	result, err := s.UpsertPosts(ctx, []models.Post{
+		{RedditID: "t3_old", Title: "new title", Subreddit: "golang", CreatedAt: created},
+		{RedditID: "t3_new", Title: "new", Subreddit: "golang", CreatedAt: created},
+	}, UpsertOptions{})
+	if err != nil {
+		t.Fatalf("UpsertPosts() error = %v", err)
+	}
+	if len(result.InsertedIDs) != 1 || result.InsertedIDs[0] != "t3_new" {
+		t.Errorf("InsertedIDs = %v, want only t3_new", result.InsertedIDs)
+	}
+	if err := s.UpsertPost(ctx, &models.Post{RedditID: "t3_single", Title: "single", Subreddit: "golang", CreatedAt: created}); err != nil {
+		t.Fatalf("UpsertPost() error = %v", err)
+	}
+
+	left, err := s.database.Collection(SubredditPostsCollection).CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Errorf("%d posts left in %s, want 0", left, SubredditPostsCollection)
+	}
+
+	page, err := s.FindPosts(ctx, PostFilter{Subreddit: "golang"})
+	if err != nil {
+		t.Fatalf("FindPosts() error = %v", err)
+	}
+	if len(page.Posts) != 3 {
+		t.Fatalf("FindPosts() returned %d posts, want 3 without duplicates", len(page.Posts))
+	}
+	for _, post := range page.Posts {
+		if post.RedditID != "t3_old" {
+			continue
+		}
+		if post.Title != "new title" || !post.InsertedAt.Equal(inserted) || post.ImportBatch != "snapshot-1" {
+			t.Errorf("moved post = %+v, want the new title with the legacy inserted_at and import batch", post)
+		}
+	}
+}
+
+func TestSubredditConfigsVersionChangesOnUpsert(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	empty, err := s.GetSubredditConfigsVersion(ctx, "")
+	if err != nil {
+		t.Fatalf("GetSubredditConfigsVersion() error = %v", err)
+	}
+	if err := s.UpsertSubredditConfig(ctx, &models.SubredditConfig{SubredditName: "golang", Enabled: true, Labels: []string{"lang"}}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	created, err := s.GetSubredditConfigsVersion(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created == empty || created.Count != 1 {
+		t.Fatalf("version after create = %+v, want one config and a change from %+v", created, empty)
+	}
+
+	// Timestamps are stored to the millisecond, so wait one out
+	time.Sleep(2 * time.Millisecond)
+	if err := s.UpsertSubredditConfig(ctx, &models.SubredditConfig{SubredditName: "golang", Enabled: false, Labels: []string{"lang"}}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := s.GetSubredditConfigsVersion(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated == created {
+		t.Errorf("version %+v unchanged by an update", updated)
+	}
+	if other, err := s.GetSubredditConfigsVersion(ctx, "news"); err != nil || other.Count != 0 {
+		t.Errorf("version of another label = %+v, %v; want no configs", other, err)
+	}
+}
@@ -0,0 +1,88 @@
+// internal/storage/mongo_task_runs.go
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// monitorSubredditTask is the BlueBerry task whose runs carry a subreddit param
+const monitorSubredditTask = "monitor_subreddit"
+
+// taskRunLookupIndex serves GetLatestSubredditRuns: its $sort follows the
+// index, so $group can take each subreddit's newest run off the index
+var taskRunLookupIndex = mongo.IndexModel{
+	Keys: bson.D{
+		{Key: "taskname", Value: 1},
+		{Key: "params.subreddit", Value: 1},
+		{Key: "starttime", Value: -1},
+	},
+}
+
+// schedulerDatabase returns BlueBerry's database, or nil when none is
+// configured
+func (s *MongoStorage) schedulerDatabase() *mongo.Database {
+	if s.retention.SchedulerDatabase == "" {
+		return nil
+	}
+	return s.client.Database(s.retention.SchedulerDatabase)
+}
+
+// GetLatestSubredditRuns returns the newest monitor_subreddit run of each of
+// names from BlueBerry's task_runs, in one aggregation. Subreddits that never
+// ran are missing from the map.
+func (s *MongoStorage) GetLatestSubredditRuns(ctx context.Context, names []string) (map[string]models.TaskRunSummary, error) {
+	runs := make(map[string]models.TaskRunSummary, len(names))
+	db := s.schedulerDatabase()
+	if db == nil || len(names) == 0 {
+		return runs, nil
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"taskname": monitorSubredditTask, "params.subreddit": bson.M{"$in": names}}},
+		bson.M{"$sort": bson.D{{Key: "taskname", Value: 1}, {Key: "params.subreddit", Value: 1}, {Key: "starttime", Value: -1}}},
+		bson.M{"$group": bson.M{
+			"_id":       "$params.subreddit",
+			"id":        bson.M{"$first": "$id"},
+			"status":    bson.M{"$first": "$status"},
+			"starttime": bson.M{"$first": "$starttime"},
+			"endtime":   bson.M{"$first": "$endtime"},
+		}},
+	}
+	cursor, err := db.Collection("task_runs").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Subreddit string    `bson:"_id"`
+		ID        int       `bson:"id"`
+		Status    string    `bson:"status"`
+		StartTime time.Time `bson:"starttime"`
+		EndTime   time.Time `bson:"endtime"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		run := models.TaskRunSummary{
+			ID:        result.ID,
+			TaskName:  monitorSubredditTask,
+			Status:    result.Status,
+			StartedAt: result.StartTime.UTC(),
+		}
+		// BlueBerry leaves endtime zero until the run finishes
+		if !result.EndTime.IsZero() && !result.EndTime.Before(result.StartTime) {
+			ended := result.EndTime.UTC()
+			run.EndedAt = &ended
+			run.DurationMs = result.EndTime.Sub(result.StartTime).Milliseconds()
+		}
+		runs[result.Subreddit] = run
+	}
+	return runs, nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// withMaxUnboundedResults lowers the cap for one test
+func withMaxUnboundedResults(t *testing.T, max int) {
+	t.Helper()
+	previous := MaxUnboundedResults
+	MaxUnboundedResults = max
+	t.Cleanup(func() { MaxUnboundedResults = previous })
+}
+
+// seedTruncationPosts stores n recent posts of subreddit
+func seedTruncationPosts(t *testing.T, s *MongoStorage, subreddit string, n int) {
+	t.Helper()
+	created := time.Now().UTC().Add(-time.Hour)
+	for i := 0; i < n; i++ {
+		post := &models.Post{
+			RedditID:  fmt.Sprintf("t3_%s%d", subreddit, i),
+			Title:     fmt.Sprintf("post %d", i),
+			Subreddit: subreddit,
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+		if err := s.UpsertPost(context.Background(), post); err != nil {
+			t.Fatalf("UpsertPost() error = %v", err)
+		}
+	}
+}
+
+func TestUnboundedListingsSignalTruncation(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	withMaxUnboundedResults(t, 5)
+	seedTruncationPosts(t, s, "golang", 7)
+	seedTruncationPosts(t, s, "rust", 5)
+	for i := 0; i < 7; i++ {
+		if err := s.UpsertSubredditMetadata(ctx, &models.SubredditMetadata{SubredditName: fmt.Sprintf("sub%d", i)}); err != nil {
+			t.Fatalf("UpsertSubredditMetadata() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		list          func() (int, error)
+		wantCount     int
+		wantTruncated bool
+	}{
+		{
+			name: "posts by subreddit without a limit",
+			list: func() (int, error) {
+				posts, err := s.GetPostsBySubreddit(ctx, "golang", 0, PostQueryOptions{})
+				return len(posts), err
+			},
+			wantCount:     5,
+			wantTruncated: true,
+		},
+		{
+			name: "posts by subreddit with a limit past the cap",
+			list: func() (int, error) {
+				posts, err := s.GetPostsBySubreddit(ctx, "golang", 10, PostQueryOptions{})
+				return len(posts), err
+			},
+			wantCount: 7,
+		},
+		{
+			name: "exactly the cap is not truncated",
+			list: func() (int, error) {
+				posts, err := s.GetPostsBySubreddit(ctx, "rust", 0, PostQueryOptions{})
+				return len(posts), err
+			},
+			wantCount: 5,
+		},
+		{
+			name: "recent posts",
+			list: func() (int, error) {
+				posts, err := s.GetRecentPosts(ctx, "golang", 24)
+				return len(posts), err
+			},
+			wantCount:     5,
+			wantTruncated: true,
+		},
+		{
+			name: "metadata without a limit",
+			list: func() (int, error) {
+				metadatas, err := s.GetAllSubredditMetadata(ctx, ListOptions{})
+				return len(metadatas), err
+			},
+			wantCount:     5,
+			wantTruncated: true,
+		},
+		{
+			name: "metadata with a limit",
+			list: func() (int, error) {
+				metadatas, err := s.GetAllSubredditMetadata(ctx, ListOptions{Limit: 10})
+				return len(metadatas), err
+			},
+			wantCount: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := tt.list()
+			if truncated := errors.Is(err, ErrTruncated); truncated != tt.wantTruncated {
+				t.Fatalf("error = %v, want truncated %v", err, tt.wantTruncated)
+			}
+			if !tt.wantTruncated && err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if tt.wantTruncated && !strings.Contains(err.Error(), "MAX_UNBOUNDED_RESULTS") {
+				t.Errorf("error %q does not name MAX_UNBOUNDED_RESULTS", err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("returned %d results, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestFindPostsTruncatedPageContinues(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	withMaxUnboundedResults(t, 5)
+	seedTruncationPosts(t, s, "golang", 7)
+
+	page, err := s.FindPosts(ctx, PostFilter{Subreddit: "golang"})
+	if err != nil {
+		t.Fatalf("FindPosts() error = %v", err)
+	}
+	if !page.Truncated || len(page.Posts) != 5 || page.NextCursor == "" {
+		t.Fatalf("page has %d posts, truncated %v, cursor %q; want 5, truncated, with a cursor",
+			len(page.Posts), page.Truncated, page.NextCursor)
+	}
+
+	rest, err := s.FindPosts(ctx, PostFilter{Subreddit: "golang", Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("FindPosts() with the cursor error = %v", err)
+	}
+	if rest.Truncated || len(rest.Posts) != 2 || rest.NextCursor != "" {
+		t.Errorf("second page has %d posts, truncated %v, cursor %q; want the last 2", len(rest.Posts), rest.Truncated, rest.NextCursor)
+	}
+}
+
+func TestIteratePostsIgnoresTheCap(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+	withMaxUnboundedResults(t, 5)
+	seedTruncationPosts(t, s, "golang", 7)
+
+	seen := map[string]bool{}
+	err := s.IteratePosts(ctx, PostFilter{Subreddit: "golang"}, func(post models.Post) error {
+		seen[post.RedditID] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("IteratePosts() error = %v", err)
+	}
+	if len(seen) != 7 {
+		t.Errorf("iterated %d posts, want all 7", len(seen))
+	}
+
+	// A limit still applies, and fn's error stops the iteration
+	count := 0
+	if err := s.IteratePosts(ctx, PostFilter{Subreddit: "golang", Limit: 3}, func(models.Post) error { count++; return nil }); err != nil || count != 3 {
+		t.Errorf("IteratePosts() with limit 3 = %d posts, %v", count, err)
+	}
+	stop := errors.New("stop")
+	count = 0
+	err = s.IteratePosts(ctx, PostFilter{Subreddit: "golang"}, func(models.Post) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 2 {
+		t.Errorf("IteratePosts() = %v after %d posts, want fn's error after 2", err, count)
+	}
+}
@@ -0,0 +1,132 @@
+// internal/storage/mongo_users.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// User config operations
+func (s *MongoStorage) GetAllUserConfigs(ctx context.Context) ([]models.UserConfig, error) {
+	return s.findUserConfigs(ctx, bson.M{})
+}
+
+func (s *MongoStorage) GetActiveUserConfigs(ctx context.Context) ([]models.UserConfig, error) {
+	return s.findUserConfigs(ctx, bson.M{"enabled": true})
+}
+
+func (s *MongoStorage) findUserConfigs(ctx context.Context, filter bson.M) ([]models.UserConfig, error) {
+	collection := s.database.Collection(UserConfigCollection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "username", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var configs []models.UserConfig
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (s *MongoStorage) GetUserConfig(ctx context.Context, username string) (*models.UserConfig, error) {
+	collection := s.database.Collection(UserConfigCollection)
+
+	var config models.UserConfig
+	err := collection.FindOne(ctx, bson.M{"username": username}).Decode(&config)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (s *MongoStorage) UpsertUserConfig(ctx context.Context, config *models.UserConfig) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid user config: %w", err)
+	}
+
+	collection := s.database.Collection(UserConfigCollection)
+
+	now := s.clock.Now().UTC()
+	config.UpdatedAt = now
+	if config.CreatedAt.IsZero() {
+		config.CreatedAt = now
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"username":      config.Username,
+			"enabled":       config.Enabled,
+			"schedule":      config.Schedule,
+			"max_posts":     config.MaxPosts,
+			"skip_nsfw":     config.SkipNSFW,
+			"skip_spoilers": config.SkipSpoilers,
+			"updated_at":    config.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": config.CreatedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := collection.UpdateOne(ctx, bson.M{"username": config.Username}, update, opts)
+	return err
+}
+
+func (s *MongoStorage) DeleteUserConfig(ctx context.Context, username string) error {
+	collection := s.database.Collection(UserConfigCollection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"username": username})
+	return err
+}
+
+// User metadata operations
+func (s *MongoStorage) GetUserMetadata(ctx context.Context, username string) (*models.UserMetadata, error) {
+	collection := s.database.Collection(UserMetadataCollection)
+
+	var metadata models.UserMetadata
+	err := collection.FindOne(ctx, bson.M{"username": username}).Decode(&metadata)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+func (s *MongoStorage) UpdateUserLastScraped(ctx context.Context, username string, scrapedAt time.Time, stats models.RunStats) error {
+	collection := s.database.Collection(UserMetadataCollection)
+
+	now := s.clock.Now().UTC()
+	update := bson.M{
+		"$set": bson.M{
+			"last_scraped_at": scrapedAt,
+			"last_run":        stats,
+			"updated_at":      now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := collection.UpdateOne(ctx, bson.M{"username": username}, update, opts)
+	return observe(OpMetadataUpdate, err)
+}
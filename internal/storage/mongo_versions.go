@@ -0,0 +1,67 @@
+// internal/storage/mongo_versions.go
+package storage
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collectionVersion counts the documents matching filter and reads the latest
+// timeField among them. timeField should be indexed so the lookup reads one
+// index entry; an empty filter uses the collection's count metadata.
+func (s *MongoStorage) collectionVersion(ctx context.Context, name string, filter bson.M, timeField string) (CollectionVersion, error) {
+	collection := s.database.Collection(name)
+
+	var version CollectionVersion
+	var err error
+	if len(filter) == 0 {
+		version.Count, err = collection.EstimatedDocumentCount(ctx)
+	} else {
+		version.Count, err = collection.CountDocuments(ctx, filter)
+	}
+	if err != nil {
+		return version, err
+	}
+
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: timeField, Value: -1}}).
+		SetProjection(bson.M{"_id": 0, timeField: 1})
+	var latest bson.M
+	err = collection.FindOne(ctx, filter, opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return version, nil
+	}
+	if err != nil {
+		return version, err
+	}
+	if t, ok := latest[timeField].(primitive.DateTime); ok {
+		version.UpdatedAt = t.Time().UTC()
+	}
+	return version, nil
+}
+
+// GetSubredditConfigsVersion versions the live configs, or those carrying
+// label when it is set. Every config write bumps updated_at, and deletes
+// change the count.
+func (s *MongoStorage) GetSubredditConfigsVersion(ctx context.Context, label string) (CollectionVersion, error) {
+	filter := bson.M{"deleted_at": bson.M{"$exists": false}}
+	if label != "" {
+		filter["labels"] = label
+	}
+	return s.collectionVersion(ctx, SubredditConfigCollection, filter, "updated_at")
+}
+
+// GetSubredditMetadataVersion versions all subreddit metadata
+func (s *MongoStorage) GetSubredditMetadataVersion(ctx context.Context) (CollectionVersion, error) {
+	return s.collectionVersion(ctx, SubredditMetadataCollection, bson.M{}, "updated_at")
+}
+
+// GetSubredditStorageStatsVersion versions the storage stats cache; every
+// refresh rewrites computed_at
+func (s *MongoStorage) GetSubredditStorageStatsVersion(ctx context.Context) (CollectionVersion, error) {
+	return s.collectionVersion(ctx, StorageStatsCollection, bson.M{}, "computed_at")
+}
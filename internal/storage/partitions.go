@@ -0,0 +1,344 @@
+// internal/storage/partitions.go
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// PartitionOptions configures monthly partitioning of posts. Off (the zero
+// value) keeps every post in the single subreddit_post collection.
+type PartitionOptions struct {
+	// Monthly writes posts to subreddit_post_YYYYMM by created_at month (UTC)
+	Monthly bool
+	// DefaultMonths is how many of the newest partitions are read by post
+	// queries without a lower created_at bound
+	DefaultMonths int
+}
+
+const (
+	partitionLayout = "200601"
+	// Partitions created by other instances are picked up after this long
+	partitionRefreshInterval = time.Minute
+)
+
+var partitionPattern = regexp.MustCompile(`^` + SubredditPostsCollection + `_\d{6}$`)
+
+// partitionSet caches the partition names and which have had their indexes ensured
+type partitionSet struct {
+	opts PartitionOptions
+
+	mu       sync.Mutex
+	names    []string // Newest month first
+	loadedAt time.Time
+	ensured  map[string]bool
+}
+
+func newPartitionSet(opts PartitionOptions) *partitionSet {
+	return &partitionSet{opts: opts, ensured: make(map[string]bool)}
+}
+
+// partitionName returns the partition holding posts created at t. Posts
+// without a created_at all land in the year-1 partition.
+func partitionName(t time.Time) string {
+	return SubredditPostsCollection + "_" + t.UTC().Format(partitionLayout)
+}
+
+// partitionMonth returns the first instant of a partition's month
+func partitionMonth(name string) (time.Time, error) {
+	return time.Parse(partitionLayout, strings.TrimPrefix(name, SubredditPostsCollection+"_"))
+}
+
+// postIndexModels are the indexes of the posts collection and of every partition
+func postIndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "reddit_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true), // Sparse to handle any nulls
+		},
+		{Keys: bson.D{{Key: "subreddit", Value: 1}}},
+		{Keys: bson.D{{Key: "author", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "updated_at", Value: -1}}},
+		{Keys: bson.D{{Key: "inserted_at", Value: -1}}},
+		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "score", Value: -1}}},       // sort=top
+		{Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "inserted_at", Value: -1}}}, // sort=inserted
+		{Keys: bson.D{{Key: "author", Value: 1}, {Key: "created_at", Value: -1}}},     // Author filters
+		{Keys: bson.D{{Key: "tags", Value: 1}}},                                       // Multikey
+		{ // Domain filters and stats
+			Keys: bson.D{{Key: "subreddit", Value: 1}, {Key: "domain", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{ // Flair change queries; only posts whose flair changed are indexed
+			Keys:    bson.D{{Key: "subreddit", Value: 1}, {Key: "flair_changed_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"flair_changed_at": bson.M{"$exists": true}}),
+		},
+		{ // Removed post queries; only removed posts are indexed
+			Keys:    bson.D{{Key: "subreddit", Value: 1}, {Key: "removed_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"status": models.PostStatusRemoved}),
+		},
+	}
+}
+
+// postsCollectionFor returns the collection a post created at createdAt is
+// written to, creating the partition's indexes on first use
+func (s *MongoStorage) postsCollectionFor(ctx context.Context, createdAt time.Time) (*mongo.Collection, error) {
+	if !s.partitions.opts.Monthly {
+		return s.database.Collection(SubredditPostsCollection), nil
+	}
+
+	name := partitionName(createdAt)
+	s.partitions.mu.Lock()
+	ensured := s.partitions.ensured[name]
+	s.partitions.mu.Unlock()
+
+	if !ensured {
+		if _, err := s.database.Collection(name).Indexes().CreateMany(ctx, postIndexModels()); err != nil {
+			return nil, fmt.Errorf("creating indexes of %s: %w", name, err)
+		}
+		s.partitions.mu.Lock()
+		s.partitions.ensured[name] = true
+		s.partitions.loadedAt = time.Time{} // List the new partition on the next read
+		s.partitions.mu.Unlock()
+	}
+	return s.database.Collection(name), nil
+}
+
+// loadPartitions lists the existing partitions, newest first
+func (s *MongoStorage) loadPartitions(ctx context.Context) ([]string, error) {
+	p := s.partitions
+	p.mu.Lock()
+	if !p.loadedAt.IsZero() && time.Since(p.loadedAt) < partitionRefreshInterval {
+		names := p.names
+		p.mu.Unlock()
+		return names, nil
+	}
+	p.mu.Unlock()
+
+	all, err := s.database.ListCollectionNames(ctx, bson.M{"name": primitive.Regex{Pattern: partitionPattern.String()}})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(all))
+	for _, name := range all {
+		if partitionPattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	p.mu.Lock()
+	p.names, p.loadedAt = names, time.Now()
+	p.mu.Unlock()
+	return names, nil
+}
+
+// postCollectionNames returns the collections that can hold posts created in
+// [from, to); zero bounds are open. With partitioning, an open lower bound
+// reads only the newest DefaultMonths partitions, and subreddit_post is always
+// included so posts not yet migrated stay visible.
+func (s *MongoStorage) postCollectionNames(ctx context.Context, from, to time.Time) ([]string, error) {
+	return s.selectPostCollections(ctx, from, to, s.partitions.opts.DefaultMonths)
+}
+
+// allPostCollectionNames returns every collection holding posts, for lookups
+// that have no time bound such as by reddit_id
+func (s *MongoStorage) allPostCollectionNames(ctx context.Context) ([]string, error) {
+	return s.selectPostCollections(ctx, time.Time{}, time.Time{}, 0)
+}
+
+func (s *MongoStorage) selectPostCollections(ctx context.Context, from, to time.Time, openMonths int) ([]string, error) {
+	if !s.partitions.opts.Monthly {
+		return []string{SubredditPostsCollection}, nil
+	}
+
+	partitions, err := s.loadPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range partitions {
+		month, err := partitionMonth(name)
+		if err != nil {
+			continue
+		}
+		if !to.IsZero() && !month.Before(to) {
+			continue
+		}
+		if !from.IsZero() && !month.AddDate(0, 1, 0).After(from) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if from.IsZero() && openMonths > 0 && len(names) > openMonths {
+		names = names[:openMonths]
+	}
+	return append(names, SubredditPostsCollection), nil
+}
+
+// aggregatePosts runs perCollection on every named collection, unions the
+// results with $unionWith and applies rest to the union. Each perCollection
+// should start with a $match so every partition uses its indexes.
+func (s *MongoStorage) aggregatePosts(ctx context.Context, names []string, perCollection, rest bson.A) (*mongo.Cursor, error) {
+	pipeline := append(bson.A{}, perCollection...)
+	for _, name := range names[1:] {
+		pipeline = append(pipeline, bson.M{"$unionWith": bson.M{"coll": name, "pipeline": perCollection}})
+	}
+	pipeline = append(pipeline, rest...)
+	return s.database.Collection(names[0]).Aggregate(ctx, pipeline)
+}
+
+// PostPartitions lists the existing post partitions, newest first; it is
+// empty with partitioning off
+func (s *MongoStorage) PostPartitions(ctx context.Context) ([]string, error) {
+	if !s.partitions.opts.Monthly {
+		return nil, nil
+	}
+	return s.loadPartitions(ctx)
+}
+
+// DropPostPartitionsBefore drops every partition whose whole month lies before
+// before, which is how retention works with partitioning on. It returns the
+// dropped partitions and does nothing with partitioning off.
+func (s *MongoStorage) DropPostPartitionsBefore(ctx context.Context, before time.Time) ([]string, error) {
+	partitions, err := s.PostPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, name := range partitions {
+		month, err := partitionMonth(name)
+		if err != nil || month.AddDate(0, 1, 0).After(before) {
+			continue
+		}
+		if err := s.database.Collection(name).Drop(ctx); err != nil {
+			return dropped, fmt.Errorf("dropping %s: %w", name, err)
+		}
+		dropped = append(dropped, name)
+	}
+
+	s.partitions.mu.Lock()
+	for _, name := range dropped {
+		delete(s.partitions.ensured, name)
+	}
+	s.partitions.loadedAt = time.Time{}
+	s.partitions.mu.Unlock()
+	return dropped, nil
+}
+
+// MigratePostsToPartitions moves posts from subreddit_post into their
+// partitions, batchSize posts at a time, calling progress after each batch
+// with the running total. Moved posts are deleted from subreddit_post, so an
+// interrupted migration resumes where it stopped. A post already present in
+// its partition (written since partitioning was enabled) is kept as is.
+func (s *MongoStorage) MigratePostsToPartitions(ctx context.Context, batchSize int, progress func(moved int64)) (int64, error) {
+	if !s.partitions.opts.Monthly {
+		return 0, fmt.Errorf("posts can only be migrated with PARTITIONING=monthly")
+	}
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive")
+	}
+
+	source := s.database.Collection(SubredditPostsCollection)
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(batchSize))
+
+	var moved int64
+	for {
+		cursor, err := source.Find(ctx, bson.M{}, opts)
+		if err != nil {
+			return moved, err
+		}
+		var batch []bson.M
+		if err := cursor.All(ctx, &batch); err != nil {
+			return moved, err
+		}
+		if len(batch) == 0 {
+			return moved, nil
+		}
+
+		if err := s.movePostsToPartitions(ctx, batch); err != nil {
+			return moved, err
+		}
+
+		moved += int64(len(batch))
+		if progress != nil {
+			progress(moved)
+		}
+	}
+}
+
+// moveLegacyPosts moves the named posts that are still in subreddit_post to
+// their partitions. It does nothing with partitioning off.
+func (s *MongoStorage) moveLegacyPosts(ctx context.Context, redditIDs []string) error {
+	if !s.partitions.opts.Monthly || len(redditIDs) == 0 {
+		return nil
+	}
+
+	cursor, err := s.database.Collection(SubredditPostsCollection).Find(ctx, bson.M{"reddit_id": bson.M{"$in": redditIDs}})
+	if err != nil {
+		return err
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	return s.movePostsToPartitions(ctx, docs)
+}
+
+// movePostsToPartitions copies subreddit_post documents into the partitions
+// of their created_at month and then deletes them from subreddit_post. A post
+// already present in its partition is kept as is.
+func (s *MongoStorage) movePostsToPartitions(ctx context.Context, docs []bson.M) error {
+	byPartition := make(map[string][]mongo.WriteModel)
+	createdAt := make(map[string]time.Time)
+	ids := make(bson.A, 0, len(docs))
+	for _, doc := range docs {
+		var created time.Time
+		if value, ok := doc["created_at"].(primitive.DateTime); ok {
+			created = value.Time()
+		}
+		name := partitionName(created)
+		createdAt[name] = created
+
+		filter := bson.M{"_id": doc["_id"]}
+		if redditID, ok := doc["reddit_id"].(string); ok && redditID != "" {
+			filter = bson.M{"reddit_id": redditID}
+		}
+		byPartition[name] = append(byPartition[name], mongo.NewUpdateOneModel().
+			SetFilter(filter).
+			SetUpdate(bson.M{"$setOnInsert": doc}).
+			SetUpsert(true))
+		ids = append(ids, doc["_id"])
+	}
+
+	for name, writes := range byPartition {
+		partition, err := s.postsCollectionFor(ctx, createdAt[name])
+		if err != nil {
+			return err
+		}
+		if _, err := partition.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("copying posts to %s: %w", name, err)
+		}
+	}
+	if _, err := s.database.Collection(SubredditPostsCollection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return fmt.Errorf("removing migrated posts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+// internal/storage/post_filter.go
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/query"
+)
+
+// Post sort orders accepted by FindPosts
+const (
+	PostSortNew = "new" // created_at descending (default)
+	PostSortOld = "old" // created_at ascending
+	PostSortTop = "top" // score descending
+	// inserted_at descending: the posts stored most recently
+	PostSortInserted = "inserted"
+)
+
+// postFields are the post fields (bson names) PostFilter.Fields may name
+var postFields = map[string]bool{
+	"reddit_id": true, "title": true, "body": true, "author": true, "score": true,
+	"subreddit": true, "url": true, "permalink": true, "flair": true, "tags": true,
+	"revision_count": true, "is_nsfw": true, "spoiler": true, "nsfw_unknown": true,
+	"extras": true, "flair_history": true, "flair_changed_at": true,
+	"import_batch": true, "created_at": true, "inserted_at": true, "updated_at": true,
+}
+
+// deletedMarkers are what Reddit leaves in place of a deleted author or a
+// deleted/removed body
+var deletedMarkers = bson.A{"[deleted]", "[removed]"}
+
+// TimeRange bounds created_at to [From, To); zero ends are open
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+	// MatchUpdated also matches posts whose updated_at falls in the range
+	MatchUpdated bool
+}
+
+// PostFilter describes a post query for FindPosts. Zero values match everything.
+type PostFilter struct {
+	Subreddit string
+	// Subreddits matches any of the listed subreddits (together with Subreddit);
+	// results are still ordered globally across all of them
+	Subreddits []string
+	Author     string
+	Flair      string
+	// Domain matches posts linking to a registrable domain, or
+	// models.DomainSelf for self posts
+	Domain string
+	// FlairChangedSince matches posts whose flair changed at or after it; zero matches all
+	FlairChangedSince time.Time
+	// Tags requires posts to carry every listed tag
+	Tags []string
+	// MinScore drops posts scoring below it; nil keeps all scores
+	MinScore  *int
+	TimeRange TimeRange
+	// IncludeDeleted keeps posts whose author or body was deleted or removed
+	IncludeDeleted bool
+	// IncludeRemoved keeps posts with status removed, which vanished from
+	// the subreddit's listing
+	IncludeRemoved bool
+	// ExcludeNSFW drops posts flagged NSFW; posts with unknown status are kept
+	ExcludeNSFW bool
+	// ImportBatch matches the posts of one snapshot import; ExcludeImported
+	// drops every imported post instead
+	ImportBatch     string
+	ExcludeImported bool
+	// Extras matches enrichment fields by dot path below extras (e.g. "label.name")
+	Extras map[string]string
+	// TextQuery matches title or body case-insensitively as a literal substring.
+	// It is not indexed, so combine it with Subreddit or Author on large collections.
+	TextQuery string
+	// Sort is one of PostSortNew, PostSortOld, PostSortTop or
+	// PostSortInserted; empty means new
+	Sort string
+	// Fields limits the returned fields (bson names), or with a leading "-"
+	// omits them (e.g. "-body"); the two forms can't be mixed. _id and the
+	// sort field are always returned, as cursors need them. Empty returns
+	// whole posts.
+	Fields []string
+	// Limit caps the page size; zero or less returns up to MaxUnboundedResults
+	// matches in one page
+	Limit int
+	// Cursor resumes after the last post of a previous page with the same Sort
+	Cursor string
+}
+
+// PostPage is one page of FindPosts results
+type PostPage struct {
+	Posts []models.Post
+	// NextCursor is empty when there are no more matches
+	NextCursor string
+	// Truncated is set when a filter without a Limit matched more than
+	// MaxUnboundedResults posts; NextCursor continues after them
+	Truncated bool
+}
+
+// postCursor is the position after the last post of a page: its sort key and _id
+type postCursor struct {
+	Sort  string             `json:"s"`
+	Value int64              `json:"v"` // score, or created_at/inserted_at in unix milliseconds
+	ID    primitive.ObjectID `json:"id"`
+}
+
+// Validate checks the sort and fields, and builds the filter to check the
+// user supplied values, time range and cursor
+func (f PostFilter) Validate() error {
+	switch f.Sort {
+	case "", PostSortNew, PostSortOld, PostSortTop, PostSortInserted:
+	default:
+		// Every sort is indexed together with subreddit; new and old are also
+		// indexed with author, while top and inserted sort an author's posts in memory
+		return fmt.Errorf("sort must be %s, %s, %s or %s (all are indexed per subreddit; with only author, use %s or %s on prolific authors)",
+			PostSortNew, PostSortOld, PostSortTop, PostSortInserted, PostSortNew, PostSortOld)
+	}
+	if err := f.validateFields(); err != nil {
+		return err
+	}
+	_, err := f.bson()
+	return err
+}
+
+// validateFields checks Fields against postFields and the projection rules
+func (f PostFilter) validateFields() error {
+	sortField, _ := f.sortField()
+	excluding := len(f.Fields) > 0 && strings.HasPrefix(f.Fields[0], "-")
+	for _, field := range f.Fields {
+		name, excluded := strings.CutPrefix(field, "-")
+		if excluded != excluding {
+			return errors.New("fields must either all be included or all be omitted with a leading -")
+		}
+		if !postFields[name] {
+			return fmt.Errorf("unknown post field %q", name)
+		}
+		if excluded && name == sortField {
+			return fmt.Errorf("%s is the sort field and can't be omitted", name)
+		}
+	}
+	return nil
+}
+
+func (f PostFilter) sortName() string {
+	if f.Sort == "" {
+		return PostSortNew
+	}
+	return f.Sort
+}
+
+// sortField returns the sorted field and direction; _id breaks ties in the
+// same direction so cursors are stable
+func (f PostFilter) sortField() (string, int) {
+	switch f.sortName() {
+	case PostSortOld:
+		return "created_at", 1
+	case PostSortTop:
+		return "score", -1
+	case PostSortInserted:
+		return "inserted_at", -1
+	default:
+		return "created_at", -1
+	}
+}
+
+func (f PostFilter) sort() bson.D {
+	field, direction := f.sortField()
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// projection maps Fields to a Mongo projection; nil returns whole posts
+func (f PostFilter) projection() bson.M {
+	if len(f.Fields) == 0 {
+		return nil
+	}
+	projection := bson.M{}
+	for _, field := range f.Fields {
+		if name, excluded := strings.CutPrefix(field, "-"); excluded {
+			projection[name] = 0
+		} else {
+			projection[field] = 1
+		}
+	}
+	if !strings.HasPrefix(f.Fields[0], "-") {
+		sortField, _ := f.sortField()
+		projection[sortField] = 1
+	}
+	return projection
+}
+
+// bson builds the Mongo filter, including the cursor position. User
+// supplied values go through the query package, which rejects operator
+// shaped or oversized ones.
+func (f PostFilter) bson() (bson.M, error) {
+	var conditions []query.Condition
+	if subreddits := f.subreddits(); len(subreddits) > 0 {
+		conditions = append(conditions, query.BySubreddits(subreddits...))
+	}
+	if f.Author != "" {
+		conditions = append(conditions, query.ByAuthor(f.Author))
+	}
+	if f.Flair != "" {
+		conditions = append(conditions, query.ByFlair(f.Flair))
+	}
+	if f.Domain != "" {
+		conditions = append(conditions, query.ByDomain(f.Domain))
+	}
+	if !f.FlairChangedSince.IsZero() {
+		conditions = append(conditions, query.AtOrAfter("flair_changed_at", f.FlairChangedSince))
+	}
+	if len(f.Tags) > 0 {
+		conditions = append(conditions, query.WithTags(f.Tags...))
+	}
+	if f.MinScore != nil {
+		conditions = append(conditions, query.ScoreAtLeast(*f.MinScore))
+	}
+	if f.ExcludeNSFW {
+		conditions = append(conditions, query.Trusted(bson.M{"is_nsfw": bson.M{"$ne": true}}))
+	}
+	if f.ImportBatch != "" {
+		conditions = append(conditions, query.ByImportBatch(f.ImportBatch))
+	} else if f.ExcludeImported {
+		conditions = append(conditions, query.Trusted(bson.M{"import_batch": bson.M{"$exists": false}}))
+	}
+	for path, value := range f.Extras {
+		conditions = append(conditions, query.ExtrasEquals(path, value))
+	}
+
+	if f.TimeRange.MatchUpdated {
+		conditions = append(conditions, query.CreatedOrUpdatedBetween(f.TimeRange.From, f.TimeRange.To))
+	} else {
+		conditions = append(conditions, query.CreatedBetween(f.TimeRange.From, f.TimeRange.To))
+	}
+
+	if !f.IncludeDeleted {
+		if f.Author == "" {
+			conditions = append(conditions, query.Trusted(bson.M{"author": bson.M{"$nin": deletedMarkers}}))
+		}
+		conditions = append(conditions, query.Trusted(bson.M{"body": bson.M{"$nin": deletedMarkers}}))
+	}
+
+	if !f.IncludeRemoved {
+		conditions = append(conditions, query.Trusted(bson.M{"status": bson.M{"$ne": models.PostStatusRemoved}}))
+	}
+
+	if f.TextQuery != "" {
+		conditions = append(conditions, query.TextSearch(f.TextQuery))
+	}
+
+	if f.Cursor != "" {
+		cursor, err := f.decodeCursor()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, query.Trusted(f.afterCursor(cursor)))
+	}
+
+	return query.Build(conditions...)
+}
+
+// subreddits merges Subreddit and Subreddits, dropping duplicates
+func (f PostFilter) subreddits() []string {
+	names := f.Subreddits
+	if f.Subreddit != "" {
+		names = append([]string{f.Subreddit}, names...)
+	}
+
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+func (r TimeRange) bounds() bson.M {
+	bounds := bson.M{}
+	if !r.From.IsZero() {
+		bounds["$gte"] = r.From
+	}
+	if !r.To.IsZero() {
+		bounds["$lt"] = r.To
+	}
+	return bounds
+}
+
+// afterCursor matches posts strictly after the cursor in sort order
+func (f PostFilter) afterCursor(cursor postCursor) bson.M {
+	field, direction := f.sortField()
+	op := "$lt"
+	if direction > 0 {
+		op = "$gt"
+	}
+
+	var value interface{} = cursor.Value
+	if field != "score" {
+		value = time.UnixMilli(cursor.Value).UTC()
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{op: value}},
+		bson.M{field: value, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+// nextCursor encodes the position after post
+func (f PostFilter) nextCursor(post models.Post) string {
+	cursor := postCursor{Sort: f.sortName(), ID: post.ID}
+	switch field, _ := f.sortField(); field {
+	case "score":
+		cursor.Value = int64(post.Score)
+	case "inserted_at":
+		cursor.Value = post.InsertedAt.UnixMilli()
+	default:
+		cursor.Value = post.CreatedAt.UnixMilli()
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (f PostFilter) decodeCursor() (postCursor, error) {
+	var cursor postCursor
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(f.Cursor))
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID.IsZero() {
+		return postCursor{}, errors.New("invalid cursor")
+	}
+	if cursor.Sort != f.sortName() {
+		return postCursor{}, fmt.Errorf("cursor was issued for sort %s", cursor.Sort)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"reddit-orchestrator/internal/models"
+)
+
+func TestPostFilterBSON(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	minScore := 10
+
+	tests := []struct {
+		name    string
+		filter  PostFilter
+		want    bson.M
+		wantErr string
+	}{
+		{
+			name:   "author, min score and time range",
+			filter: PostFilter{Author: "gopher", MinScore: &minScore, TimeRange: TimeRange{From: from, To: to}, IncludeRemoved: true},
+			want: bson.M{
+				"author":     "gopher",
+				"score":      bson.M{"$gte": 10},
+				"created_at": bson.M{"$gte": from, "$lt": to},
+				// An author filter already excludes [deleted], so only the body is checked
+				"body": bson.M{"$nin": deletedMarkers},
+			},
+		},
+		{
+			name:   "subreddits merged and deduplicated",
+			filter: PostFilter{Subreddit: "golang", Subreddits: []string{"rust", "golang"}, IncludeDeleted: true, IncludeRemoved: true},
+			want:   bson.M{"subreddit": bson.M{"$in": bson.A{"golang", "rust"}}},
+		},
+		{
+			name:   "defaults drop deleted and removed posts",
+			filter: PostFilter{Subreddit: "golang"},
+			want: bson.M{
+				"subreddit": "golang",
+				"author":    bson.M{"$nin": deletedMarkers},
+				"body":      bson.M{"$nin": deletedMarkers},
+				"status":    bson.M{"$ne": models.PostStatusRemoved},
+			},
+		},
+		{
+			name:   "time range matching updates",
+			filter: PostFilter{Subreddit: "golang", TimeRange: TimeRange{From: from, MatchUpdated: true}, IncludeDeleted: true, IncludeRemoved: true},
+			want: bson.M{
+				"subreddit": "golang",
+				"$or": bson.A{
+					bson.M{"created_at": bson.M{"$gte": from}},
+					bson.M{"updated_at": bson.M{"$gte": from}},
+				},
+			},
+		},
+		{name: "operator shaped author", filter: PostFilter{Author: "$where"}, wantErr: "operator"},
+		{name: "inverted time range", filter: PostFilter{Subreddit: "golang", TimeRange: TimeRange{From: to, To: from}}, wantErr: "before its end"},
+		{name: "unknown sort", filter: PostFilter{Subreddit: "golang", Sort: "hot"}, wantErr: "sort must be"},
+		{name: "mixed fields", filter: PostFilter{Subreddit: "golang", Fields: []string{"title", "-body"}}, wantErr: "all be included"},
+		{name: "omitted sort field", filter: PostFilter{Subreddit: "golang", Sort: PostSortTop, Fields: []string{"-score"}}, wantErr: "sort field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Validate() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			got, err := tt.filter.bson()
+			if err != nil {
+				t.Fatalf("bson() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bson() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostFilterCursor(t *testing.T) {
+	id := primitive.NewObjectID()
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	inserted := created.Add(time.Hour)
+	post := models.Post{ID: id, Score: 42, CreatedAt: created, InsertedAt: inserted}
+
+	tests := []struct {
+		sort      string
+		field     string
+		op        string
+		wantValue interface{}
+	}{
+		{sort: "", field: "created_at", op: "$lt", wantValue: created},
+		{sort: PostSortOld, field: "created_at", op: "$gt", wantValue: created},
+		{sort: PostSortTop, field: "score", op: "$lt", wantValue: int64(42)},
+		{sort: PostSortInserted, field: "inserted_at", op: "$lt", wantValue: inserted},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("sort %q", tt.sort), func(t *testing.T) {
+			page := PostFilter{Subreddit: "golang", Sort: tt.sort}
+			next := PostFilter{Subreddit: "golang", Sort: tt.sort, Cursor: page.nextCursor(post)}
+
+			cursor, err := next.decodeCursor()
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if cursor.ID != id {
+				t.Errorf("cursor ID = %v, want %v", cursor.ID, id)
+			}
+
+			// The next page starts strictly after the post, with _id breaking
+			// ties in the sort's direction
+			want := bson.M{"$or": bson.A{
+				bson.M{tt.field: bson.M{tt.op: tt.wantValue}},
+				bson.M{tt.field: tt.wantValue, "_id": bson.M{tt.op: id}},
+			}}
+			if got := next.afterCursor(cursor); !reflect.DeepEqual(got, want) {
+				t.Errorf("afterCursor() = %v, want %v", got, want)
+			}
+			direction := -1
+			if tt.op == "$gt" {
+				direction = 1
+			}
+			wantSort := bson.D{{Key: tt.field, Value: direction}, {Key: "_id", Value: direction}}
+			if got := next.sort(); !reflect.DeepEqual(got, wantSort) {
+				t.Errorf("sort() = %v, want %v", got, wantSort)
+			}
+		})
+	}
+
+	t.Run("cursor from another sort", func(t *testing.T) {
+		top := PostFilter{Subreddit: "golang", Sort: PostSortTop}
+		filter := PostFilter{Subreddit: "golang", Cursor: top.nextCursor(post)}
+		if err := filter.Validate(); err == nil || !strings.Contains(err.Error(), "sort top") {
+			t.Errorf("Validate() error = %v, want the cursor's sort rejected", err)
+		}
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		filter := PostFilter{Subreddit: "golang", Cursor: "not-a-cursor"}
+		if err := filter.Validate(); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+			t.Errorf("Validate() error = %v, want invalid cursor", err)
+		}
+	})
+}
+
+func TestFindPostsPagesInSortOrder(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Scores repeat so pages have to break ties on _id
+	for i := 0; i < 7; i++ {
+		post := &models.Post{
+			RedditID:  fmt.Sprintf("t3_%d", i),
+			Title:     fmt.Sprintf("post %d", i),
+			Author:    "gopher",
+			Subreddit: "golang",
+			Score:     10 * (i % 3),
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		}
+		if err := s.UpsertPost(ctx, post); err != nil {
+			t.Fatalf("UpsertPost() error = %v", err)
+		}
+	}
+	minScore := 10
+
+	tests := []struct {
+		name   string
+		filter PostFilter
+		want   []string
+	}{
+		{
+			name:   "new",
+			filter: PostFilter{Subreddit: "golang"},
+			want:   []string{"t3_6", "t3_5", "t3_4", "t3_3", "t3_2", "t3_1", "t3_0"},
+		},
+		{
+			name:   "old within a time range",
+			filter: PostFilter{Subreddit: "golang", Sort: PostSortOld, TimeRange: TimeRange{From: base.Add(time.Hour), To: base.Add(5 * time.Hour)}},
+			want:   []string{"t3_1", "t3_2", "t3_3", "t3_4"},
+		},
+		{
+			name:   "top by author with a min score",
+			filter: PostFilter{Author: "gopher", Sort: PostSortTop, MinScore: &minScore},
+			want:   []string{"t3_5", "t3_2", "t3_4", "t3_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.Limit = 2
+
+			var got []models.Post
+			for pages := 0; ; pages++ {
+				if pages > len(tt.want) {
+					t.Fatalf("cursor did not end after %d pages", pages)
+				}
+				page, err := s.FindPosts(ctx, filter)
+				if err != nil {
+					t.Fatalf("FindPosts() error = %v", err)
+				}
+				got = append(got, page.Posts...)
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+
+			ids := make([]string, len(got))
+			for i, post := range got {
+				ids[i] = post.RedditID
+			}
+			if tt.filter.Sort == PostSortTop {
+				// Equal scores come back in _id order, which follows insertion
+				// here; compare scores, then the set of posts
+				for i := 1; i < len(got); i++ {
+					if got[i].Score > got[i-1].Score {
+						t.Fatalf("scores %v are not descending", ids)
+					}
+				}
+				if !sameElements(ids, tt.want) {
+					t.Errorf("posts = %v, want %v in any tie order", ids, tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("posts = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[string]int{}
+	for _, value := range a {
+		counts[value]++
+	}
+	for _, value := range b {
+		counts[value]--
+	}
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFindPostsOrdersAcrossSubreddits(t *testing.T) {
+	s := newTestStorage(t, PartitionOptions{})
+	ctx := context.Background()
+
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	subreddits := []string{"golang", "rust", "python"}
+	// Timestamps interleave: post i lands in subreddit i%3, plus one post in
+	// a subreddit that is not queried
+	for i := 0; i < 9; i++ {
+		post := &models.Post{
+			RedditID:  fmt.Sprintf("t3_%d", i),
+			Title:     fmt.Sprintf("post %d", i),
+			Subreddit: subreddits[i%3],
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := s.UpsertPost(ctx, post); err != nil {
+			t.Fatalf("UpsertPost() error = %v", err)
+		}
+	}
+	other := &models.Post{RedditID: "t3_other", Title: "other", Subreddit: "java", CreatedAt: base.Add(4*time.Minute + 30*time.Second)}
+	if err := s.UpsertPost(ctx, other); err != nil {
+		t.Fatalf("UpsertPost() error = %v", err)
+	}
+
+	filter := PostFilter{Subreddits: subreddits, Limit: 2}
+	var ids []string
+	for pages := 0; ; pages++ {
+		if pages > 9 {
+			t.Fatal("cursor did not end")
+		}
+		page, err := s.FindPosts(ctx, filter)
+		if err != nil {
+			t.Fatalf("FindPosts() error = %v", err)
+		}
+		for _, post := range page.Posts {
+			ids = append(ids, post.RedditID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	want := []string{"t3_8", "t3_7", "t3_6", "t3_5", "t3_4", "t3_3", "t3_2", "t3_1", "t3_0"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("posts = %v, want %v", ids, want)
+	}
+}
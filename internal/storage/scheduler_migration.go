@@ -0,0 +1,147 @@
+// internal/storage/scheduler_migration.go
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collections created by BlueBerry's MongoDB store
+var schedulerCollections = []string{"task_runs", "task_run_logs"}
+
+const (
+	schedulerCountersCollection = "counters"
+	migrationBatchSize          = 1000
+)
+
+// MigrateSchedulerCollections copies BlueBerry's run history from the shared
+// data database into the dedicated scheduler database. Documents that already
+// exist in the target are left untouched, so the copy can be re-run safely.
+// Returns the number of documents copied per collection.
+func MigrateSchedulerCollections(ctx context.Context, mongoURI, fromDB, toDB string) (map[string]int64, error) {
+	if fromDB == toDB {
+		return nil, fmt.Errorf("source and target database are both %q", fromDB)
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	source := client.Database(fromDB)
+	target := client.Database(toDB)
+
+	copied := make(map[string]int64)
+	for _, name := range schedulerCollections {
+		count, err := copyCollection(ctx, source.Collection(name), target.Collection(name))
+		if err != nil {
+			return copied, fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+		copied[name] = count
+	}
+
+	// Counters hold the next run/log IDs; keep the higher value so IDs never collide
+	count, err := mergeCounters(ctx, source.Collection(schedulerCountersCollection), target.Collection(schedulerCountersCollection))
+	if err != nil {
+		return copied, fmt.Errorf("failed to merge counters: %w", err)
+	}
+	copied[schedulerCountersCollection] = count
+
+	return copied, nil
+}
+
+func copyCollection(ctx context.Context, source, target *mongo.Collection) (int64, error) {
+	cursor, err := source.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var copied int64
+	batch := make([]interface{}, 0, migrationBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		inserted := len(batch)
+		_, err := target.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+		if err != nil {
+			bulkErr, ok := err.(mongo.BulkWriteException)
+			if !ok || !isOnlyDuplicateKeyErrors(bulkErr) {
+				return err
+			}
+			inserted -= len(bulkErr.WriteErrors)
+		}
+		copied += int64(inserted)
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		// cursor.Current is reused between iterations
+		doc := make(bson.Raw, len(cursor.Current))
+		copy(doc, cursor.Current)
+		batch = append(batch, doc)
+		if len(batch) == migrationBatchSize {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return copied, err
+	}
+	if err := flush(); err != nil {
+		return copied, err
+	}
+
+	return copied, nil
+}
+
+func mergeCounters(ctx context.Context, source, target *mongo.Collection) (int64, error) {
+	cursor, err := source.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var merged int64
+	for cursor.Next(ctx) {
+		var counter struct {
+			ID       string `bson:"_id"`
+			SeqValue int64  `bson:"seq_value"`
+		}
+		if err := cursor.Decode(&counter); err != nil {
+			return merged, err
+		}
+
+		update := bson.M{"$max": bson.M{"seq_value": counter.SeqValue}}
+		opts := options.Update().SetUpsert(true)
+		if _, err := target.UpdateOne(ctx, bson.M{"_id": counter.ID}, update, opts); err != nil {
+			return merged, err
+		}
+		merged++
+	}
+
+	return merged, cursor.Err()
+}
+
+// isOnlyDuplicateKeyErrors reports whether every write error is a duplicate key,
+// i.e. the document was already copied by a previous run
+func isOnlyDuplicateKeyErrors(bulkErr mongo.BulkWriteException) bool {
+	if bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
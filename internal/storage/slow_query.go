@@ -0,0 +1,126 @@
+// internal/storage/slow_query.go
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+
+	"reddit-orchestrator/internal/logsample"
+)
+
+// slowQueryCommands are the read commands whose timing is checked
+var slowQueryCommands = map[string]string{
+	"find":      "filter",
+	"aggregate": "pipeline",
+	"count":     "query",
+}
+
+// slowQueryMonitor logs find/aggregate/count commands slower than threshold,
+// with filter values redacted so only the query shape is printed. A query
+// slow on every run logs a few lines a minute per command and collection.
+type slowQueryMonitor struct {
+	threshold time.Duration
+	pending   sync.Map // request ID -> startedQuery
+	limiter   *logsample.Limiter
+}
+
+type startedQuery struct {
+	command    string
+	collection string
+	shape      interface{}
+}
+
+func newSlowQueryMonitor(threshold time.Duration) *event.CommandMonitor {
+	monitor := &slowQueryMonitor{threshold: threshold, limiter: logsample.NewLimiter(3, time.Minute)}
+	return &event.CommandMonitor{
+		Started: monitor.started,
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			monitor.finished(evt.CommandFinishedEvent, "")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			monitor.finished(evt.CommandFinishedEvent, evt.Failure)
+		},
+	}
+}
+
+func (m *slowQueryMonitor) started(_ context.Context, evt *event.CommandStartedEvent) {
+	shapeKey, ok := slowQueryCommands[evt.CommandName]
+	if !ok {
+		return
+	}
+
+	query := startedQuery{command: evt.CommandName}
+	if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+		query.collection = collection
+	}
+
+	var raw bson.M
+	if err := bson.Unmarshal(evt.Command, &raw); err == nil {
+		query.shape = redactQueryShape(raw[shapeKey])
+	}
+
+	m.pending.Store(evt.RequestID, query)
+}
+
+func (m *slowQueryMonitor) finished(evt event.CommandFinishedEvent, failure string) {
+	value, ok := m.pending.LoadAndDelete(evt.RequestID)
+	if !ok || evt.Duration < m.threshold {
+		return
+	}
+	query := value.(startedQuery)
+
+	shape, err := bson.MarshalExtJSON(bson.M{"shape": query.shape}, false, false)
+	if err != nil {
+		shape = []byte("<unavailable>")
+	}
+
+	key := query.command + " " + query.collection
+	if failure != "" {
+		m.limiter.Printf(key, "Slow query: %s on %s took %v and failed (%s), %s",
+			query.command, query.collection, evt.Duration.Round(time.Millisecond), failure, shape)
+		return
+	}
+	m.limiter.Printf(key, "Slow query: %s on %s took %v, %s",
+		query.command, query.collection, evt.Duration.Round(time.Millisecond), shape)
+}
+
+// redactQueryShape keeps field names and operators but replaces every value with "?"
+func redactQueryShape(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		redacted := bson.M{}
+		for key, nested := range v {
+			redacted[key] = redactQueryShape(nested)
+		}
+		return redacted
+	case bson.D:
+		redacted := bson.D{}
+		for _, elem := range v {
+			redacted = append(redacted, bson.E{Key: elem.Key, Value: redactQueryShape(elem.Value)})
+		}
+		return redacted
+	case bson.A:
+		// Scalar lists such as $in collapse to a single "?"
+		redacted := bson.A{}
+		scalarOnly := true
+		for _, nested := range v {
+			shape := redactQueryShape(nested)
+			if shape != "?" {
+				scalarOnly = false
+			}
+			redacted = append(redacted, shape)
+		}
+		if scalarOnly {
+			return "?"
+		}
+		return redacted
+	case nil:
+		return nil
+	default:
+		return "?"
+	}
+}
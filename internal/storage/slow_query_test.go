@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestRedactQueryShape(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "nil", value: nil, want: nil},
+		{name: "scalar", value: "golang", want: "?"},
+		{name: "field equality", value: bson.M{"subreddit": "golang"}, want: bson.M{"subreddit": "?"}},
+		{
+			name:  "operators keep their names",
+			value: bson.M{"created_at": bson.M{"$gte": time.Now(), "$lt": time.Now()}},
+			want:  bson.M{"created_at": bson.M{"$gte": "?", "$lt": "?"}},
+		},
+		{name: "scalar list collapses", value: bson.M{"subreddit": bson.M{"$in": bson.A{"golang", "rust"}}}, want: bson.M{"subreddit": bson.M{"$in": "?"}}},
+		{
+			name:  "document list is kept",
+			value: bson.M{"$or": bson.A{bson.M{"author": "a"}, bson.M{"score": 3}}},
+			want:  bson.M{"$or": bson.A{bson.M{"author": "?"}, bson.M{"score": "?"}}},
+		},
+		{
+			name:  "ordered pipeline",
+			value: bson.D{{Key: "$match", Value: bson.D{{Key: "subreddit", Value: "golang"}}}},
+			want:  bson.D{{Key: "$match", Value: bson.D{{Key: "subreddit", Value: "?"}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactQueryShape(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactQueryShape() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowQueryMonitor(t *testing.T) {
+	command, err := bson.Marshal(bson.D{
+		{Key: "find", Value: "subreddit_post"},
+		{Key: "filter", Value: bson.D{{Key: "author", Value: "secret-author"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		command  string
+		duration time.Duration
+		failure  string
+		wantLog  []string
+	}{
+		{name: "fast find", command: "find", duration: 10 * time.Millisecond},
+		{name: "slow find", command: "find", duration: 2 * time.Second, wantLog: []string{"Slow query: find on subreddit_post", `"author":"?"`}},
+		{name: "slow failed find", command: "find", duration: 2 * time.Second, failure: "timeout", wantLog: []string{"failed (timeout)"}},
+		{name: "slow write is not checked", command: "insert", duration: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			previous := log.Writer()
+			log.SetOutput(&out)
+			defer log.SetOutput(previous)
+
+			monitor := newSlowQueryMonitor(time.Second)
+			monitor.Started(context.Background(), &event.CommandStartedEvent{
+				Command:     command,
+				CommandName: tt.command,
+				RequestID:   1,
+			})
+			finished := event.CommandFinishedEvent{CommandName: tt.command, RequestID: 1, Duration: tt.duration}
+			if tt.failure != "" {
+				monitor.Failed(context.Background(), &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: tt.failure})
+			} else {
+				monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+			}
+
+			logged := out.String()
+			if len(tt.wantLog) == 0 && logged != "" {
+				t.Errorf("logged %q, want nothing", logged)
+			}
+			for _, want := range tt.wantLog {
+				if !strings.Contains(logged, want) {
+					t.Errorf("log %q does not contain %q", logged, want)
+				}
+			}
+			if strings.Contains(logged, "secret-author") {
+				t.Errorf("log %q leaks a filter value", logged)
+			}
+		})
+	}
+}
@@ -0,0 +1,898 @@
+// internal/storage/storagetest/memory.go
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// Memory is an in-memory storage.StorageInterface for tests. It keeps
+// configs, metadata and posts in maps and implements the methods scrape runs
+// and the API listings use; any other method panics through the nil embedded
+// interface, so a test reaching unexpected storage fails loudly.
+//
+// Fail makes the next calls of a method return an error, for testing retries
+// and partial failures.
+type Memory struct {
+	storage.StorageInterface
+
+	mu    sync.Mutex
+	clock clock.Clock
+
+	configs     map[string]models.SubredditConfig
+	metadata    map[string]models.SubredditMetadata
+	posts       map[string]models.Post
+	userConfigs map[string]models.UserConfig
+	userMeta    map[string]models.UserMetadata
+	pause       *models.MaintenancePause
+	qaSamples   []models.QASample
+	rollups     int
+	rules       map[string]models.NotificationRule
+	ruleLog     []models.NotificationLog
+	deliveries  []models.WebhookDelivery
+	// deleted holds soft-deleted configs, which a live config may share a
+	// name with; only RestoreBackup writes them
+	deleted   []models.SubredditConfig
+	templates map[string]models.ConfigTemplate
+	digests   map[string]models.Digest
+
+	failures map[string][]error
+	calls    map[string]int
+}
+
+// NewMemory returns an empty Memory stamping writes with clk
+func NewMemory(clk clock.Clock) *Memory {
+	return &Memory{
+		clock:       clk,
+		configs:     make(map[string]models.SubredditConfig),
+		metadata:    make(map[string]models.SubredditMetadata),
+		posts:       make(map[string]models.Post),
+		userConfigs: make(map[string]models.UserConfig),
+		userMeta:    make(map[string]models.UserMetadata),
+		rules:       make(map[string]models.NotificationRule),
+		templates:   make(map[string]models.ConfigTemplate),
+		digests:     make(map[string]models.Digest),
+		failures:    make(map[string][]error),
+		calls:       make(map[string]int),
+	}
+}
+
+// Fail makes the next times calls of method return err instead of running
+func (m *Memory) Fail(method string, times int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for range times {
+		m.failures[method] = append(m.failures[method], err)
+	}
+}
+
+// Calls returns how often method was called, failed calls included
+func (m *Memory) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// enter records a call of method and returns its injected failure, if any.
+// The caller must hold m.mu.
+func (m *Memory) enter(method string) error {
+	m.calls[method]++
+	if queued := m.failures[method]; len(queued) > 0 {
+		m.failures[method] = queued[1:]
+		return queued[0]
+	}
+	return nil
+}
+
+// Posts returns the stored posts of subreddit ordered by created_at
+func (m *Memory) Posts(subreddit string) []models.Post {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var posts []models.Post
+	for _, post := range m.posts {
+		if post.Subreddit == subreddit {
+			posts = append(posts, post)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].CreatedAt.Before(posts[j].CreatedAt) })
+	return posts
+}
+
+// RollupPosts returns how many posts were passed to IncrementRollups
+func (m *Memory) RollupPosts() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rollups
+}
+
+// SetMetadata stores metadata as is, for seeding a test
+func (m *Memory) SetMetadata(metadata models.SubredditMetadata) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metadata[metadata.SubredditName] = metadata
+}
+
+// --- MetadataStore ---
+
+func (m *Memory) GetSubredditMetadata(ctx context.Context, subredditName string) (*models.SubredditMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetSubredditMetadata"); err != nil {
+		return nil, err
+	}
+	metadata, ok := m.metadata[subredditName]
+	if !ok {
+		return nil, nil
+	}
+	return &metadata, nil
+}
+
+func (m *Memory) GetSubredditMetadataByNames(ctx context.Context, names []string) (map[string]models.SubredditMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetSubredditMetadataByNames"); err != nil {
+		return nil, err
+	}
+	metadatas := make(map[string]models.SubredditMetadata, len(names))
+	for _, name := range names {
+		if metadata, ok := m.metadata[name]; ok {
+			metadatas[name] = metadata
+		}
+	}
+	return metadatas, nil
+}
+
+func (m *Memory) GetLatestSubredditRuns(ctx context.Context, names []string) (map[string]models.TaskRunSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetLatestSubredditRuns"); err != nil {
+		return nil, err
+	}
+	return map[string]models.TaskRunSummary{}, nil
+}
+
+// updateMetadata applies update to the subreddit's metadata, creating it
+// when create is set. The caller must hold m.mu.
+func (m *Memory) updateMetadata(subredditName string, create bool, update func(*models.SubredditMetadata)) bool {
+	now := m.clock.Now().UTC()
+	metadata, ok := m.metadata[subredditName]
+	if !ok {
+		if !create {
+			return false
+		}
+		metadata = models.SubredditMetadata{SubredditName: subredditName, CreatedAt: now}
+	}
+	update(&metadata)
+	metadata.UpdatedAt = now
+	m.metadata[subredditName] = metadata
+	return true
+}
+
+func (m *Memory) UpsertSubredditMetadata(ctx context.Context, metadata *models.SubredditMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpsertSubredditMetadata"); err != nil {
+		return err
+	}
+	m.updateMetadata(metadata.SubredditName, true, func(stored *models.SubredditMetadata) {
+		if !metadata.LastScrapedAt.IsZero() {
+			stored.LastScrapedAt = metadata.LastScrapedAt
+		}
+		if metadata.MonitorConfig != nil {
+			monitorConfig := *metadata.MonitorConfig
+			stored.MonitorConfig = &monitorConfig
+		}
+		if metadata.LastRun != nil {
+			lastRun := *metadata.LastRun
+			stored.LastRun = &lastRun
+		}
+	})
+	return nil
+}
+
+func (m *Memory) UpdateLastScraped(ctx context.Context, subredditName string, scrapedAt time.Time, stats models.RunStats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpdateLastScraped"); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.updateMetadata(subredditName, true, func(stored *models.SubredditMetadata) {
+		stored.LastScrapedAt = scrapedAt
+		stored.LastRun = &stats
+	})
+	return nil
+}
+
+func (m *Memory) UpdateZeroPostRuns(ctx context.Context, subredditName string, postsFetched int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpdateZeroPostRuns"); err != nil {
+		return 0, err
+	}
+	runs := 0
+	m.updateMetadata(subredditName, false, func(stored *models.SubredditMetadata) {
+		if postsFetched > 0 {
+			stored.ZeroPostRuns = 0
+			stored.Stale = false
+		} else {
+			stored.ZeroPostRuns++
+		}
+		runs = stored.ZeroPostRuns
+	})
+	return runs, nil
+}
+
+func (m *Memory) SetBestRecentPost(ctx context.Context, subredditName string, best models.BestRecentPost) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("SetBestRecentPost"); err != nil {
+		return err
+	}
+	m.updateMetadata(subredditName, false, func(stored *models.SubredditMetadata) {
+		stored.BestRecentPost = &best
+	})
+	return nil
+}
+
+func (m *Memory) SetSubredditStale(ctx context.Context, subredditName string, stale bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("SetSubredditStale"); err != nil {
+		return err
+	}
+	m.updateMetadata(subredditName, false, func(stored *models.SubredditMetadata) {
+		stored.Stale = stale
+	})
+	return nil
+}
+
+func (m *Memory) SetSubredditAbout(ctx context.Context, subredditName string, about models.AboutInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("SetSubredditAbout"); err != nil {
+		return err
+	}
+	m.updateMetadata(subredditName, true, func(stored *models.SubredditMetadata) {
+		stored.About = &about
+	})
+	return nil
+}
+
+func (m *Memory) RecordRunOutcome(ctx context.Context, subredditName string, failed bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("RecordRunOutcome"); err != nil {
+		return err
+	}
+	now := m.clock.Now().UTC()
+	m.updateMetadata(subredditName, true, func(stored *models.SubredditMetadata) {
+		stored.RecentRuns = append(stored.RecentRuns, models.RunOutcome{At: now, Failed: failed})
+		if len(stored.RecentRuns) > models.MaxRecentRuns {
+			stored.RecentRuns = stored.RecentRuns[len(stored.RecentRuns)-models.MaxRecentRuns:]
+		}
+	})
+	return nil
+}
+
+func (m *Memory) RecordCursorProbe(ctx context.Context, subredditName string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("RecordCursorProbe"); err != nil {
+		return err
+	}
+	m.updateMetadata(subredditName, false, func(stored *models.SubredditMetadata) {
+		stored.CursorProbedAt = &at
+	})
+	return nil
+}
+
+func (m *Memory) GetSubredditMetadataVersion(ctx context.Context) (storage.CollectionVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetSubredditMetadataVersion"); err != nil {
+		return storage.CollectionVersion{}, err
+	}
+	version := storage.CollectionVersion{Count: int64(len(m.metadata))}
+	for _, metadata := range m.metadata {
+		if metadata.UpdatedAt.After(version.UpdatedAt) {
+			version.UpdatedAt = metadata.UpdatedAt
+		}
+	}
+	return version, nil
+}
+
+func (m *Memory) GetUserMetadata(ctx context.Context, username string) (*models.UserMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetUserMetadata"); err != nil {
+		return nil, err
+	}
+	metadata, ok := m.userMeta[username]
+	if !ok {
+		return nil, nil
+	}
+	return &metadata, nil
+}
+
+func (m *Memory) UpdateUserLastScraped(ctx context.Context, username string, scrapedAt time.Time, stats models.RunStats) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpdateUserLastScraped"); err != nil {
+		return err
+	}
+	metadata := m.userMeta[username]
+	metadata.Username = username
+	metadata.LastScrapedAt = scrapedAt
+	m.userMeta[username] = metadata
+	return nil
+}
+
+// --- PostStore ---
+
+func (m *Memory) UpsertPosts(ctx context.Context, posts []models.Post, opts storage.UpsertOptions) (storage.UpsertResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result storage.UpsertResult
+	if err := m.enter("UpsertPosts"); err != nil {
+		return result, err
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	now := m.clock.Now().UTC()
+	for _, post := range posts {
+		post.UpdatedAt = now
+		stored, exists := m.posts[post.RedditID]
+		if exists {
+			post.InsertedAt = stored.InsertedAt
+			post.ImportBatch = stored.ImportBatch
+			post.Tags = models.KeepAnomalyTags(post.Tags, stored.Tags)
+		} else {
+			if post.InsertedAt.IsZero() {
+				post.InsertedAt = now
+			}
+			post.ImportBatch = opts.ImportBatch
+			result.InsertedIDs = append(result.InsertedIDs, post.RedditID)
+		}
+		m.posts[post.RedditID] = post
+	}
+	return result, nil
+}
+
+
+func (m *Memory) GetPostByRedditID(ctx context.Context, redditID string) (*models.Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetPostByRedditID"); err != nil {
+		return nil, err
+	}
+	post, ok := m.posts[redditID]
+	if !ok {
+		return nil, nil
+	}
+	return &post, nil
+}
+
+func (m *Memory) GetExistingRedditIDs(ctx context.Context, redditIDs []string) (map[string]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetExistingRedditIDs"); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, id := range redditIDs {
+		if _, ok := m.posts[id]; ok {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+func (m *Memory) GetPostsCount(ctx context.Context, subreddit string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetPostsCount"); err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, post := range m.posts {
+		if post.Subreddit == subreddit {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// --- ConfigStore ---
+
+func (m *Memory) GetSubredditConfig(ctx context.Context, subredditName string) (*models.SubredditConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetSubredditConfig"); err != nil {
+		return nil, err
+	}
+	config, ok := m.configs[subredditName]
+	if !ok || config.DeletedAt != nil {
+		return nil, nil
+	}
+	return &config, nil
+}
+
+func (m *Memory) UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig, actor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpsertSubredditConfig"); err != nil {
+		return err
+	}
+	now := m.clock.Now().UTC()
+	config.UpdatedAt = now
+	if config.CreatedAt.IsZero() {
+		config.CreatedAt = now
+	}
+	m.configs[config.SubredditName] = *config
+	return nil
+}
+
+// liveConfigs returns the configs that are not soft-deleted, highest
+// priority first. The caller must hold m.mu.
+func (m *Memory) liveConfigs(match func(models.SubredditConfig) bool) []models.SubredditConfig {
+	var configs []models.SubredditConfig
+	for _, config := range m.configs {
+		if config.DeletedAt == nil && match(config) {
+			configs = append(configs, config)
+		}
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		if configs[i].Priority != configs[j].Priority {
+			return configs[i].Priority > configs[j].Priority
+		}
+		return configs[i].SubredditName < configs[j].SubredditName
+	})
+	return configs
+}
+
+func (m *Memory) GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetActiveSubredditConfigs"); err != nil {
+		return nil, err
+	}
+	return m.liveConfigs(func(config models.SubredditConfig) bool { return config.Enabled }), nil
+}
+
+func (m *Memory) GetAllSubredditConfigs(ctx context.Context, filter storage.ConfigFilter, opts storage.ListOptions) (storage.ConfigPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetAllSubredditConfigs"); err != nil {
+		return storage.ConfigPage{}, err
+	}
+	configs := m.liveConfigs(func(config models.SubredditConfig) bool {
+		if filter.Enabled != nil && config.Enabled != *filter.Enabled {
+			return false
+		}
+		if filter.Query != "" && !strings.HasPrefix(strings.ToLower(config.SubredditName), strings.ToLower(filter.Query)) {
+			return false
+		}
+		return filter.Label == "" || slices.Contains(config.Labels, filter.Label)
+	})
+
+	page := storage.ConfigPage{Total: int64(len(configs))}
+	start := min(int(opts.Skip), len(configs))
+	end := len(configs)
+	if opts.Limit > 0 {
+		end = min(start+int(opts.Limit), end)
+	}
+	page.Configs = configs[start:end]
+	return page, nil
+}
+
+func (m *Memory) GetSubredditConfigsVersion(ctx context.Context, label string) (storage.CollectionVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetSubredditConfigsVersion"); err != nil {
+		return storage.CollectionVersion{}, err
+	}
+	var version storage.CollectionVersion
+	for _, config := range m.liveConfigs(func(config models.SubredditConfig) bool { return label == "" || slices.Contains(config.Labels, label) }) {
+		version.Count++
+		if config.UpdatedAt.After(version.UpdatedAt) {
+			version.UpdatedAt = config.UpdatedAt
+		}
+	}
+	return version, nil
+}
+
+// GetAllDigests returns the digests RestoreBackup wrote, by label
+func (m *Memory) GetAllDigests(ctx context.Context) ([]models.Digest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetAllDigests"); err != nil {
+		return nil, err
+	}
+	return sortedValues(m.digests), nil
+}
+
+func (m *Memory) GetActiveUserConfigs(ctx context.Context) ([]models.UserConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetActiveUserConfigs"); err != nil {
+		return nil, err
+	}
+	var configs []models.UserConfig
+	for _, config := range m.userConfigs {
+		if config.Enabled {
+			configs = append(configs, config)
+		}
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Username < configs[j].Username })
+	return configs, nil
+}
+
+func (m *Memory) GetUserConfig(ctx context.Context, username string) (*models.UserConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetUserConfig"); err != nil {
+		return nil, err
+	}
+	config, ok := m.userConfigs[username]
+	if !ok {
+		return nil, nil
+	}
+	return &config, nil
+}
+
+func (m *Memory) UpsertUserConfig(ctx context.Context, config *models.UserConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpsertUserConfig"); err != nil {
+		return err
+	}
+	m.userConfigs[config.Username] = *config
+	return nil
+}
+
+// --- NotificationStore, RollupStore, PauseStore, CaptureStore ---
+
+func (m *Memory) UpsertNotificationRule(ctx context.Context, rule *models.NotificationRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("UpsertNotificationRule"); err != nil {
+		return err
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	stored := *rule
+	stored.LastFiredAt = m.rules[rule.Name].LastFiredAt
+	m.rules[rule.Name] = stored
+	return nil
+}
+
+func (m *Memory) GetNotificationRule(ctx context.Context, name string) (*models.NotificationRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetNotificationRule"); err != nil {
+		return nil, err
+	}
+	rule, ok := m.rules[name]
+	if !ok {
+		return nil, nil
+	}
+	return &rule, nil
+}
+
+func (m *Memory) GetActiveNotificationRules(ctx context.Context) ([]models.NotificationRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetActiveNotificationRules"); err != nil {
+		return nil, err
+	}
+	var rules []models.NotificationRule
+	for _, rule := range m.rules {
+		if rule.Enabled {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules, nil
+}
+
+func (m *Memory) ClaimNotificationRule(ctx context.Context, name string, now time.Time, cooldown time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("ClaimNotificationRule"); err != nil {
+		return false, err
+	}
+	rule, ok := m.rules[name]
+	if !ok || (!rule.LastFiredAt.IsZero() && rule.LastFiredAt.After(now.Add(-cooldown))) {
+		return false, nil
+	}
+	rule.LastFiredAt = now
+	m.rules[name] = rule
+	return true, nil
+}
+
+func (m *Memory) ReleaseNotificationRule(ctx context.Context, name string, claimedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("ReleaseNotificationRule"); err != nil {
+		return err
+	}
+	if rule, ok := m.rules[name]; ok && rule.LastFiredAt.Equal(claimedAt) {
+		rule.LastFiredAt = time.Time{}
+		m.rules[name] = rule
+	}
+	return nil
+}
+
+func (m *Memory) InsertNotificationLogs(ctx context.Context, entries []models.NotificationLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("InsertNotificationLogs"); err != nil {
+		return err
+	}
+	m.ruleLog = append(m.ruleLog, entries...)
+	return nil
+}
+
+// NotificationLog returns every recorded notification log entry, oldest first
+func (m *Memory) NotificationLog() []models.NotificationLog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.NotificationLog(nil), m.ruleLog...)
+}
+
+func (m *Memory) InsertWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("InsertWebhookDelivery"); err != nil {
+		return err
+	}
+	m.deliveries = append(m.deliveries, *delivery)
+	return nil
+}
+
+func (m *Memory) GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetWebhookDelivery"); err != nil {
+		return nil, err
+	}
+	for _, delivery := range m.deliveries {
+		if delivery.ID.Hex() == id {
+			return &delivery, nil
+		}
+	}
+	return nil, nil
+}
+
+// WebhookDeliveries returns every recorded webhook delivery, oldest first
+func (m *Memory) WebhookDeliveries() []models.WebhookDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.WebhookDelivery(nil), m.deliveries...)
+}
+
+func (m *Memory) IncrementRollups(ctx context.Context, posts []models.Post) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("IncrementRollups"); err != nil {
+		return err
+	}
+	m.rollups += len(posts)
+	return nil
+}
+
+func (m *Memory) GetMaintenancePause(ctx context.Context) (*models.MaintenancePause, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("GetMaintenancePause"); err != nil {
+		return nil, err
+	}
+	if m.pause == nil {
+		return nil, nil
+	}
+	pause := *m.pause
+	return &pause, nil
+}
+
+func (m *Memory) SetMaintenancePause(ctx context.Context, pause *models.MaintenancePause) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("SetMaintenancePause"); err != nil {
+		return err
+	}
+	stored := *pause
+	m.pause = &stored
+	return nil
+}
+
+func (m *Memory) InsertQASamples(ctx context.Context, samples []models.QASample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("InsertQASamples"); err != nil {
+		return err
+	}
+	m.qaSamples = append(m.qaSamples, samples...)
+	return nil
+}
+
+// QASamples returns every stored QA sample, oldest first
+func (m *Memory) QASamples() []models.QASample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.QASample(nil), m.qaSamples...)
+}
+
+// WithTransaction runs fn. Memory has no transactions, so the writes fn made
+// before failing are kept.
+func (m *Memory) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.mu.Lock()
+	err := m.enter("WithTransaction")
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// sortedValues returns the values of a map keyed by name, sorted by name
+func sortedValues[T any](byName map[string]T) []T {
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]T, 0, len(names))
+	for _, name := range names {
+		values = append(values, byName[name])
+	}
+	return values
+}
+
+// ExportBackup returns the collections a backup covers in the order of
+// MongoStorage: by name, then id
+func (m *Memory) ExportBackup(ctx context.Context) (*models.Backup, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enter("ExportBackup"); err != nil {
+		return nil, err
+	}
+	configs := append(sortedValues(m.configs), m.deleted...)
+	sort.SliceStable(configs, func(i, j int) bool {
+		if configs[i].SubredditName != configs[j].SubredditName {
+			return configs[i].SubredditName < configs[j].SubredditName
+		}
+		return configs[i].ID.Hex() < configs[j].ID.Hex()
+	})
+	return &models.Backup{
+		Version:           storage.BackupVersion,
+		CreatedAt:         m.clock.Now().UTC(),
+		SubredditConfigs:  configs,
+		SubredditMetadata: sortedValues(m.metadata),
+		NotificationRules: sortedValues(m.rules),
+		ConfigTemplates:   sortedValues(m.templates),
+		Digests:           sortedValues(m.digests),
+	}, nil
+}
+
+// RestoreBackup validates and writes a backup as MongoStorage does. Merge
+// keeps the id of a document it replaces; all writes happen or none.
+func (m *Memory) RestoreBackup(ctx context.Context, backup *models.Backup, opts storage.RestoreOptions) (models.RestoreReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if opts.Mode == "" {
+		opts.Mode = storage.RestoreMerge
+	}
+	report := models.RestoreReport{Mode: opts.Mode, DryRun: opts.DryRun, Collections: map[string]models.RestoreCounts{}}
+	if err := m.enter("RestoreBackup"); err != nil {
+		return report, err
+	}
+	if opts.Mode != storage.RestoreMerge && opts.Mode != storage.RestoreReplace {
+		return report, fmt.Errorf("%w: mode must be %s or %s", storage.ErrInvalidBackup, storage.RestoreMerge, storage.RestoreReplace)
+	}
+	prepared, err := storage.PrepareBackup(backup, m.clock.Now().UTC())
+	if err != nil {
+		return report, err
+	}
+
+	configs, deleted := m.configs, m.deleted
+	metadata, rules, templates, digests := m.metadata, m.rules, m.templates, m.digests
+	if opts.Mode == storage.RestoreReplace {
+		report.Collections[storage.SubredditConfigCollection] = models.RestoreCounts{Deleted: len(configs) + len(deleted), Inserted: len(prepared.SubredditConfigs)}
+		report.Collections[storage.SubredditMetadataCollection] = models.RestoreCounts{Deleted: len(metadata), Inserted: len(prepared.SubredditMetadata)}
+		report.Collections[storage.NotificationRulesCollection] = models.RestoreCounts{Deleted: len(rules), Inserted: len(prepared.NotificationRules)}
+		report.Collections[storage.ConfigTemplatesCollection] = models.RestoreCounts{Deleted: len(templates), Inserted: len(prepared.ConfigTemplates)}
+		report.Collections[storage.DigestsCollection] = models.RestoreCounts{Deleted: len(digests), Inserted: len(prepared.Digests)}
+		configs, deleted = make(map[string]models.SubredditConfig), nil
+		metadata, rules = make(map[string]models.SubredditMetadata), make(map[string]models.NotificationRule)
+		templates, digests = make(map[string]models.ConfigTemplate), make(map[string]models.Digest)
+	} else {
+		configs, deleted = maps.Clone(configs), slices.Clone(deleted)
+		metadata, rules, templates, digests = maps.Clone(metadata), maps.Clone(rules), maps.Clone(templates), maps.Clone(digests)
+	}
+
+	// count records an insert or update in merge mode and returns the id
+	// of the document replaced
+	count := func(collection string, existing bool, id primitive.ObjectID) primitive.ObjectID {
+		if opts.Mode == storage.RestoreReplace {
+			return primitive.NilObjectID
+		}
+		counts := report.Collections[collection]
+		if existing {
+			counts.Updated++
+		} else {
+			counts.Inserted++
+		}
+		report.Collections[collection] = counts
+		return id
+	}
+	for _, config := range prepared.SubredditConfigs {
+		if config.DeletedAt != nil {
+			i := slices.IndexFunc(deleted, func(stored models.SubredditConfig) bool { return stored.ID == config.ID })
+			count(storage.SubredditConfigCollection, i >= 0, config.ID)
+			if i >= 0 {
+				deleted[i] = config
+			} else {
+				deleted = append(deleted, config)
+			}
+			continue
+		}
+		stored, ok := configs[config.SubredditName]
+		if id := count(storage.SubredditConfigCollection, ok, stored.ID); opts.Mode == storage.RestoreMerge {
+			config.ID = id
+		}
+		configs[config.SubredditName] = config
+	}
+	for _, meta := range prepared.SubredditMetadata {
+		stored, ok := metadata[meta.SubredditName]
+		if id := count(storage.SubredditMetadataCollection, ok, stored.ID); opts.Mode == storage.RestoreMerge {
+			meta.ID = id
+		}
+		metadata[meta.SubredditName] = meta
+	}
+	for _, rule := range prepared.NotificationRules {
+		stored, ok := rules[rule.Name]
+		if id := count(storage.NotificationRulesCollection, ok, stored.ID); opts.Mode == storage.RestoreMerge {
+			rule.ID = id
+		}
+		rules[rule.Name] = rule
+	}
+	for _, template := range prepared.ConfigTemplates {
+		stored, ok := templates[template.Name]
+		if id := count(storage.ConfigTemplatesCollection, ok, stored.ID); opts.Mode == storage.RestoreMerge {
+			template.ID = id
+		}
+		templates[template.Name] = template
+	}
+	for _, digest := range prepared.Digests {
+		stored, ok := digests[digest.Label]
+		if id := count(storage.DigestsCollection, ok, stored.ID); opts.Mode == storage.RestoreMerge {
+			digest.ID = id
+		}
+		digests[digest.Label] = digest
+	}
+
+	if !opts.DryRun {
+		m.configs, m.deleted = configs, deleted
+		m.metadata, m.rules, m.templates, m.digests = metadata, rules, templates, digests
+	}
+	return report, nil
+}
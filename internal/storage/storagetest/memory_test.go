@@ -0,0 +1,162 @@
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// benchPosts returns n processed posts of one subreddit
+func benchPosts(n int) []models.Post {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	posts := make([]models.Post, n)
+	for i := range posts {
+		posts[i] = models.Post{
+			RedditID:  fmt.Sprintf("t3_%06x", i),
+			Title:     fmt.Sprintf("post %d", i),
+			Body:      "Some body text that goes on for a while.",
+			Author:    fmt.Sprintf("author%d", i%50),
+			Score:     i % 500,
+			Subreddit: "golang",
+			Tags:      []string{"release"},
+			CreatedAt: created.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return posts
+}
+
+// TestBackupRestoreRoundTrip restores an export into an empty store, as
+// storage.TestBackupRoundTrip does against MongoDB
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	restoredAt := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	deletedAt := restoredAt.Add(-24 * time.Hour)
+	source := NewMemory(clocktest.NewFake(restoredAt))
+	seed := &models.Backup{
+		Version: storage.BackupVersion,
+		SubredditConfigs: []models.SubredditConfig{
+			{SubredditName: "golang", Enabled: true, MaxPosts: 50},
+			{ID: primitive.NewObjectID(), SubredditName: "golang", DeletedAt: &deletedAt},
+		},
+		SubredditMetadata: []models.SubredditMetadata{{SubredditName: "golang", LastScrapedAt: deletedAt}},
+		NotificationRules: []models.NotificationRule{{Name: "releases", Enabled: true, Keywords: []string{"release"}, CooldownMinutes: 30}},
+		ConfigTemplates:   []models.ConfigTemplate{{Name: "news", MaxPosts: 100}},
+		Digests:           []models.Digest{{Label: "daily", Enabled: true, Recipients: []string{"ops@example.com"}}},
+	}
+	if _, err := source.RestoreBackup(ctx, seed, storage.RestoreOptions{}); err != nil {
+		t.Fatalf("seeding: %v", err)
+	}
+
+	exported, err := source.ExportBackup(ctx)
+	if err != nil {
+		t.Fatalf("ExportBackup() error = %v", err)
+	}
+	// Through JSON, as an uploaded backup arrives
+	encoded, err := json.Marshal(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uploaded models.Backup
+	if err := json.Unmarshal(encoded, &uploaded); err != nil {
+		t.Fatal(err)
+	}
+
+	target := NewMemory(clocktest.NewFake(restoredAt.Add(time.Hour)))
+	report, err := target.RestoreBackup(ctx, &uploaded, storage.RestoreOptions{Mode: storage.RestoreReplace})
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if got := report.Collections[storage.SubredditConfigCollection]; got.Inserted != 2 || got.Deleted != 0 {
+		t.Errorf("subreddit config counts = %+v, want 2 inserted", got)
+	}
+
+	restored, err := target.ExportBackup(ctx)
+	if err != nil {
+		t.Fatalf("ExportBackup() after restore error = %v", err)
+	}
+	for _, config := range restored.SubredditConfigs {
+		if !config.UpdatedAt.Equal(restoredAt.Add(time.Hour)) {
+			t.Errorf("config %s updated_at = %v, want the restoring store's clock", config.SubredditName, config.UpdatedAt)
+		}
+	}
+	if !bytes.Equal(normalizeBackup(t, restored), normalizeBackup(t, exported)) {
+		t.Errorf("restored backup differs from the export:\n%s\n%s", normalizeBackup(t, restored), normalizeBackup(t, exported))
+	}
+
+	// Merged again, every document matches one already there
+	report, err = target.RestoreBackup(ctx, &uploaded, storage.RestoreOptions{Mode: storage.RestoreMerge})
+	if err != nil {
+		t.Fatalf("RestoreBackup(merge) error = %v", err)
+	}
+	for collection, counts := range report.Collections {
+		if counts.Inserted != 0 || counts.Updated == 0 {
+			t.Errorf("%s counts after a second merge = %+v, want only updates", collection, counts)
+		}
+	}
+}
+
+// normalizeBackup returns backup as JSON without the times a restore sets
+func normalizeBackup(t *testing.T, backup *models.Backup) []byte {
+	t.Helper()
+	copied := *backup
+	copied.CreatedAt = time.Time{}
+	copied.SubredditConfigs = append([]models.SubredditConfig(nil), backup.SubredditConfigs...)
+	for i := range copied.SubredditConfigs {
+		copied.SubredditConfigs[i].UpdatedAt = time.Time{}
+	}
+	copied.SubredditMetadata = append([]models.SubredditMetadata(nil), backup.SubredditMetadata...)
+	for i := range copied.SubredditMetadata {
+		copied.SubredditMetadata[i].UpdatedAt = time.Time{}
+	}
+	encoded, err := json.Marshal(copied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+// BenchmarkUpsertPosts measures the in-memory store, so it runs without
+// external services; storage.BenchmarkMongoUpsertPosts measures MongoDB
+func BenchmarkUpsertPosts(b *testing.B) {
+	ctx := context.Background()
+	for _, size := range []int{100, 1000, 10000} {
+		posts := benchPosts(size)
+		for _, bench := range []struct {
+			name   string
+			stored bool
+		}{
+			{name: "insert", stored: false},
+			{name: "update", stored: true},
+		} {
+			b.Run(fmt.Sprintf("%d/%s", size, bench.name), func(b *testing.B) {
+				m := NewMemory(clocktest.NewFake(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)))
+				if bench.stored {
+					if _, err := m.UpsertPosts(ctx, posts, storage.UpsertOptions{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if !bench.stored {
+						b.StopTimer()
+						m = NewMemory(clocktest.NewFake(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)))
+						b.StartTimer()
+					}
+					if _, err := m.UpsertPosts(ctx, posts, storage.UpsertOptions{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// internal/tasks/adaptive_schedule.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+	"github.com/robfig/cron/v3"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// activityProfileMaxAge is how long a stored profile is used before it is
+// recomputed from the rollups
+const activityProfileMaxAge = 24 * time.Hour
+
+// adaptiveEntry is the monitor_subreddit schedule registered for an
+// adaptive subreddit
+type adaptiveEntry struct {
+	entryID  cron.EntryID
+	schedule string
+	tier     string
+}
+
+// adaptiveSchedules tracks the registered schedule of every adaptive
+// subreddit; RegisterTasks fills it and reconcile_adaptive_schedules swaps
+// entries while API handlers and runs read it
+type adaptiveSchedules struct {
+	mu      sync.Mutex
+	entries map[string]adaptiveEntry
+
+	// reconciling is held by the reconcile pass so that a manual trigger
+	// cannot register a subreddit's schedule twice
+	reconciling sync.Mutex
+}
+
+func (a *adaptiveSchedules) get(name string) (adaptiveEntry, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.entries[name]
+	return entry, ok
+}
+
+func (a *adaptiveSchedules) set(name string, entry adaptiveEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.entries == nil {
+		a.entries = make(map[string]adaptiveEntry)
+	}
+	a.entries[name] = entry
+}
+
+func (a *adaptiveSchedules) remove(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.entries, name)
+}
+
+// registerAdaptiveTask registers reconcile_adaptive_schedules and schedules it
+// (empty AdaptiveReconcileSchedule leaves it manual-only). It runs on every
+// instance, as every instance registers the subreddit schedules.
+func (tm *SubredditTaskManager) registerAdaptiveTask() error {
+	schema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{})
+
+	task, err := tm.registerTask("reconcile_adaptive_schedules", tm.reconcileAdaptiveSchedules, schema)
+	if err != nil {
+		return fmt.Errorf("failed to register adaptive schedule task: %w", err)
+	}
+
+	if tm.config.AdaptiveReconcileSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{}, tm.config.AdaptiveReconcileSchedule); err != nil {
+		return fmt.Errorf("failed to schedule adaptive schedule reconciliation: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "reconcile_adaptive_schedules", tm.config.AdaptiveReconcileSchedule, nil)
+	return nil
+}
+
+// reconcileAdaptiveSchedules refreshes the activity profile of every adaptive
+// subreddit older than a day and moves its schedule to the dense or sparse
+// cadence of the current hour. Subreddits switched to adaptive after startup
+// are picked up once a config commit reconciles them, or at the next restart.
+func (tm *SubredditTaskManager) reconcileAdaptiveSchedules(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	if !tm.adaptive.reconciling.TryLock() {
+		logger.Info("Adaptive schedule reconciliation already running, skipping")
+		return nil
+	}
+	defer tm.adaptive.reconciling.Unlock()
+
+	configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit configs: %v", err))
+		return err
+	}
+	now := tm.clock.Now().UTC()
+	profiles, err := tm.activityProfiles(ctx, configs, now)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get activity profiles: %v", err))
+		return err
+	}
+
+	switched, failed := 0, 0
+	for _, config := range configs {
+		entry, ok := tm.adaptive.get(config.SubredditName)
+		if !config.AdaptiveSchedule || !ok {
+			continue
+		}
+
+		schedule, tier := tm.adaptiveScheduleSource(config, profiles[config.SubredditName], now)
+		if schedule == entry.schedule {
+			continue
+		}
+		info, err := tm.monitorTask.RegisterSchedule(tm.scheduleParams(config), schedule)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to move r/%s to %s: %v", config.SubredditName, schedule, err))
+			failed++
+			continue
+		}
+		tm.monitorTask.DeleteSchedule(entry.entryID)
+		tm.adaptive.set(config.SubredditName, adaptiveEntry{entryID: info.EntryID, schedule: schedule, tier: tier})
+		tm.registered.setSchedule(config.SubredditName, info.EntryID, schedule, tier)
+		tm.schedules.update(ScheduleKindSubreddit, config.SubredditName, schedule, tier)
+		logger.Info(fmt.Sprintf("r/%s moved from %s to %s", config.SubredditName, entry.schedule, schedule))
+		switched++
+	}
+
+	logger.Info(fmt.Sprintf("Adaptive schedules reconciled: %d switched, %d failed", switched, failed))
+	if failed > 0 {
+		return fmt.Errorf("%d adaptive schedules failed to switch", failed)
+	}
+	return nil
+}
+
+// activityProfiles returns the activity profile of every adaptive subreddit
+// among configs, recomputing and storing those older than
+// activityProfileMaxAge. A subreddit whose profile could not be computed is
+// left out and keeps its current schedule.
+func (tm *SubredditTaskManager) activityProfiles(ctx context.Context, configs []models.SubredditConfig, now time.Time) (map[string]*models.ActivityProfile, error) {
+	var names []string
+	for _, config := range configs {
+		if config.AdaptiveSchedule {
+			names = append(names, config.SubredditName)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	stored, err := tm.storedActivityProfiles(ctx, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*models.ActivityProfile, len(names))
+	for _, name := range names {
+		if profile := tm.activityProfile(ctx, name, stored[name], now); profile != nil {
+			profiles[name] = profile
+		}
+	}
+	return profiles, nil
+}
+
+// storedActivityProfiles reads the stored profiles of the adaptive configs;
+// subreddits without one are missing from the map
+func (tm *SubredditTaskManager) storedActivityProfiles(ctx context.Context, configs []models.SubredditConfig) (map[string]*models.ActivityProfile, error) {
+	var names []string
+	for _, config := range configs {
+		if config.AdaptiveSchedule {
+			names = append(names, config.SubredditName)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	metadatas, err := tm.storage.GetSubredditMetadataByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make(map[string]*models.ActivityProfile, len(metadatas))
+	for name, metadata := range metadatas {
+		if metadata.Activity != nil {
+			profiles[name] = metadata.Activity
+		}
+	}
+	return profiles, nil
+}
+
+// activityProfile returns stored unless it is missing or older than a day,
+// in which case the profile is recomputed; nil when that fails
+func (tm *SubredditTaskManager) activityProfile(ctx context.Context, name string, stored *models.ActivityProfile, now time.Time) *models.ActivityProfile {
+	if stored != nil && now.Sub(stored.ComputedAt) <= activityProfileMaxAge {
+		return stored
+	}
+	profile, err := tm.computeActivityProfile(ctx, name, now)
+	if err != nil {
+		fmt.Printf("Failed to compute activity profile of r/%s: %v\n", name, err)
+		return nil
+	}
+	return profile
+}
+
+// computeActivityProfile averages the last AdaptiveProfileWeeks weeks of a
+// subreddit's hourly rollups by hour of the week and stores the result
+func (tm *SubredditTaskManager) computeActivityProfile(ctx context.Context, subreddit string, now time.Time) (*models.ActivityProfile, error) {
+	weeks := tm.config.AdaptiveProfileWeeks
+	to := now.Truncate(time.Hour)
+	from := to.AddDate(0, 0, -7*weeks)
+
+	rollups, err := tm.storage.GetRollups(ctx, subreddit, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := activityProfile(rollups, weeks, tm.config.AdaptivePeakHours)
+	profile.ComputedAt = now
+	if err := tm.storage.SetSubredditActivity(ctx, subreddit, profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// activityProfile averages rollups over weeks by hour of the week and picks
+// the peakHours busiest hours; hours without posts are never peaks
+func activityProfile(rollups []models.PostRollup, weeks, peakHours int) models.ActivityProfile {
+	perHour := make([]float64, models.HoursPerWeek)
+	for _, rollup := range rollups {
+		perHour[models.HourOfWeek(rollup.BucketStart)] += float64(rollup.PostCount)
+	}
+
+	var hours []int
+	for hour := range perHour {
+		perHour[hour] /= float64(weeks)
+		if perHour[hour] > 0 {
+			hours = append(hours, hour)
+		}
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return perHour[hours[i]] > perHour[hours[j]] })
+	if len(hours) > peakHours {
+		hours = hours[:peakHours]
+	}
+	sort.Ints(hours)
+
+	return models.ActivityProfile{PostsPerHour: perHour, PeakHours: hours, Weeks: weeks}
+}
+
+// adaptiveScheduleSource picks an adaptive subreddit's schedule for now:
+// AdaptiveMinInterval in a peak hour and AdaptiveMaxInterval otherwise. Push
+// subreddits and subreddits without a profile with peaks keep their static
+// schedule.
+func (tm *SubredditTaskManager) adaptiveScheduleSource(config models.SubredditConfig, profile *models.ActivityProfile, now time.Time) (string, string) {
+	if config.PushEnabled || profile == nil || len(profile.PeakHours) == 0 {
+		return tm.staticScheduleSource(config)
+	}
+	interval := tm.config.AdaptiveMaxInterval
+	if profile.IsPeak(now) {
+		interval = tm.config.AdaptiveMinInterval
+	}
+	return "@every " + interval.String(), ScheduleTierAdaptive
+}
@@ -0,0 +1,97 @@
+// internal/tasks/anomaly_tasks.go
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/logsample"
+	"reddit-orchestrator/internal/models"
+)
+
+// registerAnomalyTask registers detect_anomalies and schedules it across all subreddits
+func (tm *SubredditTaskManager) registerAnomalyTask() error {
+	anomalySchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"subreddit": blueberry.TypeString, // empty = all active subreddits
+	})
+
+	task, err := tm.registerTask("detect_anomalies", tm.leaderOnly(tm.detectAnomalies), anomalySchema)
+	if err != nil {
+		return fmt.Errorf("failed to register anomaly detection task: %w", err)
+	}
+
+	if tm.config.AnomalySchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{"subreddit": ""}, tm.config.AnomalySchedule); err != nil {
+		return fmt.Errorf("failed to schedule anomaly detection: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "detect_anomalies", tm.config.AnomalySchedule, nil)
+	return nil
+}
+
+// detectAnomalies flags authors posting more than the configured threshold
+// within one window. Records are keyed by author/window so reruns don't duplicate.
+func (tm *SubredditTaskManager) detectAnomalies(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+	params := tctx.GetParams()
+
+	var subreddits []string
+	if name, _ := params["subreddit"].(string); name != "" {
+		subreddits = []string{name}
+	} else {
+		configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to get subreddit configs: %v", err))
+			return err
+		}
+		for _, config := range configs {
+			subreddits = append(subreddits, config.SubredditName)
+		}
+	}
+
+	since := tm.clock.Now().UTC().Add(-tm.config.AnomalyLookback)
+	total := 0
+	// Tagging failures usually share one cause; they are logged once per error
+	tagFailures := logsample.NewAggregator("Failed to tag burst posts", "authors", 0)
+
+	for _, subreddit := range subreddits {
+		anomalies, err := tm.storage.FindAuthorBursts(ctx, subreddit, since,
+			tm.config.AnomalyWindow, tm.config.AnomalyAuthorThreshold)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to detect anomalies for r/%s: %v", subreddit, err))
+			return err
+		}
+
+		for i := range anomalies {
+			anomaly := &anomalies[i]
+			if err := tm.storage.UpsertAnomaly(ctx, anomaly); err != nil {
+				logger.Error(fmt.Sprintf("Failed to store anomaly for u/%s in r/%s: %v", anomaly.Author, subreddit, err))
+				return err
+			}
+
+			if tm.config.Features.AnomalyTagPosts.Enabled() {
+				if err := tm.storage.AddTagToAuthorPosts(ctx, subreddit, anomaly.Author,
+					anomaly.WindowStart, anomaly.WindowEnd, models.TagBurstAuthor); err != nil {
+					tagFailures.Add(err.Error(), fmt.Sprintf("r/%s/u/%s", subreddit, anomaly.Author))
+				}
+			}
+
+			logger.Info(fmt.Sprintf("r/%s: u/%s posted %d times between %s and %s",
+				subreddit, anomaly.Author, anomaly.Count,
+				anomaly.WindowStart.Format(time.RFC3339), anomaly.WindowEnd.Format(time.RFC3339)))
+		}
+		total += len(anomalies)
+	}
+	for _, line := range tagFailures.Lines() {
+		logger.Error(line)
+	}
+
+	logger.Success(fmt.Sprintf("Anomaly detection finished: %d author bursts across %d subreddits", total, len(subreddits)))
+	return nil
+}
@@ -0,0 +1,87 @@
+// internal/tasks/bookkeeping.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"reddit-orchestrator/internal/storage"
+)
+
+// bookkeepingTimeout bounds writes that record a run's progress once its
+// posts are stored
+const bookkeepingTimeout = 10 * time.Second
+
+// A cursor write that fails with a timeout or network error is tried
+// bookkeepingAttempts times in all, waiting bookkeepingBackoff before the
+// first retry and twice as long before each further one
+const (
+	bookkeepingAttempts = 3
+	bookkeepingBackoff  = 250 * time.Millisecond
+)
+
+// Stages a finished run can fail in, shown as RunInfo.FailedStage
+const (
+	FailedStageIngest      = "ingest"      // fetching, processing or storing posts
+	FailedStageBookkeeping = "bookkeeping" // recording progress after posts were stored
+)
+
+// BookkeepingError reports that a run stored its posts but failed to record
+// its progress; the next run re-fetches the same window
+type BookkeepingError struct {
+	Stored int
+	Err    error
+}
+
+func (e *BookkeepingError) Error() string {
+	return fmt.Sprintf("stored %d posts, then failed to record progress: %v", e.Stored, e.Err)
+}
+
+func (e *BookkeepingError) Unwrap() error {
+	return e.Err
+}
+
+// bookkeepingContext detaches from the run's context, so a run cancelled or
+// timed out right after storing its posts still advances its cursor
+func bookkeepingContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), bookkeepingTimeout)
+}
+
+// retryBookkeeping runs write with a bookkeeping context, retrying transient
+// storage failures. All attempts share the one bookkeepingTimeout, so the
+// retries can't extend a run past it.
+func (tm *SubredditTaskManager) retryBookkeeping(ctx context.Context, what string, logger runLogger, write func(context.Context) error) error {
+	ctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+
+	backoff := bookkeepingBackoff
+	for attempt := 1; ; attempt++ {
+		err := write(ctx)
+		if err == nil || attempt >= bookkeepingAttempts || !storage.IsRetryable(err) {
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Updating %s failed (%s), retrying in %v (attempt %d of %d): %v",
+			what, storage.ClassifyError(err), backoff, attempt+1, bookkeepingAttempts, err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-tm.clock.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// failedStage names the stage err failed in, or "" for a successful run
+func failedStage(err error) string {
+	if err == nil {
+		return ""
+	}
+	var bookkeeping *BookkeepingError
+	if errors.As(err, &bookkeeping) {
+		return FailedStageBookkeeping
+	}
+	return FailedStageIngest
+}
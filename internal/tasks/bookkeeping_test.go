@@ -0,0 +1,229 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestUpdateMetadataRetriesTransientFailures(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	transient := &storage.OperationError{Op: "update_last_scraped", Category: storage.ErrorNetwork, Err: errors.New("connection reset")}
+	permanent := &storage.OperationError{Op: "update_last_scraped", Category: storage.ErrorValidation, Err: errors.New("document invalid")}
+
+	tests := []struct {
+		name         string
+		failures     []error
+		wantCalls    int
+		wantErr      bool
+		wantAdvanced bool
+		wantBackoffs []time.Duration
+	}{
+		{name: "first attempt succeeds", wantCalls: 1, wantAdvanced: true},
+		{
+			name:         "fails twice, then succeeds",
+			failures:     []error{transient, transient},
+			wantCalls:    3,
+			wantAdvanced: true,
+			wantBackoffs: []time.Duration{bookkeepingBackoff, 2 * bookkeepingBackoff},
+		},
+		{
+			name:         "gives up after the last attempt",
+			failures:     []error{transient, transient, transient},
+			wantCalls:    bookkeepingAttempts,
+			wantErr:      true,
+			wantBackoffs: []time.Duration{bookkeepingBackoff, 2 * bookkeepingBackoff},
+		},
+		{name: "does not retry other errors", failures: []error{permanent}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			store := storagetest.NewMemory(clk)
+			for _, err := range tt.failures {
+				store.Fail("UpdateLastScraped", 1, err)
+			}
+			tm := newTestManager(t, testConfig(t), store, &fakeClient{}, &recordingNotifier{}, clk)
+
+			// Advance the fake clock through each backoff as the retry waits
+			done := make(chan error, 1)
+			go func() {
+				done <- tm.updateMetadata(context.Background(), "golang", start, models.RunStats{PostsProcessed: 4}, &recordingLogger{})
+			}()
+			var backoffs []time.Duration
+			var err error
+		wait:
+			for {
+				select {
+				case err = <-done:
+					break wait
+				default:
+				}
+				if clk.Waiters() > 0 {
+					// Step until the wait ends to measure the backoff
+					waited := time.Duration(0)
+					for clk.Waiters() > 0 {
+						clk.Advance(bookkeepingBackoff / 2)
+						waited += bookkeepingBackoff / 2
+					}
+					backoffs = append(backoffs, waited)
+					continue
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			if got := store.Calls("UpdateLastScraped"); got != tt.wantCalls {
+				t.Errorf("UpdateLastScraped called %d times, want %d", got, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(backoffs, tt.wantBackoffs) {
+				t.Errorf("waited %v between attempts, want %v", backoffs, tt.wantBackoffs)
+			}
+			var bookkeeping *BookkeepingError
+			if tt.wantErr != errors.As(err, &bookkeeping) {
+				t.Fatalf("updateMetadata() error = %v, want a BookkeepingError: %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (bookkeeping.Stored != 4 || failedStage(err) != FailedStageBookkeeping) {
+				t.Errorf("error = %v in stage %q, want 4 stored posts reported as bookkeeping", err, failedStage(err))
+			}
+
+			metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+			if advanced := metadata != nil && metadata.LastScrapedAt.Equal(start); advanced != tt.wantAdvanced {
+				t.Errorf("cursor advanced = %v, want %v", advanced, tt.wantAdvanced)
+			}
+		})
+	}
+}
+
+// cancellingStore stores posts, then blocks until the run's context is
+// cancelled, so the cursor update runs after the cancellation
+type cancellingStore struct {
+	*storagetest.Memory
+	stored chan struct{}
+}
+
+func (s *cancellingStore) UpsertPosts(ctx context.Context, posts []models.Post, opts storage.UpsertOptions) (storage.UpsertResult, error) {
+	result, err := s.Memory.UpsertPosts(ctx, posts, opts)
+	close(s.stored)
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * time.Second):
+	}
+	return result, err
+}
+
+func TestMonitorSubredditCancelledAfterStore(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cursor := start.Add(-2 * time.Hour)
+	clk := clocktest.NewFake(start)
+	store := &cancellingStore{Memory: storagetest.NewMemory(clk), stored: make(chan struct{})}
+	store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: cursor})
+	ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+		return []models.IngestionPost{ingestionPost("t3_a", start.Add(-time.Hour)), ingestionPost("t3_b", start.Add(-time.Hour))}, nil
+	}}
+	tm := newTestManager(t, testConfig(t), store, ingestion, &recordingNotifier{}, clk)
+
+	runErr := make(chan error, 1)
+	task, err := tm.blueBerry.RegisterTask(t.Name(), func(tctx *blueberry.TaskContext) error {
+		err := tm.monitorSubreddit(tctx)
+		runErr <- err
+		return err
+	}, blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"subreddit":       blueberry.TypeString,
+		"limit":           blueberry.TypeInt,
+		"since_timestamp": blueberry.TypeString,
+		"chunk_size":      blueberry.TypeInt,
+		"dry_run":         blueberry.TypeBool,
+		"force":           blueberry.TypeBool,
+		"keep_cursor":     blueberry.TypeBool,
+	}))
+	if err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	id, err := task.ExecuteNow(blueberry.TaskParams{
+		"subreddit":       "golang",
+		"limit":           10,
+		"since_timestamp": "",
+		"chunk_size":      0,
+		"dry_run":         false,
+		"force":           false,
+		"keep_cursor":     false,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteNow() error = %v", err)
+	}
+
+	select {
+	case <-store.stored:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the run did not store its posts")
+	}
+	if err := tm.blueBerry.CancelExecutionByID(id); err != nil {
+		t.Fatalf("CancelExecutionByID() error = %v", err)
+	}
+	select {
+	case err = <-runErr:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the cancelled run did not return")
+	}
+
+	if stage := failedStage(err); stage == FailedStageBookkeeping {
+		t.Errorf("run error = %v, want the cursor recorded despite the cancellation", err)
+	}
+	if got := len(store.Posts("golang")); got != 2 {
+		t.Errorf("stored %d posts, want 2", got)
+	}
+	metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+	if !metadata.LastScrapedAt.Equal(start) {
+		t.Errorf("cursor = %v, want it advanced to %v after the cancellation", metadata.LastScrapedAt, start)
+	}
+	if metadata.LastRun == nil || metadata.LastRun.PostsProcessed != 2 {
+		t.Errorf("last run = %+v, want 2 posts processed", metadata.LastRun)
+	}
+}
+
+func TestMonitorSubredditKeepsCustomMetadata(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clk := clocktest.NewFake(start)
+	store := storagetest.NewMemory(clk)
+	custom := models.SubredditMetadata{
+		SubredditName: "golang",
+		LastScrapedAt: start.Add(-2 * time.Hour),
+		// Not what a scrape with limit 10 would write
+		MonitorConfig: &models.MonitorConfig{Enabled: false, MaxPosts: 0},
+		About:         &models.AboutInfo{Available: true, Subscribers: 250000, FetchedAt: start.Add(-24 * time.Hour)},
+		Activity:      &models.ActivityProfile{PeakHours: []int{14, 15}, Weeks: 4, ComputedAt: start.Add(-24 * time.Hour)},
+	}
+	store.SetMetadata(custom)
+	ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+		return []models.IngestionPost{ingestionPost("t3_a", start.Add(-time.Hour))}, nil
+	}}
+	tm := newTestManager(t, testConfig(t), store, ingestion, &recordingNotifier{}, clk)
+
+	if status, messages := runTask(t, tm, tm.monitorSubreddit, scheduledParams("golang", 10)); status != "completed" {
+		t.Fatalf("status = %s, log %v", status, messages)
+	}
+
+	metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+	if !metadata.LastScrapedAt.Equal(start) || metadata.LastRun == nil {
+		t.Fatalf("metadata %+v, want the cursor advanced to %v and the run recorded", metadata, start)
+	}
+	if !reflect.DeepEqual(metadata.MonitorConfig, custom.MonitorConfig) {
+		t.Errorf("MonitorConfig = %+v, want %+v", metadata.MonitorConfig, custom.MonitorConfig)
+	}
+	if !reflect.DeepEqual(metadata.About, custom.About) || !reflect.DeepEqual(metadata.Activity, custom.Activity) {
+		t.Errorf("About %+v, Activity %+v, want them as stored before the scrape", metadata.About, metadata.Activity)
+	}
+	if calls := store.Calls("UpsertSubredditMetadata"); calls != 0 {
+		t.Errorf("UpsertSubredditMetadata called %d times, want the scrape to use UpdateLastScraped only", calls)
+	}
+}
@@ -0,0 +1,97 @@
+// internal/tasks/catchup.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// catchUpIntervalMultiplier is how many schedule intervals a subreddit may
+// miss before the startup sweep scrapes it immediately
+const catchUpIntervalMultiplier = 2
+
+// uncleanCatchUpIntervalMultiplier replaces it after an unclean shutdown,
+// when runs may have died mid-way: one missed interval is enough
+const uncleanCatchUpIntervalMultiplier = 1
+
+// GetSubredditsNeedingScrape returns active configs, in priority order, whose
+// last scrape is older than multiplier times their schedule interval (or
+// never happened)
+func (tm *SubredditTaskManager) GetSubredditsNeedingScrape(ctx context.Context, now time.Time, multiplier int) ([]models.SubredditConfig, error) {
+	configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subreddit configs: %w", err)
+	}
+
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		names = append(names, config.SubredditName)
+	}
+	metadatas, err := tm.storage.GetSubredditMetadataByNames(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subreddit metadata: %w", err)
+	}
+
+	var stale []models.SubredditConfig
+	for _, config := range configs {
+		interval, err := scheduleInterval(tm.effectiveSchedule(config), now)
+		if err != nil {
+			fmt.Printf("Skipping catch-up check for r/%s: %v\n", config.SubredditName, err)
+			continue
+		}
+
+		last := metadatas[config.SubredditName].LastScrapedAt
+		if last.IsZero() || now.Sub(last) > time.Duration(multiplier)*interval {
+			stale = append(stale, config)
+		}
+	}
+
+	return stale, nil
+}
+
+// CatchUp immediately runs every subreddit that missed its schedule while the
+// orchestrator was down, with a lower bar after an unclean shutdown. Configs
+// are already sorted by priority.
+func (tm *SubredditTaskManager) CatchUp(ctx context.Context, afterUncleanShutdown bool) (int, error) {
+	if tm.monitorTask == nil {
+		return 0, fmt.Errorf("monitor_subreddit task is not registered")
+	}
+
+	multiplier := catchUpIntervalMultiplier
+	if afterUncleanShutdown {
+		multiplier = uncleanCatchUpIntervalMultiplier
+	}
+	stale, err := tm.GetSubredditsNeedingScrape(ctx, tm.clock.Now().UTC(), multiplier)
+	if err != nil {
+		return 0, err
+	}
+
+	triggered := 0
+	for _, config := range stale {
+		if _, err := tm.monitorTask.ExecuteNow(tm.scheduleParams(config)); err != nil {
+			fmt.Printf("Failed to trigger catch-up run for r/%s: %v\n", config.SubredditName, err)
+			continue
+		}
+		triggered++
+	}
+
+	fmt.Printf("Catch-up sweep: %d of %d stale subreddits triggered\n", triggered, len(stale))
+	return triggered, nil
+}
+
+// scheduleInterval estimates the gap between two consecutive runs of a cron
+// or @every schedule
+func scheduleInterval(schedule string, now time.Time) (time.Duration, error) {
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+
+	next := parsed.Next(now)
+	return parsed.Next(next).Sub(next), nil
+}
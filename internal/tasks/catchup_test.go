@@ -0,0 +1,66 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestGetSubredditsNeedingScrapeBatchesMetadata(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clk := clocktest.NewFake(now)
+	store := storagetest.NewMemory(clk)
+	ctx := context.Background()
+
+	// Hourly subreddits: even ones scraped 30m ago, odd ones 3h ago, and
+	// every tenth never scraped
+	const count = 40
+	want := map[string]bool{}
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("sub%02d", i)
+		if err := store.UpsertSubredditConfig(ctx, &models.SubredditConfig{SubredditName: name, Enabled: true, Schedule: "0 * * * *"}, "test"); err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case i%10 == 0:
+			want[name] = true
+		case i%2 == 0:
+			store.SetMetadata(models.SubredditMetadata{SubredditName: name, LastScrapedAt: now.Add(-30 * time.Minute)})
+		default:
+			store.SetMetadata(models.SubredditMetadata{SubredditName: name, LastScrapedAt: now.Add(-3 * time.Hour)})
+			want[name] = true
+		}
+	}
+	tm := newTestManager(t, testConfig(t), store, &fakeClient{}, &recordingNotifier{}, clk)
+
+	stale, err := tm.GetSubredditsNeedingScrape(ctx, now, catchUpIntervalMultiplier)
+	if err != nil {
+		t.Fatalf("GetSubredditsNeedingScrape() error = %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, config := range stale {
+		got[config.SubredditName] = true
+	}
+	if len(got) != len(want) {
+		t.Errorf("stale = %v, want %v", got, want)
+	}
+	for name := range want {
+		if !got[name] {
+			t.Errorf("r/%s is missing from the stale list", name)
+		}
+	}
+
+	// One batched lookup instead of one per subreddit
+	if calls := store.Calls("GetSubredditMetadataByNames"); calls != 1 {
+		t.Errorf("GetSubredditMetadataByNames called %d times, want 1", calls)
+	}
+	if calls := store.Calls("GetSubredditMetadata"); calls != 0 {
+		t.Errorf("GetSubredditMetadata called %d times, want 0", calls)
+	}
+}
@@ -0,0 +1,133 @@
+// internal/tasks/chunks.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/storage"
+)
+
+// processFunc turns one chunk of fetched posts into posts ready to store
+type processFunc func(ctx context.Context, chunk []models.IngestionPost) ([]models.Post, processor.ProcessStats, error)
+
+// chunkedStore is the combined outcome of the chunks stored so far
+type chunkedStore struct {
+	Stored     int
+	Stats      processor.ProcessStats
+	Inserted   []models.Post // Posts new to storage, for rollups and notifications
+	Best       *models.Post  // Highest-scoring stored post, nil when none was stored
+	Chunks     int
+	ChunksDone int
+	// Checkpoint is the newest created_at of the stored chunks; every older
+	// fetched post has been stored
+	Checkpoint time.Time
+}
+
+// storeInChunks processes and stores fetched posts chunkSize at a time,
+// oldest first, so a large batch never has more than one chunk of processed
+// posts in flight. The context is checked between chunks. On failure the
+// result reports the chunks stored before it.
+func (tm *SubredditTaskManager) storeInChunks(ctx context.Context, posts []models.IngestionPost, chunkSize int, process processFunc, opts storage.UpsertOptions, logger runLogger) (chunkedStore, error) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+
+	result := chunkedStore{
+		Chunks: (len(posts) + chunkSize - 1) / chunkSize,
+		Stats:  processor.ProcessStats{Rejections: processor.RejectionSummary{}},
+	}
+	for start := 0; start < len(posts); start += chunkSize {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("stopped after %d of %d chunks: %w", result.ChunksDone, result.Chunks, err)
+		}
+		chunk := posts[start:min(start+chunkSize, len(posts))]
+
+		processed, stats, err := process(ctx, chunk)
+		if err != nil {
+			return result, fmt.Errorf("chunk %d of %d: %w", result.ChunksDone+1, result.Chunks, err)
+		}
+		upsertResult, err := tm.storePosts(ctx, processed, opts, logger)
+		if err != nil {
+			return result, fmt.Errorf("storing chunk %d of %d: %w", result.ChunksDone+1, result.Chunks, err)
+		}
+
+		result.Stored += len(processed)
+		result.Inserted = append(result.Inserted, upsertResult.InsertedPosts(processed)...)
+		for i := range processed {
+			if result.Best == nil || betterPost(processed[i], *result.Best) {
+				result.Best = &processed[i]
+			}
+		}
+		mergeProcessStats(&result.Stats, stats)
+		result.ChunksDone++
+		if newest := chunk[len(chunk)-1].CreatedAt; newest.After(result.Checkpoint) {
+			result.Checkpoint = newest
+		}
+		if result.Chunks > 1 {
+			logger.Info(fmt.Sprintf("Chunk %d/%d: stored %d posts (%d so far)",
+				result.ChunksDone, result.Chunks, len(processed), result.Stored))
+		}
+	}
+	return result, nil
+}
+
+// betterPost reports whether a outranks b as a run's best post: by score,
+// then the newer post, then reddit ID so the pick is stable. The ingestion
+// payload has no comment counts to break ties with.
+func betterPost(a, b models.Post) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.RedditID < b.RedditID
+}
+
+// mergeProcessStats adds one chunk's stats to the running total
+func mergeProcessStats(total *processor.ProcessStats, chunk processor.ProcessStats) {
+	for reason, count := range chunk.Rejections {
+		total.Rejections[reason] += count
+	}
+	for reason, ids := range chunk.RejectedSamples {
+		if total.RejectedSamples == nil {
+			total.RejectedSamples = make(map[string][]string)
+		}
+		// The first chunk with a rejection supplies its samples
+		if len(total.RejectedSamples[reason]) == 0 {
+			total.RejectedSamples[reason] = ids
+		}
+	}
+	total.NSFWUnknown += chunk.NSFWUnknown
+
+	total.Batch.Count += chunk.Batch.Count
+	if oldest := chunk.Batch.OldestCreatedAt; !oldest.IsZero() && (total.Batch.OldestCreatedAt.IsZero() || oldest.Before(total.Batch.OldestCreatedAt)) {
+		total.Batch.OldestCreatedAt = oldest
+	}
+	if newest := chunk.Batch.NewestCreatedAt; newest.After(total.Batch.NewestCreatedAt) {
+		total.Batch.NewestCreatedAt = newest
+	}
+}
+
+// chunkSizeParam reads the optional chunk_size task parameter; 0 or an empty
+// value uses defaultSize
+func chunkSizeParam(params blueberry.TaskParams, defaultSize int) (int, error) {
+	size, present, err := intParam(params, "chunk_size")
+	if err != nil {
+		return 0, err
+	}
+	if !present || size == 0 {
+		return defaultSize, nil
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("chunk_size must not be negative, got %d", size)
+	}
+	return int(size), nil
+}
@@ -0,0 +1,69 @@
+// internal/tasks/cleanup_tasks.go
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+)
+
+// cleanupActor is recorded in the config audit for purges
+const cleanupActor = "cleanup"
+
+// registerCleanupTask registers cleanup and schedules it (empty CleanupSchedule
+// leaves it manual-only)
+func (tm *SubredditTaskManager) registerCleanupTask() error {
+	cleanupSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{})
+
+	task, err := tm.registerTask("cleanup", tm.leaderOnly(tm.cleanup), cleanupSchema)
+	if err != nil {
+		return fmt.Errorf("failed to register cleanup task: %w", err)
+	}
+
+	if tm.config.CleanupSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{}, tm.config.CleanupSchedule); err != nil {
+		return fmt.Errorf("failed to schedule cleanup: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "cleanup", tm.config.CleanupSchedule, nil)
+	return nil
+}
+
+// cleanup purges subreddit configs soft-deleted longer than ConfigPurgeAfter
+// ago and, with partitioning, drops post partitions past PostRetentionMonths
+func (tm *SubredditTaskManager) cleanup(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	cutoff := tm.clock.Now().UTC().Add(-tm.config.ConfigPurgeAfter)
+	purged, err := tm.storage.PurgeDeletedSubredditConfigs(ctx, cutoff, cleanupActor)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to purge deleted subreddit configs (%d purged before the error): %v", purged, err))
+		return err
+	}
+
+	logger.Success(fmt.Sprintf("Cleanup finished: purged %d subreddit configs deleted before %s",
+		purged, cutoff.Format(time.RFC3339)))
+
+	if !tm.config.PostPartitioning || tm.config.PostRetentionMonths == 0 {
+		return nil
+	}
+
+	// Keep the current month plus PostRetentionMonths full months
+	now := tm.clock.Now().UTC()
+	before := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -tm.config.PostRetentionMonths, 0)
+	dropped, err := tm.storage.DropPostPartitionsBefore(ctx, before)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to drop post partitions before %s (dropped %v): %v", before.Format("2006-01"), dropped, err))
+		return err
+	}
+	if len(dropped) > 0 {
+		logger.Success(fmt.Sprintf("Dropped %d post partitions before %s: %s", len(dropped), before.Format("2006-01"), strings.Join(dropped, ", ")))
+	}
+	return nil
+}
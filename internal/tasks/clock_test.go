@@ -0,0 +1,239 @@
+package tasks
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+// These tests move a fake clock to the edges of the windows the task
+// manager computes from the time: retry backoffs, cursor resets and the
+// cleanup cutoffs.
+
+// waitForWaiter blocks until code under test waits on clk
+func waitForWaiter(t *testing.T, clk *clocktest.Fake) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for clk.Waiters() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("nothing waited on the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFetchRetryBackoffWindows(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+		return nil, &client.APIError{StatusCode: http.StatusServiceUnavailable, Retryable: true}
+	}}
+	cfg := testConfig(t)
+	cfg.MaxRetries = 3
+	tm := newTestManager(t, cfg, storagetest.NewMemory(clk), ingestion, &recordingNotifier{}, clk)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tm.fetchSubredditPosts(context.Background(), "golang", 25, 0, &recordingLogger{})
+		done <- err
+	}()
+
+	calls := func() int {
+		ingestion.mu.Lock()
+		defer ingestion.mu.Unlock()
+		return ingestion.calls
+	}
+	var backoffs []time.Duration
+	for retry := 1; retry <= cfg.MaxRetries; retry++ {
+		waitForWaiter(t, clk)
+		backoff := fetchRetryBackoff << (retry - 1)
+
+		// A moment before the backoff ends the retry is still waiting
+		clk.Advance(backoff - time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		if got := calls(); got != retry || clk.Waiters() != 1 {
+			t.Fatalf("retry %d: %d calls and %d waiters %v into the backoff, want %d calls still waiting",
+				retry, got, clk.Waiters(), backoff-time.Millisecond, retry)
+		}
+		clk.Advance(time.Millisecond)
+		backoffs = append(backoffs, backoff)
+	}
+
+	select {
+	case err := <-done:
+		if !client.IsServerError(err) {
+			t.Errorf("fetchSubredditPosts() error = %v, want the server error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetchSubredditPosts() still retrying after MaxRetries")
+	}
+	if got := calls(); got != cfg.MaxRetries+1 {
+		t.Errorf("GetSubredditPosts called %d times, want %d", got, cfg.MaxRetries+1)
+	}
+	if want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}; !reflect.DeepEqual(backoffs, want) {
+		t.Errorf("waited %v between attempts, want %v", backoffs, want)
+	}
+}
+
+// correctionStore records the cursor corrections a run saves
+type correctionStore struct {
+	*storagetest.Memory
+
+	mu          sync.Mutex
+	corrections []models.CursorCorrection
+}
+
+func (s *correctionStore) CorrectScrapeCursor(ctx context.Context, subredditName string, correction models.CursorCorrection) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.corrections = append(s.corrections, correction)
+	return true, nil
+}
+
+func TestCheckCursorWindows(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(t)
+	cfg.CursorResetLookback = 6 * time.Hour
+	cfg.CursorMaxAge = 7 * 24 * time.Hour
+	cfg.CursorProbeAfter = 0
+
+	tests := []struct {
+		name       string
+		cursor     time.Time
+		advance    time.Duration
+		wantReason string
+	}{
+		{name: "within the clock skew", cursor: start.Add(cursorClockSkew)},
+		{name: "past the clock skew", cursor: start.Add(cursorClockSkew + time.Second), wantReason: models.CursorReasonFuture},
+		{name: "future cursor the clock catches up with", cursor: start.Add(cursorClockSkew + time.Second), advance: time.Second},
+		{name: "at the max age", cursor: start.Add(-cfg.CursorMaxAge)},
+		{name: "past the max age", cursor: start.Add(-cfg.CursorMaxAge - time.Second), wantReason: models.CursorReasonTooOld},
+		{name: "ages past the max age", cursor: start.Add(-cfg.CursorMaxAge), advance: time.Second, wantReason: models.CursorReasonTooOld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			store := &correctionStore{Memory: storagetest.NewMemory(clk)}
+			tm := newTestManager(t, cfg, store, &fakeClient{}, &recordingNotifier{}, clk)
+			clk.Advance(tt.advance)
+
+			metadata := &models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: tt.cursor}
+			got := tm.checkCursor(context.Background(), "golang", metadata, false, &recordingLogger{})
+
+			if tt.wantReason == "" {
+				if !got.Equal(tt.cursor) || len(store.corrections) != 0 {
+					t.Errorf("checkCursor() = %v with corrections %+v, want the cursor %v kept", got, store.corrections, tt.cursor)
+				}
+				return
+			}
+			now := start.Add(tt.advance)
+			want := models.CursorCorrection{Reason: tt.wantReason, Before: tt.cursor, After: now.Add(-cfg.CursorResetLookback), At: now}
+			if !got.Equal(want.After) {
+				t.Errorf("checkCursor() = %v, want %v", got, want.After)
+			}
+			if len(store.corrections) != 1 || !reflect.DeepEqual(store.corrections[0], want) {
+				t.Errorf("saved corrections %+v, want %+v", store.corrections, want)
+			}
+		})
+	}
+}
+
+func TestCheckCursorProbeInterval(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(t)
+	cfg.CursorResetLookback = 6 * time.Hour
+	cfg.CursorMaxAge = 0
+	cfg.CursorProbeAfter = 3
+
+	clk := clocktest.NewFake(start)
+	store := &correctionStore{Memory: storagetest.NewMemory(clk)}
+	// The newest post is older than the reset lookback, so the reset goes
+	// back to just before it
+	newest := start.Add(-8 * time.Hour)
+	ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+		return []models.IngestionPost{ingestionPost("t3_new", newest)}, nil
+	}}
+	tm := newTestManager(t, cfg, store, ingestion, &recordingNotifier{}, clk)
+
+	cursor := start.Add(-2 * time.Hour)
+	probedAt := start.Add(-cursorProbeInterval + time.Minute)
+	metadata := &models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: cursor, ZeroPostRuns: 3, CursorProbedAt: &probedAt}
+
+	if got := tm.checkCursor(context.Background(), "golang", metadata, false, &recordingLogger{}); !got.Equal(cursor) || ingestion.calls != 0 {
+		t.Fatalf("checkCursor() = %v after %d probes, want the cursor kept within the probe interval", got, ingestion.calls)
+	}
+
+	clk.Advance(time.Minute)
+	got := tm.checkCursor(context.Background(), "golang", metadata, false, &recordingLogger{})
+	if want := newest.Add(-time.Second); !got.Equal(want) {
+		t.Errorf("checkCursor() = %v once the interval passed, want %v", got, want)
+	}
+	if ingestion.calls != 1 || len(store.corrections) != 1 || store.corrections[0].Reason != models.CursorReasonZeroPosts {
+		t.Errorf("%d probes and corrections %+v, want one zero-posts correction", ingestion.calls, store.corrections)
+	}
+}
+
+// cleanupStore records the cutoffs cleanup purges and drops before
+type cleanupStore struct {
+	*storagetest.Memory
+
+	purgedBefore  []time.Time
+	droppedBefore []time.Time
+}
+
+func (s *cleanupStore) PurgeDeletedSubredditConfigs(ctx context.Context, deletedBefore time.Time, actor string) (int, error) {
+	s.purgedBefore = append(s.purgedBefore, deletedBefore)
+	return 0, nil
+}
+
+func (s *cleanupStore) DropPostPartitionsBefore(ctx context.Context, before time.Time) ([]string, error) {
+	s.droppedBefore = append(s.droppedBefore, before)
+	return nil, nil
+}
+
+func TestCleanupCutoffs(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.ConfigPurgeAfter = 30 * 24 * time.Hour
+	cfg.PostPartitioning = true
+	cfg.PostRetentionMonths = 2
+
+	start := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	clk := clocktest.NewFake(start)
+	store := &cleanupStore{Memory: storagetest.NewMemory(clk)}
+	tm := newTestManager(t, cfg, store, &fakeClient{}, &recordingNotifier{}, clk)
+
+	// The partition cutoff moves at month boundaries only, and across years
+	for _, advance := range []time.Duration{0, time.Second, 31 * 24 * time.Hour, 275 * 24 * time.Hour} {
+		clk.Advance(advance)
+		if status, logs := runTask(t, tm, tm.cleanup, nil); status != "completed" {
+			t.Fatalf("cleanup %s, logs %q", status, logs)
+		}
+	}
+
+	wantPurged := []time.Time{
+		time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC),
+		time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	wantDropped := []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(store.purgedBefore, wantPurged) {
+		t.Errorf("purged configs deleted before %v, want %v", store.purgedBefore, wantPurged)
+	}
+	if !reflect.DeepEqual(store.droppedBefore, wantDropped) {
+		t.Errorf("dropped partitions before %v, want %v", store.droppedBefore, wantDropped)
+	}
+}
@@ -0,0 +1,296 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+)
+
+// These tests hammer the manager's shared state from many goroutines, the
+// way runs, API handlers and the watchdog reach it; run them with -race.
+
+// readUntil calls read in a loop on its own goroutine until stop is closed
+func readUntil(wg *sync.WaitGroup, stop <-chan struct{}, read func()) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				read()
+			}
+		}
+	}()
+}
+
+func TestRunQueueConcurrentRuns(t *testing.T) {
+	const (
+		maxConcurrent = 3
+		runs          = 64
+	)
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	q := newRunQueue(maxConcurrent, clk)
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	readUntil(&readers, stop, func() { q.snapshot() })
+	readUntil(&readers, stop, func() { q.runningCount() })
+	readUntil(&readers, stop, func() { q.depth(); q.oldestWaitSeconds() })
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := q.enqueue(fmt.Sprintf("sub%d", i))
+			if err := q.acquire(context.Background(), id); err != nil {
+				t.Errorf("acquire(%d) error = %v", id, err)
+				return
+			}
+			now := running.Add(1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+			running.Add(-1)
+			q.finish(id, nil)
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	if got := peak.Load(); got > maxConcurrent {
+		t.Errorf("%d runs held a slot at once, want at most %d", got, maxConcurrent)
+	}
+	snapshot := q.snapshot()
+	if len(snapshot.Queued) != 0 || len(snapshot.Running) != 0 || len(snapshot.Finished) != runs {
+		t.Fatalf("snapshot has %d queued, %d running, %d finished; want all %d finished",
+			len(snapshot.Queued), len(snapshot.Running), len(snapshot.Finished), runs)
+	}
+
+	// Snapshots are copies, so a handler holding one cannot change the queue
+	snapshot.Finished[0].Subreddit = "changed"
+	if got := q.snapshot().Finished[0].Subreddit; got == "changed" {
+		t.Error("changing a snapshot changed the queue")
+	}
+}
+
+func TestScheduleRegistryConcurrentAccess(t *testing.T) {
+	const subreddits = 50
+	var r scheduleRegistry
+	r.begin()
+	r.expect(subreddits)
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	readUntil(&readers, stop, func() { r.snapshot() })
+
+	var wg sync.WaitGroup
+	for i := 0; i < subreddits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.record(ScheduleEntry{Kind: ScheduleKindSubreddit, Name: fmt.Sprintf("sub%02d", i), Schedule: "*/5 * * * *", OK: true})
+		}(i)
+	}
+	wg.Wait()
+	if report := r.finish(); report.Registered != subreddits || report.InProgress {
+		t.Fatalf("finish() = %d registered, in progress %v; want %d registered", report.Registered, report.InProgress, subreddits)
+	}
+
+	// Runtime schedule swaps and config commits change the stored report
+	// while handlers read it
+	for i := 0; i < subreddits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("sub%02d", i)
+			if i%2 == 0 {
+				r.update(ScheduleKindSubreddit, name, "*/10 * * * *", "adaptive")
+				return
+			}
+			r.reconcile([]ScheduleEntry{{Kind: ScheduleKindSubreddit, Name: name, Schedule: "0 * * * *", OK: true}}, nil, subreddits)
+		}(i)
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	report := r.snapshot()
+	if report.Registered != subreddits || len(report.Entries) != subreddits {
+		t.Fatalf("report has %d registered of %d entries, want %d", report.Registered, len(report.Entries), subreddits)
+	}
+	report.Entries[0].Schedule = "changed"
+	if got := r.snapshot().Entries[0].Schedule; got == "changed" {
+		t.Error("changing a snapshot changed the stored report")
+	}
+}
+
+func TestScheduleMapsConcurrentAccess(t *testing.T) {
+	const names = 40
+	var adaptive adaptiveSchedules
+	var registered subredditSchedules
+
+	var wg sync.WaitGroup
+	for i := 0; i < names; i++ {
+		name := fmt.Sprintf("sub%02d", i)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			adaptive.set(name, adaptiveEntry{schedule: "*/5 * * * *", tier: "adaptive"})
+			adaptive.get(name)
+			adaptive.set(name, adaptiveEntry{schedule: "*/15 * * * *", tier: "adaptive"})
+		}()
+		go func() {
+			defer wg.Done()
+			registered.set(name, subredditEntry{schedule: "*/5 * * * *", limit: 25})
+			registered.setSchedule(name, 0, "*/15 * * * *", "adaptive")
+		}()
+		go func() {
+			defer wg.Done()
+			registered.get(name)
+			adaptive.remove(fmt.Sprintf("gone%02d", i))
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < names; i++ {
+		name := fmt.Sprintf("sub%02d", i)
+		if entry, ok := adaptive.get(name); !ok || entry.schedule != "*/15 * * * *" {
+			t.Errorf("adaptive %s = %+v, %v; want the last schedule set", name, entry, ok)
+		}
+		if entry, ok := registered.get(name); !ok || entry.schedule != "*/15 * * * *" || entry.limit != 25 {
+			t.Errorf("registered %s = %+v, %v; want the swapped schedule with its limit", name, entry, ok)
+		}
+	}
+
+	// Removing every other subreddit races with reads of the rest
+	keep := map[string]bool{}
+	for i := 0; i < names; i += 2 {
+		keep[fmt.Sprintf("sub%02d", i)] = true
+	}
+	wg.Add(2)
+	var removed map[string]subredditEntry
+	go func() {
+		defer wg.Done()
+		removed = registered.removeExcept(keep)
+	}()
+	go func() {
+		defer wg.Done()
+		for name := range keep {
+			registered.get(name)
+		}
+	}()
+	wg.Wait()
+	if len(removed) != names/2 {
+		t.Errorf("removeExcept() removed %d entries, want %d", len(removed), names/2)
+	}
+}
+
+func TestIngestionPauseConcurrentExtend(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var pause ingestionPause
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 30; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			pause.extend(now, now.Add(time.Duration(i)*time.Second), time.Hour)
+		}(i)
+		go func() {
+			defer wg.Done()
+			pause.activeUntil(now)
+		}()
+	}
+	wg.Wait()
+
+	// Every run sees the longest pause asked for, whichever extended last
+	if got, want := pause.activeUntil(now), now.Add(30*time.Second); !got.Equal(want) {
+		t.Errorf("activeUntil() = %v, want %v", got, want)
+	}
+}
+
+func TestMaintenanceNoticesFirstOnce(t *testing.T) {
+	pausedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var notices maintenanceNotices
+
+	// Concurrent runs of one subreddit log the pause once between them
+	var told atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if notices.first("golang", pausedAt) {
+				told.Add(1)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			notices.first(fmt.Sprintf("user%d", i), pausedAt)
+		}(i)
+	}
+	wg.Wait()
+
+	if got := told.Load(); got != 1 {
+		t.Errorf("first() was true %d times for one pause, want once", got)
+	}
+	if !notices.first("golang", pausedAt.Add(time.Hour)) {
+		t.Error("first() = false for a new pause")
+	}
+}
+
+func TestWatchdogConcurrentChecks(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	gap := time.Minute
+	var w watchdog
+	w.check(start, gap, false)
+
+	// Runs complete, /readyz reads the status and the watchdog checks, all
+	// at once; only the check past the allowed gap may trip it
+	var trips atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if tripped, _ := w.check(start.Add(watchdogGapFactor*gap+time.Second), gap, false); tripped {
+				trips.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			w.status()
+		}()
+		go func() {
+			defer wg.Done()
+			w.check(start.Add(gap), gap, false)
+		}()
+	}
+	wg.Wait()
+
+	if got := trips.Load(); got != 1 {
+		t.Errorf("watchdog tripped %d times, want once", got)
+	}
+	if !w.status().Stalled {
+		t.Fatal("status() not stalled after the watchdog tripped")
+	}
+	if !w.runCompleted(start.Add(5 * gap)) {
+		t.Error("runCompleted() did not report the recovery")
+	}
+	if w.status().Stalled {
+		t.Error("status() still stalled after a completed run")
+	}
+}
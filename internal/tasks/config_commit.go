@@ -0,0 +1,347 @@
+// internal/tasks/config_commit.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+var (
+	// ErrNothingStaged means a commit found the staging area empty
+	ErrNothingStaged = errors.New("no config changes are staged")
+	// ErrSchedulingInProgress means subreddit schedules are still being
+	// registered at startup, so a commit or restore could not reconcile them
+	ErrSchedulingInProgress = errors.New("subreddit schedules are still being registered")
+)
+
+// ConfigCommitError lists every reason the staged changes can't be
+// committed; none of them were applied
+type ConfigCommitError struct {
+	Problems []string
+}
+
+func (e *ConfigCommitError) Error() string {
+	return "staged config changes are invalid: " + strings.Join(e.Problems, "; ")
+}
+
+// ConfigCommit is what committing the staged config changes did
+type ConfigCommit struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+	// ScheduledRunsPerHour estimates the monitor runs an hour of every
+	// active subreddit once the changes apply
+	ScheduledRunsPerHour float64                `json:"scheduled_runs_per_hour"`
+	Schedules            ScheduleReconciliation `json:"schedules"`
+}
+
+// ScheduleReconciliation is how this instance's monitor_subreddit schedules
+// were brought in line with the active configs
+type ScheduleReconciliation struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+	// Failed maps subreddits whose schedule could not be registered to why
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// subredditEntry is the monitor_subreddit schedule registered for a subreddit
+type subredditEntry struct {
+	entryID  cron.EntryID
+	schedule string
+	tier     string
+	limit    int
+}
+
+// subredditSchedules tracks every registered subreddit schedule so a
+// reconciliation can change or remove it; adaptive subreddits are also in
+// adaptiveSchedules
+type subredditSchedules struct {
+	mu      sync.Mutex
+	entries map[string]subredditEntry
+}
+
+func (s *subredditSchedules) get(name string) (subredditEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[name]
+	return entry, ok
+}
+
+func (s *subredditSchedules) set(name string, entry subredditEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]subredditEntry)
+	}
+	s.entries[name] = entry
+}
+
+// setSchedule swaps the schedule of a registered entry, keeping its limit
+func (s *subredditSchedules) setSchedule(name string, entryID cron.EntryID, schedule, tier string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[name]; ok {
+		entry.entryID, entry.schedule, entry.tier = entryID, schedule, tier
+		s.entries[name] = entry
+	}
+}
+
+// removeExcept deletes and returns the entries of subreddits not in keep
+func (s *subredditSchedules) removeExcept(keep map[string]bool) map[string]subredditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := make(map[string]subredditEntry)
+	for name, entry := range s.entries {
+		if !keep[name] {
+			removed[name] = entry
+			delete(s.entries, name)
+		}
+	}
+	return removed
+}
+
+// CommitStagedConfigs applies every staged config change in one pass and
+// then reconciles the subreddit schedules once. The whole set is validated
+// first: each config, conflicts with live changes made since staging, names
+// differing only in case and, with MAX_SCHEDULED_RUNS_PER_HOUR, the scheduled
+// load. Any problem returns a ConfigCommitError and applies nothing.
+//
+// Schedules are reconciled on this instance; with HA_MODE, other instances
+// keep theirs until they restart.
+func (tm *SubredditTaskManager) CommitStagedConfigs(ctx context.Context, actor string) (ConfigCommit, error) {
+	var commit ConfigCommit
+	if tm.schedules.snapshot().InProgress {
+		return commit, ErrSchedulingInProgress
+	}
+
+	staged, err := tm.storage.GetStagedConfigChanges(ctx)
+	if err != nil {
+		return commit, fmt.Errorf("failed to get staged changes: %w", err)
+	}
+	if len(staged) == 0 {
+		return commit, ErrNothingStaged
+	}
+	page, err := tm.storage.GetAllSubredditConfigs(ctx, storage.ConfigFilter{}, storage.ListOptions{})
+	if err != nil {
+		return commit, fmt.Errorf("failed to get subreddit configs: %w", err)
+	}
+
+	runsPerHour, problems := tm.checkStagedConfigs(staged, page.Configs)
+	commit.ScheduledRunsPerHour = runsPerHour
+	if len(problems) > 0 {
+		return commit, &ConfigCommitError{Problems: problems}
+	}
+
+	commit.Created, commit.Updated, commit.Deleted = []string{}, []string{}, []string{}
+	applyErr := tm.applyStagedConfigs(ctx, staged, actor, &commit)
+
+	// Runs read their config when they start, so they see the new settings
+	// from here on; schedules follow in this single pass
+	commit.Schedules, err = tm.reconcileSubredditSchedules(ctx)
+	if applyErr != nil {
+		return commit, applyErr
+	}
+	if err != nil {
+		return commit, fmt.Errorf("configs applied, but reconciling schedules failed: %w", err)
+	}
+	log.Printf("Committed staged configs by %s: %d created, %d updated, %d deleted; schedules %d added, %d changed, %d removed",
+		actor, len(commit.Created), len(commit.Updated), len(commit.Deleted),
+		commit.Schedules.Added, commit.Schedules.Changed, commit.Schedules.Removed)
+	return commit, nil
+}
+
+// ReconcileSchedules brings this instance's subreddit schedules in line with
+// the stored configs after they changed outside CommitStagedConfigs, as a
+// backup restore does
+func (tm *SubredditTaskManager) ReconcileSchedules(ctx context.Context) (ScheduleReconciliation, error) {
+	if tm.schedules.snapshot().InProgress {
+		return ScheduleReconciliation{}, ErrSchedulingInProgress
+	}
+	return tm.reconcileSubredditSchedules(ctx)
+}
+
+// checkStagedConfigs validates the staged changes against each other and the
+// live configs, returning the estimated runs an hour once they apply
+func (tm *SubredditTaskManager) checkStagedConfigs(staged []models.StagedConfigChange, live []models.SubredditConfig) (float64, []string) {
+	var problems []string
+	result := make(map[string]models.SubredditConfig, len(live)+len(staged))
+	for _, config := range live {
+		result[config.SubredditName] = config
+	}
+
+	stagedNames := make(map[string]bool, len(staged))
+	for _, change := range staged {
+		name := change.SubredditName
+		stagedNames[strings.ToLower(name)] = true
+		if change.Conflict != "" {
+			problems = append(problems, fmt.Sprintf("r/%s: %s", name, change.Conflict))
+			continue
+		}
+		if change.Delete {
+			delete(result, name)
+			continue
+		}
+		if change.Config == nil {
+			problems = append(problems, fmt.Sprintf("r/%s: staged change has no config", name))
+			continue
+		}
+
+		config := *change.Config
+		if err := config.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("r/%s: %v", name, err))
+		}
+		if config.Schedule != "" {
+			if _, err := cron.ParseStandard(config.Schedule); err != nil {
+				problems = append(problems, fmt.Sprintf("r/%s: invalid schedule %q: %v", name, config.Schedule, err))
+			}
+		}
+		if err := models.ValidateMaxPosts(config.MaxPosts, tm.config.MaxPostsCeiling); err != nil {
+			problems = append(problems, fmt.Sprintf("r/%s: %v", name, err))
+		}
+		result[name] = config
+	}
+
+	// Names that differ only in case are the same subreddit on Reddit
+	spellings := make(map[string][]string)
+	for name := range result {
+		lower := strings.ToLower(name)
+		spellings[lower] = append(spellings[lower], name)
+	}
+	for lower, names := range spellings {
+		if len(names) > 1 && stagedNames[lower] {
+			sort.Strings(names)
+			problems = append(problems, fmt.Sprintf("%s name the same subreddit", strings.Join(names, " and ")))
+		}
+	}
+
+	now := tm.clock.Now()
+	var runsPerHour float64
+	for _, config := range result {
+		if !config.Enabled {
+			continue
+		}
+		interval, err := scheduleInterval(tm.effectiveSchedule(config), now)
+		if err != nil || interval <= 0 {
+			continue // reported above for staged configs
+		}
+		runsPerHour += float64(time.Hour) / float64(interval)
+	}
+	if limit := tm.config.MaxScheduledRunsPerHour; limit > 0 && runsPerHour > float64(limit) {
+		problems = append(problems, fmt.Sprintf("active subreddits would be scheduled for %.0f runs an hour, more than MAX_SCHEDULED_RUNS_PER_HOUR (%d)",
+			runsPerHour, limit))
+	}
+
+	sort.Strings(problems)
+	return runsPerHour, problems
+}
+
+// applyStagedConfigs writes the validated changes and unstages them in one
+// transaction, so a storage failure applies none of them. Without
+// transaction support each change is unstaged once it is applied, so a retry
+// only applies the rest.
+func (tm *SubredditTaskManager) applyStagedConfigs(ctx context.Context, staged []models.StagedConfigChange, actor string, commit *ConfigCommit) error {
+	return tm.storage.WithTransaction(ctx, func(ctx context.Context) error {
+		// The transaction may be retried
+		commit.Created, commit.Updated, commit.Deleted = []string{}, []string{}, []string{}
+		for _, change := range staged {
+			name := change.SubredditName
+			if change.Delete {
+				if _, err := tm.storage.DeleteSubredditConfig(ctx, name, actor); err != nil {
+					return fmt.Errorf("failed to delete r/%s: %w", name, err)
+				}
+				commit.Deleted = append(commit.Deleted, name)
+			} else {
+				if err := tm.storage.UpsertSubredditConfig(ctx, change.Config, actor); err != nil {
+					return fmt.Errorf("failed to save r/%s: %w", name, err)
+				}
+				if change.Action == models.StagedCreate {
+					commit.Created = append(commit.Created, name)
+				} else {
+					commit.Updated = append(commit.Updated, name)
+				}
+			}
+			if err := tm.storage.UnstageConfigChange(ctx, name); err != nil {
+				return fmt.Errorf("r/%s applied, but unstaging it failed: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// reconcileSubredditSchedules registers the monitor_subreddit schedule of
+// every active subreddit that has none, replaces schedules whose cadence or
+// limit changed and removes those of subreddits no longer active. Adaptive
+// subreddits keep their current adaptive schedule.
+func (tm *SubredditTaskManager) reconcileSubredditSchedules(ctx context.Context) (ScheduleReconciliation, error) {
+	var result ScheduleReconciliation
+	tm.adaptive.reconciling.Lock()
+	defer tm.adaptive.reconciling.Unlock()
+
+	configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	var changed []ScheduleEntry
+	active := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		name := config.SubredditName
+		active[name] = true
+		schedule, tier := tm.scheduleSource(config)
+		params := tm.scheduleParams(config)
+		limit, _ := params["limit"].(int)
+
+		current, registered := tm.registered.get(name)
+		if registered && current.schedule == schedule && current.limit == limit {
+			result.Unchanged++
+			continue
+		}
+		info, err := tm.monitorTask.RegisterSchedule(params, schedule)
+		if err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[name] = err.Error()
+			changed = append(changed, ScheduleEntry{Kind: ScheduleKindSubreddit, Name: name, Schedule: schedule, Tier: tier, Error: err.Error()})
+			continue
+		}
+		if registered {
+			tm.monitorTask.DeleteSchedule(current.entryID)
+			result.Changed++
+		} else {
+			result.Added++
+		}
+
+		tm.registered.set(name, subredditEntry{entryID: info.EntryID, schedule: schedule, tier: tier, limit: limit})
+		if config.AdaptiveSchedule {
+			tm.adaptive.set(name, adaptiveEntry{entryID: info.EntryID, schedule: schedule, tier: tier})
+		} else {
+			tm.adaptive.remove(name)
+		}
+		changed = append(changed, ScheduleEntry{Kind: ScheduleKindSubreddit, Name: name, Schedule: schedule, Tier: tier, OK: true})
+	}
+
+	var removed []string
+	for name, entry := range tm.registered.removeExcept(active) {
+		tm.monitorTask.DeleteSchedule(entry.entryID)
+		tm.adaptive.remove(name)
+		removed = append(removed, name)
+		result.Removed++
+	}
+
+	tm.schedules.reconcile(changed, removed, len(configs))
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+type transactionKey struct{}
+
+// transactionStore marks the context of its transactions and records which
+// writes ran in one. attempts runs fn that many times, as the driver does
+// when it retries a transaction after a transient error.
+type transactionStore struct {
+	*storagetest.Memory
+	attempts int
+
+	mu      sync.Mutex
+	writes  []string
+	outside []string
+}
+
+func (s *transactionStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.Memory.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		for i := 0; i < s.attempts; i++ {
+			err = fn(context.WithValue(ctx, transactionKey{}, true))
+		}
+		return err
+	})
+}
+
+func (s *transactionStore) record(ctx context.Context, write string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, write)
+	if ctx.Value(transactionKey{}) == nil {
+		s.outside = append(s.outside, write)
+	}
+}
+
+func (s *transactionStore) UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig, actor string) error {
+	s.record(ctx, "upsert "+config.SubredditName)
+	return s.Memory.UpsertSubredditConfig(ctx, config, actor)
+}
+
+func (s *transactionStore) DeleteSubredditConfig(ctx context.Context, subredditName, actor string) (bool, error) {
+	s.record(ctx, "delete "+subredditName)
+	return true, nil
+}
+
+func (s *transactionStore) UnstageConfigChange(ctx context.Context, subredditName string) error {
+	s.record(ctx, "unstage "+subredditName)
+	return nil
+}
+
+func TestApplyStagedConfigsInTransaction(t *testing.T) {
+	staged := []models.StagedConfigChange{
+		{SubredditName: "golang", Action: models.StagedCreate, Config: &models.SubredditConfig{SubredditName: "golang", Enabled: true}},
+		{SubredditName: "rust", Action: models.StagedUpdate, Config: &models.SubredditConfig{SubredditName: "rust", Enabled: true}},
+		{SubredditName: "python", Action: models.StagedDelete, Delete: true},
+	}
+	errDown := errors.New("storage is down")
+
+	tests := []struct {
+		name       string
+		attempts   int
+		fail       string
+		wantErr    error
+		wantCommit ConfigCommit
+		wantWrites int
+	}{
+		{
+			name:       "applied",
+			attempts:   1,
+			wantCommit: ConfigCommit{Created: []string{"golang"}, Updated: []string{"rust"}, Deleted: []string{"python"}},
+			wantWrites: 6,
+		},
+		{
+			name:       "retried",
+			attempts:   2,
+			wantCommit: ConfigCommit{Created: []string{"golang"}, Updated: []string{"rust"}, Deleted: []string{"python"}},
+			wantWrites: 12,
+		},
+		{
+			name:       "transaction not started",
+			attempts:   1,
+			fail:       "WithTransaction",
+			wantErr:    errDown,
+			wantCommit: ConfigCommit{Created: []string{}, Updated: []string{}, Deleted: []string{}},
+		},
+		{
+			name:       "write failing",
+			attempts:   1,
+			fail:       "UpsertSubredditConfig",
+			wantErr:    errDown,
+			wantCommit: ConfigCommit{Created: []string{}, Updated: []string{}, Deleted: []string{}},
+			wantWrites: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			store := &transactionStore{Memory: storagetest.NewMemory(clk), attempts: tt.attempts}
+			if tt.fail != "" {
+				store.Fail(tt.fail, 1, errDown)
+			}
+			tm := newTestManager(t, testConfig(t), store, &fakeClient{}, &recordingNotifier{}, clk)
+
+			commit := ConfigCommit{Created: []string{}, Updated: []string{}, Deleted: []string{}}
+			err := tm.applyStagedConfigs(context.Background(), staged, "admin", &commit)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("applyStagedConfigs() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(commit, tt.wantCommit) {
+				t.Errorf("commit = %+v, want %+v", commit, tt.wantCommit)
+			}
+			if store.Calls("WithTransaction") != 1 {
+				t.Errorf("WithTransaction called %d times, want 1", store.Calls("WithTransaction"))
+			}
+			if len(store.writes) != tt.wantWrites || len(store.outside) != 0 {
+				t.Errorf("writes %q, %q outside the transaction; want %d, all inside", store.writes, store.outside, tt.wantWrites)
+			}
+		})
+	}
+}
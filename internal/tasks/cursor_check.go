@@ -0,0 +1,128 @@
+// internal/tasks/cursor_check.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+)
+
+const (
+	// cursorClockSkew is how far ahead of this instance's clock a cursor
+	// written by another instance may be before it counts as in the future
+	cursorClockSkew = 5 * time.Minute
+	// cursorProbeInterval rate-limits the zero-post probe per subreddit
+	cursorProbeInterval = 24 * time.Hour
+)
+
+// checkCursor returns the since time a monitor run should fetch from. A
+// cursor in the future, older than CURSOR_MAX_AGE, or one that has fetched
+// nothing for CURSOR_PROBE_AFTER runs while the subreddit shows an unstored
+// recent post is reset to CURSOR_RESET_LOOKBACK ago and the correction is
+// recorded in metadata. Dry runs use the reset cursor without saving it or
+// probing.
+func (tm *SubredditTaskManager) checkCursor(ctx context.Context, subredditName string, metadata *models.SubredditMetadata, dryRun bool, logger runLogger) time.Time {
+	cursor := metadata.LastScrapedAt
+	now := tm.clock.Now().UTC()
+	safe := now.Add(-tm.config.CursorResetLookback)
+
+	switch {
+	case cursor.After(now.Add(cursorClockSkew)):
+		return tm.correctCursor(ctx, subredditName, models.CursorCorrection{
+			Reason: models.CursorReasonFuture, Before: cursor, After: safe, At: now,
+		}, dryRun, logger)
+	case tm.config.CursorMaxAge > 0 && cursor.Before(now.Add(-tm.config.CursorMaxAge)):
+		return tm.correctCursor(ctx, subredditName, models.CursorCorrection{
+			Reason: models.CursorReasonTooOld, Before: cursor, After: safe, At: now,
+		}, dryRun, logger)
+	}
+
+	if dryRun || tm.config.CursorProbeAfter <= 0 || metadata.ZeroPostRuns < tm.config.CursorProbeAfter {
+		return cursor
+	}
+	if metadata.CursorProbedAt != nil && now.Sub(*metadata.CursorProbedAt) < cursorProbeInterval {
+		return cursor
+	}
+
+	newest, ok := tm.probeCursor(ctx, subredditName, now, logger)
+	if !ok {
+		return cursor
+	}
+	// Refetch from before the post the cursor skipped
+	if newest.Before(safe) {
+		safe = newest.Add(-time.Second)
+	}
+	return tm.correctCursor(ctx, subredditName, models.CursorCorrection{
+		Reason: models.CursorReasonZeroPosts, Before: cursor, After: safe, At: now,
+	}, dryRun, logger)
+}
+
+// probeCursor fetches the subreddit's newest post without a since bound and
+// returns its created_at when it is recent but not stored, meaning the
+// cursor is skipping content. The probe is recorded first, so a failing one
+// still waits out cursorProbeInterval.
+func (tm *SubredditTaskManager) probeCursor(ctx context.Context, subredditName string, now time.Time, logger runLogger) (time.Time, bool) {
+	if err := tm.storage.RecordCursorProbe(ctx, subredditName, now); err != nil {
+		logger.Error(fmt.Sprintf("Failed to record cursor probe, skipping it: %v", err))
+		return time.Time{}, false
+	}
+
+	posts, err := tm.client.GetSubredditPosts(ctx, subredditName, 1, 0, 0)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Cursor probe for r/%s failed: %v", subredditName, err))
+		return time.Time{}, false
+	}
+	if len(posts) == 0 || posts[0].CreatedAt.IsZero() {
+		logger.Info(fmt.Sprintf("Cursor probe for r/%s: no recent activity", subredditName))
+		return time.Time{}, false
+	}
+	newest := posts[0]
+	if tm.config.CursorMaxAge > 0 && newest.CreatedAt.Before(now.Add(-tm.config.CursorMaxAge)) {
+		logger.Info(fmt.Sprintf("Cursor probe for r/%s: newest post is from %s, no recent activity",
+			subredditName, newest.CreatedAt.Format(time.RFC3339)))
+		return time.Time{}, false
+	}
+
+	id := strings.TrimSpace(newest.ID)
+	existing, err := tm.storage.GetExistingRedditIDs(ctx, []string{id})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Cursor probe for r/%s could not check storage: %v", subredditName, err))
+		return time.Time{}, false
+	}
+	if existing[id] {
+		logger.Info(fmt.Sprintf("Cursor probe for r/%s: newest post is already stored", subredditName))
+		return time.Time{}, false
+	}
+	return newest.CreatedAt, true
+}
+
+// correctCursor warns about a bad cursor, saves its correction unless this is
+// a dry run, and returns the corrected cursor
+func (tm *SubredditTaskManager) correctCursor(ctx context.Context, subredditName string, correction models.CursorCorrection, dryRun bool, logger runLogger) time.Time {
+	message := fmt.Sprintf("last_scraped_at %s (%s) reset to %s",
+		correction.Before.Format(time.RFC3339), correction.Reason, correction.After.Format(time.RFC3339))
+	if dryRun {
+		logger.Info(fmt.Sprintf("Dry run: %s for this run only", message))
+		return correction.After
+	}
+
+	applied, err := tm.storage.CorrectScrapeCursor(ctx, subredditName, correction)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to save the cursor correction, using it for this run only: %v", err))
+	} else if !applied {
+		logger.Info("Cursor changed since it was read, correction not saved")
+	}
+
+	tm.notify(ctx, notify.Notification{
+		Subject:   fmt.Sprintf("r/%s scrape cursor corrected", subredditName),
+		Message:   message,
+		Severity:  notify.SeverityWarning,
+		Subreddit: subredditName,
+		Error:     correction.Reason,
+	}, logger)
+	return correction.After
+}
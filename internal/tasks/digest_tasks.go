@@ -0,0 +1,146 @@
+// internal/tasks/digest_tasks.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/storage"
+)
+
+// digestWindow is how far back a digest looks for posts
+const digestWindow = 24 * time.Hour
+
+// registerDigestTask registers send_digest and schedules every enabled digest
+func (tm *SubredditTaskManager) registerDigestTask() error {
+	digestSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"label": blueberry.TypeString,
+	})
+
+	task, err := tm.registerTask("send_digest", tm.leaderOnly(tm.sendDigest), digestSchema)
+	if err != nil {
+		return fmt.Errorf("failed to register digest task: %w", err)
+	}
+
+	digests, err := tm.storage.GetAllDigests(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get digests: %w", err)
+	}
+
+	for _, digest := range digests {
+		if !digest.Enabled {
+			continue
+		}
+		schedule := digest.Schedule
+		if schedule == "" {
+			schedule = tm.config.DigestSchedule
+		}
+
+		_, err := task.RegisterSchedule(blueberry.TaskParams{"label": digest.Label}, schedule)
+		tm.recordSchedule(ScheduleKindDigest, digest.Label, schedule, err)
+	}
+
+	return nil
+}
+
+// sendDigest sends the top posts of the last day across the subreddits
+// carrying a label. Quiet days are skipped unless the digest sends empty ones.
+func (tm *SubredditTaskManager) sendDigest(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	label, err := tctx.GetParams().GetString("label")
+	if err != nil || label == "" {
+		return logger.Error("label is required")
+	}
+
+	digest, err := tm.storage.GetDigest(ctx, label)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get digest: %v", err))
+		return err
+	}
+	if digest == nil || !digest.Enabled {
+		logger.Info(fmt.Sprintf("Digest %s is missing or disabled, skipping", label))
+		return nil
+	}
+
+	configs, err := tm.storage.GetSubredditConfigsByLabel(ctx, label, storage.ListOptions{Fields: []string{"subreddit_name"}})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddits labelled %s: %v", label, err))
+		return err
+	}
+	subreddits := make([]string, 0, len(configs))
+	for _, config := range configs {
+		subreddits = append(subreddits, config.SubredditName)
+	}
+
+	since := tm.clock.Now().UTC().Add(-digestWindow)
+	var posts []models.Post
+	if len(subreddits) > 0 {
+		// One query over all subreddits; sort=top is indexed per subreddit
+		page, err := tm.storage.FindPosts(ctx, storage.PostFilter{
+			Subreddits:  subreddits,
+			TimeRange:   storage.TimeRange{From: since},
+			Sort:        storage.PostSortTop,
+			Limit:       tm.config.DigestTopPosts,
+			ExcludeNSFW: true,
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to query top posts: %v", err))
+			return err
+		}
+		posts = page.Posts
+	}
+
+	if len(posts) == 0 && !digest.SendEmpty {
+		logger.Info(fmt.Sprintf("No posts in %d subreddits labelled %s since %s, skipping digest",
+			len(subreddits), label, since.Format(time.RFC3339)))
+		return nil
+	}
+
+	subject, body := notify.RenderDigest(label, since, posts, tm.config.DigestPreviewChars)
+	counts := map[string]int{"posts": len(posts), "subreddits": len(subreddits)}
+	if err := tm.deliverDigest(ctx, *digest, subject, body, counts); err != nil {
+		logger.Error(fmt.Sprintf("Failed to send digest %s: %v", label, err))
+		return err
+	}
+	if err := tm.storage.MarkDigestSent(ctx, label, tm.clock.Now().UTC()); err != nil {
+		logger.Error(fmt.Sprintf("Failed to record digest delivery: %v", err))
+	}
+
+	logger.Success(fmt.Sprintf("Sent digest %s with %d posts from %d subreddits", label, len(posts), len(subreddits)))
+	return nil
+}
+
+// deliverDigest mails the digest's recipients and posts it to its webhook;
+// with neither, the default notifier receives it. Every channel is tried.
+func (tm *SubredditTaskManager) deliverDigest(ctx context.Context, digest models.Digest, subject, body string, counts map[string]int) error {
+	var errs []error
+	if len(digest.Recipients) > 0 {
+		if tm.mailer == nil {
+			errs = append(errs, errors.New("digest has recipients but SMTP_HOST is not set"))
+		} else if err := tm.mailer.Send(ctx, digest.Recipients, subject, body); err != nil {
+			errs = append(errs, fmt.Errorf("mail: %w", err))
+		}
+	}
+
+	notification := notify.Notification{Subject: subject, Message: body, Severity: notify.SeverityInfo, Counts: counts}
+	switch {
+	case digest.WebhookURL != "":
+		webhook := tm.webhooks.NewNotifier(digest.WebhookURL, tm.config.RequestTimeout)
+		if err := webhook.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	case len(digest.Recipients) == 0:
+		if err := tm.notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,107 @@
+// internal/tasks/document_audit.go
+package tasks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/metrics"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+)
+
+const (
+	// maxAuditedDocuments caps the oversized posts one audit handles
+	maxAuditedDocuments = 100
+	// maxNotifiedDocuments caps the posts listed in one notification
+	maxNotifiedDocuments = 10
+)
+
+// registerDocumentAuditTask registers audit_document_sizes and schedules it
+// (empty DocumentAuditSchedule leaves it manual-only)
+func (tm *SubredditTaskManager) registerDocumentAuditTask() error {
+	schema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"dry_run": blueberry.TypeBool, // list oversized posts without trimming them
+	})
+
+	task, err := tm.registerTask("audit_document_sizes", tm.leaderOnly(tm.auditDocumentSizes), schema)
+	if err != nil {
+		return fmt.Errorf("failed to register document audit task: %w", err)
+	}
+
+	if tm.config.DocumentAuditSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{"dry_run": false}, tm.config.DocumentAuditSchedule); err != nil {
+		return fmt.Errorf("failed to schedule document audit: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "audit_document_sizes", tm.config.DocumentAuditSchedule, nil)
+	return nil
+}
+
+// auditDocumentSizes finds posts larger than DocumentSizeThreshold, trims
+// their flair history to DocumentAuditKeepHistory entries (archiving the rest)
+// and sends a warning listing them. A dry run only logs the offenders.
+func (tm *SubredditTaskManager) auditDocumentSizes(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+	dryRun, _ := tctx.GetParams()["dry_run"].(bool)
+
+	oversized, err := tm.storage.FindOversizedPosts(ctx, tm.config.DocumentSizeThreshold, maxAuditedDocuments, tm.config.StorageStatsTimeout)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to find oversized posts: %v", err))
+		return err
+	}
+	if len(oversized) == 0 {
+		metrics.SetOversizedDocuments(0, 0)
+		logger.Success(fmt.Sprintf("No post is larger than %d bytes", tm.config.DocumentSizeThreshold))
+		return nil
+	}
+	metrics.SetOversizedDocuments(len(oversized), oversized[0].Bytes)
+
+	var lines []string
+	trimmed := 0
+	for i, post := range oversized {
+		line := describeOversizedPost(post)
+		if !dryRun && post.FlairHistory > tm.config.DocumentAuditKeepHistory {
+			moved, err := tm.storage.TrimPostHistory(ctx, post.Collection, post, tm.config.DocumentAuditKeepHistory)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to trim %s: %v", line, err))
+			} else if moved > 0 {
+				trimmed++
+				line += fmt.Sprintf(", archived %d flair changes", moved)
+			}
+		}
+		logger.Info(line)
+		if i < maxNotifiedDocuments {
+			lines = append(lines, line)
+		}
+	}
+	if len(oversized) > maxNotifiedDocuments {
+		lines = append(lines, fmt.Sprintf("... and %d more", len(oversized)-maxNotifiedDocuments))
+	}
+
+	if dryRun {
+		logger.Success(fmt.Sprintf("Dry run: %d posts larger than %d bytes", len(oversized), tm.config.DocumentSizeThreshold))
+		return nil
+	}
+
+	tm.notify(ctx, notify.Notification{
+		Subject:  fmt.Sprintf("%d posts larger than %d bytes", len(oversized), tm.config.DocumentSizeThreshold),
+		Message:  strings.Join(lines, "\n"),
+		Severity: notify.SeverityWarning,
+		Counts:   map[string]int{"posts": len(oversized), "trimmed": trimmed},
+	}, logger)
+	logger.Success(fmt.Sprintf("Audited %d oversized posts, trimmed %d", len(oversized), trimmed))
+	return nil
+}
+
+// describeOversizedPost names an oversized post with its size
+func describeOversizedPost(post models.OversizedPost) string {
+	return fmt.Sprintf("r/%s %s in %s: %d bytes, %d flair changes",
+		post.Subreddit, post.RedditID, post.Collection, post.Bytes, post.FlairHistory)
+}
@@ -0,0 +1,91 @@
+// internal/tasks/drain.go
+package tasks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+)
+
+// drainPollInterval is how often Drain checks for running runs
+const drainPollInterval = 100 * time.Millisecond
+
+// taskRuns counts the executions of every registered task, so Drain waits for
+// imports, repairs and maintenance as well as the monitor runs in the queue
+type taskRuns struct {
+	mu       sync.Mutex
+	running  int
+	draining bool
+}
+
+// begin records an execution starting; it reports false once draining has
+// started, and the execution must not run then
+func (r *taskRuns) begin() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.draining {
+		return false
+	}
+	r.running++
+	return true
+}
+
+// end records an execution begin let through finishing
+func (r *taskRuns) end() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running--
+}
+
+// startDrain makes begin refuse new executions
+func (r *taskRuns) startDrain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draining = true
+}
+
+// count returns the number of executions in progress
+func (r *taskRuns) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// registerTask registers run with BlueBerry, counted in tm.runs so Drain
+// waits for it. Every task is registered through here.
+func (tm *SubredditTaskManager) registerTask(name string, run blueberry.TaskFunc, schema blueberry.TaskSchema) (*blueberry.Task, error) {
+	return tm.blueBerry.RegisterTask(name, func(tctx *blueberry.TaskContext) error {
+		if !tm.runs.begin() {
+			tctx.GetLogger().Infof("Skipped: the task manager is draining for shutdown")
+			return errDraining
+		}
+		defer tm.runs.end()
+		return run(tctx)
+	}, schema)
+}
+
+// Drain stops accepting runs and waits until every running task execution
+// has finished or ctx expires. It returns the number of executions still
+// running (abandoned).
+func (tm *SubredditTaskManager) Drain(ctx context.Context) (int, error) {
+	tm.queue.startDrain()
+	tm.runs.startDrain()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		running := tm.runs.count()
+		if running == 0 {
+			return 0, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return running, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestDrainRejectsQueuedAndNewRuns(t *testing.T) {
+	queue := newRunQueue(1, clock.Real)
+	running := queue.enqueue("golang")
+	if err := queue.acquire(context.Background(), running); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	queued := queue.enqueue("rust")
+	acquired := make(chan error, 1)
+	go func() { acquired <- queue.acquire(context.Background(), queued) }()
+
+	queue.startDrain()
+
+	select {
+	case err := <-acquired:
+		if !errors.Is(err, errDraining) {
+			t.Errorf("queued acquire() error = %v, want errDraining", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued run was not released by startDrain")
+	}
+	queue.finish(queued, errDraining)
+
+	late := queue.enqueue("python")
+	if err := queue.acquire(context.Background(), late); !errors.Is(err, errDraining) {
+		t.Errorf("acquire() after drain error = %v, want errDraining", err)
+	}
+	queue.finish(late, errDraining)
+
+	if got := queue.runningCount(); got != 1 {
+		t.Errorf("runningCount() = %d, want the run started before draining", got)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name        string
+		finishAfter time.Duration
+		timeout     time.Duration
+		wantRunning int
+		wantErr     error
+	}{
+		{name: "waits for running runs", finishAfter: 50 * time.Millisecond, timeout: 5 * time.Second},
+		{name: "gives up at the deadline", finishAfter: time.Hour, timeout: 50 * time.Millisecond, wantRunning: 1, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := &SubredditTaskManager{queue: newRunQueue(2, clock.Real)}
+			if !tm.runs.begin() {
+				t.Fatal("begin() refused a run before draining")
+			}
+			timer := time.AfterFunc(tt.finishAfter, tm.runs.end)
+			defer timer.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			running, err := tm.Drain(ctx)
+
+			if running != tt.wantRunning || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Drain() = %d, %v; want %d, %v", running, err, tt.wantRunning, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDrainWithNothingRunning(t *testing.T) {
+	tm := &SubredditTaskManager{queue: newRunQueue(1, clock.Real)}
+	running, err := tm.Drain(context.Background())
+	if running != 0 || err != nil {
+		t.Errorf("Drain() = %d, %v; want 0, nil", running, err)
+	}
+}
+
+func TestDrainWaitsForEveryTask(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	tm := newTestManager(t, testConfig(t), storagetest.NewMemory(clk), &fakeClient{}, &recordingNotifier{}, clk)
+
+	// An import or repair holds no queue slot
+	started := make(chan struct{})
+	release := make(chan struct{})
+	task, err := tm.registerTask(t.Name(), func(tctx *blueberry.TaskContext) error {
+		close(started)
+		<-release
+		return nil
+	}, blueberry.NewTaskSchema(blueberry.TaskParamDefinition{}))
+	if err != nil {
+		t.Fatalf("registerTask() error = %v", err)
+	}
+	if _, err := task.ExecuteNow(blueberry.TaskParams{}); err != nil {
+		t.Fatalf("ExecuteNow() error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	running, err := tm.Drain(ctx)
+	cancel()
+	if running != 1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain() = %d, %v while the task runs; want 1, %v", running, err, context.DeadlineExceeded)
+	}
+
+	// Executions starting after the drain are refused
+	id, err := task.ExecuteNow(blueberry.TaskParams{})
+	if err != nil {
+		t.Fatalf("ExecuteNow() error = %v", err)
+	}
+	if status, _ := waitForRun(t, id); status != "failed" {
+		t.Errorf("run started while draining %s, want failed", status)
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if running, err := tm.Drain(ctx); running != 0 || err != nil {
+		t.Errorf("Drain() = %d, %v after the task finished; want 0, nil", running, err)
+	}
+}
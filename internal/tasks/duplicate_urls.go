@@ -0,0 +1,74 @@
+// internal/tasks/duplicate_urls.go
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/notify"
+)
+
+// maxNotifiedDuplicateURLs caps the URLs listed in one notification
+const maxNotifiedDuplicateURLs = 10
+
+// registerDuplicateURLTask registers report_duplicate_urls and schedules it
+// (empty DuplicateURLSchedule leaves it manual-only)
+func (tm *SubredditTaskManager) registerDuplicateURLTask() error {
+	schema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{})
+
+	task, err := tm.registerTask("report_duplicate_urls", tm.leaderOnly(tm.reportDuplicateURLs), schema)
+	if err != nil {
+		return fmt.Errorf("failed to register duplicate URL task: %w", err)
+	}
+
+	if tm.config.DuplicateURLSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{}, tm.config.DuplicateURLSchedule); err != nil {
+		return fmt.Errorf("failed to schedule duplicate URL report: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "report_duplicate_urls", tm.config.DuplicateURLSchedule, nil)
+	return nil
+}
+
+// reportDuplicateURLs sends one notification listing the external links
+// posted to at least DuplicateURLMinSubreddits subreddits within
+// DuplicateURLLookback; nothing is sent when there are none
+func (tm *SubredditTaskManager) reportDuplicateURLs(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	since := tm.clock.Now().UTC().Add(-tm.config.DuplicateURLLookback)
+	report, err := tm.storage.GetDuplicateURLReport(ctx, since, tm.config.DuplicateURLMinSubreddits)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to build duplicate URL report: %v", err))
+		return err
+	}
+	if len(report) == 0 {
+		logger.Info(fmt.Sprintf("No URL was posted to %d or more subreddits since %s",
+			tm.config.DuplicateURLMinSubreddits, since.Format(time.RFC3339)))
+		return nil
+	}
+
+	var lines []string
+	for i, entry := range report {
+		if i == maxNotifiedDuplicateURLs {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(report)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %d posts in %d subreddits (r/%s)",
+			entry.URL, entry.PostCount, entry.SubredditCount, strings.Join(entry.Subreddits, ", r/")))
+	}
+	tm.notify(ctx, notify.Notification{
+		Subject:  fmt.Sprintf("%d links spread across %d or more subreddits", len(report), tm.config.DuplicateURLMinSubreddits),
+		Message:  strings.Join(lines, "\n"),
+		Severity: notify.SeverityInfo,
+		Counts:   map[string]int{"urls": len(report)},
+	}, logger)
+	return nil
+}
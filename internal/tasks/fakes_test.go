@@ -0,0 +1,300 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/processor"
+)
+
+// testConfig loads the configuration defaults
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("ENV", "production")
+	t.Setenv("WEB_AUTH_USER", "admin")
+	t.Setenv("WEB_AUTH_PASSWORD", "a-long-enough-passphrase")
+	t.Setenv("WEB_AUTH_PASSWORD_HASH", "")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	return cfg
+}
+
+// newTestManager returns a task manager over store and ingestion with a
+// BlueBerry keeping its runs in memory
+func newTestManager(t *testing.T, cfg *config.Config, store TaskStorage, ingestion client.IngestionClientInterface, notifier notify.NotifierInterface, clk clock.Clock) *SubredditTaskManager {
+	t.Helper()
+	return NewSubredditTaskManager(newTestBlueBerry(t), store, ingestion, processor.NewProcessor(nil, nil, clk),
+		nil, notifier, nil, nil, cfg, clk)
+}
+
+// BlueBerry instances share package-level metrics that NewBlueBerryInstance
+// replaces while earlier runs may still update them, so all tests share one
+// instance and its in-memory run store
+var (
+	testBlueBerryOnce sync.Once
+	testBlueBerry     *blueberry.BlueBerry
+	testRunDB         *memoryRunDB
+)
+
+func newTestBlueBerry(t *testing.T) *blueberry.BlueBerry {
+	t.Helper()
+	testBlueBerryOnce.Do(func() {
+		testRunDB = &memoryRunDB{}
+		testBlueBerry = blueberry.NewBlueBerryInstance(testRunDB)
+	})
+	return testBlueBerry
+}
+
+// runTask registers fn as a one-off task, runs it with params and waits for
+// it to finish. It returns the run's final status and its log messages.
+func runTask(t *testing.T, tm *SubredditTaskManager, fn blueberry.TaskFunc, params blueberry.TaskParams) (string, []string) {
+	t.Helper()
+
+	definition := blueberry.TaskParamDefinition{}
+	for key, value := range params {
+		switch value.(type) {
+		case int:
+			definition[key] = blueberry.TypeInt
+		case bool:
+			definition[key] = blueberry.TypeBool
+		default:
+			definition[key] = blueberry.TypeString
+		}
+	}
+	task, err := tm.blueBerry.RegisterTask(t.Name(), fn, blueberry.NewTaskSchema(definition))
+	if err != nil {
+		t.Fatalf("RegisterTask() error = %v", err)
+	}
+	id, err := task.ExecuteNow(params)
+	if err != nil {
+		t.Fatalf("ExecuteNow() error = %v", err)
+	}
+	return waitForRun(t, id)
+}
+
+// waitForRun waits for a test BlueBerry run to finish and returns its
+// final status and log messages
+func waitForRun(t *testing.T, id int) (string, []string) {
+	t.Helper()
+	db := testRunDB
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		run, err := db.GetTaskRunByID(context.Background(), id)
+		if err == nil && run != nil && run.Status != "started" {
+			logs, err := db.GetTaskRunLogs(context.Background(), id)
+			if err != nil {
+				t.Fatalf("GetTaskRunLogs() error = %v", err)
+			}
+			messages := make([]string, 0, len(logs))
+			for _, entry := range logs {
+				messages = append(messages, entry.Message)
+			}
+			return run.Status, messages
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("task run %d did not finish", id)
+	return "", nil
+}
+
+// memoryRunDB keeps BlueBerry task runs and their logs in memory
+type memoryRunDB struct {
+	mu   sync.Mutex
+	runs []blueberry.TaskRun
+	logs []blueberry.TaskRunLog
+}
+
+func (db *memoryRunDB) SaveTaskRun(ctx context.Context, taskRun *blueberry.TaskRun) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if taskRun.ID == 0 {
+		taskRun.ID = len(db.runs) + 1
+		db.runs = append(db.runs, *taskRun)
+		return nil
+	}
+	db.runs[taskRun.ID-1] = *taskRun
+	return nil
+}
+
+func (db *memoryRunDB) SaveTaskRunLog(ctx context.Context, taskRunLog *blueberry.TaskRunLog) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	taskRunLog.ID = len(db.logs) + 1
+	db.logs = append(db.logs, *taskRunLog)
+	return nil
+}
+
+func (db *memoryRunDB) GetTaskRuns(ctx context.Context) ([]blueberry.TaskRun, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]blueberry.TaskRun(nil), db.runs...), nil
+}
+
+func (db *memoryRunDB) GetTaskRunByID(ctx context.Context, id int) (*blueberry.TaskRun, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if id < 1 || id > len(db.runs) {
+		return nil, nil
+	}
+	run := db.runs[id-1]
+	return &run, nil
+}
+
+func (db *memoryRunDB) GetTaskRunLogs(ctx context.Context, taskRunID int) ([]blueberry.TaskRunLog, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var logs []blueberry.TaskRunLog
+	for _, entry := range db.logs {
+		if entry.TaskRunID == taskRunID {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+func (db *memoryRunDB) GetPaginatedTaskRunLogs(ctx context.Context, taskRunID int, level string, page, size int) ([]blueberry.TaskRunLog, int, error) {
+	logs, err := db.GetTaskRunLogs(ctx, taskRunID)
+	return logs, len(logs), err
+}
+
+func (db *memoryRunDB) GetPaginatedTaskRunsForTaskName(ctx context.Context, name string, page, limit int) ([]blueberry.TaskRun, error) {
+	return nil, nil
+}
+
+func (db *memoryRunDB) GetTaskRunsCountForTaskName(ctx context.Context, name string) (int, error) {
+	return 0, nil
+}
+
+func (db *memoryRunDB) Close() error { return nil }
+
+// recordingLogger keeps the messages a run logs
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) Info(message string) error {
+	l.record(message)
+	return nil
+}
+
+func (l *recordingLogger) Error(message string) error {
+	l.record(message)
+	return nil
+}
+
+func (l *recordingLogger) Success(message string) error {
+	l.record(message)
+	return nil
+}
+
+func (l *recordingLogger) record(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, message)
+}
+
+// fakeClient answers ingestion calls from its func fields; a nil field
+// returns no posts
+type fakeClient struct {
+	client.IngestionClientInterface
+
+	mu        sync.Mutex
+	calls     int
+	subreddit func(subreddit string, limit int, since, until int64) ([]models.IngestionPost, error)
+	user      func(username string, limit int, since int64) ([]models.IngestionPost, error)
+	top       func(subreddit, timeRange string, limit int, after string) (*client.TopPostsPage, error)
+	topAt     func(subreddit, timeRange string, limit, offset int) (*client.TopPostsPage, error)
+}
+
+func (c *fakeClient) GetSubredditPosts(ctx context.Context, subreddit string, limit int, since, until int64) ([]models.IngestionPost, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	if c.subreddit == nil {
+		return nil, nil
+	}
+	return c.subreddit(subreddit, limit, since, until)
+}
+
+func (c *fakeClient) GetTopPosts(ctx context.Context, subreddit, timeRange string, limit int, after string) (*client.TopPostsPage, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	if c.top == nil {
+		return &client.TopPostsPage{}, nil
+	}
+	return c.top(subreddit, timeRange, limit, after)
+}
+
+func (c *fakeClient) GetTopPostsAt(ctx context.Context, subreddit, timeRange string, limit, offset int) (*client.TopPostsPage, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	if c.topAt == nil {
+		return &client.TopPostsPage{}, nil
+	}
+	return c.topAt(subreddit, timeRange, limit, offset)
+}
+
+func (c *fakeClient) GetUserPosts(ctx context.Context, username string, limit int, since int64) ([]models.IngestionPost, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	if c.user == nil {
+		return nil, nil
+	}
+	return c.user(username, limit, since)
+}
+
+// recordingNotifier keeps the notifications sent through it
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notify.Notification
+	err  error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return n.err
+}
+
+func (n *recordingNotifier) notifications() []notify.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notify.Notification(nil), n.sent...)
+}
+
+// ingestionPost returns a SFW post created at createdAt
+func ingestionPost(id string, createdAt time.Time) models.IngestionPost {
+	sfw := false
+	return models.IngestionPost{ID: id, Title: "post " + id, Author: "someone", Score: 1, CreatedAt: createdAt, IsNSFW: &sfw}
+}
+
+// scheduledParams are monitor_subreddit's parameters for a scheduled run of
+// subreddit
+func scheduledParams(subreddit string, limit int) blueberry.TaskParams {
+	return blueberry.TaskParams{
+		"subreddit":       subreddit,
+		"limit":           limit,
+		"since_timestamp": "",
+		"chunk_size":      0,
+		"dry_run":         false,
+		"force":           false,
+		"keep_cursor":     false,
+	}
+}
@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+// TestMonitorSubredditErrorClasses runs monitor_subreddit against an
+// ingestion API answering each error class: not found marks the subreddit
+// unavailable, rate limits pause ingestion and server errors are retried
+func TestMonitorSubredditErrorClasses(t *testing.T) {
+	okBody := fmt.Sprintf(`{"posts":[{"id":"t3_a","title":"a","author":"someone","score":1,"created_at":%q,"is_nsfw":false}],"meta":{}}`,
+		time.Now().UTC().Add(-10*time.Minute).Format(time.RFC3339))
+
+	tests := []struct {
+		name            string
+		statuses        []int // answered in turn, the last one from then on
+		wantStatus      string
+		wantRequests    int32
+		wantUnavailable bool
+		wantPaused      bool
+		wantStored      int
+	}{
+		{name: "not found", statuses: []int{http.StatusNotFound}, wantStatus: "failed", wantRequests: 1, wantUnavailable: true},
+		{name: "rate limited", statuses: []int{http.StatusTooManyRequests}, wantStatus: "failed", wantRequests: 1, wantPaused: true},
+		{name: "server error retried until MAX_RETRIES", statuses: []int{http.StatusInternalServerError}, wantStatus: "failed", wantRequests: 4},
+		{name: "server error recovers", statuses: []int{http.StatusBadGateway, http.StatusInternalServerError, http.StatusOK}, wantStatus: "completed", wantRequests: 3, wantStored: 1},
+		{name: "client error is not retried", statuses: []int{http.StatusBadRequest}, wantStatus: "failed", wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1))
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "600")
+				}
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(okBody))
+				}
+			}))
+			defer server.Close()
+
+			start := time.Now().UTC()
+			clk := clocktest.NewFake(start)
+			cfg := testConfig(t)
+			cfg.MaxRetries = 3
+			store := storagetest.NewMemory(clk)
+			store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: start.Add(-time.Hour),
+				About: &models.AboutInfo{Available: true, Subscribers: 250000}})
+			ingestion := client.NewIngestionClient(server.URL, 5*time.Second, 0, client.APIVersion1, nil, nil)
+			tm := newTestManager(t, cfg, store, ingestion, &recordingNotifier{}, clk)
+
+			// Let the fetch retries' backoff pass
+			stop := make(chan struct{})
+			defer close(stop)
+			go func() {
+				for {
+					select {
+					case <-stop:
+						return
+					case <-time.After(time.Millisecond):
+						if clk.Waiters() > 0 {
+							clk.Advance(time.Minute)
+						}
+					}
+				}
+			}()
+
+			status, messages := runTask(t, tm, tm.monitorSubreddit, blueberry.TaskParams{
+				"subreddit":       "golang",
+				"limit":           10,
+				"since_timestamp": "",
+				"chunk_size":      0,
+				"dry_run":         false,
+				"force":           false,
+				"keep_cursor":     false,
+			})
+			if status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s; log %v", status, tt.wantStatus, messages)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("ingestion API got %d requests, want %d", got, tt.wantRequests)
+			}
+
+			metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+			if unavailable := !metadata.About.Available; unavailable != tt.wantUnavailable {
+				t.Errorf("subreddit unavailable = %v, want %v", unavailable, tt.wantUnavailable)
+			}
+			if tt.wantUnavailable && metadata.About.Subscribers != 250000 {
+				t.Errorf("about = %+v, want the last known details kept", metadata.About)
+			}
+			if paused := !tm.pause.activeUntil(clk.Now()).IsZero(); paused != tt.wantPaused {
+				t.Errorf("ingestion paused = %v, want %v", paused, tt.wantPaused)
+			}
+			if got := len(store.Posts("golang")); got != tt.wantStored {
+				t.Errorf("stored %d posts, want %d", got, tt.wantStored)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// internal/tasks/filter_preview.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/storage"
+)
+
+// maxPreviewSamples caps the rejected posts listed in a FilterPreview
+const maxPreviewSamples = 25
+
+// Post sources for PreviewFilters
+const (
+	PreviewSourceStored = "stored" // the newest stored posts
+	PreviewSourceFetch  = "fetch"  // a fresh fetch from the ingestion API, not stored
+)
+
+// FilterPreview reports what a candidate config would do to a set of posts
+type FilterPreview struct {
+	Subreddit  string                      `json:"subreddit"`
+	Source     string                      `json:"source"`
+	Posts      int                         `json:"posts"`
+	Kept       int                         `json:"kept"`
+	Rejected   int                         `json:"rejected"`
+	Rejections processor.RejectionSummary  `json:"rejections"`
+	Tags       map[string]int              `json:"tags"` // Kept posts per tag
+	Samples    []FilterPreviewRejectedPost `json:"rejected_samples"`
+}
+
+// FilterPreviewRejectedPost is a sampled rejected post and why it was dropped
+type FilterPreviewRejectedPost struct {
+	RedditID string `json:"reddit_id"`
+	Title    string `json:"title"`
+	Reason   string `json:"reason"`
+}
+
+// PreviewFilters runs up to limit posts through the processor with config in
+// place of the stored one. Nothing is enriched or stored, and the stored
+// config is untouched. ctx should carry the caller's time limit.
+func (tm *SubredditTaskManager) PreviewFilters(ctx context.Context, config models.SubredditConfig, limit int, source string) (FilterPreview, error) {
+	subreddit := config.SubredditName
+
+	var posts []models.IngestionPost
+	switch source {
+	case PreviewSourceStored:
+		stored, err := tm.storage.GetPostsBySubreddit(ctx, subreddit, limit, storage.PostQueryOptions{})
+		if err != nil {
+			return FilterPreview{}, fmt.Errorf("loading stored posts: %w", err)
+		}
+		posts = make([]models.IngestionPost, 0, len(stored))
+		for _, post := range stored {
+			posts = append(posts, processor.IngestionPostOf(post))
+		}
+	case PreviewSourceFetch:
+		if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+			return FilterPreview{}, fmt.Errorf("ingestion is paused until %s; preview stored posts instead", pausedUntil.UTC().Format(time.RFC3339))
+		}
+		fetched, err := tm.client.GetSubredditPosts(ctx, subreddit, limit, 0, 0)
+		if err != nil {
+			return FilterPreview{}, fmt.Errorf("fetching posts: %w", err)
+		}
+		posts = fetched
+	default:
+		return FilterPreview{}, fmt.Errorf("source must be %s or %s", PreviewSourceStored, PreviewSourceFetch)
+	}
+
+	kept, stats := tm.processor.PreviewSubredditPosts(posts, subreddit, &config)
+
+	preview := FilterPreview{
+		Subreddit:  subreddit,
+		Source:     source,
+		Posts:      len(posts),
+		Kept:       len(kept),
+		Rejected:   stats.Rejections.Total(),
+		Rejections: stats.Rejections,
+		Tags:       make(map[string]int),
+		Samples:    []FilterPreviewRejectedPost{},
+	}
+	for _, post := range kept {
+		for _, tag := range post.Tags {
+			preview.Tags[tag]++
+		}
+	}
+
+	titles := make(map[string]string, len(posts))
+	for _, post := range posts {
+		titles[post.ID] = post.Title
+	}
+	for _, rejected := range stats.Rejected {
+		if len(preview.Samples) == maxPreviewSamples {
+			break
+		}
+		preview.Samples = append(preview.Samples, FilterPreviewRejectedPost{
+			RedditID: rejected.RedditID,
+			Title:    titles[rejected.RedditID],
+			Reason:   rejected.Reason,
+		})
+	}
+	return preview, nil
+}
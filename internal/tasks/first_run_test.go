@@ -0,0 +1,140 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestMonitorSubredditFirstRunModes(t *testing.T) {
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cursor := start.Add(-2 * time.Hour)
+	history := []models.IngestionPost{
+		ingestionPost("t3_recent", start.Add(-time.Hour)),
+		ingestionPost("t3_yesterday", start.Add(-12*time.Hour)),
+		ingestionPost("t3_old", start.Add(-10*24*time.Hour)),
+	}
+
+	tests := []struct {
+		name       string
+		config     *models.SubredditConfig
+		cursor     time.Time
+		dryRun     bool
+		wantCalls  int
+		wantSince  time.Time
+		wantStored int
+		wantCursor time.Time
+	}{
+		{
+			name:      "unconfigured subreddit looks back DEFAULT_LOOKBACK_HOURS",
+			wantCalls: 1, wantSince: start.Add(-6 * time.Hour), wantStored: 1, wantCursor: start,
+		},
+		{
+			name:      "last_n_hours",
+			config:    &models.SubredditConfig{FirstRunMode: models.FirstRunLastNHours},
+			wantCalls: 1, wantSince: start.Add(-6 * time.Hour), wantStored: 1, wantCursor: start,
+		},
+		{
+			name:      "full fetches all history",
+			config:    &models.SubredditConfig{FirstRunMode: models.FirstRunFull},
+			wantCalls: 1, wantStored: 3, wantCursor: start,
+		},
+		{
+			name:      "full bounded by max_post_age_days",
+			config:    &models.SubredditConfig{FirstRunMode: models.FirstRunFull, MaxPostAgeDays: 2},
+			wantCalls: 1, wantSince: start.Add(-48 * time.Hour), wantStored: 2, wantCursor: start,
+		},
+		{
+			name:       "skip_history stores nothing and starts the cursor now",
+			config:     &models.SubredditConfig{FirstRunMode: models.FirstRunSkipHistory},
+			wantCursor: start,
+		},
+		{
+			name:   "skip_history dry run writes nothing",
+			config: &models.SubredditConfig{FirstRunMode: models.FirstRunSkipHistory},
+			dryRun: true,
+		},
+		{
+			name:      "runs with a cursor ignore the mode",
+			config:    &models.SubredditConfig{FirstRunMode: models.FirstRunSkipHistory},
+			cursor:    cursor,
+			wantCalls: 1, wantSince: cursor, wantStored: 1, wantCursor: start,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			cfg := testConfig(t)
+			cfg.DefaultLookbackHours = 6
+
+			store := storagetest.NewMemory(clk)
+			if tt.config != nil {
+				tt.config.SubredditName = "golang"
+				tt.config.Enabled = true
+				if err := store.UpsertSubredditConfig(context.Background(), tt.config, "test"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !tt.cursor.IsZero() {
+				store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: tt.cursor})
+			}
+
+			// The fake API honours since like the real one
+			var since int64
+			ingestion := &fakeClient{subreddit: func(_ string, _ int, s, _ int64) ([]models.IngestionPost, error) {
+				since = s
+				var posts []models.IngestionPost
+				for _, post := range history {
+					if post.CreatedAt.Unix() >= s {
+						posts = append(posts, post)
+					}
+				}
+				return posts, nil
+			}}
+			tm := newTestManager(t, cfg, store, ingestion, &recordingNotifier{}, clk)
+
+			status, messages := runTask(t, tm, tm.monitorSubreddit, blueberry.TaskParams{
+				"subreddit":       "golang",
+				"limit":           10,
+				"since_timestamp": "",
+				"chunk_size":      0,
+				"dry_run":         tt.dryRun,
+				"force":           false,
+				"keep_cursor":     false,
+			})
+			if status != "completed" {
+				t.Fatalf("status = %s, log %v", status, messages)
+			}
+
+			if ingestion.calls != tt.wantCalls {
+				t.Fatalf("ingestion called %d times, want %d", ingestion.calls, tt.wantCalls)
+			}
+			var wantSince int64
+			if !tt.wantSince.IsZero() {
+				wantSince = tt.wantSince.Unix()
+			}
+			if tt.wantCalls > 0 && since != wantSince {
+				t.Errorf("fetched since %d, want %d", since, wantSince)
+			}
+			if got := len(store.Posts("golang")); got != tt.wantStored {
+				t.Errorf("stored %d posts, want %d", got, tt.wantStored)
+			}
+
+			metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+			var gotCursor time.Time
+			if metadata != nil {
+				gotCursor = metadata.LastScrapedAt
+			}
+			if !gotCursor.Equal(tt.wantCursor) {
+				t.Errorf("cursor = %v, want %v", gotCursor, tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// internal/tasks/import_tasks.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+)
+
+// maxTopImportCount is the most posts a ranked listing serves
+const maxTopImportCount = 1000
+
+// registerImportTask registers import_top_posts for one-time seeding of a
+// subreddit with its best posts. It has no schedule; runs are triggered from
+// the dashboard.
+func (tm *SubredditTaskManager) registerImportTask() error {
+	schema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"subreddit":  blueberry.TypeString,
+		"count":      blueberry.TypeInt,    // posts to import, at most 1000
+		"time_range": blueberry.TypeString, // all, year or month
+	})
+
+	if _, err := tm.registerTask("import_top_posts", tm.leaderOnly(tm.importTopPosts), schema); err != nil {
+		return fmt.Errorf("failed to register top posts import task: %w", err)
+	}
+	return nil
+}
+
+// importTopPosts pages through a subreddit's top posts of time_range until
+// count posts were fetched and stores them labelled with an import batch.
+// Posts already stored are updated but keep their label, and nothing is
+// added to the rollups, so the import leaves activity stats alone. Each page
+// is stored as it arrives; a failed run keeps what it stored.
+func (tm *SubredditTaskManager) importTopPosts(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+	params := tctx.GetParams()
+
+	subredditName, _ := params["subreddit"].(string)
+	subredditName = strings.TrimSpace(subredditName)
+	if subredditName == "" {
+		return logger.Error("invalid or missing subreddit parameter")
+	}
+	rawCount, _, err := intParam(params, "count")
+	if err != nil {
+		return logger.Error(err.Error())
+	}
+	if rawCount <= 0 || rawCount > maxTopImportCount {
+		return logger.Error(fmt.Sprintf("count must be between 1 and %d", maxTopImportCount))
+	}
+	count := int(rawCount)
+	timeRange, _ := params["time_range"].(string)
+	switch timeRange {
+	case client.TopRangeAll, client.TopRangeYear, client.TopRangeMonth:
+	default:
+		return logger.Error(fmt.Sprintf("time_range must be %s, %s or %s", client.TopRangeAll, client.TopRangeYear, client.TopRangeMonth))
+	}
+
+	if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+		return logger.Error(fmt.Sprintf("Ingestion paused until %s, retry the import then", pausedUntil.UTC().Format(time.RFC3339)))
+	}
+	if pause := tm.maintenancePause(ctx, logger); pause != nil {
+		return logger.Error(fmt.Sprintf("Ingestion paused for maintenance (%s), retry the import after it", describePause(pause)))
+	}
+
+	subredditConfig, err := tm.storage.GetSubredditConfig(ctx, subredditName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit config: %v", err))
+		return err
+	}
+
+	batch := fmt.Sprintf("top-%s-%s", timeRange, tm.clock.Now().UTC().Format("20060102T150405Z"))
+	opts := upsertOptions(subredditConfig)
+	opts.ImportBatch = batch
+	logger.Info(fmt.Sprintf("Importing the top %d posts of r/%s (%s) as import batch %s", count, subredditName, timeRange, batch))
+
+	// The age limit keeps old posts out of monitor runs; a top-of-all-time
+	// import is made of old posts, so it doesn't apply
+	importConfig := subredditConfig
+	if subredditConfig != nil && subredditConfig.MaxPostAgeDays > 0 {
+		copied := *subredditConfig
+		copied.MaxPostAgeDays = 0
+		importConfig = &copied
+	}
+	process := func(ctx context.Context, chunk []models.IngestionPost) ([]models.Post, processor.ProcessStats, error) {
+		processed, stats := tm.processor.ProcessSubredditPosts(chunk, subredditName, importConfig)
+		processed, err := tm.enrichPosts(ctx, processed, importConfig, logger)
+		return processed, stats, err
+	}
+
+	// The first page follows the after cursor, as every page does with
+	// TOP_IMPORT_CONCURRENCY=1. Its size is the API's page size, which
+	// offset paging needs to request the following pages at once. Ranks
+	// shift while we page, so a post may show up on two pages.
+	seen := make(map[string]bool, count)
+	fetched, stored, inserted := 0, 0, 0
+	after := ""
+	offset, pageSize := 0, 0
+	for fetched < count {
+		var pages []*client.TopPostsPage
+		var fetchErr error
+		if pageSize == 0 {
+			var page *client.TopPostsPage
+			page, fetchErr = tm.client.GetTopPosts(ctx, subredditName, timeRange, min(count-fetched, tm.config.MaxPostsCeiling), after)
+			if fetchErr == nil {
+				pages = []*client.TopPostsPage{page}
+			}
+		} else {
+			// On an error, pages holds the ones before the failed page
+			pages, fetchErr = tm.fetchTopPagesAt(ctx, subredditName, timeRange, offset, pageSize, count-fetched)
+		}
+
+		more := fetchErr == nil
+		for _, page := range pages {
+			fresh := make([]models.IngestionPost, 0, len(page.Posts))
+			for _, post := range page.Posts {
+				if id := strings.TrimSpace(post.ID); id != "" && !seen[id] {
+					seen[id] = true
+					fresh = append(fresh, post)
+				}
+			}
+			fetched += len(fresh)
+			offset += len(page.Posts)
+
+			if len(fresh) > 0 {
+				result, err := tm.storeInChunks(ctx, fresh, tm.config.ProcessChunkSize, process, opts, logger)
+				stored += result.Stored
+				inserted += len(result.Inserted)
+				if err != nil {
+					return logger.Error(fmt.Sprintf("Import of r/%s stopped after %d posts (%d new): %v", subredditName, fetched, inserted, err))
+				}
+			}
+
+			if page.After == "" || len(fresh) == 0 {
+				more = false
+				break
+			}
+			after = page.After
+		}
+		if fetchErr != nil {
+			tm.pauseOnRateLimit(fetchErr, logger, "")
+			return logger.Error(fmt.Sprintf("Import of r/%s stopped after %d posts (%d new): %v", subredditName, fetched, inserted, fetchErr))
+		}
+		if !more {
+			break
+		}
+		if tm.config.TopImportConcurrency > 1 && pageSize == 0 {
+			pageSize = len(pages[0].Posts)
+		}
+	}
+
+	logger.Success(fmt.Sprintf("Imported r/%s top %s: %d posts fetched, %d stored, %d new, %d already present (import_batch %s)",
+		subredditName, timeRange, fetched, stored, inserted, stored-inserted, batch))
+	return nil
+}
+
+// fetchTopPagesAt requests up to TOP_IMPORT_CONCURRENCY pages of pageSize
+// top posts from offset at once, covering at most remaining posts, and
+// returns them in rank order. A failed request cancels those of the pages
+// after it; the pages before it are returned with its error.
+func (tm *SubredditTaskManager) fetchTopPagesAt(ctx context.Context, subreddit, timeRange string, offset, pageSize, remaining int) ([]*client.TopPostsPage, error) {
+	pages := make([]*client.TopPostsPage, min(tm.config.TopImportConcurrency, (remaining+pageSize-1)/pageSize))
+	errs := make([]error, len(pages))
+	cancels := make([]context.CancelFunc, len(pages))
+	contexts := make([]context.Context, len(pages))
+	for i := range pages {
+		contexts[i], cancels[i] = context.WithCancel(ctx)
+		defer cancels[i]()
+	}
+
+	var requests sync.WaitGroup
+	for i := range pages {
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			pages[i], errs[i] = tm.client.GetTopPostsAt(contexts[i], subreddit, timeRange, min(pageSize, remaining-i*pageSize), offset+i*pageSize)
+			if errs[i] != nil {
+				for _, cancel := range cancels[i+1:] {
+					cancel()
+				}
+			}
+		}()
+	}
+	requests.Wait()
+
+	// A cancelled page comes after the one that failed
+	for i, err := range errs {
+		if err != nil {
+			return pages[:i], err
+		}
+	}
+	return pages, nil
+}
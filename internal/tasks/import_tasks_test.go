@@ -0,0 +1,166 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestImportTopPostsIgnoresAgeLimit(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clk := clocktest.NewFake(start)
+	store := storagetest.NewMemory(clk)
+	if err := store.UpsertSubredditConfig(context.Background(), &models.SubredditConfig{SubredditName: "golang", Enabled: true, MaxPostAgeDays: 7}, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	ingestion := &fakeClient{top: func(string, string, int, string) (*client.TopPostsPage, error) {
+		return &client.TopPostsPage{Posts: []models.IngestionPost{
+			ingestionPost("t3_old", start.AddDate(-3, 0, 0)),
+			ingestionPost("t3_new", start.Add(-time.Hour)),
+		}}, nil
+	}}
+	tm := newTestManager(t, testConfig(t), store, ingestion, &recordingNotifier{}, clk)
+
+	status, messages := runTask(t, tm, tm.importTopPosts, blueberry.TaskParams{"subreddit": "golang", "count": 10, "time_range": client.TopRangeAll})
+	if status != "completed" {
+		t.Fatalf("status = %s, log %v", status, messages)
+	}
+	stored := make(map[string]bool)
+	for _, post := range store.Posts("golang") {
+		stored[post.RedditID] = true
+	}
+	if !stored["t3_old"] || !stored["t3_new"] {
+		t.Errorf("stored %v, want the three-year-old post imported despite max_post_age_days", stored)
+	}
+}
+
+// topServer is a paginated /subreddit/top serving total ranked posts, at
+// most 100 a page, by after cursor or offset. A request for offset failAt
+// answers 500.
+type topServer struct {
+	total  int
+	failAt int
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	byOffset    int
+}
+
+func (s *topServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inFlight++
+	s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	if r.URL.Query().Has("offset") {
+		s.byOffset++
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	// Long enough for requests sent together to overlap
+	time.Sleep(20 * time.Millisecond)
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	limit = min(limit, 100)
+	offset := 0
+	for _, key := range []string{"after", "offset"} {
+		if value := query.Get(key); value != "" {
+			offset, _ = strconv.Atoi(value)
+		}
+	}
+	if offset == s.failAt {
+		http.Error(w, "boom", http.StatusInternalServerError)
+		return
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	posts := []models.IngestionPost{}
+	for i := offset; i < min(offset+limit, s.total); i++ {
+		posts = append(posts, ingestionPost(fmt.Sprintf("t3_%03d", i), start.Add(-time.Duration(i)*time.Hour)))
+	}
+	meta := map[string]interface{}{}
+	if offset+len(posts) < s.total {
+		meta["after"] = strconv.Itoa(offset + len(posts))
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"posts": posts, "meta": meta})
+}
+
+// importFrom runs import_top_posts of count posts against server with
+// concurrency pages at once and returns the stored ids and the run's log
+func importFrom(t *testing.T, server *topServer, count, concurrency int) ([]string, []string) {
+	t.Helper()
+	api := httptest.NewServer(server)
+	t.Cleanup(api.Close)
+
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	cfg := testConfig(t)
+	cfg.TopImportConcurrency = concurrency
+	store := storagetest.NewMemory(clk)
+	ingestion := client.NewIngestionClient(api.URL, 5*time.Second, 0, client.APIVersion1, nil, nil)
+	tm := newTestManager(t, cfg, store, ingestion, &recordingNotifier{}, clk)
+
+	_, messages := runTask(t, tm, tm.importTopPosts, blueberry.TaskParams{"subreddit": "golang", "count": count, "time_range": client.TopRangeAll})
+	var ids []string
+	for _, post := range store.Posts("golang") {
+		ids = append(ids, post.RedditID)
+	}
+	slices.Sort(ids)
+	return ids, messages
+}
+
+func TestImportTopPostsParallelPagesMatchSerial(t *testing.T) {
+	for _, count := range []int{50, 150, 230, 1000} {
+		t.Run(strconv.Itoa(count), func(t *testing.T) {
+			serialServer := &topServer{total: 230, failAt: -1}
+			serial, serialLog := importFrom(t, serialServer, count, 1)
+			parallelServer := &topServer{total: 230, failAt: -1}
+			parallel, parallelLog := importFrom(t, parallelServer, count, 3)
+
+			if len(serial) != min(count, 230) {
+				t.Fatalf("serial import stored %d posts, want %d; log %v", len(serial), min(count, 230), serialLog)
+			}
+			if !slices.Equal(parallel, serial) {
+				t.Errorf("parallel import stored %v, want %v as the serial one; log %v", parallel, serial, parallelLog)
+			}
+			if serialServer.byOffset != 0 {
+				t.Errorf("serial import made %d offset requests, want none", serialServer.byOffset)
+			}
+			if count > 200 && (parallelServer.maxInFlight < 2 || parallelServer.maxInFlight > 3) {
+				t.Errorf("parallel import had %d requests in flight, want 2 or 3", parallelServer.maxInFlight)
+			}
+		})
+	}
+}
+
+func TestImportTopPostsParallelPageFailure(t *testing.T) {
+	server := &topServer{total: 500, failAt: 200}
+	ids, messages := importFrom(t, server, 500, 2)
+
+	// The page before the failed one is stored, the pages after it are not
+	// requested
+	if len(ids) != 200 {
+		t.Errorf("stored %d posts, want the 200 before the failed page", len(ids))
+	}
+	if !slices.ContainsFunc(messages, func(message string) bool { return strings.Contains(message, "stopped after 200 posts") }) {
+		t.Errorf("log %v, want the import stopped after 200 posts", messages)
+	}
+}
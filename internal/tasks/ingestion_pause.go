@@ -0,0 +1,71 @@
+// internal/tasks/ingestion_pause.go
+package tasks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/client"
+)
+
+// defaultRateLimitPause is used when a 429/503 carries no usable Retry-After
+const defaultRateLimitPause = time.Minute
+
+// ingestionPause is a global "ingestion paused until" deadline shared by all runs
+type ingestionPause struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// extend pauses ingestion until resumeAt, capped at maxPause from now. An
+// existing longer pause is kept. Returns the effective deadline.
+func (p *ingestionPause) extend(now, resumeAt time.Time, maxPause time.Duration) time.Time {
+	if resumeAt.IsZero() || !resumeAt.After(now) {
+		resumeAt = now.Add(defaultRateLimitPause)
+	}
+	if maxPause > 0 && resumeAt.Sub(now) > maxPause {
+		resumeAt = now.Add(maxPause)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if resumeAt.After(p.until) {
+		p.until = resumeAt
+	}
+	return p.until
+}
+
+// activeUntil returns the pause deadline, or zero once it has expired at now
+func (p *ingestionPause) activeUntil(now time.Time) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.until.IsZero() {
+		return time.Time{}
+	}
+	if !now.Before(p.until) {
+		p.until = time.Time{}
+		return time.Time{}
+	}
+	return p.until
+}
+
+// pauseOnRateLimit extends the shared ingestion pause when err is a 429/503
+// from the ingestion API and logs the deadline, with progress appended when
+// non-empty. It reports whether err was a rate limit.
+func (tm *SubredditTaskManager) pauseOnRateLimit(err error, logger runLogger, progress string) bool {
+	rateLimited, ok := client.AsRateLimited(err)
+	if !ok {
+		return false
+	}
+	pausedUntil := tm.pause.extend(tm.clock.Now(), rateLimited.ResumeAt, tm.config.MaxIngestionPause)
+	message := fmt.Sprintf("Ingestion API rate limited (status %d), pausing all runs until %s",
+		rateLimited.StatusCode, pausedUntil.UTC().Format(time.RFC3339))
+	if progress != "" {
+		message += "; " + progress
+	}
+	logger.Error(message)
+	return true
+}
@@ -0,0 +1,67 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/config"
+)
+
+func TestPauseOnRateLimit(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		err       error
+		wantPause bool
+		wantUntil time.Time
+	}{
+		{name: "other error", err: errors.New("connection refused")},
+		{name: "not found", err: &client.APIError{StatusCode: 404}},
+		{
+			name:      "retry after",
+			err:       &client.RateLimitedError{StatusCode: 429, ResumeAt: start.Add(5 * time.Minute)},
+			wantPause: true,
+			wantUntil: start.Add(5 * time.Minute),
+		},
+		{
+			name:      "wrapped without retry after",
+			err:       fmt.Errorf("fetch: %w", &client.RateLimitedError{StatusCode: 503}),
+			wantPause: true,
+			wantUntil: start.Add(defaultRateLimitPause),
+		},
+		{
+			name:      "capped at max pause",
+			err:       &client.RateLimitedError{StatusCode: 429, ResumeAt: start.Add(24 * time.Hour)},
+			wantPause: true,
+			wantUntil: start.Add(time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := &SubredditTaskManager{
+				config: &config.Config{MaxIngestionPause: time.Hour},
+				clock:  clocktest.NewFake(start),
+			}
+			logger := &recordingLogger{}
+
+			if got := tm.pauseOnRateLimit(tt.err, logger, "run for u/someone"); got != tt.wantPause {
+				t.Fatalf("pauseOnRateLimit() = %v, want %v", got, tt.wantPause)
+			}
+			if got := tm.pause.activeUntil(start); !got.Equal(tt.wantUntil) {
+				t.Errorf("paused until %v, want %v", got, tt.wantUntil)
+			}
+			if tt.wantPause && len(logger.messages) != 1 {
+				t.Errorf("logged %v, want one message", logger.messages)
+			}
+			if !tt.wantPause && len(logger.messages) != 0 {
+				t.Errorf("logged %v, want nothing", logger.messages)
+			}
+		})
+	}
+}
@@ -1,6 +1,152 @@
 // internal/tasks/interface.go
 package tasks
 
+import (
+	"context"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/storage"
+)
+
 type TaskManagerInterface interface {
 	RegisterTasks() error
-}
\ No newline at end of file
+	ScheduleSubreddits(ctx context.Context) error
+	CatchUp(ctx context.Context, afterUncleanShutdown bool) (int, error)
+	QueueSnapshot() QueueSnapshot
+	ScheduleReport() ScheduleReport
+	EffectiveSchedule(config models.SubredditConfig) (string, string)
+	CommitStagedConfigs(ctx context.Context, actor string) (ConfigCommit, error)
+	ReconcileSchedules(ctx context.Context) (ScheduleReconciliation, error)
+	WatchdogStatus() WatchdogStatus
+	RunWatchdog(ctx context.Context, interval time.Duration)
+	Drain(ctx context.Context) (int, error)
+	ReplayWebhookDelivery(ctx context.Context, original models.WebhookDelivery) (*models.WebhookDelivery, error)
+	NotificationChannels() []notify.ChannelStatus
+	TestNotificationChannel(ctx context.Context, channel string) (notify.ChannelStatus, error)
+	IngestPushed(ctx context.Context, subredditName string, posts []models.IngestionPost) (models.RunStats, error)
+	PreviewFilters(ctx context.Context, config models.SubredditConfig, limit int, source string) (FilterPreview, error)
+	ValidatePreset(preset models.RunPreset) error
+	RunPreset(preset models.RunPreset, values map[string]string) (PresetRun, error)
+}
+
+// TaskStorage is the part of storage the task manager uses; health checks and
+// diagnostics stay with the app and API. It is assembled from what each task
+// needs, so a task's storage dependencies can be read off its interface.
+type TaskStorage interface {
+	ScrapeStorage
+	UserScrapeStorage
+	ScheduleStorage
+	ConfigCommitStorage
+	NotificationRuleStorage
+	DigestStorage
+	AnomalyTaskStorage
+	SubredditInfoStorage
+	HousekeepingStorage
+}
+
+// Ensure every storage backend can run the tasks
+var _ TaskStorage = (storage.StorageInterface)(nil)
+
+// ScrapeStorage is used by subreddit runs (scheduled, pushed, imported and
+// repaired) and their bookkeeping: staleness, cursor probes, rollups, QA
+// samples and the maintenance pause
+type ScrapeStorage interface {
+	GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+	GetSubredditConfig(ctx context.Context, subredditName string) (*models.SubredditConfig, error)
+	GetSubredditMetadata(ctx context.Context, subredditName string) (*models.SubredditMetadata, error)
+	GetExistingRedditIDs(ctx context.Context, redditIDs []string) (map[string]bool, error)
+	GetPostsBySubreddit(ctx context.Context, subreddit string, limit int, opts storage.PostQueryOptions) ([]models.Post, error)
+	UpsertPosts(ctx context.Context, posts []models.Post, opts storage.UpsertOptions) (storage.UpsertResult, error)
+	UpdateLastScraped(ctx context.Context, subredditName string, scrapedAt time.Time, stats models.RunStats) error
+	UpdateZeroPostRuns(ctx context.Context, subredditName string, postsFetched int) (int, error)
+	SetBestRecentPost(ctx context.Context, subredditName string, best models.BestRecentPost) error
+	SetSubredditStale(ctx context.Context, subredditName string, stale bool) error
+	RecordRunOutcome(ctx context.Context, subredditName string, failed bool) error
+	RecordCursorProbe(ctx context.Context, subredditName string, at time.Time) error
+	CorrectScrapeCursor(ctx context.Context, subredditName string, correction models.CursorCorrection) (bool, error)
+	IncrementRollups(ctx context.Context, posts []models.Post) error
+	InsertQASamples(ctx context.Context, samples []models.QASample) error
+	GetMaintenancePause(ctx context.Context) (*models.MaintenancePause, error)
+}
+
+// UserScrapeStorage is used by user runs
+type UserScrapeStorage interface {
+	GetActiveUserConfigs(ctx context.Context) ([]models.UserConfig, error)
+	GetUserConfig(ctx context.Context, username string) (*models.UserConfig, error)
+	GetUserMetadata(ctx context.Context, username string) (*models.UserMetadata, error)
+	UpsertPosts(ctx context.Context, posts []models.Post, opts storage.UpsertOptions) (storage.UpsertResult, error)
+	UpdateUserLastScraped(ctx context.Context, username string, scrapedAt time.Time, stats models.RunStats) error
+}
+
+// ScheduleStorage is used to build the schedule: catch-up, adaptive
+// intervals and priority suggestions
+type ScheduleStorage interface {
+	GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+	GetSubredditMetadataByNames(ctx context.Context, names []string) (map[string]models.SubredditMetadata, error)
+	GetRollups(ctx context.Context, subreddit string, from, to time.Time) ([]models.PostRollup, error)
+	GetRollupsForSubreddits(ctx context.Context, subreddits []string, from, to time.Time) ([]models.PostRollup, error)
+	SetSubredditActivity(ctx context.Context, subredditName string, profile models.ActivityProfile) error
+	SetPrioritySuggestion(ctx context.Context, subredditName string, suggestion models.PrioritySuggestion, actor string) (bool, error)
+}
+
+// ConfigCommitStorage is used to apply staged config changes
+type ConfigCommitStorage interface {
+	GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+	GetAllSubredditConfigs(ctx context.Context, filter storage.ConfigFilter, opts storage.ListOptions) (storage.ConfigPage, error)
+	GetStagedConfigChanges(ctx context.Context) ([]models.StagedConfigChange, error)
+	UnstageConfigChange(ctx context.Context, subredditName string) error
+	UpsertSubredditConfig(ctx context.Context, config *models.SubredditConfig, actor string) error
+	DeleteSubredditConfig(ctx context.Context, subredditName, actor string) (bool, error)
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NotificationRuleStorage is used to fire notification rules and replay
+// their webhook deliveries
+type NotificationRuleStorage interface {
+	GetActiveNotificationRules(ctx context.Context) ([]models.NotificationRule, error)
+	GetNotificationRule(ctx context.Context, name string) (*models.NotificationRule, error)
+	ClaimNotificationRule(ctx context.Context, name string, now time.Time, cooldown time.Duration) (bool, error)
+	ReleaseNotificationRule(ctx context.Context, name string, claimedAt time.Time) error
+	InsertNotificationLogs(ctx context.Context, entries []models.NotificationLog) error
+	InsertWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetPostByRedditID(ctx context.Context, redditID string) (*models.Post, error)
+}
+
+// DigestStorage is used to send digests
+type DigestStorage interface {
+	GetAllDigests(ctx context.Context) ([]models.Digest, error)
+	GetDigest(ctx context.Context, label string) (*models.Digest, error)
+	GetSubredditConfigsByLabel(ctx context.Context, label string, opts storage.ListOptions) ([]models.SubredditConfig, error)
+	FindPosts(ctx context.Context, filter storage.PostFilter) (storage.PostPage, error)
+	MarkDigestSent(ctx context.Context, label string, sentAt time.Time) error
+}
+
+// AnomalyTaskStorage is used by the author burst detector
+type AnomalyTaskStorage interface {
+	GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+	FindAuthorBursts(ctx context.Context, subreddit string, since time.Time, window time.Duration, minPosts int) ([]models.Anomaly, error)
+	UpsertAnomaly(ctx context.Context, anomaly *models.Anomaly) error
+	AddTagToAuthorPosts(ctx context.Context, subreddit, author string, from, to time.Time, tag string) error
+}
+
+// SubredditInfoStorage is used to refresh subreddit about info
+type SubredditInfoStorage interface {
+	GetAllSubredditConfigs(ctx context.Context, filter storage.ConfigFilter, opts storage.ListOptions) (storage.ConfigPage, error)
+	GetSubredditMetadata(ctx context.Context, subredditName string) (*models.SubredditMetadata, error)
+	SetSubredditAbout(ctx context.Context, subredditName string, about models.AboutInfo) error
+}
+
+// HousekeepingStorage is used by cleanup, removal scans, document audits and
+// reports over stored posts
+type HousekeepingStorage interface {
+	GetActiveSubredditConfigs(ctx context.Context) ([]models.SubredditConfig, error)
+	PurgeDeletedSubredditConfigs(ctx context.Context, deletedBefore time.Time, actor string) (int, error)
+	DropPostPartitionsBefore(ctx context.Context, before time.Time) ([]string, error)
+	ReconcilePostPresence(ctx context.Context, subreddit string, window storage.TimeRange, seen []string, missesToRemove int) (models.PresenceScan, error)
+	FindOversizedPosts(ctx context.Context, minBytes, limit int, timeout time.Duration) ([]models.OversizedPost, error)
+	TrimPostHistory(ctx context.Context, collection string, post models.OversizedPost, keep int) (int, error)
+	GetDuplicateURLReport(ctx context.Context, since time.Time, minSubreddits int) ([]models.DuplicateURL, error)
+	RefreshSubredditStorageStats(ctx context.Context, opts storage.StorageStatsOptions) ([]models.SubredditStorageStats, error)
+}
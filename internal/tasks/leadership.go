@@ -0,0 +1,29 @@
+// internal/tasks/leadership.go
+package tasks
+
+import (
+	"log"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+)
+
+// leaderOnly wraps a scheduled task so it only runs on the leader. In HA mode
+// a follower's cron may still fire after it lost leadership (BlueBerry cannot
+// stop it), and dashboard triggers on a follower land here too. Every run,
+// skipped or not, is reported to the watchdog.
+func (tm *SubredditTaskManager) leaderOnly(run blueberry.TaskFunc) blueberry.TaskFunc {
+	return func(tctx *blueberry.TaskContext) error {
+		defer func() {
+			if tm.watchdog.runCompleted(tm.clock.Now()) {
+				log.Printf("Scheduler watchdog: task runs resumed")
+			}
+		}()
+		if !tm.elector.IsLeader() {
+			status := tm.elector.Status()
+			tctx.GetLogger().Infof("Skipped: instance %s is not the leader (leader: %s); trigger runs on the leader",
+				status.InstanceID, status.LeaderID)
+			return nil
+		}
+		return run(tctx)
+	}
+}
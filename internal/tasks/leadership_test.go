@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/leader"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+// switchElector leads while leading is set
+type switchElector struct {
+	leading atomic.Bool
+}
+
+func (e *switchElector) Run(ctx context.Context) {}
+func (e *switchElector) OnElected(fn func())     {}
+func (e *switchElector) IsLeader() bool          { return e.leading.Load() }
+
+func (e *switchElector) Status() leader.Status {
+	status := leader.Status{HAMode: true, InstanceID: "b", Role: leader.RoleFollower, LeaderID: "a"}
+	if e.IsLeader() {
+		status.Role, status.LeaderID = leader.RoleLeader, "b"
+	}
+	return status
+}
+
+func TestLeaderOnlySkipsRunsOnFollowers(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	tm := newTestManager(t, testConfig(t), storagetest.NewMemory(clk), &fakeClient{}, &recordingNotifier{}, clk)
+	elector := &switchElector{}
+	tm.elector = elector
+
+	var runs atomic.Int32
+	task := tm.leaderOnly(func(*blueberry.TaskContext) error {
+		runs.Add(1)
+		return nil
+	})
+
+	status, messages := runTask(t, tm, task, blueberry.TaskParams{})
+	if status != "completed" || runs.Load() != 0 {
+		t.Fatalf("follower run: status %s, %d runs, log %v; want it skipped", status, runs.Load(), messages)
+	}
+	if !strings.Contains(strings.Join(messages, "\n"), "not the leader (leader: a)") {
+		t.Errorf("follower log %v does not name the leader", messages)
+	}
+
+	// After a takeover the same schedule runs
+	elector.leading.Store(true)
+	if status, messages := runTask(t, tm, task, blueberry.TaskParams{}); status != "completed" || runs.Load() != 1 {
+		t.Fatalf("leader run: status %s, %d runs, log %v; want one run", status, runs.Load(), messages)
+	}
+}
@@ -0,0 +1,78 @@
+// internal/tasks/maintenance_pause.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// maintenanceNotices remembers which subreddits and users were told about
+// the current maintenance pause, so their skipped runs are logged once per
+// pause instead of on every run
+type maintenanceNotices struct {
+	mu   sync.Mutex
+	told map[string]time.Time // name -> PausedAt of the pause last logged
+}
+
+// first reports whether name has not been told about the pause that started
+// at pausedAt yet, and marks it told
+func (n *maintenanceNotices) first(name string, pausedAt time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.told == nil {
+		n.told = make(map[string]time.Time)
+	}
+	if n.told[name].Equal(pausedAt) {
+		return false
+	}
+	n.told[name] = pausedAt
+	return true
+}
+
+// maintenancePause returns the active maintenance pause, or nil. A pause
+// that can't be read is logged and treated as none, so a storage hiccup
+// does not stop scraping.
+func (tm *SubredditTaskManager) maintenancePause(ctx context.Context, logger runLogger) *models.MaintenancePause {
+	pause, err := tm.storage.GetMaintenancePause(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to read the maintenance pause, running anyway: %v", err))
+		return nil
+	}
+	return pause
+}
+
+// skipForMaintenance reports whether a monitor run for name is skipped by
+// the maintenance pause. Forced runs go ahead. The skip is logged on the
+// first run per name of each pause only.
+func (tm *SubredditTaskManager) skipForMaintenance(ctx context.Context, name string, force bool, logger runLogger) bool {
+	pause := tm.maintenancePause(ctx, logger)
+	if pause == nil {
+		return false
+	}
+	if force {
+		logger.Info(fmt.Sprintf("Ingestion is paused for maintenance (%s), running %s anyway: force is set", describePause(pause), name))
+		return false
+	}
+	if tm.maintenance.first(name, pause.PausedAt) {
+		logger.Info(fmt.Sprintf("Ingestion is paused for maintenance (%s), skipping %s; later runs are skipped without logging, set force to run anyway",
+			describePause(pause), name))
+	}
+	return true
+}
+
+// describePause summarizes a maintenance pause for run logs
+func describePause(pause *models.MaintenancePause) string {
+	until := "until resumed"
+	if pause.Until != nil {
+		until = "until " + pause.Until.Format(time.RFC3339)
+	}
+	if pause.Reason == "" {
+		return until
+	}
+	return fmt.Sprintf("%s: %s", until, pause.Reason)
+}
@@ -0,0 +1,55 @@
+// internal/tasks/manual_scrape.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ManualScrape describes a one-off monitor_subreddit run. Zero Limit uses the
+// config's max_posts and zero SinceTimestamp resumes from the cursor.
+type ManualScrape struct {
+	Subreddit      string
+	Limit          int
+	SinceTimestamp int64
+	// KeepCursor leaves last_scraped_at as is; set it for bounded scrapes,
+	// which may skip posts between the cursor and their window
+	KeepCursor bool
+	// Force runs during a maintenance pause
+	Force bool
+}
+
+// ScrapeNow starts a one-off monitor_subreddit run the way catch-up does, so
+// it waits for the run queue and respects the ingestion and maintenance
+// pauses like a scheduled run. Returns the BlueBerry execution ID.
+func (tm *SubredditTaskManager) ScrapeNow(ctx context.Context, scrape ManualScrape) (int, error) {
+	if tm.monitorTask == nil {
+		return 0, fmt.Errorf("monitor_subreddit task is not registered")
+	}
+
+	config, err := tm.storage.GetSubredditConfig(ctx, scrape.Subreddit)
+	if err != nil {
+		return 0, err
+	}
+	if config == nil {
+		config = &models.SubredditConfig{SubredditName: scrape.Subreddit}
+	}
+
+	params := tm.scheduleParams(*config)
+	if scrape.Limit > 0 {
+		params["limit"] = scrape.Limit
+	}
+	if scrape.SinceTimestamp > 0 {
+		params["since_timestamp"] = strconv.FormatInt(scrape.SinceTimestamp, 10)
+	}
+	params["keep_cursor"] = scrape.KeepCursor
+	params["force"] = scrape.Force
+	if err := tm.checkMonitorParams(params, "subreddit"); err != nil {
+		return 0, err
+	}
+
+	return tm.monitorTask.ExecuteNow(params)
+}
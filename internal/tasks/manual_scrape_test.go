@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/leader"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestMonitorSubredditKeepCursor(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cursor := start.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name       string
+		keepCursor bool
+		posts      []models.IngestionPost
+		wantCursor time.Time
+	}{
+		{name: "bounded scrape keeps the cursor", keepCursor: true, posts: []models.IngestionPost{ingestionPost("t3_a", start.Add(-5*time.Hour))}, wantCursor: cursor},
+		{name: "bounded scrape without posts keeps the cursor", keepCursor: true, wantCursor: cursor},
+		{name: "regular run advances the cursor", posts: []models.IngestionPost{ingestionPost("t3_a", start.Add(-5*time.Hour))}, wantCursor: start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			store := storagetest.NewMemory(clk)
+			store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: cursor})
+			ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+				return tt.posts, nil
+			}}
+			tm := newTestManager(t, testConfig(t), store, ingestion, &recordingNotifier{}, clk)
+
+			status, messages := runTask(t, tm, tm.monitorSubreddit, blueberry.TaskParams{
+				"subreddit":       "golang",
+				"limit":           10,
+				"since_timestamp": strconv.FormatInt(start.Add(-6*time.Hour).Unix(), 10),
+				"chunk_size":      0,
+				"dry_run":         false,
+				"force":           false,
+				"keep_cursor":     tt.keepCursor,
+			})
+			if status != "completed" {
+				t.Fatalf("status = %s, log %v", status, messages)
+			}
+
+			if got := len(store.Posts("golang")); got != len(tt.posts) {
+				t.Errorf("stored %d posts, want %d", got, len(tt.posts))
+			}
+			metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+			if !metadata.LastScrapedAt.Equal(tt.wantCursor) {
+				t.Errorf("LastScrapedAt = %v, want %v", metadata.LastScrapedAt, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestScrapeNowRespectsMaintenancePause(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		force     bool
+		wantCalls int
+	}{
+		{name: "paused", wantCalls: 0},
+		{name: "forced", force: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			store := storagetest.NewMemory(clk)
+			if err := store.SetMaintenancePause(context.Background(), &models.MaintenancePause{Reason: "upgrade", PausedAt: start}); err != nil {
+				t.Fatalf("SetMaintenancePause() error = %v", err)
+			}
+			ingestion := &fakeClient{}
+			tm := NewSubredditTaskManager(newTestBlueBerry(t), store, ingestion, processor.NewProcessor(nil, nil, clk),
+				nil, &recordingNotifier{}, nil, leader.NewStatic("test"), testConfig(t), clk)
+			if err := tm.RegisterTasks(); err != nil {
+				t.Fatalf("RegisterTasks() error = %v", err)
+			}
+
+			id, err := tm.ScrapeNow(context.Background(), ManualScrape{Subreddit: "golang", Limit: 5, KeepCursor: true, Force: tt.force})
+			if err != nil {
+				t.Fatalf("ScrapeNow() error = %v", err)
+			}
+			if status, messages := waitForRun(t, id); status != "completed" {
+				t.Fatalf("status = %s, log %v", status, messages)
+			}
+			if ingestion.calls != tt.wantCalls {
+				t.Errorf("ingestion called %d times, want %d", ingestion.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestScrapeNowRejectsInvalidLimit(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := storagetest.NewMemory(clk)
+	cfg := testConfig(t)
+	tm := NewSubredditTaskManager(newTestBlueBerry(t), store, &fakeClient{}, processor.NewProcessor(nil, nil, clk),
+		nil, &recordingNotifier{}, nil, leader.NewStatic("test"), cfg, clk)
+	if err := tm.RegisterTasks(); err != nil {
+		t.Fatalf("RegisterTasks() error = %v", err)
+	}
+
+	if _, err := tm.ScrapeNow(context.Background(), ManualScrape{Subreddit: "golang", Limit: cfg.MaxPostsCeiling + 1}); err == nil {
+		t.Fatal("ScrapeNow() accepted a limit above MAX_POSTS_CEILING")
+	}
+}
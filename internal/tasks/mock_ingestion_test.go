@@ -0,0 +1,160 @@
+package tasks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+// startMockIngestion builds cmd/mock-ingestion, runs it on a free port and
+// returns its URL once it answers /health
+func startMockIngestion(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds and runs cmd/mock-ingestion")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not on PATH")
+	}
+
+	bin := filepath.Join(t.TempDir(), "mock-ingestion")
+	if out, err := exec.Command("go", "build", "-o", bin, "reddit-orchestrator/cmd/mock-ingestion").CombinedOutput(); err != nil {
+		t.Fatalf("building cmd/mock-ingestion: %v\n%s", err, out)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cmd := exec.Command(bin)
+	cmd.Env = append(os.Environ(), "MOCK_ADDR="+addr, "MOCK_ERROR_RATE=0", "MOCK_RATE_LIMIT_RATE=0", "MOCK_LATENCY=0s")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting cmd/mock-ingestion: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Signal(os.Interrupt)
+		cmd.Wait()
+	})
+
+	url := "http://" + addr
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(url + "/health")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return url
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("cmd/mock-ingestion not healthy on %s: %v", addr, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestMonitorSubredditAgainstMock runs full monitor_subreddit cycles against
+// cmd/mock-ingestion with the in-memory storage
+func TestMonitorSubredditAgainstMock(t *testing.T) {
+	url := startMockIngestion(t)
+	params := func(subreddit string) blueberry.TaskParams {
+		return blueberry.TaskParams{
+			"subreddit":       subreddit,
+			"limit":           100,
+			"since_timestamp": "",
+			"chunk_size":      0,
+			"dry_run":         false,
+			"force":           false,
+			"keep_cursor":     false,
+		}
+	}
+
+	t.Run("first run and the run after it", func(t *testing.T) {
+		// The mock's timeline follows the system clock, so the fake one
+		// starts at the current time
+		start := time.Now().UTC()
+		clk := clocktest.NewFake(start)
+		cfg := testConfig(t)
+		cfg.DefaultLookbackHours = 6
+		store := storagetest.NewMemory(clk)
+		ingestion := client.NewIngestionClient(url, 5*time.Second, 0, client.APIVersion1, nil, nil)
+		tm := newTestManager(t, cfg, store, ingestion, &recordingNotifier{}, clk)
+
+		if status, messages := runTask(t, tm, tm.monitorSubreddit, params("golang")); status != "completed" {
+			t.Fatalf("first run %s, log %v", status, messages)
+		}
+		// About one post every ten minutes, fewer than the limit
+		first := store.Posts("golang")
+		if len(first) == 0 || len(first) == 100 {
+			t.Fatalf("first run stored %d posts, want the 6 hours' worth", len(first))
+		}
+		lookback := start.Add(-6 * time.Hour).Truncate(time.Minute)
+		// The mock serves posts up to a second past its own clock
+		for _, post := range first {
+			if post.CreatedAt.Before(lookback) || post.CreatedAt.After(time.Now().Add(time.Second)) {
+				t.Errorf("stored %s created %v, want within the lookback from %v", post.RedditID, post.CreatedAt, lookback)
+			}
+		}
+		metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+		if metadata == nil || !metadata.LastScrapedAt.Equal(start) {
+			t.Fatalf("metadata %+v, want the cursor at %v", metadata, start)
+		}
+
+		// The second run fetches from the cursor: it may pick up a post
+		// published since, but stores nothing twice and misses nothing older
+		if status, messages := runTask(t, tm, tm.monitorSubreddit, params("golang")); status != "completed" {
+			t.Fatalf("second run %s, log %v", status, messages)
+		}
+		firstIDs := make(map[string]bool, len(first))
+		for _, post := range first {
+			firstIDs[post.RedditID] = true
+		}
+		seen := make(map[string]bool)
+		for _, post := range store.Posts("golang") {
+			if seen[post.RedditID] {
+				t.Errorf("%s stored twice", post.RedditID)
+			}
+			seen[post.RedditID] = true
+			if !firstIDs[post.RedditID] && !post.CreatedAt.After(start) {
+				t.Errorf("%s created %v, before the first run, but only stored by the second", post.RedditID, post.CreatedAt)
+			}
+		}
+		for _, post := range first {
+			if !seen[post.RedditID] {
+				t.Errorf("%s from the first run missing after the second", post.RedditID)
+			}
+		}
+	})
+
+	t.Run("missing subreddit", func(t *testing.T) {
+		clk := clocktest.NewFake(time.Now().UTC())
+		store := storagetest.NewMemory(clk)
+		store.SetMetadata(models.SubredditMetadata{SubredditName: "missing_golang", LastScrapedAt: clk.Now().Add(-time.Hour),
+			About: &models.AboutInfo{Available: true}})
+		ingestion := client.NewIngestionClient(url, 5*time.Second, 0, client.APIVersion1, nil, nil)
+		tm := newTestManager(t, testConfig(t), store, ingestion, &recordingNotifier{}, clk)
+
+		if status, messages := runTask(t, tm, tm.monitorSubreddit, params("missing_golang")); status != "failed" {
+			t.Fatalf("status = %s, want failed; log %v", status, messages)
+		}
+		metadata, _ := store.GetSubredditMetadata(context.Background(), "missing_golang")
+		if metadata.About.Available {
+			t.Error("subreddit still available after the mock answered 404")
+		}
+	})
+}
@@ -0,0 +1,207 @@
+// internal/tasks/notification_rules.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+)
+
+// evaluateNotificationRules matches active rules against the posts a run
+// inserted. Each rule sends at most one message per cooldown; matches inside
+// the cooldown are logged as suppressed. Failures are logged only.
+func (tm *SubredditTaskManager) evaluateNotificationRules(ctx context.Context, newPosts []models.Post, logger runLogger) {
+	if len(newPosts) == 0 || !tm.config.Features.NotificationRules.Enabled() {
+		return
+	}
+
+	rules, err := tm.storage.GetActiveNotificationRules(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load notification rules: %v", err))
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	for _, rule := range rules {
+		matcher, err := notify.NewRuleMatcher(rule)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Skipping notification rule %q: %v", rule.Name, err))
+			continue
+		}
+
+		var matches []notify.RuleMatch
+		for _, post := range newPosts {
+			if terms := matcher.Match(post); len(terms) > 0 {
+				matches = append(matches, notify.RuleMatch{Post: post, Terms: terms})
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		tm.fireNotificationRule(ctx, rule, matches, logger)
+	}
+}
+
+// fireNotificationRule sends one message for a rule's matches if its cooldown
+// allows, and records every match in the notification log
+func (tm *SubredditTaskManager) fireNotificationRule(ctx context.Context, rule models.NotificationRule, matches []notify.RuleMatch, logger runLogger) {
+	now := tm.clock.Now().UTC()
+	cooldown := time.Duration(rule.CooldownMinutes) * time.Minute
+
+	claimed, err := tm.storage.ClaimNotificationRule(ctx, rule.Name, now, cooldown)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to check cooldown of notification rule %q: %v", rule.Name, err))
+		return
+	}
+
+	var sendErr error
+	if claimed {
+		_, sendErr = tm.sendRuleNotification(ctx, rule, matches, nil)
+		if sendErr != nil {
+			logger.Error(fmt.Sprintf("Failed to send notification for rule %q: %v", rule.Name, sendErr))
+			// A failed send must not start the cooldown, or the next matches
+			// would be suppressed although nothing was delivered
+			if err := tm.storage.ReleaseNotificationRule(ctx, rule.Name, now); err != nil {
+				logger.Error(fmt.Sprintf("Failed to release cooldown of notification rule %q: %v", rule.Name, err))
+			}
+		} else {
+			logger.Info(fmt.Sprintf("Notification rule %q matched %d new posts", rule.Name, len(matches)))
+		}
+	} else {
+		logger.Info(fmt.Sprintf("Notification rule %q matched %d new posts during cooldown, not sent", rule.Name, len(matches)))
+	}
+
+	entries := make([]models.NotificationLog, 0, len(matches))
+	for _, match := range matches {
+		entry := models.NotificationLog{
+			RuleName:     rule.Name,
+			RedditID:     match.Post.RedditID,
+			Subreddit:    match.Post.Subreddit,
+			Title:        match.Post.Title,
+			MatchedTerms: match.Terms,
+			Channel:      rule.Channel,
+			Suppressed:   !claimed,
+			CreatedAt:    now,
+		}
+		if sendErr != nil {
+			entry.Error = sendErr.Error()
+		}
+		entries = append(entries, entry)
+	}
+	if err := tm.storage.InsertNotificationLogs(ctx, entries); err != nil {
+		logger.Error(fmt.Sprintf("Failed to record notification log: %v", err))
+	}
+}
+
+// ErrReplayUnavailable means a webhook delivery can no longer be replayed
+var ErrReplayUnavailable = errors.New("delivery cannot be replayed")
+
+// ruleNotifier is the rule's own webhook, or the default notifier
+func (tm *SubredditTaskManager) ruleNotifier(rule models.NotificationRule) notify.NotifierInterface {
+	if rule.WebhookURL != "" {
+		return tm.webhooks.NewNotifier(rule.WebhookURL, tm.config.RequestTimeout)
+	}
+	return tm.notifier
+}
+
+// sendRuleNotification sends a rule's matches. Webhook sends are recorded in
+// webhook_deliveries, and the delivery is returned; replayOf links a replay to
+// its original, whose ID is sent again so the receiver can deduplicate.
+func (tm *SubredditTaskManager) sendRuleNotification(ctx context.Context, rule models.NotificationRule, matches []notify.RuleMatch, replayOf *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	notification := notify.FormatRuleNotification(rule, matches)
+	notifier := tm.ruleNotifier(rule)
+	if notifier == nil {
+		return nil, nil
+	}
+	deliverer, ok := notifier.(notify.DelivererInterface)
+	if !ok {
+		return nil, notifier.Notify(ctx, notification)
+	}
+
+	now := tm.clock.Now().UTC()
+	delivery := &models.WebhookDelivery{
+		ID:        primitive.NewObjectID(),
+		RuleName:  rule.Name,
+		Target:    deliverer.Target(),
+		Channel:   rule.Channel,
+		BatchSize: len(matches),
+		CreatedAt: now,
+		ExpiresAt: now.Add(tm.config.WebhookDeliveryTTL),
+	}
+	for i, match := range matches {
+		if i == models.MaxDeliveryPostIDs {
+			delivery.PostIDsTruncated = true
+			break
+		}
+		delivery.RedditIDs = append(delivery.RedditIDs, match.Post.RedditID)
+	}
+	deliveryID := delivery.ID.Hex()
+	if replayOf != nil {
+		delivery.ReplayOf = &replayOf.ID
+		deliveryID = replayOf.ID.Hex()
+	}
+
+	start := time.Now()
+	statusCode, sendErr := deliverer.Deliver(ctx, notification, deliveryID)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.Status = models.DeliveryDelivered
+	if sendErr != nil {
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := tm.storage.InsertWebhookDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to record webhook delivery for rule %q: %v", rule.Name, err)
+	}
+	return delivery, sendErr
+}
+
+// ReplayWebhookDelivery re-sends a delivery to its rule's current webhook,
+// rebuilding the message from the stored post IDs. The replay is recorded as
+// a new delivery; a send failure is reported in it rather than as an error.
+func (tm *SubredditTaskManager) ReplayWebhookDelivery(ctx context.Context, original models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	rule, err := tm.storage.GetNotificationRule(ctx, original.RuleName)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("%w: notification rule %q was deleted", ErrReplayUnavailable, original.RuleName)
+	}
+	if _, ok := tm.ruleNotifier(*rule).(notify.DelivererInterface); !ok {
+		return nil, fmt.Errorf("%w: rule %q has no webhook", ErrReplayUnavailable, rule.Name)
+	}
+
+	matcher, err := notify.NewRuleMatcher(*rule)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]notify.RuleMatch, 0, len(original.RedditIDs))
+	for _, redditID := range original.RedditIDs {
+		post, err := tm.storage.GetPostByRedditID(ctx, redditID)
+		if err != nil {
+			return nil, err
+		}
+		if post == nil {
+			continue
+		}
+		// The rule may have changed since; unmatched posts are still sent
+		matches = append(matches, notify.RuleMatch{Post: *post, Terms: matcher.Match(*post)})
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: none of its posts are stored any more", ErrReplayUnavailable)
+	}
+
+	delivery, _ := tm.sendRuleNotification(ctx, *rule, matches, &original)
+	return delivery, nil
+}
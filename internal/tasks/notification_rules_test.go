@@ -0,0 +1,183 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestFireNotificationRuleCooldown(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	matches := []notify.RuleMatch{{Post: models.Post{RedditID: "t3_a", Subreddit: "golang", Title: "Go 1.23"}, Terms: []string{"go"}}}
+
+	tests := []struct {
+		name          string
+		firstErr      error
+		wantSent      int
+		wantSuppress  bool
+		wantFirstFail bool
+		wantFiredAt   time.Time
+	}{
+		{name: "failed send releases the cooldown", firstErr: errors.New("connection refused"), wantSent: 2, wantFirstFail: true, wantFiredAt: start.Add(time.Minute)},
+		{name: "successful send keeps the cooldown", wantSent: 1, wantSuppress: true, wantFiredAt: start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			store := storagetest.NewMemory(clk)
+			notifier := &recordingNotifier{err: tt.firstErr}
+			tm := newTestManager(t, testConfig(t), store, &fakeClient{}, notifier, clk)
+			rule := models.NotificationRule{Name: "go", Enabled: true, Keywords: []string{"go"}, CooldownMinutes: 60}
+			if err := store.UpsertNotificationRule(context.Background(), &rule); err != nil {
+				t.Fatalf("UpsertNotificationRule() error = %v", err)
+			}
+
+			logger := &recordingLogger{}
+			tm.fireNotificationRule(context.Background(), rule, matches, logger)
+			notifier.mu.Lock()
+			notifier.err = nil
+			notifier.mu.Unlock()
+			clk.Advance(time.Minute)
+			tm.fireNotificationRule(context.Background(), rule, matches, logger)
+
+			if got := len(notifier.notifications()); got != tt.wantSent {
+				t.Errorf("sent %d notifications, want %d", got, tt.wantSent)
+			}
+			entries := store.NotificationLog()
+			if len(entries) != 2 {
+				t.Fatalf("got %d notification log entries, want 2", len(entries))
+			}
+			if failed := entries[0].Error != ""; failed != tt.wantFirstFail {
+				t.Errorf("first entry error = %q, want failed %v", entries[0].Error, tt.wantFirstFail)
+			}
+			if entries[1].Suppressed != tt.wantSuppress {
+				t.Errorf("second entry suppressed = %v, want %v", entries[1].Suppressed, tt.wantSuppress)
+			}
+
+			stored, _ := store.GetNotificationRule(context.Background(), "go")
+			if !stored.LastFiredAt.Equal(tt.wantFiredAt) {
+				t.Errorf("LastFiredAt = %v, want %v", stored.LastFiredAt, tt.wantFiredAt)
+			}
+		})
+	}
+}
+
+func TestRuleWebhookBlocksInternalTargets(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	parsed, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		name       string
+		allowed    []string
+		wantStatus string
+		wantHits   int32
+	}{
+		{name: "loopback refused", wantStatus: models.DeliveryFailed},
+		{name: "allowlisted loopback delivered", allowed: []string{parsed.Hostname()}, wantStatus: models.DeliveryDelivered, wantHits: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+			clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			store := storagetest.NewMemory(clk)
+			cfg := testConfig(t)
+			cfg.WebhookAllowedHosts = tt.allowed
+			tm := newTestManager(t, cfg, store, &fakeClient{}, &recordingNotifier{}, clk)
+			rule := models.NotificationRule{Name: "go", Enabled: true, Keywords: []string{"go"}, WebhookURL: server.URL}
+			if err := store.UpsertNotificationRule(context.Background(), &rule); err != nil {
+				t.Fatalf("UpsertNotificationRule() error = %v", err)
+			}
+
+			matches := []notify.RuleMatch{{Post: models.Post{RedditID: "t3_a", Title: "Go"}, Terms: []string{"go"}}}
+			tm.fireNotificationRule(context.Background(), rule, matches, &recordingLogger{})
+
+			deliveries := store.WebhookDeliveries()
+			if len(deliveries) != 1 {
+				t.Fatalf("got %d deliveries, want 1", len(deliveries))
+			}
+			if deliveries[0].Status != tt.wantStatus {
+				t.Errorf("delivery status = %q (error %q), want %q", deliveries[0].Status, deliveries[0].Error, tt.wantStatus)
+			}
+			if tt.wantStatus == models.DeliveryFailed && !strings.Contains(deliveries[0].Error, notify.ErrBlockedTarget.Error()) {
+				t.Errorf("delivery error = %q, want the blocked target error", deliveries[0].Error)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("webhook got %d requests, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+// TestRuleNotificationThroughChannels sends a rule without its own webhook
+// to the NOTIFY_WEBHOOK_URL channel of a fan-out, and expects the delivery
+// recorded and replayable as with the webhook alone
+func TestRuleNotificationThroughChannels(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		deliveryIDs []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		deliveryIDs = append(deliveryIDs, r.Header.Get(notify.DeliveryIDHeader))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := notify.NewDeliveringFanoutNotifier([]notify.ChannelConfig{
+		{Name: "log", Type: notify.ChannelLog},
+		{Name: "default", Type: notify.ChannelWebhook, URL: server.URL},
+	}, "default", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := storagetest.NewMemory(clk)
+	tm := newTestManager(t, testConfig(t), store, &fakeClient{}, notifier, clk)
+
+	post := models.Post{RedditID: "t3_a", Subreddit: "golang", Title: "Go 1.23"}
+	if _, err := store.UpsertPosts(context.Background(), []models.Post{post}, storage.UpsertOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	rule := models.NotificationRule{Name: "go", Enabled: true, Keywords: []string{"go"}}
+	if err := store.UpsertNotificationRule(context.Background(), &rule); err != nil {
+		t.Fatal(err)
+	}
+	tm.fireNotificationRule(context.Background(), rule, []notify.RuleMatch{{Post: post, Terms: []string{"go"}}}, &recordingLogger{})
+
+	deliveries := store.WebhookDeliveries()
+	if len(deliveries) != 1 || deliveries[0].Status != models.DeliveryDelivered || deliveries[0].Target != server.URL {
+		t.Fatalf("deliveries %+v, want one delivered to %s", deliveries, server.URL)
+	}
+	replay, err := tm.ReplayWebhookDelivery(context.Background(), deliveries[0])
+	if err != nil || replay.Status != models.DeliveryDelivered {
+		t.Fatalf("ReplayWebhookDelivery() = %+v, %v; want it delivered", replay, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := deliveries[0].ID.Hex()
+	if len(deliveryIDs) != 2 || deliveryIDs[0] != want || deliveryIDs[1] != want {
+		t.Errorf("webhook got delivery IDs %q, want %s sent and replayed", deliveryIDs, want)
+	}
+}
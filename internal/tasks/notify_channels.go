@@ -0,0 +1,39 @@
+// internal/tasks/notify_channels.go
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"reddit-orchestrator/internal/notify"
+)
+
+// ErrNoChannels means alerts don't fan out to named channels
+// (NOTIFY_CHANNELS_FILE is unset)
+var ErrNoChannels = errors.New("no notification channels are configured")
+
+// NotificationChannels returns each notification channel's delivery record;
+// empty without NOTIFY_CHANNELS_FILE
+func (tm *SubredditTaskManager) NotificationChannels() []notify.ChannelStatus {
+	if channels, ok := tm.notifier.(notify.ChannelNotifierInterface); ok {
+		return channels.Channels()
+	}
+	return []notify.ChannelStatus{}
+}
+
+// TestNotificationChannel sends a synthetic critical alert to one channel,
+// bypassing its severity filter, and returns the channel's record after it
+func (tm *SubredditTaskManager) TestNotificationChannel(ctx context.Context, channel string) (notify.ChannelStatus, error) {
+	channels, ok := tm.notifier.(notify.ChannelNotifierInterface)
+	if !ok {
+		return notify.ChannelStatus{}, ErrNoChannels
+	}
+	return channels.NotifyChannel(ctx, channel, notify.Notification{
+		Subject:   "Test notification",
+		Message:   "Synthetic alert sent by POST /api/admin/notify-test; no action is needed.",
+		Severity:  notify.SeverityCritical,
+		Subreddit: "example",
+		Error:     "synthetic failure",
+		Counts:    map[string]int{"posts": 0, "failures": 1},
+	})
+}
@@ -0,0 +1,106 @@
+// internal/tasks/params.go
+package tasks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+)
+
+const (
+	minMonitorLimit = 1
+
+	// Earliest accepted since_timestamp (2005-06-01, before Reddit's first posts)
+	minSinceTimestamp = 1117584000
+	// Allowance for clock skew between the dashboard and this host
+	sinceTimestampSkew = 5 * time.Minute
+)
+
+// monitorParams are the validated monitor_subreddit/monitor_user parameters
+type monitorParams struct {
+	Name  string // Subreddit or username, read from the task's name key
+	Limit int
+	// SinceTimestamp is zero when the run should resume from last_scraped_at
+	SinceTimestamp int64
+}
+
+// parseMonitorParams validates monitor task params; nameKey is "subreddit" or
+// "username", maxLimit is MAX_POSTS_CEILING and since_timestamp may not be
+// after now. limit is declared as TypeInt, but string values are still
+// accepted so run history and schedules created before the schema change keep
+// working; since_timestamp stays a string because an empty value means
+// "resume from last scrape".
+func parseMonitorParams(params blueberry.TaskParams, nameKey string, defaultLimit, maxLimit int, now time.Time) (monitorParams, error) {
+	var parsed monitorParams
+
+	name, _ := params[nameKey].(string)
+	parsed.Name = strings.TrimSpace(name)
+	if parsed.Name == "" {
+		return parsed, fmt.Errorf("missing %s parameter", nameKey)
+	}
+
+	limit, present, err := intParam(params, "limit")
+	if err != nil {
+		return parsed, err
+	}
+	if !present {
+		limit = int64(defaultLimit)
+	}
+	if limit < minMonitorLimit || limit > int64(maxLimit) {
+		return parsed, fmt.Errorf("limit must be between %d and %d, got %d", minMonitorLimit, maxLimit, limit)
+	}
+	parsed.Limit = int(limit)
+
+	since, present, err := intParam(params, "since_timestamp")
+	if err != nil {
+		return parsed, err
+	}
+	if present {
+		maxSince := now.Add(sinceTimestampSkew).Unix()
+		if since < minSinceTimestamp || since > maxSince {
+			return parsed, fmt.Errorf("since_timestamp must be epoch seconds between %d and %d, got %d",
+				minSinceTimestamp, maxSince, since)
+		}
+		parsed.SinceTimestamp = since
+	}
+
+	return parsed, nil
+}
+
+// intParam reads an integer parameter given as a number or a numeric string.
+// Missing keys and empty strings report present=false.
+func intParam(params blueberry.TaskParams, key string) (value int64, present bool, err error) {
+	raw, exists := params[key]
+	if !exists || raw == nil {
+		return 0, false, nil
+	}
+
+	switch v := raw.(type) {
+	case int:
+		return int64(v), true, nil
+	case int32:
+		return int64(v), true, nil
+	case int64:
+		return v, true, nil
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, false, fmt.Errorf("%s must be a whole number, got %v", key, v)
+		}
+		return int64(v), true, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, false, nil
+		}
+		parsed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%s must be a whole number, got '%s'", key, v)
+		}
+		return parsed, true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be a whole number, got %T", key, raw)
+	}
+}
@@ -0,0 +1,193 @@
+// internal/tasks/presets.go
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// ErrInvalidPreset means a run preset does not fit its task, or a run request
+// lacks a value one of its placeholders needs
+var ErrInvalidPreset = errors.New("invalid run preset")
+
+// placeholderPattern matches {{name}} and {{name:argument}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z0-9_]+)(?::([^}]*))?\s*\}\}`)
+
+// presetTarget is a task run presets may start
+type presetTarget struct {
+	task   *blueberry.Task
+	schema blueberry.TaskSchema
+	// defaults fill the schema's params a preset leaves out; BlueBerry
+	// requires every one of them
+	defaults blueberry.TaskParams
+	// check validates resolved params the way the task will, so bad values
+	// are rejected before the run starts
+	check func(params blueberry.TaskParams) error
+}
+
+// PresetRun is a run started from a preset
+type PresetRun struct {
+	Preset      string               `json:"preset"`
+	Task        string               `json:"task"`
+	ExecutionID int                  `json:"execution_id"`
+	Params      blueberry.TaskParams `json:"params"`
+}
+
+// allowPresets lets run presets start task
+func (tm *SubredditTaskManager) allowPresets(name string, task *blueberry.Task, schema blueberry.TaskSchema, defaults blueberry.TaskParams, check func(blueberry.TaskParams) error) {
+	if tm.presetTargets == nil {
+		tm.presetTargets = make(map[string]presetTarget)
+	}
+	tm.presetTargets[name] = presetTarget{task: task, schema: schema, defaults: defaults, check: check}
+}
+
+// checkMonitorParams validates monitor_subreddit/monitor_user params as the
+// task does when it starts
+func (tm *SubredditTaskManager) checkMonitorParams(params blueberry.TaskParams, nameKey string) error {
+	if _, err := parseMonitorParams(params, nameKey, tm.config.DefaultLimit, tm.config.MaxPostsCeiling, tm.clock.Now()); err != nil {
+		return err
+	}
+	_, err := chunkSizeParam(params, tm.config.ProcessChunkSize)
+	return err
+}
+
+// ValidatePreset checks that a preset names a task presets may start, that
+// its params are in the task's schema and that its placeholders are known.
+// Values that depend on placeholders are checked when it runs.
+func (tm *SubredditTaskManager) ValidatePreset(preset models.RunPreset) error {
+	target, ok := tm.presetTargets[preset.Task]
+	if !ok {
+		return fmt.Errorf("%w: task must be one of %s", ErrInvalidPreset, strings.Join(tm.presetTaskNames(), ", "))
+	}
+	for key, value := range preset.Params {
+		if _, ok := target.schema.Fields[key]; !ok {
+			return fmt.Errorf("%w: %s has no parameter %q", ErrInvalidPreset, preset.Task, key)
+		}
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if match[1] == "ago" {
+				if _, err := presetAgo(match[2], tm.clock.Now()); err != nil {
+					return fmt.Errorf("%w: %s: %v", ErrInvalidPreset, key, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RunPreset fills in a preset's placeholders from values, validates the
+// result against the task and starts the run the way catch-up does. Nothing
+// runs when a placeholder has no value or a param is invalid.
+func (tm *SubredditTaskManager) RunPreset(preset models.RunPreset, values map[string]string) (PresetRun, error) {
+	if err := tm.ValidatePreset(preset); err != nil {
+		return PresetRun{}, err
+	}
+	target := tm.presetTargets[preset.Task]
+
+	params, err := tm.resolvePresetParams(preset, values, tm.clock.Now().UTC())
+	if err != nil {
+		return PresetRun{}, err
+	}
+	for key, value := range target.defaults {
+		if _, ok := params[key]; !ok {
+			params[key] = value
+		}
+	}
+	if err := target.task.ValidateParams(params); err != nil {
+		return PresetRun{}, fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	if target.check != nil {
+		if err := target.check(params); err != nil {
+			return PresetRun{}, fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+		}
+	}
+
+	executionID, err := target.task.ExecuteNow(params)
+	if err != nil {
+		return PresetRun{}, fmt.Errorf("starting %s: %w", preset.Task, err)
+	}
+	return PresetRun{Preset: preset.Name, Task: preset.Task, ExecutionID: executionID, Params: params}, nil
+}
+
+// resolvePresetParams copies a preset's params with their placeholders filled
+// in. Every missing value is reported at once.
+func (tm *SubredditTaskManager) resolvePresetParams(preset models.RunPreset, values map[string]string, now time.Time) (blueberry.TaskParams, error) {
+	params := make(blueberry.TaskParams, len(preset.Params))
+	missing := map[string]bool{}
+	for key, value := range preset.Params {
+		text, ok := value.(string)
+		if !ok {
+			params[key] = normalizePresetValue(value)
+			continue
+		}
+		params[key] = placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			match := placeholderPattern.FindStringSubmatch(placeholder)
+			switch match[1] {
+			case "ago":
+				// ValidatePreset checked the duration
+				since, _ := presetAgo(match[2], now)
+				return strconv.FormatInt(since, 10)
+			case "max_limit":
+				return strconv.Itoa(tm.config.MaxPostsCeiling)
+			}
+			value := strings.TrimSpace(values[match[1]])
+			if value == "" {
+				missing[match[1]] = true
+			}
+			return value
+		})
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: no value for %s (pass them as query parameters)", ErrInvalidPreset, strings.Join(names, ", "))
+	}
+	return params, nil
+}
+
+// presetAgo resolves {{ago:duration}} to the epoch seconds duration before now
+func presetAgo(duration string, now time.Time) (int64, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(duration))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("{{ago:%s}} needs a positive duration such as 24h", duration)
+	}
+	return now.Add(-d).Unix(), nil
+}
+
+// normalizePresetValue turns the integer types Mongo decodes into the int
+// BlueBerry's schema validation expects
+func normalizePresetValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	}
+	return value
+}
+
+// presetTaskNames lists the tasks presets may start
+func (tm *SubredditTaskManager) presetTaskNames() []string {
+	names := make([]string, 0, len(tm.presetTargets))
+	for name := range tm.presetTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
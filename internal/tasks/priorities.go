@@ -0,0 +1,237 @@
+// internal/tasks/priorities.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/models"
+)
+
+// priorityActor is recorded in the config audit for priorities it applies
+const priorityActor = "recompute_priorities"
+
+// priorityHalfLives is how many half-lives fit in PRIORITY_LOOKBACK: with
+// the default 28 days, a post from a week ago counts half as much as one from
+// today
+const priorityHalfLives = 4
+
+// priorityStats are what a subreddit's priority is scored from
+type priorityStats struct {
+	PostsPerDay float64
+	AvgScore    float64
+	FailureRate float64
+}
+
+// priorityWeights are the relative weights of volume, average score and
+// reliability (one minus the failure rate)
+type priorityWeights struct {
+	Volume      float64
+	Score       float64
+	Reliability float64
+}
+
+// scorePriorities maps every subreddit's stats into the 0-100 priority range.
+// Volume and average score are taken on a log scale relative to the busiest
+// and best-scoring subreddit, so one outlier does not flatten the rest.
+func scorePriorities(stats map[string]priorityStats, weights priorityWeights) map[string]int {
+	var maxVolume, maxScore float64
+	for _, s := range stats {
+		maxVolume = math.Max(maxVolume, math.Log1p(math.Max(s.PostsPerDay, 0)))
+		maxScore = math.Max(maxScore, math.Log1p(math.Max(s.AvgScore, 0)))
+	}
+
+	total := weights.Volume + weights.Score + weights.Reliability
+	priorities := make(map[string]int, len(stats))
+	for name, s := range stats {
+		if total <= 0 {
+			priorities[name] = 0
+			continue
+		}
+		var volume, score float64
+		if maxVolume > 0 {
+			volume = math.Log1p(math.Max(s.PostsPerDay, 0)) / maxVolume
+		}
+		if maxScore > 0 {
+			score = math.Log1p(math.Max(s.AvgScore, 0)) / maxScore
+		}
+		reliability := 1 - math.Min(math.Max(s.FailureRate, 0), 1)
+
+		weighted := (weights.Volume*volume + weights.Score*score + weights.Reliability*reliability) / total
+		priorities[name] = int(math.Round(math.Min(math.Max(weighted, 0), 1) * 100))
+	}
+	return priorities
+}
+
+// agedPriorityStats derives a subreddit's stats from its hourly rollups
+// between from and to, each hour weighted by its age with the given half-life
+func agedPriorityStats(rollups []models.PostRollup, from, to time.Time, halfLife time.Duration, failureRate float64) priorityStats {
+	weight := func(at time.Time) float64 {
+		return math.Pow(0.5, float64(to.Sub(at))/float64(halfLife))
+	}
+
+	// The weight the window's hours add up to, so the volume is a weighted
+	// average per hour rather than a weighted sum
+	var hours float64
+	for at := to.Add(-time.Hour); !at.Before(from); at = at.Add(-time.Hour) {
+		hours += weight(at)
+	}
+
+	var posts, scores float64
+	for _, rollup := range rollups {
+		w := weight(rollup.BucketStart)
+		posts += w * float64(rollup.PostCount)
+		scores += w * float64(rollup.SumScore)
+	}
+
+	stats := priorityStats{FailureRate: failureRate}
+	if hours > 0 {
+		stats.PostsPerDay = posts / hours * 24
+	}
+	if posts > 0 {
+		stats.AvgScore = scores / posts
+	}
+	return stats
+}
+
+// registerPriorityTask registers recompute_priorities and schedules it (empty
+// PriorityRecomputeSchedule leaves it manual-only)
+func (tm *SubredditTaskManager) registerPriorityTask() error {
+	schema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{})
+
+	task, err := tm.registerTask("recompute_priorities", tm.leaderOnly(tm.recomputePriorities), schema)
+	if err != nil {
+		return fmt.Errorf("failed to register priority task: %w", err)
+	}
+
+	if tm.config.PriorityRecomputeSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{}, tm.config.PriorityRecomputeSchedule); err != nil {
+		return fmt.Errorf("failed to schedule priority recomputation: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "recompute_priorities", tm.config.PriorityRecomputeSchedule, nil)
+	return nil
+}
+
+// recomputePriorities scores every enabled subreddit from its volume, average
+// score and failure rate over PRIORITY_LOOKBACK and saves the result as its
+// suggested priority. With AUTO_PRIORITY the suggestion also replaces the
+// priority of configs that are not priority_locked; like other config
+// changes, a new priority tier applies from the next restart.
+func (tm *SubredditTaskManager) recomputePriorities(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit configs: %v", err))
+		return err
+	}
+	if len(configs) == 0 {
+		logger.Success("No enabled subreddits to prioritize")
+		return nil
+	}
+
+	now := tm.clock.Now().UTC()
+	stats, err := tm.priorityStats(ctx, configs, now)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to gather priority stats: %v", err))
+		return err
+	}
+	priorities := scorePriorities(stats, priorityWeights{
+		Volume:      tm.config.PriorityWeightVolume,
+		Score:       tm.config.PriorityWeightScore,
+		Reliability: tm.config.PriorityWeightReliability,
+	})
+
+	suggested, applied, locked, failed := 0, 0, 0, 0
+	for _, config := range configs {
+		s := stats[config.SubredditName]
+		suggestion := models.PrioritySuggestion{
+			Priority:    priorities[config.SubredditName],
+			PostsPerDay: math.Round(s.PostsPerDay*100) / 100,
+			AvgScore:    math.Round(s.AvgScore*100) / 100,
+			FailureRate: math.Round(s.FailureRate*1000) / 1000,
+			Applied:     tm.config.AutoPriority,
+			ComputedAt:  now,
+		}
+		changed, err := tm.storage.SetPrioritySuggestion(ctx, config.SubredditName, suggestion, priorityActor)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to save the priority of r/%s: %v", config.SubredditName, err))
+			failed++
+			continue
+		}
+		suggested++
+		if config.PriorityLocked {
+			locked++
+		}
+		if changed {
+			applied++
+			logger.Info(fmt.Sprintf("r/%s: priority %d -> %d", config.SubredditName, config.Priority, suggestion.Priority))
+		}
+	}
+
+	message := fmt.Sprintf("Priorities recomputed: %d suggested, %d applied, %d locked, %d failed", suggested, applied, locked, failed)
+	if failed > 0 && suggested == 0 {
+		return logger.Error(message)
+	}
+	logger.Success(message)
+	return nil
+}
+
+// priorityStats gathers the age-weighted stats of every config
+func (tm *SubredditTaskManager) priorityStats(ctx context.Context, configs []models.SubredditConfig, now time.Time) (map[string]priorityStats, error) {
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		names = append(names, config.SubredditName)
+	}
+
+	to := now.Truncate(time.Hour)
+	from := to.Add(-tm.config.PriorityLookback)
+	rollups, err := tm.storage.GetRollupsForSubreddits(ctx, names, from, to)
+	if err != nil {
+		return nil, err
+	}
+	bySubreddit := make(map[string][]models.PostRollup, len(names))
+	for _, rollup := range rollups {
+		bySubreddit[rollup.Subreddit] = append(bySubreddit[rollup.Subreddit], rollup)
+	}
+
+	metadatas, err := tm.storage.GetSubredditMetadataByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	halfLife := tm.config.PriorityLookback / priorityHalfLives
+	stats := make(map[string]priorityStats, len(names))
+	for _, name := range names {
+		metadata := metadatas[name]
+		stats[name] = agedPriorityStats(bySubreddit[name], from, to, halfLife, metadata.FailureRate(from))
+	}
+	return stats, nil
+}
+
+// recordRunOutcome adds a finished monitor run to the subreddit's recent
+// runs. Rate limits and cancellations say nothing about the subreddit and
+// are left out, and a run that stored its posts but failed bookkeeping
+// counts as succeeded. Failures are logged only.
+func (tm *SubredditTaskManager) recordRunOutcome(ctx context.Context, subredditName string, runErr error) {
+	if _, ok := client.AsRateLimited(runErr); ok || errors.Is(runErr, context.Canceled) {
+		return
+	}
+
+	ctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+	if err := tm.storage.RecordRunOutcome(ctx, subredditName, failedStage(runErr) == FailedStageIngest); err != nil {
+		fmt.Printf("Failed to record the run outcome of r/%s: %v\n", subredditName, err)
+	}
+}
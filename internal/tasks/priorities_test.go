@@ -0,0 +1,223 @@
+package tasks
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestScorePriorities(t *testing.T) {
+	defaults := priorityWeights{Volume: 0.5, Score: 0.3, Reliability: 0.2}
+
+	// log1p of 99 and 999 is twice and three times that of 9, so the
+	// relative volumes and scores below come out as exact fractions
+	tests := []struct {
+		name    string
+		stats   map[string]priorityStats
+		weights priorityWeights
+		want    map[string]int
+	}{
+		{
+			name: "relative to the busiest and best-scoring subreddit",
+			stats: map[string]priorityStats{
+				"busy":    {PostsPerDay: 99, AvgScore: 99},
+				"quality": {PostsPerDay: 9, AvgScore: 999, FailureRate: 0.5},
+				"broken":  {PostsPerDay: 9, AvgScore: 9, FailureRate: 1},
+				"quiet":   {},
+			},
+			weights: defaults,
+			// busy: 0.5*1 + 0.3*2/3 + 0.2*1; quality: 0.5*1/2 + 0.3*1 + 0.2*1/2
+			want: map[string]int{"busy": 90, "quality": 65, "broken": 35, "quiet": 20},
+		},
+		{
+			name:    "a lone subreddit is its own maximum",
+			stats:   map[string]priorityStats{"golang": {PostsPerDay: 5, AvgScore: 12}},
+			weights: defaults,
+			want:    map[string]int{"golang": 100},
+		},
+		{
+			name: "out of range stats are clamped",
+			stats: map[string]priorityStats{
+				"odd":  {PostsPerDay: -3, AvgScore: -5, FailureRate: 2},
+				"fine": {PostsPerDay: 9, AvgScore: 9, FailureRate: -1},
+			},
+			weights: defaults,
+			want:    map[string]int{"odd": 0, "fine": 100},
+		},
+		{
+			name: "weights are relative",
+			stats: map[string]priorityStats{
+				"busy":  {PostsPerDay: 99, AvgScore: 9},
+				"quiet": {PostsPerDay: 9, AvgScore: 999},
+			},
+			weights: priorityWeights{Volume: 2, Score: 2},
+			// busy: (1 + 1/3) / 2; quiet: (1/2 + 1) / 2
+			want: map[string]int{"busy": 67, "quiet": 75},
+		},
+		{
+			name:    "zero weights",
+			stats:   map[string]priorityStats{"golang": {PostsPerDay: 5}},
+			weights: priorityWeights{},
+			want:    map[string]int{"golang": 0},
+		},
+		{name: "no subreddits", stats: map[string]priorityStats{}, weights: defaults, want: map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorePriorities(tt.stats, tt.weights); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scorePriorities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgedPriorityStats(t *testing.T) {
+	to := time.Date(2024, 5, 29, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-2 * time.Hour)
+	rollup := func(hoursAgo, posts int, sumScore int64) models.PostRollup {
+		return models.PostRollup{BucketStart: to.Add(-time.Duration(hoursAgo) * time.Hour), PostCount: posts, SumScore: sumScore}
+	}
+
+	// With an hour's half-life the window's two hours weigh 1/2 and 1/4
+	tests := []struct {
+		name    string
+		rollups []models.PostRollup
+		want    priorityStats
+	}{
+		{name: "no posts", want: priorityStats{FailureRate: 0.25}},
+		{
+			name:    "recent hour",
+			rollups: []models.PostRollup{rollup(1, 3, 30)},
+			// 3/2 weighted posts over 3/4 weighted hours, times 24
+			want: priorityStats{PostsPerDay: 48, AvgScore: 10, FailureRate: 0.25},
+		},
+		{
+			name:    "older hour counts half as much",
+			rollups: []models.PostRollup{rollup(2, 3, 30)},
+			want:    priorityStats{PostsPerDay: 24, AvgScore: 10, FailureRate: 0.25},
+		},
+		{
+			name:    "score averaged over weighted posts",
+			rollups: []models.PostRollup{rollup(1, 3, 30), rollup(2, 4, 4)},
+			// (3/2 + 4/4) posts scoring (30/2 + 4/4)
+			want: priorityStats{PostsPerDay: 80, AvgScore: 6.4, FailureRate: 0.25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := agedPriorityStats(tt.rollups, from, to, time.Hour, 0.25)
+			if !closeTo(got.PostsPerDay, tt.want.PostsPerDay) || !closeTo(got.AvgScore, tt.want.AvgScore) || got.FailureRate != tt.want.FailureRate {
+				t.Errorf("agedPriorityStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+// priorityStore serves fixture rollups and applies priority suggestions to
+// the stored configs the way MongoStorage does
+type priorityStore struct {
+	*storagetest.Memory
+	rollups     []models.PostRollup
+	suggestions map[string]models.PrioritySuggestion
+}
+
+func (s *priorityStore) GetRollupsForSubreddits(ctx context.Context, subreddits []string, from, to time.Time) ([]models.PostRollup, error) {
+	var rollups []models.PostRollup
+	for _, rollup := range s.rollups {
+		if slices.Contains(subreddits, rollup.Subreddit) && !rollup.BucketStart.Before(from) && rollup.BucketStart.Before(to) {
+			rollups = append(rollups, rollup)
+		}
+	}
+	return rollups, nil
+}
+
+func (s *priorityStore) SetPrioritySuggestion(ctx context.Context, subredditName string, suggestion models.PrioritySuggestion, actor string) (bool, error) {
+	config, err := s.GetSubredditConfig(ctx, subredditName)
+	if err != nil || config == nil {
+		return false, err
+	}
+	suggestion.Applied = suggestion.Applied && !config.PriorityLocked
+	s.suggestions[subredditName] = suggestion
+	if !suggestion.Applied || config.Priority == suggestion.Priority {
+		return false, nil
+	}
+	config.Priority = suggestion.Priority
+	return true, s.UpsertSubredditConfig(ctx, config, actor)
+}
+
+func TestRecomputePriorities(t *testing.T) {
+	now := time.Date(2024, 5, 29, 12, 30, 0, 0, time.UTC)
+	to := now.Truncate(time.Hour)
+	clk := clocktest.NewFake(now)
+	cfg := testConfig(t)
+	// A half-life of 30 minutes: the window's two hours weigh 1/4 and 1/16
+	cfg.PriorityLookback = 2 * time.Hour
+	cfg.AutoPriority = true
+
+	store := &priorityStore{
+		Memory: storagetest.NewMemory(clk),
+		rollups: []models.PostRollup{
+			{Subreddit: "busy", BucketStart: to.Add(-time.Hour), PostCount: 10, SumScore: 1000},
+			{Subreddit: "quiet", BucketStart: to.Add(-2 * time.Hour), PostCount: 16, SumScore: 16},
+			// Outside the lookback
+			{Subreddit: "quiet", BucketStart: to.Add(-3 * time.Hour), PostCount: 500, SumScore: 50000},
+		},
+		suggestions: map[string]models.PrioritySuggestion{},
+	}
+	for _, config := range []models.SubredditConfig{
+		{SubredditName: "busy", Enabled: true, Priority: 10},
+		{SubredditName: "quiet", Enabled: true, Priority: 50, PriorityLocked: true},
+		{SubredditName: "disabled", Enabled: false, Priority: 5},
+	} {
+		if err := store.UpsertSubredditConfig(context.Background(), &config, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.SetMetadata(models.SubredditMetadata{SubredditName: "busy", RecentRuns: []models.RunOutcome{
+		{At: to.Add(-3 * time.Hour), Failed: true}, // Before the lookback
+		{At: to.Add(-90 * time.Minute), Failed: true},
+		{At: to.Add(-60 * time.Minute)},
+		{At: to.Add(-30 * time.Minute)},
+		{At: now},
+	}})
+
+	tm := newTestManager(t, cfg, store, &fakeClient{}, &recordingNotifier{}, clk)
+	status, logs := runTask(t, tm, tm.recomputePriorities, nil)
+	if status != "completed" {
+		t.Fatalf("status = %q, logs %v; want completed", status, logs)
+	}
+	if want := "Priorities recomputed: 2 suggested, 1 applied, 1 locked, 0 failed"; !slices.Contains(logs, want) {
+		t.Errorf("logs %v, want %q", logs, want)
+	}
+
+	// busy: 10/4 posts over 5/16 hours, all scoring 100, one in four runs
+	// failed. quiet: 16/16 posts over the same hours, scoring 1, so its
+	// volume is ln(77.8)/ln(193) and its score ln(2)/ln(101) of busy's.
+	want := map[string]models.PrioritySuggestion{
+		"busy":  {Priority: 95, PostsPerDay: 192, AvgScore: 100, FailureRate: 0.25, Applied: true, ComputedAt: now},
+		"quiet": {Priority: 66, PostsPerDay: 76.8, AvgScore: 1, FailureRate: 0, Applied: false, ComputedAt: now},
+	}
+	if !reflect.DeepEqual(store.suggestions, want) {
+		t.Errorf("suggestions = %+v, want %+v", store.suggestions, want)
+	}
+
+	for name, wantPriority := range map[string]int{"busy": 95, "quiet": 50, "disabled": 5} {
+		config, _ := store.GetSubredditConfig(context.Background(), name)
+		if config.Priority != wantPriority {
+			t.Errorf("r/%s priority = %d, want %d", name, config.Priority, wantPriority)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// internal/tasks/push.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"reddit-orchestrator/internal/metrics"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+)
+
+// ErrSubredditPaused is returned for pushes to a subreddit whose config is paused
+var ErrSubredditPaused = errors.New("subreddit config is paused")
+
+// runLogger is the logging the run helpers need; *blueberry.Logger
+// implements it for task runs and pushLogger for pushed batches
+type runLogger interface {
+	Info(message string) error
+	Error(message string) error
+	Success(message string) error
+}
+
+// pushLogger writes a pushed batch's progress to the process log, as there
+// is no task run to attach it to
+type pushLogger struct {
+	subreddit string
+}
+
+func (l pushLogger) Info(message string) error {
+	log.Printf("Push r/%s: %s", l.subreddit, message)
+	return nil
+}
+
+func (l pushLogger) Error(message string) error {
+	log.Printf("Error: push r/%s: %s", l.subreddit, message)
+	return nil
+}
+
+func (l pushLogger) Success(message string) error {
+	log.Printf("Push r/%s: %s", l.subreddit, message)
+	return nil
+}
+
+// IngestPushed stores posts pushed to the ingest endpoint the way a scheduled
+// run stores fetched ones: processed and upserted in chunks, then rollups,
+// metadata (with source "push"), staleness and notification rules. The
+// batch waits for a run slot like a scheduled run.
+func (tm *SubredditTaskManager) IngestPushed(ctx context.Context, subredditName string, posts []models.IngestionPost) (stats models.RunStats, runErr error) {
+	logger := pushLogger{subreddit: subredditName}
+
+	subredditConfig, err := tm.storage.GetSubredditConfig(ctx, subredditName)
+	if err != nil {
+		return stats, err
+	}
+	if subredditConfig != nil && subredditConfig.Paused {
+		return stats, ErrSubredditPaused
+	}
+
+	runID := tm.queue.enqueue(subredditName)
+	if err := tm.queue.acquire(ctx, runID); err != nil {
+		tm.queue.finish(runID, err)
+		return stats, err
+	}
+	defer func() { tm.queue.finish(runID, runErr) }()
+
+	receivedAt := tm.clock.Now().UTC()
+	logger.Info(fmt.Sprintf("Received %d pushed posts", len(posts)))
+
+	sampler := tm.newQASampler(runID, subredditName, subredditConfig)
+	process := func(ctx context.Context, chunk []models.IngestionPost) ([]models.Post, processor.ProcessStats, error) {
+		processed, stats := tm.processor.ProcessSubredditPosts(chunk, subredditName, subredditConfig)
+		enriched, err := tm.enrichPosts(ctx, processed, subredditConfig, logger)
+		tm.recordQASamples(ctx, sampler, chunk, processed, enriched, err, logger)
+		return enriched, stats, err
+	}
+	stored, err := tm.storeInChunks(ctx, posts, tm.config.ProcessChunkSize, process, upsertOptions(subredditConfig), logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to store posts (%d of %d chunks stored): %v", stored.ChunksDone, stored.Chunks, err))
+		tm.updateRollups(ctx, stored.Inserted, logger)
+		tm.evaluateNotificationRules(ctx, stored.Inserted, logger)
+		return stats, err
+	}
+	processStats := stored.Stats
+	logRejections(logger, processStats)
+	metrics.RecordRejections(subredditName, processStats.Rejections)
+	tm.updateRollups(ctx, stored.Inserted, logger)
+
+	stats = models.RunStats{
+		Limit:          len(posts),
+		PostsFetched:   len(posts),
+		PostsProcessed: stored.Stored,
+		SkippedNSFW:    processStats.Rejections[processor.RejectNSFW],
+		SkippedSpoiler: processStats.Rejections[processor.RejectSpoiler],
+		Rejections:     processStats.Rejections,
+		Duration:       tm.clock.Now().Sub(receivedAt),
+		Source:         models.RunSourcePush,
+	}
+	stats.SetPostRange(processStats.Batch.OldestCreatedAt, processStats.Batch.NewestCreatedAt)
+	if err := tm.updateMetadata(ctx, subredditName, receivedAt, stats, logger); err != nil {
+		return stats, err
+	}
+	tm.trackStaleness(ctx, subredditName, subredditConfig, len(posts), logger)
+	tm.evaluateNotificationRules(ctx, stored.Inserted, logger)
+
+	logger.Success(fmt.Sprintf("Stored %d of %d pushed posts%s", stored.Stored, len(posts), formatBatchSpan(processStats.Batch)))
+	return stats, nil
+}
@@ -0,0 +1,127 @@
+// internal/tasks/qa_samples.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// qaSampler samples one run's posts for QA at its subreddit's sample_rate.
+// A nil sampler samples nothing, so runs at rate 0 pay only a nil check.
+type qaSampler struct {
+	runID     string
+	subreddit string
+	config    *models.SubredditConfig
+	rate      float64
+}
+
+// newQASampler returns the run's sampler, or nil when the subreddit is not sampled
+func (tm *SubredditTaskManager) newQASampler(runID int64, subreddit string, config *models.SubredditConfig) *qaSampler {
+	if config == nil || config.SampleRate <= 0 {
+		return nil
+	}
+	return &qaSampler{
+		runID:     fmt.Sprintf("%s-%d", tm.config.InstanceID, runID),
+		subreddit: subreddit,
+		config:    config,
+		rate:      config.SampleRate,
+	}
+}
+
+// qaSampled reports whether a post falls in the sample at rate. The choice
+// hashes the reddit ID, so reruns and refetches sample the same posts.
+func qaSampled(redditID string, rate float64) bool {
+	hash := fnv.New64a()
+	hash.Write([]byte(redditID))
+	return float64(hash.Sum64())/math.MaxUint64 < rate
+}
+
+// recordQASamples stores the sampled posts of a chunk with what the processor
+// and enricher did with them, given the chunk's processed posts and the
+// enricher's result. Failures are logged only.
+func (tm *SubredditTaskManager) recordQASamples(ctx context.Context, sampler *qaSampler, chunk []models.IngestionPost, processed, enriched []models.Post, enrichErr error, logger runLogger) {
+	if sampler == nil {
+		return
+	}
+
+	var sampled []models.IngestionPost
+	for _, post := range chunk {
+		if qaSampled(strings.TrimSpace(post.ID), sampler.rate) {
+			sampled = append(sampled, post)
+		}
+	}
+	if len(sampled) == 0 {
+		return
+	}
+
+	// Fail-closed enrichment returns no posts; the unenriched ones are kept then
+	if enrichErr != nil {
+		enriched = processed
+	}
+	kept := make(map[string]models.Post, len(enriched))
+	for _, post := range enriched {
+		kept[post.RedditID] = post
+	}
+	// Runs don't list rejected posts; processing the few sampled ones again
+	// recovers their rejection reasons
+	_, preview := tm.processor.PreviewSubredditPosts(sampled, sampler.subreddit, sampler.config)
+	reasons := make(map[string]string, len(preview.Rejected))
+	for _, rejected := range preview.Rejected {
+		reasons[rejected.RedditID] = rejected.Reason
+	}
+
+	now := tm.clock.Now().UTC()
+	samples := make([]models.QASample, 0, len(sampled))
+	for _, raw := range sampled {
+		// The raw post is kept as fetched except for its author, which
+		// PRIVACY_MODE covers wherever it is stored
+		raw.Author = tm.authors.Apply(raw.Author)
+		sample := models.QASample{
+			RunID:     sampler.runID,
+			Subreddit: sampler.subreddit,
+			RedditID:  strings.TrimSpace(raw.ID),
+			Raw:       raw,
+			SampledAt: now,
+			ExpiresAt: now.Add(tm.config.QASampleTTL),
+		}
+		post, ok := kept[sample.RedditID]
+		if !ok {
+			sample.Stages = []models.QAStage{{Stage: models.QAStageProcess, Outcome: models.QAOutcomeRejected, Detail: reasons[sample.RedditID]}}
+			samples = append(samples, sample)
+			continue
+		}
+		sample.Processed = &post
+		sample.Stages = []models.QAStage{
+			{Stage: models.QAStageProcess, Outcome: models.QAOutcomeKept},
+			tm.qaEnrichStage(post, enrichErr),
+		}
+		samples = append(samples, sample)
+	}
+
+	if err := tm.storage.InsertQASamples(ctx, samples); err != nil {
+		logger.Error(fmt.Sprintf("Failed to store %d QA samples: %v", len(samples), err))
+	}
+}
+
+// qaEnrichStage describes what enrichment did with a kept post
+func (tm *SubredditTaskManager) qaEnrichStage(post models.Post, enrichErr error) models.QAStage {
+	stage := models.QAStage{Stage: models.QAStageEnrich}
+	switch {
+	case tm.enricher == nil || !tm.config.Features.Enrichment.Enabled():
+		stage.Outcome = models.QAOutcomeSkipped
+	case enrichErr != nil:
+		stage.Outcome = models.QAOutcomeFailed
+		stage.Detail = enrichErr.Error()
+	case len(post.Extras) == 0:
+		stage.Outcome = models.QAOutcomeNoExtras
+	default:
+		stage.Outcome = models.QAOutcomeEnriched
+		stage.Detail = fmt.Sprintf("%d extras", len(post.Extras))
+	}
+	return stage
+}
@@ -0,0 +1,44 @@
+package tasks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/privacy"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestQASamplesApplyPrivacyMode(t *testing.T) {
+	t.Setenv("PRIVACY_MODE", "hash")
+	t.Setenv("PRIVACY_HASH_KEY", "test-key")
+	cfg := testConfig(t)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clk := clocktest.NewFake(start)
+	store := storagetest.NewMemory(clk)
+	store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", LastScrapedAt: start.Add(-2 * time.Hour)})
+	if err := store.UpsertSubredditConfig(context.Background(), &models.SubredditConfig{SubredditName: "golang", Enabled: true, SampleRate: 1}, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+		post := ingestionPost("t3_a", start.Add(-time.Hour))
+		post.Author = "alice"
+		return []models.IngestionPost{post}, nil
+	}}
+	tm := newTestManager(t, cfg, store, ingestion, &recordingNotifier{}, clk)
+
+	if status, messages := runTask(t, tm, tm.monitorSubreddit, scheduledParams("golang", 10)); status != "completed" {
+		t.Fatalf("status = %s, log %v", status, messages)
+	}
+	samples := store.QASamples()
+	if len(samples) != 1 {
+		t.Fatalf("stored %d QA samples, want 1", len(samples))
+	}
+	want := privacy.NewAuthors("hash", "test-key").Apply("alice")
+	if got := samples[0].Raw.Author; got != want {
+		t.Errorf("sampled raw author = %q, want the hashed %q", got, want)
+	}
+}
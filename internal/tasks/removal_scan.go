@@ -0,0 +1,120 @@
+// internal/tasks/removal_scan.go
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/storage"
+)
+
+// removalMisses is how many consecutive scans a post must be missing from
+// before it counts as removed; one miss may just be a shifted page
+const removalMisses = 2
+
+// registerRemovalScanTask registers reconcile_removed_posts and schedules it
+// (empty RemovalScanSchedule leaves it manual-only)
+func (tm *SubredditTaskManager) registerRemovalScanTask() error {
+	schema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{})
+
+	task, err := tm.registerTask("reconcile_removed_posts", tm.leaderOnly(tm.reconcileRemovedPosts), schema)
+	if err != nil {
+		return fmt.Errorf("failed to register removed post task: %w", err)
+	}
+
+	if tm.config.RemovalScanSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{}, tm.config.RemovalScanSchedule); err != nil {
+		return fmt.Errorf("failed to schedule removed post reconciliation: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "reconcile_removed_posts", tm.config.RemovalScanSchedule, nil)
+	return nil
+}
+
+// reconcileRemovedPosts refetches the last RemovalScanWindow of every active
+// subreddit and updates the status of its stored posts: missing twice in a
+// row marks a post removed, reappearing marks it restored. When the fetch
+// hits MAX_POSTS_CEILING, only posts at least as new as the oldest fetched
+// one are judged, as older ones were simply past the page.
+func (tm *SubredditTaskManager) reconcileRemovedPosts(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+		logger.Info(fmt.Sprintf("Ingestion paused until %s, skipping removed post reconciliation", pausedUntil.UTC().Format(time.RFC3339)))
+		return nil
+	}
+	if pause := tm.maintenancePause(ctx, logger); pause != nil {
+		logger.Info(fmt.Sprintf("Ingestion paused for maintenance (%s), skipping removed post reconciliation", describePause(pause)))
+		return nil
+	}
+
+	configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit configs: %v", err))
+		return err
+	}
+
+	limit := tm.config.MaxPostsCeiling
+	scanned, removed, restored, failed := 0, 0, 0, 0
+	for _, config := range configs {
+		if config.Paused {
+			continue
+		}
+
+		// Posts created after the fetch started may be missing from it
+		// without having been removed
+		window := storage.TimeRange{To: tm.clock.Now().UTC()}
+		window.From = window.To.Add(-tm.config.RemovalScanWindow)
+
+		posts, err := tm.fetchSubredditPosts(ctx, config.SubredditName, limit, window.From.Unix(), logger)
+		if err != nil {
+			if tm.pauseOnRateLimit(err, logger, fmt.Sprintf("%d subreddits scanned", scanned)) {
+				return err
+			}
+			logger.Error(fmt.Sprintf("Failed to fetch r/%s: %v", config.SubredditName, err))
+			failed++
+			continue
+		}
+
+		seen := make([]string, 0, len(posts))
+		var oldest time.Time
+		for _, post := range posts {
+			seen = append(seen, strings.TrimSpace(post.ID))
+			if !post.CreatedAt.IsZero() && (oldest.IsZero() || post.CreatedAt.Before(oldest)) {
+				oldest = post.CreatedAt
+			}
+		}
+		if len(posts) >= limit && oldest.After(window.From) {
+			window.From = oldest
+		}
+
+		scan, err := tm.storage.ReconcilePostPresence(ctx, config.SubredditName, window, seen, removalMisses)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to reconcile r/%s: %v", config.SubredditName, err))
+			failed++
+			continue
+		}
+		scanned++
+		removed += scan.Removed
+		restored += scan.Restored
+		if scan.Removed > 0 || scan.Restored > 0 {
+			logger.Info(fmt.Sprintf("r/%s: %d posts removed, %d restored, %d missing from this scan",
+				config.SubredditName, scan.Removed, scan.Restored, scan.Missed))
+		}
+	}
+
+	message := fmt.Sprintf("Removed post reconciliation: %d subreddits scanned, %d posts removed, %d restored, %d subreddits failed",
+		scanned, removed, restored, failed)
+	if failed > 0 && scanned == 0 {
+		return logger.Error(message)
+	}
+	logger.Success(message)
+	return nil
+}
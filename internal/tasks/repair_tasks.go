@@ -0,0 +1,153 @@
+// internal/tasks/repair_tasks.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+)
+
+// registerRepairTask registers repair_window for manual re-fetching of a
+// historical slice. It has no schedule; runs are triggered from the dashboard.
+func (tm *SubredditTaskManager) registerRepairTask() error {
+	repairSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"subreddit":  blueberry.TypeString,
+		"from":       blueberry.TypeString, // epoch seconds, inclusive
+		"to":         blueberry.TypeString, // epoch seconds
+		"limit":      blueberry.TypeInt,    // default DEFAULT_LIMIT, at most MAX_POSTS_CEILING
+		"chunk_size": blueberry.TypeString, // default PROCESS_CHUNK_SIZE
+	})
+
+	if _, err := tm.registerTask("repair_window", tm.repairWindow, repairSchema); err != nil {
+		return fmt.Errorf("failed to register repair window task: %w", err)
+	}
+	return nil
+}
+
+// repairWindow fetches exactly [from, to) for a subreddit and upserts it,
+// reporting how many posts were missing before the repair
+func (tm *SubredditTaskManager) repairWindow(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+	params := tctx.GetParams()
+
+	subredditName, ok := params["subreddit"].(string)
+	if !ok || subredditName == "" {
+		return logger.Error("invalid or missing subreddit parameter")
+	}
+
+	from, err := parseEpochParam(params, "from")
+	if err != nil {
+		return logger.Error(err.Error())
+	}
+	to, err := parseEpochParam(params, "to")
+	if err != nil {
+		return logger.Error(err.Error())
+	}
+
+	now := tm.clock.Now().Unix()
+	if from >= to {
+		return logger.Error(fmt.Sprintf("from (%d) must be before to (%d)", from, to))
+	}
+	if to > now {
+		return logger.Error(fmt.Sprintf("to (%d) is in the future", to))
+	}
+
+	// Runs queued before limit became TypeInt pass it as a string, which
+	// intParam still reads
+	limit, present, err := intParam(params, "limit")
+	if err != nil {
+		return logger.Error(err.Error())
+	}
+	if !present || limit == 0 {
+		limit = int64(tm.config.DefaultLimit)
+	}
+	if limit < minMonitorLimit {
+		return logger.Error(fmt.Sprintf("limit must be at least %d, got %d", minMonitorLimit, limit))
+	}
+	if limit > int64(tm.config.MaxPostsCeiling) {
+		logger.Info(fmt.Sprintf("Limit %d is above MAX_POSTS_CEILING, fetching %d", limit, tm.config.MaxPostsCeiling))
+		limit = int64(tm.config.MaxPostsCeiling)
+	}
+
+	chunkSize, err := chunkSizeParam(params, tm.config.ProcessChunkSize)
+	if err != nil {
+		return logger.Error(err.Error())
+	}
+
+	if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+		return logger.Error(fmt.Sprintf("Ingestion paused until %s, retry the repair then", pausedUntil.UTC().Format(time.RFC3339)))
+	}
+	if pause := tm.maintenancePause(ctx, logger); pause != nil {
+		return logger.Error(fmt.Sprintf("Ingestion paused for maintenance (%s), retry the repair after it", describePause(pause)))
+	}
+
+	logger.Info(fmt.Sprintf("Repairing r/%s between %s and %s (limit: %d)", subredditName,
+		time.Unix(from, 0).UTC().Format(time.RFC3339), time.Unix(to, 0).UTC().Format(time.RFC3339), limit))
+
+	subredditConfig, err := tm.storage.GetSubredditConfig(ctx, subredditName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit config: %v", err))
+		return err
+	}
+
+	// A repair must see the window as the API serves it now, not a result
+	// shared with a routine run
+	ingestionPosts, err := tm.client.GetSubredditPosts(client.NoCoalesce(ctx), subredditName, int(limit), from, to)
+	if err != nil {
+		if tm.pauseOnRateLimit(err, logger, "") {
+			return err
+		}
+		logger.Error(fmt.Sprintf("Failed to fetch subreddit posts: %v", err))
+		return err
+	}
+
+	process := func(ctx context.Context, chunk []models.IngestionPost) ([]models.Post, processor.ProcessStats, error) {
+		processed, stats := tm.processor.ProcessSubredditPosts(chunk, subredditName, subredditConfig)
+		processed, err := tm.enrichPosts(ctx, processed, subredditConfig, logger)
+		return processed, stats, err
+	}
+	stored, err := tm.storeInChunks(ctx, ingestionPosts, chunkSize, process, upsertOptions(subredditConfig), logger)
+	tm.updateRollups(ctx, stored.Inserted, logger)
+	if err != nil {
+		// Every fetched post up to the checkpoint is stored, so a rerun from
+		// there repeats at most the posts of the checkpoint's second
+		resumeFrom := from
+		if !stored.Checkpoint.IsZero() {
+			resumeFrom = max(from, stored.Checkpoint.Unix())
+		}
+		return logger.Error(fmt.Sprintf("Repair of r/%s stopped after %d of %d chunks (%d posts stored): %v; rerun with from=%d to resume",
+			subredditName, stored.ChunksDone, stored.Chunks, stored.Stored, err, resumeFrom))
+	}
+	if stored.Stored == 0 {
+		logger.Success(fmt.Sprintf("No posts found for r/%s in window", subredditName))
+		return nil
+	}
+
+	newCount := len(stored.Inserted)
+	logger.Success(fmt.Sprintf("Repaired r/%s: %d posts in window, %d new, %d already present",
+		subredditName, stored.Stored, newCount, stored.Stored-newCount))
+
+	return nil
+}
+
+// parseEpochParam reads a required epoch-seconds string parameter
+func parseEpochParam(params blueberry.TaskParams, key string) (int64, error) {
+	value, _ := params[key].(string)
+	if value == "" {
+		return 0, fmt.Errorf("missing %s parameter", key)
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("invalid %s value '%s', expected epoch seconds", key, value)
+	}
+	return parsed, nil
+}
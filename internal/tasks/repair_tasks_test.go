@@ -0,0 +1,54 @@
+package tasks
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestRepairWindowLimit(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	from := strconv.FormatInt(start.Add(-2*time.Hour).Unix(), 10)
+	to := strconv.FormatInt(start.Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		limit     interface{}
+		paused    bool
+		wantLimit int // 0 when nothing may be fetched
+	}{
+		{name: "default", limit: 0, wantLimit: 100},
+		{name: "within the ceiling", limit: 250, wantLimit: 250},
+		{name: "clamped to the ceiling", limit: 5000, wantLimit: 1000},
+		{name: "string from an older run", limit: "40", wantLimit: 40},
+		{name: "negative", limit: -1},
+		{name: "ingestion paused", limit: 0, paused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			cfg := testConfig(t)
+			cfg.DefaultLimit, cfg.MaxPostsCeiling = 100, 1000
+			fetched := 0
+			ingestion := &fakeClient{subreddit: func(subreddit string, limit int, since, until int64) ([]models.IngestionPost, error) {
+				fetched = limit
+				return []models.IngestionPost{ingestionPost("t3_repaired", start.Add(-90*time.Minute))}, nil
+			}}
+			tm := newTestManager(t, cfg, storagetest.NewMemory(clk), ingestion, &recordingNotifier{}, clk)
+			if tt.paused {
+				tm.pause.extend(clk.Now(), clk.Now().Add(time.Minute), 0)
+			}
+
+			_, messages := runTask(t, tm, tm.repairWindow, blueberry.TaskParams{"subreddit": "golang", "from": from, "to": to, "limit": tt.limit})
+			if fetched != tt.wantLimit {
+				t.Errorf("fetched with limit %d, want %d; log %v", fetched, tt.wantLimit, messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// internal/tasks/rollups.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"reddit-orchestrator/internal/models"
+)
+
+// updateRollups counts newly inserted posts into their hourly rollups.
+// Failures are logged only; RebuildRollups can repair the buckets.
+func (tm *SubredditTaskManager) updateRollups(ctx context.Context, newPosts []models.Post, logger runLogger) {
+	if len(newPosts) == 0 {
+		return
+	}
+	if err := tm.storage.IncrementRollups(ctx, newPosts); err != nil {
+		logger.Error(fmt.Sprintf("Failed to update post rollups: %v", err))
+	}
+}
@@ -0,0 +1,261 @@
+// internal/tasks/run_queue.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/clock"
+)
+
+type RunState string
+
+const (
+	RunQueued   RunState = "queued"
+	RunRunning  RunState = "running"
+	RunFinished RunState = "finished"
+)
+
+// errDraining rejects runs that reach the queue after Drain started
+var errDraining = errors.New("task manager is draining, run rejected")
+
+const (
+	maxFinishedRuns   = 200
+	finishedRunMaxAge = time.Hour
+)
+
+// RunInfo describes one monitor_subreddit run as seen by the run queue. The
+// queue keeps local times, whose monotonic readings time waits and evictions;
+// snapshots carry them in UTC.
+type RunInfo struct {
+	ID           int64         `json:"id"`
+	Subreddit    string        `json:"subreddit"`
+	State        RunState      `json:"state"`
+	EnqueuedAt   time.Time     `json:"enqueued_at"`
+	StartedAt    time.Time     `json:"started_at,omitempty"`
+	FinishedAt   time.Time     `json:"finished_at,omitempty"`
+	WaitDuration time.Duration `json:"wait_duration"`
+	Error        string        `json:"error,omitempty"`
+	// FailedStage is FailedStageIngest or FailedStageBookkeeping for failed runs
+	FailedStage string `json:"failed_stage,omitempty"`
+}
+
+// inUTC returns a copy of the run with its times in UTC
+func (r RunInfo) inUTC() RunInfo {
+	r.EnqueuedAt = r.EnqueuedAt.UTC()
+	r.StartedAt = r.StartedAt.UTC()
+	r.FinishedAt = r.FinishedAt.UTC()
+	return r
+}
+
+// QueueSnapshot is a point-in-time copy of the run queue
+type QueueSnapshot struct {
+	MaxConcurrent int       `json:"max_concurrent"`
+	Queued        []RunInfo `json:"queued"`
+	Running       []RunInfo `json:"running"`
+	Finished      []RunInfo `json:"finished"`
+}
+
+// runQueue limits concurrent runs and tracks queued, running and recently
+// finished runs. Finished runs are bounded by count and age.
+type runQueue struct {
+	mu       sync.Mutex
+	slots    chan struct{}
+	nextID   int64
+	active   map[int64]*RunInfo
+	finished []RunInfo // oldest first
+
+	// drainCh is closed by startDrain; queued and new runs are then rejected
+	draining bool
+	drainCh  chan struct{}
+
+	clock clock.Clock
+}
+
+func newRunQueue(maxConcurrent int, clk clock.Clock) *runQueue {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return &runQueue{
+		slots:   make(chan struct{}, maxConcurrent),
+		active:  make(map[int64]*RunInfo),
+		drainCh: make(chan struct{}),
+		clock:   clk,
+	}
+}
+
+// enqueue registers a run waiting for a slot and returns its ID
+func (q *runQueue) enqueue(subreddit string) int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	q.active[q.nextID] = &RunInfo{
+		ID:         q.nextID,
+		Subreddit:  subreddit,
+		State:      RunQueued,
+		EnqueuedAt: q.clock.Now(),
+	}
+	return q.nextID
+}
+
+// acquire blocks until a slot is free, ctx is done or draining starts, then
+// marks the run running
+func (q *runQueue) acquire(ctx context.Context, id int64) error {
+	select {
+	case <-q.drainCh:
+		return errDraining
+	default:
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.drainCh:
+		return errDraining
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Draining may have started while we waited for the slot
+	if q.draining {
+		<-q.slots
+		return errDraining
+	}
+
+	if run, ok := q.active[id]; ok {
+		run.State = RunRunning
+		run.StartedAt = q.clock.Now()
+		run.WaitDuration = run.StartedAt.Sub(run.EnqueuedAt)
+	}
+	return nil
+}
+
+// finish releases the run's slot (if it held one) and moves it to the finished list
+func (q *runQueue) finish(id int64, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	run, ok := q.active[id]
+	if !ok {
+		return
+	}
+	delete(q.active, id)
+
+	if run.State == RunRunning {
+		<-q.slots
+	}
+
+	run.State = RunFinished
+	run.FinishedAt = q.clock.Now()
+	if err != nil {
+		run.Error = err.Error()
+		run.FailedStage = failedStage(err)
+	}
+
+	q.finished = append(q.finished, *run)
+	if len(q.finished) > maxFinishedRuns {
+		q.finished = append([]RunInfo(nil), q.finished[len(q.finished)-maxFinishedRuns:]...)
+	}
+	q.evictLocked(run.FinishedAt)
+}
+
+// evictLocked drops finished runs older than finishedRunMaxAge
+func (q *runQueue) evictLocked(now time.Time) {
+	cutoff := now.Add(-finishedRunMaxAge)
+	drop := 0
+	for drop < len(q.finished) && q.finished[drop].FinishedAt.Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		q.finished = append([]RunInfo(nil), q.finished[drop:]...)
+	}
+}
+
+// startDrain rejects queued and future runs; running runs are unaffected
+func (q *runQueue) startDrain() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.draining {
+		q.draining = true
+		close(q.drainCh)
+	}
+}
+
+// runningCount returns the number of runs holding a slot
+func (q *runQueue) runningCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	running := 0
+	for _, run := range q.active {
+		if run.State == RunRunning {
+			running++
+		}
+	}
+	return running
+}
+
+// snapshot copies the queue state under lock
+func (q *runQueue) snapshot() QueueSnapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.evictLocked(q.clock.Now())
+
+	snapshot := QueueSnapshot{
+		MaxConcurrent: cap(q.slots),
+		Queued:        []RunInfo{},
+		Running:       []RunInfo{},
+		Finished:      make([]RunInfo, 0, len(q.finished)),
+	}
+	for _, run := range q.active {
+		if run.State == RunQueued {
+			snapshot.Queued = append(snapshot.Queued, run.inUTC())
+		} else {
+			snapshot.Running = append(snapshot.Running, run.inUTC())
+		}
+	}
+	// Newest finished first
+	for i := len(q.finished) - 1; i >= 0; i-- {
+		snapshot.Finished = append(snapshot.Finished, q.finished[i].inUTC())
+	}
+
+	return snapshot
+}
+
+// depth returns the number of queued runs
+func (q *runQueue) depth() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queued := 0
+	for _, run := range q.active {
+		if run.State == RunQueued {
+			queued++
+		}
+	}
+	return float64(queued)
+}
+
+// oldestWaitSeconds returns how long the oldest queued run has been waiting
+func (q *runQueue) oldestWaitSeconds() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var oldest time.Time
+	for _, run := range q.active {
+		if run.State == RunQueued && (oldest.IsZero() || run.EnqueuedAt.Before(oldest)) {
+			oldest = run.EnqueuedAt
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return q.clock.Now().Sub(oldest).Seconds()
+}
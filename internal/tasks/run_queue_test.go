@@ -0,0 +1,153 @@
+package tasks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock"
+	"reddit-orchestrator/internal/clock/clocktest"
+)
+
+// inZone reports whether this process runs with tz as its local time zone.
+// Otherwise it runs the calling test again in a process started with TZ=tz
+// and reports its failures. Tests don't set time.Local instead, as runs and
+// timers other tests leave behind read it concurrently.
+func inZone(t *testing.T, tz string) bool {
+	t.Helper()
+	if os.Getenv("TASKS_TEST_TZ") == tz {
+		return true
+	}
+	names := strings.Split(t.Name(), "/")
+	for i, name := range names {
+		names[i] = "^" + regexp.QuoteMeta(name) + "$"
+	}
+	cmd := exec.Command(os.Args[0], "-test.run="+strings.Join(names, "/"), "-test.count=1", "-test.v")
+	cmd.Env = append(os.Environ(), "TZ="+tz, "TASKS_TEST_TZ="+tz)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("with TZ=%s: %v\n%s", tz, err, out)
+	}
+	// A pattern matching nothing passes too
+	if !strings.Contains(string(out), "--- PASS: "+t.Name()+" ") {
+		t.Fatalf("with TZ=%s the test did not run:\n%s", tz, out)
+	}
+	return false
+}
+
+// wantLocalOffset fails unless the local zone is offset seconds east of UTC
+// at at, so a TZ the host can't load doesn't pass as UTC
+func wantLocalOffset(t *testing.T, at time.Time, offset int) {
+	t.Helper()
+	if _, got := at.In(time.Local).Zone(); got != offset {
+		t.Fatalf("local zone %s is %ds from UTC at %v, want %ds", time.Local, got, at, offset)
+	}
+}
+
+func TestRunQueueNonUTCZones(t *testing.T) {
+	start := time.Date(2024, 3, 10, 6, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		tz     string
+		offset int
+	}{
+		{name: "utc", tz: "UTC"},
+		{name: "ahead of utc", tz: "Asia/Kolkata", offset: 5*3600 + 1800},
+		{name: "behind utc", tz: "America/New_York", offset: -5 * 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !inZone(t, tt.tz) {
+				return
+			}
+			wantLocalOffset(t, start, tt.offset)
+			clk := clocktest.NewFake(start.Local())
+			q := newRunQueue(1, clk)
+
+			id := q.enqueue("golang")
+			clk.Advance(3 * time.Second)
+			if err := q.acquire(context.Background(), id); err != nil {
+				t.Fatalf("acquire() error = %v", err)
+			}
+			clk.Advance(time.Minute)
+			q.finish(id, nil)
+
+			finished := q.snapshot().Finished
+			if len(finished) != 1 {
+				t.Fatalf("got %d finished runs, want 1", len(finished))
+			}
+			run := finished[0]
+			if run.WaitDuration != 3*time.Second {
+				t.Errorf("WaitDuration = %v, want 3s", run.WaitDuration)
+			}
+			for name, got := range map[string]time.Time{"EnqueuedAt": run.EnqueuedAt, "StartedAt": run.StartedAt, "FinishedAt": run.FinishedAt} {
+				if got.Location() != time.UTC {
+					t.Errorf("%s = %v, want UTC", name, got)
+				}
+			}
+			if want := start.Add(3*time.Second + time.Minute); !run.FinishedAt.Equal(want) {
+				t.Errorf("FinishedAt = %v, want %v", run.FinishedAt, want)
+			}
+
+			clk.Advance(finishedRunMaxAge + time.Second)
+			if got := len(q.snapshot().Finished); got != 0 {
+				t.Errorf("got %d finished runs after %v, want them evicted", got, finishedRunMaxAge)
+			}
+		})
+	}
+}
+
+func TestRunQueueKeepsMonotonicReadings(t *testing.T) {
+	if !inZone(t, "Asia/Kolkata") {
+		return
+	}
+	wantLocalOffset(t, time.Now(), 5*3600+1800)
+	q := newRunQueue(1, clock.Real)
+
+	id := q.enqueue("golang")
+	if err := q.acquire(context.Background(), id); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// A monotonic reading shows as m=±<seconds>; UTC() would have stripped it
+	q.mu.Lock()
+	run := *q.active[id]
+	q.mu.Unlock()
+	for name, got := range map[string]time.Time{"EnqueuedAt": run.EnqueuedAt, "StartedAt": run.StartedAt} {
+		if !strings.Contains(got.String(), " m=") {
+			t.Errorf("%s = %v, want a monotonic clock reading", name, got)
+		}
+	}
+
+	running := q.snapshot().Running
+	if len(running) != 1 || running[0].StartedAt.Location() != time.UTC {
+		t.Errorf("snapshot running = %+v, want one run with UTC times", running)
+	}
+	q.finish(id, nil)
+}
+
+func TestWatchdogStatusInUTC(t *testing.T) {
+	if !inZone(t, "America/New_York") {
+		return
+	}
+	var w watchdog
+
+	at := time.Date(2024, 3, 10, 1, 0, 0, 0, time.Local)
+	wantLocalOffset(t, at, -5*3600)
+	w.runCompleted(at)
+	status := w.status()
+	if status.LastRunAt.Location() != time.UTC || !status.LastRunAt.Equal(at) {
+		t.Errorf("LastRunAt = %v, want %v in UTC", status.LastRunAt, at)
+	}
+
+	tripped, missing := w.check(at.Add(4*time.Minute), time.Minute, false)
+	if !tripped || missing != 4*time.Minute {
+		t.Errorf("check() = %v, %v, want a trip after 4m", tripped, missing)
+	}
+}
@@ -0,0 +1,218 @@
+// internal/tasks/schedule_report.go
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/metrics"
+)
+
+// Kinds of schedule in the startup report
+const (
+	ScheduleKindSubreddit = "subreddit"
+	ScheduleKindUser      = "user"
+	ScheduleKindTask      = "task"
+	ScheduleKindDigest    = "digest"
+)
+
+// ScheduleEntry is the outcome of registering one schedule at startup
+type ScheduleEntry struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+	// Tier is where a subreddit's schedule came from: a priority tier such
+	// as "priority>=80", or adaptive, explicit, push or default
+	Tier string `json:"tier,omitempty"`
+}
+
+// ScheduleReport lists every schedule registration attempted at startup,
+// sorted by kind then name. While subreddit schedules are still registering,
+// InProgress is set and Entries holds the outcomes so far.
+type ScheduleReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	InProgress  bool            `json:"in_progress"`
+	Subreddits  int             `json:"subreddits"` // active subreddits to schedule, once known
+	Registered  int             `json:"registered"`
+	Failed      int             `json:"failed"`
+	Entries     []ScheduleEntry `json:"entries"`
+}
+
+// scheduleRegistry collects registration outcomes while RegisterTasks and
+// ScheduleSubreddits run and holds the finished report, which API handlers
+// read concurrently
+type scheduleRegistry struct {
+	mu         sync.Mutex
+	pending    []ScheduleEntry
+	inProgress bool
+	subreddits int
+	recorded   int // subreddit entries in pending
+	report     ScheduleReport
+}
+
+// begin marks registration as in progress until finish
+func (r *scheduleRegistry) begin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inProgress = true
+	r.subreddits = 0
+	r.recorded = 0
+}
+
+// expect sets how many subreddit schedules registration will attempt
+func (r *scheduleRegistry) expect(subreddits int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subreddits = subreddits
+}
+
+// record adds an outcome to the pending report and returns how many
+// subreddit outcomes it now holds
+func (r *scheduleRegistry) record(entry ScheduleEntry) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = append(r.pending, entry)
+	if entry.Kind == ScheduleKindSubreddit {
+		r.recorded++
+	}
+	return r.recorded
+}
+
+// finish sorts the pending entries into a new report and stores it
+func (r *scheduleRegistry) finish() ScheduleReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report = buildScheduleReport(r.pending, r.subreddits)
+	r.pending = nil
+	r.recorded = 0
+	r.inProgress = false
+	return r.current()
+}
+
+// buildScheduleReport sorts entries, which it takes over, into a report
+func buildScheduleReport(entries []ScheduleEntry, subreddits int) ScheduleReport {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	report := ScheduleReport{GeneratedAt: time.Now().UTC(), Subreddits: subreddits, Entries: entries}
+	for _, entry := range entries {
+		if entry.OK {
+			report.Registered++
+		} else {
+			report.Failed++
+		}
+	}
+	if report.Entries == nil {
+		report.Entries = []ScheduleEntry{}
+	}
+	return report
+}
+
+// update changes the schedule of a registered entry in the stored report,
+// after a schedule was swapped at runtime
+func (r *scheduleRegistry) update(kind, name, schedule, tier string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, entry := range r.report.Entries {
+		if entry.Kind == kind && entry.Name == name && entry.OK {
+			r.report.Entries[i].Schedule = schedule
+			r.report.Entries[i].Tier = tier
+		}
+	}
+}
+
+// reconcile replaces the stored report's entries of changed subreddits,
+// drops those of removed ones and sets the active subreddit count, after
+// a config commit reconciled the schedules
+func (r *scheduleRegistry) reconcile(changed []ScheduleEntry, removed []string, subreddits int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replaced := make(map[string]bool, len(changed)+len(removed))
+	for _, entry := range changed {
+		replaced[entry.Name] = true
+	}
+	for _, name := range removed {
+		replaced[name] = true
+	}
+	entries := make([]ScheduleEntry, 0, len(r.report.Entries)+len(changed))
+	for _, entry := range r.report.Entries {
+		if entry.Kind != ScheduleKindSubreddit || !replaced[entry.Name] {
+			entries = append(entries, entry)
+		}
+	}
+	r.report = buildScheduleReport(append(entries, changed...), subreddits)
+}
+
+// snapshot copies the stored report under lock, or builds one from the
+// pending entries while registration is in progress
+func (r *scheduleRegistry) snapshot() ScheduleReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inProgress {
+		report := buildScheduleReport(append([]ScheduleEntry(nil), r.pending...), r.subreddits)
+		report.InProgress = true
+		return report
+	}
+	return r.current()
+}
+
+// current copies the report; callers hold mu
+func (r *scheduleRegistry) current() ScheduleReport {
+	report := r.report
+	report.Entries = append([]ScheduleEntry(nil), r.report.Entries...)
+	return report
+}
+
+// ScheduleReport returns the report of the last startup registration, or its
+// progress while ScheduleSubreddits runs
+func (tm *SubredditTaskManager) ScheduleReport() ScheduleReport {
+	return tm.schedules.snapshot()
+}
+
+// recordSchedule adds a registration outcome to the pending report. Failures
+// are counted in a metric as they happen, since registration carries on past them.
+func (tm *SubredditTaskManager) recordSchedule(kind, name, schedule string, err error) {
+	tm.recordEntry(ScheduleEntry{Kind: kind, Name: name, Schedule: schedule}, err)
+}
+
+// recordSubredditSchedule records a subreddit's schedule with its tier
+// and returns how many subreddits have been recorded so far
+func (tm *SubredditTaskManager) recordSubredditSchedule(name, schedule, tier string, err error) int {
+	return tm.recordEntry(ScheduleEntry{Kind: ScheduleKindSubreddit, Name: name, Schedule: schedule, Tier: tier}, err)
+}
+
+func (tm *SubredditTaskManager) recordEntry(entry ScheduleEntry, err error) int {
+	entry.OK = err == nil
+	if err != nil {
+		entry.Error = err.Error()
+		metrics.RecordScheduleFailure(entry.Kind)
+	}
+	return tm.schedules.record(entry)
+}
+
+// finishScheduleReport stores the pending entries as the report, logs it once
+// and, with STRICT_SCHEDULING, fails when too many registrations failed
+func (tm *SubredditTaskManager) finishScheduleReport() error {
+	report := tm.schedules.finish()
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	log.Printf("Schedule registration: %d registered, %d failed\n%s", report.Registered, report.Failed, data)
+
+	if tm.config.StrictScheduling && report.Failed > tm.config.ScheduleFailureThreshold {
+		return fmt.Errorf("%d schedule(s) failed to register, more than SCHEDULE_FAILURE_THRESHOLD (%d)",
+			report.Failed, tm.config.ScheduleFailureThreshold)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// internal/tasks/staleness.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+)
+
+// trackStaleness updates the zero-post run streak after a run. When the streak
+// reaches the subreddit's threshold it is flagged stale and a warning is sent;
+// after StaleProbeAfter zero-post runs one unbounded probe fetch checks whether
+// the API still has content we have not stored. Failures are logged only.
+func (tm *SubredditTaskManager) trackStaleness(ctx context.Context, subredditName string, config *models.SubredditConfig, postsFetched int, logger runLogger) {
+	zeroRuns, err := tm.storage.UpdateZeroPostRuns(ctx, subredditName, postsFetched)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to update zero-post run count: %v", err))
+		return
+	}
+	if postsFetched > 0 {
+		return
+	}
+
+	threshold := tm.staleThreshold(config)
+	if zeroRuns == threshold {
+		if err := tm.storage.SetSubredditStale(ctx, subredditName, true); err != nil {
+			logger.Error(fmt.Sprintf("Failed to flag subreddit stale: %v", err))
+		}
+		tm.notify(ctx, notify.Notification{
+			Subject:   fmt.Sprintf("r/%s looks stale", subredditName),
+			Message:   fmt.Sprintf("%d consecutive runs returned no new posts", zeroRuns),
+			Severity:  notify.SeverityWarning,
+			Subreddit: subredditName,
+			Counts:    map[string]int{"zero_post_runs": zeroRuns},
+		}, logger)
+	}
+
+	if tm.config.StaleProbeAfter > 0 && zeroRuns == tm.config.StaleProbeAfter {
+		tm.probeSubreddit(ctx, subredditName, logger)
+	}
+}
+
+// staleThreshold derives the zero-post run limit from the subreddit's
+// expected post interval and schedule, falling back to the global setting
+func (tm *SubredditTaskManager) staleThreshold(config *models.SubredditConfig) int {
+	if config == nil || config.ExpectedPostIntervalHours <= 0 {
+		return tm.config.StaleZeroRunThreshold
+	}
+
+	interval, err := scheduleInterval(tm.effectiveSchedule(*config), tm.clock.Now().UTC())
+	if err != nil || interval <= 0 {
+		return tm.config.StaleZeroRunThreshold
+	}
+
+	expected := time.Duration(config.ExpectedPostIntervalHours) * time.Hour
+	runs := int(math.Ceil(float64(expected) / float64(interval)))
+	if runs < 1 {
+		runs = 1
+	}
+	return runs
+}
+
+// probeSubreddit fetches the newest posts without a since bound and compares
+// them with storage. Unstored posts mean the scrape cursor is skipping content.
+func (tm *SubredditTaskManager) probeSubreddit(ctx context.Context, subredditName string, logger runLogger) {
+	posts, err := tm.client.GetSubredditPosts(ctx, subredditName, tm.config.StaleProbeLimit, 0, 0)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Stale probe for r/%s failed: %v", subredditName, err))
+		return
+	}
+	if len(posts) == 0 {
+		logger.Info(fmt.Sprintf("Stale probe for r/%s: API returned no posts", subredditName))
+		return
+	}
+
+	redditIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		redditIDs = append(redditIDs, post.ID)
+	}
+	existing, err := tm.storage.GetExistingRedditIDs(ctx, redditIDs)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Stale probe for r/%s could not check storage: %v", subredditName, err))
+		return
+	}
+
+	missing := len(posts) - len(existing)
+	if missing == 0 {
+		logger.Info(fmt.Sprintf("Stale probe for r/%s: API returned %d posts, all already stored", subredditName, len(posts)))
+		return
+	}
+
+	tm.notify(ctx, notify.Notification{
+		Subject: fmt.Sprintf("r/%s scrape cursor may be wedged", subredditName),
+		Message: fmt.Sprintf("Probe fetch returned %d of the newest %d posts that are not stored",
+			missing, len(posts)),
+		Severity:  notify.SeverityWarning,
+		Subreddit: subredditName,
+		Counts:    map[string]int{"missing": missing, "probed": len(posts)},
+	}, logger)
+}
+
+// notify sends a notification, logging delivery failures
+func (tm *SubredditTaskManager) notify(ctx context.Context, notification notify.Notification, logger runLogger) {
+	logger.Info(fmt.Sprintf("%s: %s", notification.Subject, notification.Message))
+	if tm.notifier == nil {
+		return
+	}
+	if err := tm.notifier.Notify(ctx, notification); err != nil {
+		logger.Error(fmt.Sprintf("Failed to send notification: %v", err))
+	}
+}
@@ -0,0 +1,113 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"reddit-orchestrator/internal/clock/clocktest"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+	"reddit-orchestrator/internal/storage/storagetest"
+)
+
+func TestTrackStaleness(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		zeroRuns     int
+		postsFetched int
+		probePosts   []string
+		storedPosts  []string
+		wantStale    bool
+		wantRuns     int
+		wantSubjects []string
+	}{
+		{name: "posts reset the streak", zeroRuns: 5, postsFetched: 3, wantRuns: 0},
+		{name: "below threshold", zeroRuns: 0, wantRuns: 1},
+		{name: "reaching threshold flags stale", zeroRuns: 2, wantStale: true, wantRuns: 3, wantSubjects: []string{"r/golang looks stale"}},
+		{name: "past threshold does not notify again", zeroRuns: 3, wantRuns: 4},
+		{
+			name:         "probe finds unstored posts",
+			zeroRuns:     4,
+			probePosts:   []string{"t3_a", "t3_b"},
+			storedPosts:  []string{"t3_a"},
+			wantRuns:     5,
+			wantSubjects: []string{"r/golang scrape cursor may be wedged"},
+		},
+		{name: "probe finds everything stored", zeroRuns: 4, probePosts: []string{"t3_a"}, storedPosts: []string{"t3_a"}, wantRuns: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktest.NewFake(start)
+			cfg := testConfig(t)
+			cfg.StaleZeroRunThreshold = 3
+			cfg.StaleProbeAfter = 5
+
+			store := storagetest.NewMemory(clk)
+			store.SetMetadata(models.SubredditMetadata{SubredditName: "golang", ZeroPostRuns: tt.zeroRuns})
+			var stored []models.Post
+			for _, id := range tt.storedPosts {
+				stored = append(stored, models.Post{RedditID: id, Title: id, Subreddit: "golang"})
+			}
+			if _, err := store.UpsertPosts(context.Background(), stored, storage.UpsertOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			ingestion := &fakeClient{subreddit: func(string, int, int64, int64) ([]models.IngestionPost, error) {
+				var posts []models.IngestionPost
+				for _, id := range tt.probePosts {
+					posts = append(posts, ingestionPost(id, start))
+				}
+				return posts, nil
+			}}
+			notifier := &recordingNotifier{}
+			tm := newTestManager(t, cfg, store, ingestion, notifier, clk)
+
+			tm.trackStaleness(context.Background(), "golang", nil, tt.postsFetched, &recordingLogger{})
+
+			metadata, _ := store.GetSubredditMetadata(context.Background(), "golang")
+			if metadata.ZeroPostRuns != tt.wantRuns || metadata.Stale != tt.wantStale {
+				t.Errorf("zero-post runs = %d, stale = %v; want %d, %v", metadata.ZeroPostRuns, metadata.Stale, tt.wantRuns, tt.wantStale)
+			}
+			sent := notifier.notifications()
+			if len(sent) != len(tt.wantSubjects) {
+				t.Fatalf("sent %v, want subjects %v", sent, tt.wantSubjects)
+			}
+			for i, notification := range sent {
+				if !strings.Contains(notification.Subject, tt.wantSubjects[i]) {
+					t.Errorf("subject %q, want %q", notification.Subject, tt.wantSubjects[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStaleThresholdFollowsExpectedInterval(t *testing.T) {
+	clk := clocktest.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	cfg := testConfig(t)
+	cfg.StaleZeroRunThreshold = 48
+	tm := newTestManager(t, cfg, storagetest.NewMemory(clk), &fakeClient{}, nil, clk)
+
+	tests := []struct {
+		name   string
+		config *models.SubredditConfig
+		want   int
+	}{
+		{name: "no config", config: nil, want: 48},
+		{name: "no expected interval", config: &models.SubredditConfig{Schedule: "*/30 * * * *"}, want: 48},
+		{name: "six hours at half-hourly runs", config: &models.SubredditConfig{Schedule: "*/30 * * * *", ExpectedPostIntervalHours: 6}, want: 12},
+		{name: "interval shorter than a run", config: &models.SubredditConfig{Schedule: "0 */2 * * *", ExpectedPostIntervalHours: 1}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tm.staleThreshold(tt.config); got != tt.want {
+				t.Errorf("staleThreshold() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// internal/tasks/storage_stats_tasks.go
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/storage"
+)
+
+// registerStorageStatsTask registers storage_stats and schedules it (empty
+// StorageStatsSchedule leaves it manual-only)
+func (tm *SubredditTaskManager) registerStorageStatsTask() error {
+	statsSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"force": blueberry.TypeBool, // scan even above STORAGE_STATS_MAX_DOCUMENTS
+	})
+
+	task, err := tm.registerTask("storage_stats", tm.leaderOnly(tm.refreshStorageStats), statsSchema)
+	if err != nil {
+		return fmt.Errorf("failed to register storage stats task: %w", err)
+	}
+
+	if tm.config.StorageStatsSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{"force": false}, tm.config.StorageStatsSchedule); err != nil {
+		return fmt.Errorf("failed to schedule storage stats: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "storage_stats", tm.config.StorageStatsSchedule, nil)
+	return nil
+}
+
+// refreshStorageStats recomputes the per-subreddit storage footprints
+func (tm *SubredditTaskManager) refreshStorageStats(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+	force, _ := tctx.GetParams()["force"].(bool)
+
+	stats, err := tm.storage.RefreshSubredditStorageStats(ctx, storage.StorageStatsOptions{
+		MaxDocuments: tm.config.StorageStatsMaxDocuments,
+		Timeout:      tm.config.StorageStatsTimeout,
+		Force:        force,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to refresh storage stats: %v", err))
+		return err
+	}
+
+	var bytes int64
+	for _, stat := range stats {
+		bytes += stat.Bytes
+	}
+	logger.Success(fmt.Sprintf("Storage stats refreshed: %d subreddits, %d bytes of posts", len(stats), bytes))
+	return nil
+}
@@ -0,0 +1,130 @@
+// internal/tasks/subreddit_info_tasks.go
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/storage"
+)
+
+// registerSubredditInfoTask registers refresh_subreddit_info and schedules it
+// (empty SubredditInfoSchedule leaves it manual-only)
+func (tm *SubredditTaskManager) registerSubredditInfoTask() error {
+	infoSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{})
+
+	task, err := tm.registerTask("refresh_subreddit_info", tm.leaderOnly(tm.refreshSubredditInfo), infoSchema)
+	if err != nil {
+		return fmt.Errorf("failed to register subreddit info task: %w", err)
+	}
+
+	if tm.config.SubredditInfoSchedule == "" {
+		return nil
+	}
+
+	if _, err := task.RegisterSchedule(blueberry.TaskParams{}, tm.config.SubredditInfoSchedule); err != nil {
+		return fmt.Errorf("failed to schedule subreddit info refresh: %w", err)
+	}
+
+	tm.recordSchedule(ScheduleKindTask, "refresh_subreddit_info", tm.config.SubredditInfoSchedule, nil)
+	return nil
+}
+
+// refreshSubredditInfo stores the about info of every configured subreddit.
+// A subreddit the ingestion API has no about info for (404 or 403) is marked
+// unavailable instead of failing the run; a rate limit pauses ingestion and
+// ends the run.
+func (tm *SubredditTaskManager) refreshSubredditInfo(tctx *blueberry.TaskContext) error {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+		logger.Info(fmt.Sprintf("Ingestion paused until %s, skipping subreddit info refresh", pausedUntil.UTC().Format(time.RFC3339)))
+		return nil
+	}
+	if pause := tm.maintenancePause(ctx, logger); pause != nil {
+		logger.Info(fmt.Sprintf("Ingestion paused for maintenance (%s), skipping subreddit info refresh", describePause(pause)))
+		return nil
+	}
+
+	page, err := tm.storage.GetAllSubredditConfigs(ctx, storage.ConfigFilter{}, storage.ListOptions{
+		Fields: []string{"subreddit_name", "enabled", "skip_nsfw"},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit configs: %v", err))
+		return err
+	}
+
+	refreshed, unavailable, failed := 0, 0, 0
+	for _, config := range page.Configs {
+		about, err := tm.client.GetSubredditAbout(ctx, config.SubredditName)
+		var apiErr *client.APIError
+		switch {
+		case err == nil:
+		case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusForbidden):
+			about = &models.AboutInfo{Available: false}
+		default:
+			if tm.pauseOnRateLimit(err, logger, fmt.Sprintf("%d subreddits refreshed", refreshed+unavailable)) {
+				return err
+			}
+			logger.Error(fmt.Sprintf("Failed to get about info for r/%s: %v", config.SubredditName, err))
+			failed++
+			continue
+		}
+
+		about.FetchedAt = tm.clock.Now().UTC()
+		if err := tm.storage.SetSubredditAbout(ctx, config.SubredditName, *about); err != nil {
+			logger.Error(fmt.Sprintf("Failed to store about info for r/%s: %v", config.SubredditName, err))
+			failed++
+			continue
+		}
+		if !about.Available {
+			unavailable++
+			continue
+		}
+		refreshed++
+		if about.Over18 && config.Enabled && !config.SkipNSFW {
+			logger.Info(fmt.Sprintf("Warning: r/%s is marked NSFW but its config does not set skip_nsfw", config.SubredditName))
+		}
+	}
+
+	message := fmt.Sprintf("Subreddit info refreshed for %d subreddits, %d unavailable, %d failed", refreshed, unavailable, failed)
+	if failed > 0 && refreshed+unavailable == 0 {
+		return logger.Error(message)
+	}
+	logger.Success(message)
+	return nil
+}
+
+// markSubredditUnavailable flags a subreddit's about info unavailable after
+// the ingestion API answered 404 for its posts, keeping the last known
+// details. refresh_subreddit_info sets it back once the API has it again.
+func (tm *SubredditTaskManager) markSubredditUnavailable(ctx context.Context, subredditName string, logger runLogger) {
+	ctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+
+	about := models.AboutInfo{}
+	metadata, err := tm.storage.GetSubredditMetadata(ctx, subredditName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get metadata: %v", err))
+		return
+	}
+	if metadata != nil && metadata.About != nil {
+		about = *metadata.About
+	}
+	about.Available = false
+	about.FetchedAt = tm.clock.Now().UTC()
+
+	if err := tm.storage.SetSubredditAbout(ctx, subredditName, about); err != nil {
+		logger.Error(fmt.Sprintf("Failed to mark r/%s unavailable: %v", subredditName, err))
+		return
+	}
+	logger.Info(fmt.Sprintf("Ingestion API has no r/%s, marked it unavailable", subredditName))
+}
@@ -4,14 +4,22 @@ package tasks
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/ersauravadhikari/blueberry-go/blueberry"
 
 	"reddit-orchestrator/internal/client"
+	"reddit-orchestrator/internal/clock"
 	"reddit-orchestrator/internal/config"
+	"reddit-orchestrator/internal/enrichment"
+	"reddit-orchestrator/internal/leader"
+	"reddit-orchestrator/internal/logsample"
+	"reddit-orchestrator/internal/metrics"
 	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/notify"
+	"reddit-orchestrator/internal/privacy"
 	"reddit-orchestrator/internal/processor"
 	"reddit-orchestrator/internal/storage"
 )
@@ -21,125 +29,437 @@ var _ TaskManagerInterface = (*SubredditTaskManager)(nil)
 
 type SubredditTaskManager struct {
 	blueBerry *blueberry.BlueBerry
-	storage   storage.StorageInterface
+	storage   TaskStorage
 	client    client.IngestionClientInterface
 	processor processor.ProcessorInterface
-	config    *config.Config
+	enricher  enrichment.EnricherInterface // nil when enrichment is disabled
+	notifier  notify.NotifierInterface
+	mailer    notify.MailerInterface // nil when SMTP is not configured
+	webhooks  *notify.WebhookGuard   // builds rule and digest webhooks
+	// authors applies PRIVACY_MODE to authors stored outside the processor's
+	// posts, such as the raw posts of QA samples
+	authors *privacy.Authors
+	config  *config.Config
+	// clock is read for cursors, backoffs, windows and cutoffs instead of
+	// the time package
+	clock clock.Clock
+
+	// Scheduled runs are skipped unless this instance leads (always true with HA_MODE=off)
+	elector leader.ElectorInterface
+
+	// Set when the ingestion API asks us to back off; checked before every run
+	pause ingestionPause
+	// Who was told about the maintenance pause, which is kept in storage
+	maintenance maintenanceNotices
+
+	// monitorTask is the registered monitor_subreddit task. RegisterTasks sets
+	// it before the scheduler starts; it is only read afterwards.
+	monitorTask *blueberry.Task
+	// presetTargets are the tasks run presets may start, also set by
+	// RegisterTasks and read-only afterwards
+	presetTargets map[string]presetTarget
+
+	// Shared with scheduler goroutines and API handlers; each component
+	// synchronizes itself, so the manager needs no lock of its own.
+	// queue bounds concurrent monitor runs and records queued/running/finished
+	// runs; schedules collects registration outcomes and the finished report.
+	queue     *runQueue
+	schedules scheduleRegistry
+	// adaptive holds the registered schedules of adaptive_schedule subreddits
+	// and registered those of every subreddit, which config commits reconcile
+	adaptive   adaptiveSchedules
+	registered subredditSchedules
+
+	// Every scheduled run reports completion here so RunWatchdog can tell a
+	// wedged scheduler from a quiet one
+	watchdog watchdog
+	// runs counts the executions of every task for Drain
+	runs taskRuns
 }
 
 func NewSubredditTaskManager(
 	bb *blueberry.BlueBerry,
-	storage storage.StorageInterface,
+	storage TaskStorage,
 	client client.IngestionClientInterface,
 	processor processor.ProcessorInterface,
+	enricher enrichment.EnricherInterface,
+	notifier notify.NotifierInterface,
+	mailer notify.MailerInterface,
+	elector leader.ElectorInterface,
 	config *config.Config,
+	clk clock.Clock,
 ) *SubredditTaskManager {
+	queue := newRunQueue(config.MaxConcurrentRuns, clk)
+	metrics.RegisterQueueGauges(queue.depth, queue.oldestWaitSeconds)
+
 	return &SubredditTaskManager{
 		blueBerry: bb,
 		storage:   storage,
 		client:    client,
 		processor: processor,
+		enricher:  enricher,
+		notifier:  notifier,
+		mailer:    mailer,
+		webhooks:  notify.NewWebhookGuard(config.WebhookAllowedHosts),
+		authors:   privacy.NewAuthors(config.PrivacyMode, config.PrivacyHashKey),
+		elector:   elector,
 		config:    config,
+		queue:     queue,
+		clock:     clk,
 	}
 }
 
-// RegisterTasks registers all subreddit monitoring tasks with BlueBerry
+// QueueSnapshot returns the current run queue state
+func (tm *SubredditTaskManager) QueueSnapshot() QueueSnapshot {
+	return tm.queue.snapshot()
+}
+
+// RegisterTasks registers all subreddit monitoring tasks with BlueBerry and
+// schedules the maintenance tasks. Subreddit schedules are registered
+// afterwards by ScheduleSubreddits.
 func (tm *SubredditTaskManager) RegisterTasks() error {
+	tm.schedules.begin()
+
 	// Define task schema
 	subredditSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
 		"subreddit":       blueberry.TypeString,
-		"limit":           blueberry.TypeString,
-		"since_timestamp": blueberry.TypeString,
+		"limit":           blueberry.TypeInt,
+		"since_timestamp": blueberry.TypeString, // epoch seconds, empty resumes from last scrape
+		"chunk_size":      blueberry.TypeInt,    // posts processed and stored at a time, 0 uses PROCESS_CHUNK_SIZE
+		"dry_run":         blueberry.TypeBool,   // fetch and process without storing anything
+		"force":           blueberry.TypeBool,   // run even while ingestion is paused for maintenance
+		"keep_cursor":     blueberry.TypeBool,   // leave last_scraped_at as is, for bounded manual scrapes
 	})
 
 	// Register the subreddit monitoring task
-	task, err := tm.blueBerry.RegisterTask(
+	task, err := tm.registerTask(
 		"monitor_subreddit",
-		tm.monitorSubreddit,
+		tm.leaderOnly(tm.monitorSubreddit),
 		subredditSchema,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register subreddit monitoring task: %w", err)
 	}
+	tm.monitorTask = task
+	tm.allowPresets("monitor_subreddit", task, subredditSchema, blueberry.TaskParams{
+		"limit":           tm.config.DefaultLimit,
+		"since_timestamp": "",
+		"chunk_size":      0,
+		"dry_run":         false,
+		"force":           false,
+		"keep_cursor":     false,
+	}, func(params blueberry.TaskParams) error {
+		return tm.checkMonitorParams(params, "subreddit")
+	})
+
+	if err := tm.registerAnomalyTask(); err != nil {
+		return err
+	}
+	if err := tm.registerDuplicateURLTask(); err != nil {
+		return err
+	}
+	if err := tm.registerDocumentAuditTask(); err != nil {
+		return err
+	}
+	if err := tm.registerPriorityTask(); err != nil {
+		return err
+	}
+	if err := tm.registerRemovalScanTask(); err != nil {
+		return err
+	}
+	if err := tm.registerRepairTask(); err != nil {
+		return err
+	}
+	if err := tm.registerImportTask(); err != nil {
+		return err
+	}
+	if err := tm.registerUserTask(); err != nil {
+		return err
+	}
+	if err := tm.registerCleanupTask(); err != nil {
+		return err
+	}
+	if err := tm.registerSubredditInfoTask(); err != nil {
+		return err
+	}
+	if err := tm.registerStorageStatsTask(); err != nil {
+		return err
+	}
+	if err := tm.registerDigestTask(); err != nil {
+		return err
+	}
+	return tm.registerAdaptiveTask()
+}
+
+// ScheduleSubreddits registers the monitor_subreddit schedule of every
+// active subreddit once RegisterTasks has run; the app calls it in the
+// background so the API is up meanwhile. SCHEDULE_WORKERS goroutines prepare
+// the schedules (adaptive ones need an activity profile), while registering
+// them is serialized, as BlueBerry's schedule list is not safe for concurrent
+// appends. A failure is reported and skipped; a cancelled ctx stops early.
+func (tm *SubredditTaskManager) ScheduleSubreddits(ctx context.Context) error {
+	// Adaptive reconciliation skips its runs until every entry exists
+	tm.adaptive.reconciling.Lock()
+	defer tm.adaptive.reconciling.Unlock()
 
-	// Get active subreddit configurations from database
-	ctx := context.Background()
 	configs, err := tm.storage.GetActiveSubredditConfigs(ctx)
 	if err != nil {
+		tm.finishScheduleReport()
 		return fmt.Errorf("failed to get subreddit configs: %w", err)
 	}
 
 	if len(configs) == 0 {
 		fmt.Println("No active subreddit configurations found. Please add some to the database.")
-		return nil
+	}
+	tm.schedules.expect(len(configs))
+	log.Printf("Registering schedules of %d subreddits", len(configs))
+
+	// Adaptive subreddits start on the cadence of the current hour
+	now := tm.clock.Now().UTC()
+	stored, err := tm.storedActivityProfiles(ctx, configs)
+	profilesFailed := err != nil
+	if profilesFailed {
+		fmt.Printf("Failed to get activity profiles, adaptive subreddits start on their static schedule: %v\n", err)
+	}
+
+	jobs := make(chan models.SubredditConfig)
+	var register sync.Mutex
+	var workers sync.WaitGroup
+	for i := 0; i < min(tm.config.ScheduleWorkers, max(len(configs), 1)); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for config := range jobs {
+				schedule, tier := tm.scheduleSource(config)
+				if config.AdaptiveSchedule {
+					var profile *models.ActivityProfile
+					if !profilesFailed {
+						profile = tm.activityProfile(ctx, config.SubredditName, stored[config.SubredditName], now)
+					}
+					schedule, tier = tm.adaptiveScheduleSource(config, profile, now)
+				}
+
+				register.Lock()
+				params := tm.scheduleParams(config)
+				info, err := tm.monitorTask.RegisterSchedule(params, schedule)
+				if err == nil {
+					limit, _ := params["limit"].(int)
+					tm.registered.set(config.SubredditName, subredditEntry{entryID: info.EntryID, schedule: schedule, tier: tier, limit: limit})
+					if config.AdaptiveSchedule {
+						tm.adaptive.set(config.SubredditName, adaptiveEntry{entryID: info.EntryID, schedule: schedule, tier: tier})
+					}
+				}
+				register.Unlock()
+
+				if done := tm.recordSubredditSchedule(config.SubredditName, schedule, tier, err); done%tm.config.ScheduleProgressEvery == 0 {
+					log.Printf("Registered %d of %d subreddit schedules", done, len(configs))
+				}
+			}
+		}()
 	}
 
-	// Schedule each active subreddit
+feed:
 	for _, config := range configs {
-		schedule := config.Schedule
-		if schedule == "" {
-			schedule = tm.config.SubredditSchedule // Default from config
+		select {
+		case jobs <- config:
+		case <-ctx.Done():
+			break feed
 		}
+	}
+	close(jobs)
+	workers.Wait()
 
-		_, err := task.RegisterSchedule(blueberry.TaskParams{
-			"subreddit":       config.SubredditName,
-			"limit":           fmt.Sprintf("%d", config.MaxPosts),
-			"since_timestamp": "", // Use automatic timestamp
-		}, schedule)
-		
-		if err != nil {
-			fmt.Printf("Failed to schedule subreddit %s: %v\n", config.SubredditName, err)
-			continue
+	if err := tm.finishScheduleReport(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// Where a subreddit's schedule came from, besides a priority tier's name
+const (
+	ScheduleTierAdaptive = "adaptive" // ADAPTIVE_MIN/MAX_INTERVAL by activity profile
+	ScheduleTierPush     = "push"     // PUSH_POLL_SCHEDULE for push_enabled configs
+	ScheduleTierExplicit = "explicit" // the config's own schedule
+	ScheduleTierDefault  = "default"  // SUBREDDIT_SCHEDULE
+)
+
+// effectiveSchedule returns the schedule a subreddit runs on
+func (tm *SubredditTaskManager) effectiveSchedule(config models.SubredditConfig) string {
+	schedule, _ := tm.scheduleSource(config)
+	return schedule
+}
+
+// EffectiveSchedule returns the schedule a subreddit runs on and where it
+// came from: a priority tier's name, adaptive, explicit, push or default
+func (tm *SubredditTaskManager) EffectiveSchedule(config models.SubredditConfig) (string, string) {
+	return tm.scheduleSource(config)
+}
+
+// scheduleSource returns the schedule currently registered for an adaptive
+// subreddit and the static schedule of any other
+func (tm *SubredditTaskManager) scheduleSource(config models.SubredditConfig) (string, string) {
+	if config.AdaptiveSchedule {
+		if entry, ok := tm.adaptive.get(config.SubredditName); ok {
+			return entry.schedule, entry.tier
 		}
+	}
+	return tm.staticScheduleSource(config)
+}
 
-		fmt.Printf("Scheduled r/%s (priority: %d, max_posts: %d, schedule: %s)\n", 
-			config.SubredditName, config.Priority, config.MaxPosts, schedule)
+// staticScheduleSource picks a subreddit's schedule and names where it came
+// from: pushed subreddits are only polled on the relaxed push schedule, then
+// the config's own schedule wins over its priority tier and the global default
+func (tm *SubredditTaskManager) staticScheduleSource(config models.SubredditConfig) (string, string) {
+	if config.PushEnabled && tm.config.PushPollSchedule != "" {
+		return tm.config.PushPollSchedule, ScheduleTierPush
+	}
+	if config.Schedule != "" {
+		return config.Schedule, ScheduleTierExplicit
 	}
+	if tier, ok := tm.config.TierFor(config.Priority); ok {
+		return tier.Schedule, tier.Name()
+	}
+	return tm.config.SubredditSchedule, ScheduleTierDefault
+}
 
-	fmt.Printf("Successfully scheduled %d subreddits\n", len(configs))
-	return nil
+// scheduleParams builds the monitor_subreddit params for a configured subreddit
+func (tm *SubredditTaskManager) scheduleParams(config models.SubredditConfig) blueberry.TaskParams {
+	return blueberry.TaskParams{
+		"subreddit":       config.SubredditName,
+		"limit":           tm.effectiveLimit(config.MaxPosts, "r/"+config.SubredditName),
+		"since_timestamp": "", // Use automatic timestamp
+		"chunk_size":      0,
+		"dry_run":         false,
+		"force":           false,
+		"keep_cursor":     false,
+	}
+}
+
+// effectiveLimit applies DEFAULT_LIMIT to unset max_posts and clamps values
+// above MAX_POSTS_CEILING, which configs saved before the ceiling may hold
+func (tm *SubredditTaskManager) effectiveLimit(maxPosts int, name string) int {
+	if maxPosts <= 0 {
+		return tm.config.DefaultLimit
+	}
+	if maxPosts > tm.config.MaxPostsCeiling {
+		fmt.Printf("Warning: %s max_posts %d exceeds MAX_POSTS_CEILING, using %d\n",
+			name, maxPosts, tm.config.MaxPostsCeiling)
+		return tm.config.MaxPostsCeiling
+	}
+	return maxPosts
+}
+
+// upsertOptions derives storage options from the subreddit's config (may be nil)
+func upsertOptions(config *models.SubredditConfig) storage.UpsertOptions {
+	if config == nil {
+		return storage.UpsertOptions{}
+	}
+	return storage.UpsertOptions{TrackRevisions: config.TrackRevisions}
+}
+
+// storePosts upserts a batch, retrying once when storage classifies the
+// failure as transient (timeout or network). Upserts are idempotent, and a
+// batch only fails when none of its posts were written.
+func (tm *SubredditTaskManager) storePosts(ctx context.Context, posts []models.Post, opts storage.UpsertOptions, logger runLogger) (storage.UpsertResult, error) {
+	result, err := tm.storage.UpsertPosts(ctx, posts, opts)
+	if err == nil || !storage.IsRetryable(err) || ctx.Err() != nil {
+		return result, err
+	}
+
+	logger.Info(fmt.Sprintf("Storing posts failed (%s), retrying once: %v", storage.ClassifyError(err), err))
+	return tm.storage.UpsertPosts(ctx, posts, opts)
+}
+
+// fetchRetryBackoff is the wait before the first fetch retry; it doubles per attempt
+const fetchRetryBackoff = 2 * time.Second
+
+// fetchSubredditPosts fetches a subreddit's posts, retrying server errors up
+// to MaxRetries times. Rate limits and other client errors are not retried.
+func (tm *SubredditTaskManager) fetchSubredditPosts(ctx context.Context, subredditName string, limit int, sinceTimestamp int64, logger runLogger) ([]models.IngestionPost, error) {
+	backoff := fetchRetryBackoff
+	for attempt := 0; ; attempt++ {
+		posts, err := tm.client.GetSubredditPosts(ctx, subredditName, limit, sinceTimestamp, 0)
+		if err == nil || !client.IsServerError(err) || attempt >= tm.config.MaxRetries {
+			return posts, err
+		}
+
+		logger.Info(fmt.Sprintf("Ingestion API server error, retrying in %v (attempt %d of %d): %v",
+			backoff, attempt+1, tm.config.MaxRetries, err))
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-tm.clock.After(backoff):
+		}
+		backoff *= 2
+	}
 }
 
 // monitorSubreddit is the main task function executed by BlueBerry
-func (tm *SubredditTaskManager) monitorSubreddit(tctx *blueberry.TaskContext) error {
+func (tm *SubredditTaskManager) monitorSubreddit(tctx *blueberry.TaskContext) (runErr error) {
 	ctx := tctx.GetContext()
 	logger := tctx.GetLogger()
 	params := tctx.GetParams()
 
-	// Extract and validate required parameters
-	subredditName, ok := params["subreddit"].(string)
-	if !ok || subredditName == "" {
-		return logger.Error("invalid or missing subreddit parameter")
+	// Extract and validate parameters; bad values fail the run so the
+	// dashboard shows why instead of silently using defaults
+	parsed, err := parseMonitorParams(params, "subreddit", tm.config.DefaultLimit, tm.config.MaxPostsCeiling, tm.clock.Now())
+	if err != nil {
+		return logger.Error(fmt.Sprintf("invalid parameters: %v", err))
+	}
+	chunkSize, err := chunkSizeParam(params, tm.config.ProcessChunkSize)
+	if err != nil {
+		return logger.Error(fmt.Sprintf("invalid parameters: %v", err))
+	}
+	dryRun, _ := params["dry_run"].(bool)
+	force, _ := params["force"].(bool)
+	keepCursor, _ := params["keep_cursor"].(bool)
+	subredditName := parsed.Name
+	limit := parsed.Limit
+	sinceTimestamp := parsed.SinceTimestamp
+	hasManualTimestamp := sinceTimestamp > 0
+	if hasManualTimestamp {
+		logger.Info(fmt.Sprintf("Using manual since_timestamp: %d", sinceTimestamp))
 	}
 
-	limit := tm.config.DefaultLimit
-	if l, exists := params["limit"]; exists {
-		if limitStr, ok := l.(string); ok && limitStr != "" {
-			if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-				limit = parsed
-			} else {
-				logger.Info(fmt.Sprintf("Invalid limit value '%s', using default %d", limitStr, tm.config.DefaultLimit))
-			}
-		}
+	if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+		logger.Info(fmt.Sprintf("Ingestion paused until %s, deferring run for r/%s",
+			pausedUntil.UTC().Format(time.RFC3339), subredditName))
+		return nil
+	}
+	if tm.skipForMaintenance(ctx, "r/"+subredditName, force, logger) {
+		return nil
 	}
 
-	var sinceTimestamp int64
-	var hasManualTimestamp bool
-	if ts, exists := params["since_timestamp"]; exists {
-		if tsStr, ok := ts.(string); ok && tsStr != "" {
-			if parsed, err := strconv.ParseInt(tsStr, 10, 64); err == nil && parsed > 0 {
-				sinceTimestamp = parsed
-				hasManualTimestamp = true
-				logger.Info(fmt.Sprintf("Using manual since_timestamp: %d", sinceTimestamp))
-			} else {
-				logger.Info(fmt.Sprintf("Invalid timestamp value '%s', using last scraped time", tsStr))
-			}
-		}
+	// Wait for a concurrency slot; the run is visible at /api/queue meanwhile
+	runID := tm.queue.enqueue(subredditName)
+	if err := tm.queue.acquire(ctx, runID); err != nil {
+		tm.queue.finish(runID, err)
+		logger.Error(fmt.Sprintf("Cancelled while queued for r/%s: %v", subredditName, err))
+		return err
 	}
+	defer func() { tm.queue.finish(runID, runErr) }()
 
 	logger.Info(fmt.Sprintf("Starting subreddit monitoring for: r/%s (limit: %d)", subredditName, limit))
+	if dryRun {
+		logger.Info("Dry run: posts, metadata and rollups will not be written")
+	}
+
+	// Per-subreddit processing settings (tag rules); nil for unconfigured subreddits
+	subredditConfig, err := tm.storage.GetSubredditConfig(ctx, subredditName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get subreddit config: %v", err))
+		return err
+	}
+	if subredditConfig != nil && subredditConfig.Paused {
+		logger.Info(fmt.Sprintf("Skipping r/%s: config is paused", subredditName))
+		return nil
+	}
+	if !dryRun {
+		defer func() { tm.recordRunOutcome(ctx, subredditName, runErr) }()
+	}
 
 	// Get last scraped timestamp if no manual override
+	firstRunMode := ""
 	if !hasManualTimestamp {
 		metadata, err := tm.storage.GetSubredditMetadata(ctx, subredditName)
 		if err != nil {
@@ -148,68 +468,250 @@ func (tm *SubredditTaskManager) monitorSubreddit(tctx *blueberry.TaskContext) er
 		}
 
 		if metadata != nil && !metadata.LastScrapedAt.IsZero() {
-			sinceTimestamp = metadata.LastScrapedAt.Unix()
+			sinceTimestamp = tm.checkCursor(ctx, subredditName, metadata, dryRun || keepCursor, logger).Unix()
 			logger.Info(fmt.Sprintf("Using since_timestamp: %d", sinceTimestamp))
 		} else {
-			logger.Info("No previous scrape data found")
+			firstRunMode = subredditConfig.FirstRun()
+			sinceTimestamp = tm.firstRunSince(subredditConfig, logger)
 		}
 	}
 
 	// Record the time we're starting this scrape
-	scrapeStartTime := time.Now()
+	scrapeStartTime := tm.clock.Now().UTC()
+
+	// Skipping history only sets the cursor, so a run keeping it fetches instead
+	if firstRunMode == models.FirstRunSkipHistory && !keepCursor {
+		if dryRun {
+			logger.Success(fmt.Sprintf("Dry run for r/%s: the first run would skip history and set the cursor to now; nothing was written", subredditName))
+			return nil
+		}
+		if err := tm.updateMetadata(ctx, subredditName, scrapeStartTime, models.RunStats{
+			Limit:  limit,
+			Source: models.RunSourcePoll,
+		}, logger); err != nil {
+			return err
+		}
+		logger.Success(fmt.Sprintf("Skipped the history of r/%s: cursor set to %s, later runs collect newer posts",
+			subredditName, scrapeStartTime.Format(time.RFC3339)))
+		return nil
+	}
 
 	// Fetch posts from ingestion API
-	ingestionPosts, err := tm.client.GetSubredditPosts(ctx, subredditName, limit, sinceTimestamp)
+	ingestionPosts, err := tm.fetchSubredditPosts(ctx, subredditName, limit, sinceTimestamp, logger)
 	if err != nil {
+		if !tm.pauseOnRateLimit(err, logger, "") && client.IsNotFound(err) {
+			tm.markSubredditUnavailable(ctx, subredditName, logger)
+		}
 		logger.Error(fmt.Sprintf("Failed to fetch subreddit posts: %v", err))
 		return err
 	}
 
+	if dryRun {
+		tm.logDryRun(ingestionPosts, subredditName, subredditConfig, logger)
+		return nil
+	}
+
 	if len(ingestionPosts) == 0 {
 		logger.Info("No new posts found")
-		return tm.updateMetadata(ctx, subredditName, limit, scrapeStartTime, logger)
+		if keepCursor {
+			logger.Info(fmt.Sprintf("Cursor of r/%s left unchanged", subredditName))
+			return nil
+		}
+		if err := tm.updateMetadata(ctx, subredditName, scrapeStartTime, models.RunStats{
+			Limit:    limit,
+			Duration: tm.clock.Now().Sub(scrapeStartTime),
+			Source:   models.RunSourcePoll,
+		}, logger); err != nil {
+			return err
+		}
+		tm.trackStaleness(ctx, subredditName, subredditConfig, 0, logger)
+		return nil
 	}
 
 	logger.Info(fmt.Sprintf("Fetched %d posts from ingestion API", len(ingestionPosts)))
 
-	// Process posts (clean and convert)
-	processedPosts := tm.processor.ProcessSubredditPosts(ingestionPosts, subredditName)
-	logger.Info(fmt.Sprintf("Processed %d valid posts", len(processedPosts)))
-
-	// Store posts in MongoDB
-	if err := tm.storage.UpsertPosts(ctx, processedPosts); err != nil {
-		logger.Error(fmt.Sprintf("Failed to store posts: %v", err))
+	// Process (clean, convert, enrich) and store posts chunk by chunk
+	sampler := tm.newQASampler(runID, subredditName, subredditConfig)
+	process := func(ctx context.Context, chunk []models.IngestionPost) ([]models.Post, processor.ProcessStats, error) {
+		processed, stats := tm.processor.ProcessSubredditPosts(chunk, subredditName, subredditConfig)
+		enriched, err := tm.enrichPosts(ctx, processed, subredditConfig, logger)
+		tm.recordQASamples(ctx, sampler, chunk, processed, enriched, err, logger)
+		return enriched, stats, err
+	}
+	stored, err := tm.storeInChunks(ctx, ingestionPosts, chunkSize, process, upsertOptions(subredditConfig), logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to store posts (%d of %d chunks stored): %v", stored.ChunksDone, stored.Chunks, err))
+		// Stored chunks won't count as inserted when the next run refetches them
+		tm.updateRollups(ctx, stored.Inserted, logger)
+		tm.evaluateNotificationRules(ctx, stored.Inserted, logger)
 		return err
 	}
+	processStats := stored.Stats
+	logger.Info(fmt.Sprintf("Processed %d valid posts", stored.Stored))
+	logRejections(logger, processStats)
+	metrics.RecordRejections(subredditName, processStats.Rejections)
+	if processStats.NSFWUnknown > 0 {
+		logger.Info(fmt.Sprintf("%d posts had no NSFW flag in the ingestion payload", processStats.NSFWUnknown))
+	}
+
+	logger.Info(fmt.Sprintf("Stored %d posts%s", stored.Stored, formatBatchSpan(processStats.Batch)))
+	newPosts := stored.Inserted
+	tm.updateRollups(ctx, newPosts, logger)
+
+	duration := tm.clock.Now().Sub(scrapeStartTime)
 
 	// Update metadata with scrape start time
-	if err := tm.updateMetadata(ctx, subredditName, limit, scrapeStartTime, logger); err != nil {
-		return err
+	stats := models.RunStats{
+		Limit:          limit,
+		PostsFetched:   len(ingestionPosts),
+		PostsProcessed: stored.Stored,
+		SkippedNSFW:    processStats.Rejections[processor.RejectNSFW],
+		SkippedSpoiler: processStats.Rejections[processor.RejectSpoiler],
+		Rejections:     processStats.Rejections,
+		Duration:       duration,
+		Source:         models.RunSourcePoll,
+	}
+	stats.SetPostRange(processStats.Batch.OldestCreatedAt, processStats.Batch.NewestCreatedAt)
+	if keepCursor {
+		// A bounded scrape may have skipped posts between the cursor and its window
+		logger.Info(fmt.Sprintf("Cursor of r/%s left unchanged", subredditName))
+	} else {
+		if err := tm.updateMetadata(ctx, subredditName, scrapeStartTime, stats, logger); err != nil {
+			return err
+		}
+		tm.trackStaleness(ctx, subredditName, subredditConfig, len(ingestionPosts), logger)
 	}
+	tm.recordBestRecentPost(ctx, subredditName, stored.Best, logger)
+	tm.evaluateNotificationRules(ctx, newPosts, logger)
 
-	duration := time.Since(scrapeStartTime)
-	logger.Success(fmt.Sprintf("Successfully processed r/%s: %d posts stored in %v", 
-		subredditName, len(processedPosts), duration.Round(time.Millisecond)))
+	logger.Success(fmt.Sprintf("Successfully processed r/%s: %d of %d fetched posts stored (limit %d), %d NSFW skipped in %v",
+		subredditName, stored.Stored, len(ingestionPosts), limit, processStats.Rejections[processor.RejectNSFW], duration.Round(time.Millisecond)))
 
 	return nil
 }
 
-// updateMetadata updates the subreddit monitoring metadata
-func (tm *SubredditTaskManager) updateMetadata(ctx context.Context, subredditName string, limit int, scrapedAt time.Time, logger *blueberry.Logger) error {
-	metadata := &models.SubredditMetadata{
-		SubredditName: subredditName,
-		LastScrapedAt: scrapedAt,
-		MonitorConfig: models.MonitorConfig{
-			Enabled:  true,
-			MaxPosts: limit,
-		},
+// firstRunSince returns the since_timestamp of a run without a cursor,
+// following the config's first run mode; zero fetches without a bound.
+// skip_history runs fetch nothing, so it returns zero for them.
+func (tm *SubredditTaskManager) firstRunSince(config *models.SubredditConfig, logger runLogger) int64 {
+	now := tm.clock.Now().UTC()
+	switch mode := config.FirstRun(); mode {
+	case models.FirstRunSkipHistory:
+		logger.Info(fmt.Sprintf("No previous scrape data found, first run mode %s: storing nothing and starting the cursor now", mode))
+		return 0
+	case models.FirstRunFull:
+		if config.MaxPostAgeDays > 0 {
+			since := now.Add(-config.MaxPostAge()).Unix()
+			logger.Info(fmt.Sprintf("No previous scrape data found, first run mode %s: fetching the last %d days (since_timestamp: %d)",
+				mode, config.MaxPostAgeDays, since))
+			return since
+		}
+		logger.Info(fmt.Sprintf("No previous scrape data found, first run mode %s: fetching all available history", mode))
+		return 0
+	default:
+		since := now.Add(-time.Duration(tm.config.DefaultLookbackHours) * time.Hour).Unix()
+		logger.Info(fmt.Sprintf("No previous scrape data found, first run mode %s: fetching the last %d hours (since_timestamp: %d)",
+			mode, tm.config.DefaultLookbackHours, since))
+		return since
 	}
+}
 
-	if err := tm.storage.UpsertSubredditMetadata(ctx, metadata); err != nil {
-		logger.Error(fmt.Sprintf("Failed to update metadata: %v", err))
-		return err
+// recordBestRecentPost saves the run's best stored post on the subreddit's
+// metadata. Runs that stored nothing keep the previous one. Failures are
+// logged only.
+func (tm *SubredditTaskManager) recordBestRecentPost(ctx context.Context, subredditName string, best *models.Post, logger runLogger) {
+	if best == nil {
+		return
+	}
+
+	ctx, cancel := bookkeepingContext(ctx)
+	defer cancel()
+	if err := tm.storage.SetBestRecentPost(ctx, subredditName, models.BestRecentPost{
+		RedditID:   best.RedditID,
+		Title:      best.Title,
+		Score:      best.Score,
+		Permalink:  best.Permalink,
+		CreatedAt:  best.CreatedAt,
+		RecordedAt: tm.clock.Now().UTC(),
+	}); err != nil {
+		logger.Error(fmt.Sprintf("Failed to record the best recent post: %v", err))
+	}
+}
+
+// enrichPosts runs the optional enricher with a per-batch timeout. On failure
+// the unenriched posts are returned unless the subreddit is fail-closed.
+func (tm *SubredditTaskManager) enrichPosts(ctx context.Context, posts []models.Post, config *models.SubredditConfig, logger runLogger) ([]models.Post, error) {
+	if tm.enricher == nil || len(posts) == 0 || !tm.config.Features.Enrichment.Enabled() {
+		return posts, nil
+	}
+
+	enrichCtx, cancel := context.WithTimeout(ctx, tm.config.EnrichmentTimeout)
+	defer cancel()
+
+	enriched, err := tm.enricher.Enrich(enrichCtx, posts)
+	if err != nil {
+		if config != nil && config.EnrichmentFailClosed {
+			logger.Error(fmt.Sprintf("Enrichment failed, not storing batch: %v", err))
+			return nil, err
+		}
+		logger.Error(fmt.Sprintf("Enrichment failed, storing posts unenriched: %v", err))
+		return posts, nil
+	}
+
+	logger.Info(fmt.Sprintf("Enriched %d posts", len(enriched)))
+	return enriched, nil
+}
+
+// logDryRun processes a dry run's fetched posts and logs what a real run
+// would have stored; enrichment is skipped, as it may call external services
+func (tm *SubredditTaskManager) logDryRun(posts []models.IngestionPost, subredditName string, config *models.SubredditConfig, logger runLogger) {
+	processed, stats := tm.processor.ProcessSubredditPosts(posts, subredditName, config)
+	logRejections(logger, stats)
+	logger.Success(fmt.Sprintf("Dry run for r/%s: %d of %d fetched posts would be stored%s; nothing was written",
+		subredditName, len(processed), len(posts), formatBatchSpan(stats.Batch)))
+}
+
+// logRejections logs one line per rejection reason with its count and, when
+// the processor sampled them, a few rejected IDs
+func logRejections(logger runLogger, stats processor.ProcessStats) {
+	rejections := logsample.NewAggregator("validation failed", "reddit_ids", 0)
+	for reason, count := range stats.Rejections {
+		rejections.AddN(reason, count, stats.RejectedSamples[reason]...)
+	}
+	for _, line := range rejections.Lines() {
+		logger.Info(line)
+	}
+}
+
+// formatBatchSpan renders a batch's created_at range as " spanning
+// 2024-05-01T10:02Z – 11:47Z", with the date repeated only when it differs,
+// or "" when the batch had no timestamps
+func formatBatchSpan(batch processor.BatchInfo) string {
+	if batch.OldestCreatedAt.IsZero() {
+		return ""
+	}
+	const full, clock = "2006-01-02T15:04Z", "15:04Z"
+
+	end := batch.NewestCreatedAt.Format(full)
+	if batch.NewestCreatedAt.Format("2006-01-02") == batch.OldestCreatedAt.Format("2006-01-02") {
+		end = batch.NewestCreatedAt.Format(clock)
+	}
+	return fmt.Sprintf(" spanning %s – %s", batch.OldestCreatedAt.Format(full), end)
+}
+
+// updateMetadata advances the scrape cursor; other metadata fields are left
+// intact. It outlives the run's context and retries transient failures; a
+// failure that remains is returned as a BookkeepingError since the posts are
+// already stored.
+func (tm *SubredditTaskManager) updateMetadata(ctx context.Context, subredditName string, scrapedAt time.Time, stats models.RunStats, logger runLogger) error {
+	err := tm.retryBookkeeping(ctx, "metadata", logger, func(ctx context.Context) error {
+		return tm.storage.UpdateLastScraped(ctx, subredditName, scrapedAt, stats)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Bookkeeping failed: posts were stored, but updating metadata failed: %v", err))
+		return &BookkeepingError{Stored: stats.PostsProcessed, Err: err}
 	}
 
 	logger.Info(fmt.Sprintf("Updated last_scraped_at timestamp: %d", scrapedAt.Unix()))
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,170 @@
+// internal/tasks/user_tasks.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ersauravadhikari/blueberry-go/blueberry"
+
+	"reddit-orchestrator/internal/models"
+	"reddit-orchestrator/internal/processor"
+	"reddit-orchestrator/internal/storage"
+)
+
+// registerUserTask registers monitor_user and schedules every active user config
+func (tm *SubredditTaskManager) registerUserTask() error {
+	userSchema := blueberry.NewTaskSchema(blueberry.TaskParamDefinition{
+		"username":        blueberry.TypeString,
+		"limit":           blueberry.TypeInt,
+		"since_timestamp": blueberry.TypeString, // epoch seconds, empty resumes from last scrape
+		"chunk_size":      blueberry.TypeInt,    // posts processed and stored at a time, 0 uses PROCESS_CHUNK_SIZE
+		"force":           blueberry.TypeBool,   // run even while ingestion is paused for maintenance
+	})
+
+	task, err := tm.registerTask("monitor_user", tm.leaderOnly(tm.monitorUser), userSchema)
+	if err != nil {
+		return fmt.Errorf("failed to register user monitoring task: %w", err)
+	}
+	tm.allowPresets("monitor_user", task, userSchema, blueberry.TaskParams{
+		"limit":           tm.config.DefaultLimit,
+		"since_timestamp": "",
+		"chunk_size":      0,
+		"force":           false,
+	}, func(params blueberry.TaskParams) error {
+		return tm.checkMonitorParams(params, "username")
+	})
+
+	configs, err := tm.storage.GetActiveUserConfigs(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get user configs: %w", err)
+	}
+
+	for _, config := range configs {
+		schedule := config.Schedule
+		if schedule == "" {
+			schedule = tm.config.SubredditSchedule
+		}
+
+		_, err := task.RegisterSchedule(tm.userScheduleParams(config), schedule)
+		tm.recordSchedule(ScheduleKindUser, config.Username, schedule, err)
+	}
+
+	return nil
+}
+
+// userScheduleParams builds the monitor_user params for a configured user
+func (tm *SubredditTaskManager) userScheduleParams(config models.UserConfig) blueberry.TaskParams {
+	return blueberry.TaskParams{
+		"username":        config.Username,
+		"limit":           tm.effectiveLimit(config.MaxPosts, "u/"+config.Username),
+		"since_timestamp": "", // Use automatic timestamp
+		"chunk_size":      0,
+		"force":           false,
+	}
+}
+
+// monitorUser mirrors monitorSubreddit for a user's submissions; posts are
+// stored under the subreddit they were posted to
+func (tm *SubredditTaskManager) monitorUser(tctx *blueberry.TaskContext) (runErr error) {
+	ctx := tctx.GetContext()
+	logger := tctx.GetLogger()
+
+	parsed, err := parseMonitorParams(tctx.GetParams(), "username", tm.config.DefaultLimit, tm.config.MaxPostsCeiling, tm.clock.Now())
+	if err != nil {
+		return logger.Error(fmt.Sprintf("invalid parameters: %v", err))
+	}
+	chunkSize, err := chunkSizeParam(tctx.GetParams(), tm.config.ProcessChunkSize)
+	if err != nil {
+		return logger.Error(fmt.Sprintf("invalid parameters: %v", err))
+	}
+	username := parsed.Name
+	sinceTimestamp := parsed.SinceTimestamp
+	force, _ := tctx.GetParams()["force"].(bool)
+
+	if pausedUntil := tm.pause.activeUntil(tm.clock.Now()); !pausedUntil.IsZero() {
+		logger.Info(fmt.Sprintf("Ingestion paused until %s, deferring run for u/%s",
+			pausedUntil.UTC().Format(time.RFC3339), username))
+		return nil
+	}
+	if tm.skipForMaintenance(ctx, "u/"+username, force, logger) {
+		return nil
+	}
+
+	runID := tm.queue.enqueue("u/" + username)
+	if err := tm.queue.acquire(ctx, runID); err != nil {
+		tm.queue.finish(runID, err)
+		logger.Error(fmt.Sprintf("Cancelled while queued for u/%s: %v", username, err))
+		return err
+	}
+	defer func() { tm.queue.finish(runID, runErr) }()
+
+	logger.Info(fmt.Sprintf("Starting user monitoring for: u/%s (limit: %d)", username, parsed.Limit))
+
+	userConfig, err := tm.storage.GetUserConfig(ctx, username)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get user config: %v", err))
+		return err
+	}
+
+	if sinceTimestamp == 0 {
+		metadata, err := tm.storage.GetUserMetadata(ctx, username)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to get user metadata: %v", err))
+			return err
+		}
+		if metadata != nil && !metadata.LastScrapedAt.IsZero() {
+			sinceTimestamp = metadata.LastScrapedAt.Unix()
+			logger.Info(fmt.Sprintf("Using since_timestamp: %d", sinceTimestamp))
+		}
+	}
+
+	scrapeStartTime := tm.clock.Now().UTC()
+
+	ingestionPosts, err := tm.client.GetUserPosts(ctx, username, parsed.Limit, sinceTimestamp)
+	if err != nil {
+		tm.pauseOnRateLimit(err, logger, "")
+		logger.Error(fmt.Sprintf("Failed to fetch user posts: %v", err))
+		return err
+	}
+
+	process := func(_ context.Context, chunk []models.IngestionPost) ([]models.Post, processor.ProcessStats, error) {
+		processed, stats := tm.processor.ProcessUserPosts(chunk, userConfig)
+		return processed, stats, nil
+	}
+	stored, err := tm.storeInChunks(ctx, ingestionPosts, chunkSize, process, storage.UpsertOptions{}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to store posts (%d of %d chunks stored): %v", stored.ChunksDone, stored.Chunks, err))
+		tm.updateRollups(ctx, stored.Inserted, logger)
+		tm.evaluateNotificationRules(ctx, stored.Inserted, logger)
+		return err
+	}
+	processStats := stored.Stats
+	logRejections(logger, processStats)
+	logger.Info(fmt.Sprintf("Stored %d posts%s", stored.Stored, formatBatchSpan(processStats.Batch)))
+	newPosts := stored.Inserted
+	tm.updateRollups(ctx, newPosts, logger)
+
+	stats := models.RunStats{
+		Limit:          parsed.Limit,
+		PostsFetched:   len(ingestionPosts),
+		PostsProcessed: stored.Stored,
+		Rejections:     processStats.Rejections,
+		Duration:       tm.clock.Now().Sub(scrapeStartTime),
+	}
+	stats.SetPostRange(processStats.Batch.OldestCreatedAt, processStats.Batch.NewestCreatedAt)
+	err = tm.retryBookkeeping(ctx, "user metadata", logger, func(ctx context.Context) error {
+		return tm.storage.UpdateUserLastScraped(ctx, username, scrapeStartTime, stats)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Bookkeeping failed: posts were stored, but updating user metadata failed: %v", err))
+		return &BookkeepingError{Stored: stats.PostsProcessed, Err: err}
+	}
+	tm.evaluateNotificationRules(ctx, newPosts, logger)
+
+	logger.Success(fmt.Sprintf("Successfully processed u/%s: %d of %d fetched posts stored (limit %d) in %v",
+		username, stored.Stored, len(ingestionPosts), parsed.Limit, stats.Duration.Round(time.Millisecond)))
+
+	return nil
+}
@@ -0,0 +1,149 @@
+// internal/tasks/watchdog.go
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"reddit-orchestrator/internal/notify"
+)
+
+// watchdogGapFactor is how many expected gaps may pass without a completed
+// run before the scheduler is considered wedged
+const watchdogGapFactor = 3
+
+// WatchdogStatus is the scheduler watchdog's view, shown by /readyz
+type WatchdogStatus struct {
+	// LastRunAt is when a task run last completed, or when the watchdog started
+	LastRunAt time.Time `json:"last_run_at"`
+	// ExpectedGap is the interval of the densest subreddit schedule; zero
+	// when no subreddit is scheduled and the watchdog is idle
+	ExpectedGap time.Duration `json:"expected_gap"`
+	Stalled     bool          `json:"stalled"`
+}
+
+// watchdog tracks completed runs; any run counts, including runs skipped
+// because of a pause, leadership or a paused config, since they show the
+// scheduler still fires
+type watchdog struct {
+	mu          sync.Mutex
+	lastRunAt   time.Time
+	expectedGap time.Duration
+	stalled     bool
+}
+
+// runCompleted records a finished run and reports whether it ended a stall
+func (w *watchdog) runCompleted(at time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastRunAt = at
+	recovered := w.stalled
+	w.stalled = false
+	return recovered
+}
+
+func (w *watchdog) status() WatchdogStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WatchdogStatus{LastRunAt: w.lastRunAt.UTC(), ExpectedGap: w.expectedGap, Stalled: w.stalled}
+}
+
+// check compares the time since the last run with the allowed gap and
+// reports whether the watchdog just tripped and how long runs have been missing
+func (w *watchdog) check(now time.Time, expectedGap time.Duration, paused bool) (bool, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.expectedGap = expectedGap
+	if w.lastRunAt.IsZero() || paused || expectedGap <= 0 {
+		// Start the window over, so the first check after a pause or after
+		// the first schedule appears gets a full window
+		w.lastRunAt = now
+		return false, 0
+	}
+
+	missing := now.Sub(w.lastRunAt)
+	if w.stalled || missing <= watchdogGapFactor*expectedGap {
+		return false, missing
+	}
+	w.stalled = true
+	return true, missing
+}
+
+// WatchdogStatus reports whether scheduled runs are still completing
+func (tm *SubredditTaskManager) WatchdogStatus() WatchdogStatus {
+	return tm.watchdog.status()
+}
+
+// RunWatchdog checks every interval that a task run completed within three
+// gaps of the densest subreddit schedule. When none did, it logs, fails
+// /readyz, notifies and, with WATCHDOG_EXIT, exits so the process is
+// restarted. It returns when ctx is done.
+func (tm *SubredditTaskManager) RunWatchdog(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	tm.checkWatchdog(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tm.checkWatchdog(ctx)
+		}
+	}
+}
+
+func (tm *SubredditTaskManager) checkWatchdog(ctx context.Context) {
+	now := tm.clock.Now()
+	gap := tm.densestScheduleGap(now)
+	paused := !tm.pause.activeUntil(now).IsZero()
+	if !paused {
+		pause, err := tm.storage.GetMaintenancePause(ctx)
+		paused = err == nil && pause != nil
+	}
+
+	tripped, missing := tm.watchdog.check(now, gap, paused)
+	if !tripped {
+		return
+	}
+
+	message := fmt.Sprintf("No task run completed in %s; the densest subreddit schedule runs every %s. The scheduler may be wedged.",
+		missing.Round(time.Second), gap)
+	log.Printf("CRITICAL: scheduler watchdog: %s", message)
+	if err := tm.notifier.Notify(ctx, notify.Notification{
+		Subject:  "Scheduler watchdog tripped",
+		Message:  message,
+		Severity: notify.SeverityCritical,
+		Error:    fmt.Sprintf("no task run completed in %s", missing.Round(time.Second)),
+		Counts:   map[string]int{"missing_seconds": int(missing.Seconds()), "schedule_gap_seconds": int(gap.Seconds())},
+	}); err != nil {
+		log.Printf("Failed to send watchdog notification: %v", err)
+	}
+
+	if tm.config.WatchdogExit {
+		log.Fatalf("Exiting so the process is restarted (WATCHDOG_EXIT=true)")
+	}
+}
+
+// densestScheduleGap is the shortest interval among the registered subreddit
+// schedules, or zero when there are none
+func (tm *SubredditTaskManager) densestScheduleGap(now time.Time) time.Duration {
+	var densest time.Duration
+	for _, entry := range tm.schedules.snapshot().Entries {
+		if entry.Kind != ScheduleKindSubreddit || !entry.OK {
+			continue
+		}
+		gap, err := scheduleInterval(entry.Schedule, now)
+		if err != nil || gap <= 0 {
+			continue
+		}
+		if densest == 0 || gap < densest {
+			densest = gap
+		}
+	}
+	return densest
+}